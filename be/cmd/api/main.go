@@ -9,6 +9,7 @@ import (
 	"koskosan-be/internal/routes"
 	"koskosan-be/internal/scheduler"
 	"koskosan-be/internal/service"
+	"koskosan-be/internal/templates"
 	"koskosan-be/internal/utils"
 	"log"
 	"strings"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func main() {
@@ -31,6 +33,17 @@ func main() {
 	// 1. Load Configuration
 	cfg := config.LoadConfig()
 
+	// 1.1 Load JWT signing keys (generated on first run, rotated via cmd/rotate_keys)
+	jwtKeys, err := utils.EnsureKeySet(cfg.JWTKeysDir, cfg.JWTAlgorithm)
+	if err != nil {
+		log.Fatalf("Failed to load JWT signing keys: %v", err)
+	}
+
+	// 1.2 Storage upload (lokal, S3-compatible atau Cloudinary) dan folder privat
+	if err := utils.InitStorage(cfg); err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+
 	// 2. Initialize Database
 	database.InitDB(cfg)
 	db := database.GetDB()
@@ -43,32 +56,81 @@ func main() {
 	penyewaRepo := repository.NewPenyewaRepository(db)
 	bookingRepo := repository.NewBookingRepository(db)
 	paymentRepo := repository.NewPaymentRepository(db)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	notificationPrefRepo := repository.NewNotificationPreferenceRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	inboundMessageRepo := repository.NewInboundMessageRepository(db)
+	messageTemplateRepo := repository.NewMessageTemplateRepository(db)
+	maintenanceRepo := repository.NewMaintenanceRepository(db)
+	contactRepo := repository.NewContactRepository(db)
+	spamRepo := repository.NewSpamRepository(db)
+	facilityRepo := repository.NewFacilityRepository(db)
+	roomTypeRepo := repository.NewRoomTypeRepository(db)
+	pricingRepo := repository.NewPricingRepository(db)
+	priceHistoryRepo := repository.NewPriceHistoryRepository(db)
+
+	allowedOrigins := strings.Split(cfg.AllowedOrigins, ",")
+
+	// 3.1 Initialize Socket.io (same origin allow-list as CORS, handshake requires access token)
+	socketServer, err := utils.InitSocketServer(allowedOrigins, jwtKeys)
+	if err != nil {
+		log.Fatalf("Failed to initialize Socket.io: %v", err)
+	}
+	socketAdapter, err := newSocketAdapter(cfg, db)
+	if err != nil {
+		log.Fatalf("Failed to initialize Socket.io adapter: %v", err)
+	}
+	// Event terbaru untuk SSE (termasuk event dari instance lain)
+	eventHub := utils.NewEventHub(utils.DefaultEventHubSize)
+	socketServer.OnRemoteBroadcast(eventHub.HandleRemoteBroadcast)
+	if err := socketServer.UseAdapter(socketAdapter); err != nil {
+		log.Fatalf("Failed to subscribe Socket.io adapter: %v", err)
+	}
+	defer socketAdapter.Close()
 
 	// 4. Initialize Services
-	emailSender := utils.NewEmailSender(cfg)
+	// Katalog template email/WhatsApp (bawaan di-embed, hasil edit admin dari database)
+	messages, err := templates.New(messageTemplateRepo)
+	if err != nil {
+		log.Fatalf("Failed to load message templates: %v", err)
+	}
+	emailSender := utils.NewEmailSender(cfg, messages)
 	waSender := utils.NewWhatsAppSender(cfg)
+	smsSender := utils.NewSMSSender(cfg)
+	notificationService := service.NewNotificationService(notificationRepo)
+	eventPublisher := utils.MultiEventPublisher{
+		service.NewInboxEventPublisher(notificationService), // simpan ke inbox dulu
+		utils.NewSocketEventPublisher(socketServer),
+		eventHub,
+	}
+	notificationPrefService := service.NewNotificationPreferenceService(notificationPrefRepo)
+	outboxService := service.NewOutboxService(outboxRepo, emailSender, waSender, smsSender)
+	notifier := service.NewNotificationDispatcher(notificationPrefService, penyewaRepo, outboxService, messages, eventPublisher)
 
 	// Removed Cloudinary Initialization
 
-	authService := service.NewAuthService(userRepo, penyewaRepo, cfg, emailSender, &utils.RealIDTokenVerifier{})
-	kamarService := service.NewKamarService(kamarRepo)
+	authService := service.NewAuthService(userRepo, penyewaRepo, passwordResetRepo, db, cfg, jwtKeys, emailSender, &utils.RealIDTokenVerifier{})
+	kamarService := service.NewKamarService(kamarRepo, facilityRepo, roomTypeRepo)
+	facilityService := service.NewFacilityService(facilityRepo)
+	roomTypeService := service.NewRoomTypeService(roomTypeRepo)
+	pricingService := service.NewPricingService(pricingRepo, kamarRepo)
+	priceHistoryService := service.NewPriceHistoryService(priceHistoryRepo, kamarRepo, notifier, cfg.RentNoticeDays)
 	galleryService := service.NewGalleryService(galleryRepo)
 	dashboardService := service.NewDashboardService(db)
 	reviewService := service.NewReviewService(reviewRepo, bookingRepo, penyewaRepo)
 	profileService := service.NewProfileService(userRepo, penyewaRepo)
-	bookingService := service.NewBookingService(bookingRepo, userRepo, penyewaRepo, kamarRepo, paymentRepo, db)
-	paymentService := service.NewPaymentService(paymentRepo, bookingRepo, kamarRepo, penyewaRepo, db, emailSender, waSender)
+	bookingService := service.NewBookingService(bookingRepo, userRepo, penyewaRepo, kamarRepo, paymentRepo, db, notifier, pricingService)
+	paymentService := service.NewPaymentService(paymentRepo, bookingRepo, kamarRepo, penyewaRepo, db, notifier, pricingService)
 	tenantService := service.NewTenantService(penyewaRepo)
-	contactService := service.NewContactService()
-
-	// 4.1 Initialize Socket.io
-	socketServer, err := utils.InitSocketServer()
-	if err != nil {
-		log.Fatalf("Failed to initialize Socket.io: %v", err)
-	}
+	contactService := service.NewContactService(contactRepo, userRepo, outboxService, emailSender, messages, notifier, cfg)
+	messageTemplateService := service.NewMessageTemplateService(messageTemplateRepo, messages)
+	maintenanceService := service.NewMaintenanceService(maintenanceRepo, bookingRepo, penyewaRepo, userRepo, notifier)
+	spamGuard := service.NewSpamGuard(spamRepo, utils.NewChallengeVerifier(cfg), cfg.JWTSecret)
+	whatsAppBotService := service.NewWhatsAppBotService(penyewaRepo, notificationPrefRepo, inboundMessageRepo, paymentService, bookingService, waSender, utils.NewWhatsAppMediaFetcher(cfg), messages)
 
 	// 5. Initialize Handlers
-	authHandler := handlers.NewAuthHandler(authService, cfg)
+	authHandler := handlers.NewAuthHandler(authService, cfg, jwtKeys)
 	kamarHandler := handlers.NewKamarHandler(kamarService)
 	galleryHandler := handlers.NewGalleryHandler(galleryService)
 	dashboardHandler := handlers.NewDashboardHandler(dashboardService)
@@ -78,6 +140,18 @@ func main() {
 	paymentHandler := handlers.NewPaymentHandler(paymentService)
 	tenantHandler := handlers.NewTenantHandler(tenantService)
 	contactHandler := handlers.NewContactHandler(contactService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService, notificationPrefService, eventHub)
+	outboxHandler := handlers.NewOutboxHandler(outboxService)
+	messageTemplateHandler := handlers.NewMessageTemplateHandler(messageTemplateService)
+	waWebhookHandler := handlers.NewWhatsAppWebhookHandler(outboxService, whatsAppBotService, cfg)
+	maintenanceHandler := handlers.NewMaintenanceHandler(maintenanceService)
+	spamHandler := handlers.NewSpamHandler(spamGuard)
+	facilityHandler := handlers.NewFacilityHandler(facilityService)
+	roomTypeHandler := handlers.NewRoomTypeHandler(roomTypeService)
+	pricingHandler := handlers.NewPricingHandler(pricingService)
+	priceHistoryHandler := handlers.NewPriceHistoryHandler(priceHistoryService)
+	localStorage, _ := utils.GlobalStorage.(*utils.LocalStorage)
+	fileHandler := handlers.NewFileHandler(localStorage)
 
 	// Initialize Routes
 	appRoutes := routes.NewRoutes(
@@ -91,6 +165,18 @@ func main() {
 		paymentHandler,
 		tenantHandler,
 		contactHandler,
+		notificationHandler,
+		outboxHandler,
+		messageTemplateHandler,
+		waWebhookHandler,
+		maintenanceHandler,
+		spamHandler,
+		spamGuard,
+		facilityHandler,
+		roomTypeHandler,
+		pricingHandler,
+		priceHistoryHandler,
+		fileHandler,
 	)
 
 	// Log startup
@@ -110,12 +196,11 @@ func main() {
 	r.Use(middleware.SecurityHeadersMiddleware())
 
 	// CORS Setup
-	allowedOrigins := strings.Split(cfg.AllowedOrigins, ",")
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     allowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Cookie", "X-Requested-With"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Cookie", "X-Requested-With", utils.CSRFHeaderName},
+		ExposeHeaders:    []string{"Content-Length", utils.CSRFHeaderName},
 		AllowCredentials: true,
 	}))
 
@@ -124,23 +209,23 @@ func main() {
 	r.POST("/socket.io/*any", gin.WrapH(socketServer.Server))
 	r.Handle("ANY", "/socket.io/*any", gin.WrapH(socketServer.Server))
 
-	// Serve Static Files for local uploads
+	// Serve Static Files for local uploads (folder privat seperti proofs hanya lewat /api/files)
 	r.Static("/uploads", "./public/uploads")
 	r.Static("/rooms", "./public/rooms")
 	r.Static("/gallery", "./public/gallery")
 	r.Static("/profiles", "./public/profiles")
-	r.Static("/proofs", "./public/proofs")
+	r.Static("/tickets", "./public/tickets")
 
 	// API Routes
-	appRoutes.Register(r, cfg)
+	appRoutes.Register(r, cfg, jwtKeys)
 
 	// 7. Start Background Workers
 	go func() {
 		utils.GlobalLogger.Info("Starting background workers...")
 
 		// Reminder Service & Scheduler
-		reminderService := service.NewReminderService(paymentRepo, db, emailSender, waSender)
-		schedulerService := scheduler.NewScheduler(reminderService)
+		reminderService := service.NewReminderService(paymentRepo, db, notifier, priceHistoryService, cfg.FrontendURL)
+		schedulerService := scheduler.NewScheduler(reminderService, priceHistoryService)
 		schedulerService.Start()
 
 		// Run initial checks
@@ -148,6 +233,24 @@ func main() {
 			utils.GlobalLogger.Error("Failed to auto-cancel bookings: %v", err)
 		}
 
+		// Pick up keys rotated by cmd/rotate_keys without a restart
+		go func() {
+			for range time.Tick(5 * time.Minute) {
+				if err := jwtKeys.Reload(); err != nil {
+					utils.GlobalLogger.Error("Failed to reload JWT keys: %v", err)
+				}
+			}
+		}()
+
+		// Outbox worker: kirim email/WhatsApp yang antri, retry dengan backoff
+		go func() {
+			for range time.Tick(30 * time.Second) {
+				if _, err := outboxService.ProcessDue(50); err != nil {
+					utils.GlobalLogger.Error("Failed to process notification outbox: %v", err)
+				}
+			}
+		}()
+
 		// Tickers (Only for Cancel now, Reminder handled by Scheduler)
 		cancelTicker := time.NewTicker(1 * time.Hour)
 
@@ -165,3 +268,19 @@ func main() {
 		log.Fatal("Failed to run server:", err)
 	}
 }
+
+// newSocketAdapter memilih adapter broadcast sesuai SOCKET_ADAPTER
+func newSocketAdapter(cfg *config.Config, db *gorm.DB) (utils.BroadcastAdapter, error) {
+	switch cfg.SocketAdapter {
+	case "redis":
+		return utils.NewRedisAdapter(cfg.RedisURL)
+	case "postgres":
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		return utils.NewPostgresAdapter(sqlDB, cfg.DatabaseDSN()), nil
+	default:
+		return utils.LocalAdapter{}, nil
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"koskosan-be/internal/utils"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+)
+
+// Rotasi kunci JWT:
+//
+//	go run ./cmd/rotate_keys                 # buat kunci baru, kunci lama tetap untuk verifikasi
+//	go run ./cmd/rotate_keys -alg RS256      # ganti algoritma sekaligus
+//	go run ./cmd/rotate_keys -prune          # hapus kunci retired yang lebih tua dari -retention
+//
+// Instance API memuat ulang direktori kunci setiap 5 menit, jadi tidak perlu restart.
+func main() {
+	_ = godotenv.Load()
+
+	dir := flag.String("dir", getEnv("JWT_KEYS_DIR", "./keys"), "directory containing keys.json and <kid>.pem files")
+	alg := flag.String("alg", getEnv("JWT_ALGORITHM", utils.AlgEdDSA), "signing algorithm for the new key (RS256 or EdDSA)")
+	prune := flag.Bool("prune", false, "only remove retired keys older than -retention, do not generate a new key")
+	retention := flag.Duration("retention", utils.RefreshTokenExpiry+utils.AccessTokenExpiry, "how long retired keys are kept for verification")
+	flag.Parse()
+
+	if *prune {
+		removed, err := utils.PruneKeys(*dir, *retention)
+		if err != nil {
+			log.Fatalf("Prune failed: %v", err)
+		}
+		log.Printf("Pruned %d retired key(s): %v", len(removed), removed)
+		return
+	}
+
+	key, err := utils.RotateKeys(*dir, *alg)
+	if err != nil {
+		log.Fatalf("Rotation failed: %v", err)
+	}
+
+	log.Printf("New active key %s (%s) written to %s", key.KID, key.Algorithm, *dir)
+	log.Printf("Previous keys remain valid for verification until pruned (retention %s)", *retention)
+}
+
+func getEnv(key, fallback string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+	return fallback
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
@@ -19,6 +20,14 @@ type Config struct {
 	AllowedOrigins string
 	IsProduction   bool
 
+	// JWT signing keys (RS256/EdDSA, rotated via cmd/rotate_keys)
+	JWTKeysDir   string
+	JWTAlgorithm string
+
+	// Socket.io broadcast adapter untuk multi-instance: local, redis, atau postgres
+	SocketAdapter string
+	RedisURL      string
+
 	// Application Config
 	FrontendURL string
 	AppVersion  string
@@ -33,12 +42,61 @@ type Config struct {
 	SMTPPort     string
 	SMTPEmail    string
 	SMTPPassword string
+	// Alamat admin yang menerima salinan pesan contact form
+	ContactEmail string
 
 	// Cloudinary Config
 	CloudinaryURL string
 
+	// Penyimpanan file upload: local, s3, cloudinary (kosong = cloudinary jika CLOUDINARY_URL diisi, selain itu local)
+	StorageProvider string
+	// Folder yang tidak bisa dibuka publik, hanya lewat signed URL (dipisah koma)
+	StoragePrivateFolders string
+	// Secret HMAC untuk signed URL storage lokal (kosong = JWT_SECRET)
+	StorageSigningSecret string
+	SignedURLTTLMinutes  int
+
+	// S3-compatible storage (AWS S3, MinIO, R2, ...), selalu path-style: <endpoint>/<bucket>/<key>
+	S3Endpoint      string
+	S3Region        string
+	S3AccessKey     string
+	S3SecretKey     string
+	S3PublicBucket  string
+	S3PrivateBucket string
+	// Base URL publik untuk bucket publik (mis. CDN); kosong = <endpoint>/<bucket>
+	S3PublicURL string
+
 	// WhatsApp Config
-	FonnteToken string
+	WhatsAppProvider string // fonnte, meta, webhook, log (kosong = fonnte jika token ada)
+	FonnteToken      string
+	// Token di query string webhook pesan masuk Fonnte (/api/webhooks/whatsapp/fonnte?token=...)
+	FonnteWebhookToken string
+
+	// Meta WhatsApp Cloud API
+	MetaWAAPIURL        string
+	MetaWAToken         string
+	MetaWAPhoneNumberID string
+	MetaWAAppSecret     string // verifikasi X-Hub-Signature-256 pada webhook status
+	MetaWAVerifyToken   string // verifikasi saat mendaftarkan webhook di dashboard Meta
+
+	// Gateway WhatsApp generik (HTTP webhook bertanda tangan HMAC)
+	WAWebhookURL    string
+	WAWebhookSecret string
+	// Host yang boleh diunduh untuk lampiran pesan masuk (koma), misalnya "fonnte.com,cdn.gateway.id"
+	WhatsAppMediaHosts string
+
+	// SMS cadangan jika WhatsApp gagal: zenziva, log (kosong = log)
+	SMSProvider    string
+	ZenzivaAPIURL  string
+	ZenzivaUserKey string
+	ZenzivaPassKey string
+
+	// Captcha untuk form publik: hcaptcha, turnstile, fake (kosong = nonaktif)
+	CaptchaProvider string
+	CaptchaSecret   string
+
+	// Berapa hari sebelum kenaikan sewa terjadwal berlaku penyewa harus diberi tahu
+	RentNoticeDays int
 }
 
 func LoadConfig() *Config {
@@ -58,6 +116,12 @@ func LoadConfig() *Config {
 		AllowedOrigins: getEnv("ALLOWED_ORIGINS", "http://localhost:3000"),
 		IsProduction:   getEnv("GIN_MODE", "debug") == "release",
 
+		JWTKeysDir:   getEnv("JWT_KEYS_DIR", "./keys"),
+		JWTAlgorithm: getEnv("JWT_ALGORITHM", "EdDSA"),
+
+		SocketAdapter: getEnv("SOCKET_ADAPTER", "local"),
+		RedisURL:      getEnv("REDIS_URL", "redis://localhost:6379/0"),
+
 		// Application Config
 		FrontendURL: getEnv("FRONTEND_URL", "http://localhost:3000"),
 		AppVersion:  getEnv("APP_VERSION", "1.0.0"),
@@ -71,12 +135,49 @@ func LoadConfig() *Config {
 		SMTPPort:     getEnv("SMTP_PORT", "587"),
 		SMTPEmail:    getEnv("SMTP_EMAIL", ""),
 		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		ContactEmail: getEnv("CONTACT_EMAIL", ""),
 
 		// Cloudinary Config
 		CloudinaryURL: getEnv("CLOUDINARY_URL", ""),
 
+		StorageProvider:       getEnv("STORAGE_PROVIDER", ""),
+		StoragePrivateFolders: getEnv("STORAGE_PRIVATE_FOLDERS", "proofs"),
+		StorageSigningSecret:  getEnv("STORAGE_SIGNING_SECRET", ""),
+		SignedURLTTLMinutes:   getEnvInt("SIGNED_URL_TTL_MINUTES", 15),
+
+		S3Endpoint:      getEnv("S3_ENDPOINT", ""),
+		S3Region:        getEnv("S3_REGION", "us-east-1"),
+		S3AccessKey:     getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:     getEnv("S3_SECRET_KEY", ""),
+		S3PublicBucket:  getEnv("S3_PUBLIC_BUCKET", ""),
+		S3PrivateBucket: getEnv("S3_PRIVATE_BUCKET", ""),
+		S3PublicURL:     getEnv("S3_PUBLIC_URL", ""),
+
 		// WhatsApp Config
-		FonnteToken: getEnv("FONNTE_TOKEN", ""),
+		WhatsAppProvider:   getEnv("WHATSAPP_PROVIDER", ""),
+		FonnteToken:        getEnv("FONNTE_TOKEN", ""),
+		FonnteWebhookToken: getEnv("FONNTE_WEBHOOK_TOKEN", ""),
+
+		MetaWAAPIURL:        getEnv("META_WA_API_URL", "https://graph.facebook.com/v20.0"),
+		MetaWAToken:         getEnv("META_WA_TOKEN", ""),
+		MetaWAPhoneNumberID: getEnv("META_WA_PHONE_NUMBER_ID", ""),
+		MetaWAAppSecret:     getEnv("META_WA_APP_SECRET", ""),
+		MetaWAVerifyToken:   getEnv("META_WA_VERIFY_TOKEN", ""),
+
+		WAWebhookURL:    getEnv("WA_WEBHOOK_URL", ""),
+		WAWebhookSecret: getEnv("WA_WEBHOOK_SECRET", ""),
+
+		WhatsAppMediaHosts: getEnv("WHATSAPP_MEDIA_HOSTS", ""),
+
+		SMSProvider:    getEnv("SMS_PROVIDER", ""),
+		ZenzivaAPIURL:  getEnv("ZENZIVA_API_URL", "https://console.zenziva.net/reguler/api"),
+		ZenzivaUserKey: getEnv("ZENZIVA_USERKEY", ""),
+		ZenzivaPassKey: getEnv("ZENZIVA_PASSKEY", ""),
+
+		CaptchaProvider: getEnv("CAPTCHA_PROVIDER", ""),
+		CaptchaSecret:   getEnv("CAPTCHA_SECRET", ""),
+
+		RentNoticeDays: getEnvInt("RENT_NOTICE_DAYS", 30),
 	}
 
 	// Validate required environment variables
@@ -95,15 +196,83 @@ func (c *Config) ValidateRequired() error {
 	if len(c.JWTSecret) < 32 {
 		return fmt.Errorf("JWT_SECRET must be at least 32 characters long for security")
 	}
+	if c.JWTAlgorithm != "RS256" && c.JWTAlgorithm != "EdDSA" {
+		return fmt.Errorf("JWT_ALGORITHM must be RS256 or EdDSA, got %q", c.JWTAlgorithm)
+	}
+	switch c.SocketAdapter {
+	case "local", "redis", "postgres":
+	default:
+		return fmt.Errorf("SOCKET_ADAPTER must be local, redis or postgres, got %q", c.SocketAdapter)
+	}
+	switch c.WhatsAppProvider {
+	case "", "fonnte", "log":
+	case "meta":
+		if c.MetaWAToken == "" || c.MetaWAPhoneNumberID == "" {
+			return fmt.Errorf("WHATSAPP_PROVIDER=meta requires META_WA_TOKEN and META_WA_PHONE_NUMBER_ID")
+		}
+	case "webhook":
+		if c.WAWebhookURL == "" || c.WAWebhookSecret == "" {
+			return fmt.Errorf("WHATSAPP_PROVIDER=webhook requires WA_WEBHOOK_URL and WA_WEBHOOK_SECRET")
+		}
+	default:
+		return fmt.Errorf("WHATSAPP_PROVIDER must be fonnte, meta, webhook or log, got %q", c.WhatsAppProvider)
+	}
+	switch c.SMSProvider {
+	case "", "log":
+	case "zenziva":
+		if c.ZenzivaUserKey == "" || c.ZenzivaPassKey == "" {
+			return fmt.Errorf("SMS_PROVIDER=zenziva requires ZENZIVA_USERKEY and ZENZIVA_PASSKEY")
+		}
+	default:
+		return fmt.Errorf("SMS_PROVIDER must be zenziva or log, got %q", c.SMSProvider)
+	}
+	switch c.StorageProvider {
+	case "", "local":
+	case "s3":
+		if c.S3Endpoint == "" || c.S3AccessKey == "" || c.S3SecretKey == "" || c.S3PublicBucket == "" || c.S3PrivateBucket == "" {
+			return fmt.Errorf("STORAGE_PROVIDER=s3 requires S3_ENDPOINT, S3_ACCESS_KEY, S3_SECRET_KEY, S3_PUBLIC_BUCKET and S3_PRIVATE_BUCKET")
+		}
+	case "cloudinary":
+		if c.CloudinaryURL == "" {
+			return fmt.Errorf("STORAGE_PROVIDER=cloudinary requires CLOUDINARY_URL")
+		}
+	default:
+		return fmt.Errorf("STORAGE_PROVIDER must be local, s3 or cloudinary, got %q", c.StorageProvider)
+	}
+	if c.SignedURLTTLMinutes <= 0 {
+		return fmt.Errorf("SIGNED_URL_TTL_MINUTES must be a positive number of minutes")
+	}
+	if c.RentNoticeDays < 0 {
+		return fmt.Errorf("RENT_NOTICE_DAYS must be a non-negative number of days")
+	}
 	if c.DBPassword == "" {
 		log.Println("WARNING: DB_PASSWORD is empty. This is insecure for production!")
 	}
 	return nil
 }
 
+// DatabaseDSN membangun connection string Postgres (dipakai GORM dan LISTEN/NOTIFY)
+func (c *Config) DatabaseDSN() string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Jakarta",
+		c.DBHost, c.DBUser, c.DBPassword, c.DBName, c.DBPort)
+}
+
 func getEnv(key, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
 	}
 	return fallback
 }
+
+// getEnvInt mengembalikan -1 jika nilainya bukan angka (ditolak ValidateRequired)
+func getEnvInt(key string, fallback int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return -1
+	}
+	return n
+}
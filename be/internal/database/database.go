@@ -1,7 +1,6 @@
 package database
 
 import (
-	"fmt"
 	"koskosan-be/internal/config"
 	"koskosan-be/internal/models"
 	"log"
@@ -15,9 +14,7 @@ var DB *gorm.DB
 
 func InitDB(cfg *config.Config) {
 	var err error
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Jakarta",
-		cfg.DBHost, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBPort)
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	DB, err = gorm.Open(postgres.Open(cfg.DatabaseDSN()), &gorm.Config{})
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
@@ -52,8 +49,29 @@ func InitDB(cfg *config.Config) {
 
 		&models.Gallery{},
 		&models.KamarImage{},
+		&models.KamarPriceHistory{},
+		&models.RentChangeNotice{},
+		&models.Facility{},
+		&models.RoomType{},
+		&models.RoomTypeImage{},
+		&models.PricingRule{},
+		&models.BookingDiscount{},
 		&models.Review{},
 		&models.PaymentReminder{},
+		&models.PasswordResetToken{},
+		&models.Notification{},
+		&models.NotificationPreference{},
+		&models.NotificationSetting{},
+		&models.OutboxMessage{},
+		&models.InboundWhatsAppMessage{},
+		&models.MessageTemplate{},
+		&models.MaintenanceTicket{},
+		&models.MaintenanceTicketPhoto{},
+		&models.MaintenanceTicketComment{},
+		&models.ContactMessage{},
+		&models.ContactReply{},
+		&models.SpamSettings{},
+		&models.SpamRejection{},
 	)
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
@@ -22,6 +22,37 @@ func SeedData() {
 		log.Println("Admin user 'admin' with password 'admin123' ensured")
 	}
 
+	// Seed katalog fasilitas
+	var facilityCount int64
+	DB.Model(&models.Facility{}).Count(&facilityCount)
+	if facilityCount == 0 {
+		facilities := []models.Facility{
+			{Name: "AC", IconKey: "ac", Category: models.FacilityCategoryRoom},
+			{Name: "Lemari", IconKey: "wardrobe", Category: models.FacilityCategoryRoom},
+			{Name: "Meja Belajar", IconKey: "desk", Category: models.FacilityCategoryRoom},
+			{Name: "Kamar Mandi Dalam", IconKey: "bath", Category: models.FacilityCategoryBathroom},
+			{Name: "Kamar Mandi Luar", IconKey: "bath", Category: models.FacilityCategoryBathroom},
+			{Name: "Wi-Fi", IconKey: "wifi", Category: models.FacilityCategoryBuilding},
+			{Name: "Parkir Motor", IconKey: "parking", Category: models.FacilityCategoryBuilding},
+			{Name: "Laundry", IconKey: "laundry", Category: models.FacilityCategoryService},
+		}
+		DB.Create(&facilities)
+		log.Println("Facilities seeded")
+	}
+
+	// Seed tipe kamar
+	var roomTypeCount int64
+	DB.Model(&models.RoomType{}).Count(&roomTypeCount)
+	if roomTypeCount == 0 {
+		DB.Create(&models.RoomType{
+			Name: "Single", HargaPerBulan: 1500000, Size: "3x4m", Capacity: 1,
+			Description: "Kamar untuk satu orang dengan AC dan Wi-Fi",
+		})
+		log.Println("Room types seeded")
+	}
+	var single models.RoomType
+	DB.Where("name = ?", "Single").First(&single)
+
 	// Seed Kamar
 	var count int64
 	DB.Model(&models.Kamar{}).Count(&count)
@@ -29,14 +60,24 @@ func SeedData() {
 		kamars := []models.Kamar{
 			{
 				NomorKamar: "A1", TipeKamar: "Single", HargaPerBulan: 1500000, Status: "Tersedia",
-				Fasilitas: "AC, Wi-Fi, Kamar Mandi Dalam, Lemari, Meja Belajar",
+				RoomTypeID: &single.ID, Size: single.Size, Capacity: single.Capacity, Description: single.Description,
+				Fasilitas:  "AC, Kamar Mandi Dalam, Lemari, Meja Belajar, Wi-Fi",
+				Facilities: seedFacilities("AC", "Kamar Mandi Dalam", "Lemari", "Meja Belajar", "Wi-Fi"),
 			},
 			{
 				NomorKamar: "A2", TipeKamar: "Single", HargaPerBulan: 1200000, Status: "Penuh",
-				Fasilitas: "AC, Wi-Fi, Kamar Mandi Luar, Lemari",
+				RoomTypeID: &single.ID, OverridePrice: true, Size: single.Size, Capacity: single.Capacity, Description: single.Description,
+				Fasilitas:  "AC, Kamar Mandi Luar, Lemari, Wi-Fi",
+				Facilities: seedFacilities("AC", "Kamar Mandi Luar", "Lemari", "Wi-Fi"),
 			},
 		}
 		DB.Create(&kamars)
 		log.Println("Kamars seeded")
 	}
 }
+
+func seedFacilities(names ...string) []models.Facility {
+	var facilities []models.Facility
+	DB.Where("name IN ?", names).Find(&facilities)
+	return facilities
+}
@@ -12,12 +12,14 @@ import (
 type AuthHandler struct {
 	service service.AuthService
 	cfg     *config.Config
+	keys    *utils.KeySet
 }
 
-func NewAuthHandler(s service.AuthService, cfg *config.Config) *AuthHandler {
+func NewAuthHandler(s service.AuthService, cfg *config.Config, keys *utils.KeySet) *AuthHandler {
 	return &AuthHandler{
 		service: s,
 		cfg:     cfg,
+		keys:    keys,
 	}
 }
 
@@ -43,7 +45,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// Generate token pair for secure authentication
-	accessToken, refreshToken, err := utils.GenerateTokenPair(int(user.ID), user.Username, user.Role, h.cfg.JWTSecret)
+	accessToken, refreshToken, err := utils.GenerateTokenPair(int(user.ID), user.Username, user.Role, h.keys)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
 		return
@@ -52,8 +54,15 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	// Set secure HttpOnly cookies (XSS protection)
 	utils.SetAuthCookies(c, accessToken, refreshToken, h.cfg.IsProduction)
 
+	csrfToken, err := h.issueCSRFToken(c, int(user.ID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"user": user,
+		"user":       user,
+		"csrf_token": csrfToken,
 		// Token NOT returned in response for security
 	})
 }
@@ -134,7 +143,7 @@ func (h *AuthHandler) GoogleLogin(c *gin.Context) {
 	}
 
 	// Generate token pair
-	accessToken, refreshToken, err := utils.GenerateTokenPair(int(user.ID), user.Username, user.Role, h.cfg.JWTSecret)
+	accessToken, refreshToken, err := utils.GenerateTokenPair(int(user.ID), user.Username, user.Role, h.keys)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
 		return
@@ -143,8 +152,15 @@ func (h *AuthHandler) GoogleLogin(c *gin.Context) {
 	// Set secure HttpOnly cookies
 	utils.SetAuthCookies(c, accessToken, refreshToken, h.cfg.IsProduction)
 
+	csrfToken, err := h.issueCSRFToken(c, int(user.ID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"user": user,
+		"user":       user,
+		"csrf_token": csrfToken,
 	})
 }
 
@@ -160,7 +176,7 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 
 	// SECURITY FIX: Always return generic success message to prevent user enumeration
 	// Service will handle email sending if user exists, but won't reveal existence
-	_ = h.service.ForgotPassword(input.Email)
+	_ = h.service.ForgotPassword(input.Email, c.ClientIP())
 
 	// Always return success to prevent email enumeration attacks
 	c.JSON(http.StatusOK, gin.H{"message": "If the email is registered, a password reset link will be sent"})
@@ -200,14 +216,14 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	}
 
 	// Validate refresh token
-	claims, err := utils.ValidateRefreshToken(refreshToken, h.cfg.JWTSecret)
+	claims, err := utils.ValidateRefreshToken(refreshToken, h.keys)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
 		return
 	}
 
 	// Generate new access token
-	newAccessToken, err := utils.GenerateToken(claims.UserID, claims.Username, claims.Role, "access", h.cfg.JWTSecret, utils.AccessTokenExpiry)
+	newAccessToken, err := utils.GenerateToken(claims.UserID, claims.Username, claims.Role, "access", h.keys, utils.AccessTokenExpiry)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate new access token"})
 		return
@@ -225,13 +241,40 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		true,
 	)
 
-	c.JSON(http.StatusOK, gin.H{"message": "Token refreshed successfully"})
+	// Rotate the CSRF token together with the access token
+	csrfToken, err := h.issueCSRFToken(c, claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate new access token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Token refreshed successfully",
+		"csrf_token": csrfToken,
+	})
 }
 
 // Logout clears authentication cookies
 func (h *AuthHandler) Logout(c *gin.Context) {
 	// Clear all auth cookies
 	utils.ClearAuthCookies(c)
+	utils.ClearCSRFCookie(c)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
 }
+
+// issueCSRFToken sets a fresh double-submit CSRF cookie bound to the user and returns the token
+func (h *AuthHandler) issueCSRFToken(c *gin.Context, userID int) (string, error) {
+	token, err := utils.GenerateCSRFToken(userID, h.cfg.JWTSecret)
+	if err != nil {
+		return "", err
+	}
+	utils.SetCSRFCookie(c, token, h.cfg.IsProduction)
+	return token, nil
+}
+
+// JWKS publishes the public keys used to verify access and refresh tokens
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
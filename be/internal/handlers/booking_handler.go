@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"koskosan-be/internal/models"
 	"koskosan-be/internal/service"
 	"koskosan-be/internal/utils"
 	"net/http"
@@ -63,7 +65,8 @@ func (h *BookingHandler) CreateBooking(c *gin.Context) {
 	}
 
 	var req struct {
-		KamarID      uint   `json:"kamar_id" binding:"required"`
+		KamarID      uint   `json:"kamar_id"`
+		RoomTypeID   uint   `json:"room_type_id"` // alternatif kamar_id: kamar kosong dipilihkan otomatis
 		TanggalMulai string `json:"tanggal_mulai" binding:"required"`
 		DurasiSewa   int    `json:"durasi_sewa" binding:"required"`
 	}
@@ -73,7 +76,12 @@ func (h *BookingHandler) CreateBooking(c *gin.Context) {
 		return
 	}
 
-	booking, err := h.service.CreateBooking(userID, req.KamarID, req.TanggalMulai, req.DurasiSewa)
+	kamarID, ok := h.bookingKamarID(c, req.KamarID, req.RoomTypeID, req.TanggalMulai, req.DurasiSewa)
+	if !ok {
+		return
+	}
+
+	booking, err := h.service.CreateBooking(userID, kamarID, req.TanggalMulai, req.DurasiSewa)
 	if err != nil {
 		if err.Error() == "record not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Profile or Room not found. Please complete your profile first."})
@@ -111,10 +119,18 @@ func (h *BookingHandler) CreateBookingWithProof(c *gin.Context) {
 	durasiSewaStr := c.PostForm("durasi_sewa")
 	paymentType := c.PostForm("payment_type")
 	paymentMethod := c.PostForm("payment_method") // Added payment_method
+	voucherCode := c.PostForm("voucher_code")     // opsional
 
-	kamarID, _ := strconv.ParseUint(kamarIDStr, 10, 32)
+	kamarIDValue, _ := strconv.ParseUint(kamarIDStr, 10, 32)
+	roomTypeID, _ := strconv.ParseUint(c.PostForm("room_type_id"), 10, 32)
 	durasiSewa, _ := strconv.Atoi(durasiSewaStr)
 
+	// Kamar dipilih sebelum bukti diunggah supaya tidak ada upload sia-sia jika tipe penuh
+	kamarID, ok := h.bookingKamarID(c, uint(kamarIDValue), uint(roomTypeID), tanggalMulai, durasiSewa)
+	if !ok {
+		return
+	}
+
 	var proofURL string
 	file, err := c.FormFile("proof")
 
@@ -131,10 +147,10 @@ func (h *BookingHandler) CreateBookingWithProof(c *gin.Context) {
 		}
 
 		var errUpload error
-		proofURL, errUpload = utils.UploadToCloudinary(file, "proofs")
+		proofURL, errUpload = utils.UploadImage(file, "proofs")
 		if errUpload != nil {
 			utils.GlobalLogger.Error("Upload proof failed: %v", errUpload)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to upload proof: %v", errUpload)})
+			c.JSON(uploadErrorStatus(errUpload), gin.H{"error": fmt.Sprintf("Failed to upload proof: %v", errUpload)})
 			return
 		}
 	case "cash":
@@ -145,13 +161,50 @@ func (h *BookingHandler) CreateBookingWithProof(c *gin.Context) {
 		return
 	}
 
-	booking, err := h.service.CreateBookingWithProof(userID, uint(kamarID), tanggalMulai, durasiSewa, proofURL, paymentType, paymentMethod)
+	booking, err := h.service.CreateBookingWithProof(userID, kamarID, tanggalMulai, durasiSewa, proofURL, paymentType, paymentMethod, voucherCode)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidVoucher) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, booking)
+	var variants *utils.ImageVariants
+	if proofURL != "" {
+		v := utils.ImageVariantURLs(proofURL)
+		variants = &v
+	}
+	c.JSON(http.StatusCreated, bookingUploadResponse{booking, variants})
+}
+
+// bookingUploadResponse adalah Pemesanan ditambah URL varian bukti transfer (kosong untuk pembayaran tunai)
+type bookingUploadResponse struct {
+	*models.Pemesanan
+	Variants *utils.ImageVariants `json:"variants,omitempty"`
+}
+
+// bookingKamarID memakai kamar_id, atau memilihkan kamar kosong dari room_type_id
+func (h *BookingHandler) bookingKamarID(c *gin.Context, kamarID, roomTypeID uint, tanggalMulai string, durasiSewa int) (uint, bool) {
+	if kamarID != 0 {
+		return kamarID, true
+	}
+	if roomTypeID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kamar_id atau room_type_id wajib diisi"})
+		return 0, false
+	}
+
+	kamar, err := h.service.FindAvailableRoom(roomTypeID, tanggalMulai, durasiSewa)
+	if err != nil {
+		if errors.Is(err, service.ErrNoRoomAvailable) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return 0, false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return 0, false
+	}
+	return kamar.ID, true
 }
 
 func (h *BookingHandler) CancelBooking(c *gin.Context) {
@@ -223,6 +276,7 @@ func (h *BookingHandler) ExtendBooking(c *gin.Context) {
 	var req struct {
 		Months        int    `json:"months" binding:"required,min=1"`
 		PaymentMethod string `json:"payment_method" binding:"required"`
+		VoucherCode   string `json:"voucher_code"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -230,7 +284,7 @@ func (h *BookingHandler) ExtendBooking(c *gin.Context) {
 		return
 	}
 
-	payment, err := h.service.ExtendBooking(uint(id), req.Months, userID, req.PaymentMethod)
+	payment, err := h.service.ExtendBooking(uint(id), req.Months, userID, req.PaymentMethod, req.VoucherCode)
 	if err != nil {
 		if err.Error() == "unauthorized: you can only extend your own bookings" {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
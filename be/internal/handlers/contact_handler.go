@@ -1,10 +1,16 @@
 package handlers
 
 import (
+	"errors"
+	"io"
+	"koskosan-be/internal/models"
 	"koskosan-be/internal/service"
+	"koskosan-be/internal/utils"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type ContactHandler struct {
@@ -18,11 +24,12 @@ func NewContactHandler(s service.ContactService) *ContactHandler {
 }
 
 type ContactRequest struct {
-	Name    string `json:"name" binding:"required"`
+	Name    string `json:"name" binding:"required,max=100"`
 	Email   string `json:"email" binding:"required,email"`
-	Message string `json:"message" binding:"required"`
+	Message string `json:"message" binding:"required,max=5000"`
 }
 
+// HandleContactForm hanya menyimpan pesan; email ke admin dikirim worker outbox sehingga request tetap cepat
 func (h *ContactHandler) HandleContactForm(c *gin.Context) {
 	var req ContactRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -30,11 +37,172 @@ func (h *ContactHandler) HandleContactForm(c *gin.Context) {
 		return
 	}
 
-	err := h.contactService.SendContactMessage(req.Name, req.Email, req.Message)
+	_, err := h.contactService.SubmitMessage(service.ContactInput{
+		Name:      req.Name,
+		Email:     req.Email,
+		Message:   req.Message,
+		IPAddress: c.ClientIP(),
+	})
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidContactMessage) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		utils.GlobalLogger.Error("Failed to store contact message: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Message sent successfully"})
 }
+
+// --- Admin inbox ---
+
+// GetMessages GET /api/contact-messages?status=new&page=1&limit=10 (tanpa status = semua kecuali spam)
+func (h *ContactHandler) GetMessages(c *gin.Context) {
+	pagination := utils.GeneratePaginationFromRequest(c)
+
+	messages, totalRows, err := h.contactService.GetMessages(c.Query("status"), &pagination)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if messages == nil {
+		messages = []models.ContactMessage{}
+	}
+
+	pagination.TotalRows = totalRows
+	pagination.TotalPages = int((totalRows + int64(pagination.GetLimit()) - 1) / int64(pagination.GetLimit()))
+
+	c.JSON(http.StatusOK, utils.PaginatedResponse{
+		Data: messages,
+		Meta: pagination,
+	})
+}
+
+// GetUnreadCount GET /api/contact-messages/unread-count
+func (h *ContactHandler) GetUnreadCount(c *gin.Context) {
+	count, err := h.contactService.CountUnread()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"count": count})
+}
+
+// GetMessage GET /api/contact-messages/:id (menandai pesan sudah dibaca)
+func (h *ContactHandler) GetMessage(c *gin.Context) {
+	id, ok := contactMessageID(c)
+	if !ok {
+		return
+	}
+
+	message, err := h.contactService.GetMessage(id)
+	if err != nil {
+		respondContactError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": message})
+}
+
+// AssignMessage PUT /api/contact-messages/:id/assign {"assignee_id": 1}; tanpa assignee_id = admin yang login
+func (h *ContactHandler) AssignMessage(c *gin.Context) {
+	id, ok := contactMessageID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		AssigneeID uint `json:"assignee_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.AssigneeID == 0 {
+		req.AssigneeID, _ = currentUserID(c)
+	}
+
+	message, err := h.contactService.AssignMessage(id, req.AssigneeID)
+	if err != nil {
+		respondContactError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Pesan berhasil ditugaskan", "data": message})
+}
+
+// Reply POST /api/contact-messages/:id/reply {"subject": "...", "message": "..."}
+func (h *ContactHandler) Reply(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	id, ok := contactMessageID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Subject string `json:"subject" binding:"max=150"`
+		Message string `json:"message" binding:"required,max=5000"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reply, err := h.contactService.Reply(adminID, id, req.Subject, req.Message)
+	if err != nil {
+		respondContactError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Balasan terkirim", "data": reply})
+}
+
+// MarkSpam PUT /api/contact-messages/:id/spam {"spam": true}
+func (h *ContactHandler) MarkSpam(c *gin.Context) {
+	id, ok := contactMessageID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Spam *bool `json:"spam" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	message, err := h.contactService.MarkSpam(id, *req.Spam)
+	if err != nil {
+		respondContactError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": message})
+}
+
+func contactMessageID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func respondContactError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+	case errors.Is(err, service.ErrInvalidContactMessage):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrContactSpam):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrContactReplyFailed):
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FacilityHandler struct {
+	service service.FacilityService
+}
+
+func NewFacilityHandler(s service.FacilityService) *FacilityHandler {
+	return &FacilityHandler{service: s}
+}
+
+// GetFacilities GET /api/facilities (katalog untuk filter dan form kamar)
+func (h *FacilityHandler) GetFacilities(c *gin.Context) {
+	facilities, err := h.service.GetAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if facilities == nil {
+		facilities = []models.Facility{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": facilities, "categories": service.FacilityCategories})
+}
+
+// CreateFacility POST /api/facilities {"name": "Wi-Fi", "icon_key": "wifi", "category": "building"}
+func (h *FacilityHandler) CreateFacility(c *gin.Context) {
+	var input service.FacilityInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	facility, err := h.service.Create(input)
+	if err != nil {
+		respondFacilityCatalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Fasilitas ditambahkan", "data": facility})
+}
+
+// UpdateFacility PUT /api/facilities/:id
+func (h *FacilityHandler) UpdateFacility(c *gin.Context) {
+	id, ok := facilityID(c)
+	if !ok {
+		return
+	}
+
+	var input service.FacilityInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	facility, err := h.service.Update(id, input)
+	if err != nil {
+		respondFacilityCatalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Fasilitas diperbarui", "data": facility})
+}
+
+// DeleteFacility DELETE /api/facilities/:id (juga dilepas dari semua kamar)
+func (h *FacilityHandler) DeleteFacility(c *gin.Context) {
+	id, ok := facilityID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		respondFacilityCatalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Fasilitas dihapus"})
+}
+
+func facilityID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func respondFacilityCatalogError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrFacilityNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrFacilityExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidFacility):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"errors"
+	"koskosan-be/internal/utils"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FileHandler menyajikan file privat storage lokal lewat signed URL.
+// storage nil berarti provider lain (S3/Cloudinary) yang menandatangani URL-nya sendiri.
+type FileHandler struct {
+	storage *utils.LocalStorage
+}
+
+func NewFileHandler(storage *utils.LocalStorage) *FileHandler {
+	return &FileHandler{storage}
+}
+
+// ServeSignedFile GET /api/files/*key?expires=...&signature=...
+func (h *FileHandler) ServeSignedFile(c *gin.Context) {
+	if h.storage == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if err := h.storage.Verify(key, c.Query("expires"), c.Query("signature")); err != nil {
+		if errors.Is(err, utils.ErrSignedURLExpired) {
+			c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	fullPath, err := h.storage.Open(key)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.File(fullPath)
+}
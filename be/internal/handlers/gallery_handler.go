@@ -49,12 +49,12 @@ func (h *GalleryHandler) CreateGallery(c *gin.Context) {
 	}
 
 	var imageURL string
-	url, err := utils.UploadToCloudinary(file, "gallery")
+	url, err := utils.UploadImage(file, "gallery")
 	if err == nil {
 		imageURL = url
 	} else {
 		utils.GlobalLogger.Error("Upload failed: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to upload image: %v", err)})
+		c.JSON(uploadErrorStatus(err), gin.H{"error": fmt.Sprintf("Failed to upload image: %v", err)})
 		return
 	}
 
@@ -69,7 +69,13 @@ func (h *GalleryHandler) CreateGallery(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, gallery)
+	c.JSON(http.StatusCreated, galleryUploadResponse{gallery, utils.ImageVariantURLs(gallery.ImageURL)})
+}
+
+// galleryUploadResponse adalah Gallery ditambah URL varian (asli/medium/thumbnail) fotonya
+type galleryUploadResponse struct {
+	models.Gallery
+	Variants utils.ImageVariants `json:"variants"`
 }
 
 func (h *GalleryHandler) DeleteGallery(c *gin.Context) {
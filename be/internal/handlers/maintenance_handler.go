@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"koskosan-be/internal/service"
+	"koskosan-be/internal/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type MaintenanceHandler struct {
+	service service.MaintenanceService
+}
+
+func NewMaintenanceHandler(s service.MaintenanceService) *MaintenanceHandler {
+	return &MaintenanceHandler{s}
+}
+
+type ticketCommentRequest struct {
+	Message string `json:"message" binding:"required,max=2000"`
+}
+
+// --- Tenant ---
+
+// CreateTicket POST /api/tickets (multipart: kamar_id, title, description, category, priority, photos[])
+func (h *MaintenanceHandler) CreateTicket(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	var input service.CreateTicketInput
+	if err := c.ShouldBind(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var photoURLs []string
+	if form, err := c.MultipartForm(); err == nil {
+		files := form.File["photos"]
+		if len(files) > service.MaxTicketPhotos {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Maksimal %d foto", service.MaxTicketPhotos)})
+			return
+		}
+		for _, file := range files {
+			if !utils.IsImageFile(file) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file type. Only images are allowed."})
+				return
+			}
+		}
+		for _, file := range files {
+			url, err := utils.UploadImage(file, "tickets")
+			if err != nil {
+				utils.GlobalLogger.Error("Upload ticket photo failed: %v", err)
+				removeTicketPhotos(photoURLs)
+				c.JSON(uploadErrorStatus(err), gin.H{"error": fmt.Sprintf("Failed to upload photo: %v", err)})
+				return
+			}
+			photoURLs = append(photoURLs, url)
+		}
+	}
+
+	ticket, err := h.service.CreateTicket(userID, input, photoURLs)
+	if err != nil {
+		// Tiket ditolak (bukan kamar sewaan, data tidak valid, ...): foto yang sudah terunggah tidak dipakai
+		removeTicketPhotos(photoURLs)
+		respondTicketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Tiket berhasil dibuat", "data": ticket, "variants": utils.ImageVariantsOf(photoURLs)})
+}
+
+// removeTicketPhotos menghapus foto yang terunggah untuk tiket yang gagal dibuat
+func removeTicketPhotos(urls []string) {
+	for _, url := range urls {
+		if err := utils.DeleteUploadedFile(url); err != nil {
+			utils.GlobalLogger.Error("Failed to delete ticket photo %s: %v", url, err)
+		}
+	}
+}
+
+// GetMyTickets GET /api/tickets
+func (h *MaintenanceHandler) GetMyTickets(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	tickets, err := h.service.GetMyTickets(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if tickets == nil {
+		tickets = []models.MaintenanceTicket{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": tickets})
+}
+
+// GetMyTicket GET /api/tickets/:id
+func (h *MaintenanceHandler) GetMyTicket(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	id, ok := ticketID(c)
+	if !ok {
+		return
+	}
+
+	ticket, err := h.service.GetMyTicket(userID, id)
+	if err != nil {
+		respondTicketError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": ticket})
+}
+
+// AddMyComment POST /api/tickets/:id/comments
+func (h *MaintenanceHandler) AddMyComment(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	id, ok := ticketID(c)
+	if !ok {
+		return
+	}
+
+	var req ticketCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.service.AddTenantComment(userID, id, req.Message)
+	if err != nil {
+		respondTicketError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": comment})
+}
+
+// --- Admin ---
+
+// GetTickets GET /api/maintenance-tickets?status=&category=&priority=&kamar_id=&assigned_to=&page=&limit=
+func (h *MaintenanceHandler) GetTickets(c *gin.Context) {
+	pagination := utils.GeneratePaginationFromRequest(c)
+	filter := repository.MaintenanceTicketFilter{
+		Status:   c.Query("status"),
+		Category: c.Query("category"),
+		Priority: c.Query("priority"),
+	}
+	if kamarID, err := strconv.ParseUint(c.Query("kamar_id"), 10, 32); err == nil {
+		filter.KamarID = uint(kamarID)
+	}
+	if assignedTo := c.Query("assigned_to"); assignedTo == "me" {
+		filter.AssignedTo, _ = currentUserID(c)
+	} else if id, err := strconv.ParseUint(assignedTo, 10, 32); err == nil {
+		filter.AssignedTo = uint(id)
+	}
+
+	tickets, totalRows, err := h.service.GetTickets(filter, &pagination)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if tickets == nil {
+		tickets = []models.MaintenanceTicket{}
+	}
+
+	pagination.TotalRows = totalRows
+	pagination.TotalPages = int((totalRows + int64(pagination.GetLimit()) - 1) / int64(pagination.GetLimit()))
+
+	c.JSON(http.StatusOK, utils.PaginatedResponse{
+		Data: tickets,
+		Meta: pagination,
+	})
+}
+
+// GetTicket GET /api/maintenance-tickets/:id
+func (h *MaintenanceHandler) GetTicket(c *gin.Context) {
+	id, ok := ticketID(c)
+	if !ok {
+		return
+	}
+
+	ticket, err := h.service.GetTicket(id)
+	if err != nil {
+		respondTicketError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": ticket})
+}
+
+// AssignTicket PUT /api/maintenance-tickets/:id/assign {"assignee_id": 1}; tanpa assignee_id = admin yang login
+func (h *MaintenanceHandler) AssignTicket(c *gin.Context) {
+	id, ok := ticketID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		AssigneeID uint `json:"assignee_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.AssigneeID == 0 {
+		req.AssigneeID, _ = currentUserID(c)
+	}
+
+	ticket, err := h.service.AssignTicket(id, req.AssigneeID)
+	if err != nil {
+		respondTicketError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Tiket berhasil ditugaskan", "data": ticket})
+}
+
+// AddComment POST /api/maintenance-tickets/:id/comments
+func (h *MaintenanceHandler) AddComment(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	id, ok := ticketID(c)
+	if !ok {
+		return
+	}
+
+	var req ticketCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.service.AddAdminComment(adminID, id, req.Message)
+	if err != nil {
+		respondTicketError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": comment})
+}
+
+// UpdateStatus PUT /api/maintenance-tickets/:id/status {"status": "resolved", "note": "..."}
+func (h *MaintenanceHandler) UpdateStatus(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	id, ok := ticketID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Status string `json:"status" binding:"required"`
+		Note   string `json:"note" binding:"max=2000"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ticket, err := h.service.UpdateStatus(adminID, id, req.Status, req.Note)
+	if err != nil {
+		respondTicketError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Status tiket diperbarui", "data": ticket})
+}
+
+func ticketID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func respondTicketError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+	case errors.Is(err, service.ErrTicketForbidden), errors.Is(err, service.ErrTicketNotRented):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTicketClosed), errors.Is(err, service.ErrInvalidTicketStatus):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidTicket):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "profile not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"koskosan-be/internal/service"
+	"koskosan-be/internal/templates"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MessageTemplateHandler struct {
+	service service.MessageTemplateService
+}
+
+func NewMessageTemplateHandler(s service.MessageTemplateService) *MessageTemplateHandler {
+	return &MessageTemplateHandler{s}
+}
+
+// GetTemplates GET /api/message-templates
+func (h *MessageTemplateHandler) GetTemplates(c *gin.Context) {
+	entries, err := h.service.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": entries, "languages": templates.Languages})
+}
+
+// UpdateTemplate PUT /api/message-templates/:name/:channel/:language
+func (h *MessageTemplateHandler) UpdateTemplate(c *gin.Context) {
+	var input templates.Source
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	adminID, _ := currentUserID(c)
+
+	entry, err := h.service.Update(c.Param("name"), c.Param("channel"), c.Param("language"), input, adminID)
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Template berhasil disimpan", "data": entry})
+}
+
+// ResetTemplate DELETE /api/message-templates/:name/:channel/:language
+func (h *MessageTemplateHandler) ResetTemplate(c *gin.Context) {
+	entry, err := h.service.Reset(c.Param("name"), c.Param("channel"), c.Param("language"))
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Template dikembalikan ke bawaan", "data": entry})
+}
+
+// PreviewTemplate POST /api/message-templates/:name/:channel/:language/preview
+// Body kosong = preview template yang sedang berlaku; {subject, body} = preview hasil edit sebelum disimpan.
+func (h *MessageTemplateHandler) PreviewTemplate(c *gin.Context) {
+	var src *templates.Source
+	if c.Request.ContentLength > 0 {
+		var input templates.Source
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		src = &input
+	}
+
+	msg, err := h.service.Preview(c.Param("name"), c.Param("channel"), c.Param("language"), src)
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, msg)
+}
+
+func respondTemplateError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrMessageTemplateNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	// Selain not found, error berasal dari parse/render template yang diedit admin
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
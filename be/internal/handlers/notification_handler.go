@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/service"
+	"koskosan-be/internal/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Interval komentar keep-alive supaya proxy tidak memutus stream yang idle
+const sseKeepAliveInterval = 25 * time.Second
+
+type NotificationHandler struct {
+	service     service.NotificationService
+	prefService service.NotificationPreferenceService
+	hub         *utils.EventHub
+}
+
+func NewNotificationHandler(s service.NotificationService, prefService service.NotificationPreferenceService, hub *utils.EventHub) *NotificationHandler {
+	return &NotificationHandler{s, prefService, hub}
+}
+
+// GetNotifications GET /api/notifications?page=&limit=&unread=true
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	pagination := utils.GeneratePaginationFromRequest(c)
+	unreadOnly := c.Query("unread") == "true"
+
+	notifications, totalRows, err := h.service.GetNotifications(userID, &pagination, unreadOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if notifications == nil {
+		notifications = []models.Notification{}
+	}
+
+	unread, err := h.service.CountUnread(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	pagination.TotalRows = totalRows
+	pagination.TotalPages = int((totalRows + int64(pagination.GetLimit()) - 1) / int64(pagination.GetLimit()))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":         notifications,
+		"meta":         pagination,
+		"unread_count": unread,
+	})
+}
+
+// GetUnreadCount GET /api/notifications/unread-count
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	unread, err := h.service.CountUnread(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"unread_count": unread})
+}
+
+// MarkRead PUT /api/notifications/:id/read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	if err := h.service.MarkRead(uint(id), userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+// MarkAllRead PUT /api/notifications/read-all
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	updated, err := h.service.MarkAllRead(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "All notifications marked as read", "updated": updated})
+}
+
+// GetPreferences GET /api/profile/notification-preferences
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	prefs, err := h.prefService.GetPreferences(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, prefs)
+}
+
+// UpdatePreferences PUT /api/profile/notification-preferences
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	var input service.NotificationPreferences
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	prefs, err := h.prefService.UpdatePreferences(userID, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, prefs)
+}
+
+// Stream GET /api/notifications/stream
+// Fallback Server-Sent Events untuk client yang tidak bisa memakai socket.io.
+// Event yang terlewat di-replay dari header Last-Event-ID (atau query ?last_event_id=).
+func (h *NotificationHandler) Stream(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+	role, _ := c.Get("role")
+	isAdmin := role == "admin"
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	sub, missed := h.hub.Subscribe(userID, isAdmin, lastEventID)
+	defer h.hub.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // nginx: jangan buffer response
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	fmt.Fprintf(w, "retry: 5000\n\n")
+	for _, event := range missed {
+		writeSSEEvent(w, event)
+	}
+	w.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event := <-sub.C:
+			writeSSEEvent(w, event)
+			w.Flush()
+		case <-keepAlive.C:
+			fmt.Fprintf(w, ": ping\n\n")
+			w.Flush()
+		}
+	}
+}
+
+func writeSSEEvent(w io.Writer, event utils.DomainEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
+
+// currentUserID membaca user_id yang diset AuthMiddleware
+func currentUserID(c *gin.Context) (uint, bool) {
+	userIDRaw, exists := c.Get("user_id")
+	if !exists {
+		return 0, false
+	}
+	switch v := userIDRaw.(type) {
+	case float64:
+		return uint(v), true
+	case int:
+		return uint(v), true
+	case uint:
+		return v, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/service"
+	"koskosan-be/internal/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type OutboxHandler struct {
+	service service.OutboxService
+}
+
+func NewOutboxHandler(s service.OutboxService) *OutboxHandler {
+	return &OutboxHandler{s}
+}
+
+// GetDeliveries GET /api/notification-deliveries?status=failed&page=&limit=
+func (h *OutboxHandler) GetDeliveries(c *gin.Context) {
+	pagination := utils.GeneratePaginationFromRequest(c)
+	status := c.DefaultQuery("status", models.OutboxStatusFailed)
+	if status == "all" {
+		status = ""
+	}
+
+	messages, totalRows, err := h.service.GetDeliveries(status, &pagination)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if messages == nil {
+		messages = []models.OutboxMessage{}
+	}
+
+	pagination.TotalRows = totalRows
+	pagination.TotalPages = int((totalRows + int64(pagination.GetLimit()) - 1) / int64(pagination.GetLimit()))
+
+	c.JSON(http.StatusOK, utils.PaginatedResponse{
+		Data: messages,
+		Meta: pagination,
+	})
+}
+
+// Resend POST /api/notification-deliveries/:id/resend
+func (h *OutboxHandler) Resend(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	msg, err := h.service.Resend(uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		case errors.Is(err, service.ErrOutboxAlreadySent):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pesan dijadwalkan untuk dikirim ulang", "data": msg})
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"koskosan-be/internal/models"
 	"koskosan-be/internal/service"
@@ -68,6 +69,7 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 	var req struct {
 		PemesananID uint   `json:"pemesanan_id" binding:"required"`
 		PaymentType string `json:"payment_type" binding:"required"` // "full" atau "dp"
+		VoucherCode string `json:"voucher_code"`                    // opsional
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -81,8 +83,12 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 		return
 	}
 
-	payment, err := h.service.CreatePaymentSession(req.PemesananID, req.PaymentType)
+	payment, err := h.service.CreatePaymentSession(req.PemesananID, req.PaymentType, req.VoucherCode)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidVoucher) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -118,12 +124,12 @@ func (h *PaymentHandler) UploadPaymentProof(c *gin.Context) {
 	}
 
 	var proofURL string
-	url, err := utils.UploadToCloudinary(file, "proofs")
+	url, err := utils.UploadImage(file, "proofs")
 	if err == nil {
 		proofURL = url
 	} else {
 		utils.GlobalLogger.Error("Upload proof failed: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to upload proof: %v", err)})
+		c.JSON(uploadErrorStatus(err), gin.H{"error": fmt.Sprintf("Failed to upload proof: %v", err)})
 		return
 	}
 
@@ -151,16 +157,70 @@ func (h *PaymentHandler) UploadPaymentProof(c *gin.Context) {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
 		}
+		if errors.Is(err, service.ErrInvalidVoucher) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Bukti transfer disimpan privat; klien menerima signed URL sementara
+	signedURL, err := utils.FileURL(proofURL)
+	if err != nil {
+		utils.GlobalLogger.Error("Failed to sign proof URL: %v", err)
+	}
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Payment proof uploaded successfully",
-		"url":     proofURL,
+		"message":  "Payment proof uploaded successfully",
+		"url":      signedURL,
+		"variants": utils.ImageVariantURLs(proofURL),
 	})
 }
 
+// GetPaymentProof mengembalikan signed URL bukti transfer untuk penyewa pemilik pembayaran atau admin
+func (h *PaymentHandler) GetPaymentProof(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	role, _ := c.Get("role")
+
+	ref, err := h.service.GetPaymentProof(uint(id), userID, role == "admin")
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPaymentProofForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrPaymentProofNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+		}
+		return
+	}
+
+	signedURL, err := utils.FileURL(ref)
+	if err != nil {
+		utils.GlobalLogger.Error("Failed to sign proof URL: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create proof URL"})
+		return
+	}
+	resp := gin.H{
+		"url":      signedURL,
+		"variants": utils.ImageVariantURLs(ref),
+	}
+	if utils.IsPrivateRef(ref) {
+		resp["expires_at"] = time.Now().Add(utils.SignedURLTTL())
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.JSON(http.StatusOK, resp)
+}
+
 // generateUUID creates a unique identifier for file naming
 func generateUUID() string {
 	// Simple UUID generation (you could use github.com/google/uuid for production)
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PriceHistoryHandler struct {
+	service service.PriceHistoryService
+}
+
+func NewPriceHistoryHandler(s service.PriceHistoryService) *PriceHistoryHandler {
+	return &PriceHistoryHandler{service: s}
+}
+
+// GetPriceHistory GET /api/kamar/:id/price-history (admin)
+func (h *PriceHistoryHandler) GetPriceHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	history, err := h.service.GetHistory(uint(id))
+	if err != nil {
+		respondPriceHistoryError(c, err)
+		return
+	}
+	if history == nil {
+		history = []models.KamarPriceHistory{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": history, "notice_days": h.service.NoticeDays()})
+}
+
+// SchedulePriceChange POST /api/kamar/:id/price-changes {"harga_per_bulan": 1650000, "effective_from": "2026-12-01", "note": "..."}
+func (h *PriceHistoryHandler) SchedulePriceChange(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var input service.PriceChangeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	change, err := h.service.ScheduleChange(uint(id), input)
+	if err != nil {
+		respondPriceHistoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Perubahan harga dijadwalkan", "data": change})
+}
+
+// CancelPriceChange DELETE /api/kamar/:id/price-changes/:changeId (hanya yang belum berlaku)
+func (h *PriceHistoryHandler) CancelPriceChange(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	changeID, err := strconv.ParseUint(c.Param("changeId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := h.service.CancelChange(uint(id), uint(changeID)); err != nil {
+		respondPriceHistoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Perubahan harga dibatalkan"})
+}
+
+func respondPriceHistoryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kamar tidak ditemukan"})
+	case errors.Is(err, service.ErrPriceChangeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidPriceChange):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrPriceChangeApplied):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PricingHandler struct {
+	service service.PricingService
+}
+
+func NewPricingHandler(s service.PricingService) *PricingHandler {
+	return &PricingHandler{service: s}
+}
+
+// GetQuote GET /api/pricing/quote?kamar_id=1&tanggal_mulai=2026-11-01&durasi_sewa=6&voucher_code=HEMAT
+func (h *PricingHandler) GetQuote(c *gin.Context) {
+	kamarID, err := strconv.ParseUint(c.Query("kamar_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kamar_id wajib diisi"})
+		return
+	}
+	durasiSewa, err := strconv.Atoi(c.Query("durasi_sewa"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "durasi_sewa wajib diisi"})
+		return
+	}
+
+	quote, err := h.service.QuoteRoom(uint(kamarID), c.Query("tanggal_mulai"), durasiSewa, c.Query("voucher_code"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Kamar tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, quote)
+}
+
+// GetPricingRules GET /api/pricing-rules (admin)
+func (h *PricingHandler) GetPricingRules(c *gin.Context) {
+	rules, err := h.service.GetRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if rules == nil {
+		rules = []models.PricingRule{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rules})
+}
+
+// CreatePricingRule POST /api/pricing-rules {"name": "Sewa 6 bulan", "type": "long_stay", "discount_percent": 5, "min_months": 6}
+func (h *PricingHandler) CreatePricingRule(c *gin.Context) {
+	var input service.PricingRuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.service.CreateRule(input)
+	if err != nil {
+		respondPricingError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Aturan harga ditambahkan", "data": rule})
+}
+
+// UpdatePricingRule PUT /api/pricing-rules/:id (diskon yang sudah tercatat tidak berubah)
+func (h *PricingHandler) UpdatePricingRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var input service.PricingRuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.service.UpdateRule(uint(id), input)
+	if err != nil {
+		respondPricingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Aturan harga diperbarui", "data": rule})
+}
+
+// DeletePricingRule DELETE /api/pricing-rules/:id
+func (h *PricingHandler) DeletePricingRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := h.service.DeleteRule(uint(id)); err != nil {
+		respondPricingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Aturan harga dihapus"})
+}
+
+func respondPricingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrPricingRuleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidPricingRule):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
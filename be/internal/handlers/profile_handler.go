@@ -94,10 +94,10 @@ func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
 		file, err := c.FormFile("foto_profil")
 		if err == nil {
 			if utils.IsImageFile(file) {
-				url, err := utils.UploadToCloudinary(file, "profiles")
+				url, err := utils.UploadImage(file, "profiles")
 				if err != nil {
 					utils.GlobalLogger.Error("Failed to upload profile photo: %v", err)
-					c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to upload profile photo: %v", err)})
+					c.JSON(uploadErrorStatus(err), gin.H{"error": fmt.Sprintf("Failed to upload profile photo: %v", err)})
 					return
 				}
 				input.FotoProfil = url
@@ -118,8 +118,9 @@ func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "profile updated successfully",
-		"penyewa": penyewa,
+		"message":  "profile updated successfully",
+		"penyewa":  penyewa,
+		"variants": utils.ImageVariantURLs(penyewa.FotoProfil),
 	})
 }
 
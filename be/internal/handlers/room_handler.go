@@ -1,14 +1,19 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
 	"koskosan-be/internal/service"
 	"koskosan-be/internal/utils"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type KamarHandler struct {
@@ -19,16 +24,153 @@ func NewKamarHandler(s service.KamarService) *KamarHandler {
 	return &KamarHandler{service: s}
 }
 
+// GetKamars GET /api/kamar?tipe_kamar=Single&room_type_id=1&min_price=1000000&max_price=2000000&floor=2&capacity=1
+// &status=Tersedia&fasilitas=AC,Wi-Fi&facility_ids=1,3&available_from=2026-11-01&available_to=2027-02-01&sort=price_asc&page=1&limit=10
 func (h *KamarHandler) GetKamars(c *gin.Context) {
-	kamars, err := h.service.GetAll()
+	filter, err := kamarFilterFromQuery(c)
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	pagination := utils.GeneratePaginationFromRequest(c)
+
+	kamars, totalRows, err := h.service.Search(filter, &pagination)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidKamarFilter) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	if kamars == nil {
 		kamars = []models.Kamar{}
 	}
-	c.JSON(http.StatusOK, kamars)
+
+	pagination.TotalRows = totalRows
+	pagination.TotalPages = int((totalRows + int64(pagination.GetLimit()) - 1) / int64(pagination.GetLimit()))
+
+	c.JSON(http.StatusOK, utils.PaginatedResponse{
+		Data: kamars,
+		Meta: pagination,
+	})
+}
+
+// facilityIDsFromForm membaca facility_ids=1,2 atau facility_ids=1&facility_ids=2; ok = field dikirim
+func facilityIDsFromForm(c *gin.Context) ([]uint, bool, error) {
+	values, ok := c.GetPostFormArray("facility_ids")
+	ids, err := parseIDList(values)
+	if err != nil {
+		return nil, ok, errors.New("facility_ids harus berupa daftar ID angka")
+	}
+	return ids, ok, nil
+}
+
+func parseIDList(values []string) ([]uint, error) {
+	var ids []uint
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			id, err := strconv.ParseUint(part, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids, nil
+}
+
+func respondFacilityError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrFacilityNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// kamarOverrideFlags memetakan field form kamar ke flag Override* yang menandai nilai milik kamar sendiri
+func kamarOverrideFlags(kamar *models.Kamar) map[string]*bool {
+	return map[string]*bool{
+		"harga_per_bulan": &kamar.OverridePrice,
+		"size":            &kamar.OverrideSize,
+		"capacity":        &kamar.OverrideCapacity,
+		"description":     &kamar.OverrideDescription,
+	}
+}
+
+func respondKamarRoomTypeError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrRoomTypeNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+func kamarFilterFromQuery(c *gin.Context) (repository.KamarFilter, error) {
+	filter := repository.KamarFilter{
+		TipeKamar: c.Query("tipe_kamar"),
+		Status:    c.Query("status"),
+		Sort:      c.Query("sort"),
+	}
+
+	var err error
+	if v := c.Query("room_type_id"); v != "" {
+		roomTypeID, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return filter, errors.New("room_type_id harus berupa angka")
+		}
+		filter.RoomTypeID = uint(roomTypeID)
+	}
+	if v := c.Query("min_price"); v != "" {
+		if filter.MinPrice, err = strconv.ParseFloat(v, 64); err != nil {
+			return filter, errors.New("min_price harus berupa angka")
+		}
+	}
+	if v := c.Query("max_price"); v != "" {
+		if filter.MaxPrice, err = strconv.ParseFloat(v, 64); err != nil {
+			return filter, errors.New("max_price harus berupa angka")
+		}
+	}
+	if v := c.Query("floor"); v != "" {
+		if filter.Floor, err = strconv.Atoi(v); err != nil {
+			return filter, errors.New("floor harus berupa angka")
+		}
+	}
+	if v := c.Query("capacity"); v != "" {
+		if filter.MinCapacity, err = strconv.Atoi(v); err != nil {
+			return filter, errors.New("capacity harus berupa angka")
+		}
+	}
+
+	// fasilitas=AC,Wi-Fi atau fasilitas=AC&fasilitas=Wi-Fi
+	for _, value := range c.QueryArray("fasilitas") {
+		for _, facility := range strings.Split(value, ",") {
+			if facility = strings.TrimSpace(facility); facility != "" {
+				filter.Facilities = append(filter.Facilities, facility)
+			}
+		}
+	}
+
+	facilityIDs, err := parseIDList(c.QueryArray("facility_ids"))
+	if err != nil {
+		return filter, errors.New("facility_ids harus berupa daftar ID angka")
+	}
+	filter.FacilityIDs = facilityIDs
+
+	if v := c.Query("available_from"); v != "" {
+		if filter.AvailableFrom, err = time.ParseInLocation("2006-01-02", v, time.Local); err != nil {
+			return filter, errors.New("available_from harus berformat YYYY-MM-DD")
+		}
+	}
+	if v := c.Query("available_to"); v != "" {
+		if filter.AvailableTo, err = time.ParseInLocation("2006-01-02", v, time.Local); err != nil {
+			return filter, errors.New("available_to harus berformat YYYY-MM-DD")
+		}
+	}
+	return filter, nil
 }
 
 func (h *KamarHandler) GetKamarByID(c *gin.Context) {
@@ -62,7 +204,47 @@ func (h *KamarHandler) CreateKamar(c *gin.Context) {
 	bedrooms, _ := strconv.Atoi(c.PostForm("bedrooms"))
 	bathrooms, _ := strconv.Atoi(c.PostForm("bathrooms"))
 
-	// Cloudinary check removed
+	facilityIDs, _, err := facilityIDsFromForm(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	facilities, err := h.service.ResolveFacilities(facilityIDs, service.SplitFacilityNames(fasilitas))
+	if err != nil {
+		respondFacilityError(c, err)
+		return
+	}
+
+	kamar := models.Kamar{
+		NomorKamar:    nomorKamar,
+		TipeKamar:     tipeKamar,
+		Fasilitas:     service.FacilityNames(facilities),
+		Facilities:    facilities,
+		HargaPerBulan: harga,
+		Status:        status,
+		Capacity:      capacity,
+		Floor:         floor,
+		Size:          size,
+		Bedrooms:      bedrooms,
+		Bathrooms:     bathrooms,
+		Description:   description,
+	}
+
+	// room_type_id: field yang tidak dikirim mengikuti nilai bawaan tipe
+	if v := c.PostForm("room_type_id"); v != "" {
+		roomTypeID, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "room_type_id harus berupa angka"})
+			return
+		}
+		for field, flag := range kamarOverrideFlags(&kamar) {
+			*flag = c.PostForm(field) != ""
+		}
+		if err := h.service.AssignRoomType(&kamar, uint(roomTypeID)); err != nil {
+			respondKamarRoomTypeError(c, err)
+			return
+		}
+	}
 
 	// Parse multipart form to get multiple files
 	form, err := c.MultipartForm()
@@ -71,8 +253,10 @@ func (h *KamarHandler) CreateKamar(c *gin.Context) {
 		return
 	}
 
+	// Kamar bertipe boleh tanpa foto sendiri jika tipenya sudah punya foto
 	imageFiles := form.File["images"]
-	if len(imageFiles) < 3 {
+	useRoomTypeImages := len(imageFiles) == 0 && kamar.ImageURL != ""
+	if len(imageFiles) < 3 && !useRoomTypeImages {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Minimal 3 foto kamar diperlukan"})
 		return
 	}
@@ -85,31 +269,18 @@ func (h *KamarHandler) CreateKamar(c *gin.Context) {
 			return
 		}
 
-		url, err := utils.UploadToCloudinary(fileHeader, "rooms")
+		url, err := utils.UploadImage(fileHeader, "rooms")
 		if err != nil {
 			utils.GlobalLogger.Error("Failed to upload image: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to upload image: %v", err)})
+			c.JSON(uploadErrorStatus(err), gin.H{"error": fmt.Sprintf("Failed to upload image: %v", err)})
 			return
 		}
 		uploadedURLs = append(uploadedURLs, url)
 	}
 
 	// First image is the main image_url
-	mainImageURL := uploadedURLs[0]
-
-	kamar := models.Kamar{
-		NomorKamar:    nomorKamar,
-		TipeKamar:     tipeKamar,
-		Fasilitas:     fasilitas,
-		HargaPerBulan: harga,
-		Status:        status,
-		Capacity:      capacity,
-		Floor:         floor,
-		Size:          size,
-		Bedrooms:      bedrooms,
-		Bathrooms:     bathrooms,
-		Description:   description,
-		ImageURL:      mainImageURL,
+	if len(uploadedURLs) > 0 {
+		kamar.ImageURL = uploadedURLs[0]
 	}
 
 	if err := h.service.Create(&kamar); err != nil {
@@ -118,10 +289,11 @@ func (h *KamarHandler) CreateKamar(c *gin.Context) {
 	}
 
 	// Save all images to kamar_images table
-	for _, url := range uploadedURLs {
+	for i, url := range uploadedURLs {
 		img := models.KamarImage{
-			KamarID:  kamar.ID,
-			ImageURL: url,
+			KamarID:   kamar.ID,
+			ImageURL:  url,
+			SortOrder: i,
 		}
 		if err := h.service.AddImage(&img); err != nil {
 			utils.GlobalLogger.Error("Failed to save kamar image: %v", err)
@@ -130,11 +302,10 @@ func (h *KamarHandler) CreateKamar(c *gin.Context) {
 
 	// Reload with images
 	kamarWithImages, _ := h.service.GetByID(kamar.ID)
-	if kamarWithImages != nil {
-		c.JSON(http.StatusCreated, kamarWithImages)
-	} else {
-		c.JSON(http.StatusCreated, kamar)
+	if kamarWithImages == nil {
+		kamarWithImages = &kamar
 	}
+	c.JSON(http.StatusCreated, kamarUploadResponse{kamarWithImages, utils.ImageVariantsOf(uploadedURLs)})
 }
 
 func (h *KamarHandler) UpdateKamar(c *gin.Context) {
@@ -182,7 +353,65 @@ func (h *KamarHandler) UpdateKamar(c *gin.Context) {
 		kamar.Bathrooms, _ = strconv.Atoi(v)
 	}
 
+	// room_type_id kosong/0 melepas kamar dari tipenya; field yang dikirim menjadi override,
+	// inherit=harga_per_bulan,size mengembalikan field ke nilai bawaan tipe
+	if v, ok := c.GetPostForm("room_type_id"); ok {
+		if v == "" || v == "0" {
+			kamar.RoomTypeID = nil
+			kamar.RoomType = nil
+		} else {
+			roomTypeID, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "room_type_id harus berupa angka"})
+				return
+			}
+			id := uint(roomTypeID)
+			kamar.RoomTypeID = &id
+		}
+	}
+	overrideFlags := kamarOverrideFlags(kamar)
+	for field, flag := range overrideFlags {
+		if c.PostForm(field) != "" {
+			*flag = true
+		}
+	}
+	for _, field := range strings.Split(c.PostForm("inherit"), ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		flag, ok := overrideFlags[field]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "inherit hanya boleh berisi harga_per_bulan, size, capacity atau description"})
+			return
+		}
+		*flag = false
+	}
+	if kamar.RoomTypeID != nil {
+		if err := h.service.AssignRoomType(kamar, *kamar.RoomTypeID); err != nil {
+			respondKamarRoomTypeError(c, err)
+			return
+		}
+	}
+
+	// facility_ids (atau teks fasilitas dari klien lama) mengganti seluruh fasilitas kamar
+	facilityIDs, hasFacilityIDs, err := facilityIDsFromForm(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var facilities []models.Facility
+	replaceFacilities := hasFacilityIDs || c.PostForm("fasilitas") != ""
+	if replaceFacilities {
+		facilities, err = h.service.ResolveFacilities(facilityIDs, service.SplitFacilityNames(c.PostForm("fasilitas")))
+		if err != nil {
+			respondFacilityError(c, err)
+			return
+		}
+	}
+
 	// Check for new multi-image upload
+	var replacedImages []models.KamarImage
+	var uploadedURLs []string
 	form, err := c.MultipartForm()
 	if err == nil {
 		imageFiles := form.File["images"]
@@ -192,32 +421,33 @@ func (h *KamarHandler) UpdateKamar(c *gin.Context) {
 				return
 			}
 
-			var uploadedURLs []string
 			for _, fileHeader := range imageFiles {
 				if !utils.IsImageFile(fileHeader) {
 					c.JSON(http.StatusBadRequest, gin.H{"error": "Semua file harus berupa gambar"})
 					return
 				}
-				url, err := utils.UploadToCloudinary(fileHeader, "rooms")
+				url, err := utils.UploadImage(fileHeader, "rooms")
 				if err != nil {
 					utils.GlobalLogger.Error("Failed to upload image: %v", err)
-					c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to upload image: %v", err)})
+					c.JSON(uploadErrorStatus(err), gin.H{"error": fmt.Sprintf("Failed to upload image: %v", err)})
 					return
 				}
 				uploadedURLs = append(uploadedURLs, url)
 			}
 
-			// Delete old images
+			// Delete old images (file-nya dibersihkan setelah kamar tersimpan)
+			replacedImages = kamar.Images
 			_ = h.service.DeleteImagesByKamarID(uint(id))
 
 			// Update main image
 			kamar.ImageURL = uploadedURLs[0]
 
 			// Save new images
-			for _, url := range uploadedURLs {
+			for i, url := range uploadedURLs {
 				img := models.KamarImage{
-					KamarID:  uint(id),
-					ImageURL: url,
+					KamarID:   uint(id),
+					ImageURL:  url,
+					SortOrder: i,
 				}
 				_ = h.service.AddImage(&img)
 			}
@@ -228,26 +458,199 @@ func (h *KamarHandler) UpdateKamar(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if replaceFacilities {
+		if err := h.service.ReplaceFacilities(kamar.ID, facilities); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	for _, image := range replacedImages {
+		removeKamarImageFile(image)
+	}
 
 	// Reload with images
 	kamarWithImages, _ := h.service.GetByID(uint(id))
-	if kamarWithImages != nil {
-		c.JSON(http.StatusOK, kamarWithImages)
-	} else {
-		c.JSON(http.StatusOK, kamar)
+	if kamarWithImages == nil {
+		kamarWithImages = kamar
+	}
+	c.JSON(http.StatusOK, kamarUploadResponse{kamarWithImages, utils.ImageVariantsOf(uploadedURLs)})
+}
+
+// UploadKamarImages POST /api/kamar/:id/images (multipart, field "images"; ditambahkan di akhir urutan)
+func (h *KamarHandler) UploadKamarImages(c *gin.Context) {
+	id, ok := kamarID(c)
+	if !ok {
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to parse form"})
+		return
+	}
+	imageFiles := form.File["images"]
+	if len(imageFiles) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Minimal 1 foto diperlukan"})
+		return
+	}
+	if _, err := h.service.GetByID(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kamar not found"})
+		return
+	}
+
+	var uploadedURLs []string
+	for _, fileHeader := range imageFiles {
+		if !utils.IsImageFile(fileHeader) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Semua file harus berupa gambar"})
+			return
+		}
+		url, err := utils.UploadImage(fileHeader, "rooms")
+		if err != nil {
+			utils.GlobalLogger.Error("Failed to upload image: %v", err)
+			c.JSON(uploadErrorStatus(err), gin.H{"error": fmt.Sprintf("Failed to upload image: %v", err)})
+			return
+		}
+		uploadedURLs = append(uploadedURLs, url)
+	}
+
+	kamar, err := h.service.AddImages(id, uploadedURLs)
+	if err != nil {
+		respondKamarImageError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Foto kamar ditambahkan", "data": kamar, "variants": utils.ImageVariantsOf(uploadedURLs)})
+}
+
+// DeleteKamarImage DELETE /api/kamar/:id/images/:imageId (file lokal/Cloudinary ikut dihapus)
+func (h *KamarHandler) DeleteKamarImage(c *gin.Context) {
+	id, ok := kamarID(c)
+	if !ok {
+		return
+	}
+	imageID, err := strconv.ParseUint(c.Param("imageId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image ID"})
+		return
+	}
+
+	image, err := h.service.DeleteImage(id, uint(imageID))
+	if err != nil {
+		respondKamarImageError(c, err)
+		return
+	}
+	removeKamarImageFile(*image)
+	c.JSON(http.StatusOK, gin.H{"message": "Foto kamar dihapus"})
+}
+
+// ReorderKamarImages PUT /api/kamar/:id/images/order {"image_ids": [5, 3, 4]} (foto pertama menjadi cover)
+func (h *KamarHandler) ReorderKamarImages(c *gin.Context) {
+	id, ok := kamarID(c)
+	if !ok {
+		return
+	}
+	var input struct {
+		ImageIDs []uint `json:"image_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	kamar, err := h.service.ReorderImages(id, input.ImageIDs)
+	if err != nil {
+		respondKamarImageError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Urutan foto disimpan", "data": kamar})
+}
+
+// SetKamarCoverImage PUT /api/kamar/:id/images/:imageId/cover
+func (h *KamarHandler) SetKamarCoverImage(c *gin.Context) {
+	id, ok := kamarID(c)
+	if !ok {
+		return
+	}
+	imageID, err := strconv.ParseUint(c.Param("imageId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image ID"})
+		return
+	}
+
+	kamar, err := h.service.SetCoverImage(id, uint(imageID))
+	if err != nil {
+		respondKamarImageError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Cover kamar diperbarui", "data": kamar})
+}
+
+func kamarID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// kamarUploadResponse adalah Kamar ditambah URL varian (asli/medium/thumbnail) foto yang baru diunggah
+type kamarUploadResponse struct {
+	*models.Kamar
+	Variants []utils.ImageVariants `json:"variants,omitempty"`
+}
+
+// uploadErrorStatus: gambar yang ditolak pipeline (format/ukuran) adalah kesalahan klien
+func uploadErrorStatus(err error) int {
+	if errors.Is(err, utils.ErrUnsupportedImage) || errors.Is(err, utils.ErrImageTooLarge) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// removeKamarImageFile membersihkan file foto yang record-nya sudah dihapus; kegagalan hanya dicatat
+func removeKamarImageFile(image models.KamarImage) {
+	if err := utils.DeleteUploadedFile(image.ImageURL); err != nil {
+		utils.GlobalLogger.Error("Failed to delete image file %s: %v", image.ImageURL, err)
+	}
+}
+
+func respondKamarImageError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kamar not found"})
+	case errors.Is(err, service.ErrKamarImageNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidImageOrder):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 	}
 }
 
 func (h *KamarHandler) DeleteKamar(c *gin.Context) {
-	idStr := c.Param("id")
-	id, _ := strconv.ParseUint(idStr, 10, 32)
+	id, ok := kamarID(c)
+	if !ok {
+		return
+	}
+	kamar, err := h.service.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kamar not found"})
+		return
+	}
 
 	// Delete associated images first
-	_ = h.service.DeleteImagesByKamarID(uint(id))
+	if err := h.service.DeleteImagesByKamarID(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-	if err := h.service.Delete(uint(id)); err != nil {
+	if err := h.service.Delete(id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	// File baru dihapus setelah kamar terhapus supaya kegagalan di atas tidak meninggalkan record tanpa file
+	for _, image := range kamar.Images {
+		removeKamarImageFile(image)
+	}
 	c.JSON(http.StatusOK, gin.H{"message": "kamar deleted successfully"})
 }
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/service"
+	"koskosan-be/internal/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoomTypeHandler struct {
+	service service.RoomTypeService
+}
+
+func NewRoomTypeHandler(s service.RoomTypeService) *RoomTypeHandler {
+	return &RoomTypeHandler{service: s}
+}
+
+// GetRoomTypes GET /api/room-types (beserta foto bersama)
+func (h *RoomTypeHandler) GetRoomTypes(c *gin.Context) {
+	roomTypes, err := h.service.GetAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if roomTypes == nil {
+		roomTypes = []models.RoomType{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": roomTypes})
+}
+
+// GetRoomTypeByID GET /api/room-types/:id
+func (h *RoomTypeHandler) GetRoomTypeByID(c *gin.Context) {
+	id, ok := roomTypeID(c)
+	if !ok {
+		return
+	}
+
+	roomType, err := h.service.GetByID(id)
+	if err != nil {
+		respondRoomTypeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, roomType)
+}
+
+// CreateRoomType POST /api/room-types {"name": "Deluxe", "harga_per_bulan": 2000000, "size": "4x5m", "capacity": 2}
+func (h *RoomTypeHandler) CreateRoomType(c *gin.Context) {
+	var input service.RoomTypeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	roomType, err := h.service.Create(input)
+	if err != nil {
+		respondRoomTypeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Tipe kamar ditambahkan", "data": roomType})
+}
+
+// UpdateRoomType PUT /api/room-types/:id (kamar yang tidak meng-override ikut diperbarui)
+func (h *RoomTypeHandler) UpdateRoomType(c *gin.Context) {
+	id, ok := roomTypeID(c)
+	if !ok {
+		return
+	}
+
+	var input service.RoomTypeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	roomType, err := h.service.Update(id, input)
+	if err != nil {
+		respondRoomTypeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Tipe kamar diperbarui", "data": roomType})
+}
+
+// DeleteRoomType DELETE /api/room-types/:id
+func (h *RoomTypeHandler) DeleteRoomType(c *gin.Context) {
+	id, ok := roomTypeID(c)
+	if !ok {
+		return
+	}
+
+	images, err := h.service.Delete(id)
+	if err != nil {
+		respondRoomTypeError(c, err)
+		return
+	}
+	for _, image := range images {
+		removeRoomTypeImageFile(image)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Tipe kamar dihapus"})
+}
+
+// UploadRoomTypeImages POST /api/room-types/:id/images (multipart, field "images")
+func (h *RoomTypeHandler) UploadRoomTypeImages(c *gin.Context) {
+	id, ok := roomTypeID(c)
+	if !ok {
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to parse form"})
+		return
+	}
+	imageFiles := form.File["images"]
+	if len(imageFiles) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Minimal 1 foto diperlukan"})
+		return
+	}
+
+	var uploadedURLs []string
+	for _, fileHeader := range imageFiles {
+		if !utils.IsImageFile(fileHeader) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Semua file harus berupa gambar"})
+			return
+		}
+		url, err := utils.UploadImage(fileHeader, "room-types")
+		if err != nil {
+			utils.GlobalLogger.Error("Failed to upload room type image: %v", err)
+			c.JSON(uploadErrorStatus(err), gin.H{"error": fmt.Sprintf("Failed to upload image: %v", err)})
+			return
+		}
+		uploadedURLs = append(uploadedURLs, url)
+	}
+
+	roomType, err := h.service.AddImages(id, uploadedURLs)
+	if err != nil {
+		respondRoomTypeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Foto tipe kamar ditambahkan", "data": roomType, "variants": utils.ImageVariantsOf(uploadedURLs)})
+}
+
+// DeleteRoomTypeImage DELETE /api/room-types/:id/images/:imageId
+func (h *RoomTypeHandler) DeleteRoomTypeImage(c *gin.Context) {
+	id, ok := roomTypeID(c)
+	if !ok {
+		return
+	}
+	imageID, err := strconv.ParseUint(c.Param("imageId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image ID"})
+		return
+	}
+
+	image, err := h.service.DeleteImage(id, uint(imageID))
+	if err != nil {
+		respondRoomTypeError(c, err)
+		return
+	}
+	removeRoomTypeImageFile(*image)
+	c.JSON(http.StatusOK, gin.H{"message": "Foto tipe kamar dihapus"})
+}
+
+// removeRoomTypeImageFile membersihkan file foto tipe yang record-nya sudah dihapus; kegagalan hanya dicatat
+func removeRoomTypeImageFile(image models.RoomTypeImage) {
+	if err := utils.DeleteUploadedFile(image.ImageURL); err != nil {
+		utils.GlobalLogger.Error("Failed to delete room type image file %s: %v", image.ImageURL, err)
+	}
+}
+
+func roomTypeID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func respondRoomTypeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRoomTypeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrRoomTypeExists), errors.Is(err, service.ErrRoomTypeInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidRoomType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
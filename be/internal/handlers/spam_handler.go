@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"koskosan-be/internal/service"
+	"koskosan-be/internal/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SpamHandler struct {
+	guard service.SpamGuard
+}
+
+func NewSpamHandler(guard service.SpamGuard) *SpamHandler {
+	return &SpamHandler{guard}
+}
+
+// GetFormToken GET /api/forms/token: diambil form publik saat ditampilkan, dikirim balik sebagai form_token
+func (h *SpamHandler) GetFormToken(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"form_token": h.guard.IssueFormToken()})
+}
+
+// GetSettings GET /api/spam/settings
+func (h *SpamHandler) GetSettings(c *gin.Context) {
+	settings, err := h.guard.GetSettings()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": settings})
+}
+
+// UpdateSettings PUT /api/spam/settings {"min_submit_seconds": 3, "max_links": 2, "blocked_words": "casino\nslot gacor"}
+func (h *SpamHandler) UpdateSettings(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	var input service.SpamSettingsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.guard.UpdateSettings(adminID, input)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSpamSettings) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Pengaturan anti-spam disimpan", "data": settings})
+}
+
+// GetRejections GET /api/spam/rejections?form=contact&reason=too_fast&page=1&limit=20
+func (h *SpamHandler) GetRejections(c *gin.Context) {
+	pagination := utils.GeneratePaginationFromRequest(c)
+
+	rejections, totalRows, err := h.guard.GetRejections(c.Query("form"), c.Query("reason"), &pagination)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if rejections == nil {
+		rejections = []models.SpamRejection{}
+	}
+
+	pagination.TotalRows = totalRows
+	pagination.TotalPages = int((totalRows + int64(pagination.GetLimit()) - 1) / int64(pagination.GetLimit()))
+
+	c.JSON(http.StatusOK, utils.PaginatedResponse{
+		Data: rejections,
+		Meta: pagination,
+	})
+}
+
+// GetStats GET /api/spam/stats?days=30 (jumlah penolakan per form dan alasan)
+func (h *SpamHandler) GetStats(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+
+	stats, err := h.guard.GetRejectionStats(days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if stats == nil {
+		stats = []repository.SpamRejectionCount{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": stats})
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+	"koskosan-be/internal/config"
+	"koskosan-be/internal/service"
+	"koskosan-be/internal/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Batas ukuran body callback dari provider
+const whatsAppWebhookMaxBody = 1 << 20
+
+// WhatsAppWebhookHandler menerima callback status pengiriman (delivered/read/failed) dan pesan masuk
+// untuk bot dari provider WhatsApp
+type WhatsAppWebhookHandler struct {
+	service service.OutboxService
+	bot     service.WhatsAppBotService
+	cfg     *config.Config
+}
+
+func NewWhatsAppWebhookHandler(s service.OutboxService, bot service.WhatsAppBotService, cfg *config.Config) *WhatsAppWebhookHandler {
+	return &WhatsAppWebhookHandler{s, bot, cfg}
+}
+
+// VerifyMeta GET /api/webhooks/whatsapp/meta - handshake saat webhook didaftarkan di dashboard Meta
+func (h *WhatsAppWebhookHandler) VerifyMeta(c *gin.Context) {
+	if h.cfg.MetaWAVerifyToken == "" ||
+		c.Query("hub.mode") != "subscribe" ||
+		c.Query("hub.verify_token") != h.cfg.MetaWAVerifyToken {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid verify token"})
+		return
+	}
+	c.String(http.StatusOK, c.Query("hub.challenge"))
+}
+
+// MetaStatus POST /api/webhooks/whatsapp/meta
+func (h *WhatsAppWebhookHandler) MetaStatus(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, whatsAppWebhookMaxBody))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid body"})
+		return
+	}
+	if !utils.VerifyMetaSignature(h.cfg.MetaWAAppSecret, body, c.GetHeader("X-Hub-Signature-256")) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
+		return
+	}
+
+	statuses, err := utils.ParseMetaStatuses(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payload"})
+		return
+	}
+	messages, err := utils.ParseMetaMessages(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payload"})
+		return
+	}
+
+	// Meta mengirim ulang callback jika tidak dibalas 200, jadi error per status cukup dicatat
+	for _, status := range statuses {
+		h.apply(status)
+	}
+	for _, msg := range messages {
+		go h.handleInbound(msg)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "ok"})
+}
+
+// GenericStatus POST /api/webhooks/whatsapp/generic
+// Body: {"message_id": "...", "status": "delivered", "timestamp": "RFC3339", "error": ""}
+func (h *WhatsAppWebhookHandler) GenericStatus(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, whatsAppWebhookMaxBody))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid body"})
+		return
+	}
+	if !utils.VerifyWebhookSignature(h.cfg.WAWebhookSecret, c.GetHeader(utils.WebhookTimestampHeader), body, c.GetHeader(utils.WebhookSignatureHeader), time.Now()) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
+		return
+	}
+
+	status, err := utils.ParseWebhookStatus(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.apply(*status); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update status"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "ok"})
+}
+
+// GenericMessage POST /api/webhooks/whatsapp/generic/messages - pesan masuk dari gateway webhook generik
+func (h *WhatsAppWebhookHandler) GenericMessage(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, whatsAppWebhookMaxBody))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid body"})
+		return
+	}
+	if !utils.VerifyWebhookSignature(h.cfg.WAWebhookSecret, c.GetHeader(utils.WebhookTimestampHeader), body, c.GetHeader(utils.WebhookSignatureHeader), time.Now()) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
+		return
+	}
+
+	msg, err := utils.ParseWebhookMessage(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	go h.handleInbound(*msg)
+	c.JSON(http.StatusOK, gin.H{"message": "ok"})
+}
+
+// FonnteMessage POST /api/webhooks/whatsapp/fonnte?token=... - Fonnte tidak menandatangani webhook,
+// jadi URL yang didaftarkan harus memuat FONNTE_WEBHOOK_TOKEN
+func (h *WhatsAppWebhookHandler) FonnteMessage(c *gin.Context) {
+	token := c.Query("token")
+	if h.cfg.FonnteWebhookToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.cfg.FonnteWebhookToken)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, whatsAppWebhookMaxBody))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid body"})
+		return
+	}
+	msg, err := utils.ParseFonnteMessage(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	go h.handleInbound(*msg)
+	c.JSON(http.StatusOK, gin.H{"message": "ok"})
+}
+
+// handleInbound dijalankan di goroutine: unduh gambar dan balasan bisa lebih lama dari batas waktu webhook provider
+func (h *WhatsAppWebhookHandler) handleInbound(msg utils.InboundWhatsApp) {
+	if err := h.bot.HandleMessage(msg); err != nil {
+		utils.GlobalLogger.Error("WhatsApp bot failed to handle message %s from %s: %v", msg.MessageID, msg.From, err)
+	}
+}
+
+func (h *WhatsAppWebhookHandler) apply(status utils.WhatsAppStatus) error {
+	err := h.service.UpdateWhatsAppStatus(status)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.GlobalLogger.Warn("WhatsApp status %s for unknown message %s", status.Status, status.MessageID)
+	} else if err != nil {
+		utils.GlobalLogger.Error("Failed to update WhatsApp status for %s: %v", status.MessageID, err)
+	}
+	return err
+}
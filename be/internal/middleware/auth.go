@@ -1,14 +1,13 @@
 package middleware
 
 import (
-	"koskosan-be/internal/config"
 	"koskosan-be/internal/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
 // AuthMiddleware verify JWT token dari cookie
-func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+func AuthMiddleware(keys *utils.KeySet) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get token dari cookie (prioritas) atau Authorization header (fallback)
 		token, err := utils.GetAuthToken(c)
@@ -19,7 +18,7 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 		}
 
 		// Validate ACCESS token specifically (not refresh token)
-		claims, err := utils.ValidateAccessToken(token, cfg.JWTSecret)
+		claims, err := utils.ValidateAccessToken(token, keys)
 		if err != nil {
 			utils.GlobalLogger.Error("AuthMiddleware: Token validation failed: %v", err)
 			utils.UnauthorizedError(c, "Invalid or expired token")
@@ -68,7 +67,7 @@ func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
 }
 
 // OptionalAuthMiddleware verify token tapi tidak mandatory
-func OptionalAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+func OptionalAuthMiddleware(keys *utils.KeySet) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token, err := utils.GetAuthToken(c)
 		if err != nil {
@@ -77,7 +76,7 @@ func OptionalAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		claims, err := utils.ValidateToken(token, cfg.JWTSecret)
+		claims, err := utils.ValidateAccessToken(token, keys)
 		if err != nil {
 			// Token invalid, tapi allowed - skip validation
 			c.Next()
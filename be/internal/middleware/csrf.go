@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"koskosan-be/internal/config"
+	"koskosan-be/internal/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CSRFMiddleware memverifikasi header X-CSRF-Token pada request POST/PUT/PATCH/DELETE
+// yang diautentikasi lewat cookie. Harus dipasang setelah AuthMiddleware.
+func CSRFMiddleware(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		// Browser tidak mengirim Authorization header secara otomatis,
+		// jadi request dengan Bearer token (tanpa cookie) tidak rentan CSRF
+		if accessCookie, err := c.Cookie("access_token"); err != nil || accessCookie == "" {
+			c.Next()
+			return
+		}
+
+		headerToken := c.GetHeader(utils.CSRFHeaderName)
+		cookieToken, _ := c.Cookie(utils.CSRFCookieName)
+		if !utils.CSRFTokensMatch(headerToken, cookieToken) {
+			utils.ForbiddenError(c, "Invalid or missing CSRF token")
+			c.Abort()
+			return
+		}
+
+		userID, _ := c.Get("user_id")
+		id, ok := userID.(int)
+		if !ok || !utils.ValidateCSRFToken(headerToken, id, cfg.JWTSecret) {
+			utils.ForbiddenError(c, "Invalid or missing CSRF token")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
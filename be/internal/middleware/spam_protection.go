@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"koskosan-be/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Field metadata anti-spam yang dikirim form publik bersama datanya
+const (
+	SpamFieldChallenge = "captcha_token"
+	SpamFieldHoneypot  = "website"         // input tersembunyi, harus kosong
+	SpamFieldFormToken = "form_token"      // dari GET /api/forms/token saat form ditampilkan
+)
+
+const spamMaxBodySize = 1 << 20
+
+var spamRejectionMessages = map[string]string{
+	service.SpamReasonTooManyLinks: "Pesan mengandung terlalu banyak link",
+	service.SpamReasonBlockedWord:  "Pesan mengandung kata yang tidak diizinkan",
+	service.SpamReasonChallenge:    "Verifikasi captcha gagal, silakan coba lagi",
+}
+
+// SpamProtection memeriksa kiriman form publik (honeypot, waktu isi, isi teks, captcha) sebelum handler.
+// contentFields adalah field teks di body JSON yang diperiksa link dan kata terlarang; jangan masukkan password.
+func SpamProtection(guard service.SpamGuard, form string, contentFields ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, spamMaxBodySize))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+			c.Abort()
+			return
+		}
+		// Kembalikan body supaya handler tetap bisa bind JSON
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var payload map[string]interface{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			// Biarkan handler yang menolak JSON tidak valid dengan pesan biasanya
+			c.Next()
+			return
+		}
+
+		sub := service.FormSubmission{
+			Form:           form,
+			IPAddress:      c.ClientIP(),
+			UserAgent:      c.Request.UserAgent(),
+			Honeypot:       stringField(payload, SpamFieldHoneypot),
+			ChallengeToken: stringField(payload, SpamFieldChallenge),
+			FormToken:      stringField(payload, SpamFieldFormToken),
+			Email:          stringField(payload, "email"),
+		}
+		for _, field := range contentFields {
+			if value := stringField(payload, field); value != "" {
+				sub.Content = append(sub.Content, value)
+			}
+		}
+
+		if err := guard.Check(sub); err != nil {
+			var rejected *service.SpamRejectedError
+			if errors.As(err, &rejected) {
+				message, ok := spamRejectionMessages[rejected.Reason]
+				if !ok {
+					message = "Kiriman ditolak, silakan muat ulang halaman dan coba lagi"
+				}
+				c.JSON(http.StatusBadRequest, gin.H{"error": message, "reason": rejected.Reason})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify submission"})
+			}
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func stringField(payload map[string]interface{}, key string) string {
+	value, _ := payload[key].(string)
+	return value
+}
@@ -7,43 +7,135 @@ import (
 )
 
 type User struct {
-	ID               uint           `gorm:"primaryKey" json:"id"`
-	Username         string         `gorm:"uniqueIndex" json:"username"`
-	Password         string         `json:"-"`
-	Role             string         `gorm:"index" json:"role"` // enum: admin, penyewa, etc.
-	CreatedAt        time.Time      `json:"created_at"`
-	UpdatedAt        time.Time      `json:"updated_at"`
-	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
-	ResetToken       string         `json:"-"`
-	ResetTokenExpiry time.Time      `json:"-"`
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	Username  string         `gorm:"uniqueIndex" json:"username"`
+	Password  string         `json:"-"`
+	Role      string         `gorm:"index" json:"role"` // enum: admin, penyewa, etc.
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// PasswordResetToken menyimpan hash SHA-256 dari token reset, bukan token aslinya
+type PasswordResetToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index" json:"user_id"`
+	TokenHash string     `gorm:"uniqueIndex;size:64" json:"-"`
+	CreatedIP string     `json:"created_ip"`
+	ExpiresAt time.Time  `gorm:"index" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"` // nil = belum dipakai
+	CreatedAt time.Time  `json:"created_at"`
 }
 
 type Kamar struct {
 	ID            uint           `gorm:"primaryKey" json:"id"`
 	NomorKamar    string         `json:"nomor_kamar"`
-	TipeKamar     string         `json:"tipe_kamar"`
-	Fasilitas     string         `json:"fasilitas"` // text
-	HargaPerBulan float64        `json:"harga_per_bulan"`
+	TipeKamar     string         `gorm:"index" json:"tipe_kamar"` // nama RoomType jika RoomTypeID diisi
+	RoomTypeID    *uint          `gorm:"index" json:"room_type_id"`
+	RoomType      *RoomType      `json:"room_type,omitempty"`
+	Fasilitas     string         `json:"fasilitas"` // nama Facilities dipisah koma, disinkronkan otomatis
+	HargaPerBulan float64        `gorm:"index" json:"harga_per_bulan"`
 	Status        string         `gorm:"index" json:"status"` // enum
-	Capacity      int            `json:"capacity"`
-	Floor         int            `json:"floor"`
+	Capacity      int            `gorm:"index" json:"capacity"`
+	Floor         int            `gorm:"index" json:"floor"`
 	Size          string         `json:"size"` // e.g. "3x4m" or "12m2"
 	Bedrooms      int            `json:"bedrooms"`
 	Bathrooms     int            `json:"bathrooms"`
 	Description   string         `json:"description"`
 	ImageURL      string         `json:"image_url"`
 	Images        []KamarImage   `gorm:"foreignKey:KamarID" json:"Images,omitempty"`
+	Facilities    []Facility     `gorm:"many2many:kamar_facilities;" json:"facilities,omitempty"`
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
 	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Override* = kamar memakai nilainya sendiri; false = mengikuti RoomType (hanya berlaku jika RoomTypeID diisi)
+	OverridePrice       bool `gorm:"default:false" json:"override_price"`
+	OverrideSize        bool `gorm:"default:false" json:"override_size"`
+	OverrideCapacity    bool `gorm:"default:false" json:"override_capacity"`
+	OverrideDescription bool `gorm:"default:false" json:"override_description"`
+}
+
+// RoomType menyimpan harga, ukuran, kapasitas, deskripsi dan foto bawaan untuk kamar-kamar bertipe sama
+type RoomType struct {
+	ID            uint            `gorm:"primaryKey" json:"id"`
+	Name          string          `gorm:"size:100;uniqueIndex" json:"name"`
+	HargaPerBulan float64         `json:"harga_per_bulan"`
+	Size          string          `json:"size"`
+	Capacity      int             `json:"capacity"`
+	Description   string          `json:"description"`
+	Images        []RoomTypeImage `gorm:"foreignKey:RoomTypeID" json:"images,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
 }
 
+type RoomTypeImage struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	RoomTypeID uint      `gorm:"index" json:"room_type_id"`
+	ImageURL   string    `json:"image_url"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Kategori fasilitas untuk pengelompokan tampilan
+const (
+	FacilityCategoryRoom     = "room"     // di dalam kamar: AC, lemari, meja
+	FacilityCategoryBathroom = "bathroom" // kamar mandi dalam/luar, water heater
+	FacilityCategoryBuilding = "building" // bersama: Wi-Fi, dapur, parkir
+	FacilityCategoryService  = "service"  // layanan: laundry, kebersihan
+	FacilityCategoryOther    = "other"
+)
+
+// Facility adalah katalog fasilitas kamar; IconKey dipetakan ke ikon di frontend
+type Facility struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"size:100;uniqueIndex" json:"name"`
+	IconKey   string    `gorm:"size:50" json:"icon_key"` // mis. "wifi", "ac", "bath"
+	Category  string    `gorm:"size:20;index;default:other" json:"category"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// KamarImage adalah foto kamar; foto dengan SortOrder terkecil adalah cover (Kamar.ImageURL)
 type KamarImage struct {
 	ID        uint           `gorm:"primaryKey" json:"id"`
 	KamarID   uint           `gorm:"index" json:"kamar_id"`
 	ImageURL  string         `json:"image_url"`
 	CreatedAt time.Time      `json:"created_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	SortOrder int `gorm:"default:0" json:"sort_order"`
+}
+
+// Sumber perubahan harga kamar
+const (
+	PriceChangeManual    = "manual"    // edit kamar/tipe kamar, hanya berlaku untuk pemesanan baru
+	PriceChangeScheduled = "scheduled" // kenaikan terjadwal, juga berlaku untuk sewa berjalan setelah pemberitahuan
+)
+
+// KamarPriceHistory adalah riwayat harga kamar. Sewa berjalan tetap membayar harga kontrak
+// (Pemesanan.HargaPerBulan) sampai ada perubahan terjadwal yang sudah diberitahukan berlaku.
+type KamarPriceHistory struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	KamarID       uint       `gorm:"index;not null" json:"kamar_id"`
+	HargaSebelum  float64    `json:"harga_sebelum"`
+	HargaPerBulan float64    `json:"harga_per_bulan"`
+	EffectiveFrom time.Time  `gorm:"index;not null" json:"effective_from"`
+	Source        string     `gorm:"size:20;index" json:"source"`
+	Note          string     `gorm:"type:text" json:"note"`
+	NoticeSentAt  *time.Time `json:"notice_sent_at"` // semua penyewa sudah diberi tahu (hanya scheduled)
+	AppliedAt     *time.Time `json:"applied_at"`     // harga kamar sudah diganti
+	CreatedAt     time.Time  `json:"created_at"`
+
+	Notices []RentChangeNotice `gorm:"foreignKey:PriceHistoryID" json:"-"`
+}
+
+// RentChangeNotice mencatat pemberitahuan perubahan harga yang sudah terkirim ke satu sewa berjalan,
+// supaya pengiriman ulang hanya mencakup penyewa yang terlewat
+type RentChangeNotice struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	PriceHistoryID uint      `gorm:"uniqueIndex:idx_rent_change_notice_lease" json:"price_history_id"`
+	PemesananID    uint      `gorm:"uniqueIndex:idx_rent_change_notice_lease" json:"pemesanan_id"`
+	SentAt         time.Time `json:"sent_at"`
 }
 
 type Gallery struct {
@@ -99,6 +191,13 @@ type Pemesanan struct {
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Snapshot harga saat dipesan; TotalHarga = Subtotal - Diskon (0 untuk pemesanan lama)
+	HargaPerBulan float64           `json:"harga_per_bulan"`
+	Subtotal      float64           `json:"subtotal"`
+	Diskon        float64           `json:"diskon"`
+	TotalHarga    float64           `json:"total_harga"`
+	Discounts     []BookingDiscount `gorm:"foreignKey:PemesananID" json:"discounts,omitempty"`
 }
 
 type Pembayaran struct {
@@ -117,6 +216,50 @@ type Pembayaran struct {
 	CreatedAt         time.Time      `json:"created_at"`
 	UpdatedAt         time.Time      `json:"updated_at"`
 	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Rincian tagihan untuk invoice: Subtotal - Diskon = total tagihan (JumlahBayar bisa berupa DP-nya)
+	Subtotal    float64 `json:"subtotal"`
+	Diskon      float64 `json:"diskon"`
+	JumlahBulan int     `json:"jumlah_bulan"` // bulan yang dibayar tagihan extend
+}
+
+// Jenis aturan harga
+const (
+	PricingRuleLongStay  = "long_stay" // diskon untuk sewa minimal MinMonths bulan
+	PricingRulePromotion = "promotion" // diskon untuk sewa yang mulai di antara StartsAt dan EndsAt
+	PricingRuleVoucher   = "voucher"   // diskon dengan kode, dibatasi MaxUses
+)
+
+// PricingRule adalah aturan diskon sewa; DiscountPercent atau DiscountAmount (potongan tetap) diisi salah satu
+type PricingRule struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	Name            string     `gorm:"size:100" json:"name"`
+	Type            string     `gorm:"size:20;index" json:"type"`
+	Code            string     `gorm:"size:50;index" json:"code,omitempty"` // voucher, disimpan huruf besar
+	DiscountPercent float64    `json:"discount_percent"`
+	DiscountAmount  float64    `json:"discount_amount"`
+	MinMonths       int        `json:"min_months"`
+	StartsAt        *time.Time `json:"starts_at"`
+	EndsAt          *time.Time `json:"ends_at"`
+	RoomTypeID      *uint      `gorm:"index" json:"room_type_id"` // nil = semua tipe kamar
+	MaxUses         int        `json:"max_uses"`                  // 0 = tanpa batas
+	UsedCount       int        `json:"used_count"`
+	Active          bool       `json:"active"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// BookingDiscount adalah snapshot diskon yang dipakai; tidak berubah walau aturannya diubah atau dihapus
+type BookingDiscount struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	PemesananID   uint      `gorm:"index" json:"pemesanan_id"`
+	PembayaranID  *uint     `gorm:"index" json:"pembayaran_id"` // diisi untuk perpanjangan
+	PricingRuleID uint      `gorm:"index" json:"pricing_rule_id"`
+	Name          string    `json:"name"`
+	Type          string    `json:"type"`
+	Code          string    `json:"code,omitempty"`
+	Amount        float64   `json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 // PaymentReminder untuk tracking pembayaran bulanan
@@ -128,7 +271,216 @@ type PaymentReminder struct {
 	TanggalReminder time.Time      `json:"tanggal_reminder"`
 	StatusReminder  string         `gorm:"index" json:"status_reminder"` // enum: Pending, Paid, Expired
 	IsSent          bool           `json:"is_sent"`                      // Apakah reminder sudah dikirim
+	SentChannels    string         `gorm:"type:varchar(100)" json:"-"`   // channel yang sudah diantrikan (koma), supaya retry tidak menduplikasi
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
 }
+
+// Notification adalah isi inbox in-app per user, satu baris untuk setiap event yang dikirim ke user
+type Notification struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index:idx_notifications_user_created,priority:1" json:"user_id"`
+	EventID   string     `gorm:"size:32;index" json:"event_id"`
+	Type      string     `gorm:"size:50" json:"type"` // payment.confirmed, bill.created, booking.cancelled, ...
+	Title     string     `json:"title"`
+	Message   string     `gorm:"type:text" json:"message"`
+	Channels  string     `json:"channels"`              // channel yang dipakai, dipisah koma: in_app,email,whatsapp
+	Data      string     `gorm:"type:text" json:"data"` // payload event (JSON)
+	ReadAt    *time.Time `json:"read_at"`               // nil = belum dibaca
+	CreatedAt time.Time  `gorm:"index:idx_notifications_user_created,priority:2" json:"created_at"`
+}
+
+// NotificationPreference menentukan channel yang aktif untuk satu kategori notifikasi (payment, bill, booking).
+// Tidak ada baris = semua channel aktif.
+type NotificationPreference struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"uniqueIndex:idx_notification_pref_user_category" json:"user_id"`
+	Category  string    `gorm:"size:30;uniqueIndex:idx_notification_pref_user_category" json:"category"`
+	Email     bool      `json:"email"`
+	WhatsApp  bool      `gorm:"column:whatsapp" json:"whatsapp"`
+	InApp     bool      `json:"in_app"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NotificationSetting menyimpan jam tenang (format HH:MM, waktu Asia/Jakarta) dan bahasa pesan per user
+type NotificationSetting struct {
+	UserID            uint      `gorm:"primaryKey" json:"user_id"`
+	QuietHoursEnabled bool      `json:"quiet_hours_enabled"`
+	QuietHoursStart   string    `gorm:"size:5" json:"quiet_hours_start"`   // contoh: 22:00
+	QuietHoursEnd     string    `gorm:"size:5" json:"quiet_hours_end"`     // contoh: 07:00
+	Language          string    `gorm:"size:5;default:id" json:"language"` // bahasa email/WhatsApp: id, en
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// Status pengiriman outbox
+const (
+	OutboxStatusPending = "pending" // menunggu dikirim / dicoba ulang
+	OutboxStatusSent    = "sent"
+	OutboxStatusFailed  = "failed" // percobaan habis, perlu kirim ulang manual oleh admin
+)
+
+// OutboxMessage adalah satu pesan email/WhatsApp yang harus dikirim ke tenant.
+// Worker mengirim ulang dengan backoff eksponensial sampai berhasil atau MaxAttempts tercapai.
+type OutboxMessage struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	UserID        uint       `gorm:"index" json:"user_id"`
+	EventID       string     `gorm:"size:32;index" json:"event_id"`
+	EventType     string     `gorm:"size:50" json:"event_type"`
+	Channel       string     `gorm:"size:20" json:"channel"` // email, whatsapp
+	Recipient     string     `json:"recipient"`              // alamat email atau nomor 62xxx
+	RecipientName string     `json:"recipient_name"`
+	Template      string     `gorm:"size:50" json:"template"`   // nama template di katalog pesan (payment_success, ...)
+	Subject       string     `json:"subject"`                   // hanya untuk email
+	Body          string     `gorm:"type:text" json:"body"`     // isi yang sudah dirender (HTML untuk email)
+	SMSBody       string     `gorm:"type:text" json:"sms_body"` // cadangan jika WhatsApp gagal (kosong = tanpa SMS)
+	SentVia       string     `gorm:"size:20" json:"sent_via"`   // channel yang akhirnya dipakai: email, whatsapp, sms
+	Status        string     `gorm:"size:20;index:idx_outbox_status_next_retry,priority:1;default:'pending'" json:"status"`
+	Attempts      int        `gorm:"default:0" json:"attempts"`
+	MaxAttempts   int        `gorm:"default:5" json:"max_attempts"`
+	NextRetryAt   time.Time  `gorm:"index:idx_outbox_status_next_retry,priority:2" json:"next_retry_at"`
+	LastError     string     `gorm:"type:text" json:"last_error"`
+	SentAt        *time.Time `json:"sent_at"`
+
+	// Diisi dari balasan provider WhatsApp dan diperbarui lewat webhook status
+	ProviderMessageID string     `gorm:"size:128;index" json:"provider_message_id"`
+	DeliveryStatus    string     `gorm:"size:20" json:"delivery_status"` // sent, delivered, read, failed
+	DeliveredAt       *time.Time `json:"delivered_at"`
+	ReadAt            *time.Time `json:"read_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// InboundWhatsAppMessage mencatat ID pesan masuk yang sudah diproses bot,
+// supaya webhook yang dikirim ulang provider tidak diproses dua kali
+type InboundWhatsAppMessage struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	MessageID string    `gorm:"size:191;uniqueIndex" json:"message_id"`
+	From      string    `gorm:"size:20" json:"from"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MessageTemplate menyimpan template pesan yang diedit admin.
+// Menimpa template bawaan (internal/templates/files) untuk kombinasi name+channel+language yang sama.
+type MessageTemplate struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"size:50;uniqueIndex:idx_message_template_key" json:"name"`    // payment_success, payment_reminder, ...
+	Channel   string    `gorm:"size:20;uniqueIndex:idx_message_template_key" json:"channel"` // email, whatsapp
+	Language  string    `gorm:"size:5;uniqueIndex:idx_message_template_key" json:"language"` // id, en
+	Subject   string    `json:"subject"`                                                     // hanya untuk email
+	Body      string    `gorm:"type:text" json:"body"`
+	UpdatedBy uint      `json:"updated_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Status tiket perbaikan/keluhan
+const (
+	TicketStatusOpen       = "open"
+	TicketStatusInProgress = "in_progress"
+	TicketStatusResolved   = "resolved" // sudah diperbaiki, menunggu ditutup
+	TicketStatusClosed     = "closed"
+)
+
+// MaintenanceTicket adalah laporan kerusakan/keluhan tenant untuk kamar yang sedang disewanya
+type MaintenanceTicket struct {
+	ID          uint                       `gorm:"primaryKey" json:"id"`
+	PenyewaID   uint                       `gorm:"index" json:"penyewa_id"`
+	Penyewa     Penyewa                    `gorm:"foreignKey:PenyewaID" json:"penyewa"`
+	KamarID     uint                       `gorm:"index" json:"kamar_id"`
+	Kamar       Kamar                      `gorm:"foreignKey:KamarID" json:"kamar"`
+	Title       string                     `json:"title"`
+	Description string                     `gorm:"type:text" json:"description"`
+	Category    string                     `gorm:"size:30;index" json:"category"`            // listrik, air, ac, furnitur, kebersihan, internet, lainnya
+	Priority    string                     `gorm:"size:20;default:'medium'" json:"priority"` // low, medium, high, urgent
+	Status      string                     `gorm:"size:20;index;default:'open'" json:"status"`
+	AssignedTo  *uint                      `gorm:"index" json:"assigned_to"` // user admin yang menangani
+	Assignee    *User                      `gorm:"foreignKey:AssignedTo" json:"assignee,omitempty"`
+	Photos      []MaintenanceTicketPhoto   `gorm:"foreignKey:TicketID" json:"photos"`
+	Comments    []MaintenanceTicketComment `gorm:"foreignKey:TicketID" json:"comments,omitempty"`
+	ResolvedAt  *time.Time                 `json:"resolved_at"` // dipakai untuk menghitung lama penyelesaian
+	ClosedAt    *time.Time                 `json:"closed_at"`
+	CreatedAt   time.Time                  `json:"created_at"`
+	UpdatedAt   time.Time                  `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt             `gorm:"index" json:"-"`
+}
+
+type MaintenanceTicketPhoto struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TicketID  uint      `gorm:"index" json:"ticket_id"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MaintenanceTicketComment adalah percakapan tenant dan admin di satu tiket
+type MaintenanceTicketComment struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	TicketID   uint      `gorm:"index" json:"ticket_id"`
+	UserID     uint      `gorm:"index" json:"user_id"`
+	AuthorName string    `json:"author_name"`
+	IsAdmin    bool      `json:"is_admin"`
+	Message    string    `gorm:"type:text" json:"message"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Status pesan dari contact form
+const (
+	ContactStatusNew     = "new"
+	ContactStatusRead    = "read"
+	ContactStatusReplied = "replied"
+	ContactStatusSpam    = "spam"
+)
+
+// ContactMessage adalah pesan dari contact form publik; disimpan dulu supaya tidak hilang saat SMTP bermasalah
+type ContactMessage struct {
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	Name       string         `json:"name"`
+	Email      string         `gorm:"index" json:"email"`
+	Message    string         `gorm:"type:text" json:"message"`
+	Status     string         `gorm:"size:20;index;default:'new'" json:"status"` // new, read, replied, spam
+	AssignedTo *uint          `gorm:"index" json:"assigned_to"`
+	Assignee   *User          `gorm:"foreignKey:AssignedTo" json:"assignee,omitempty"`
+	Replies    []ContactReply `gorm:"foreignKey:ContactMessageID" json:"replies,omitempty"`
+	IPAddress  string         `gorm:"size:45" json:"ip_address"`
+	ReadAt     *time.Time     `json:"read_at"`
+	RepliedAt  *time.Time     `json:"replied_at"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// ContactReply adalah balasan admin yang dikirim ke email pengirim
+type ContactReply struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	ContactMessageID uint      `gorm:"index" json:"contact_message_id"`
+	UserID           uint      `json:"user_id"`
+	AuthorName       string    `json:"author_name"`
+	Subject          string    `json:"subject"`
+	Message          string    `gorm:"type:text" json:"message"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// SpamSettings adalah aturan anti-spam form publik yang bisa diatur admin (satu baris)
+type SpamSettings struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	MinSubmitSeconds int       `gorm:"default:3" json:"min_submit_seconds"` // form yang dikirim lebih cepat dari ini dianggap bot
+	MaxLinks         int       `gorm:"default:2" json:"max_links"`          // jumlah link maksimal di isi form
+	BlockedWords     string    `gorm:"type:text" json:"blocked_words"`      // satu kata/frasa per baris, tidak peka huruf besar
+	UpdatedBy        uint      `json:"updated_by"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// SpamRejection mencatat kiriman form yang ditolak supaya admin bisa menyetel aturan
+type SpamRejection struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Form      string    `gorm:"size:30;index" json:"form"`   // contact, register, forgot_password
+	Reason    string    `gorm:"size:30;index" json:"reason"` // honeypot, too_fast, too_many_links, blocked_word, challenge_failed
+	Detail    string    `json:"detail"`
+	IPAddress string    `gorm:"size:45;index" json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	Email     string    `json:"email"`
+	Content   string    `gorm:"type:text" json:"content"` // potongan isi form (tanpa password)
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
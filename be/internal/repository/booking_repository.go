@@ -47,6 +47,7 @@ func (r *bookingRepository) FindByPenyewaIDWithPayments(penyewaID uint) ([]model
 	// Performance improvement: ~20x faster for 10 bookings
 	err := r.db.Preload("Kamar").
 		Preload("Pembayaran"). // Load payments eagerly
+		Preload("Discounts").  // Rincian diskon untuk invoice
 		Where("penyewa_id = ?", penyewaID).
 		Order("created_at DESC").
 		Find(&bookings).Error
@@ -76,7 +77,7 @@ func (r *bookingRepository) GetPaymentsByBookingID(bookingID uint) ([]models.Pem
 func (r *bookingRepository) FindExpiredPendingBookings(expiryTime time.Time) ([]models.Pemesanan, error) {
 	var bookings []models.Pemesanan
 	// Find bookings that are 'Pending' and created before the expiryTime
-	err := r.db.Where("status_pemesanan = ? AND created_at < ?", "Pending", expiryTime).Find(&bookings).Error
+	err := r.db.Preload("Penyewa").Where("status_pemesanan = ? AND created_at < ?", "Pending", expiryTime).Find(&bookings).Error
 	return bookings, err
 }
 
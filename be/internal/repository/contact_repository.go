@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+type ContactRepository interface {
+	Create(message *models.ContactMessage) error
+	FindByID(id uint) (*models.ContactMessage, error)
+	FindAll(status string, pagination *utils.Pagination) ([]models.ContactMessage, int64, error)
+	CountByStatus(status string) (int64, error)
+	Save(message *models.ContactMessage) error
+	AddReply(reply *models.ContactReply) error
+	WithTx(tx *gorm.DB) ContactRepository
+}
+
+type contactRepository struct {
+	db *gorm.DB
+}
+
+func NewContactRepository(db *gorm.DB) ContactRepository {
+	return &contactRepository{db}
+}
+
+func (r *contactRepository) Create(message *models.ContactMessage) error {
+	return r.db.Omit("Assignee", "Replies").Create(message).Error
+}
+
+// FindByID memuat pesan beserta admin penanggung jawab dan riwayat balasan
+func (r *contactRepository) FindByID(id uint) (*models.ContactMessage, error) {
+	var message models.ContactMessage
+	err := r.db.Preload("Assignee").
+		Preload("Replies", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC, id ASC")
+		}).
+		First(&message, id).Error
+	return &message, err
+}
+
+// FindAll: status kosong = semua pesan kecuali spam
+func (r *contactRepository) FindAll(status string, pagination *utils.Pagination) ([]models.ContactMessage, int64, error) {
+	var messages []models.ContactMessage
+	var totalRows int64
+
+	query := r.db.Model(&models.ContactMessage{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	} else {
+		query = query.Where("status <> ?", models.ContactStatusSpam)
+	}
+
+	query.Count(&totalRows)
+
+	err := query.Scopes(utils.Paginate(models.ContactMessage{}, pagination, query)).
+		Preload("Assignee").
+		Order("created_at DESC").
+		Find(&messages).Error
+
+	return messages, totalRows, err
+}
+
+func (r *contactRepository) CountByStatus(status string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.ContactMessage{}).Where("status = ?", status).Count(&count).Error
+	return count, err
+}
+
+func (r *contactRepository) Save(message *models.ContactMessage) error {
+	return r.db.Omit("Assignee", "Replies").Save(message).Error
+}
+
+func (r *contactRepository) AddReply(reply *models.ContactReply) error {
+	return r.db.Create(reply).Error
+}
+
+func (r *contactRepository) WithTx(tx *gorm.DB) ContactRepository {
+	return &contactRepository{db: tx}
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"koskosan-be/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type FacilityRepository interface {
+	FindAll() ([]models.Facility, error)
+	FindByID(id uint) (*models.Facility, error)
+	FindByIDs(ids []uint) ([]models.Facility, error)
+	// FindByName tidak peka huruf besar; gorm.ErrRecordNotFound jika belum ada
+	FindByName(name string) (*models.Facility, error)
+	Create(facility *models.Facility) error
+	// Save juga memperbarui kolom Kamar.Fasilitas kamar yang memakai fasilitas ini
+	Save(facility *models.Facility) error
+	// Delete menghapus fasilitas beserta tautannya ke kamar
+	Delete(id uint) error
+	WithTx(tx *gorm.DB) FacilityRepository
+}
+
+type facilityRepository struct {
+	db *gorm.DB
+}
+
+func NewFacilityRepository(db *gorm.DB) FacilityRepository {
+	return &facilityRepository{db}
+}
+
+func (r *facilityRepository) FindAll() ([]models.Facility, error) {
+	var facilities []models.Facility
+	err := r.db.Order("category ASC").Order("name ASC").Find(&facilities).Error
+	return facilities, err
+}
+
+func (r *facilityRepository) FindByID(id uint) (*models.Facility, error) {
+	var facility models.Facility
+	err := r.db.First(&facility, id).Error
+	return &facility, err
+}
+
+func (r *facilityRepository) FindByIDs(ids []uint) ([]models.Facility, error) {
+	var facilities []models.Facility
+	if len(ids) == 0 {
+		return facilities, nil
+	}
+	err := r.db.Where("id IN ?", ids).Order("name ASC").Find(&facilities).Error
+	return facilities, err
+}
+
+func (r *facilityRepository) FindByName(name string) (*models.Facility, error) {
+	var facility models.Facility
+	err := r.db.Where("LOWER(name) = LOWER(?)", name).First(&facility).Error
+	return &facility, err
+}
+
+func (r *facilityRepository) Create(facility *models.Facility) error {
+	return r.db.Create(facility).Error
+}
+
+func (r *facilityRepository) Save(facility *models.Facility) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(facility).Error; err != nil {
+			return err
+		}
+		kamarIDs, err := kamarIDsWithFacility(tx, facility.ID)
+		if err != nil {
+			return err
+		}
+		return syncFasilitasText(tx, kamarIDs)
+	})
+}
+
+func (r *facilityRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		kamarIDs, err := kamarIDsWithFacility(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM kamar_facilities WHERE facility_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.Facility{}, id).Error; err != nil {
+			return err
+		}
+		return syncFasilitasText(tx, kamarIDs)
+	})
+}
+
+func (r *facilityRepository) WithTx(tx *gorm.DB) FacilityRepository {
+	return &facilityRepository{db: tx}
+}
+
+func kamarIDsWithFacility(db *gorm.DB, facilityID uint) ([]uint, error) {
+	var kamarIDs []uint
+	err := db.Table("kamar_facilities").Where("facility_id = ?", facilityID).Pluck("kamar_id", &kamarIDs).Error
+	return kamarIDs, err
+}
+
+// syncFasilitasText mengisi ulang kolom teks Kamar.Fasilitas dari katalog supaya klien lama tetap konsisten
+func syncFasilitasText(db *gorm.DB, kamarIDs []uint) error {
+	if len(kamarIDs) == 0 {
+		return nil
+	}
+	return db.Exec(`UPDATE kamars SET fasilitas = COALESCE((
+		SELECT string_agg(f.name, ', ' ORDER BY f.name)
+		FROM kamar_facilities kf JOIN facilities f ON f.id = kf.facility_id
+		WHERE kf.kamar_id = kamars.id
+	), '') WHERE id IN ?`, kamarIDs).Error
+}
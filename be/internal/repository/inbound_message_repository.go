@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"koskosan-be/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InboundMessageRepository interface {
+	// MarkProcessed mencatat ID pesan masuk; false jika ID itu sudah pernah dicatat (webhook dikirim ulang)
+	MarkProcessed(messageID, from string) (bool, error)
+	WithTx(tx *gorm.DB) InboundMessageRepository
+}
+
+type inboundMessageRepository struct {
+	db *gorm.DB
+}
+
+func NewInboundMessageRepository(db *gorm.DB) InboundMessageRepository {
+	return &inboundMessageRepository{db}
+}
+
+func (r *inboundMessageRepository) MarkProcessed(messageID, from string) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "message_id"}},
+		DoNothing: true,
+	}).Create(&models.InboundWhatsAppMessage{MessageID: messageID, From: from})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *inboundMessageRepository) WithTx(tx *gorm.DB) InboundMessageRepository {
+	return &inboundMessageRepository{tx}
+}
@@ -2,12 +2,43 @@ package repository
 
 import (
 	"koskosan-be/internal/models"
+	"koskosan-be/internal/utils"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// Urutan hasil pencarian kamar; kosong = urut ID
+const (
+	KamarSortPriceAsc  = "price_asc"
+	KamarSortPriceDesc = "price_desc"
+	KamarSortRating    = "rating"
+	KamarSortNewest    = "newest"
+)
+
+// KamarFilter untuk pencarian kamar; field kosong = tidak difilter
+type KamarFilter struct {
+	TipeKamar   string
+	RoomTypeID  uint
+	Status      string
+	MinPrice    float64
+	MaxPrice    float64
+	Floor       int
+	MinCapacity int
+	Facilities  []string // nama; semua harus tercantum di Fasilitas (tidak peka huruf besar)
+	FacilityIDs []uint   // katalog Facility; kamar harus punya semuanya
+
+	// Kamar tanpa pemesanan Pending/Confirmed yang bertumpuk dengan [AvailableFrom, AvailableTo)
+	AvailableFrom time.Time
+	AvailableTo   time.Time
+
+	Sort string
+}
+
 type KamarRepository interface {
 	FindAll() ([]models.Kamar, error)
+	Search(filter KamarFilter, pagination *utils.Pagination) ([]models.Kamar, int64, error)
 	FindByID(id uint) (*models.Kamar, error)
 	Create(kamar *models.Kamar) error
 	Update(kamar *models.Kamar) error
@@ -16,6 +47,14 @@ type KamarRepository interface {
 	WithTx(tx *gorm.DB) KamarRepository
 	AddImage(image *models.KamarImage) error
 	DeleteImagesByKamarID(kamarID uint) error
+	// FindImages mengembalikan foto kamar sesuai urutan tampil
+	FindImages(kamarID uint) ([]models.KamarImage, error)
+	// DeleteImage menghapus permanen satu foto, merapikan urutan dan cover; gorm.ErrRecordNotFound jika tidak ada
+	DeleteImage(kamarID, imageID uint) (*models.KamarImage, error)
+	// ReorderImages menyimpan urutan foto (imageIDs[0] = cover) dan menyalin cover ke Kamar.ImageURL
+	ReorderImages(kamarID uint, imageIDs []uint) error
+	// ReplaceFacilities mengganti tautan fasilitas kamar dan menyinkronkan kolom teks Fasilitas
+	ReplaceFacilities(kamarID uint, facilities []models.Facility) error
 }
 
 type kamarRepository struct {
@@ -32,18 +71,128 @@ func (r *kamarRepository) FindAll() ([]models.Kamar, error) {
 	return kamars, err
 }
 
+func (r *kamarRepository) Search(filter KamarFilter, pagination *utils.Pagination) ([]models.Kamar, int64, error) {
+	var kamars []models.Kamar
+	var totalRows int64
+
+	query := r.db.Model(&models.Kamar{})
+	if filter.TipeKamar != "" {
+		query = query.Where("tipe_kamar = ?", filter.TipeKamar)
+	}
+	if filter.RoomTypeID != 0 {
+		query = query.Where("room_type_id = ?", filter.RoomTypeID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.MinPrice > 0 {
+		query = query.Where("harga_per_bulan >= ?", filter.MinPrice)
+	}
+	if filter.MaxPrice > 0 {
+		query = query.Where("harga_per_bulan <= ?", filter.MaxPrice)
+	}
+	if filter.Floor != 0 {
+		query = query.Where("floor = ?", filter.Floor)
+	}
+	if filter.MinCapacity > 0 {
+		query = query.Where("capacity >= ?", filter.MinCapacity)
+	}
+	for _, facility := range filter.Facilities {
+		query = query.Where("fasilitas ILIKE ?", "%"+escapeLike(facility)+"%")
+	}
+	if len(filter.FacilityIDs) > 0 {
+		query = query.Where(`kamars.id IN (
+			SELECT kamar_id FROM kamar_facilities WHERE facility_id IN ?
+			GROUP BY kamar_id HAVING COUNT(DISTINCT facility_id) = ?
+		)`, filter.FacilityIDs, len(uniqueIDs(filter.FacilityIDs)))
+	}
+	if !filter.AvailableFrom.IsZero() && !filter.AvailableTo.IsZero() {
+		// Masa sewa pemesanan: tanggal_mulai s/d tanggal_mulai + durasi_sewa bulan
+		query = query.Where(`NOT EXISTS (
+			SELECT 1 FROM pemesanans p
+			WHERE p.kamar_id = kamars.id AND p.deleted_at IS NULL
+				AND p.status_pemesanan IN ('Pending', 'Confirmed')
+				AND p.tanggal_mulai < ?
+				AND p.tanggal_mulai + p.durasi_sewa * INTERVAL '1 month' > ?
+		)`, filter.AvailableTo, filter.AvailableFrom)
+	}
+
+	query.Count(&totalRows)
+
+	switch filter.Sort {
+	case KamarSortPriceAsc:
+		query = query.Order("harga_per_bulan ASC")
+	case KamarSortPriceDesc:
+		query = query.Order("harga_per_bulan DESC")
+	case KamarSortRating:
+		query = query.Order(`(SELECT COALESCE(AVG(rv.rating), 0) FROM reviews rv
+			WHERE rv.kamar_id = kamars.id AND rv.deleted_at IS NULL) DESC`)
+	case KamarSortNewest:
+		query = query.Order("created_at DESC")
+	}
+
+	err := query.Scopes(utils.Paginate(models.Kamar{}, pagination, query)).
+		Preload("Facilities").
+		Preload("RoomType.Images").
+		Order("id ASC").
+		Find(&kamars).Error
+
+	return kamars, totalRows, err
+}
+
+// escapeLike supaya % dan _ dari input dicari sebagai karakter biasa
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
 func (r *kamarRepository) FindByID(id uint) (*models.Kamar, error) {
 	var kamar models.Kamar
-	err := r.db.Preload("Images").First(&kamar, id).Error
+	err := r.db.Preload("Images", orderImages).Preload("Facilities").Preload("RoomType.Images").First(&kamar, id).Error
 	return &kamar, err
 }
 
+// orderImages mengurutkan foto kamar; foto pertama adalah cover
+func orderImages(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order ASC").Order("id ASC")
+}
+
 func (r *kamarRepository) Create(kamar *models.Kamar) error {
-	return r.db.Create(kamar).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(kamar).Error; err != nil {
+			return err
+		}
+		return recordManualPrice(tx, kamar.ID, 0, kamar.HargaPerBulan)
+	})
 }
 
 func (r *kamarRepository) Update(kamar *models.Kamar) error {
-	return r.db.Save(kamar).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var previous []float64
+		if err := tx.Model(&models.Kamar{}).Where("id = ?", kamar.ID).Pluck("harga_per_bulan", &previous).Error; err != nil {
+			return err
+		}
+		// Tautan fasilitas hanya diubah lewat ReplaceFacilities, tipe kamar lewat RoomTypeID
+		if err := tx.Omit("Facilities", "RoomType").Save(kamar).Error; err != nil {
+			return err
+		}
+		if len(previous) == 0 || previous[0] == kamar.HargaPerBulan {
+			return nil
+		}
+		return recordManualPrice(tx, kamar.ID, previous[0], kamar.HargaPerBulan)
+	})
+}
+
+// recordManualPrice mencatat perubahan harga langsung; sewa berjalan tetap memakai harga kontraknya
+func recordManualPrice(tx *gorm.DB, kamarID uint, previous, harga float64) error {
+	now := time.Now()
+	return tx.Create(&models.KamarPriceHistory{
+		KamarID:       kamarID,
+		HargaSebelum:  previous,
+		HargaPerBulan: harga,
+		EffectiveFrom: now,
+		Source:        models.PriceChangeManual,
+		AppliedAt:     &now,
+	}).Error
 }
 
 func (r *kamarRepository) UpdateStatus(id uint, status string) error {
@@ -65,3 +214,92 @@ func (r *kamarRepository) AddImage(image *models.KamarImage) error {
 func (r *kamarRepository) DeleteImagesByKamarID(kamarID uint) error {
 	return r.db.Where("kamar_id = ?", kamarID).Delete(&models.KamarImage{}).Error
 }
+
+func (r *kamarRepository) FindImages(kamarID uint) ([]models.KamarImage, error) {
+	var images []models.KamarImage
+	err := orderImages(r.db.Where("kamar_id = ?", kamarID)).Find(&images).Error
+	return images, err
+}
+
+func (r *kamarRepository) DeleteImage(kamarID, imageID uint) (*models.KamarImage, error) {
+	var image models.KamarImage
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND kamar_id = ?", imageID, kamarID).First(&image).Error; err != nil {
+			return err
+		}
+		// Permanen: file-nya ikut dihapus, jadi record soft-delete tidak berguna
+		if err := tx.Unscoped().Delete(&image).Error; err != nil {
+			return err
+		}
+
+		var remaining []uint
+		if err := orderImages(tx.Model(&models.KamarImage{}).Where("kamar_id = ?", kamarID)).Pluck("id", &remaining).Error; err != nil {
+			return err
+		}
+		return saveImageOrder(tx, kamarID, remaining)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+func (r *kamarRepository) ReorderImages(kamarID uint, imageIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return saveImageOrder(tx, kamarID, imageIDs)
+	})
+}
+
+// saveImageOrder menomori ulang sort_order dan menyinkronkan Kamar.ImageURL dengan foto pertama
+func saveImageOrder(tx *gorm.DB, kamarID uint, imageIDs []uint) error {
+	for i, id := range imageIDs {
+		if err := tx.Model(&models.KamarImage{}).Where("id = ? AND kamar_id = ?", id, kamarID).
+			Update("sort_order", i).Error; err != nil {
+			return err
+		}
+	}
+
+	// Tanpa foto sendiri, cover memakai foto pertama tipe kamar (seperti ApplyRoomType)
+	var urls []string
+	query := tx.Model(&models.RoomTypeImage{}).
+		Where("room_type_id = (SELECT room_type_id FROM kamars WHERE id = ?)", kamarID).Order("id ASC").Limit(1)
+	if len(imageIDs) > 0 {
+		query = tx.Model(&models.KamarImage{}).Where("id = ?", imageIDs[0])
+	}
+	if err := query.Pluck("image_url", &urls).Error; err != nil {
+		return err
+	}
+	cover := ""
+	if len(urls) > 0 {
+		cover = urls[0]
+	}
+	return tx.Model(&models.Kamar{}).Where("id = ?", kamarID).Update("image_url", cover).Error
+}
+
+func (r *kamarRepository) ReplaceFacilities(kamarID uint, facilities []models.Facility) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		association := tx.Model(&models.Kamar{ID: kamarID}).Association("Facilities")
+		var err error
+		if len(facilities) == 0 {
+			err = association.Clear()
+		} else {
+			err = association.Replace(facilities)
+		}
+		if err != nil {
+			return err
+		}
+		return syncFasilitasText(tx, []uint{kamarID})
+	})
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := map[uint]bool{}
+	var unique []uint
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// MaintenanceTicketFilter untuk daftar tiket di halaman admin; field kosong = tidak difilter
+type MaintenanceTicketFilter struct {
+	Status     string
+	Category   string
+	Priority   string
+	KamarID    uint
+	AssignedTo uint
+}
+
+type MaintenanceRepository interface {
+	Create(ticket *models.MaintenanceTicket) error
+	FindByID(id uint) (*models.MaintenanceTicket, error)
+	FindByPenyewaID(penyewaID uint) ([]models.MaintenanceTicket, error)
+	FindAll(filter MaintenanceTicketFilter, pagination *utils.Pagination) ([]models.MaintenanceTicket, int64, error)
+	Save(ticket *models.MaintenanceTicket) error
+	AddComment(comment *models.MaintenanceTicketComment) error
+	WithTx(tx *gorm.DB) MaintenanceRepository
+}
+
+type maintenanceRepository struct {
+	db *gorm.DB
+}
+
+func NewMaintenanceRepository(db *gorm.DB) MaintenanceRepository {
+	return &maintenanceRepository{db}
+}
+
+// Create menyimpan tiket beserta fotonya
+func (r *maintenanceRepository) Create(ticket *models.MaintenanceTicket) error {
+	return r.db.Omit("Penyewa", "Kamar", "Assignee").Create(ticket).Error
+}
+
+// FindByID memuat tiket lengkap dengan tenant, kamar, admin penanggung jawab, foto dan komentar
+func (r *maintenanceRepository) FindByID(id uint) (*models.MaintenanceTicket, error) {
+	var ticket models.MaintenanceTicket
+	err := r.db.Preload("Penyewa").Preload("Kamar").Preload("Assignee").Preload("Photos").
+		Preload("Comments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC, id ASC")
+		}).
+		First(&ticket, id).Error
+	return &ticket, err
+}
+
+func (r *maintenanceRepository) FindByPenyewaID(penyewaID uint) ([]models.MaintenanceTicket, error) {
+	var tickets []models.MaintenanceTicket
+	err := r.db.Preload("Kamar").Preload("Photos").
+		Where("penyewa_id = ?", penyewaID).
+		Order("created_at DESC").
+		Find(&tickets).Error
+	return tickets, err
+}
+
+// FindAll mengurutkan tiket yang belum selesai dan prioritas tertinggi lebih dulu
+func (r *maintenanceRepository) FindAll(filter MaintenanceTicketFilter, pagination *utils.Pagination) ([]models.MaintenanceTicket, int64, error) {
+	var tickets []models.MaintenanceTicket
+	var totalRows int64
+
+	query := r.db.Model(&models.MaintenanceTicket{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.Priority != "" {
+		query = query.Where("priority = ?", filter.Priority)
+	}
+	if filter.KamarID != 0 {
+		query = query.Where("kamar_id = ?", filter.KamarID)
+	}
+	if filter.AssignedTo != 0 {
+		query = query.Where("assigned_to = ?", filter.AssignedTo)
+	}
+
+	query.Count(&totalRows)
+
+	err := query.Scopes(utils.Paginate(models.MaintenanceTicket{}, pagination, query)).
+		Preload("Penyewa").Preload("Kamar").Preload("Assignee").Preload("Photos").
+		Order(`CASE status WHEN 'open' THEN 0 WHEN 'in_progress' THEN 1 WHEN 'resolved' THEN 2 ELSE 3 END`).
+		Order(`CASE priority WHEN 'urgent' THEN 0 WHEN 'high' THEN 1 WHEN 'medium' THEN 2 ELSE 3 END`).
+		Order("created_at DESC").
+		Find(&tickets).Error
+
+	return tickets, totalRows, err
+}
+
+func (r *maintenanceRepository) Save(ticket *models.MaintenanceTicket) error {
+	// Omit asosiasi supaya Save tidak ikut menulis ulang tenant/kamar/foto yang di-preload
+	return r.db.Omit("Penyewa", "Kamar", "Assignee", "Photos", "Comments").Save(ticket).Error
+}
+
+func (r *maintenanceRepository) AddComment(comment *models.MaintenanceTicketComment) error {
+	return r.db.Create(comment).Error
+}
+
+func (r *maintenanceRepository) WithTx(tx *gorm.DB) MaintenanceRepository {
+	return &maintenanceRepository{db: tx}
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"koskosan-be/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MessageTemplateRepository interface {
+	Find(name, channel, language string) (*models.MessageTemplate, error)
+	FindAll() ([]models.MessageTemplate, error)
+	Upsert(tmpl *models.MessageTemplate) error
+	Delete(name, channel, language string) error
+	WithTx(tx *gorm.DB) MessageTemplateRepository
+}
+
+type messageTemplateRepository struct {
+	db *gorm.DB
+}
+
+func NewMessageTemplateRepository(db *gorm.DB) MessageTemplateRepository {
+	return &messageTemplateRepository{db}
+}
+
+func (r *messageTemplateRepository) Find(name, channel, language string) (*models.MessageTemplate, error) {
+	var tmpl models.MessageTemplate
+	err := r.db.Where("name = ? AND channel = ? AND language = ?", name, channel, language).First(&tmpl).Error
+	return &tmpl, err
+}
+
+func (r *messageTemplateRepository) FindAll() ([]models.MessageTemplate, error) {
+	var templates []models.MessageTemplate
+	err := r.db.Order("name, channel, language").Find(&templates).Error
+	return templates, err
+}
+
+// Upsert menyimpan template berdasarkan (name, channel, language)
+func (r *messageTemplateRepository) Upsert(tmpl *models.MessageTemplate) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}, {Name: "channel"}, {Name: "language"}},
+		DoUpdates: clause.AssignmentColumns([]string{"subject", "body", "updated_by", "updated_at"}),
+	}).Create(tmpl).Error
+}
+
+// Delete menghapus hasil edit admin sehingga template bawaan dipakai lagi
+func (r *messageTemplateRepository) Delete(name, channel, language string) error {
+	return r.db.Where("name = ? AND channel = ? AND language = ?", name, channel, language).
+		Delete(&models.MessageTemplate{}).Error
+}
+
+func (r *messageTemplateRepository) WithTx(tx *gorm.DB) MessageTemplateRepository {
+	return &messageTemplateRepository{db: tx}
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"errors"
+	"koskosan-be/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationPreferenceRepository interface {
+	FindByUserID(userID uint) ([]models.NotificationPreference, error)
+	Upsert(pref *models.NotificationPreference) error
+	FindSetting(userID uint) (*models.NotificationSetting, error)
+	SaveSetting(setting *models.NotificationSetting) error
+	WithTx(tx *gorm.DB) NotificationPreferenceRepository
+}
+
+type notificationPreferenceRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationPreferenceRepository(db *gorm.DB) NotificationPreferenceRepository {
+	return &notificationPreferenceRepository{db}
+}
+
+func (r *notificationPreferenceRepository) FindByUserID(userID uint) ([]models.NotificationPreference, error) {
+	var prefs []models.NotificationPreference
+	err := r.db.Where("user_id = ?", userID).Find(&prefs).Error
+	return prefs, err
+}
+
+// Upsert menyimpan preferensi berdasarkan (user_id, category)
+func (r *notificationPreferenceRepository) Upsert(pref *models.NotificationPreference) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "category"}},
+		DoUpdates: clause.AssignmentColumns([]string{"email", "whatsapp", "in_app", "updated_at"}),
+	}).Create(pref).Error
+}
+
+// FindSetting mengembalikan setting kosong (jam tenang nonaktif) jika user belum pernah menyimpan
+func (r *notificationPreferenceRepository) FindSetting(userID uint) (*models.NotificationSetting, error) {
+	var setting models.NotificationSetting
+	err := r.db.Where("user_id = ?", userID).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.NotificationSetting{UserID: userID}, nil
+	}
+	return &setting, err
+}
+
+func (r *notificationPreferenceRepository) SaveSetting(setting *models.NotificationSetting) error {
+	return r.db.Save(setting).Error
+}
+
+func (r *notificationPreferenceRepository) WithTx(tx *gorm.DB) NotificationPreferenceRepository {
+	return &notificationPreferenceRepository{db: tx}
+}
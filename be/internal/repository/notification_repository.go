@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRepository interface {
+	Create(notification *models.Notification) error
+	FindByUserID(userID uint, pagination *utils.Pagination, unreadOnly bool) ([]models.Notification, int64, error)
+	CountUnread(userID uint) (int64, error)
+	MarkRead(id uint, userID uint, readAt time.Time) error
+	MarkAllRead(userID uint, readAt time.Time) (int64, error)
+	WithTx(tx *gorm.DB) NotificationRepository
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db}
+}
+
+func (r *notificationRepository) Create(notification *models.Notification) error {
+	return r.db.Create(notification).Error
+}
+
+// FindByUserID mengambil inbox user, terbaru lebih dulu
+func (r *notificationRepository) FindByUserID(userID uint, pagination *utils.Pagination, unreadOnly bool) ([]models.Notification, int64, error) {
+	var notifications []models.Notification
+	var totalRows int64
+
+	query := r.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	query.Count(&totalRows)
+
+	err := query.Scopes(utils.Paginate(models.Notification{}, pagination, query)).
+		Order("created_at DESC, id DESC").
+		Find(&notifications).Error
+
+	return notifications, totalRows, err
+}
+
+func (r *notificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// MarkRead menandai satu notifikasi milik user sebagai dibaca.
+// Mengembalikan gorm.ErrRecordNotFound jika notifikasi bukan milik user tersebut.
+func (r *notificationRepository) MarkRead(id uint, userID uint, readAt time.Time) error {
+	var notification models.Notification
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&notification).Error; err != nil {
+		return err
+	}
+	if notification.ReadAt != nil {
+		return nil
+	}
+	return r.db.Model(&notification).Update("read_at", readAt).Error
+}
+
+func (r *notificationRepository) MarkAllRead(userID uint, readAt time.Time) (int64, error) {
+	result := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", readAt)
+	return result.RowsAffected, result.Error
+}
+
+func (r *notificationRepository) WithTx(tx *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: tx}
+}
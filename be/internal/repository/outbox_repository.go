@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/utils"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepository interface {
+	Create(msg *models.OutboxMessage) error
+	FindByID(id uint) (*models.OutboxMessage, error)
+	FindByProviderMessageID(channel, providerMessageID string) (*models.OutboxMessage, error)
+	FindByStatus(status string, pagination *utils.Pagination) ([]models.OutboxMessage, int64, error)
+	ClaimDue(now time.Time, limit int, lease time.Duration) ([]models.OutboxMessage, error)
+	Save(msg *models.OutboxMessage) error
+	WithTx(tx *gorm.DB) OutboxRepository
+}
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db}
+}
+
+func (r *outboxRepository) Create(msg *models.OutboxMessage) error {
+	return r.db.Create(msg).Error
+}
+
+func (r *outboxRepository) FindByID(id uint) (*models.OutboxMessage, error) {
+	var msg models.OutboxMessage
+	err := r.db.First(&msg, id).Error
+	return &msg, err
+}
+
+func (r *outboxRepository) FindByProviderMessageID(channel, providerMessageID string) (*models.OutboxMessage, error) {
+	var msg models.OutboxMessage
+	err := r.db.Where("channel = ? AND provider_message_id = ?", channel, providerMessageID).First(&msg).Error
+	return &msg, err
+}
+
+// FindByStatus untuk tampilan admin; status kosong = semua
+func (r *outboxRepository) FindByStatus(status string, pagination *utils.Pagination) ([]models.OutboxMessage, int64, error) {
+	var messages []models.OutboxMessage
+	var totalRows int64
+
+	query := r.db.Model(&models.OutboxMessage{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	query.Count(&totalRows)
+
+	err := query.Scopes(utils.Paginate(models.OutboxMessage{}, pagination, query)).
+		Order("updated_at DESC, id DESC").
+		Find(&messages).Error
+
+	return messages, totalRows, err
+}
+
+// ClaimDue mengambil pesan pending yang sudah waktunya dikirim dan menggeser next_retry_at sejauh lease,
+// supaya worker di instance lain tidak mengambil pesan yang sama. Jika worker mati di tengah jalan,
+// pesan otomatis diambil lagi setelah lease habis.
+func (r *outboxRepository) ClaimDue(now time.Time, limit int, lease time.Duration) ([]models.OutboxMessage, error) {
+	var messages []models.OutboxMessage
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_retry_at <= ?", models.OutboxStatusPending, now).
+			Order("next_retry_at ASC").
+			Limit(limit).
+			Find(&messages).Error; err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(messages))
+		for i, m := range messages {
+			ids[i] = m.ID
+		}
+		return tx.Model(&models.OutboxMessage{}).
+			Where("id IN ?", ids).
+			Update("next_retry_at", now.Add(lease)).Error
+	})
+	return messages, err
+}
+
+func (r *outboxRepository) Save(msg *models.OutboxMessage) error {
+	return r.db.Save(msg).Error
+}
+
+func (r *outboxRepository) WithTx(tx *gorm.DB) OutboxRepository {
+	return &outboxRepository{db: tx}
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"koskosan-be/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PasswordResetRepository interface {
+	Create(token *models.PasswordResetToken) error
+	Consume(tokenHash string, now time.Time) (*models.PasswordResetToken, error)
+	InvalidateForUser(userID uint, now time.Time) error
+	WithTx(tx *gorm.DB) PasswordResetRepository
+}
+
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{db}
+}
+
+func (r *passwordResetRepository) Create(token *models.PasswordResetToken) error {
+	return r.db.Create(token).Error
+}
+
+// Consume menandai token sebagai terpakai dalam satu UPDATE, sehingga dua request
+// paralel dengan token yang sama tidak bisa sama-sama berhasil.
+func (r *passwordResetRepository) Consume(tokenHash string, now time.Time) (*models.PasswordResetToken, error) {
+	var tokens []models.PasswordResetToken
+	result := r.db.Model(&tokens).
+		Clauses(clause.Returning{}).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 || len(tokens) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &tokens[0], nil
+}
+
+// InvalidateForUser menandai semua token user yang belum terpakai sebagai terpakai
+func (r *passwordResetRepository) InvalidateForUser(userID uint, now time.Time) error {
+	return r.db.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", now).Error
+}
+
+func (r *passwordResetRepository) WithTx(tx *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{db: tx}
+}
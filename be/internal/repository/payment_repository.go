@@ -15,6 +15,7 @@ type PaymentRepository interface {
 	Update(payment *models.Pembayaran) error
 	DeleteByBookingID(bookingID uint) error
 	DeleteRemindersByBookingID(bookingID uint) error
+	UpdateReminderStatus(pembayaranID uint, status string) error
 	WithTx(tx *gorm.DB) PaymentRepository
 }
 
@@ -75,3 +76,9 @@ func (r *paymentRepository) DeleteRemindersByBookingID(bookingID uint) error {
 		bookingID,
 	).Delete(&models.PaymentReminder{}).Error
 }
+
+func (r *paymentRepository) UpdateReminderStatus(pembayaranID uint, status string) error {
+	return r.db.Model(&models.PaymentReminder{}).
+		Where("pembayaran_id = ?", pembayaranID).
+		Update("status_reminder", status).Error
+}
@@ -3,14 +3,17 @@ package repository
 import (
 	"koskosan-be/internal/models"
 	"koskosan-be/internal/utils"
+	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PenyewaRepository interface {
 	FindByUserID(userID uint) (*models.Penyewa, error)
 	FindByID(id uint) (*models.Penyewa, error)
 	FindByEmail(email string) (*models.Penyewa, error)
+	FindByPhone(phone string) (*models.Penyewa, error)
 	FindAll() ([]models.Penyewa, error)
 	FindByRole(role string) ([]models.Penyewa, error)
 	Create(penyewa *models.Penyewa) error
@@ -47,6 +50,18 @@ func (r *penyewaRepository) FindByEmail(email string) (*models.Penyewa, error) {
 	return &penyewa, err
 }
 
+// FindByPhone mencari penyewa dari nomor format 62xxx. NomorHP disimpan apa adanya dari form
+// (08xx, 62xx, +62 xx-xx), jadi dibandingkan setelah karakter non-digit dibuang.
+// Jika satu nomor dipakai beberapa akun, tenant aktif didahulukan.
+func (r *penyewaRepository) FindByPhone(phone string) (*models.Penyewa, error) {
+	var penyewa models.Penyewa
+	local := "0" + strings.TrimPrefix(phone, "62")
+	err := r.db.Where("regexp_replace(nomor_hp, '[^0-9]', '', 'g') IN ?", []string{phone, local}).
+		Order(clause.Expr{SQL: "CASE WHEN role = ? THEN 0 ELSE 1 END, updated_at DESC", Vars: []interface{}{"tenant"}}).
+		First(&penyewa).Error
+	return &penyewa, err
+}
+
 func (r *penyewaRepository) FindAll() ([]models.Penyewa, error) {
 	var penyewas []models.Penyewa
 	err := r.db.Preload("User").Find(&penyewas).Error
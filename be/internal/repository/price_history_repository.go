@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"koskosan-be/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PriceHistoryRepository interface {
+	// FindByKamarID mengembalikan riwayat harga kamar, terbaru dulu
+	FindByKamarID(kamarID uint) ([]models.KamarPriceHistory, error)
+	FindByID(id uint) (*models.KamarPriceHistory, error)
+	Create(entry *models.KamarPriceHistory) error
+	Delete(id uint) error
+	// FindPendingNotices: perubahan terjadwal yang belum diberitahukan dan berlaku paling lambat before
+	FindPendingNotices(before time.Time) ([]models.KamarPriceHistory, error)
+	MarkNoticeSent(id uint, at time.Time) error
+	// FindLeaseNotices: pemberitahuan per sewa yang sudah terkirim untuk perubahan ini
+	FindLeaseNotices(priceHistoryID uint) ([]models.RentChangeNotice, error)
+	CreateLeaseNotice(notice *models.RentChangeNotice) error
+	// FindDueChanges: perubahan terjadwal yang sudah berlaku tapi harga kamarnya belum diganti
+	FindDueChanges(now time.Time) ([]models.KamarPriceHistory, error)
+	// ApplyChange mengganti harga kamar (menjadi override tipe kamar) dan mengisi AppliedAt
+	ApplyChange(entry *models.KamarPriceHistory, at time.Time) error
+	// FindNoticedChanges: perubahan terjadwal kamar yang diberitahukan ke pemesanan setelah contractedAt,
+	// urut tanggal berlaku; Notices berisi pemberitahuan untuk pemesanan itu saja
+	FindNoticedChanges(kamarID, pemesananID uint, contractedAt time.Time) ([]models.KamarPriceHistory, error)
+	// FindRunningLeases: pemesanan Pending/Confirmed di kamar beserta Penyewa dan Kamar
+	FindRunningLeases(kamarID uint) ([]models.Pemesanan, error)
+	WithTx(tx *gorm.DB) PriceHistoryRepository
+}
+
+type priceHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewPriceHistoryRepository(db *gorm.DB) PriceHistoryRepository {
+	return &priceHistoryRepository{db}
+}
+
+func (r *priceHistoryRepository) FindByKamarID(kamarID uint) ([]models.KamarPriceHistory, error) {
+	var entries []models.KamarPriceHistory
+	err := r.db.Where("kamar_id = ?", kamarID).Order("effective_from DESC").Order("id DESC").Find(&entries).Error
+	return entries, err
+}
+
+func (r *priceHistoryRepository) FindByID(id uint) (*models.KamarPriceHistory, error) {
+	var entry models.KamarPriceHistory
+	err := r.db.First(&entry, id).Error
+	return &entry, err
+}
+
+func (r *priceHistoryRepository) Create(entry *models.KamarPriceHistory) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *priceHistoryRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("price_history_id = ?", id).Delete(&models.RentChangeNotice{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.KamarPriceHistory{}, id).Error
+	})
+}
+
+func (r *priceHistoryRepository) FindPendingNotices(before time.Time) ([]models.KamarPriceHistory, error) {
+	var entries []models.KamarPriceHistory
+	err := r.db.Where("source = ? AND notice_sent_at IS NULL AND effective_from <= ?", models.PriceChangeScheduled, before).
+		Order("effective_from ASC").Find(&entries).Error
+	return entries, err
+}
+
+func (r *priceHistoryRepository) MarkNoticeSent(id uint, at time.Time) error {
+	return r.db.Model(&models.KamarPriceHistory{}).Where("id = ?", id).Update("notice_sent_at", at).Error
+}
+
+func (r *priceHistoryRepository) FindLeaseNotices(priceHistoryID uint) ([]models.RentChangeNotice, error) {
+	var notices []models.RentChangeNotice
+	err := r.db.Where("price_history_id = ?", priceHistoryID).Find(&notices).Error
+	return notices, err
+}
+
+func (r *priceHistoryRepository) CreateLeaseNotice(notice *models.RentChangeNotice) error {
+	return r.db.Create(notice).Error
+}
+
+func (r *priceHistoryRepository) FindDueChanges(now time.Time) ([]models.KamarPriceHistory, error) {
+	var entries []models.KamarPriceHistory
+	err := r.db.Where("source = ? AND applied_at IS NULL AND effective_from <= ?", models.PriceChangeScheduled, now).
+		Order("effective_from ASC").Order("id ASC").Find(&entries).Error
+	return entries, err
+}
+
+func (r *priceHistoryRepository) ApplyChange(entry *models.KamarPriceHistory, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Kamar{}).Where("id = ?", entry.KamarID).Updates(map[string]interface{}{
+			"harga_per_bulan": entry.HargaPerBulan,
+			"override_price":  true,
+		}).Error; err != nil {
+			return err
+		}
+		entry.AppliedAt = &at
+		return tx.Model(entry).Update("applied_at", at).Error
+	})
+}
+
+func (r *priceHistoryRepository) FindNoticedChanges(kamarID, pemesananID uint, contractedAt time.Time) ([]models.KamarPriceHistory, error) {
+	var entries []models.KamarPriceHistory
+	err := r.db.Preload("Notices", "pemesanan_id = ?", pemesananID).
+		Where("kamar_id = ? AND source = ?", kamarID, models.PriceChangeScheduled).
+		Where(`notice_sent_at > ? OR EXISTS (SELECT 1 FROM rent_change_notices n
+			WHERE n.price_history_id = kamar_price_histories.id AND n.pemesanan_id = ? AND n.sent_at > ?)`,
+			contractedAt, pemesananID, contractedAt).
+		Order("effective_from ASC").Order("id ASC").Find(&entries).Error
+	return entries, err
+}
+
+func (r *priceHistoryRepository) FindRunningLeases(kamarID uint) ([]models.Pemesanan, error) {
+	var bookings []models.Pemesanan
+	err := r.db.Preload("Penyewa").Preload("Kamar").
+		Where("kamar_id = ? AND status_pemesanan IN ?", kamarID, []string{"Pending", "Confirmed"}).
+		Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *priceHistoryRepository) WithTx(tx *gorm.DB) PriceHistoryRepository {
+	return &priceHistoryRepository{db: tx}
+}
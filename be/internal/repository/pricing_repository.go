@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"errors"
+	"koskosan-be/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrVoucherExhausted dikembalikan ClaimVoucher jika kuota voucher sudah habis
+var ErrVoucherExhausted = errors.New("kuota voucher sudah habis")
+
+type PricingRepository interface {
+	FindAll() ([]models.PricingRule, error)
+	FindByID(id uint) (*models.PricingRule, error)
+	// FindActive mengembalikan aturan aktif tanpa kode (long_stay dan promotion)
+	FindActive() ([]models.PricingRule, error)
+	// FindVoucher mencari voucher (aktif maupun tidak) berdasarkan kode; gorm.ErrRecordNotFound jika tidak ada
+	FindVoucher(code string) (*models.PricingRule, error)
+	Create(rule *models.PricingRule) error
+	Save(rule *models.PricingRule) error
+	Delete(id uint) error
+	// ClaimVoucher menambah UsedCount secara atomik selama kuota masih ada
+	ClaimVoucher(id uint) error
+	// ReleaseVoucher mengurangi UsedCount sebanyak count, tidak sampai di bawah nol
+	ReleaseVoucher(id uint, count int) error
+	// FindBookingVoucherIDs mengembalikan ID voucher per baris diskon pemesanan (duplikat tetap ada),
+	// tanpa diskon pembayaran perpanjangan yang ditolak
+	FindBookingVoucherIDs(pemesananID uint) ([]uint, error)
+	// FindPaymentVoucherIDs mengembalikan ID voucher yang dipakai sebuah pembayaran perpanjangan
+	FindPaymentVoucherIDs(pembayaranID uint) ([]uint, error)
+	CreateDiscounts(discounts []models.BookingDiscount) error
+	WithTx(tx *gorm.DB) PricingRepository
+}
+
+type pricingRepository struct {
+	db *gorm.DB
+}
+
+func NewPricingRepository(db *gorm.DB) PricingRepository {
+	return &pricingRepository{db}
+}
+
+func (r *pricingRepository) FindAll() ([]models.PricingRule, error) {
+	var rules []models.PricingRule
+	err := r.db.Order("type ASC").Order("created_at DESC").Find(&rules).Error
+	return rules, err
+}
+
+func (r *pricingRepository) FindByID(id uint) (*models.PricingRule, error) {
+	var rule models.PricingRule
+	err := r.db.First(&rule, id).Error
+	return &rule, err
+}
+
+func (r *pricingRepository) FindActive() ([]models.PricingRule, error) {
+	var rules []models.PricingRule
+	err := r.db.Where("active = ? AND type IN ?", true, []string{models.PricingRuleLongStay, models.PricingRulePromotion}).
+		Find(&rules).Error
+	return rules, err
+}
+
+func (r *pricingRepository) FindVoucher(code string) (*models.PricingRule, error) {
+	var rule models.PricingRule
+	err := r.db.Where("type = ? AND code = ?", models.PricingRuleVoucher, code).First(&rule).Error
+	return &rule, err
+}
+
+func (r *pricingRepository) Create(rule *models.PricingRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *pricingRepository) Save(rule *models.PricingRule) error {
+	return r.db.Save(rule).Error
+}
+
+func (r *pricingRepository) Delete(id uint) error {
+	return r.db.Delete(&models.PricingRule{}, id).Error
+}
+
+func (r *pricingRepository) ClaimVoucher(id uint) error {
+	result := r.db.Model(&models.PricingRule{}).
+		Where("id = ? AND (max_uses = 0 OR used_count < max_uses)", id).
+		Update("used_count", gorm.Expr("used_count + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVoucherExhausted
+	}
+	return nil
+}
+
+func (r *pricingRepository) ReleaseVoucher(id uint, count int) error {
+	return r.db.Model(&models.PricingRule{}).Where("id = ?", id).
+		Update("used_count", gorm.Expr("GREATEST(used_count - ?, 0)", count)).Error
+}
+
+func (r *pricingRepository) FindBookingVoucherIDs(pemesananID uint) ([]uint, error) {
+	var ids []uint
+	// Voucher perpanjangan yang pembayarannya ditolak sudah dikembalikan saat penolakan
+	err := r.db.Model(&models.BookingDiscount{}).
+		Where("pemesanan_id = ? AND type = ?", pemesananID, models.PricingRuleVoucher).
+		Where("pembayaran_id IS NULL OR pembayaran_id NOT IN (?)",
+			r.db.Model(&models.Pembayaran{}).Select("id").Where("status_pembayaran = ?", "Rejected")).
+		Pluck("pricing_rule_id", &ids).Error
+	return ids, err
+}
+
+func (r *pricingRepository) FindPaymentVoucherIDs(pembayaranID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.BookingDiscount{}).
+		Where("pembayaran_id = ? AND type = ?", pembayaranID, models.PricingRuleVoucher).
+		Pluck("pricing_rule_id", &ids).Error
+	return ids, err
+}
+
+func (r *pricingRepository) CreateDiscounts(discounts []models.BookingDiscount) error {
+	if len(discounts) == 0 {
+		return nil
+	}
+	return r.db.Create(&discounts).Error
+}
+
+func (r *pricingRepository) WithTx(tx *gorm.DB) PricingRepository {
+	return &pricingRepository{db: tx}
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"koskosan-be/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type RoomTypeRepository interface {
+	FindAll() ([]models.RoomType, error)
+	FindByID(id uint) (*models.RoomType, error)
+	// FindByName tidak peka huruf besar; gorm.ErrRecordNotFound jika belum ada
+	FindByName(name string) (*models.RoomType, error)
+	Create(roomType *models.RoomType) error
+	// Save juga menurunkan nilai bawaan ke kamar bertipe ini yang tidak meng-override
+	Save(roomType *models.RoomType) error
+	// Delete mengembalikan foto tipe yang ikut terhapus supaya file-nya bisa dibersihkan
+	Delete(id uint) ([]models.RoomTypeImage, error)
+	CountKamars(id uint) (int64, error)
+	AddImage(image *models.RoomTypeImage) error
+	// DeleteImage mengembalikan foto yang dihapus; cover kamar yang mewarisi foto tipe ikut disesuaikan
+	DeleteImage(roomTypeID, imageID uint) (*models.RoomTypeImage, error)
+	WithTx(tx *gorm.DB) RoomTypeRepository
+}
+
+type roomTypeRepository struct {
+	db *gorm.DB
+}
+
+func NewRoomTypeRepository(db *gorm.DB) RoomTypeRepository {
+	return &roomTypeRepository{db}
+}
+
+func (r *roomTypeRepository) FindAll() ([]models.RoomType, error) {
+	var roomTypes []models.RoomType
+	err := r.db.Preload("Images").Order("name ASC").Find(&roomTypes).Error
+	return roomTypes, err
+}
+
+func (r *roomTypeRepository) FindByID(id uint) (*models.RoomType, error) {
+	var roomType models.RoomType
+	err := r.db.Preload("Images").First(&roomType, id).Error
+	return &roomType, err
+}
+
+func (r *roomTypeRepository) FindByName(name string) (*models.RoomType, error) {
+	var roomType models.RoomType
+	err := r.db.Where("LOWER(name) = LOWER(?)", name).First(&roomType).Error
+	return &roomType, err
+}
+
+func (r *roomTypeRepository) Create(roomType *models.RoomType) error {
+	return r.db.Omit("Images").Create(roomType).Error
+}
+
+func (r *roomTypeRepository) Save(roomType *models.RoomType) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Images").Save(roomType).Error; err != nil {
+			return err
+		}
+		return syncRoomTypeToKamars(tx, roomType)
+	})
+}
+
+func (r *roomTypeRepository) Delete(id uint) ([]models.RoomTypeImage, error) {
+	var images []models.RoomTypeImage
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("room_type_id = ?", id).Find(&images).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("room_type_id = ?", id).Delete(&models.RoomTypeImage{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.RoomType{}, id).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+func (r *roomTypeRepository) CountKamars(id uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Kamar{}).Where("room_type_id = ?", id).Count(&count).Error
+	return count, err
+}
+
+func (r *roomTypeRepository) AddImage(image *models.RoomTypeImage) error {
+	return r.db.Create(image).Error
+}
+
+func (r *roomTypeRepository) DeleteImage(roomTypeID, imageID uint) (*models.RoomTypeImage, error) {
+	var image models.RoomTypeImage
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND room_type_id = ?", imageID, roomTypeID).First(&image).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&image).Error; err != nil {
+			return err
+		}
+
+		// Kamar tanpa foto sendiri memakai foto tipe sebagai cover; hitung ulang dengan aturan saveImageOrder
+		var kamarIDs []uint
+		if err := tx.Model(&models.Kamar{}).Where("room_type_id = ?", roomTypeID).
+			Where("NOT EXISTS (SELECT 1 FROM kamar_images WHERE kamar_images.kamar_id = kamars.id AND kamar_images.deleted_at IS NULL)").
+			Pluck("id", &kamarIDs).Error; err != nil {
+			return err
+		}
+		for _, kamarID := range kamarIDs {
+			if err := saveImageOrder(tx, kamarID, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+func (r *roomTypeRepository) WithTx(tx *gorm.DB) RoomTypeRepository {
+	return &roomTypeRepository{db: tx}
+}
+
+// syncRoomTypeToKamars menyalin nama dan nilai bawaan tipe ke kamarnya; field yang di-override kamar tidak disentuh
+func syncRoomTypeToKamars(db *gorm.DB, roomType *models.RoomType) error {
+	kamars := func() *gorm.DB {
+		return db.Model(&models.Kamar{}).Where("room_type_id = ?", roomType.ID)
+	}
+	updates := []struct {
+		override string
+		column   string
+		value    interface{}
+	}{
+		{"", "tipe_kamar", roomType.Name},
+		{"override_price", "harga_per_bulan", roomType.HargaPerBulan},
+		{"override_size", "size", roomType.Size},
+		{"override_capacity", "capacity", roomType.Capacity},
+		{"override_description", "description", roomType.Description},
+	}
+	// Riwayat harga untuk kamar yang harganya ikut berubah (sebelum kolomnya ditimpa)
+	if err := db.Exec(`INSERT INTO kamar_price_histories
+			(kamar_id, harga_sebelum, harga_per_bulan, effective_from, source, applied_at, created_at)
+		SELECT id, harga_per_bulan, ?, NOW(), ?, NOW(), NOW() FROM kamars
+		WHERE room_type_id = ? AND override_price = ? AND harga_per_bulan <> ? AND deleted_at IS NULL`,
+		roomType.HargaPerBulan, models.PriceChangeManual, roomType.ID, false, roomType.HargaPerBulan).Error; err != nil {
+		return err
+	}
+	for _, u := range updates {
+		query := kamars()
+		if u.override != "" {
+			query = query.Where(u.override+" = ?", false)
+		}
+		if err := query.Update(u.column, u.value).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"errors"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SpamRejectionCount adalah jumlah penolakan per form dan alasan
+type SpamRejectionCount struct {
+	Form   string `json:"form"`
+	Reason string `json:"reason"`
+	Count  int64  `json:"count"`
+}
+
+type SpamRepository interface {
+	FindSettings() (*models.SpamSettings, error)
+	SaveSettings(settings *models.SpamSettings) error
+	CreateRejection(rejection *models.SpamRejection) error
+	FindRejections(form, reason string, pagination *utils.Pagination) ([]models.SpamRejection, int64, error)
+	CountRejectionsSince(since time.Time) ([]SpamRejectionCount, error)
+	WithTx(tx *gorm.DB) SpamRepository
+}
+
+type spamRepository struct {
+	db *gorm.DB
+}
+
+func NewSpamRepository(db *gorm.DB) SpamRepository {
+	return &spamRepository{db}
+}
+
+// FindSettings mengembalikan setting kosong (ID 0) jika admin belum pernah menyimpan
+func (r *spamRepository) FindSettings() (*models.SpamSettings, error) {
+	var settings models.SpamSettings
+	err := r.db.Order("id ASC").First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.SpamSettings{}, nil
+	}
+	return &settings, err
+}
+
+func (r *spamRepository) SaveSettings(settings *models.SpamSettings) error {
+	return r.db.Save(settings).Error
+}
+
+func (r *spamRepository) CreateRejection(rejection *models.SpamRejection) error {
+	return r.db.Create(rejection).Error
+}
+
+func (r *spamRepository) FindRejections(form, reason string, pagination *utils.Pagination) ([]models.SpamRejection, int64, error) {
+	var rejections []models.SpamRejection
+	var totalRows int64
+
+	query := r.db.Model(&models.SpamRejection{})
+	if form != "" {
+		query = query.Where("form = ?", form)
+	}
+	if reason != "" {
+		query = query.Where("reason = ?", reason)
+	}
+
+	query.Count(&totalRows)
+
+	err := query.Scopes(utils.Paginate(models.SpamRejection{}, pagination, query)).
+		Order("created_at DESC").
+		Find(&rejections).Error
+
+	return rejections, totalRows, err
+}
+
+func (r *spamRepository) CountRejectionsSince(since time.Time) ([]SpamRejectionCount, error) {
+	var counts []SpamRejectionCount
+	err := r.db.Model(&models.SpamRejection{}).
+		Select("form, reason, COUNT(*) AS count").
+		Where("created_at >= ?", since).
+		Group("form, reason").
+		Order("count DESC").
+		Scan(&counts).Error
+	return counts, err
+}
+
+func (r *spamRepository) WithTx(tx *gorm.DB) SpamRepository {
+	return &spamRepository{db: tx}
+}
@@ -9,7 +9,6 @@ import (
 type UserRepository interface {
 	FindByUsername(username string) (*models.User, error)
 	FindByID(id uint) (*models.User, error)
-	FindByResetToken(token string) (*models.User, error)
 	Create(user *models.User) error
 	Update(user *models.User) error
 	WithTx(tx *gorm.DB) UserRepository
@@ -35,12 +34,6 @@ func (r *userRepository) FindByID(id uint) (*models.User, error) {
 	return &user, err
 }
 
-func (r *userRepository) FindByResetToken(token string) (*models.User, error) {
-	var user models.User
-	err := r.db.Where("reset_token = ?", token).First(&user).Error
-	return &user, err
-}
-
 func (r *userRepository) Create(user *models.User) error {
 	return r.db.Create(user).Error
 }
@@ -4,6 +4,8 @@ import (
 	"koskosan-be/internal/config"
 	"koskosan-be/internal/handlers"
 	"koskosan-be/internal/middleware"
+	"koskosan-be/internal/service"
+	"koskosan-be/internal/utils"
 
 	"github.com/gin-gonic/gin"
 	ginprometheus "github.com/zsais/go-gin-prometheus"
@@ -11,16 +13,28 @@ import (
 
 // Routes structure untuk organization yang lebih baik
 type Routes struct {
-	authHandler      *handlers.AuthHandler
-	kamarHandler     *handlers.KamarHandler
-	galleryHandler   *handlers.GalleryHandler
-	dashboardHandler *handlers.DashboardHandler
-	reviewHandler    *handlers.ReviewHandler
-	profileHandler   *handlers.ProfileHandler
-	bookingHandler   *handlers.BookingHandler
-	paymentHandler   *handlers.PaymentHandler
-	tenantHandler    *handlers.TenantHandler
-	contactHandler   *handlers.ContactHandler
+	authHandler         *handlers.AuthHandler
+	kamarHandler        *handlers.KamarHandler
+	galleryHandler      *handlers.GalleryHandler
+	dashboardHandler    *handlers.DashboardHandler
+	reviewHandler       *handlers.ReviewHandler
+	profileHandler      *handlers.ProfileHandler
+	bookingHandler      *handlers.BookingHandler
+	paymentHandler      *handlers.PaymentHandler
+	tenantHandler       *handlers.TenantHandler
+	contactHandler      *handlers.ContactHandler
+	notificationHandler *handlers.NotificationHandler
+	outboxHandler       *handlers.OutboxHandler
+	templateHandler     *handlers.MessageTemplateHandler
+	waWebhookHandler    *handlers.WhatsAppWebhookHandler
+	maintenanceHandler  *handlers.MaintenanceHandler
+	spamHandler         *handlers.SpamHandler
+	spamGuard           service.SpamGuard
+	facilityHandler     *handlers.FacilityHandler
+	roomTypeHandler     *handlers.RoomTypeHandler
+	pricingHandler      *handlers.PricingHandler
+	priceHistoryHandler *handlers.PriceHistoryHandler
+	fileHandler         *handlers.FileHandler
 }
 
 // NewRoutes initialize routes dengan semua handlers
@@ -35,36 +49,64 @@ func NewRoutes(
 	paymentHandler *handlers.PaymentHandler,
 	tenantHandler *handlers.TenantHandler,
 	contactHandler *handlers.ContactHandler,
+	notificationHandler *handlers.NotificationHandler,
+	outboxHandler *handlers.OutboxHandler,
+	templateHandler *handlers.MessageTemplateHandler,
+	waWebhookHandler *handlers.WhatsAppWebhookHandler,
+	maintenanceHandler *handlers.MaintenanceHandler,
+	spamHandler *handlers.SpamHandler,
+	spamGuard service.SpamGuard,
+	facilityHandler *handlers.FacilityHandler,
+	roomTypeHandler *handlers.RoomTypeHandler,
+	pricingHandler *handlers.PricingHandler,
+	priceHistoryHandler *handlers.PriceHistoryHandler,
+	fileHandler *handlers.FileHandler,
 ) *Routes {
 	return &Routes{
-		authHandler:      authHandler,
-		kamarHandler:     kamarHandler,
-		galleryHandler:   galleryHandler,
-		dashboardHandler: dashboardHandler,
-		reviewHandler:    reviewHandler,
-		profileHandler:   profileHandler,
-		bookingHandler:   bookingHandler,
-		paymentHandler:   paymentHandler,
-		tenantHandler:    tenantHandler,
-		contactHandler:   contactHandler,
+		authHandler:         authHandler,
+		kamarHandler:        kamarHandler,
+		galleryHandler:      galleryHandler,
+		dashboardHandler:    dashboardHandler,
+		reviewHandler:       reviewHandler,
+		profileHandler:      profileHandler,
+		bookingHandler:      bookingHandler,
+		paymentHandler:      paymentHandler,
+		tenantHandler:       tenantHandler,
+		contactHandler:      contactHandler,
+		notificationHandler: notificationHandler,
+		outboxHandler:       outboxHandler,
+		templateHandler:     templateHandler,
+		waWebhookHandler:    waWebhookHandler,
+		maintenanceHandler:  maintenanceHandler,
+		spamHandler:         spamHandler,
+		spamGuard:           spamGuard,
+		facilityHandler:     facilityHandler,
+		roomTypeHandler:     roomTypeHandler,
+		pricingHandler:      pricingHandler,
+		priceHistoryHandler: priceHistoryHandler,
+		fileHandler:         fileHandler,
 	}
 }
 
 // Register registers semua routes ke gin router
-func (r *Routes) Register(router *gin.Engine, cfg *config.Config) {
+func (r *Routes) Register(router *gin.Engine, cfg *config.Config, keys *utils.KeySet) {
 	// Prometheus Monitoring
 	p := ginprometheus.NewPrometheus("gin")
 	p.Use(router)
 
+	// Public keys untuk verifikasi JWT (RS256/EdDSA)
+	router.GET("/.well-known/jwks.json", r.authHandler.JWKS)
+
 	// API Route Group
 	api := router.Group("/api")
 	{
 		// Public routes - tidak perlu auth
 		r.registerPublicRoutes(api)
 
-		// Protected routes - perlu auth (dari cookie)
+		// Protected routes - perlu auth (dari cookie) + CSRF token untuk POST/PUT/DELETE
 		protected := api.Group("")
-		protected.Use(middleware.AuthMiddleware(cfg))
+		protected.Use(middleware.AuthMiddleware(keys))
+		protected.Use(middleware.CSRFMiddleware(cfg))
 		{
 			r.registerProtectedRoutes(protected)
 		}
@@ -78,9 +120,9 @@ func (r *Routes) registerPublicRoutes(api *gin.RouterGroup) {
 	{
 		// Strict rate limiting for login/register (prevent brute force)
 		auth.POST("/login", middleware.StrictRateLimit(), r.authHandler.Login)
-		auth.POST("/register", middleware.StrictRateLimit(), r.authHandler.Register)
+		auth.POST("/register", middleware.StrictRateLimit(), middleware.SpamProtection(r.spamGuard, service.SpamFormRegister, "username", "address"), r.authHandler.Register)
 		auth.POST("/google-login", middleware.StrictRateLimit(), r.authHandler.GoogleLogin)
-		auth.POST("/forgot-password", middleware.StrictRateLimit(), r.authHandler.ForgotPassword)
+		auth.POST("/forgot-password", middleware.StrictRateLimit(), middleware.SpamProtection(r.spamGuard, service.SpamFormForgotPassword), r.authHandler.ForgotPassword)
 		auth.POST("/reset-password", middleware.ModerateRateLimit(), r.authHandler.ResetPassword)
 		auth.POST("/refresh", r.authHandler.RefreshToken) // New: Token refresh endpoint
 		auth.POST("/logout", r.authHandler.Logout)        // New: Logout endpoint
@@ -94,17 +136,43 @@ func (r *Routes) registerPublicRoutes(api *gin.RouterGroup) {
 		kamar.GET("/:id/reviews", r.reviewHandler.GetReviews) // GET /api/kamar/:id/reviews
 	}
 
+	// File privat (bukti transfer) - akses dibatasi lewat signed URL, bukan session
+	api.GET("/files/*key", r.fileHandler.ServeSignedFile)
+
+	// Katalog fasilitas kamar
+	api.GET("/facilities", r.facilityHandler.GetFacilities)
+
+	// Tipe kamar (harga & foto bersama)
+	api.GET("/room-types", r.roomTypeHandler.GetRoomTypes)        // GET /api/room-types
+	api.GET("/room-types/:id", r.roomTypeHandler.GetRoomTypeByID) // GET /api/room-types/:id
+
+	// Simulasi harga sewa (diskon & voucher) sebelum memesan
+	api.GET("/pricing/quote", r.pricingHandler.GetQuote) // GET /api/pricing/quote
+
 	// Gallery
 	api.GET("/galleries", r.galleryHandler.GetGalleries)
 
 	// Reviews
 	api.GET("/reviews", r.reviewHandler.GetAllReviews)
 
-	// Contact form
-	api.POST("/contact", r.contactHandler.HandleContactForm)
+	// Token waktu mulai isi form publik (contact, register, forgot-password)
+	api.GET("/forms/token", middleware.ModerateRateLimit(), r.spamHandler.GetFormToken)
+
+	// Contact form (rate limit + honeypot/waktu isi/isi pesan/captcha)
+	api.POST("/contact", middleware.ModerateRateLimit(), middleware.SpamProtection(r.spamGuard, service.SpamFormContact, "name", "message"), r.contactHandler.HandleContactForm)
 
 	// Public stats (for login page)
 	api.GET("/public-stats", r.dashboardHandler.GetPublicStats)
+
+	// Callback status pengiriman & pesan masuk bot dari provider WhatsApp (diverifikasi lewat signature/token, bukan cookie)
+	webhooks := api.Group("/webhooks/whatsapp")
+	{
+		webhooks.GET("/meta", r.waWebhookHandler.VerifyMeta)
+		webhooks.POST("/meta", r.waWebhookHandler.MetaStatus) // status + pesan masuk
+		webhooks.POST("/generic", r.waWebhookHandler.GenericStatus)
+		webhooks.POST("/generic/messages", r.waWebhookHandler.GenericMessage)
+		webhooks.POST("/fonnte", r.waWebhookHandler.FonnteMessage)
+	}
 }
 
 // Protected routes (auth required)
@@ -115,6 +183,9 @@ func (r *Routes) registerProtectedRoutes(protected *gin.RouterGroup) {
 		profile.GET("", r.profileHandler.GetProfile)                     // GET /api/profile
 		profile.PUT("", r.profileHandler.UpdateProfile)                  // PUT /api/profile
 		profile.PUT("/change-password", r.profileHandler.ChangePassword) // PUT /api/profile/change-password
+
+		profile.GET("/notification-preferences", r.notificationHandler.GetPreferences)    // GET /api/profile/notification-preferences
+		profile.PUT("/notification-preferences", r.notificationHandler.UpdatePreferences) // PUT /api/profile/notification-preferences
 	}
 
 	// Bookings
@@ -132,12 +203,32 @@ func (r *Routes) registerProtectedRoutes(protected *gin.RouterGroup) {
 	{
 		payments.POST("", r.paymentHandler.CreatePayment)                // POST /api/payments
 		payments.POST("/:id/proof", r.paymentHandler.UploadPaymentProof) // POST /api/payments/:id/proof
+		payments.GET("/:id/proof", r.paymentHandler.GetPaymentProof)     // GET /api/payments/:id/proof (pemilik atau admin)
 		payments.GET("/reminders", r.paymentHandler.GetReminders)        // GET /api/payments/reminders
 	}
 
 	// Reviews
 	protected.POST("/reviews", r.reviewHandler.CreateReview)
 
+	// Tiket perbaikan/keluhan milik tenant
+	tickets := protected.Group("/tickets")
+	{
+		tickets.GET("", r.maintenanceHandler.GetMyTickets)               // GET /api/tickets
+		tickets.POST("", r.maintenanceHandler.CreateTicket)              // POST /api/tickets (multipart, photos[])
+		tickets.GET("/:id", r.maintenanceHandler.GetMyTicket)            // GET /api/tickets/:id
+		tickets.POST("/:id/comments", r.maintenanceHandler.AddMyComment) // POST /api/tickets/:id/comments
+	}
+
+	// Notifications (inbox + SSE fallback untuk socket.io)
+	notifications := protected.Group("/notifications")
+	{
+		notifications.GET("", r.notificationHandler.GetNotifications)            // GET /api/notifications
+		notifications.GET("/unread-count", r.notificationHandler.GetUnreadCount) // GET /api/notifications/unread-count
+		notifications.GET("/stream", r.notificationHandler.Stream)               // GET /api/notifications/stream
+		notifications.PUT("/read-all", r.notificationHandler.MarkAllRead)        // PUT /api/notifications/read-all
+		notifications.PUT("/:id/read", r.notificationHandler.MarkRead)           // PUT /api/notifications/:id/read
+	}
+
 	// Admin routes
 	r.registerAdminRoutes(protected)
 }
@@ -153,6 +244,44 @@ func (r *Routes) registerAdminRoutes(protected *gin.RouterGroup) {
 			kamar.POST("", r.kamarHandler.CreateKamar)       // POST /api/kamar
 			kamar.PUT("/:id", r.kamarHandler.UpdateKamar)    // PUT /api/kamar/:id
 			kamar.DELETE("/:id", r.kamarHandler.DeleteKamar) // DELETE /api/kamar/:id
+
+			// Foto kamar satu per satu; foto pertama = cover (image_url)
+			kamar.POST("/:id/images", r.kamarHandler.UploadKamarImages)                // POST /api/kamar/:id/images
+			kamar.PUT("/:id/images/order", r.kamarHandler.ReorderKamarImages)          // PUT /api/kamar/:id/images/order
+			kamar.PUT("/:id/images/:imageId/cover", r.kamarHandler.SetKamarCoverImage) // PUT /api/kamar/:id/images/:imageId/cover
+			kamar.DELETE("/:id/images/:imageId", r.kamarHandler.DeleteKamarImage)      // DELETE /api/kamar/:id/images/:imageId
+
+			// Riwayat harga & perubahan harga terjadwal (sewa berjalan diberi tahu lebih dulu)
+			kamar.GET("/:id/price-history", r.priceHistoryHandler.GetPriceHistory)                // GET /api/kamar/:id/price-history
+			kamar.POST("/:id/price-changes", r.priceHistoryHandler.SchedulePriceChange)           // POST /api/kamar/:id/price-changes
+			kamar.DELETE("/:id/price-changes/:changeId", r.priceHistoryHandler.CancelPriceChange) // DELETE /api/kamar/:id/price-changes/:changeId
+		}
+
+		// Katalog fasilitas
+		facilities := admin.Group("/facilities")
+		{
+			facilities.POST("", r.facilityHandler.CreateFacility)       // POST /api/facilities
+			facilities.PUT("/:id", r.facilityHandler.UpdateFacility)    // PUT /api/facilities/:id
+			facilities.DELETE("/:id", r.facilityHandler.DeleteFacility) // DELETE /api/facilities/:id
+		}
+
+		// Tipe kamar
+		roomTypes := admin.Group("/room-types")
+		{
+			roomTypes.POST("", r.roomTypeHandler.CreateRoomType)                            // POST /api/room-types
+			roomTypes.PUT("/:id", r.roomTypeHandler.UpdateRoomType)                         // PUT /api/room-types/:id
+			roomTypes.DELETE("/:id", r.roomTypeHandler.DeleteRoomType)                      // DELETE /api/room-types/:id
+			roomTypes.POST("/:id/images", r.roomTypeHandler.UploadRoomTypeImages)           // POST /api/room-types/:id/images
+			roomTypes.DELETE("/:id/images/:imageId", r.roomTypeHandler.DeleteRoomTypeImage) // DELETE /api/room-types/:id/images/:imageId
+		}
+
+		// Aturan harga: diskon sewa panjang, promosi dan voucher
+		pricingRules := admin.Group("/pricing-rules")
+		{
+			pricingRules.GET("", r.pricingHandler.GetPricingRules)          // GET /api/pricing-rules
+			pricingRules.POST("", r.pricingHandler.CreatePricingRule)       // POST /api/pricing-rules
+			pricingRules.PUT("/:id", r.pricingHandler.UpdatePricingRule)    // PUT /api/pricing-rules/:id
+			pricingRules.DELETE("/:id", r.pricingHandler.DeletePricingRule) // DELETE /api/pricing-rules/:id
 		}
 
 		// Gallery management
@@ -183,5 +312,51 @@ func (r *Routes) registerAdminRoutes(protected *gin.RouterGroup) {
 		admin.GET("/tenant-rooms", r.dashboardHandler.GetTenantRooms)
 		admin.GET("/room-payments/:id", r.dashboardHandler.GetPaymentsByRoom)
 		admin.GET("/tenant-payments/:id", r.dashboardHandler.GetPaymentsByTenant)
+
+		// Notification outbox (email/WhatsApp yang gagal terkirim)
+		deliveries := admin.Group("/notification-deliveries")
+		{
+			deliveries.GET("", r.outboxHandler.GetDeliveries)      // GET /api/notification-deliveries?status=failed
+			deliveries.POST("/:id/resend", r.outboxHandler.Resend) // POST /api/notification-deliveries/:id/resend
+		}
+
+		// Tiket perbaikan (semua tenant)
+		maintenance := admin.Group("/maintenance-tickets")
+		{
+			maintenance.GET("", r.maintenanceHandler.GetTickets)               // GET /api/maintenance-tickets?status=open
+			maintenance.GET("/:id", r.maintenanceHandler.GetTicket)            // GET /api/maintenance-tickets/:id
+			maintenance.PUT("/:id/assign", r.maintenanceHandler.AssignTicket)  // PUT /api/maintenance-tickets/:id/assign
+			maintenance.PUT("/:id/status", r.maintenanceHandler.UpdateStatus)  // PUT /api/maintenance-tickets/:id/status
+			maintenance.POST("/:id/comments", r.maintenanceHandler.AddComment) // POST /api/maintenance-tickets/:id/comments
+		}
+
+		// Inbox pesan contact form
+		contactMessages := admin.Group("/contact-messages")
+		{
+			contactMessages.GET("", r.contactHandler.GetMessages)                 // GET /api/contact-messages?status=new
+			contactMessages.GET("/unread-count", r.contactHandler.GetUnreadCount) // GET /api/contact-messages/unread-count
+			contactMessages.GET("/:id", r.contactHandler.GetMessage)              // GET /api/contact-messages/:id
+			contactMessages.PUT("/:id/assign", r.contactHandler.AssignMessage)    // PUT /api/contact-messages/:id/assign
+			contactMessages.POST("/:id/reply", r.contactHandler.Reply)            // POST /api/contact-messages/:id/reply
+			contactMessages.PUT("/:id/spam", r.contactHandler.MarkSpam)           // PUT /api/contact-messages/:id/spam
+		}
+
+		// Aturan anti-spam form publik dan log kiriman yang ditolak
+		spam := admin.Group("/spam")
+		{
+			spam.GET("/settings", r.spamHandler.GetSettings)     // GET /api/spam/settings
+			spam.PUT("/settings", r.spamHandler.UpdateSettings)  // PUT /api/spam/settings
+			spam.GET("/rejections", r.spamHandler.GetRejections) // GET /api/spam/rejections?form=contact&reason=too_fast
+			spam.GET("/stats", r.spamHandler.GetStats)           // GET /api/spam/stats?days=30
+		}
+
+		// Katalog template email/WhatsApp
+		messageTemplates := admin.Group("/message-templates")
+		{
+			messageTemplates.GET("", r.templateHandler.GetTemplates)                                      // GET /api/message-templates
+			messageTemplates.PUT("/:name/:channel/:language", r.templateHandler.UpdateTemplate)           // PUT /api/message-templates/:name/:channel/:language
+			messageTemplates.DELETE("/:name/:channel/:language", r.templateHandler.ResetTemplate)         // DELETE (kembali ke bawaan)
+			messageTemplates.POST("/:name/:channel/:language/preview", r.templateHandler.PreviewTemplate) // POST preview dengan data contoh
+		}
 	}
 }
@@ -10,15 +10,17 @@ import (
 type Scheduler struct {
 	cron            *cron.Cron
 	reminderService service.ReminderService
+	priceService    service.PriceHistoryService
 }
 
-func NewScheduler(reminderService service.ReminderService) *Scheduler {
+func NewScheduler(reminderService service.ReminderService, priceService service.PriceHistoryService) *Scheduler {
 	// Initialize cron with seconds precision if needed, but standard is fine.
 	// We use standard cron parser (Minute Hour Dom Month Dow)
 	c := cron.New()
 	return &Scheduler{
 		cron:            c,
 		reminderService: reminderService,
+		priceService:    priceService,
 	}
 }
 
@@ -28,6 +30,9 @@ func (s *Scheduler) Start() {
 	_, err := s.cron.AddFunc("0 8 * * *", func() {
 		log.Println("[Scheduler] Running daily payment reminder check...")
 
+		// 0. Rent changes: notify tenants ahead of time, then switch room prices that took effect
+		s.runPriceChanges()
+
 		// 1. Create monthly reminders if needed
 		if err := s.reminderService.CreateMonthlyReminders(); err != nil {
 			log.Printf("[Scheduler] Error creating reminders: %v", err)
@@ -52,6 +57,7 @@ func (s *Scheduler) Start() {
 	// Trigger immediately on start for testing/catch-up
 	go func() {
 		log.Println("[Scheduler] Running initial startup payment reminder check...")
+		s.runPriceChanges()
 		if err := s.reminderService.CreateMonthlyReminders(); err != nil {
 			log.Printf("[Scheduler] Error creating reminders on startup: %v", err)
 		}
@@ -62,6 +68,25 @@ func (s *Scheduler) Start() {
 	}()
 }
 
+// runPriceChanges mengirim pemberitahuan perubahan sewa dan menerapkan harga kamar yang sudah berlaku
+func (s *Scheduler) runPriceChanges() {
+	if s.priceService == nil {
+		return
+	}
+	notices, err := s.priceService.SendDueNotices()
+	if err != nil {
+		log.Printf("[Scheduler] Error sending rent change notices: %v", err)
+	} else if notices > 0 {
+		log.Printf("[Scheduler] Sent notices for %d rent changes", notices)
+	}
+	applied, err := s.priceService.ApplyDueChanges()
+	if err != nil {
+		log.Printf("[Scheduler] Error applying price changes: %v", err)
+	} else if applied > 0 {
+		log.Printf("[Scheduler] Applied %d price changes", applied)
+	}
+}
+
 func (s *Scheduler) Stop() {
 	s.cron.Stop()
 }
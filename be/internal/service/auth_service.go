@@ -10,28 +10,34 @@ import (
 	"log"
 	"time"
 
-	github_uuid "github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type AuthService interface {
 	Login(username, password string) (string, *models.User, error)
 	Register(username, password, role, email, phone, address, birthdate, nik string) (*models.User, error)
 	GoogleLogin(idToken, username, picture string) (string, *models.User, error)
-	ForgotPassword(email string) error
+	ForgotPassword(email, clientIP string) error
 	ResetPassword(token, newPassword string) error
 }
 
+// PasswordResetTokenExpiry adalah masa berlaku link reset password
+const PasswordResetTokenExpiry = 1 * time.Hour
+
 type authService struct {
 	repo           repository.UserRepository
 	penyewaRepo    repository.PenyewaRepository
+	resetRepo      repository.PasswordResetRepository
+	db             *gorm.DB
 	config         *config.Config
+	keys           *utils.KeySet
 	emailSender    utils.EmailSender
 	googleVerifier utils.IDTokenVerifier
 }
 
-func NewAuthService(repo repository.UserRepository, penyewaRepo repository.PenyewaRepository, cfg *config.Config, emailSender utils.EmailSender, googleVerifier utils.IDTokenVerifier) AuthService {
-	return &authService{repo, penyewaRepo, cfg, emailSender, googleVerifier}
+func NewAuthService(repo repository.UserRepository, penyewaRepo repository.PenyewaRepository, resetRepo repository.PasswordResetRepository, db *gorm.DB, cfg *config.Config, keys *utils.KeySet, emailSender utils.EmailSender, googleVerifier utils.IDTokenVerifier) AuthService {
+	return &authService{repo, penyewaRepo, resetRepo, db, cfg, keys, emailSender, googleVerifier}
 }
 
 func (s *authService) Login(username, password string) (string, *models.User, error) {
@@ -51,7 +57,7 @@ func (s *authService) Login(username, password string) (string, *models.User, er
 	}
 
 	// Generate token pair (access + refresh)
-	accessToken, _, err := utils.GenerateTokenPair(int(user.ID), user.Username, user.Role, s.config.JWTSecret)
+	accessToken, _, err := utils.GenerateTokenPair(int(user.ID), user.Username, user.Role, s.keys)
 	if err != nil {
 		return "", nil, err
 	}
@@ -163,7 +169,7 @@ func (s *authService) GoogleLogin(idToken, username, picture string) (string, *m
 	}
 
 	// 5. Generate JWT Token
-	accessToken, _, err := utils.GenerateTokenPair(int(user.ID), user.Username, user.Role, s.config.JWTSecret)
+	accessToken, _, err := utils.GenerateTokenPair(int(user.ID), user.Username, user.Role, s.keys)
 	if err != nil {
 		return "", nil, err
 	}
@@ -171,7 +177,7 @@ func (s *authService) GoogleLogin(idToken, username, picture string) (string, *m
 	return accessToken, user, nil
 }
 
-func (s *authService) ForgotPassword(email string) error {
+func (s *authService) ForgotPassword(email, clientIP string) error {
 	// 1. Try to find user by username (assuming username might be email)
 	user, err := s.repo.FindByUsername(email)
 	if err != nil {
@@ -190,19 +196,25 @@ func (s *authService) ForgotPassword(email string) error {
 		}
 	}
 
-	// 3. Generate Token
-	token := github_uuid.New().String()
-	expiry := time.Now().Add(1 * time.Hour) // 1 hour expiry
+	// 3. Generate Token. Only the SHA-256 hash is stored; the raw token only travels in the email.
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		log.Printf("Failed to generate reset token for user %d: %v", user.ID, err)
+		return nil
+	}
 
-	// 4. Save token to user
-	user.ResetToken = token
-	user.ResetTokenExpiry = expiry
-	if err := s.repo.Update(user); err != nil {
-		log.Printf("Failed to save reset token for user %s: %v", email, err)
+	resetToken := &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		CreatedIP: clientIP,
+		ExpiresAt: time.Now().Add(PasswordResetTokenExpiry),
+	}
+	if err := s.resetRepo.Create(resetToken); err != nil {
+		log.Printf("Failed to save reset token for user %d: %v", user.ID, err)
 		return nil // Still return nil to prevent enumeration
 	}
 
-	// 5. Send Email (errors in email sending shouldn't reveal user existence)
+	// 4. Send Email (errors in email sending shouldn't reveal user existence)
 	if err := s.emailSender.SendResetPasswordEmail(email, token); err != nil {
 		log.Printf("Failed to send reset email to %s: %v", email, err)
 		// Don't return error to prevent enumeration
@@ -212,13 +224,32 @@ func (s *authService) ForgotPassword(email string) error {
 }
 
 func (s *authService) ResetPassword(token, newPassword string) error {
-	user, err := s.repo.FindByResetToken(token)
+	if s.db == nil {
+		return s.resetPassword(nil, token, newPassword)
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return s.resetPassword(tx, token, newPassword)
+	})
+}
+
+// resetPassword: token baru benar-benar terpakai jika password berhasil diganti;
+// kegagalan di langkah mana pun me-rollback Consume sehingga link masih bisa dipakai ulang
+func (s *authService) resetPassword(tx *gorm.DB, token, newPassword string) error {
+	repo, resetRepo := s.repo, s.resetRepo
+	if tx != nil {
+		repo, resetRepo = repo.WithTx(tx), resetRepo.WithTx(tx)
+	}
+	now := time.Now()
+
+	// Consume marks the token used atomically, so it cannot be replayed
+	resetToken, err := resetRepo.Consume(utils.HashToken(token), now)
 	if err != nil {
 		return errors.New("invalid or expired token")
 	}
 
-	if time.Now().After(user.ResetTokenExpiry) {
-		return errors.New("token expired")
+	user, err := repo.FindByID(resetToken.UserID)
+	if err != nil {
+		return errors.New("invalid or expired token")
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
@@ -227,8 +258,14 @@ func (s *authService) ResetPassword(token, newPassword string) error {
 	}
 
 	user.Password = string(hashedPassword)
-	user.ResetToken = "" // Clear token
-	user.ResetTokenExpiry = time.Time{}
+	if err := repo.Update(user); err != nil {
+		return err
+	}
 
-	return s.repo.Update(user)
+	// Any other outstanding reset links for this user are no longer valid
+	if err := resetRepo.InvalidateForUser(user.ID, now); err != nil {
+		return err
+	}
+
+	return nil
 }
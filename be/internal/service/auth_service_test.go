@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"golang.org/x/crypto/bcrypt"
@@ -46,14 +47,6 @@ func (m *MockUserRepository) FindByID(id uint) (*models.User, error) {
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-func (m *MockUserRepository) FindByResetToken(token string) (*models.User, error) {
-	args := m.Called(token)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.User), args.Error(1)
-}
-
 func (m *MockUserRepository) WithTx(tx *gorm.DB) repository.UserRepository {
 	args := m.Called(tx)
 	if args.Get(0) == nil {
@@ -103,6 +96,14 @@ func (m *MockPenyewaRepository) FindByEmail(email string) (*models.Penyewa, erro
 	return args.Get(0).(*models.Penyewa), args.Error(1)
 }
 
+func (m *MockPenyewaRepository) FindByPhone(phone string) (*models.Penyewa, error) {
+	args := m.Called(phone)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Penyewa), args.Error(1)
+}
+
 func (m *MockPenyewaRepository) FindByRole(role string) ([]models.Penyewa, error) {
 	args := m.Called(role)
 	if args.Get(0) == nil {
@@ -140,6 +141,33 @@ func (m *MockPenyewaRepository) FindByID(id uint) (*models.Penyewa, error) {
 	return args.Get(0).(*models.Penyewa), args.Error(1)
 }
 
+// MockPasswordResetRepository implements repository.PasswordResetRepository interface
+type MockPasswordResetRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasswordResetRepository) Create(token *models.PasswordResetToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetRepository) Consume(tokenHash string, now time.Time) (*models.PasswordResetToken, error) {
+	args := m.Called(tokenHash, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PasswordResetToken), args.Error(1)
+}
+
+func (m *MockPasswordResetRepository) InvalidateForUser(userID uint, now time.Time) error {
+	args := m.Called(userID, now)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetRepository) WithTx(tx *gorm.DB) repository.PasswordResetRepository {
+	return m
+}
+
 // MockEmailSender implements utils.EmailSender interface
 type MockEmailSender struct {
 	mock.Mock
@@ -168,14 +196,14 @@ func (m *MockGoogleVerifier) Verify(tokenString string, clientID string) (*utils
 	return args.Get(0).(*utils.GoogleClaims), args.Error(1)
 }
 
-func (m *MockEmailSender) SendPaymentSuccessEmail(toEmail, tenantName string, amount float64, date time.Time) error {
-	args := m.Called(toEmail, tenantName, amount, date)
-	return args.Error(0)
-}
-
-func (m *MockEmailSender) SendPaymentReminderEmail(toEmail, tenantName string, amount float64, dueDate time.Time, paymentLink string) error {
-	args := m.Called(toEmail, tenantName, amount, dueDate, paymentLink)
-	return args.Error(0)
+// newTestKeySet creates an in-memory EdDSA key set for signing test tokens
+func newTestKeySet(t *testing.T) *utils.KeySet {
+	t.Helper()
+	keys, err := utils.NewEphemeralKeySet(utils.AlgEdDSA)
+	if err != nil {
+		t.Fatalf("failed to create test key set: %v", err)
+	}
+	return keys
 }
 
 // =============================================================================
@@ -200,8 +228,9 @@ func TestLogin_Success(t *testing.T) {
 	expectedUser.ID = 1
 
 	mockUserRepo.On("FindByUsername", "testuser").Return(expectedUser, nil)
+	mockPenyewaRepo.On("FindByUserID", uint(1)).Return(&models.Penyewa{UserID: 1, Role: "tenant"}, nil)
 
-	authService := NewAuthService(mockUserRepo, mockPenyewaRepo, cfg, mockEmailSender, nil)
+	authService := NewAuthService(mockUserRepo, mockPenyewaRepo, new(MockPasswordResetRepository), nil, cfg, newTestKeySet(t), mockEmailSender, nil)
 
 	// Act
 	token, user, err := authService.Login("testuser", password)
@@ -224,7 +253,7 @@ func TestLogin_UserNotFound(t *testing.T) {
 
 	mockUserRepo.On("FindByUsername", "nonexistent").Return(nil, errors.New("user not found"))
 
-	authService := NewAuthService(mockUserRepo, mockPenyewaRepo, cfg, mockEmailSender, nil)
+	authService := NewAuthService(mockUserRepo, mockPenyewaRepo, new(MockPasswordResetRepository), nil, cfg, newTestKeySet(t), mockEmailSender, nil)
 
 	// Act
 	token, user, err := authService.Login("nonexistent", "password")
@@ -233,7 +262,7 @@ func TestLogin_UserNotFound(t *testing.T) {
 	assert.Error(t, err)
 	assert.Empty(t, token)
 	assert.Nil(t, user)
-	assert.Equal(t, "invalid credentials", err.Error())
+	assert.Equal(t, "Username tidak ditemukan", err.Error())
 	mockUserRepo.AssertExpectations(t)
 }
 
@@ -253,7 +282,7 @@ func TestLogin_InvalidPassword(t *testing.T) {
 
 	mockUserRepo.On("FindByUsername", "testuser").Return(user, nil)
 
-	authService := NewAuthService(mockUserRepo, mockPenyewaRepo, cfg, mockEmailSender, nil)
+	authService := NewAuthService(mockUserRepo, mockPenyewaRepo, new(MockPasswordResetRepository), nil, cfg, newTestKeySet(t), mockEmailSender, nil)
 
 	// Act
 	token, returnedUser, err := authService.Login("testuser", "wrongPassword")
@@ -262,7 +291,7 @@ func TestLogin_InvalidPassword(t *testing.T) {
 	assert.Error(t, err)
 	assert.Empty(t, token)
 	assert.Nil(t, returnedUser)
-	assert.Equal(t, "invalid credentials", err.Error())
+	assert.Equal(t, "Password yang Anda masukkan salah", err.Error())
 	mockUserRepo.AssertExpectations(t)
 }
 
@@ -284,7 +313,7 @@ func TestRegister_Success(t *testing.T) {
 	})
 	mockPenyewaRepo.On("Create", mock.AnythingOfType("*models.Penyewa")).Return(nil)
 
-	authService := NewAuthService(mockUserRepo, mockPenyewaRepo, cfg, mockEmailSender, nil)
+	authService := NewAuthService(mockUserRepo, mockPenyewaRepo, new(MockPasswordResetRepository), nil, cfg, newTestKeySet(t), mockEmailSender, nil)
 
 	// Act
 	user, err := authService.Register("newuser", "password", "tenant", "test@example.com", "08123456789", "Jl. Test", "2000-01-01", "1234567890")
@@ -310,7 +339,7 @@ func TestRegister_UserAlreadyExists(t *testing.T) {
 	existingUser := &models.User{Username: "existinguser"}
 	mockUserRepo.On("FindByUsername", "existinguser").Return(existingUser, nil)
 
-	authService := NewAuthService(mockUserRepo, mockPenyewaRepo, cfg, mockEmailSender, nil)
+	authService := NewAuthService(mockUserRepo, mockPenyewaRepo, new(MockPasswordResetRepository), nil, cfg, newTestKeySet(t), mockEmailSender, nil)
 
 	// Act
 	user, err := authService.Register("existinguser", "ValidPassword123", "tenant", "", "", "", "", "")
@@ -336,7 +365,7 @@ func TestRegister_AdminRole(t *testing.T) {
 	})
 	// Penyewa should NOT be created for admin role
 
-	authService := NewAuthService(mockUserRepo, mockPenyewaRepo, cfg, mockEmailSender, nil)
+	authService := NewAuthService(mockUserRepo, mockPenyewaRepo, new(MockPasswordResetRepository), nil, cfg, newTestKeySet(t), mockEmailSender, nil)
 
 	// Act
 	user, err := authService.Register("adminuser", "AdminPass123", "admin", "", "", "", "", "")
@@ -380,8 +409,9 @@ func TestGoogleLogin_NewUser(t *testing.T) {
 		user.ID = 1
 	})
 	mockPenyewaRepo.On("Create", mock.AnythingOfType("*models.Penyewa")).Return(nil)
+	mockPenyewaRepo.On("FindByUserID", uint(1)).Return(&models.Penyewa{UserID: 1, Role: "guest"}, nil)
 
-	authService := NewAuthService(mockUserRepo, mockPenyewaRepo, cfg, mockEmailSender, mockGoogleVerifier)
+	authService := NewAuthService(mockUserRepo, mockPenyewaRepo, new(MockPasswordResetRepository), nil, cfg, newTestKeySet(t), mockEmailSender, mockGoogleVerifier)
 
 	// Act
 	token, user, err := authService.GoogleLogin(idToken, username, "")
@@ -423,8 +453,9 @@ func TestGoogleLogin_ExistingUser(t *testing.T) {
 
 	mockGoogleVerifier.On("Verify", idToken, cfg.GoogleClientID).Return(claims, nil)
 	mockUserRepo.On("FindByUsername", email).Return(existingUser, nil)
+	mockPenyewaRepo.On("FindByUserID", uint(1)).Return(&models.Penyewa{UserID: 1, Role: "tenant"}, nil)
 
-	authService := NewAuthService(mockUserRepo, mockPenyewaRepo, cfg, mockEmailSender, mockGoogleVerifier)
+	authService := NewAuthService(mockUserRepo, mockPenyewaRepo, new(MockPasswordResetRepository), nil, cfg, newTestKeySet(t), mockEmailSender, mockGoogleVerifier)
 
 	// Act
 	token, user, err := authService.GoogleLogin(idToken, "Some Name", "")
@@ -439,6 +470,118 @@ func TestGoogleLogin_ExistingUser(t *testing.T) {
 	mockPenyewaRepo.AssertNotCalled(t, "Create") // Should not create new Penyewa
 }
 
+// =============================================================================
+// PASSWORD RESET TESTS
+// =============================================================================
+
+func TestForgotPassword_StoresOnlyTokenHash(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockPenyewaRepo := new(MockPenyewaRepository)
+	mockResetRepo := new(MockPasswordResetRepository)
+	mockEmailSender := new(MockEmailSender)
+
+	user := &models.User{Username: "tenant@example.com"}
+	user.ID = 5
+	mockUserRepo.On("FindByUsername", "tenant@example.com").Return(user, nil)
+
+	var stored *models.PasswordResetToken
+	mockResetRepo.On("Create", mock.AnythingOfType("*models.PasswordResetToken")).Return(nil).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*models.PasswordResetToken)
+	})
+
+	var sentToken string
+	mockEmailSender.On("SendResetPasswordEmail", "tenant@example.com", mock.AnythingOfType("string")).Return(nil).Run(func(args mock.Arguments) {
+		sentToken = args.String(1)
+	})
+
+	authService := NewAuthService(mockUserRepo, mockPenyewaRepo, mockResetRepo, nil, &config.Config{}, newTestKeySet(t), mockEmailSender, nil)
+	err := authService.ForgotPassword("tenant@example.com", "10.0.0.1")
+
+	assert.NoError(t, err)
+	assert.NotNil(t, stored)
+	assert.NotEmpty(t, sentToken)
+	assert.Equal(t, uint(5), stored.UserID)
+	assert.Equal(t, "10.0.0.1", stored.CreatedIP)
+	assert.Equal(t, utils.HashToken(sentToken), stored.TokenHash)
+	assert.NotEqual(t, sentToken, stored.TokenHash)
+	assert.WithinDuration(t, time.Now().Add(PasswordResetTokenExpiry), stored.ExpiresAt, time.Minute)
+	mockResetRepo.AssertExpectations(t)
+	mockEmailSender.AssertExpectations(t)
+}
+
+func TestForgotPassword_UnknownEmailDoesNotCreateToken(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockPenyewaRepo := new(MockPenyewaRepository)
+	mockResetRepo := new(MockPasswordResetRepository)
+	mockEmailSender := new(MockEmailSender)
+
+	mockUserRepo.On("FindByUsername", "ghost@example.com").Return(nil, errors.New("not found"))
+	mockPenyewaRepo.On("FindByEmail", "ghost@example.com").Return(nil, errors.New("not found"))
+
+	authService := NewAuthService(mockUserRepo, mockPenyewaRepo, mockResetRepo, nil, &config.Config{}, newTestKeySet(t), mockEmailSender, nil)
+	err := authService.ForgotPassword("ghost@example.com", "10.0.0.1")
+
+	assert.NoError(t, err) // No enumeration
+	mockResetRepo.AssertNotCalled(t, "Create", mock.Anything)
+	mockEmailSender.AssertNotCalled(t, "SendResetPasswordEmail", mock.Anything, mock.Anything)
+}
+
+func TestResetPassword_ConsumesTokenAndInvalidatesOthers(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockResetRepo := new(MockPasswordResetRepository)
+
+	user := &models.User{Username: "tenant", Password: "old-hash"}
+	user.ID = 9
+
+	mockResetRepo.On("Consume", utils.HashToken("raw-token"), mock.AnythingOfType("time.Time")).
+		Return(&models.PasswordResetToken{UserID: 9}, nil)
+	mockUserRepo.On("FindByID", uint(9)).Return(user, nil)
+	mockUserRepo.On("Update", user).Return(nil)
+	mockResetRepo.On("InvalidateForUser", uint(9), mock.AnythingOfType("time.Time")).Return(nil)
+
+	authService := NewAuthService(mockUserRepo, new(MockPenyewaRepository), mockResetRepo, nil, &config.Config{}, newTestKeySet(t), new(MockEmailSender), nil)
+	err := authService.ResetPassword("raw-token", "NewPassword123")
+
+	assert.NoError(t, err)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("NewPassword123")))
+	mockResetRepo.AssertExpectations(t)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestResetPassword_UsedOrExpiredToken(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockResetRepo := new(MockPasswordResetRepository)
+
+	mockResetRepo.On("Consume", utils.HashToken("reused-token"), mock.AnythingOfType("time.Time")).
+		Return(nil, gorm.ErrRecordNotFound)
+
+	authService := NewAuthService(mockUserRepo, new(MockPenyewaRepository), mockResetRepo, nil, &config.Config{}, newTestKeySet(t), new(MockEmailSender), nil)
+	err := authService.ResetPassword("reused-token", "NewPassword123")
+
+	assert.EqualError(t, err, "invalid or expired token")
+	mockUserRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestResetPassword_UpdateFailureReturnsError(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockResetRepo := new(MockPasswordResetRepository)
+
+	user := &models.User{Username: "tenant", Password: "old-hash"}
+	user.ID = 9
+
+	mockResetRepo.On("Consume", utils.HashToken("raw-token"), mock.AnythingOfType("time.Time")).
+		Return(&models.PasswordResetToken{UserID: 9}, nil)
+	mockUserRepo.On("FindByID", uint(9)).Return(user, nil)
+	mockUserRepo.On("Update", user).Return(errors.New("db down"))
+
+	authService := NewAuthService(mockUserRepo, new(MockPenyewaRepository), mockResetRepo, nil, &config.Config{}, newTestKeySet(t), new(MockEmailSender), nil)
+	err := authService.ResetPassword("raw-token", "NewPassword123")
+
+	// Error harus naik supaya transaksi me-rollback Consume dan link reset masih berlaku
+	assert.EqualError(t, err, "db down")
+	mockResetRepo.AssertNotCalled(t, "InvalidateForUser", mock.Anything, mock.Anything)
+}
+
 // =============================================================================
 // TABLE-DRIVEN TESTS (Go Best Practice)
 // =============================================================================
@@ -459,6 +602,7 @@ func TestLogin_TableDriven(t *testing.T) {
 			setupMock: func(m *MockUserRepository) {
 				hashedPwd, _ := bcrypt.GenerateFromPassword([]byte("ValidPassword123"), bcrypt.DefaultCost)
 				user := &models.User{Username: "validuser", Password: string(hashedPwd), Role: "tenant"}
+				user.ID = 7
 				m.On("FindByUsername", "validuser").Return(user, nil)
 			},
 			expectError: false,
@@ -471,7 +615,7 @@ func TestLogin_TableDriven(t *testing.T) {
 				m.On("FindByUsername", "nonexistent").Return(nil, errors.New("not found"))
 			},
 			expectError:   true,
-			expectedError: "invalid credentials",
+			expectedError: "Username tidak ditemukan",
 		},
 		{
 			name:     "wrong password",
@@ -483,7 +627,7 @@ func TestLogin_TableDriven(t *testing.T) {
 				m.On("FindByUsername", "user").Return(user, nil)
 			},
 			expectError:   true,
-			expectedError: "invalid credentials",
+			expectedError: "Password yang Anda masukkan salah",
 		},
 	}
 
@@ -495,8 +639,9 @@ func TestLogin_TableDriven(t *testing.T) {
 			cfg := &config.Config{JWTSecret: "test-secret-key-32-characters-long"}
 
 			tt.setupMock(mockUserRepo)
+			mockPenyewaRepo.On("FindByUserID", mock.Anything).Return(nil, errors.New("not found")).Maybe()
 
-			authService := NewAuthService(mockUserRepo, mockPenyewaRepo, cfg, mockEmailSender, nil)
+			authService := NewAuthService(mockUserRepo, mockPenyewaRepo, new(MockPasswordResetRepository), nil, cfg, newTestKeySet(t), mockEmailSender, nil)
 			token, user, err := authService.Login(tt.username, tt.password)
 
 			if tt.expectError {
@@ -516,3 +661,90 @@ func TestLogin_TableDriven(t *testing.T) {
 		})
 	}
 }
+
+// =============================================================================
+// JWT SIGNING TESTS
+// =============================================================================
+
+func TestLogin_TokenCarriesKidAndValidates(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockPenyewaRepo := new(MockPenyewaRepository)
+	keys := newTestKeySet(t)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("TestPassword123"), bcrypt.DefaultCost)
+	user := &models.User{Username: "testuser", Password: string(hashedPassword), Role: "tenant"}
+	user.ID = 3
+
+	mockUserRepo.On("FindByUsername", "testuser").Return(user, nil)
+	mockPenyewaRepo.On("FindByUserID", uint(3)).Return(nil, errors.New("not found"))
+
+	authService := NewAuthService(mockUserRepo, mockPenyewaRepo, new(MockPasswordResetRepository), nil, &config.Config{}, keys, new(MockEmailSender), nil)
+	token, _, err := authService.Login("testuser", "TestPassword123")
+	assert.NoError(t, err)
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &utils.TokenClaims{})
+	assert.NoError(t, err)
+	assert.Equal(t, utils.AlgEdDSA, parsed.Method.Alg())
+	assert.Equal(t, keys.ActiveKey().KID, parsed.Header["kid"])
+
+	claims, err := utils.ValidateAccessToken(token, keys)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, claims.UserID)
+	assert.Equal(t, "tenant", claims.Role)
+}
+
+func TestValidateToken_RejectsUnexpectedAlgorithms(t *testing.T) {
+	keys := newTestKeySet(t)
+	claims := &utils.TokenClaims{
+		UserID:    1,
+		Role:      "admin",
+		TokenType: "access",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+
+	// HS256 token signed with a guessed secret, carrying a valid kid
+	hsToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	hsToken.Header["kid"] = keys.ActiveKey().KID
+	signed, err := hsToken.SignedString([]byte("test-secret-key-32-characters-long"))
+	assert.NoError(t, err)
+	_, err = utils.ValidateAccessToken(signed, keys)
+	assert.Error(t, err)
+
+	// Unsigned token
+	noneToken := jwt.NewWithClaims(jwt.SigningMethodNone, claims)
+	noneToken.Header["kid"] = keys.ActiveKey().KID
+	unsigned, err := noneToken.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	assert.NoError(t, err)
+	_, err = utils.ValidateAccessToken(unsigned, keys)
+	assert.Error(t, err)
+}
+
+func TestKeyRotation_OldTokensRemainValid(t *testing.T) {
+	dir := t.TempDir()
+	_, err := utils.RotateKeys(dir, utils.AlgRS256)
+	assert.NoError(t, err)
+
+	keys, err := utils.LoadKeySet(dir)
+	assert.NoError(t, err)
+	oldToken, err := utils.GenerateToken(1, "user", "tenant", "access", keys, utils.AccessTokenExpiry)
+	assert.NoError(t, err)
+
+	newKey, err := utils.RotateKeys(dir, utils.AlgEdDSA)
+	assert.NoError(t, err)
+	assert.NoError(t, keys.Reload())
+	assert.Equal(t, newKey.KID, keys.ActiveKey().KID)
+	assert.Len(t, keys.JWKS().Keys, 2)
+
+	_, err = utils.ValidateAccessToken(oldToken, keys)
+	assert.NoError(t, err, "token signed before rotation should still validate")
+
+	// Pruning with zero retention drops the retired key, and its tokens with it
+	removed, err := utils.PruneKeys(dir, 0)
+	assert.NoError(t, err)
+	assert.Len(t, removed, 1)
+	assert.NoError(t, keys.Reload())
+	_, err = utils.ValidateAccessToken(oldToken, keys)
+	assert.Error(t, err)
+}
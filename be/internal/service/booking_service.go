@@ -1,14 +1,18 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"koskosan-be/internal/models"
 	"koskosan-be/internal/repository"
+	"koskosan-be/internal/utils"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+var ErrNoRoomAvailable = errors.New("tidak ada kamar kosong untuk tipe ini pada tanggal tersebut")
+
 type BookingResponse struct {
 	ID              uint                `json:"id"`
 	Kamar           models.Kamar        `json:"kamar"`
@@ -18,14 +22,23 @@ type BookingResponse struct {
 	TotalBayar      float64             `json:"total_bayar"`
 	StatusBayar     string              `json:"status_bayar"`
 	Payments        []models.Pembayaran `json:"payments"`
+
+	// Rincian harga untuk invoice (0 untuk pemesanan sebelum aturan harga)
+	Subtotal   float64                  `json:"subtotal"`
+	Diskon     float64                  `json:"diskon"`
+	TotalHarga float64                  `json:"total_harga"`
+	Discounts  []models.BookingDiscount `json:"discounts"`
 }
 
 type BookingService interface {
 	GetUserBookings(userID uint) ([]BookingResponse, error)
 	CreateBooking(userID uint, kamarID uint, tanggalMulai string, durasiSewa int) (*models.Pemesanan, error)
-	CreateBookingWithProof(userID uint, kamarID uint, tanggalMulai string, durasiSewa int, proofURL string, paymentType string, paymentMethod string) (*models.Pemesanan, error)
+	// CreateBookingWithProof menghitung harga dengan aturan diskon aktif dan voucherCode (opsional)
+	CreateBookingWithProof(userID uint, kamarID uint, tanggalMulai string, durasiSewa int, proofURL string, paymentType string, paymentMethod string, voucherCode string) (*models.Pemesanan, error)
+	// FindAvailableRoom memilihkan kamar Tersedia dari tipe tersebut yang bebas selama masa sewa
+	FindAvailableRoom(roomTypeID uint, tanggalMulai string, durasiSewa int) (*models.Kamar, error)
 	CancelBooking(id uint, userID uint) error
-	ExtendBooking(bookingID uint, months int, userID uint, paymentMethod string) (*models.Pembayaran, error)
+	ExtendBooking(bookingID uint, months int, userID uint, paymentMethod string, voucherCode string) (*models.Pembayaran, error)
 	AutoCancelExpiredBookings() error
 }
 
@@ -36,10 +49,19 @@ type bookingService struct {
 	kamarRepo   repository.KamarRepository
 	paymentRepo repository.PaymentRepository
 	db          *gorm.DB // Added db for transactions
+	events      utils.EventPublisher
+	pricing     PriceCalculator
 }
 
-func NewBookingService(repo repository.BookingRepository, userRepo repository.UserRepository, penyewaRepo repository.PenyewaRepository, kamarRepo repository.KamarRepository, paymentRepo repository.PaymentRepository, db *gorm.DB) BookingService {
-	return &bookingService{repo, userRepo, penyewaRepo, kamarRepo, paymentRepo, db}
+// NewBookingService: pricing nil berarti harga penuh tanpa diskon
+func NewBookingService(repo repository.BookingRepository, userRepo repository.UserRepository, penyewaRepo repository.PenyewaRepository, kamarRepo repository.KamarRepository, paymentRepo repository.PaymentRepository, db *gorm.DB, events utils.EventPublisher, pricing PriceCalculator) BookingService {
+	if events == nil {
+		events = utils.NoopEventPublisher{}
+	}
+	if pricing == nil {
+		pricing = flatPricing{}
+	}
+	return &bookingService{repo, userRepo, penyewaRepo, kamarRepo, paymentRepo, db, events, pricing}
 }
 
 func (s *bookingService) GetUserBookings(userID uint) ([]BookingResponse, error) {
@@ -83,6 +105,10 @@ func (s *bookingService) GetUserBookings(userID uint) ([]BookingResponse, error)
 			TotalBayar:      totalPaid,
 			StatusBayar:     lastStatus,
 			Payments:        payments,
+			Subtotal:        b.Subtotal,
+			Diskon:          b.Diskon,
+			TotalHarga:      b.TotalHarga,
+			Discounts:       b.Discounts,
 		})
 	}
 
@@ -156,7 +182,7 @@ func (s *bookingService) CreateBooking(userID uint, kamarID uint, tanggalMulai s
 	return &booking, nil
 }
 
-func (s *bookingService) CreateBookingWithProof(userID uint, kamarID uint, tanggalMulai string, durasiSewa int, proofURL string, paymentType string, paymentMethod string) (*models.Pemesanan, error) {
+func (s *bookingService) CreateBookingWithProof(userID uint, kamarID uint, tanggalMulai string, durasiSewa int, proofURL string, paymentType string, paymentMethod string, voucherCode string) (*models.Pemesanan, error) {
 	tm, err := time.Parse("2006-01-02", tanggalMulai)
 	if err != nil {
 		return nil, err
@@ -190,7 +216,16 @@ func (s *bookingService) CreateBookingWithProof(userID uint, kamarID uint, tangg
 			}
 		}
 
-		// 1. Create Booking
+		kamar, err := txKamarRepo.FindByID(kamarID)
+		if err != nil {
+			return err
+		}
+		quote, err := s.pricing.Quote(kamar, tm, durasiSewa, voucherCode)
+		if err != nil {
+			return err
+		}
+
+		// 1. Create Booking (dengan snapshot harga)
 		newBooking := models.Pemesanan{
 			PenyewaID:       penyewa.ID,
 			KamarID:         kamarID,
@@ -198,19 +233,18 @@ func (s *bookingService) CreateBookingWithProof(userID uint, kamarID uint, tangg
 			DurasiSewa:      durasiSewa,
 			StatusPemesanan: "Pending",
 		}
+		applyPriceQuote(&newBooking, quote)
 
 		if err := txRepo.Create(&newBooking); err != nil {
 			return err
 		}
-		booking = &newBooking
-
-		// 2. Setup Payment
-		kamar, err := txKamarRepo.FindByID(kamarID)
-		if err != nil {
+		if err := s.pricing.Redeem(tx, quote, newBooking.ID, nil); err != nil {
 			return err
 		}
+		booking = &newBooking
 
-		totalAmount := float64(durasiSewa) * kamar.HargaPerBulan
+		// 2. Setup Payment
+		totalAmount := quote.Total
 		var dpAmount float64
 		var finalAmount float64
 
@@ -231,6 +265,8 @@ func (s *bookingService) CreateBookingWithProof(userID uint, kamarID uint, tangg
 			JumlahDP:         dpAmount,
 			BuktiTransfer:    proofURL,
 			TanggalBayar:     time.Now(),
+			Subtotal:         quote.Subtotal,
+			Diskon:           quote.Diskon,
 		}
 
 		if paymentType == "dp" {
@@ -282,9 +318,37 @@ func (s *bookingService) CreateBookingWithProof(userID uint, kamarID uint, tangg
 		return nil, err
 	}
 
+	s.events.Publish(bookingEvent(utils.EventBookingCreated, booking, userID, ""))
+
 	return booking, nil
 }
 
+func (s *bookingService) FindAvailableRoom(roomTypeID uint, tanggalMulai string, durasiSewa int) (*models.Kamar, error) {
+	tm, err := time.Parse("2006-01-02", tanggalMulai)
+	if err != nil {
+		return nil, err
+	}
+	if durasiSewa <= 0 {
+		return nil, fmt.Errorf("durasi sewa minimal 1 bulan")
+	}
+
+	// Termurah lebih dulu (kamar bisa meng-override harga tipe), lalu urut ID
+	kamars, _, err := s.kamarRepo.Search(repository.KamarFilter{
+		RoomTypeID:    roomTypeID,
+		Status:        "Tersedia",
+		AvailableFrom: tm,
+		AvailableTo:   tm.AddDate(0, durasiSewa, 0),
+		Sort:          repository.KamarSortPriceAsc,
+	}, &utils.Pagination{Page: 1, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(kamars) == 0 {
+		return nil, ErrNoRoomAvailable
+	}
+	return &kamars[0], nil
+}
+
 func (s *bookingService) CancelBooking(id uint, userID uint) error {
 	booking, err := s.repo.FindByID(id)
 	if err != nil {
@@ -311,6 +375,11 @@ func (s *bookingService) CancelBooking(id uint, userID uint) error {
 		return err
 	}
 
+	// Kuota voucher yang dipakai pemesanan ini bisa dipakai lagi
+	if err := s.pricing.ReleaseVouchers(nil, id, nil); err != nil {
+		utils.GlobalLogger.Error("Failed to release vouchers for booking %d: %v", id, err)
+	}
+
 	// NEW: Update Room Status back to Available (Tersedia)
 	// Fetch the booking again with Kamar loaded or just use KamarID if available options
 	// Since we have 'booking', we can use booking.KamarID
@@ -325,11 +394,14 @@ func (s *bookingService) CancelBooking(id uint, userID uint) error {
 		return fmt.Errorf("failed to remove pending payment: %v", err)
 	}
 
+	booking.StatusPemesanan = "Cancelled"
+	s.events.Publish(bookingEvent(utils.EventBookingCancelled, booking, penyewa.UserID, "user"))
+
 	return nil
 }
 
 // ExtendBooking creates a new payment for extending the lease
-func (s *bookingService) ExtendBooking(bookingID uint, months int, userID uint, paymentMethod string) (*models.Pembayaran, error) {
+func (s *bookingService) ExtendBooking(bookingID uint, months int, userID uint, paymentMethod string, voucherCode string) (*models.Pembayaran, error) {
 	booking, err := s.repo.FindByID(bookingID)
 	if err != nil {
 		return nil, err
@@ -349,21 +421,34 @@ func (s *bookingService) ExtendBooking(bookingID uint, months int, userID uint,
 		return nil, fmt.Errorf("hanya booking yang sudah dikonfirmasi yang bisa diperpanjang")
 	}
 
-	// Calculate amount
-	amount := booking.Kamar.HargaPerBulan * float64(months)
+	// Harga perpanjangan dihitung untuk periode setelah masa sewa berjalan
+	periodStart := booking.TanggalMulai.AddDate(0, booking.DurasiSewa, 0)
+	quote, err := s.pricing.Quote(&booking.Kamar, periodStart, months, voucherCode)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create new payment record
 	payment := models.Pembayaran{
 		PemesananID:      booking.ID,
-		JumlahBayar:      amount,
+		JumlahBayar:      quote.Total,
 		TanggalBayar:     time.Now(),
 		StatusPembayaran: "Pending",
 		MetodePembayaran: paymentMethod, // Selected method (bank_transfer or cash)
 		TipePembayaran:   "extend",      // New type for extension
 		JumlahDP:         0,
+		Subtotal:         quote.Subtotal,
+		Diskon:           quote.Diskon,
+		JumlahBulan:      months,
 	}
 
-	if err := s.paymentRepo.Create(&payment); err != nil {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.paymentRepo.WithTx(tx).Create(&payment); err != nil {
+			return err
+		}
+		return s.pricing.Redeem(tx, quote, booking.ID, &payment.ID)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -381,6 +466,14 @@ func (s *bookingService) ExtendBooking(bookingID uint, months int, userID uint,
 		// Non-critical error, payment still created
 	}
 
+	s.events.Publish(utils.NewDomainEvent(utils.EventBillCreated, userID, map[string]interface{}{
+		"payment_id":   payment.ID,
+		"booking_id":   booking.ID,
+		"amount":       payment.JumlahBayar,
+		"payment_type": payment.TipePembayaran,
+		"months":       months,
+	}))
+
 	return &payment, nil
 }
 
@@ -404,6 +497,13 @@ func (s *bookingService) AutoCancelExpiredBookings() error {
 		if err := s.kamarRepo.UpdateStatus(b.KamarID, "Tersedia"); err != nil {
 			fmt.Printf("Failed to update room status for auto-cancelled booking %d: %v\n", b.ID, err)
 		}
+
+		if err := s.pricing.ReleaseVouchers(nil, b.ID, nil); err != nil {
+			fmt.Printf("Failed to release vouchers for auto-cancelled booking %d: %v\n", b.ID, err)
+		}
+
+		b.StatusPemesanan = "Cancelled"
+		s.events.Publish(bookingEvent(utils.EventBookingCancelled, &b, b.Penyewa.UserID, "expired"))
 	}
 
 	if len(expiredBookings) > 0 {
@@ -412,3 +512,23 @@ func (s *bookingService) AutoCancelExpiredBookings() error {
 
 	return nil
 }
+
+func bookingEvent(eventType string, booking *models.Pemesanan, userID uint, reason string) utils.DomainEvent {
+	data := map[string]interface{}{
+		"booking_id": booking.ID,
+		"room_id":    booking.KamarID,
+		"status":     booking.StatusPemesanan,
+	}
+	if reason != "" {
+		data["reason"] = reason
+	}
+	return utils.NewDomainEvent(eventType, userID, data)
+}
+
+// applyPriceQuote menyimpan snapshot harga pada pemesanan
+func applyPriceQuote(booking *models.Pemesanan, quote *PriceQuote) {
+	booking.HargaPerBulan = quote.HargaPerBulan
+	booking.Subtotal = quote.Subtotal
+	booking.Diskon = quote.Diskon
+	booking.TotalHarga = quote.Total
+}
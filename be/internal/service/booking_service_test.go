@@ -3,6 +3,7 @@ package service
 import (
 	"koskosan-be/internal/models"
 	"koskosan-be/internal/repository"
+	"koskosan-be/internal/utils"
 	"testing"
 	"time"
 
@@ -44,6 +45,16 @@ func (m *MockKamarRepository) FindAll() ([]models.Kamar, error) {
 	return args.Get(0).([]models.Kamar), args.Error(1)
 }
 
+func (m *MockKamarRepository) Search(filter repository.KamarFilter, pagination *utils.Pagination) ([]models.Kamar, int64, error) {
+	args := m.Called(filter, pagination)
+	return args.Get(0).([]models.Kamar), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockKamarRepository) ReplaceFacilities(kamarID uint, facilities []models.Facility) error {
+	args := m.Called(kamarID, facilities)
+	return args.Error(0)
+}
+
 func (m *MockKamarRepository) Delete(id uint) error {
 	args := m.Called(id)
 	return args.Error(0)
@@ -72,6 +83,24 @@ func (m *MockKamarRepository) DeleteImagesByKamarID(kamarID uint) error {
 	return args.Error(0)
 }
 
+func (m *MockKamarRepository) FindImages(kamarID uint) ([]models.KamarImage, error) {
+	args := m.Called(kamarID)
+	return args.Get(0).([]models.KamarImage), args.Error(1)
+}
+
+func (m *MockKamarRepository) DeleteImage(kamarID, imageID uint) (*models.KamarImage, error) {
+	args := m.Called(kamarID, imageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.KamarImage), args.Error(1)
+}
+
+func (m *MockKamarRepository) ReorderImages(kamarID uint, imageIDs []uint) error {
+	args := m.Called(kamarID, imageIDs)
+	return args.Error(0)
+}
+
 func (m *MockBookingRepository) FindByPenyewaID(penyewaID uint) ([]models.Pemesanan, error) {
 	args := m.Called(penyewaID)
 	if args.Get(0) == nil {
@@ -143,7 +172,7 @@ func TestBookingService_CancelBooking_Success(t *testing.T) {
 	mockKamarRepo := new(MockKamarRepository)
 	mockPaymentRepo := new(MockPaymentRepository)
 
-	service := NewBookingService(mockBookingRepo, mockUserRepo, mockPenyewaRepo, mockKamarRepo, mockPaymentRepo, nil)
+	service := NewBookingService(mockBookingRepo, mockUserRepo, mockPenyewaRepo, mockKamarRepo, mockPaymentRepo, nil, nil, nil)
 
 	bookingID := uint(1)
 	userID := uint(1)
@@ -174,7 +203,7 @@ func TestBookingService_CancelBooking_IDOR_Unauthorized(t *testing.T) {
 	mockKamarRepo := new(MockKamarRepository)
 	mockPaymentRepo := new(MockPaymentRepository)
 
-	service := NewBookingService(mockBookingRepo, mockUserRepo, mockPenyewaRepo, mockKamarRepo, mockPaymentRepo, nil)
+	service := NewBookingService(mockBookingRepo, mockUserRepo, mockPenyewaRepo, mockKamarRepo, mockPaymentRepo, nil, nil, nil)
 
 	bookingID := uint(1)
 	attackerUserID := uint(2)
@@ -198,3 +227,73 @@ func TestBookingService_CancelBooking_IDOR_Unauthorized(t *testing.T) {
 	mockBookingRepo.AssertExpectations(t)
 	mockPenyewaRepo.AssertExpectations(t)
 }
+
+// MockEventPublisher implements utils.EventPublisher interface
+type MockEventPublisher struct {
+	mock.Mock
+}
+
+func (m *MockEventPublisher) Publish(event utils.DomainEvent) {
+	m.Called(event)
+}
+
+// Test CancelBooking - publishes booking.cancelled to the tenant's room
+func TestBookingService_CancelBooking_PublishesEvent(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	mockUserRepo := new(MockUserRepository)
+	mockPenyewaRepo := new(MockPenyewaRepository)
+	mockKamarRepo := new(MockKamarRepository)
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockEvents := new(MockEventPublisher)
+
+	service := NewBookingService(mockBookingRepo, mockUserRepo, mockPenyewaRepo, mockKamarRepo, mockPaymentRepo, nil, mockEvents, nil)
+
+	penyewa := &models.Penyewa{ID: 3, UserID: 7}
+	booking := &models.Pemesanan{ID: 5, PenyewaID: 3, KamarID: 101, StatusPemesanan: "Pending"}
+
+	mockBookingRepo.On("FindByID", uint(5)).Return(booking, nil)
+	mockPenyewaRepo.On("FindByUserID", uint(7)).Return(penyewa, nil)
+	mockBookingRepo.On("UpdateStatus", uint(5), "Cancelled").Return(nil)
+	mockKamarRepo.On("UpdateStatus", uint(101), "Tersedia").Return(nil)
+	mockPaymentRepo.On("DeleteByBookingID", uint(5)).Return(nil)
+	mockEvents.On("Publish", mock.MatchedBy(func(e utils.DomainEvent) bool {
+		return e.Type == utils.EventBookingCancelled && e.UserID == 7 &&
+			e.Data["booking_id"] == uint(5) && e.Data["status"] == "Cancelled"
+	})).Return()
+
+	err := service.CancelBooking(5, 7)
+
+	assert.NoError(t, err)
+	mockEvents.AssertExpectations(t)
+}
+
+func TestBookingService_FindAvailableRoom(t *testing.T) {
+	mockKamarRepo := new(MockKamarRepository)
+	service := NewBookingService(new(MockBookingRepository), new(MockUserRepository), new(MockPenyewaRepository), mockKamarRepo, new(MockPaymentRepository), nil, nil, nil)
+
+	start := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	filter := repository.KamarFilter{
+		RoomTypeID:    2,
+		Status:        "Tersedia",
+		AvailableFrom: start,
+		AvailableTo:   start.AddDate(0, 3, 0),
+		Sort:          repository.KamarSortPriceAsc,
+	}
+	mockKamarRepo.On("Search", filter, &utils.Pagination{Page: 1, Limit: 1}).Return([]models.Kamar{{ID: 7}}, int64(2), nil)
+
+	kamar, err := service.FindAvailableRoom(2, "2026-11-01", 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), kamar.ID)
+	mockKamarRepo.AssertExpectations(t)
+}
+
+func TestBookingService_FindAvailableRoom_NoneFree(t *testing.T) {
+	mockKamarRepo := new(MockKamarRepository)
+	service := NewBookingService(new(MockBookingRepository), new(MockUserRepository), new(MockPenyewaRepository), mockKamarRepo, new(MockPaymentRepository), nil, nil, nil)
+	mockKamarRepo.On("Search", mock.Anything, mock.Anything).Return([]models.Kamar{}, int64(0), nil)
+
+	_, err := service.FindAvailableRoom(2, "2026-11-01", 3)
+
+	assert.ErrorIs(t, err, ErrNoRoomAvailable)
+}
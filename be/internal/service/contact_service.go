@@ -1,130 +1,235 @@
 package service
 
 import (
+	"errors"
 	"fmt"
-	"os"
-	"strconv"
+	"koskosan-be/internal/config"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"koskosan-be/internal/templates"
+	"koskosan-be/internal/utils"
+	"strings"
+	"time"
+)
 
-	"gopkg.in/gomail.v2"
+var (
+	ErrInvalidContactMessage = errors.New("pesan tidak valid")
+	ErrContactSpam           = errors.New("pesan ditandai sebagai spam")
+	ErrContactReplyFailed    = errors.New("gagal mengirim balasan email")
 )
 
+type ContactInput struct {
+	Name      string
+	Email     string
+	Message   string
+	IPAddress string
+}
+
 type ContactService interface {
-	SendContactMessage(name, email, message string) error
+	// SubmitMessage menyimpan pesan dari contact form; email ke admin diantrikan lewat outbox
+	SubmitMessage(input ContactInput) (*models.ContactMessage, error)
+
+	// Admin inbox
+	GetMessages(status string, pagination *utils.Pagination) ([]models.ContactMessage, int64, error)
+	GetMessage(id uint) (*models.ContactMessage, error)
+	CountUnread() (int64, error)
+	AssignMessage(id, assigneeID uint) (*models.ContactMessage, error)
+	Reply(adminID, id uint, subject, message string) (*models.ContactReply, error)
+	MarkSpam(id uint, spam bool) (*models.ContactMessage, error)
 }
 
 type contactService struct {
-	smtpHost     string
-	smtpPort     int
-	smtpEmail    string
-	smtpPassword string
-	targetEmail  string
+	repo        repository.ContactRepository
+	userRepo    repository.UserRepository
+	outbox      OutboxService
+	emailSender utils.EmailSender
+	messages    *templates.Catalogue
+	notifier    NotificationDispatcher
+	targetEmail string
+	now         func() time.Time
+}
+
+func NewContactService(repo repository.ContactRepository, userRepo repository.UserRepository, outbox OutboxService, emailSender utils.EmailSender, messages *templates.Catalogue, notifier NotificationDispatcher, cfg *config.Config) ContactService {
+	if notifier == nil {
+		notifier = noopDispatcher{}
+	}
+	return &contactService{repo, userRepo, outbox, emailSender, messages, notifier, cfg.ContactEmail, time.Now}
+}
+
+func (s *contactService) SubmitMessage(input ContactInput) (*models.ContactMessage, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Email = strings.TrimSpace(input.Email)
+	input.Message = strings.TrimSpace(input.Message)
+	if input.Name == "" || input.Email == "" || input.Message == "" {
+		return nil, fmt.Errorf("%w: nama, email dan pesan wajib diisi", ErrInvalidContactMessage)
+	}
+
+	message := &models.ContactMessage{
+		Name:      input.Name,
+		Email:     input.Email,
+		Message:   input.Message,
+		Status:    models.ContactStatusNew,
+		IPAddress: input.IPAddress,
+	}
+	if err := s.repo.Create(message); err != nil {
+		return nil, err
+	}
+
+	event := utils.NewDomainEvent(utils.EventContactReceived, 0, map[string]interface{}{
+		"contact_message_id": message.ID,
+		"name":               message.Name,
+		"email":              message.Email,
+	})
+
+	// Pesan sudah tersimpan; gagal mengantrikan email ke admin tidak membatalkan request
+	if s.targetEmail != "" {
+		if err := s.enqueueAdminCopy(event, message); err != nil {
+			utils.GlobalLogger.Error("Failed to queue contact message %d email: %v", message.ID, err)
+		}
+	}
+	s.notifier.Publish(event)
+
+	return message, nil
+}
+
+func (s *contactService) enqueueAdminCopy(event utils.DomainEvent, message *models.ContactMessage) error {
+	// Isi email dari katalog template (html/template meng-escape input pengunjung)
+	rendered, err := s.messages.Render(templates.ContactMessage, templates.ChannelEmail, templates.DefaultLanguage, map[string]interface{}{
+		"Name":    message.Name,
+		"Email":   message.Email,
+		"Message": message.Message,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render email: %v", err)
+	}
+	return s.outbox.EnqueueEmail(event, s.targetEmail, "Admin", templates.ContactMessage, rendered)
+}
+
+func (s *contactService) GetMessages(status string, pagination *utils.Pagination) ([]models.ContactMessage, int64, error) {
+	return s.repo.FindAll(status, pagination)
+}
+
+// GetMessage menandai pesan baru sebagai sudah dibaca
+func (s *contactService) GetMessage(id uint) (*models.ContactMessage, error) {
+	message, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if message.Status == models.ContactStatusNew {
+		now := s.now()
+		message.Status = models.ContactStatusRead
+		message.ReadAt = &now
+		if err := s.repo.Save(message); err != nil {
+			return nil, err
+		}
+	}
+	return message, nil
 }
 
-func NewContactService() ContactService {
-	port, _ := strconv.Atoi(os.Getenv("SMTP_PORT"))
-	if port == 0 {
-		port = 587 // default SMTP port
+func (s *contactService) CountUnread() (int64, error) {
+	return s.repo.CountByStatus(models.ContactStatusNew)
+}
+
+func (s *contactService) AssignMessage(id, assigneeID uint) (*models.ContactMessage, error) {
+	message, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	assignee, err := s.userRepo.FindByID(assigneeID)
+	if err != nil || assignee.Role != "admin" {
+		return nil, fmt.Errorf("%w: pesan hanya bisa ditugaskan ke admin", ErrInvalidContactMessage)
+	}
+
+	message.AssignedTo = &assignee.ID
+	if err := s.repo.Save(message); err != nil {
+		return nil, err
+	}
+	message.Assignee = assignee
+	return message, nil
+}
+
+// Reply mengirim balasan langsung lewat EmailSender supaya admin tahu saat itu juga jika gagal.
+// Balasan hanya dicatat jika email berhasil dikirim.
+func (s *contactService) Reply(adminID, id uint, subject, body string) (*models.ContactReply, error) {
+	subject = strings.TrimSpace(subject)
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, fmt.Errorf("%w: balasan wajib diisi", ErrInvalidContactMessage)
+	}
+
+	message, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if message.Status == models.ContactStatusSpam {
+		return nil, ErrContactSpam
+	}
+
+	rendered, err := s.messages.Render(templates.ContactReply, templates.ChannelEmail, templates.DefaultLanguage, map[string]interface{}{
+		"Name":    message.Name,
+		"Subject": subject,
+		"Message": message.Message,
+		"Reply":   body,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render email: %v", err)
+	}
+	if err := s.emailSender.SendEmail(message.Email, rendered.Subject, rendered.Body); err != nil {
+		utils.GlobalLogger.Error("Failed to send reply for contact message %d: %v", message.ID, err)
+		return nil, fmt.Errorf("%w: %v", ErrContactReplyFailed, err)
 	}
 
-	return &contactService{
-		smtpHost:     os.Getenv("SMTP_HOST"),
-		smtpPort:     port,
-		smtpEmail:    os.Getenv("SMTP_EMAIL"),
-		smtpPassword: os.Getenv("SMTP_PASSWORD"),
-		targetEmail:  os.Getenv("CONTACT_EMAIL"),
+	authorName := ""
+	if admin, err := s.userRepo.FindByID(adminID); err == nil {
+		authorName = admin.Username
+	}
+	reply := &models.ContactReply{
+		ContactMessageID: message.ID,
+		UserID:           adminID,
+		AuthorName:       authorName,
+		Subject:          rendered.Subject,
+		Message:          body,
+	}
+	if err := s.repo.AddReply(reply); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	message.Status = models.ContactStatusReplied
+	message.RepliedAt = &now
+	if message.ReadAt == nil {
+		message.ReadAt = &now
+	}
+	if message.AssignedTo == nil {
+		message.AssignedTo = &adminID
 	}
+	if err := s.repo.Save(message); err != nil {
+		return nil, err
+	}
+	return reply, nil
 }
 
-func (s *contactService) SendContactMessage(name, email, message string) error {
-	// Create new email message
-	m := gomail.NewMessage()
-	
-	// Set email headers
-	m.SetHeader("From", s.smtpEmail)
-	m.SetHeader("To", s.targetEmail)
-	m.SetHeader("Subject", fmt.Sprintf("Pesan Baru dari %s - Contact Form Koskosan", name))
-	m.SetHeader("Reply-To", email)
-	
-	// Create HTML email body
-	htmlBody := fmt.Sprintf(`
-<!DOCTYPE html>
-<html>
-<head>
-	<style>
-		body { font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; background-color: #f4f4f4; margin: 0; padding: 0; }
-		.container { max-width: 600px; margin: 40px auto; background: white; border-radius: 12px; overflow: hidden; box-shadow: 0 4px 6px rgba(0,0,0,0.1); }
-		.header { background: linear-gradient(135deg, #292524 0%%, #44403c 100%%); color: white; padding: 30px; text-align: center; }
-		.header h1 { margin: 0; font-size: 24px; font-weight: 700; }
-		.header p { margin: 10px 0 0 0; opacity: 0.9; font-size: 14px; }
-		.content { padding: 40px 30px; }
-		.info-row { margin-bottom: 20px; }
-		.info-row label { display: block; color: #78716c; font-size: 12px; font-weight: 600; text-transform: uppercase; letter-spacing: 0.5px; margin-bottom: 5px; }
-		.info-row .value { color: #292524; font-size: 16px; font-weight: 500; }
-		.message-box { background-color: #fafaf9; border-left: 4px solid #78716c; padding: 20px; border-radius: 8px; margin-top: 10px; }
-		.message-box p { margin: 0; color: #292524; line-height: 1.6; white-space: pre-wrap; }
-		.footer { background-color: #fafaf9; padding: 20px; text-align: center; border-top: 1px solid #e7e5e4; }
-		.footer p { margin: 5px 0; color: #78716c; font-size: 13px; }
-	</style>
-</head>
-<body>
-	<div class="container">
-		<div class="header">
-			<h1>📬 Pesan Baru dari Contact Form</h1>
-			<p>Koskosan Rahmat ZAW - Malang</p>
-		</div>
-		<div class="content">
-			<div class="info-row">
-				<label>Dari</label>
-				<div class="value">%s</div>
-			</div>
-			<div class="info-row">
-				<label>Email</label>
-				<div class="value">%s</div>
-			</div>
-			<div class="info-row">
-				<label>Pesan</label>
-				<div class="message-box">
-					<p>%s</p>
-				</div>
-			</div>
-		</div>
-		<div class="footer">
-			<p>Email ini dikirim secara otomatis dari website Koskosan Rahmat ZAW</p>
-			<p>Untuk membalas, klik "Reply" atau email ke: %s</p>
-		</div>
-	</div>
-</body>
-</html>
-	`, name, email, message, email)
-	
-	// Set HTML body
-	m.SetBody("text/html", htmlBody)
-	
-	// Create plain text alternative
-	plainBody := fmt.Sprintf(`
-Pesan Baru dari Contact Form
-Koskosan Rahmat ZAW - Malang
-
-Dari: %s
-Email: %s
-
-Pesan:
-%s
-
----
-Email ini dikirim secara otomatis dari website Koskosan Rahmat ZAW
-Untuk membalas, email ke: %s
-	`, name, email, message, email)
-	
-	m.AddAlternative("text/plain", plainBody)
-	
-	// Create SMTP dialer
-	d := gomail.NewDialer(s.smtpHost, s.smtpPort, s.smtpEmail, s.smtpPassword)
-	
-	// Send the email
-	if err := d.DialAndSend(m); err != nil {
-		return fmt.Errorf("failed to send email: %v", err)
-	}
-	
-	return nil
+// MarkSpam menandai/membatalkan spam; pesan yang dikembalikan mendapat status sesuai riwayatnya
+func (s *contactService) MarkSpam(id uint, spam bool) (*models.ContactMessage, error) {
+	message, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case spam:
+		message.Status = models.ContactStatusSpam
+	case message.Status != models.ContactStatusSpam:
+		return message, nil
+	case message.RepliedAt != nil:
+		message.Status = models.ContactStatusReplied
+	default:
+		message.Status = models.ContactStatusRead
+	}
+
+	if err := s.repo.Save(message); err != nil {
+		return nil, err
+	}
+	return message, nil
 }
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"errors"
+	"koskosan-be/internal/config"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"koskosan-be/internal/templates"
+	"koskosan-be/internal/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type MockContactRepository struct {
+	mock.Mock
+}
+
+func (m *MockContactRepository) Create(message *models.ContactMessage) error {
+	args := m.Called(message)
+	return args.Error(0)
+}
+
+func (m *MockContactRepository) FindByID(id uint) (*models.ContactMessage, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ContactMessage), args.Error(1)
+}
+
+func (m *MockContactRepository) FindAll(status string, pagination *utils.Pagination) ([]models.ContactMessage, int64, error) {
+	args := m.Called(status, pagination)
+	return args.Get(0).([]models.ContactMessage), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockContactRepository) CountByStatus(status string) (int64, error) {
+	args := m.Called(status)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockContactRepository) Save(message *models.ContactMessage) error {
+	args := m.Called(message)
+	return args.Error(0)
+}
+
+func (m *MockContactRepository) AddReply(reply *models.ContactReply) error {
+	args := m.Called(reply)
+	return args.Error(0)
+}
+
+func (m *MockContactRepository) WithTx(tx *gorm.DB) repository.ContactRepository {
+	return m
+}
+
+type contactMocks struct {
+	repo        *MockContactRepository
+	userRepo    *MockUserRepository
+	outbox      *MockOutboxService
+	emailSender *MockEmailSender
+	notifier    *MockNotificationDispatcher
+}
+
+func newTestContactService(contactEmail string, now time.Time) (*contactService, contactMocks) {
+	m := contactMocks{
+		repo:        new(MockContactRepository),
+		userRepo:    new(MockUserRepository),
+		outbox:      new(MockOutboxService),
+		emailSender: new(MockEmailSender),
+		notifier:    new(MockNotificationDispatcher),
+	}
+	cfg := &config.Config{ContactEmail: contactEmail}
+	s := NewContactService(m.repo, m.userRepo, m.outbox, m.emailSender, templates.MustNew(nil), m.notifier, cfg).(*contactService)
+	s.now = func() time.Time { return now }
+	return s, m
+}
+
+func TestContactService_SubmitMessage_StoresAndQueuesEmail(t *testing.T) {
+	s, m := newTestContactService("admin@example.com", time.Now())
+
+	m.repo.On("Create", mock.MatchedBy(func(msg *models.ContactMessage) bool {
+		return msg.Name == "Siti" && msg.Status == models.ContactStatusNew && msg.IPAddress == "10.0.0.1"
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.ContactMessage).ID = 5
+	}).Return(nil)
+	m.outbox.On("EnqueueEmail", mock.MatchedBy(func(e utils.DomainEvent) bool {
+		return e.Type == utils.EventContactReceived && e.UserID == 0
+	}), "admin@example.com", "Admin", templates.ContactMessage, mock.MatchedBy(func(msg *templates.Message) bool {
+		return strings.Contains(msg.Body, "Masih ada kamar?")
+	})).Return(nil)
+	m.notifier.On("Publish", mock.MatchedBy(func(e utils.DomainEvent) bool {
+		return e.Type == utils.EventContactReceived && e.Data["contact_message_id"] == uint(5)
+	})).Return()
+
+	msg, err := s.SubmitMessage(ContactInput{Name: " Siti ", Email: "siti@example.com", Message: "Masih ada kamar?", IPAddress: "10.0.0.1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(5), msg.ID)
+	m.outbox.AssertExpectations(t)
+	m.notifier.AssertExpectations(t)
+	m.emailSender.AssertNotCalled(t, "SendEmail", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Outbox gagal tidak boleh membuat pengunjung melihat error karena pesannya sudah tersimpan
+func TestContactService_SubmitMessage_KeepsMessageWhenQueueFails(t *testing.T) {
+	s, m := newTestContactService("admin@example.com", time.Now())
+
+	m.repo.On("Create", mock.Anything).Return(nil)
+	m.outbox.On("EnqueueEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("db down"))
+	m.notifier.On("Publish", mock.Anything).Return()
+
+	_, err := s.SubmitMessage(ContactInput{Name: "Siti", Email: "siti@example.com", Message: "Halo"})
+
+	assert.NoError(t, err)
+	m.repo.AssertExpectations(t)
+}
+
+func TestContactService_GetMessage_MarksRead(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.Local)
+	s, m := newTestContactService("", now)
+
+	m.repo.On("FindByID", uint(5)).Return(&models.ContactMessage{ID: 5, Status: models.ContactStatusNew}, nil)
+	m.repo.On("Save", mock.MatchedBy(func(msg *models.ContactMessage) bool {
+		return msg.Status == models.ContactStatusRead && msg.ReadAt != nil && msg.ReadAt.Equal(now)
+	})).Return(nil)
+
+	msg, err := s.GetMessage(5)
+
+	require.NoError(t, err)
+	assert.Equal(t, models.ContactStatusRead, msg.Status)
+	m.repo.AssertExpectations(t)
+}
+
+func TestContactService_Reply_SendsAndTracks(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.Local)
+	s, m := newTestContactService("", now)
+
+	m.repo.On("FindByID", uint(5)).Return(&models.ContactMessage{ID: 5, Name: "Siti", Email: "siti@example.com", Message: "Masih ada kamar?", Status: models.ContactStatusRead}, nil)
+	m.emailSender.On("SendEmail", "siti@example.com", mock.MatchedBy(func(subject string) bool {
+		return strings.HasPrefix(subject, "Ketersediaan kamar")
+	}), mock.MatchedBy(func(body string) bool {
+		return strings.Contains(body, "Masih ada 2 kamar")
+	})).Return(nil)
+	m.userRepo.On("FindByID", uint(1)).Return(&models.User{ID: 1, Username: "admin", Role: "admin"}, nil)
+	m.repo.On("AddReply", mock.MatchedBy(func(r *models.ContactReply) bool {
+		return r.ContactMessageID == 5 && r.UserID == 1 && r.AuthorName == "admin"
+	})).Return(nil)
+	m.repo.On("Save", mock.MatchedBy(func(msg *models.ContactMessage) bool {
+		return msg.Status == models.ContactStatusReplied && msg.RepliedAt != nil && msg.AssignedTo != nil && *msg.AssignedTo == 1
+	})).Return(nil)
+
+	reply, err := s.Reply(1, 5, "Ketersediaan kamar", "Masih ada 2 kamar")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Masih ada 2 kamar", reply.Message)
+	m.repo.AssertExpectations(t)
+	m.emailSender.AssertExpectations(t)
+}
+
+func TestContactService_Reply_NotRecordedWhenEmailFails(t *testing.T) {
+	s, m := newTestContactService("", time.Now())
+
+	m.repo.On("FindByID", uint(5)).Return(&models.ContactMessage{ID: 5, Email: "siti@example.com", Status: models.ContactStatusRead}, nil)
+	m.emailSender.On("SendEmail", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("smtp timeout"))
+
+	_, err := s.Reply(1, 5, "", "Halo")
+
+	assert.ErrorIs(t, err, ErrContactReplyFailed)
+	m.repo.AssertNotCalled(t, "AddReply", mock.Anything)
+	m.repo.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestContactService_Reply_RejectsSpam(t *testing.T) {
+	s, m := newTestContactService("", time.Now())
+
+	m.repo.On("FindByID", uint(5)).Return(&models.ContactMessage{ID: 5, Status: models.ContactStatusSpam}, nil)
+
+	_, err := s.Reply(1, 5, "", "Halo")
+
+	assert.ErrorIs(t, err, ErrContactSpam)
+	m.emailSender.AssertNotCalled(t, "SendEmail", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestContactService_MarkSpam_RestoresPreviousStatus(t *testing.T) {
+	s, m := newTestContactService("", time.Now())
+	repliedAt := time.Now()
+
+	m.repo.On("FindByID", uint(5)).Return(&models.ContactMessage{ID: 5, Status: models.ContactStatusSpam, RepliedAt: &repliedAt}, nil)
+	m.repo.On("Save", mock.Anything).Return(nil)
+
+	msg, err := s.MarkSpam(5, false)
+
+	require.NoError(t, err)
+	assert.Equal(t, models.ContactStatusReplied, msg.Status)
+}
+
+func TestContactService_AssignMessage_AdminsOnly(t *testing.T) {
+	s, m := newTestContactService("", time.Now())
+
+	m.repo.On("FindByID", uint(5)).Return(&models.ContactMessage{ID: 5}, nil)
+	m.userRepo.On("FindByID", uint(7)).Return(&models.User{ID: 7, Role: "tenant"}, nil)
+
+	_, err := s.AssignMessage(5, 7)
+
+	assert.ErrorIs(t, err, ErrInvalidContactMessage)
+	m.repo.AssertNotCalled(t, "Save", mock.Anything)
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"fmt"
 	"koskosan-be/internal/models"
 	"math"
 	"time"
@@ -13,7 +14,9 @@ type MonthlyData struct {
 	Revenue float64 `json:"revenue"`
 }
 
+// TypeRevenue per RoomType; TypeID nil untuk kamar lama yang belum punya tipe (dikelompokkan per TipeKamar)
 type TypeRevenue struct {
+	TypeID   *uint   `json:"type_id"`
 	Type     string  `json:"type"`
 	Revenue  float64 `json:"revenue"`
 	Count    int     `json:"count"`
@@ -39,6 +42,17 @@ type DashboardStats struct {
 	TypeBreakdown    []TypeRevenue    `json:"type_breakdown"`
 	Demographics     []Demographic    `json:"demographics"`
 	RecentCheckouts  []RecentCheckout `json:"recent_checkouts"`
+	Tickets          TicketStats      `json:"tickets"`
+}
+
+// TicketStats ringkasan tiket perbaikan untuk dashboard admin
+type TicketStats struct {
+	Open               int64   `json:"open"`
+	InProgress         int64   `json:"in_progress"`
+	Resolved           int64   `json:"resolved"`
+	Closed             int64   `json:"closed"`
+	Urgent             int64   `json:"urgent"`               // tiket urgent yang belum selesai
+	AvgResolutionHours float64 `json:"avg_resolution_hours"` // rata-rata 90 hari terakhir
 }
 
 type RecentCheckout struct {
@@ -650,52 +664,66 @@ func (s *dashboardService) GetStats() (*DashboardStats, error) {
 		}
 	}
 
-	// 8. Type Breakdown (Optimized)
+	// 8. Type Breakdown per RoomType (Optimized)
 	// Query 1: Room Counts & Occupancy
 	type roomStat struct {
-		TipeKamar string
-		Count     int
-		Occupied  int
+		RoomTypeID *uint
+		TypeName   string
+		Count      int
+		Occupied   int
 	}
 	var roomStats []roomStat
 	s.db.Raw(`
 		SELECT 
-			tipe_kamar,
+			k.room_type_id,
+			COALESCE(rt.name, k.tipe_kamar) as type_name,
 			COUNT(*) as count,
-			SUM(CASE WHEN status = 'Penuh' THEN 1 ELSE 0 END) as occupied
-		FROM kamars 
-		GROUP BY tipe_kamar
+			SUM(CASE WHEN k.status = 'Penuh' THEN 1 ELSE 0 END) as occupied
+		FROM kamars k
+		LEFT JOIN room_types rt ON rt.id = k.room_type_id
+		GROUP BY k.room_type_id, COALESCE(rt.name, k.tipe_kamar)
+		ORDER BY type_name
 	`).Scan(&roomStats)
 
 	// Query 2: Revenue per Type
 	type revStat struct {
-		TipeKamar string
-		Revenue   float64
+		RoomTypeID *uint
+		TypeName   string
+		Revenue    float64
 	}
 	var revStats []revStat
 	s.db.Raw(`
 		SELECT 
-			k.tipe_kamar,
+			k.room_type_id,
+			COALESCE(rt.name, k.tipe_kamar) as type_name,
 			COALESCE(SUM(p.jumlah_bayar), 0) as revenue
 		FROM pembayarans p
 		JOIN pemesanans pm ON p.pemesanan_id = pm.id
 		JOIN kamars k ON pm.kamar_id = k.id
+		LEFT JOIN room_types rt ON rt.id = k.room_type_id
 		WHERE p.status_pembayaran = 'Confirmed'
-		GROUP BY k.tipe_kamar
+		GROUP BY k.room_type_id, COALESCE(rt.name, k.tipe_kamar)
 	`).Scan(&revStats)
 
-	// Merge results efficiently in Go
+	// Merge results efficiently in Go (kunci: ID tipe, atau nama untuk kamar tanpa tipe)
+	typeKey := func(id *uint, name string) string {
+		if id != nil {
+			return fmt.Sprintf("id:%d", *id)
+		}
+		return "name:" + name
+	}
 	revMap := make(map[string]float64)
 	for _, r := range revStats {
-		revMap[r.TipeKamar] = r.Revenue
+		revMap[typeKey(r.RoomTypeID, r.TypeName)] = r.Revenue
 	}
 
 	for _, rs := range roomStats {
 		stats.TypeBreakdown = append(stats.TypeBreakdown, TypeRevenue{
-			Type:     rs.TipeKamar,
+			TypeID:   rs.RoomTypeID,
+			Type:     rs.TypeName,
 			Count:    rs.Count,
 			Occupied: rs.Occupied,
-			Revenue:  revMap[rs.TipeKamar],
+			Revenue:  revMap[typeKey(rs.RoomTypeID, rs.TypeName)],
 		})
 	}
 
@@ -774,5 +802,52 @@ func (s *dashboardService) GetStats() (*DashboardStats, error) {
 		})
 	}
 
+	// 11. Maintenance Tickets
+	stats.Tickets = s.ticketStats()
+
 	return &stats, nil
 }
+
+func (s *dashboardService) ticketStats() TicketStats {
+	var stats TicketStats
+
+	var counts []struct {
+		Status string
+		Total  int64
+	}
+	s.db.Model(&models.MaintenanceTicket{}).
+		Select("status, COUNT(*) as total").
+		Group("status").
+		Scan(&counts)
+	for _, c := range counts {
+		switch c.Status {
+		case models.TicketStatusOpen:
+			stats.Open = c.Total
+		case models.TicketStatusInProgress:
+			stats.InProgress = c.Total
+		case models.TicketStatusResolved:
+			stats.Resolved = c.Total
+		case models.TicketStatusClosed:
+			stats.Closed = c.Total
+		}
+	}
+
+	s.db.Model(&models.MaintenanceTicket{}).
+		Where("priority = ? AND status IN ?", "urgent", []string{models.TicketStatusOpen, models.TicketStatusInProgress}).
+		Count(&stats.Urgent)
+
+	// Lama penyelesaian dihitung di Go supaya tidak bergantung pada fungsi tanggal dialect SQL
+	var resolved []models.MaintenanceTicket
+	s.db.Select("created_at", "resolved_at").
+		Where("resolved_at IS NOT NULL AND resolved_at >= ?", time.Now().AddDate(0, 0, -90)).
+		Find(&resolved)
+	if len(resolved) > 0 {
+		var total float64
+		for _, t := range resolved {
+			total += t.ResolvedAt.Sub(t.CreatedAt).Hours()
+		}
+		stats.AvgResolutionHours = math.Round(total/float64(len(resolved))*10) / 10
+	}
+
+	return stats
+}
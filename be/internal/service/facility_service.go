@@ -0,0 +1,153 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var FacilityCategories = []string{
+	models.FacilityCategoryRoom,
+	models.FacilityCategoryBathroom,
+	models.FacilityCategoryBuilding,
+	models.FacilityCategoryService,
+	models.FacilityCategoryOther,
+}
+
+var (
+	ErrFacilityNotFound = errors.New("fasilitas tidak ditemukan")
+	ErrFacilityExists   = errors.New("fasilitas dengan nama ini sudah ada")
+	ErrInvalidFacility  = errors.New("data fasilitas tidak valid")
+)
+
+type FacilityInput struct {
+	Name     string `json:"name" binding:"required,max=100"`
+	IconKey  string `json:"icon_key" binding:"max=50"`
+	Category string `json:"category"` // kosong = other
+}
+
+type FacilityService interface {
+	GetAll() ([]models.Facility, error)
+	Create(input FacilityInput) (*models.Facility, error)
+	Update(id uint, input FacilityInput) (*models.Facility, error)
+	// Delete juga melepas fasilitas dari semua kamar
+	Delete(id uint) error
+}
+
+type facilityService struct {
+	repo repository.FacilityRepository
+}
+
+func NewFacilityService(repo repository.FacilityRepository) FacilityService {
+	return &facilityService{repo}
+}
+
+func (s *facilityService) GetAll() ([]models.Facility, error) {
+	return s.repo.FindAll()
+}
+
+func (s *facilityService) Create(input FacilityInput) (*models.Facility, error) {
+	if err := normalizeFacilityInput(&input); err != nil {
+		return nil, err
+	}
+	if err := s.ensureUniqueName(input.Name, 0); err != nil {
+		return nil, err
+	}
+
+	facility := &models.Facility{Name: input.Name, IconKey: input.IconKey, Category: input.Category}
+	if err := s.repo.Create(facility); err != nil {
+		return nil, err
+	}
+	return facility, nil
+}
+
+func (s *facilityService) Update(id uint, input FacilityInput) (*models.Facility, error) {
+	if err := normalizeFacilityInput(&input); err != nil {
+		return nil, err
+	}
+	facility, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, ErrFacilityNotFound
+	}
+	if err := s.ensureUniqueName(input.Name, id); err != nil {
+		return nil, err
+	}
+
+	facility.Name = input.Name
+	facility.IconKey = input.IconKey
+	facility.Category = input.Category
+	if err := s.repo.Save(facility); err != nil {
+		return nil, err
+	}
+	return facility, nil
+}
+
+func (s *facilityService) Delete(id uint) error {
+	if _, err := s.repo.FindByID(id); err != nil {
+		return ErrFacilityNotFound
+	}
+	return s.repo.Delete(id)
+}
+
+func (s *facilityService) ensureUniqueName(name string, exceptID uint) error {
+	existing, err := s.repo.FindByName(name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != exceptID {
+		return ErrFacilityExists
+	}
+	return nil
+}
+
+func normalizeFacilityInput(input *FacilityInput) error {
+	input.Name = strings.Join(strings.Fields(input.Name), " ")
+	input.IconKey = strings.ToLower(strings.TrimSpace(input.IconKey))
+	input.Category = strings.ToLower(strings.TrimSpace(input.Category))
+	if input.Category == "" {
+		input.Category = models.FacilityCategoryOther
+	}
+
+	if input.Name == "" || strings.Contains(input.Name, ",") {
+		return fmt.Errorf("%w: nama wajib diisi dan tidak boleh mengandung koma", ErrInvalidFacility)
+	}
+	for _, category := range FacilityCategories {
+		if input.Category == category {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: kategori harus salah satu dari %s", ErrInvalidFacility, strings.Join(FacilityCategories, ", "))
+}
+
+// SplitFacilityNames memecah teks Fasilitas lama ("AC, Wi-Fi, ...") menjadi nama unik
+func SplitFacilityNames(text string) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, name := range strings.Split(text, ",") {
+		name = strings.Join(strings.Fields(name), " ")
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// FacilityNames menyusun teks Kamar.Fasilitas dari katalog (urut nama, sama dengan sinkronisasi di repository)
+func FacilityNames(facilities []models.Facility) string {
+	names := make([]string, 0, len(facilities))
+	for _, facility := range facilities {
+		names = append(names, facility.Name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type MockFacilityRepository struct {
+	mock.Mock
+}
+
+func (m *MockFacilityRepository) FindAll() ([]models.Facility, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Facility), args.Error(1)
+}
+
+func (m *MockFacilityRepository) FindByID(id uint) (*models.Facility, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Facility), args.Error(1)
+}
+
+func (m *MockFacilityRepository) FindByIDs(ids []uint) ([]models.Facility, error) {
+	args := m.Called(ids)
+	return args.Get(0).([]models.Facility), args.Error(1)
+}
+
+func (m *MockFacilityRepository) FindByName(name string) (*models.Facility, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Facility), args.Error(1)
+}
+
+func (m *MockFacilityRepository) Create(facility *models.Facility) error {
+	args := m.Called(facility)
+	return args.Error(0)
+}
+
+func (m *MockFacilityRepository) Save(facility *models.Facility) error {
+	args := m.Called(facility)
+	return args.Error(0)
+}
+
+func (m *MockFacilityRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockFacilityRepository) WithTx(tx *gorm.DB) repository.FacilityRepository {
+	return m
+}
+
+func TestFacilityService_CreateNormalizesInput(t *testing.T) {
+	repo := new(MockFacilityRepository)
+	s := NewFacilityService(repo)
+	repo.On("FindByName", "Kamar Mandi Dalam").Return(nil, gorm.ErrRecordNotFound)
+	repo.On("Create", mock.MatchedBy(func(f *models.Facility) bool {
+		return f.Name == "Kamar Mandi Dalam" && f.IconKey == "bath" && f.Category == models.FacilityCategoryBathroom
+	})).Return(nil)
+
+	facility, err := s.Create(FacilityInput{Name: "  Kamar   Mandi Dalam ", IconKey: " Bath", Category: "Bathroom"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Kamar Mandi Dalam", facility.Name)
+	repo.AssertExpectations(t)
+}
+
+func TestFacilityService_CreateDefaultsCategory(t *testing.T) {
+	repo := new(MockFacilityRepository)
+	s := NewFacilityService(repo)
+	repo.On("FindByName", "Balkon").Return(nil, gorm.ErrRecordNotFound)
+	repo.On("Create", mock.Anything).Return(nil)
+
+	facility, err := s.Create(FacilityInput{Name: "Balkon"})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.FacilityCategoryOther, facility.Category)
+}
+
+func TestFacilityService_CreateRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input FacilityInput
+	}{
+		{"empty name", FacilityInput{Name: "   "}},
+		{"comma in name", FacilityInput{Name: "AC, Wi-Fi"}},
+		{"unknown category", FacilityInput{Name: "AC", Category: "luxury"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockFacilityRepository)
+			s := NewFacilityService(repo)
+
+			_, err := s.Create(tt.input)
+
+			assert.ErrorIs(t, err, ErrInvalidFacility)
+			repo.AssertNotCalled(t, "Create", mock.Anything)
+		})
+	}
+}
+
+func TestFacilityService_CreateDuplicateName(t *testing.T) {
+	repo := new(MockFacilityRepository)
+	s := NewFacilityService(repo)
+	repo.On("FindByName", "wi-fi").Return(&models.Facility{ID: 3, Name: "Wi-Fi"}, nil)
+
+	_, err := s.Create(FacilityInput{Name: "wi-fi"})
+
+	assert.ErrorIs(t, err, ErrFacilityExists)
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestFacilityService_UpdateKeepsOwnName(t *testing.T) {
+	repo := new(MockFacilityRepository)
+	s := NewFacilityService(repo)
+	repo.On("FindByID", uint(3)).Return(&models.Facility{ID: 3, Name: "Wifi", Category: "other"}, nil)
+	repo.On("FindByName", "WiFi").Return(&models.Facility{ID: 3, Name: "Wifi"}, nil)
+	repo.On("Save", mock.MatchedBy(func(f *models.Facility) bool {
+		return f.ID == 3 && f.Name == "WiFi" && f.Category == models.FacilityCategoryBuilding
+	})).Return(nil)
+
+	_, err := s.Update(3, FacilityInput{Name: "WiFi", IconKey: "wifi", Category: "building"})
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestFacilityService_DeleteNotFound(t *testing.T) {
+	repo := new(MockFacilityRepository)
+	s := NewFacilityService(repo)
+	repo.On("FindByID", uint(9)).Return(nil, gorm.ErrRecordNotFound)
+
+	err := s.Delete(9)
+
+	assert.ErrorIs(t, err, ErrFacilityNotFound)
+	repo.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+func TestSplitFacilityNames(t *testing.T) {
+	assert.Equal(t, []string{"AC", "Wi-Fi", "Kamar Mandi Dalam"}, SplitFacilityNames(" AC,Wi-Fi ,,  Kamar  Mandi Dalam, ac"))
+	assert.Nil(t, SplitFacilityNames(""))
+}
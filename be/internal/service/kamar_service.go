@@ -1,32 +1,96 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"koskosan-be/internal/models"
 	"koskosan-be/internal/repository"
+	"koskosan-be/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// MaxKamarPageSize membatasi limit pencarian kamar per halaman
+const MaxKamarPageSize = 100
+
+var ErrInvalidKamarFilter = errors.New("filter pencarian kamar tidak valid")
+
+var (
+	ErrKamarImageNotFound = errors.New("foto kamar tidak ditemukan")
+	ErrInvalidImageOrder  = errors.New("urutan foto harus berisi semua foto kamar tepat satu kali")
 )
 
 type KamarService interface {
 	GetAll() ([]models.Kamar, error)
+	Search(filter repository.KamarFilter, pagination *utils.Pagination) ([]models.Kamar, int64, error)
 	GetByID(id uint) (*models.Kamar, error)
 	Create(kamar *models.Kamar) error
 	Update(kamar *models.Kamar) error
 	Delete(id uint) error
 	AddImage(image *models.KamarImage) error
 	DeleteImagesByKamarID(kamarID uint) error
+
+	// ResolveFacilities mencari fasilitas katalog dari ID; jika ids kosong dari nama (klien lama
+	// yang mengirim teks "AC, Wi-Fi"), nama yang belum ada ditambahkan ke katalog
+	ResolveFacilities(ids []uint, names []string) ([]models.Facility, error)
+	// ReplaceFacilities mengganti fasilitas kamar dan menyinkronkan kolom teks Fasilitas
+	ReplaceFacilities(kamarID uint, facilities []models.Facility) error
+	// AssignRoomType mengaitkan kamar ke tipe dan menyalin nilai bawaan yang tidak di-override (belum disimpan)
+	AssignRoomType(kamar *models.Kamar, roomTypeID uint) error
+
+	// AddImages menambah foto di akhir urutan; kamar tanpa foto sendiri mendapat cover baru
+	AddImages(kamarID uint, urls []string) (*models.Kamar, error)
+	// DeleteImage menghapus satu foto dan mengembalikannya supaya file-nya bisa dibersihkan
+	DeleteImage(kamarID, imageID uint) (*models.KamarImage, error)
+	// ReorderImages mengatur urutan foto; imageIDs[0] menjadi cover
+	ReorderImages(kamarID uint, imageIDs []uint) (*models.Kamar, error)
+	// SetCoverImage memindahkan foto ke urutan pertama dan menjadikannya Kamar.ImageURL
+	SetCoverImage(kamarID, imageID uint) (*models.Kamar, error)
 }
 
 type kamarService struct {
-	repo repository.KamarRepository
+	repo         repository.KamarRepository
+	facilityRepo repository.FacilityRepository
+	roomTypeRepo repository.RoomTypeRepository
 }
 
-func NewKamarService(repo repository.KamarRepository) KamarService {
-	return &kamarService{repo}
+func NewKamarService(repo repository.KamarRepository, facilityRepo repository.FacilityRepository, roomTypeRepo repository.RoomTypeRepository) KamarService {
+	return &kamarService{repo, facilityRepo, roomTypeRepo}
 }
 
 func (s *kamarService) GetAll() ([]models.Kamar, error) {
 	return s.repo.FindAll()
 }
 
+func (s *kamarService) Search(filter repository.KamarFilter, pagination *utils.Pagination) ([]models.Kamar, int64, error) {
+	switch {
+	case filter.MinPrice < 0 || filter.MaxPrice < 0:
+		return nil, 0, fmt.Errorf("%w: harga tidak boleh negatif", ErrInvalidKamarFilter)
+	case filter.MaxPrice > 0 && filter.MinPrice > filter.MaxPrice:
+		return nil, 0, fmt.Errorf("%w: min_price lebih besar dari max_price", ErrInvalidKamarFilter)
+	case filter.AvailableFrom.IsZero() != filter.AvailableTo.IsZero():
+		return nil, 0, fmt.Errorf("%w: available_from dan available_to harus diisi bersamaan", ErrInvalidKamarFilter)
+	case !filter.AvailableTo.IsZero() && !filter.AvailableTo.After(filter.AvailableFrom):
+		return nil, 0, fmt.Errorf("%w: available_to harus setelah available_from", ErrInvalidKamarFilter)
+	}
+	switch filter.Sort {
+	case "", repository.KamarSortPriceAsc, repository.KamarSortPriceDesc, repository.KamarSortRating, repository.KamarSortNewest:
+	default:
+		return nil, 0, fmt.Errorf("%w: sort harus price_asc, price_desc, rating atau newest", ErrInvalidKamarFilter)
+	}
+
+	if pagination.Limit <= 0 {
+		pagination.Limit = 10
+	} else if pagination.Limit > MaxKamarPageSize {
+		pagination.Limit = MaxKamarPageSize
+	}
+	if pagination.Page <= 0 {
+		pagination.Page = 1
+	}
+
+	return s.repo.Search(filter, pagination)
+}
+
 func (s *kamarService) GetByID(id uint) (*models.Kamar, error) {
 	return s.repo.FindByID(id)
 }
@@ -50,3 +114,141 @@ func (s *kamarService) AddImage(image *models.KamarImage) error {
 func (s *kamarService) DeleteImagesByKamarID(kamarID uint) error {
 	return s.repo.DeleteImagesByKamarID(kamarID)
 }
+
+func (s *kamarService) AddImages(kamarID uint, urls []string) (*models.Kamar, error) {
+	if _, err := s.repo.FindByID(kamarID); err != nil {
+		return nil, err
+	}
+	images, err := s.repo.FindImages(kamarID)
+	if err != nil {
+		return nil, err
+	}
+
+	order := imageIDs(images)
+	for _, url := range urls {
+		image := &models.KamarImage{KamarID: kamarID, ImageURL: url, SortOrder: len(order)}
+		if err := s.repo.AddImage(image); err != nil {
+			return nil, err
+		}
+		order = append(order, image.ID)
+	}
+	if err := s.repo.ReorderImages(kamarID, order); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(kamarID)
+}
+
+func (s *kamarService) DeleteImage(kamarID, imageID uint) (*models.KamarImage, error) {
+	image, err := s.repo.DeleteImage(kamarID, imageID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrKamarImageNotFound
+	}
+	return image, err
+}
+
+func (s *kamarService) ReorderImages(kamarID uint, ids []uint) (*models.Kamar, error) {
+	images, err := s.repo.FindImages(kamarID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) != len(images) {
+		return nil, ErrInvalidImageOrder
+	}
+	unique := uniqueUintIDs(ids)
+	if len(unique) != len(ids) {
+		return nil, ErrInvalidImageOrder
+	}
+	for _, image := range images {
+		if !unique[image.ID] {
+			return nil, ErrInvalidImageOrder
+		}
+	}
+
+	if err := s.repo.ReorderImages(kamarID, ids); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(kamarID)
+}
+
+func (s *kamarService) SetCoverImage(kamarID, imageID uint) (*models.Kamar, error) {
+	images, err := s.repo.FindImages(kamarID)
+	if err != nil {
+		return nil, err
+	}
+	order := []uint{imageID}
+	found := false
+	for _, image := range images {
+		if image.ID == imageID {
+			found = true
+			continue
+		}
+		order = append(order, image.ID)
+	}
+	if !found {
+		return nil, ErrKamarImageNotFound
+	}
+
+	if err := s.repo.ReorderImages(kamarID, order); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(kamarID)
+}
+
+func imageIDs(images []models.KamarImage) []uint {
+	ids := make([]uint, 0, len(images))
+	for _, image := range images {
+		ids = append(ids, image.ID)
+	}
+	return ids
+}
+
+func (s *kamarService) ResolveFacilities(ids []uint, names []string) ([]models.Facility, error) {
+	if len(ids) > 0 {
+		facilities, err := s.facilityRepo.FindByIDs(ids)
+		if err != nil {
+			return nil, err
+		}
+		if len(facilities) != len(uniqueUintIDs(ids)) {
+			return nil, ErrFacilityNotFound
+		}
+		return facilities, nil
+	}
+
+	var facilities []models.Facility
+	for _, name := range names {
+		facility, err := s.facilityRepo.FindByName(name)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			facility = &models.Facility{Name: name, Category: models.FacilityCategoryOther}
+			err = s.facilityRepo.Create(facility)
+		}
+		if err != nil {
+			return nil, err
+		}
+		facilities = append(facilities, *facility)
+	}
+	return facilities, nil
+}
+
+func (s *kamarService) ReplaceFacilities(kamarID uint, facilities []models.Facility) error {
+	return s.repo.ReplaceFacilities(kamarID, facilities)
+}
+
+func (s *kamarService) AssignRoomType(kamar *models.Kamar, roomTypeID uint) error {
+	roomType, err := s.roomTypeRepo.FindByID(roomTypeID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrRoomTypeNotFound
+	}
+	if err != nil {
+		return err
+	}
+	ApplyRoomType(kamar, roomType)
+	return nil
+}
+
+func uniqueUintIDs(ids []uint) map[uint]bool {
+	unique := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		unique[id] = true
+	}
+	return unique
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"koskosan-be/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestKamarService_SearchPassesFilter(t *testing.T) {
+	repo := new(MockKamarRepository)
+	s := NewKamarService(repo, new(MockFacilityRepository), new(MockRoomTypeRepository))
+
+	filter := repository.KamarFilter{
+		TipeKamar:     "Single",
+		MinPrice:      1000000,
+		MaxPrice:      2000000,
+		Facilities:    []string{"AC", "Wi-Fi"},
+		AvailableFrom: time.Date(2026, 11, 1, 0, 0, 0, 0, time.Local),
+		AvailableTo:   time.Date(2027, 2, 1, 0, 0, 0, 0, time.Local),
+		Sort:          repository.KamarSortPriceAsc,
+	}
+	pagination := &utils.Pagination{Page: 2, Limit: 5}
+	rooms := []models.Kamar{{ID: 1, TipeKamar: "Single"}}
+	repo.On("Search", filter, pagination).Return(rooms, int64(6), nil)
+
+	result, total, err := s.Search(filter, pagination)
+
+	require.NoError(t, err)
+	assert.Equal(t, rooms, result)
+	assert.Equal(t, int64(6), total)
+	repo.AssertExpectations(t)
+}
+
+func TestKamarService_SearchClampsPagination(t *testing.T) {
+	repo := new(MockKamarRepository)
+	s := NewKamarService(repo, new(MockFacilityRepository), new(MockRoomTypeRepository))
+	repo.On("Search", mock.Anything, mock.Anything).Return([]models.Kamar{}, int64(0), nil)
+
+	pagination := &utils.Pagination{Page: -1, Limit: 1000}
+	_, _, err := s.Search(repository.KamarFilter{}, pagination)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, pagination.Page)
+	assert.Equal(t, MaxKamarPageSize, pagination.Limit)
+}
+
+func TestKamarService_SearchRejectsInvalidFilter(t *testing.T) {
+	from := time.Date(2026, 11, 1, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		name   string
+		filter repository.KamarFilter
+	}{
+		{"negative price", repository.KamarFilter{MinPrice: -1}},
+		{"min above max", repository.KamarFilter{MinPrice: 2000000, MaxPrice: 1000000}},
+		{"only available_from", repository.KamarFilter{AvailableFrom: from}},
+		{"range reversed", repository.KamarFilter{AvailableFrom: from, AvailableTo: from.AddDate(0, 0, -1)}},
+		{"unknown sort", repository.KamarFilter{Sort: "cheapest"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockKamarRepository)
+			s := NewKamarService(repo, new(MockFacilityRepository), new(MockRoomTypeRepository))
+
+			_, _, err := s.Search(tt.filter, &utils.Pagination{})
+
+			assert.ErrorIs(t, err, ErrInvalidKamarFilter)
+			repo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestKamarService_ResolveFacilitiesByID(t *testing.T) {
+	facilityRepo := new(MockFacilityRepository)
+	s := NewKamarService(new(MockKamarRepository), facilityRepo, new(MockRoomTypeRepository))
+	facilityRepo.On("FindByIDs", []uint{1, 2, 2}).Return([]models.Facility{{ID: 1, Name: "AC"}, {ID: 2, Name: "Wi-Fi"}}, nil)
+
+	facilities, err := s.ResolveFacilities([]uint{1, 2, 2}, []string{"diabaikan"})
+
+	require.NoError(t, err)
+	assert.Len(t, facilities, 2)
+	facilityRepo.AssertNotCalled(t, "FindByName", mock.Anything)
+}
+
+func TestKamarService_ResolveFacilitiesUnknownID(t *testing.T) {
+	facilityRepo := new(MockFacilityRepository)
+	s := NewKamarService(new(MockKamarRepository), facilityRepo, new(MockRoomTypeRepository))
+	facilityRepo.On("FindByIDs", []uint{1, 99}).Return([]models.Facility{{ID: 1, Name: "AC"}}, nil)
+
+	_, err := s.ResolveFacilities([]uint{1, 99}, nil)
+
+	assert.ErrorIs(t, err, ErrFacilityNotFound)
+}
+
+// Klien lama mengirim teks fasilitas; nama yang belum ada masuk katalog sebagai "other"
+func TestKamarService_ResolveFacilitiesByNameCreatesMissing(t *testing.T) {
+	facilityRepo := new(MockFacilityRepository)
+	s := NewKamarService(new(MockKamarRepository), facilityRepo, new(MockRoomTypeRepository))
+	facilityRepo.On("FindByName", "AC").Return(&models.Facility{ID: 1, Name: "AC"}, nil)
+	facilityRepo.On("FindByName", "Balkon").Return(nil, gorm.ErrRecordNotFound)
+	facilityRepo.On("Create", mock.MatchedBy(func(f *models.Facility) bool {
+		return f.Name == "Balkon" && f.Category == models.FacilityCategoryOther
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Facility).ID = 7
+	}).Return(nil)
+
+	facilities, err := s.ResolveFacilities(nil, SplitFacilityNames("AC, Balkon, ac"))
+
+	require.NoError(t, err)
+	require.Len(t, facilities, 2)
+	assert.Equal(t, uint(7), facilities[1].ID)
+	assert.Equal(t, "AC, Balkon", FacilityNames(facilities))
+	facilityRepo.AssertExpectations(t)
+}
+
+func TestKamarService_AddImagesAppendsAfterExisting(t *testing.T) {
+	repo := new(MockKamarRepository)
+	s := NewKamarService(repo, new(MockFacilityRepository), new(MockRoomTypeRepository))
+	kamar := &models.Kamar{ID: 1}
+	repo.On("FindByID", uint(1)).Return(kamar, nil)
+	repo.On("FindImages", uint(1)).Return([]models.KamarImage{{ID: 4, SortOrder: 0}, {ID: 2, SortOrder: 1}}, nil)
+	repo.On("AddImage", mock.MatchedBy(func(img *models.KamarImage) bool {
+		return img.ImageURL == "/rooms/new.jpg" && img.SortOrder == 2
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.KamarImage).ID = 9
+	}).Return(nil)
+	repo.On("ReorderImages", uint(1), []uint{4, 2, 9}).Return(nil)
+
+	_, err := s.AddImages(1, []string{"/rooms/new.jpg"})
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestKamarService_SetCoverImageMovesImageFirst(t *testing.T) {
+	repo := new(MockKamarRepository)
+	s := NewKamarService(repo, new(MockFacilityRepository), new(MockRoomTypeRepository))
+	repo.On("FindImages", uint(1)).Return([]models.KamarImage{{ID: 4}, {ID: 2}, {ID: 9}}, nil)
+	repo.On("ReorderImages", uint(1), []uint{9, 4, 2}).Return(nil)
+	repo.On("FindByID", uint(1)).Return(&models.Kamar{ID: 1}, nil)
+
+	_, err := s.SetCoverImage(1, 9)
+	require.NoError(t, err)
+
+	_, err = s.SetCoverImage(1, 7)
+	assert.ErrorIs(t, err, ErrKamarImageNotFound)
+	repo.AssertExpectations(t)
+}
+
+func TestKamarService_ReorderImagesRequiresEveryImageOnce(t *testing.T) {
+	repo := new(MockKamarRepository)
+	s := NewKamarService(repo, new(MockFacilityRepository), new(MockRoomTypeRepository))
+	repo.On("FindImages", uint(1)).Return([]models.KamarImage{{ID: 4}, {ID: 2}, {ID: 9}}, nil)
+
+	for _, ids := range [][]uint{{4, 2}, {4, 4, 2}, {4, 2, 7}} {
+		_, err := s.ReorderImages(1, ids)
+		assert.ErrorIs(t, err, ErrInvalidImageOrder, "%v", ids)
+	}
+	repo.AssertNotCalled(t, "ReorderImages", mock.Anything, mock.Anything)
+}
+
+func TestKamarService_DeleteImageNotFound(t *testing.T) {
+	repo := new(MockKamarRepository)
+	s := NewKamarService(repo, new(MockFacilityRepository), new(MockRoomTypeRepository))
+	repo.On("DeleteImage", uint(1), uint(5)).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := s.DeleteImage(1, 5)
+
+	assert.ErrorIs(t, err, ErrKamarImageNotFound)
+}
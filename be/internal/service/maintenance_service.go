@@ -0,0 +1,345 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"koskosan-be/internal/templates"
+	"koskosan-be/internal/utils"
+	"strings"
+	"time"
+)
+
+// Kategori dan prioritas tiket perbaikan
+var (
+	TicketCategories = []string{"listrik", "air", "ac", "furnitur", "kebersihan", "internet", "lainnya"}
+	TicketPriorities = []string{"low", "medium", "high", "urgent"}
+)
+
+// Maksimal foto yang dilampirkan saat membuat tiket
+const MaxTicketPhotos = 5
+
+// ticketTransitions: status tujuan yang boleh dipilih admin dari status saat ini. Tiket closed tidak bisa dibuka lagi.
+var ticketTransitions = map[string][]string{
+	models.TicketStatusOpen:       {models.TicketStatusInProgress, models.TicketStatusResolved, models.TicketStatusClosed},
+	models.TicketStatusInProgress: {models.TicketStatusOpen, models.TicketStatusResolved, models.TicketStatusClosed},
+	models.TicketStatusResolved:   {models.TicketStatusInProgress, models.TicketStatusClosed},
+}
+
+var (
+	ErrInvalidTicket       = errors.New("data tiket tidak valid")
+	ErrTicketForbidden     = errors.New("tiket bukan milik Anda")
+	ErrTicketClosed        = errors.New("tiket sudah ditutup")
+	ErrTicketNotRented     = errors.New("tiket hanya bisa dibuat untuk kamar yang sedang Anda sewa")
+	ErrInvalidTicketStatus = errors.New("perubahan status tiket tidak diizinkan")
+)
+
+type CreateTicketInput struct {
+	KamarID     uint   `form:"kamar_id" json:"kamar_id" binding:"required"`
+	Title       string `form:"title" json:"title" binding:"required,max=150"`
+	Description string `form:"description" json:"description" binding:"required,max=2000"`
+	Category    string `form:"category" json:"category" binding:"required"`
+	Priority    string `form:"priority" json:"priority"` // kosong = medium
+}
+
+type MaintenanceService interface {
+	// Tenant
+	CreateTicket(userID uint, input CreateTicketInput, photoURLs []string) (*models.MaintenanceTicket, error)
+	GetMyTickets(userID uint) ([]models.MaintenanceTicket, error)
+	GetMyTicket(userID, ticketID uint) (*models.MaintenanceTicket, error)
+	AddTenantComment(userID, ticketID uint, message string) (*models.MaintenanceTicketComment, error)
+
+	// Admin
+	GetTickets(filter repository.MaintenanceTicketFilter, pagination *utils.Pagination) ([]models.MaintenanceTicket, int64, error)
+	GetTicket(ticketID uint) (*models.MaintenanceTicket, error)
+	AssignTicket(ticketID, assigneeID uint) (*models.MaintenanceTicket, error)
+	AddAdminComment(adminID, ticketID uint, message string) (*models.MaintenanceTicketComment, error)
+	UpdateStatus(adminID, ticketID uint, status, note string) (*models.MaintenanceTicket, error)
+}
+
+type maintenanceService struct {
+	repo        repository.MaintenanceRepository
+	bookingRepo repository.BookingRepository
+	penyewaRepo repository.PenyewaRepository
+	userRepo    repository.UserRepository
+	notifier    NotificationDispatcher
+	now         func() time.Time
+}
+
+func NewMaintenanceService(repo repository.MaintenanceRepository, bookingRepo repository.BookingRepository, penyewaRepo repository.PenyewaRepository, userRepo repository.UserRepository, notifier NotificationDispatcher) MaintenanceService {
+	if notifier == nil {
+		notifier = noopDispatcher{}
+	}
+	return &maintenanceService{repo, bookingRepo, penyewaRepo, userRepo, notifier, time.Now}
+}
+
+func (s *maintenanceService) CreateTicket(userID uint, input CreateTicketInput, photoURLs []string) (*models.MaintenanceTicket, error) {
+	input.Title = strings.TrimSpace(input.Title)
+	input.Description = strings.TrimSpace(input.Description)
+	if input.Priority == "" {
+		input.Priority = "medium"
+	}
+	if input.Title == "" || input.Description == "" {
+		return nil, fmt.Errorf("%w: judul dan deskripsi wajib diisi", ErrInvalidTicket)
+	}
+	if !contains(TicketCategories, input.Category) {
+		return nil, fmt.Errorf("%w: kategori harus salah satu dari %s", ErrInvalidTicket, strings.Join(TicketCategories, ", "))
+	}
+	if !contains(TicketPriorities, input.Priority) {
+		return nil, fmt.Errorf("%w: prioritas harus salah satu dari %s", ErrInvalidTicket, strings.Join(TicketPriorities, ", "))
+	}
+	if len(photoURLs) > MaxTicketPhotos {
+		return nil, fmt.Errorf("%w: maksimal %d foto", ErrInvalidTicket, MaxTicketPhotos)
+	}
+
+	penyewa, err := s.penyewaRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("penyewa profile not found")
+	}
+
+	// Hanya kamar dengan pesanan Confirmed (sedang disewa) yang boleh dilaporkan
+	bookings, err := s.bookingRepo.FindByPenyewaID(penyewa.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify booking history")
+	}
+	var room *models.Kamar
+	for i := range bookings {
+		if bookings[i].KamarID == input.KamarID && bookings[i].StatusPemesanan == "Confirmed" {
+			room = &bookings[i].Kamar
+			break
+		}
+	}
+	if room == nil {
+		return nil, ErrTicketNotRented
+	}
+
+	ticket := &models.MaintenanceTicket{
+		PenyewaID:   penyewa.ID,
+		KamarID:     input.KamarID,
+		Title:       input.Title,
+		Description: input.Description,
+		Category:    input.Category,
+		Priority:    input.Priority,
+		Status:      models.TicketStatusOpen,
+	}
+	for _, url := range photoURLs {
+		ticket.Photos = append(ticket.Photos, models.MaintenanceTicketPhoto{URL: url})
+	}
+	if err := s.repo.Create(ticket); err != nil {
+		return nil, err
+	}
+
+	// Event khusus admin (UserID = 0)
+	s.notifier.Publish(utils.NewDomainEvent(utils.EventTicketCreated, 0, map[string]interface{}{
+		"ticket_id":   ticket.ID,
+		"title":       ticket.Title,
+		"category":    ticket.Category,
+		"priority":    ticket.Priority,
+		"room_number": room.NomorKamar,
+		"tenant_name": penyewa.NamaLengkap,
+	}))
+
+	ticket.Kamar = *room
+	return ticket, nil
+}
+
+func (s *maintenanceService) GetMyTickets(userID uint) ([]models.MaintenanceTicket, error) {
+	penyewa, err := s.penyewaRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("penyewa profile not found")
+	}
+	return s.repo.FindByPenyewaID(penyewa.ID)
+}
+
+func (s *maintenanceService) GetMyTicket(userID, ticketID uint) (*models.MaintenanceTicket, error) {
+	ticket, err := s.repo.FindByID(ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Penyewa.UserID != userID {
+		return nil, ErrTicketForbidden
+	}
+	return ticket, nil
+}
+
+func (s *maintenanceService) AddTenantComment(userID, ticketID uint, message string) (*models.MaintenanceTicketComment, error) {
+	ticket, err := s.GetMyTicket(userID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Status == models.TicketStatusClosed {
+		return nil, ErrTicketClosed
+	}
+
+	comment, err := s.addComment(ticket, userID, ticket.Penyewa.NamaLengkap, false, message)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifier.Publish(utils.NewDomainEvent(utils.EventTicketComment, 0, map[string]interface{}{
+		"ticket_id":   ticket.ID,
+		"title":       ticket.Title,
+		"tenant_name": ticket.Penyewa.NamaLengkap,
+	}))
+	return comment, nil
+}
+
+func (s *maintenanceService) GetTickets(filter repository.MaintenanceTicketFilter, pagination *utils.Pagination) ([]models.MaintenanceTicket, int64, error) {
+	return s.repo.FindAll(filter, pagination)
+}
+
+func (s *maintenanceService) GetTicket(ticketID uint) (*models.MaintenanceTicket, error) {
+	return s.repo.FindByID(ticketID)
+}
+
+// AssignTicket menyerahkan tiket ke admin lain (atau diri sendiri). Status tiket tidak berubah.
+func (s *maintenanceService) AssignTicket(ticketID, assigneeID uint) (*models.MaintenanceTicket, error) {
+	ticket, err := s.repo.FindByID(ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Status == models.TicketStatusClosed {
+		return nil, ErrTicketClosed
+	}
+
+	assignee, err := s.userRepo.FindByID(assigneeID)
+	if err != nil || assignee.Role != "admin" {
+		return nil, fmt.Errorf("%w: tiket hanya bisa ditugaskan ke admin", ErrInvalidTicket)
+	}
+
+	ticket.AssignedTo = &assignee.ID
+	if err := s.repo.Save(ticket); err != nil {
+		return nil, err
+	}
+	ticket.Assignee = assignee
+	return ticket, nil
+}
+
+// AddAdminComment menambah balasan admin; tenant mendapat notifikasi in-app
+func (s *maintenanceService) AddAdminComment(adminID, ticketID uint, message string) (*models.MaintenanceTicketComment, error) {
+	ticket, err := s.repo.FindByID(ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Status == models.TicketStatusClosed {
+		return nil, ErrTicketClosed
+	}
+
+	comment, err := s.addComment(ticket, adminID, s.adminName(adminID), true, message)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifier.Dispatch(OutboundMessage{
+		Event: utils.NewDomainEvent(utils.EventTicketComment, ticket.Penyewa.UserID, map[string]interface{}{
+			"ticket_id": ticket.ID,
+			"title":     ticket.Title,
+		}),
+	})
+	return comment, nil
+}
+
+// UpdateStatus mengubah status tiket lalu memberi tahu tenant (in-app, email, WhatsApp sesuai preferensi "maintenance").
+// note (opsional) disimpan sebagai komentar admin dan ikut dikirim ke tenant.
+func (s *maintenanceService) UpdateStatus(adminID, ticketID uint, status, note string) (*models.MaintenanceTicket, error) {
+	ticket, err := s.repo.FindByID(ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Status == models.TicketStatusClosed {
+		return nil, ErrTicketClosed
+	}
+	if !contains(ticketTransitions[ticket.Status], status) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidTicketStatus, ticket.Status, status)
+	}
+
+	now := s.now()
+	previous := ticket.Status
+	ticket.Status = status
+	switch status {
+	case models.TicketStatusResolved:
+		ticket.ResolvedAt = &now
+	case models.TicketStatusClosed:
+		// Ditutup tanpa melewati resolved tetap dihitung selesai saat ditutup
+		if ticket.ResolvedAt == nil {
+			ticket.ResolvedAt = &now
+		}
+		ticket.ClosedAt = &now
+	default:
+		// Dibuka kembali: lama penyelesaian dihitung ulang saat resolved berikutnya
+		ticket.ResolvedAt = nil
+	}
+	if ticket.AssignedTo == nil && status == models.TicketStatusInProgress {
+		ticket.AssignedTo = &adminID
+	}
+
+	if err := s.repo.Save(ticket); err != nil {
+		return nil, err
+	}
+
+	note = strings.TrimSpace(note)
+	if note != "" {
+		if _, err := s.addComment(ticket, adminID, s.adminName(adminID), true, note); err != nil {
+			utils.GlobalLogger.Error("Failed to save status note for ticket %d: %v", ticket.ID, err)
+		}
+	}
+
+	if _, err := s.notifier.Dispatch(OutboundMessage{
+		Event: utils.NewDomainEvent(utils.EventTicketStatusChanged, ticket.Penyewa.UserID, map[string]interface{}{
+			"ticket_id":       ticket.ID,
+			"title":           ticket.Title,
+			"status":          status,
+			"previous_status": previous,
+			"room_number":     ticket.Kamar.NomorKamar,
+		}),
+		Template: templates.TicketStatus,
+		Data: map[string]interface{}{
+			"TicketID":   ticket.ID,
+			"Title":      ticket.Title,
+			"RoomNumber": ticket.Kamar.NomorKamar,
+			"Status":     status,
+			"Note":       note,
+		},
+	}); err != nil {
+		utils.GlobalLogger.Error("Failed to queue status notification for ticket %d: %v", ticket.ID, err)
+	}
+
+	return ticket, nil
+}
+
+// addComment tidak mengecek status; catatan penutupan tiket tetap disimpan
+func (s *maintenanceService) addComment(ticket *models.MaintenanceTicket, userID uint, author string, isAdmin bool, message string) (*models.MaintenanceTicketComment, error) {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return nil, fmt.Errorf("%w: komentar tidak boleh kosong", ErrInvalidTicket)
+	}
+
+	comment := &models.MaintenanceTicketComment{
+		TicketID:   ticket.ID,
+		UserID:     userID,
+		AuthorName: author,
+		IsAdmin:    isAdmin,
+		Message:    message,
+	}
+	if err := s.repo.AddComment(comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+func (s *maintenanceService) adminName(adminID uint) string {
+	if user, err := s.userRepo.FindByID(adminID); err == nil {
+		return user.Username
+	}
+	return "Admin"
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"koskosan-be/internal/templates"
+	"koskosan-be/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type MockMaintenanceRepository struct {
+	mock.Mock
+}
+
+func (m *MockMaintenanceRepository) Create(ticket *models.MaintenanceTicket) error {
+	args := m.Called(ticket)
+	return args.Error(0)
+}
+
+func (m *MockMaintenanceRepository) FindByID(id uint) (*models.MaintenanceTicket, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.MaintenanceTicket), args.Error(1)
+}
+
+func (m *MockMaintenanceRepository) FindByPenyewaID(penyewaID uint) ([]models.MaintenanceTicket, error) {
+	args := m.Called(penyewaID)
+	return args.Get(0).([]models.MaintenanceTicket), args.Error(1)
+}
+
+func (m *MockMaintenanceRepository) FindAll(filter repository.MaintenanceTicketFilter, pagination *utils.Pagination) ([]models.MaintenanceTicket, int64, error) {
+	args := m.Called(filter, pagination)
+	return args.Get(0).([]models.MaintenanceTicket), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockMaintenanceRepository) Save(ticket *models.MaintenanceTicket) error {
+	args := m.Called(ticket)
+	return args.Error(0)
+}
+
+func (m *MockMaintenanceRepository) AddComment(comment *models.MaintenanceTicketComment) error {
+	args := m.Called(comment)
+	return args.Error(0)
+}
+
+func (m *MockMaintenanceRepository) WithTx(tx *gorm.DB) repository.MaintenanceRepository {
+	return m
+}
+
+// MockNotificationDispatcher implements NotificationDispatcher
+type MockNotificationDispatcher struct {
+	mock.Mock
+}
+
+func (m *MockNotificationDispatcher) Publish(event utils.DomainEvent) {
+	m.Called(event)
+}
+
+func (m *MockNotificationDispatcher) Dispatch(msg OutboundMessage) ([]string, error) {
+	args := m.Called(msg)
+	channels, _ := args.Get(0).([]string)
+	return channels, args.Error(1)
+}
+
+type maintenanceMocks struct {
+	repo        *MockMaintenanceRepository
+	bookingRepo *MockBookingRepository
+	penyewaRepo *MockPenyewaRepository
+	userRepo    *MockUserRepository
+	notifier    *MockNotificationDispatcher
+}
+
+func newTestMaintenanceService(now time.Time) (*maintenanceService, maintenanceMocks) {
+	m := maintenanceMocks{
+		repo:        new(MockMaintenanceRepository),
+		bookingRepo: new(MockBookingRepository),
+		penyewaRepo: new(MockPenyewaRepository),
+		userRepo:    new(MockUserRepository),
+		notifier:    new(MockNotificationDispatcher),
+	}
+	s := NewMaintenanceService(m.repo, m.bookingRepo, m.penyewaRepo, m.userRepo, m.notifier).(*maintenanceService)
+	s.now = func() time.Time { return now }
+	return s, m
+}
+
+func TestMaintenanceService_CreateTicket(t *testing.T) {
+	s, m := newTestMaintenanceService(time.Now())
+	penyewa := &models.Penyewa{ID: 3, UserID: 7, NamaLengkap: "Budi"}
+
+	m.penyewaRepo.On("FindByUserID", uint(7)).Return(penyewa, nil)
+	m.bookingRepo.On("FindByPenyewaID", uint(3)).Return([]models.Pemesanan{
+		{ID: 1, KamarID: 101, StatusPemesanan: "Cancelled", Kamar: models.Kamar{ID: 101, NomorKamar: "A-01"}},
+		{ID: 2, KamarID: 102, StatusPemesanan: "Confirmed", Kamar: models.Kamar{ID: 102, NomorKamar: "A-02"}},
+	}, nil)
+	m.repo.On("Create", mock.MatchedBy(func(ticket *models.MaintenanceTicket) bool {
+		return ticket.PenyewaID == 3 && ticket.KamarID == 102 && ticket.Status == models.TicketStatusOpen &&
+			ticket.Priority == "medium" && len(ticket.Photos) == 2
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.MaintenanceTicket).ID = 12
+	}).Return(nil)
+	m.notifier.On("Publish", mock.MatchedBy(func(e utils.DomainEvent) bool {
+		return e.Type == utils.EventTicketCreated && e.UserID == 0 && e.Data["ticket_id"] == uint(12) && e.Data["room_number"] == "A-02"
+	})).Return()
+
+	ticket, err := s.CreateTicket(7, CreateTicketInput{
+		KamarID:     102,
+		Title:       "  AC tidak dingin ",
+		Description: "Sudah 2 hari",
+		Category:    "ac",
+	}, []string{"/tickets/a.jpg", "/tickets/b.jpg"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "AC tidak dingin", ticket.Title)
+	assert.Equal(t, "A-02", ticket.Kamar.NomorKamar)
+	m.repo.AssertExpectations(t)
+	m.notifier.AssertExpectations(t)
+}
+
+func TestMaintenanceService_CreateTicket_RequiresActiveRental(t *testing.T) {
+	s, m := newTestMaintenanceService(time.Now())
+
+	m.penyewaRepo.On("FindByUserID", uint(7)).Return(&models.Penyewa{ID: 3, UserID: 7}, nil)
+	m.bookingRepo.On("FindByPenyewaID", uint(3)).Return([]models.Pemesanan{
+		{ID: 1, KamarID: 101, StatusPemesanan: "Pending"},
+	}, nil)
+
+	_, err := s.CreateTicket(7, CreateTicketInput{KamarID: 101, Title: "Lampu mati", Description: "-", Category: "listrik"}, nil)
+
+	assert.ErrorIs(t, err, ErrTicketNotRented)
+	m.repo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestMaintenanceService_CreateTicket_Validation(t *testing.T) {
+	s, m := newTestMaintenanceService(time.Now())
+	valid := CreateTicketInput{KamarID: 101, Title: "Keran bocor", Description: "Air menetes terus", Category: "air", Priority: "high"}
+
+	invalidCategory := valid
+	invalidCategory.Category = "parkir"
+	_, err := s.CreateTicket(7, invalidCategory, nil)
+	assert.ErrorIs(t, err, ErrInvalidTicket)
+
+	invalidPriority := valid
+	invalidPriority.Priority = "asap"
+	_, err = s.CreateTicket(7, invalidPriority, nil)
+	assert.ErrorIs(t, err, ErrInvalidTicket)
+
+	_, err = s.CreateTicket(7, valid, make([]string, MaxTicketPhotos+1))
+	assert.ErrorIs(t, err, ErrInvalidTicket)
+
+	m.penyewaRepo.AssertNotCalled(t, "FindByUserID", mock.Anything)
+}
+
+func TestMaintenanceService_GetMyTicket_OtherTenant(t *testing.T) {
+	s, m := newTestMaintenanceService(time.Now())
+	m.repo.On("FindByID", uint(12)).Return(&models.MaintenanceTicket{ID: 12, Penyewa: models.Penyewa{ID: 4, UserID: 8}}, nil)
+
+	_, err := s.GetMyTicket(7, 12)
+	assert.ErrorIs(t, err, ErrTicketForbidden)
+
+	_, err = s.AddTenantComment(7, 12, "halo?")
+	assert.ErrorIs(t, err, ErrTicketForbidden)
+	m.repo.AssertNotCalled(t, "AddComment", mock.Anything)
+}
+
+func TestMaintenanceService_UpdateStatus_ResolvedNotifiesTenant(t *testing.T) {
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	s, m := newTestMaintenanceService(now)
+	ticket := &models.MaintenanceTicket{
+		ID:        12,
+		Title:     "AC tidak dingin",
+		Status:    models.TicketStatusInProgress,
+		Penyewa:   models.Penyewa{ID: 3, UserID: 7},
+		Kamar:     models.Kamar{NomorKamar: "A-02"},
+		CreatedAt: now.Add(-26 * time.Hour),
+	}
+
+	m.repo.On("FindByID", uint(12)).Return(ticket, nil)
+	m.repo.On("Save", ticket).Return(nil)
+	m.userRepo.On("FindByID", uint(1)).Return(&models.User{ID: 1, Username: "admin", Role: "admin"}, nil)
+	m.repo.On("AddComment", mock.MatchedBy(func(c *models.MaintenanceTicketComment) bool {
+		return c.TicketID == 12 && c.IsAdmin && c.AuthorName == "admin" && c.Message == "Freon diisi ulang"
+	})).Return(nil)
+	m.notifier.On("Dispatch", mock.MatchedBy(func(msg OutboundMessage) bool {
+		return msg.Event.Type == utils.EventTicketStatusChanged && msg.Event.UserID == 7 &&
+			msg.Template == templates.TicketStatus && msg.Data["Status"] == models.TicketStatusResolved &&
+			msg.Data["Note"] == "Freon diisi ulang" && msg.Event.Data["previous_status"] == models.TicketStatusInProgress
+	})).Return([]string{"in_app", "email"}, nil)
+
+	updated, err := s.UpdateStatus(1, 12, models.TicketStatusResolved, " Freon diisi ulang ")
+
+	require.NoError(t, err)
+	assert.Equal(t, models.TicketStatusResolved, updated.Status)
+	require.NotNil(t, updated.ResolvedAt)
+	assert.Equal(t, now, *updated.ResolvedAt)
+	assert.Nil(t, updated.ClosedAt)
+	m.repo.AssertExpectations(t)
+	m.notifier.AssertExpectations(t)
+}
+
+func TestMaintenanceService_UpdateStatus_Transitions(t *testing.T) {
+	now := time.Now()
+	resolvedAt := now.Add(-time.Hour)
+
+	t.Run("closed ticket cannot change", func(t *testing.T) {
+		s, m := newTestMaintenanceService(now)
+		m.repo.On("FindByID", uint(12)).Return(&models.MaintenanceTicket{ID: 12, Status: models.TicketStatusClosed}, nil)
+
+		_, err := s.UpdateStatus(1, 12, models.TicketStatusOpen, "")
+		assert.ErrorIs(t, err, ErrTicketClosed)
+		m.repo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("unknown status rejected", func(t *testing.T) {
+		s, m := newTestMaintenanceService(now)
+		m.repo.On("FindByID", uint(12)).Return(&models.MaintenanceTicket{ID: 12, Status: models.TicketStatusOpen}, nil)
+
+		_, err := s.UpdateStatus(1, 12, "done", "")
+		assert.ErrorIs(t, err, ErrInvalidTicketStatus)
+	})
+
+	t.Run("reopen clears resolution time", func(t *testing.T) {
+		s, m := newTestMaintenanceService(now)
+		ticket := &models.MaintenanceTicket{ID: 12, Status: models.TicketStatusResolved, ResolvedAt: &resolvedAt}
+		m.repo.On("FindByID", uint(12)).Return(ticket, nil)
+		m.repo.On("Save", ticket).Return(nil)
+		m.notifier.On("Dispatch", mock.Anything).Return(nil, nil)
+
+		updated, err := s.UpdateStatus(1, 12, models.TicketStatusInProgress, "")
+		require.NoError(t, err)
+		assert.Nil(t, updated.ResolvedAt)
+		require.NotNil(t, updated.AssignedTo)
+		assert.Equal(t, uint(1), *updated.AssignedTo)
+		m.repo.AssertNotCalled(t, "AddComment", mock.Anything)
+	})
+
+	t.Run("closing keeps earlier resolution time", func(t *testing.T) {
+		s, m := newTestMaintenanceService(now)
+		ticket := &models.MaintenanceTicket{ID: 12, Status: models.TicketStatusResolved, ResolvedAt: &resolvedAt}
+		m.repo.On("FindByID", uint(12)).Return(ticket, nil)
+		m.repo.On("Save", ticket).Return(nil)
+		m.notifier.On("Dispatch", mock.Anything).Return(nil, nil)
+
+		updated, err := s.UpdateStatus(1, 12, models.TicketStatusClosed, "")
+		require.NoError(t, err)
+		assert.Equal(t, resolvedAt, *updated.ResolvedAt)
+		require.NotNil(t, updated.ClosedAt)
+		assert.Equal(t, now, *updated.ClosedAt)
+	})
+}
+
+func TestMaintenanceService_AssignTicket_OnlyAdmins(t *testing.T) {
+	s, m := newTestMaintenanceService(time.Now())
+	ticket := &models.MaintenanceTicket{ID: 12, Status: models.TicketStatusOpen}
+	m.repo.On("FindByID", uint(12)).Return(ticket, nil)
+	m.userRepo.On("FindByID", uint(5)).Return(&models.User{ID: 5, Role: "penyewa"}, nil)
+	m.userRepo.On("FindByID", uint(1)).Return(&models.User{ID: 1, Username: "admin", Role: "admin"}, nil)
+	m.repo.On("Save", ticket).Return(nil)
+
+	_, err := s.AssignTicket(12, 5)
+	assert.ErrorIs(t, err, ErrInvalidTicket)
+
+	assigned, err := s.AssignTicket(12, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), *assigned.AssignedTo)
+	assert.Equal(t, models.TicketStatusOpen, assigned.Status)
+	m.repo.AssertNumberOfCalls(t, "Save", 1)
+}
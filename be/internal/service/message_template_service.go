@@ -0,0 +1,86 @@
+package service
+
+import (
+	"errors"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"koskosan-be/internal/templates"
+)
+
+var ErrMessageTemplateNotFound = errors.New("template tidak ditemukan")
+
+type MessageTemplateService interface {
+	List() ([]templates.Entry, error)
+	Update(name, channel, language string, src templates.Source, adminID uint) (*templates.Entry, error)
+	Reset(name, channel, language string) (*templates.Entry, error)
+	Preview(name, channel, language string, src *templates.Source) (*templates.Message, error)
+}
+
+type messageTemplateService struct {
+	repo     repository.MessageTemplateRepository
+	messages *templates.Catalogue
+}
+
+func NewMessageTemplateService(repo repository.MessageTemplateRepository, messages *templates.Catalogue) MessageTemplateService {
+	return &messageTemplateService{repo, messages}
+}
+
+func (s *messageTemplateService) List() ([]templates.Entry, error) {
+	return s.messages.Entries()
+}
+
+// Update menyimpan hasil edit admin; hanya template yang punya versi bawaan yang bisa diedit
+func (s *messageTemplateService) Update(name, channel, language string, src templates.Source, adminID uint) (*templates.Entry, error) {
+	if _, ok := s.messages.Default(name, channel, language); !ok {
+		return nil, ErrMessageTemplateNotFound
+	}
+	if channel != templates.ChannelEmail {
+		src.Subject = ""
+	}
+	if err := templates.Validate(channel, src); err != nil {
+		return nil, err
+	}
+	// Pastikan template bisa dirender dengan data contoh (misalnya field yang salah ketik)
+	if _, err := s.messages.RenderSource(channel, language, src, templates.SampleData(name)); err != nil {
+		return nil, err
+	}
+
+	tmpl := &models.MessageTemplate{
+		Name:      name,
+		Channel:   channel,
+		Language:  language,
+		Subject:   src.Subject,
+		Body:      src.Body,
+		UpdatedBy: adminID,
+	}
+	if err := s.repo.Upsert(tmpl); err != nil {
+		return nil, err
+	}
+	return &templates.Entry{Name: name, Channel: channel, Language: language, Subject: src.Subject, Body: src.Body, Customized: true}, nil
+}
+
+// Reset menghapus hasil edit admin dan mengembalikan template bawaan
+func (s *messageTemplateService) Reset(name, channel, language string) (*templates.Entry, error) {
+	def, ok := s.messages.Default(name, channel, language)
+	if !ok {
+		return nil, ErrMessageTemplateNotFound
+	}
+	if err := s.repo.Delete(name, channel, language); err != nil {
+		return nil, err
+	}
+	return &templates.Entry{Name: name, Channel: channel, Language: language, Subject: def.Subject, Body: def.Body}, nil
+}
+
+// Preview merender template dengan data contoh. src nil = template yang sedang berlaku.
+func (s *messageTemplateService) Preview(name, channel, language string, src *templates.Source) (*templates.Message, error) {
+	if _, ok := s.messages.Default(name, channel, language); !ok {
+		return nil, ErrMessageTemplateNotFound
+	}
+	if src == nil {
+		return s.messages.Render(name, channel, language, templates.SampleData(name))
+	}
+	if err := templates.Validate(channel, *src); err != nil {
+		return nil, err
+	}
+	return s.messages.RenderSource(channel, language, *src, templates.SampleData(name))
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"koskosan-be/internal/templates"
+	"koskosan-be/internal/utils"
+	"koskosan-be/internal/validators"
+	"time"
+)
+
+// OutboundMessage adalah satu notifikasi untuk tenant.
+// Email/WhatsApp dirender dari katalog template (bahasa sesuai preferensi tenant); channel yang tidak
+// punya varian template tidak dikirim. Template kosong = hanya in-app.
+type OutboundMessage struct {
+	Event    utils.DomainEvent
+	Template string                 // nama template, misalnya templates.PaymentReminder
+	Data     map[string]interface{} // data template; TenantName diisi otomatis dari profil tenant
+	// Skip berisi channel yang sudah terkirim pada percobaan sebelumnya ("in_app" = event sudah diterbitkan)
+	Skip []string
+}
+
+// NotificationDispatcher mengantrikan notifikasi ke kontak milik tenant sesuai preferensinya.
+// Juga berlaku sebagai EventPublisher untuk event yang hanya in-app.
+type NotificationDispatcher interface {
+	utils.EventPublisher
+	Dispatch(msg OutboundMessage) ([]string, error)
+}
+
+type notificationDispatcher struct {
+	prefs       NotificationPreferenceService
+	penyewaRepo repository.PenyewaRepository
+	outbox      OutboxService
+	messages    *templates.Catalogue
+	events      utils.EventPublisher
+	now         func() time.Time
+}
+
+func NewNotificationDispatcher(prefs NotificationPreferenceService, penyewaRepo repository.PenyewaRepository, outbox OutboxService, messages *templates.Catalogue, events utils.EventPublisher) NotificationDispatcher {
+	if events == nil {
+		events = utils.NoopEventPublisher{}
+	}
+	return &notificationDispatcher{prefs, penyewaRepo, outbox, messages, events, time.Now}
+}
+
+func (d *notificationDispatcher) Publish(event utils.DomainEvent) {
+	d.Dispatch(OutboundMessage{Event: event})
+}
+
+// Dispatch mengantrikan email/WhatsApp ke outbox untuk kontak tenant sendiri, lalu menerbitkan event in-app.
+// WhatsApp saat jam tenang ditahan di outbox sampai jam tenang selesai; email dan inbox tetap langsung. Mengembalikan channel yang diantrikan;
+// error berarti ada pesan yang gagal masuk outbox. Saat retry, channel di msg.Skip tidak dikirim ulang.
+func (d *notificationDispatcher) Dispatch(msg OutboundMessage) ([]string, error) {
+	event := msg.Event
+	if event.Data == nil {
+		event.Data = map[string]interface{}{}
+	}
+	if event.UserID == 0 {
+		d.events.Publish(event)
+		return nil, nil
+	}
+
+	pref, setting, prefErr := d.prefs.Resolve(event.UserID, eventCategory(event.Type))
+	if prefErr != nil {
+		utils.GlobalLogger.Error("Failed to load notification preferences for user %d: %v", event.UserID, prefErr)
+		pref = categoryPreference(eventCategory(event.Type), nil)
+	}
+
+	pref.Email = pref.Email && !skipped(msg.Skip, templates.ChannelEmail)
+	pref.WhatsApp = pref.WhatsApp && !skipped(msg.Skip, templates.ChannelWhatsApp)
+
+	var channels []string
+	var err error
+	if msg.Template != "" && (pref.Email || pref.WhatsApp) {
+		channels, err = d.enqueueExternal(msg, pref, setting)
+	}
+
+	if skipped(msg.Skip, "in_app") {
+		return channels, err
+	}
+	event.Data["channels"] = channels
+	if !pref.InApp {
+		// Tetap terlihat oleh admin, tapi tidak masuk inbox/stream tenant
+		event.Data["user_id"] = event.UserID
+		event.UserID = 0
+	}
+	d.events.Publish(event)
+
+	if pref.InApp {
+		return append([]string{"in_app"}, channels...), err
+	}
+	return channels, err
+}
+
+func (d *notificationDispatcher) enqueueExternal(msg OutboundMessage, pref CategoryPreference, setting *models.NotificationSetting) ([]string, error) {
+	penyewa, err := d.penyewaRepo.FindByUserID(msg.Event.UserID)
+	if err != nil {
+		utils.GlobalLogger.Error("Notification %s: tenant profile for user %d not found", msg.Event.Type, msg.Event.UserID)
+		return nil, nil
+	}
+
+	language := templates.DefaultLanguage
+	if setting != nil && setting.Language != "" {
+		language = setting.Language
+	}
+	var whatsAppAt time.Time
+	if now := d.now(); setting != nil && InQuietHours(setting, now) {
+		whatsAppAt = QuietHoursEnd(setting, now)
+	}
+
+	data := map[string]interface{}{"TenantName": penyewa.NamaLengkap}
+	for k, v := range msg.Data {
+		data[k] = v
+	}
+
+	var channels []string
+	var enqueueErr error
+
+	if pref.Email && d.messages.Has(msg.Template, templates.ChannelEmail) && validators.ValidateEmail(penyewa.Email) == nil {
+		if rendered, err := d.messages.Render(msg.Template, templates.ChannelEmail, language, data); err != nil {
+			utils.GlobalLogger.Error("Failed to render %s email for user %d: %v", msg.Template, msg.Event.UserID, err)
+			enqueueErr = err
+		} else if err := d.outbox.EnqueueEmail(msg.Event, penyewa.Email, penyewa.NamaLengkap, msg.Template, rendered); err != nil {
+			utils.GlobalLogger.Error("Failed to queue %s email for user %d: %v", msg.Event.Type, msg.Event.UserID, err)
+			enqueueErr = err
+		} else {
+			channels = append(channels, "email")
+		}
+	}
+
+	if pref.WhatsApp && d.messages.Has(msg.Template, templates.ChannelWhatsApp) {
+		phone, err := validators.NormalizePhone(penyewa.NomorHP)
+		if err != nil {
+			utils.GlobalLogger.Info("Skipping WhatsApp %s for user %d: no valid phone number", msg.Event.Type, msg.Event.UserID)
+		} else if rendered, err := d.messages.Render(msg.Template, templates.ChannelWhatsApp, language, data); err != nil {
+			utils.GlobalLogger.Error("Failed to render %s WhatsApp for user %d: %v", msg.Template, msg.Event.UserID, err)
+			enqueueErr = err
+		} else if err := d.outbox.EnqueueWhatsApp(msg.Event, phone, penyewa.NamaLengkap, msg.Template, rendered, d.renderSMS(msg, language, data), whatsAppAt); err != nil {
+			utils.GlobalLogger.Error("Failed to queue %s WhatsApp for user %d: %v", msg.Event.Type, msg.Event.UserID, err)
+			enqueueErr = err
+		} else {
+			channels = append(channels, "whatsapp")
+		}
+	}
+
+	return channels, enqueueErr
+}
+
+func skipped(skip []string, channel string) bool {
+	for _, c := range skip {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// renderSMS menyiapkan SMS cadangan untuk template yang punya varian sms (misalnya pengingat tagihan).
+// nil jika tidak ada; gagal render tidak membatalkan WhatsApp-nya.
+func (d *notificationDispatcher) renderSMS(msg OutboundMessage, language string, data map[string]interface{}) *templates.Message {
+	if !d.messages.Has(msg.Template, templates.ChannelSMS) {
+		return nil
+	}
+	rendered, err := d.messages.Render(msg.Template, templates.ChannelSMS, language, data)
+	if err != nil {
+		utils.GlobalLogger.Error("Failed to render %s SMS fallback for user %d: %v", msg.Template, msg.Event.UserID, err)
+		return nil
+	}
+	return rendered
+}
+
+// noopDispatcher dipakai jika service dibuat tanpa dispatcher (misalnya di test)
+type noopDispatcher struct{}
+
+func (noopDispatcher) Publish(event utils.DomainEvent)                {}
+func (noopDispatcher) Dispatch(msg OutboundMessage) ([]string, error) { return nil, nil }
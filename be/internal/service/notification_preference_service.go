@@ -0,0 +1,223 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"koskosan-be/internal/templates"
+	"strings"
+	"time"
+)
+
+// Kategori notifikasi yang bisa diatur user; diambil dari prefix tipe event (payment.confirmed -> payment)
+const (
+	NotificationCategoryPayment     = "payment"
+	NotificationCategoryBill        = "bill"
+	NotificationCategoryBooking     = "booking"
+	NotificationCategoryMaintenance = "maintenance"
+)
+
+var NotificationCategories = []string{
+	NotificationCategoryPayment,
+	NotificationCategoryBill,
+	NotificationCategoryBooking,
+	NotificationCategoryMaintenance,
+}
+
+type CategoryPreference struct {
+	Category string `json:"category"`
+	Email    bool   `json:"email"`
+	WhatsApp bool   `json:"whatsapp"`
+	InApp    bool   `json:"in_app"`
+}
+
+type QuietHours struct {
+	Enabled bool   `json:"enabled"`
+	Start   string `json:"start"` // HH:MM
+	End     string `json:"end"`   // HH:MM
+}
+
+type NotificationPreferences struct {
+	Categories []CategoryPreference `json:"categories"`
+	QuietHours QuietHours           `json:"quiet_hours"`
+	Language   string               `json:"language"` // bahasa email/WhatsApp: id, en
+}
+
+type NotificationPreferenceService interface {
+	GetPreferences(userID uint) (*NotificationPreferences, error)
+	UpdatePreferences(userID uint, input NotificationPreferences) (*NotificationPreferences, error)
+	Resolve(userID uint, category string) (CategoryPreference, *models.NotificationSetting, error)
+}
+
+type notificationPreferenceService struct {
+	repo repository.NotificationPreferenceRepository
+}
+
+func NewNotificationPreferenceService(repo repository.NotificationPreferenceRepository) NotificationPreferenceService {
+	return &notificationPreferenceService{repo}
+}
+
+func (s *notificationPreferenceService) GetPreferences(userID uint) (*NotificationPreferences, error) {
+	prefs, err := s.repo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	setting, err := s.repo.FindSetting(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := make(map[string]models.NotificationPreference, len(prefs))
+	for _, p := range prefs {
+		stored[p.Category] = p
+	}
+
+	result := &NotificationPreferences{
+		QuietHours: QuietHours{
+			Enabled: setting.QuietHoursEnabled,
+			Start:   setting.QuietHoursStart,
+			End:     setting.QuietHoursEnd,
+		},
+		Language: setting.Language,
+	}
+	if result.Language == "" {
+		result.Language = templates.DefaultLanguage
+	}
+	for _, category := range NotificationCategories {
+		result.Categories = append(result.Categories, categoryPreference(category, stored))
+	}
+	return result, nil
+}
+
+func (s *notificationPreferenceService) UpdatePreferences(userID uint, input NotificationPreferences) (*NotificationPreferences, error) {
+	for _, c := range input.Categories {
+		if !isNotificationCategory(c.Category) {
+			return nil, fmt.Errorf("kategori notifikasi tidak dikenal: %s", c.Category)
+		}
+	}
+	if input.Language == "" {
+		input.Language = templates.DefaultLanguage
+	}
+	if !templates.IsLanguage(input.Language) {
+		return nil, fmt.Errorf("bahasa tidak didukung: %s", input.Language)
+	}
+	if input.QuietHours.Enabled {
+		if _, err := parseClock(input.QuietHours.Start); err != nil {
+			return nil, errors.New("format jam tenang harus HH:MM")
+		}
+		if _, err := parseClock(input.QuietHours.End); err != nil {
+			return nil, errors.New("format jam tenang harus HH:MM")
+		}
+	}
+
+	for _, c := range input.Categories {
+		pref := &models.NotificationPreference{
+			UserID:   userID,
+			Category: c.Category,
+			Email:    c.Email,
+			WhatsApp: c.WhatsApp,
+			InApp:    c.InApp,
+		}
+		if err := s.repo.Upsert(pref); err != nil {
+			return nil, err
+		}
+	}
+
+	setting := &models.NotificationSetting{
+		UserID:            userID,
+		QuietHoursEnabled: input.QuietHours.Enabled,
+		QuietHoursStart:   input.QuietHours.Start,
+		QuietHoursEnd:     input.QuietHours.End,
+		Language:          input.Language,
+	}
+	if err := s.repo.SaveSetting(setting); err != nil {
+		return nil, err
+	}
+
+	return s.GetPreferences(userID)
+}
+
+// Resolve mengembalikan channel aktif untuk kategori tersebut beserta jam tenang user
+func (s *notificationPreferenceService) Resolve(userID uint, category string) (CategoryPreference, *models.NotificationSetting, error) {
+	prefs, err := s.repo.FindByUserID(userID)
+	if err != nil {
+		return CategoryPreference{}, nil, err
+	}
+	stored := make(map[string]models.NotificationPreference, len(prefs))
+	for _, p := range prefs {
+		stored[p.Category] = p
+	}
+
+	setting, err := s.repo.FindSetting(userID)
+	if err != nil {
+		return CategoryPreference{}, nil, err
+	}
+	return categoryPreference(category, stored), setting, nil
+}
+
+// categoryPreference: default semua channel aktif jika user belum mengatur kategori tersebut
+func categoryPreference(category string, stored map[string]models.NotificationPreference) CategoryPreference {
+	if p, ok := stored[category]; ok {
+		return CategoryPreference{Category: category, Email: p.Email, WhatsApp: p.WhatsApp, InApp: p.InApp}
+	}
+	return CategoryPreference{Category: category, Email: true, WhatsApp: true, InApp: true}
+}
+
+func isNotificationCategory(category string) bool {
+	for _, c := range NotificationCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// eventCategory: "payment.confirmed" -> "payment"
+func eventCategory(eventType string) string {
+	category, _, _ := strings.Cut(eventType, ".")
+	return category
+}
+
+// InQuietHours mengecek apakah waktu t berada di jam tenang user. Rentang boleh melewati tengah malam (22:00-07:00).
+func InQuietHours(setting *models.NotificationSetting, t time.Time) bool {
+	if setting == nil || !setting.QuietHoursEnabled {
+		return false
+	}
+	start, err := parseClock(setting.QuietHoursStart)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(setting.QuietHoursEnd)
+	if err != nil {
+		return false
+	}
+
+	now := t.Hour()*60 + t.Minute()
+	if start <= end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+// QuietHoursEnd mengembalikan akhir jam tenang pertama setelah t (dipakai untuk menunda WhatsApp)
+func QuietHoursEnd(setting *models.NotificationSetting, t time.Time) time.Time {
+	end, err := parseClock(setting.QuietHoursEnd)
+	if err != nil {
+		return t
+	}
+	at := time.Date(t.Year(), t.Month(), t.Day(), end/60, end%60, 0, 0, t.Location())
+	if !at.After(t) {
+		at = at.AddDate(0, 0, 1)
+	}
+	return at
+}
+
+// parseClock mengubah "HH:MM" menjadi menit sejak tengah malam
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"koskosan-be/internal/utils"
+	"strings"
+	"time"
+)
+
+type NotificationService interface {
+	GetNotifications(userID uint, pagination *utils.Pagination, unreadOnly bool) ([]models.Notification, int64, error)
+	CountUnread(userID uint) (int64, error)
+	MarkRead(id uint, userID uint) error
+	MarkAllRead(userID uint) (int64, error)
+	RecordEvent(event utils.DomainEvent) (*models.Notification, error)
+}
+
+type notificationService struct {
+	repo repository.NotificationRepository
+}
+
+func NewNotificationService(repo repository.NotificationRepository) NotificationService {
+	return &notificationService{repo}
+}
+
+func (s *notificationService) GetNotifications(userID uint, pagination *utils.Pagination, unreadOnly bool) ([]models.Notification, int64, error) {
+	return s.repo.FindByUserID(userID, pagination, unreadOnly)
+}
+
+func (s *notificationService) CountUnread(userID uint) (int64, error) {
+	return s.repo.CountUnread(userID)
+}
+
+func (s *notificationService) MarkRead(id uint, userID uint) error {
+	return s.repo.MarkRead(id, userID, time.Now())
+}
+
+func (s *notificationService) MarkAllRead(userID uint) (int64, error) {
+	return s.repo.MarkAllRead(userID, time.Now())
+}
+
+// RecordEvent menyimpan event ke inbox penerimanya. Event khusus admin (UserID = 0) tidak disimpan.
+func (s *notificationService) RecordEvent(event utils.DomainEvent) (*models.Notification, error) {
+	if event.UserID == 0 {
+		return nil, nil
+	}
+
+	title, message := describeEvent(event)
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	notification := &models.Notification{
+		UserID:    event.UserID,
+		EventID:   event.ID,
+		Type:      event.Type,
+		Title:     title,
+		Message:   message,
+		Channels:  strings.Join(eventChannels(event), ","),
+		Data:      string(data),
+		CreatedAt: event.OccurredAt,
+	}
+	if err := s.repo.Create(notification); err != nil {
+		return nil, err
+	}
+	return notification, nil
+}
+
+// eventChannels: in_app selalu ada, ditambah channel yang diisi service pengirim di Data["channels"]
+func eventChannels(event utils.DomainEvent) []string {
+	channels := []string{"in_app"}
+	if extra, ok := event.Data["channels"].([]string); ok {
+		channels = append(channels, extra...)
+	}
+	return channels
+}
+
+func describeEvent(event utils.DomainEvent) (string, string) {
+	amount := eventAmount(event.Data["amount"])
+	switch event.Type {
+	case utils.EventPaymentConfirmed:
+		return "Pembayaran dikonfirmasi", fmt.Sprintf("Pembayaran sebesar Rp %.0f telah kami terima.", amount)
+	case utils.EventPaymentRejected:
+		return "Pembayaran ditolak", fmt.Sprintf("Bukti pembayaran sebesar Rp %.0f ditolak. Silakan unggah ulang bukti transfer.", amount)
+	case utils.EventPaymentProofUploaded:
+		return "Bukti pembayaran diterima", "Bukti pembayaran Anda sedang diverifikasi oleh admin."
+	case utils.EventBookingCreated:
+		return "Pesanan dibuat", "Pesanan kamar Anda berhasil dibuat dan menunggu konfirmasi pembayaran."
+	case utils.EventBookingCancelled:
+		if event.Data["reason"] == "expired" {
+			return "Pesanan dibatalkan", "Pesanan Anda dibatalkan otomatis karena pembayaran tidak diselesaikan dalam 7 hari."
+		}
+		return "Pesanan dibatalkan", "Pesanan kamar Anda telah dibatalkan."
+	case utils.EventBillCreated:
+		return "Tagihan baru", fmt.Sprintf("Tagihan sewa sebesar Rp %.0f telah dibuat.", amount)
+	case utils.EventBillReminder:
+		return "Pengingat tagihan", fmt.Sprintf("Tagihan sewa sebesar Rp %.0f akan segera jatuh tempo.", amount)
+	case utils.EventRentChangeNotice:
+		message := fmt.Sprintf("Harga sewa kamar %v berubah menjadi Rp %.0f per bulan.", event.Data["room_number"], eventAmount(event.Data["new_amount"]))
+		if effective, ok := event.Data["effective_from"].(time.Time); ok {
+			message = fmt.Sprintf("Harga sewa kamar %v berubah menjadi Rp %.0f per bulan mulai %s.",
+				event.Data["room_number"], eventAmount(event.Data["new_amount"]), effective.Format("02-01-2006"))
+		}
+		return "Perubahan harga sewa", message
+	case utils.EventTicketStatusChanged:
+		return "Update tiket perbaikan", fmt.Sprintf("Tiket \"%v\" sekarang berstatus %s.", event.Data["title"], ticketStatusLabel(event.Data["status"]))
+	case utils.EventTicketComment:
+		return "Balasan tiket perbaikan", fmt.Sprintf("Admin membalas tiket \"%v\".", event.Data["title"])
+	default:
+		return "Notifikasi", event.Type
+	}
+}
+
+// eventAmount menerima float64 (event lokal) maupun json.Number/float64 hasil decode
+func eventAmount(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	default:
+		return 0
+	}
+}
+
+// inboxEventPublisher menyimpan setiap event ke inbox sebelum diteruskan ke channel real-time
+type inboxEventPublisher struct {
+	service NotificationService
+}
+
+func NewInboxEventPublisher(s NotificationService) utils.EventPublisher {
+	return &inboxEventPublisher{s}
+}
+
+func (p *inboxEventPublisher) Publish(event utils.DomainEvent) {
+	if _, err := p.service.RecordEvent(event); err != nil {
+		utils.GlobalLogger.Error("Failed to store notification %s for user %d: %v", event.Type, event.UserID, err)
+	}
+}
+
+func ticketStatusLabel(status interface{}) string {
+	switch status {
+	case models.TicketStatusOpen:
+		return "dibuka kembali"
+	case models.TicketStatusInProgress:
+		return "sedang dikerjakan"
+	case models.TicketStatusResolved:
+		return "sudah diperbaiki"
+	case models.TicketStatusClosed:
+		return "ditutup"
+	default:
+		return fmt.Sprint(status)
+	}
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"koskosan-be/internal/templates"
+	"koskosan-be/internal/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockNotificationRepository implements repository.NotificationRepository interface
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepository) Create(notification *models.Notification) error {
+	args := m.Called(notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) FindByUserID(userID uint, pagination *utils.Pagination, unreadOnly bool) ([]models.Notification, int64, error) {
+	args := m.Called(userID, pagination, unreadOnly)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]models.Notification), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockNotificationRepository) CountUnread(userID uint) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationRepository) MarkRead(id uint, userID uint, readAt time.Time) error {
+	args := m.Called(id, userID, readAt)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) MarkAllRead(userID uint, readAt time.Time) (int64, error) {
+	args := m.Called(userID, readAt)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationRepository) WithTx(tx *gorm.DB) repository.NotificationRepository {
+	return m
+}
+
+// Test RecordEvent - stores tenant event with title and delivery channels
+func TestNotificationService_RecordEvent_StoresInbox(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	service := NewNotificationService(mockRepo)
+
+	event := utils.NewDomainEvent(utils.EventPaymentConfirmed, 7, map[string]interface{}{
+		"payment_id": uint(3),
+		"amount":     1500000.0,
+		"channels":   []string{"email", "whatsapp"},
+	})
+
+	mockRepo.On("Create", mock.MatchedBy(func(n *models.Notification) bool {
+		return n.UserID == 7 &&
+			n.EventID == event.ID &&
+			n.Type == utils.EventPaymentConfirmed &&
+			n.Title == "Pembayaran dikonfirmasi" &&
+			n.Message == "Pembayaran sebesar Rp 1500000 telah kami terima." &&
+			n.Channels == "in_app,email,whatsapp" &&
+			n.ReadAt == nil
+	})).Return(nil)
+
+	notification, err := service.RecordEvent(event)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, notification)
+	mockRepo.AssertExpectations(t)
+}
+
+// Test RecordEvent - admin-only events are not stored in any inbox
+func TestNotificationService_RecordEvent_SkipsAdminOnlyEvents(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	service := NewNotificationService(mockRepo)
+
+	notification, err := service.RecordEvent(utils.NewDomainEvent(utils.EventPaymentProofUploaded, 0, nil))
+
+	assert.NoError(t, err)
+	assert.Nil(t, notification)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// MockNotificationPreferenceRepository implements repository.NotificationPreferenceRepository interface
+type MockNotificationPreferenceRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationPreferenceRepository) FindByUserID(userID uint) ([]models.NotificationPreference, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.NotificationPreference), args.Error(1)
+}
+
+func (m *MockNotificationPreferenceRepository) Upsert(pref *models.NotificationPreference) error {
+	args := m.Called(pref)
+	return args.Error(0)
+}
+
+func (m *MockNotificationPreferenceRepository) FindSetting(userID uint) (*models.NotificationSetting, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*models.NotificationSetting), args.Error(1)
+}
+
+func (m *MockNotificationPreferenceRepository) SaveSetting(setting *models.NotificationSetting) error {
+	args := m.Called(setting)
+	return args.Error(0)
+}
+
+func (m *MockNotificationPreferenceRepository) WithTx(tx *gorm.DB) repository.NotificationPreferenceRepository {
+	return m
+}
+
+// MockOutboxService implements OutboxService interface
+type MockOutboxService struct {
+	mock.Mock
+}
+
+func (m *MockOutboxService) EnqueueEmail(event utils.DomainEvent, to, name, template string, msg *templates.Message) error {
+	args := m.Called(event, to, name, template, msg)
+	return args.Error(0)
+}
+
+func (m *MockOutboxService) EnqueueWhatsApp(event utils.DomainEvent, to, name, template string, msg *templates.Message, sms *templates.Message, sendAt time.Time) error {
+	args := m.Called(event, to, name, template, msg, sms, sendAt)
+	return args.Error(0)
+}
+
+func (m *MockOutboxService) ProcessDue(limit int) (int, error) {
+	args := m.Called(limit)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockOutboxService) GetDeliveries(status string, pagination *utils.Pagination) ([]models.OutboxMessage, int64, error) {
+	args := m.Called(status, pagination)
+	return args.Get(0).([]models.OutboxMessage), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockOutboxService) Resend(id uint) (*models.OutboxMessage, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OutboxMessage), args.Error(1)
+}
+
+func (m *MockOutboxService) UpdateWhatsAppStatus(status utils.WhatsAppStatus) error {
+	args := m.Called(status)
+	return args.Error(0)
+}
+
+func newTestDispatcher(prefRepo *MockNotificationPreferenceRepository, penyewaRepo *MockPenyewaRepository, outbox *MockOutboxService, events *MockEventPublisher, now time.Time) NotificationDispatcher {
+	d := NewNotificationDispatcher(NewNotificationPreferenceService(prefRepo), penyewaRepo, outbox, templates.MustNew(nil), events).(*notificationDispatcher)
+	d.now = func() time.Time { return now }
+	return d
+}
+
+// Test Dispatch - queues to the tenant's own phone and honours per-category preferences
+func TestNotificationDispatcher_UsesTenantContactsAndPreferences(t *testing.T) {
+	prefRepo := new(MockNotificationPreferenceRepository)
+	penyewaRepo := new(MockPenyewaRepository)
+	outbox := new(MockOutboxService)
+	events := new(MockEventPublisher)
+	dispatcher := newTestDispatcher(prefRepo, penyewaRepo, outbox, events, time.Date(2026, 10, 19, 8, 0, 0, 0, time.Local))
+
+	prefRepo.On("FindByUserID", uint(7)).Return([]models.NotificationPreference{
+		{UserID: 7, Category: NotificationCategoryBill, Email: false, WhatsApp: true, InApp: true},
+	}, nil)
+	prefRepo.On("FindSetting", uint(7)).Return(&models.NotificationSetting{UserID: 7}, nil)
+	penyewaRepo.On("FindByUserID", uint(7)).Return(&models.Penyewa{UserID: 7, NamaLengkap: "Budi", Email: "tenant@example.com", NomorHP: "0812-3456-7890"}, nil)
+	outbox.On("EnqueueWhatsApp", mock.Anything, "6281234567890", "Budi", templates.PaymentReminder, mock.MatchedBy(func(m *templates.Message) bool {
+		return strings.Contains(m.Body, "Halo Budi") && strings.Contains(m.Body, "Rp 1.000.000")
+	}), mock.MatchedBy(func(sms *templates.Message) bool {
+		// Pengingat tagihan membawa SMS cadangan
+		return sms != nil && strings.HasPrefix(sms.Body, "Kost: Yth. Budi") && !strings.Contains(sms.Body, "*")
+	}), time.Time{}).Return(nil)
+	events.On("Publish", mock.MatchedBy(func(e utils.DomainEvent) bool {
+		channels, _ := e.Data["channels"].([]string)
+		return e.UserID == 7 && assert.ObjectsAreEqual([]string{"whatsapp"}, channels)
+	})).Return()
+
+	channels, err := dispatcher.Dispatch(OutboundMessage{
+		Event:    utils.NewDomainEvent(utils.EventBillReminder, 7, map[string]interface{}{"amount": 1000000.0}),
+		Template: templates.PaymentReminder,
+		Data:     map[string]interface{}{"Amount": 1000000.0, "DueDate": time.Now(), "RoomNumber": "A-01", "PaymentLink": "link"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"in_app", "whatsapp"}, channels)
+	outbox.AssertNotCalled(t, "EnqueueEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	outbox.AssertExpectations(t)
+	events.AssertExpectations(t)
+}
+
+// Test Dispatch - WhatsApp is deferred to the end of quiet hours, email still goes out now in the tenant's language
+func TestNotificationDispatcher_QuietHoursDeferWhatsApp(t *testing.T) {
+	prefRepo := new(MockNotificationPreferenceRepository)
+	penyewaRepo := new(MockPenyewaRepository)
+	outbox := new(MockOutboxService)
+	events := new(MockEventPublisher)
+	dispatcher := newTestDispatcher(prefRepo, penyewaRepo, outbox, events, time.Date(2026, 10, 19, 23, 30, 0, 0, time.Local))
+
+	prefRepo.On("FindByUserID", uint(7)).Return([]models.NotificationPreference{}, nil)
+	prefRepo.On("FindSetting", uint(7)).Return(&models.NotificationSetting{
+		UserID: 7, QuietHoursEnabled: true, QuietHoursStart: "22:00", QuietHoursEnd: "07:00", Language: "en",
+	}, nil)
+	penyewaRepo.On("FindByUserID", uint(7)).Return(&models.Penyewa{UserID: 7, NamaLengkap: "Budi", Email: "budi@example.com", NomorHP: "081234567890"}, nil)
+	outbox.On("EnqueueEmail", mock.Anything, "budi@example.com", "Budi", templates.PaymentSuccess, mock.MatchedBy(func(m *templates.Message) bool {
+		return m.Subject == "Payment Confirmation - Kost Putra Rahmat ZAW" && strings.Contains(m.Body, "Rp 500.000")
+	})).Return(nil)
+	outbox.On("EnqueueWhatsApp", mock.Anything, "6281234567890", "Budi", templates.PaymentSuccess, mock.Anything, mock.Anything,
+		time.Date(2026, 10, 20, 7, 0, 0, 0, time.Local)).Return(nil)
+	events.On("Publish", mock.Anything).Return()
+
+	channels, err := dispatcher.Dispatch(OutboundMessage{
+		Event:    utils.NewDomainEvent(utils.EventPaymentConfirmed, 7, nil),
+		Template: templates.PaymentSuccess,
+		Data:     map[string]interface{}{"Amount": 500000.0, "Date": time.Now(), "RoomNumber": "A-01"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"in_app", "email", "whatsapp"}, channels)
+	outbox.AssertExpectations(t)
+}
+
+// Test Dispatch - a retry only queues channels that failed before and does not republish the event
+func TestNotificationDispatcher_SkipsAlreadyDeliveredChannels(t *testing.T) {
+	prefRepo := new(MockNotificationPreferenceRepository)
+	penyewaRepo := new(MockPenyewaRepository)
+	outbox := new(MockOutboxService)
+	events := new(MockEventPublisher)
+	dispatcher := newTestDispatcher(prefRepo, penyewaRepo, outbox, events, time.Date(2026, 10, 19, 8, 0, 0, 0, time.Local))
+
+	prefRepo.On("FindByUserID", uint(7)).Return([]models.NotificationPreference{}, nil)
+	prefRepo.On("FindSetting", uint(7)).Return(&models.NotificationSetting{UserID: 7}, nil)
+	penyewaRepo.On("FindByUserID", uint(7)).Return(&models.Penyewa{UserID: 7, NamaLengkap: "Budi", Email: "budi@example.com", NomorHP: "081234567890"}, nil)
+	outbox.On("EnqueueWhatsApp", mock.Anything, "6281234567890", "Budi", templates.PaymentReminder, mock.Anything, mock.Anything, time.Time{}).Return(nil)
+
+	channels, err := dispatcher.Dispatch(OutboundMessage{
+		Event:    utils.NewDomainEvent(utils.EventBillReminder, 7, nil),
+		Template: templates.PaymentReminder,
+		Data:     map[string]interface{}{"Amount": 1000000.0, "DueDate": time.Now(), "RoomNumber": "A-01", "PaymentLink": "link"},
+		Skip:     []string{"in_app", "email"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"whatsapp"}, channels)
+	outbox.AssertNotCalled(t, "EnqueueEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	events.AssertNotCalled(t, "Publish", mock.Anything)
+	outbox.AssertExpectations(t)
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"koskosan-be/internal/templates"
+	"koskosan-be/internal/utils"
+	"time"
+)
+
+const (
+	outboxMaxAttempts = 5
+	outboxBaseBackoff = time.Minute
+	outboxMaxBackoff  = 6 * time.Hour
+	// outboxClaimLease: lama pesan "dipegang" satu worker sebelum boleh diambil worker lain
+	outboxClaimLease = 5 * time.Minute
+)
+
+var ErrOutboxAlreadySent = errors.New("pesan sudah terkirim")
+
+type OutboxService interface {
+	EnqueueEmail(event utils.DomainEvent, to, name, template string, msg *templates.Message) error
+	// EnqueueWhatsApp: sms boleh nil; jika diisi, dikirim sebagai SMS ke nomor yang sama saat WhatsApp gagal.
+	// sendAt zero = segera; diisi untuk menahan pesan sampai jam tenang tenant selesai.
+	EnqueueWhatsApp(event utils.DomainEvent, to, name, template string, msg *templates.Message, sms *templates.Message, sendAt time.Time) error
+	ProcessDue(limit int) (int, error)
+	GetDeliveries(status string, pagination *utils.Pagination) ([]models.OutboxMessage, int64, error)
+	Resend(id uint) (*models.OutboxMessage, error)
+	UpdateWhatsAppStatus(status utils.WhatsAppStatus) error
+}
+
+type outboxService struct {
+	repo        repository.OutboxRepository
+	emailSender utils.EmailSender
+	waSender    utils.WhatsAppSender
+	smsSender   utils.SMSSender
+	now         func() time.Time
+}
+
+func NewOutboxService(repo repository.OutboxRepository, emailSender utils.EmailSender, waSender utils.WhatsAppSender, smsSender utils.SMSSender) OutboxService {
+	return &outboxService{repo, emailSender, waSender, smsSender, time.Now}
+}
+
+func (s *outboxService) EnqueueEmail(event utils.DomainEvent, to, name, template string, msg *templates.Message) error {
+	return s.enqueue(&models.OutboxMessage{
+		UserID:        event.UserID,
+		EventID:       event.ID,
+		EventType:     event.Type,
+		Channel:       templates.ChannelEmail,
+		Recipient:     to,
+		RecipientName: name,
+		Template:      template,
+		Subject:       msg.Subject,
+		Body:          msg.Body,
+	})
+}
+
+func (s *outboxService) EnqueueWhatsApp(event utils.DomainEvent, to, name, template string, msg *templates.Message, sms *templates.Message, sendAt time.Time) error {
+	outbox := &models.OutboxMessage{
+		UserID:        event.UserID,
+		EventID:       event.ID,
+		EventType:     event.Type,
+		Channel:       templates.ChannelWhatsApp,
+		Recipient:     to,
+		RecipientName: name,
+		Template:      template,
+		Body:          msg.Body,
+		NextRetryAt:   sendAt,
+	}
+	if sms != nil {
+		outbox.SMSBody = sms.Body
+	}
+	return s.enqueue(outbox)
+}
+
+// enqueue menyimpan pesan pending; NextRetryAt yang sudah diisi (di masa depan) menunda pengiriman,
+// karena worker hanya mengambil pesan dengan next_retry_at <= sekarang
+func (s *outboxService) enqueue(msg *models.OutboxMessage) error {
+	msg.Status = models.OutboxStatusPending
+	msg.MaxAttempts = outboxMaxAttempts
+	if now := s.now(); msg.NextRetryAt.Before(now) {
+		msg.NextRetryAt = now
+	}
+	return s.repo.Create(msg)
+}
+
+// ProcessDue mengirim pesan yang sudah jatuh waktu. Gagal -> dijadwalkan ulang dengan backoff eksponensial,
+// setelah MaxAttempts percobaan statusnya menjadi failed. Mengembalikan jumlah pesan yang terkirim.
+func (s *outboxService) ProcessDue(limit int) (int, error) {
+	messages, err := s.repo.ClaimDue(s.now(), limit, outboxClaimLease)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range messages {
+		msg := &messages[i]
+		msg.Attempts++
+
+		providerID, via, err := s.deliver(msg)
+		if err != nil {
+			msg.LastError = err.Error()
+			if msg.Attempts >= msg.MaxAttempts {
+				msg.Status = models.OutboxStatusFailed
+				utils.GlobalLogger.Error("Outbox %d (%s to user %d) failed permanently after %d attempts: %v", msg.ID, msg.Channel, msg.UserID, msg.Attempts, err)
+			} else {
+				msg.NextRetryAt = s.now().Add(outboxBackoff(msg.Attempts))
+			}
+		} else {
+			now := s.now()
+			msg.Status = models.OutboxStatusSent
+			msg.SentAt = &now
+			msg.SentVia = via
+			msg.LastError = ""
+			if via == templates.ChannelWhatsApp {
+				msg.ProviderMessageID = providerID
+				if providerID != "" {
+					msg.DeliveryStatus = utils.WhatsAppStatusSent
+				}
+			}
+			sent++
+		}
+
+		if err := s.repo.Save(msg); err != nil {
+			utils.GlobalLogger.Error("Failed to update outbox %d: %v", msg.ID, err)
+		}
+	}
+	return sent, nil
+}
+
+// deliver mengirim pesan dan mengembalikan ID pesan dari provider (kosong untuk email) serta channel yang dipakai.
+// WhatsApp yang gagal langsung dicoba lewat SMS pada percobaan yang sama jika SMSBody diisi;
+// jika keduanya gagal, pesan ikut jadwal retry biasa.
+func (s *outboxService) deliver(msg *models.OutboxMessage) (string, string, error) {
+	switch msg.Channel {
+	case templates.ChannelWhatsApp:
+		id, err := s.waSender.SendWhatsApp(msg.Recipient, msg.Body)
+		if err == nil {
+			return id, templates.ChannelWhatsApp, nil
+		}
+		if msg.SMSBody == "" {
+			return "", "", err
+		}
+		smsID, smsErr := s.smsSender.SendSMS(msg.Recipient, msg.SMSBody)
+		if smsErr != nil {
+			return "", "", fmt.Errorf("whatsapp: %v; sms: %v", err, smsErr)
+		}
+		utils.GlobalLogger.Info("Outbox %d: WhatsApp to user %d failed (%v), sent via SMS", msg.ID, msg.UserID, err)
+		return smsID, templates.ChannelSMS, nil
+	case templates.ChannelSMS:
+		id, err := s.smsSender.SendSMS(msg.Recipient, msg.Body)
+		return id, templates.ChannelSMS, err
+	case templates.ChannelEmail:
+		return "", templates.ChannelEmail, s.emailSender.SendEmail(msg.Recipient, msg.Subject, msg.Body)
+	}
+	return "", "", fmt.Errorf("channel tidak dikenal: %s", msg.Channel)
+}
+
+func (s *outboxService) GetDeliveries(status string, pagination *utils.Pagination) ([]models.OutboxMessage, int64, error) {
+	return s.repo.FindByStatus(status, pagination)
+}
+
+// Resend menjadwalkan ulang pesan (biasanya yang failed) untuk dikirim worker secepatnya dengan jatah percobaan baru
+func (s *outboxService) Resend(id uint) (*models.OutboxMessage, error) {
+	msg, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Status == models.OutboxStatusSent {
+		return nil, ErrOutboxAlreadySent
+	}
+
+	msg.Status = models.OutboxStatusPending
+	msg.Attempts = 0
+	msg.NextRetryAt = s.now()
+	// Pengiriman ulang mendapat ID provider baru, status lama dibuang
+	msg.SentVia = ""
+	msg.ProviderMessageID = ""
+	msg.DeliveryStatus = ""
+	msg.DeliveredAt = nil
+	msg.ReadAt = nil
+	if err := s.repo.Save(msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// deliveryStatusRank: callback bisa datang tidak berurutan, status tidak boleh mundur (read -> delivered)
+var deliveryStatusRank = map[string]int{
+	utils.WhatsAppStatusSent:      1,
+	utils.WhatsAppStatusDelivered: 2,
+	utils.WhatsAppStatusRead:      3,
+	utils.WhatsAppStatusFailed:    4,
+}
+
+// UpdateWhatsAppStatus menyimpan status delivered/read/failed dari webhook provider.
+// Status failed mengembalikan pesan ke status failed supaya bisa dikirim ulang admin.
+func (s *outboxService) UpdateWhatsAppStatus(status utils.WhatsAppStatus) error {
+	rank, ok := deliveryStatusRank[status.Status]
+	if !ok {
+		return nil // status lain (mis. "deleted") diabaikan
+	}
+
+	msg, err := s.repo.FindByProviderMessageID(templates.ChannelWhatsApp, status.MessageID)
+	if err != nil {
+		return err
+	}
+	if rank <= deliveryStatusRank[msg.DeliveryStatus] {
+		return nil
+	}
+
+	at := status.Timestamp
+	if at.IsZero() {
+		at = s.now()
+	}
+	msg.DeliveryStatus = status.Status
+	switch status.Status {
+	case utils.WhatsAppStatusDelivered:
+		msg.DeliveredAt = &at
+	case utils.WhatsAppStatusRead:
+		if msg.DeliveredAt == nil {
+			msg.DeliveredAt = &at
+		}
+		msg.ReadAt = &at
+	case utils.WhatsAppStatusFailed:
+		msg.Status = models.OutboxStatusFailed
+		msg.LastError = status.Error
+		if msg.LastError == "" {
+			msg.LastError = "provider melaporkan pesan gagal terkirim"
+		}
+		// Gagal setelah diterima provider (misalnya nomor tidak punya WhatsApp): kirim SMS lewat antrian yang sama
+		if msg.SMSBody != "" {
+			if err := s.enqueueSMSFallback(msg); err != nil {
+				return err
+			}
+			msg.LastError += " (dialihkan ke SMS)"
+		}
+	}
+	return s.repo.Save(msg)
+}
+
+func (s *outboxService) enqueueSMSFallback(msg *models.OutboxMessage) error {
+	return s.enqueue(&models.OutboxMessage{
+		UserID:        msg.UserID,
+		EventID:       msg.EventID,
+		EventType:     msg.EventType,
+		Channel:       templates.ChannelSMS,
+		Recipient:     msg.Recipient,
+		RecipientName: msg.RecipientName,
+		Template:      msg.Template,
+		Body:          msg.SMSBody,
+	})
+}
+
+// outboxBackoff: 1m, 2m, 4m, 8m, ... maksimal 6 jam
+func outboxBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := outboxBaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= outboxMaxBackoff {
+			return outboxMaxBackoff
+		}
+	}
+	return delay
+}
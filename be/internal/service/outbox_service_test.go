@@ -0,0 +1,319 @@
+package service
+
+import (
+	"errors"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"koskosan-be/internal/templates"
+	"koskosan-be/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockOutboxRepository implements repository.OutboxRepository interface
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) Create(msg *models.OutboxMessage) error {
+	args := m.Called(msg)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) FindByID(id uint) (*models.OutboxMessage, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OutboxMessage), args.Error(1)
+}
+
+func (m *MockOutboxRepository) FindByProviderMessageID(channel, providerMessageID string) (*models.OutboxMessage, error) {
+	args := m.Called(channel, providerMessageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OutboxMessage), args.Error(1)
+}
+
+func (m *MockOutboxRepository) FindByStatus(status string, pagination *utils.Pagination) ([]models.OutboxMessage, int64, error) {
+	args := m.Called(status, pagination)
+	return args.Get(0).([]models.OutboxMessage), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockOutboxRepository) ClaimDue(now time.Time, limit int, lease time.Duration) ([]models.OutboxMessage, error) {
+	args := m.Called(now, limit, lease)
+	return args.Get(0).([]models.OutboxMessage), args.Error(1)
+}
+
+func (m *MockOutboxRepository) Save(msg *models.OutboxMessage) error {
+	args := m.Called(msg)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) WithTx(tx *gorm.DB) repository.OutboxRepository {
+	return m
+}
+
+// MockSMSSender implements utils.SMSSender interface
+type MockSMSSender struct {
+	mock.Mock
+}
+
+func (m *MockSMSSender) SendSMS(to, message string) (string, error) {
+	args := m.Called(to, message)
+	return args.String(0), args.Error(1)
+}
+
+func newTestOutboxService(repo *MockOutboxRepository, emailSender *MockEmailSender, waSender *MockWhatsAppSender, now time.Time) *outboxService {
+	s := NewOutboxService(repo, emailSender, waSender, new(MockSMSSender)).(*outboxService)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+// Test EnqueueEmail - stores the rendered message so the worker only has to deliver it
+func TestOutboxService_EnqueueEmail(t *testing.T) {
+	repo := new(MockOutboxRepository)
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	s := newTestOutboxService(repo, new(MockEmailSender), new(MockWhatsAppSender), now)
+
+	event := utils.NewDomainEvent(utils.EventPaymentConfirmed, 7, nil)
+	repo.On("Create", mock.MatchedBy(func(m *models.OutboxMessage) bool {
+		return m.UserID == 7 &&
+			m.EventID == event.ID &&
+			m.Channel == templates.ChannelEmail &&
+			m.Template == templates.PaymentSuccess &&
+			m.Subject == "Pembayaran Berhasil" &&
+			m.Body == "<p>ok</p>" &&
+			m.Status == models.OutboxStatusPending &&
+			m.MaxAttempts == outboxMaxAttempts &&
+			m.NextRetryAt.Equal(now)
+	})).Return(nil)
+
+	err := s.EnqueueEmail(event, "budi@example.com", "Budi", templates.PaymentSuccess, &templates.Message{Subject: "Pembayaran Berhasil", Body: "<p>ok</p>"})
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+// Test EnqueueWhatsApp - a message deferred for quiet hours only becomes due at sendAt
+func TestOutboxService_EnqueueWhatsAppDeferred(t *testing.T) {
+	repo := new(MockOutboxRepository)
+	now := time.Date(2026, 10, 19, 23, 30, 0, 0, time.UTC)
+	sendAt := time.Date(2026, 10, 20, 7, 0, 0, 0, time.UTC)
+	s := newTestOutboxService(repo, new(MockEmailSender), new(MockWhatsAppSender), now)
+
+	repo.On("Create", mock.MatchedBy(func(m *models.OutboxMessage) bool {
+		return m.Channel == templates.ChannelWhatsApp && m.Status == models.OutboxStatusPending && m.NextRetryAt.Equal(sendAt)
+	})).Return(nil).Once()
+	repo.On("Create", mock.MatchedBy(func(m *models.OutboxMessage) bool {
+		return m.Channel == templates.ChannelWhatsApp && m.NextRetryAt.Equal(now)
+	})).Return(nil).Once()
+
+	event := utils.NewDomainEvent(utils.EventBillReminder, 7, nil)
+	msg := &templates.Message{Body: "Tagihan"}
+	assert.NoError(t, s.EnqueueWhatsApp(event, "6281234567890", "Budi", templates.PaymentReminder, msg, nil, sendAt))
+	// sendAt yang sudah lewat atau kosong berarti dikirim segera
+	assert.NoError(t, s.EnqueueWhatsApp(event, "6281234567890", "Budi", templates.PaymentReminder, msg, nil, time.Time{}))
+	repo.AssertExpectations(t)
+}
+
+// Test ProcessDue - sent, retried with backoff, and permanently failed messages
+func TestOutboxService_ProcessDue(t *testing.T) {
+	repo := new(MockOutboxRepository)
+	emailSender := new(MockEmailSender)
+	waSender := new(MockWhatsAppSender)
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	s := newTestOutboxService(repo, emailSender, waSender, now)
+
+	repo.On("ClaimDue", now, 10, outboxClaimLease).Return([]models.OutboxMessage{
+		{ID: 1, Channel: "email", Recipient: "budi@example.com", Subject: "Pembayaran Berhasil", Body: "<p>ok</p>", MaxAttempts: 5},
+		{ID: 2, Channel: "whatsapp", Recipient: "6281234567890", Body: "halo", Status: models.OutboxStatusPending, Attempts: 1, MaxAttempts: 5},
+		{ID: 3, Channel: "whatsapp", Recipient: "6281234567891", Body: "halo", Attempts: 4, MaxAttempts: 5},
+		{ID: 4, Channel: "whatsapp", Recipient: "6281234567892", Body: "tagihan", MaxAttempts: 5},
+	}, nil)
+	emailSender.On("SendEmail", "budi@example.com", "Pembayaran Berhasil", "<p>ok</p>").Return(nil)
+	waSender.On("SendWhatsApp", mock.Anything, "halo").Return("", errors.New("gateway down"))
+	waSender.On("SendWhatsApp", "6281234567892", "tagihan").Return("wamid.ABC", nil)
+
+	saved := map[uint]models.OutboxMessage{}
+	repo.On("Save", mock.Anything).Run(func(args mock.Arguments) {
+		m := args.Get(0).(*models.OutboxMessage)
+		saved[m.ID] = *m
+	}).Return(nil)
+
+	sent, err := s.ProcessDue(10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, sent)
+
+	assert.Equal(t, models.OutboxStatusSent, saved[1].Status)
+	assert.NotNil(t, saved[1].SentAt)
+	assert.Empty(t, saved[1].ProviderMessageID)
+
+	assert.Equal(t, models.OutboxStatusSent, saved[4].Status)
+	assert.Equal(t, "wamid.ABC", saved[4].ProviderMessageID)
+	assert.Equal(t, utils.WhatsAppStatusSent, saved[4].DeliveryStatus)
+
+	assert.Equal(t, models.OutboxStatusPending, saved[2].Status)
+	assert.Equal(t, 2, saved[2].Attempts)
+	assert.Equal(t, now.Add(2*time.Minute), saved[2].NextRetryAt)
+	assert.Equal(t, "gateway down", saved[2].LastError)
+
+	assert.Equal(t, models.OutboxStatusFailed, saved[3].Status)
+	assert.Equal(t, 5, saved[3].Attempts)
+}
+
+// Test ProcessDue - WhatsApp errors fall back to SMS in the same attempt
+func TestOutboxService_ProcessDueSMSFallback(t *testing.T) {
+	repo := new(MockOutboxRepository)
+	waSender := new(MockWhatsAppSender)
+	smsSender := new(MockSMSSender)
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	s := newTestOutboxService(repo, new(MockEmailSender), waSender, now)
+	s.smsSender = smsSender
+
+	repo.On("ClaimDue", now, 10, outboxClaimLease).Return([]models.OutboxMessage{
+		{ID: 1, Channel: "whatsapp", Recipient: "6281234567890", Body: "pengingat", SMSBody: "sms 1", Status: models.OutboxStatusPending, MaxAttempts: 5},
+		{ID: 2, Channel: "whatsapp", Recipient: "6281234567891", Body: "pengingat", SMSBody: "sms 2", Status: models.OutboxStatusPending, MaxAttempts: 5},
+		{ID: 3, Channel: "whatsapp", Recipient: "6281234567892", Body: "lunas", Status: models.OutboxStatusPending, MaxAttempts: 5},
+	}, nil)
+	waSender.On("SendWhatsApp", mock.Anything, mock.Anything).Return("", errors.New("target is not on whatsapp"))
+	smsSender.On("SendSMS", "6281234567890", "sms 1").Return("zz-1", nil)
+	smsSender.On("SendSMS", "6281234567891", "sms 2").Return("", errors.New("insufficient balance"))
+
+	saved := map[uint]models.OutboxMessage{}
+	repo.On("Save", mock.Anything).Run(func(args mock.Arguments) {
+		m := args.Get(0).(*models.OutboxMessage)
+		saved[m.ID] = *m
+	}).Return(nil)
+
+	sent, err := s.ProcessDue(10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	assert.Equal(t, models.OutboxStatusSent, saved[1].Status)
+	assert.Equal(t, templates.ChannelSMS, saved[1].SentVia)
+	assert.Empty(t, saved[1].ProviderMessageID)
+
+	assert.Equal(t, models.OutboxStatusPending, saved[2].Status)
+	assert.Equal(t, "whatsapp: target is not on whatsapp; sms: insufficient balance", saved[2].LastError)
+
+	// Tanpa SMSBody tidak ada fallback
+	assert.Equal(t, models.OutboxStatusPending, saved[3].Status)
+	smsSender.AssertNumberOfCalls(t, "SendSMS", 2)
+}
+
+// Test Resend - failed message gets a fresh set of attempts
+func TestOutboxService_Resend(t *testing.T) {
+	repo := new(MockOutboxRepository)
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	s := newTestOutboxService(repo, new(MockEmailSender), new(MockWhatsAppSender), now)
+
+	repo.On("FindByID", uint(3)).Return(&models.OutboxMessage{ID: 3, Status: models.OutboxStatusFailed, Attempts: 5, LastError: "gateway down"}, nil)
+	repo.On("FindByID", uint(4)).Return(&models.OutboxMessage{ID: 4, Status: models.OutboxStatusSent}, nil)
+	repo.On("Save", mock.Anything).Return(nil)
+
+	msg, err := s.Resend(3)
+	assert.NoError(t, err)
+	assert.Equal(t, models.OutboxStatusPending, msg.Status)
+	assert.Equal(t, 0, msg.Attempts)
+	assert.Equal(t, now, msg.NextRetryAt)
+
+	_, err = s.Resend(4)
+	assert.ErrorIs(t, err, ErrOutboxAlreadySent)
+}
+
+// Test UpdateWhatsAppStatus - callbacks move the status forward only, failed makes the message resendable
+func TestOutboxService_UpdateWhatsAppStatus(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	readAt := now.Add(5 * time.Minute)
+
+	t.Run("Delivered then read", func(t *testing.T) {
+		repo := new(MockOutboxRepository)
+		s := newTestOutboxService(repo, new(MockEmailSender), new(MockWhatsAppSender), now)
+
+		msg := &models.OutboxMessage{ID: 1, Channel: "whatsapp", Status: models.OutboxStatusSent, ProviderMessageID: "wamid.1", DeliveryStatus: utils.WhatsAppStatusSent}
+		repo.On("FindByProviderMessageID", templates.ChannelWhatsApp, "wamid.1").Return(msg, nil)
+		repo.On("Save", msg).Return(nil)
+
+		assert.NoError(t, s.UpdateWhatsAppStatus(utils.WhatsAppStatus{MessageID: "wamid.1", Status: utils.WhatsAppStatusDelivered, Timestamp: now}))
+		assert.NoError(t, s.UpdateWhatsAppStatus(utils.WhatsAppStatus{MessageID: "wamid.1", Status: utils.WhatsAppStatusRead, Timestamp: readAt}))
+
+		assert.Equal(t, utils.WhatsAppStatusRead, msg.DeliveryStatus)
+		assert.Equal(t, now, *msg.DeliveredAt)
+		assert.Equal(t, readAt, *msg.ReadAt)
+		repo.AssertNumberOfCalls(t, "Save", 2)
+	})
+
+	t.Run("Late delivered callback is ignored", func(t *testing.T) {
+		repo := new(MockOutboxRepository)
+		s := newTestOutboxService(repo, new(MockEmailSender), new(MockWhatsAppSender), now)
+
+		msg := &models.OutboxMessage{ID: 1, Channel: "whatsapp", ProviderMessageID: "wamid.1", DeliveryStatus: utils.WhatsAppStatusRead, ReadAt: &readAt}
+		repo.On("FindByProviderMessageID", templates.ChannelWhatsApp, "wamid.1").Return(msg, nil)
+
+		assert.NoError(t, s.UpdateWhatsAppStatus(utils.WhatsAppStatus{MessageID: "wamid.1", Status: utils.WhatsAppStatusDelivered, Timestamp: now}))
+
+		assert.Equal(t, utils.WhatsAppStatusRead, msg.DeliveryStatus)
+		repo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		repo := new(MockOutboxRepository)
+		s := newTestOutboxService(repo, new(MockEmailSender), new(MockWhatsAppSender), now)
+
+		msg := &models.OutboxMessage{ID: 1, Channel: "whatsapp", Status: models.OutboxStatusSent, ProviderMessageID: "wamid.1", DeliveryStatus: utils.WhatsAppStatusDelivered}
+		repo.On("FindByProviderMessageID", templates.ChannelWhatsApp, "wamid.1").Return(msg, nil)
+		repo.On("Save", msg).Return(nil)
+
+		assert.NoError(t, s.UpdateWhatsAppStatus(utils.WhatsAppStatus{MessageID: "wamid.1", Status: utils.WhatsAppStatusFailed, Error: "131026 Message undeliverable"}))
+
+		assert.Equal(t, models.OutboxStatusFailed, msg.Status)
+		assert.Equal(t, "131026 Message undeliverable", msg.LastError)
+	})
+
+	t.Run("Failed after acceptance queues SMS fallback", func(t *testing.T) {
+		repo := new(MockOutboxRepository)
+		s := newTestOutboxService(repo, new(MockEmailSender), new(MockWhatsAppSender), now)
+
+		msg := &models.OutboxMessage{ID: 1, UserID: 7, EventID: "evt", Channel: "whatsapp", Recipient: "6281234567890", Template: templates.PaymentReminder,
+			Status: models.OutboxStatusSent, ProviderMessageID: "wamid.1", DeliveryStatus: utils.WhatsAppStatusSent, SMSBody: "sms pengingat"}
+		repo.On("FindByProviderMessageID", templates.ChannelWhatsApp, "wamid.1").Return(msg, nil)
+		repo.On("Create", mock.MatchedBy(func(m *models.OutboxMessage) bool {
+			return m.Channel == templates.ChannelSMS &&
+				m.Recipient == "6281234567890" &&
+				m.Body == "sms pengingat" &&
+				m.EventID == "evt" &&
+				m.Status == models.OutboxStatusPending
+		})).Return(nil)
+		repo.On("Save", msg).Return(nil)
+
+		assert.NoError(t, s.UpdateWhatsAppStatus(utils.WhatsAppStatus{MessageID: "wamid.1", Status: utils.WhatsAppStatusFailed, Error: "131026 Message undeliverable"}))
+
+		assert.Equal(t, models.OutboxStatusFailed, msg.Status)
+		assert.Contains(t, msg.LastError, "dialihkan ke SMS")
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown message", func(t *testing.T) {
+		repo := new(MockOutboxRepository)
+		s := newTestOutboxService(repo, new(MockEmailSender), new(MockWhatsAppSender), now)
+
+		repo.On("FindByProviderMessageID", templates.ChannelWhatsApp, "wamid.x").Return(nil, gorm.ErrRecordNotFound)
+
+		err := s.UpdateWhatsAppStatus(utils.WhatsAppStatus{MessageID: "wamid.x", Status: utils.WhatsAppStatusDelivered})
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	assert.Equal(t, time.Minute, outboxBackoff(1))
+	assert.Equal(t, 8*time.Minute, outboxBackoff(4))
+	assert.Equal(t, outboxMaxBackoff, outboxBackoff(20))
+}
@@ -1,24 +1,34 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"koskosan-be/internal/models"
 	"koskosan-be/internal/repository"
+	"koskosan-be/internal/templates"
 	"koskosan-be/internal/utils"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+var (
+	ErrPaymentProofForbidden = errors.New("bukti pembayaran bukan milik Anda")
+	ErrPaymentProofNotFound  = errors.New("bukti pembayaran belum diunggah")
+)
+
 type PaymentService interface {
 	GetAllPayments() ([]models.Pembayaran, error)
 	ConfirmPayment(paymentID uint) error
 	RejectPayment(paymentID uint) error
-	CreatePaymentSession(pemesananID uint, paymentType string) (*models.Pembayaran, error)
+	// CreatePaymentSession memakai snapshot harga pemesanan; jika belum ada, harga dihitung (dengan voucherCode opsional) lalu disimpan
+	CreatePaymentSession(pemesananID uint, paymentType string, voucherCode string) (*models.Pembayaran, error)
 	ConfirmCashPayment(paymentID uint, buktiTransfer string) error
 	GetPaymentReminders(userID uint) ([]models.PaymentReminder, error)
 	CreatePaymentReminder(pembayaranID uint, jumlahBayar float64, daysUntilDue int) error
 	UploadPaymentProof(paymentID uint, buktiTransfer string, userID uint) error
+	// GetPaymentProof mengembalikan referensi bukti transfer; hanya untuk penyewa pemilik pembayaran atau admin
+	GetPaymentProof(paymentID uint, userID uint, isAdmin bool) (string, error)
 }
 
 type paymentService struct {
@@ -27,12 +37,19 @@ type paymentService struct {
 	kamarRepo   repository.KamarRepository
 	penyewaRepo repository.PenyewaRepository
 	db          *gorm.DB
-	emailSender utils.EmailSender
-	waSender    utils.WhatsAppSender
+	notifier    NotificationDispatcher
+	pricing     PriceCalculator
 }
 
-func NewPaymentService(repo repository.PaymentRepository, bookingRepo repository.BookingRepository, kamarRepo repository.KamarRepository, penyewaRepo repository.PenyewaRepository, db *gorm.DB, emailSender utils.EmailSender, waSender utils.WhatsAppSender) PaymentService {
-	return &paymentService{repo, bookingRepo, kamarRepo, penyewaRepo, db, emailSender, waSender}
+// NewPaymentService: pricing nil berarti harga penuh tanpa diskon
+func NewPaymentService(repo repository.PaymentRepository, bookingRepo repository.BookingRepository, kamarRepo repository.KamarRepository, penyewaRepo repository.PenyewaRepository, db *gorm.DB, notifier NotificationDispatcher, pricing PriceCalculator) PaymentService {
+	if notifier == nil {
+		notifier = noopDispatcher{}
+	}
+	if pricing == nil {
+		pricing = flatPricing{}
+	}
+	return &paymentService{repo, bookingRepo, kamarRepo, penyewaRepo, db, notifier, pricing}
 }
 
 func (s *paymentService) GetAllPayments() ([]models.Pembayaran, error) {
@@ -40,7 +57,7 @@ func (s *paymentService) GetAllPayments() ([]models.Pembayaran, error) {
 }
 
 func (s *paymentService) ConfirmPayment(paymentID uint) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		txRepo := s.repo.WithTx(tx)
 		txBookingRepo := s.bookingRepo.WithTx(tx)
 		txKamarRepo := s.kamarRepo.WithTx(tx)
@@ -65,8 +82,11 @@ func (s *paymentService) ConfirmPayment(paymentID uint) error {
 		// Also update booking status if needed
 		booking, err := txBookingRepo.FindByID(payment.PemesananID)
 		if err == nil {
-			// If this is an extension payment, increase the DurasiSewa based on payment amount and room price
-			if payment.TipePembayaran == "extend" {
+			// If this is an extension payment, increase the DurasiSewa by the months billed
+			// (tagihan lama tanpa JumlahBulan: dihitung dari nominal dan harga kamar)
+			if payment.TipePembayaran == "extend" && payment.JumlahBulan > 0 {
+				booking.DurasiSewa += payment.JumlahBulan
+			} else if payment.TipePembayaran == "extend" {
 				kamar, kamarErr := txKamarRepo.FindByID(booking.KamarID)
 				if kamarErr == nil && kamar.HargaPerBulan > 0 {
 					months := int(payment.JumlahBayar / kamar.HargaPerBulan)
@@ -112,48 +132,88 @@ func (s *paymentService) ConfirmPayment(paymentID uint) error {
 			}
 		}
 
-		// Send Notifications
-		go s.sendSuccessNotifications(payment.ID)
-
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	// Notifikasi setelah commit, supaya client tidak melihat data lama.
+	// Email/WhatsApp hanya diantrikan ke outbox, pengiriman (dan retry) dilakukan worker.
+	s.sendSuccessNotifications(paymentID)
+
+	return nil
 }
 
 func (s *paymentService) RejectPayment(paymentID uint) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
-		txRepo := s.repo.WithTx(tx)
+	if s.db == nil {
+		return s.rejectPayment(nil, paymentID)
+	}
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		return s.rejectPayment(tx, paymentID)
+	}); err != nil {
+		return err
+	}
 
-		payment, err := txRepo.FindByID(paymentID)
-		if err != nil {
-			return err
-		}
+	go s.publishPaymentEvent(utils.EventPaymentRejected, paymentID)
 
-		payment.StatusPembayaran = "Rejected"
-		if err := txRepo.Update(payment); err != nil {
+	return nil
+}
+
+// rejectPayment menolak pembayaran; tx nil berarti tanpa transaksi
+func (s *paymentService) rejectPayment(tx *gorm.DB, paymentID uint) error {
+	repo := s.repo
+	if tx != nil {
+		repo = repo.WithTx(tx)
+	}
+
+	payment, err := repo.FindByID(paymentID)
+	if err != nil {
+		return err
+	}
+	wasRejected := payment.StatusPembayaran == "Rejected"
+
+	payment.StatusPembayaran = "Rejected"
+	if err := repo.Update(payment); err != nil {
+		return err
+	}
+
+	// Voucher yang dipakai perpanjangan ini tidak jadi dipakai, kuotanya dikembalikan
+	if !wasRejected {
+		if err := s.pricing.ReleaseVouchers(tx, payment.PemesananID, &payment.ID); err != nil {
 			return err
 		}
+	}
 
-		// Also update the reminder status to Rejected so frontend reflects it
-		return tx.Model(&models.PaymentReminder{}).
-			Where("pembayaran_id = ?", payment.ID).
-			Update("status_reminder", "Rejected").Error
-	})
+	// Also update the reminder status to Rejected so frontend reflects it
+	return repo.UpdateReminderStatus(payment.ID, "Rejected")
 }
 
 // CreatePaymentSession now only creates a Pending Manual payment
-func (s *paymentService) CreatePaymentSession(pemesananID uint, paymentType string) (*models.Pembayaran, error) {
+func (s *paymentService) CreatePaymentSession(pemesananID uint, paymentType string, voucherCode string) (*models.Pembayaran, error) {
 	booking, err := s.bookingRepo.FindByID(pemesananID)
 	if err != nil {
 		return nil, err
 	}
 
-	kamar, err := s.kamarRepo.FindByID(booking.KamarID)
-	if err != nil {
-		return nil, err
+	// Harga sudah dikunci saat pemesanan pertama kali ditagih
+	var quote *PriceQuote
+	if booking.TotalHarga <= 0 {
+		kamar, err := s.kamarRepo.FindByID(booking.KamarID)
+		if err != nil {
+			return nil, err
+		}
+		quote, err = s.pricing.Quote(kamar, booking.TanggalMulai, booking.DurasiSewa, voucherCode)
+		if err != nil {
+			return nil, err
+		}
+		applyPriceQuote(booking, quote)
+	} else if voucherCode != "" {
+		return nil, fmt.Errorf("%w: harga pemesanan ini sudah dikunci", ErrInvalidVoucher)
 	}
 
 	// Hitung total amount
-	totalAmount := float64(booking.DurasiSewa) * kamar.HargaPerBulan
+	totalAmount := booking.TotalHarga
 	var dpAmount float64
 	var finalAmount float64
 
@@ -175,6 +235,8 @@ func (s *paymentService) CreatePaymentSession(pemesananID uint, paymentType stri
 		MetodePembayaran: "manual", // Forced to manual
 		TipePembayaran:   paymentType,
 		JumlahDP:         dpAmount,
+		Subtotal:         booking.Subtotal,
+		Diskon:           booking.Diskon,
 	}
 
 	// Set jatuh tempo untuk pembayaran cicilan
@@ -183,7 +245,18 @@ func (s *paymentService) CreatePaymentSession(pemesananID uint, paymentType stri
 		payment.TanggalJatuhTempo = booking.TanggalMulai.AddDate(0, 1, 0)
 	}
 
-	if err := s.repo.Create(&payment); err != nil {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if quote != nil {
+			if err := s.bookingRepo.WithTx(tx).Update(booking); err != nil {
+				return err
+			}
+			if err := s.pricing.Redeem(tx, quote, booking.ID, nil); err != nil {
+				return err
+			}
+		}
+		return s.repo.WithTx(tx).Create(&payment)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -217,6 +290,13 @@ func (s *paymentService) UploadPaymentProof(paymentID uint, buktiTransfer string
 		return fmt.Errorf("unauthorized: you can only upload proof for your own payments")
 	}
 
+	// Pembayaran yang ditolak sudah mengembalikan kuota vouchernya, pakai lagi sebelum diproses ulang
+	if payment.StatusPembayaran == "Rejected" {
+		if err := s.pricing.ReclaimVouchers(nil, payment.ID); err != nil {
+			return err
+		}
+	}
+
 	payment.BuktiTransfer = buktiTransfer
 	// Reset status to Pending so admin can process the new proof.
 	// This handles the re-upload case where payment was previously Rejected.
@@ -233,9 +313,39 @@ func (s *paymentService) UploadPaymentProof(paymentID uint, buktiTransfer string
 			Update("status_reminder", "Pending")
 	}
 
+	s.notifier.Publish(utils.NewDomainEvent(utils.EventPaymentProofUploaded, userID, map[string]interface{}{
+		"payment_id": payment.ID,
+		"booking_id": payment.PemesananID,
+		"amount":     payment.JumlahBayar,
+		"status":     payment.StatusPembayaran,
+	}))
+
 	return nil
 }
 
+func (s *paymentService) GetPaymentProof(paymentID uint, userID uint, isAdmin bool) (string, error) {
+	payment, err := s.repo.FindByID(paymentID)
+	if err != nil {
+		return "", err
+	}
+
+	if !isAdmin {
+		booking, err := s.bookingRepo.FindByID(payment.PemesananID)
+		if err != nil {
+			return "", fmt.Errorf("failed to verify payment ownership: %v", err)
+		}
+		penyewa, err := s.penyewaRepo.FindByUserID(userID)
+		if err != nil || booking.PenyewaID != penyewa.ID {
+			return "", ErrPaymentProofForbidden
+		}
+	}
+
+	if payment.BuktiTransfer == "" {
+		return "", ErrPaymentProofNotFound
+	}
+	return payment.BuktiTransfer, nil
+}
+
 func (s *paymentService) ConfirmCashPayment(paymentID uint, buktiTransfer string) error {
 	// Reusing ConfirmPayment logic but allowing to set proof if provided
 	return s.db.Transaction(func(tx *gorm.DB) error {
@@ -302,20 +412,42 @@ func (s *paymentService) CreatePaymentReminder(pembayaranID uint, jumlahBayar fl
 func (s *paymentService) sendSuccessNotifications(paymentID uint) {
 	var payment models.Pembayaran
 	if err := s.db.Preload("Pemesanan.Penyewa").Preload("Pemesanan.Kamar").First(&payment, paymentID).Error; err != nil {
+		utils.GlobalLogger.Error("Failed to load payment %d for notifications: %v", paymentID, err)
 		return
 	}
 
-	tenant := payment.Pemesanan.Penyewa
-
-	// Email
-	if tenant.Email != "" {
-		s.emailSender.SendPaymentSuccessEmail(tenant.Email, tenant.NamaLengkap, payment.JumlahBayar, time.Now())
+	// Email/WhatsApp ke kontak tenant sesuai preferensi, lalu socket + inbox
+	_, err := s.notifier.Dispatch(OutboundMessage{
+		Event:    paymentEvent(utils.EventPaymentConfirmed, &payment),
+		Template: templates.PaymentSuccess,
+		Data: map[string]interface{}{
+			"Amount":     payment.JumlahBayar,
+			"Date":       time.Now(),
+			"RoomNumber": payment.Pemesanan.Kamar.NomorKamar,
+		},
+	})
+	if err != nil {
+		utils.GlobalLogger.Error("Failed to queue notifications for payment %d: %v", paymentID, err)
 	}
+}
 
-	// WhatsApp
-	if tenant.NomorHP != "" {
-		msg := fmt.Sprintf("Terima kasih %s! Pembayaran sebesar Rp %.0f untuk kamar %s telah kami terima.",
-			tenant.NamaLengkap, payment.JumlahBayar, payment.Pemesanan.Kamar.NomorKamar)
-		s.waSender.SendWhatsApp(tenant.NomorHP, msg)
+// publishPaymentEvent memuat pembayaran beserta penyewanya lalu mengirim event
+func (s *paymentService) publishPaymentEvent(eventType string, paymentID uint) {
+	var payment models.Pembayaran
+	if err := s.db.Preload("Pemesanan.Penyewa").Preload("Pemesanan.Kamar").First(&payment, paymentID).Error; err != nil {
+		return
 	}
+	s.notifier.Publish(paymentEvent(eventType, &payment))
+}
+
+func paymentEvent(eventType string, payment *models.Pembayaran) utils.DomainEvent {
+	return utils.NewDomainEvent(eventType, payment.Pemesanan.Penyewa.UserID, map[string]interface{}{
+		"payment_id":   payment.ID,
+		"booking_id":   payment.PemesananID,
+		"amount":       payment.JumlahBayar,
+		"payment_type": payment.TipePembayaran,
+		"status":       payment.StatusPembayaran,
+		"room_number":  payment.Pemesanan.Kamar.NomorKamar,
+		"due_date":     payment.TanggalJatuhTempo,
+	})
 }
@@ -60,9 +60,9 @@ type MockWhatsAppSender struct {
 	mock.Mock
 }
 
-func (m *MockWhatsAppSender) SendWhatsApp(to, message string) error {
+func (m *MockWhatsAppSender) SendWhatsApp(to, message string) (string, error) {
 	args := m.Called(to, message)
-	return args.Error(0)
+	return args.String(0), args.Error(1)
 }
 
 // Test GetAllPayments - Happy Path
@@ -71,8 +71,6 @@ func TestPaymentService_GetAllPayments_Success(t *testing.T) {
 	mockBookingRepo := new(MockBookingRepository)
 	mockKamarRepo := new(MockKamarRepository)
 	mockPenyewaRepo := new(MockPenyewaRepository)
-	mockEmailSender := new(MockEmailSender)
-	mockWASender := new(MockWhatsAppSender)
 
 	service := NewPaymentService(
 		mockRepo,
@@ -80,8 +78,8 @@ func TestPaymentService_GetAllPayments_Success(t *testing.T) {
 		mockKamarRepo,
 		mockPenyewaRepo,
 		nil, // db not needed for this test
-		mockEmailSender,
-		mockWASender,
+		nil,
+		nil,
 	)
 
 	expectedPayments := []models.Pembayaran{
@@ -117,8 +115,6 @@ func TestPaymentService_GetAllPayments_Error(t *testing.T) {
 	mockBookingRepo := new(MockBookingRepository)
 	mockKamarRepo := new(MockKamarRepository)
 	mockPenyewaRepo := new(MockPenyewaRepository)
-	mockEmailSender := new(MockEmailSender)
-	mockWASender := new(MockWhatsAppSender)
 
 	service := NewPaymentService(
 		mockRepo,
@@ -126,8 +122,8 @@ func TestPaymentService_GetAllPayments_Error(t *testing.T) {
 		mockKamarRepo,
 		mockPenyewaRepo,
 		nil,
-		mockEmailSender,
-		mockWASender,
+		nil,
+		nil,
 	)
 
 	mockRepo.On("FindAll").Return(nil, errors.New("database error"))
@@ -146,8 +142,6 @@ func TestPaymentService_GetAllPayments_EmptyResult(t *testing.T) {
 	mockBookingRepo := new(MockBookingRepository)
 	mockKamarRepo := new(MockKamarRepository)
 	mockPenyewaRepo := new(MockPenyewaRepository)
-	mockEmailSender := new(MockEmailSender)
-	mockWASender := new(MockWhatsAppSender)
 
 	service := NewPaymentService(
 		mockRepo,
@@ -155,8 +149,8 @@ func TestPaymentService_GetAllPayments_EmptyResult(t *testing.T) {
 		mockKamarRepo,
 		mockPenyewaRepo,
 		nil,
-		mockEmailSender,
-		mockWASender,
+		nil,
+		nil,
 	)
 
 	emptyPayments := []models.Pembayaran{}
@@ -176,8 +170,6 @@ func TestPaymentService_UploadPaymentProof_Success(t *testing.T) {
 	mockBookingRepo := new(MockBookingRepository)
 	mockKamarRepo := new(MockKamarRepository)
 	mockPenyewaRepo := new(MockPenyewaRepository)
-	mockEmailSender := new(MockEmailSender)
-	mockWASender := new(MockWhatsAppSender)
 
 	service := NewPaymentService(
 		mockRepo,
@@ -185,8 +177,8 @@ func TestPaymentService_UploadPaymentProof_Success(t *testing.T) {
 		mockKamarRepo,
 		mockPenyewaRepo,
 		nil,
-		mockEmailSender,
-		mockWASender,
+		nil,
+		nil,
 	)
 
 	payment := &models.Pembayaran{
@@ -229,8 +221,6 @@ func TestPaymentService_UploadPaymentProof_NotFound(t *testing.T) {
 	mockBookingRepo := new(MockBookingRepository)
 	mockKamarRepo := new(MockKamarRepository)
 	mockPenyewaRepo := new(MockPenyewaRepository)
-	mockEmailSender := new(MockEmailSender)
-	mockWASender := new(MockWhatsAppSender)
 
 	service := NewPaymentService(
 		mockRepo,
@@ -238,8 +228,8 @@ func TestPaymentService_UploadPaymentProof_NotFound(t *testing.T) {
 		mockKamarRepo,
 		mockPenyewaRepo,
 		nil,
-		mockEmailSender,
-		mockWASender,
+		nil,
+		nil,
 	)
 
 	mockRepo.On("FindByID", uint(999)).Return(nil, errors.New("record not found"))
@@ -257,8 +247,6 @@ func TestPaymentService_UploadPaymentProof_UpdateError(t *testing.T) {
 	mockBookingRepo := new(MockBookingRepository)
 	mockKamarRepo := new(MockKamarRepository)
 	mockPenyewaRepo := new(MockPenyewaRepository)
-	mockEmailSender := new(MockEmailSender)
-	mockWASender := new(MockWhatsAppSender)
 
 	service := NewPaymentService(
 		mockRepo,
@@ -266,8 +254,8 @@ func TestPaymentService_UploadPaymentProof_UpdateError(t *testing.T) {
 		mockKamarRepo,
 		mockPenyewaRepo,
 		nil,
-		mockEmailSender,
-		mockWASender,
+		nil,
+		nil,
 	)
 
 	payment := &models.Pembayaran{
@@ -300,6 +288,44 @@ func TestPaymentService_UploadPaymentProof_UpdateError(t *testing.T) {
 	mockPenyewaRepo.AssertExpectations(t)
 }
 
+// Test GetPaymentProof - hanya pemilik pembayaran atau admin
+func TestPaymentService_GetPaymentProof_OwnerAndAdmin(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+	mockPenyewaRepo := new(MockPenyewaRepository)
+
+	service := NewPaymentService(mockRepo, mockBookingRepo, new(MockKamarRepository), mockPenyewaRepo, nil, nil, nil)
+
+	payment := &models.Pembayaran{ID: 1, PemesananID: 1, BuktiTransfer: "/proofs/proof_123.jpg"}
+	mockRepo.On("FindByID", uint(1)).Return(payment, nil)
+	mockBookingRepo.On("FindByID", uint(1)).Return(&models.Pemesanan{ID: 1, PenyewaID: 1}, nil)
+	mockPenyewaRepo.On("FindByUserID", uint(1)).Return(&models.Penyewa{ID: 1, UserID: 1}, nil)
+	mockPenyewaRepo.On("FindByUserID", uint(2)).Return(&models.Penyewa{ID: 2, UserID: 2}, nil)
+
+	ref, err := service.GetPaymentProof(1, 1, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "/proofs/proof_123.jpg", ref)
+
+	_, err = service.GetPaymentProof(1, 2, false)
+	assert.ErrorIs(t, err, ErrPaymentProofForbidden)
+
+	ref, err = service.GetPaymentProof(1, 99, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "/proofs/proof_123.jpg", ref)
+	mockPenyewaRepo.AssertNotCalled(t, "FindByUserID", uint(99))
+}
+
+// Test GetPaymentProof - belum ada bukti
+func TestPaymentService_GetPaymentProof_NotUploaded(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	service := NewPaymentService(mockRepo, new(MockBookingRepository), new(MockKamarRepository), new(MockPenyewaRepository), nil, nil, nil)
+
+	mockRepo.On("FindByID", uint(1)).Return(&models.Pembayaran{ID: 1, PemesananID: 1}, nil)
+
+	_, err := service.GetPaymentProof(1, 1, true)
+	assert.ErrorIs(t, err, ErrPaymentProofNotFound)
+}
+
 func (m *MockPaymentRepository) CreateReminder(reminder *models.PaymentReminder) error {
 	args := m.Called(reminder)
 	return args.Error(0)
@@ -315,6 +341,11 @@ func (m *MockPaymentRepository) DeleteRemindersByBookingID(bookingID uint) error
 	return args.Error(0)
 }
 
+func (m *MockPaymentRepository) UpdateReminderStatus(pembayaranID uint, status string) error {
+	args := m.Called(pembayaranID, status)
+	return args.Error(0)
+}
+
 func (m *MockPaymentRepository) FindByOrderID(orderID string) (*models.Pembayaran, error) {
 	args := m.Called(orderID)
 	if args.Get(0) == nil {
@@ -322,3 +353,49 @@ func (m *MockPaymentRepository) FindByOrderID(orderID string) (*models.Pembayara
 	}
 	return args.Get(0).(*models.Pembayaran), args.Error(1)
 }
+
+func TestPaymentService_RejectPayment_ReleasesExtensionVouchers(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	pricingRepo := new(MockPricingRepository)
+	service := NewPaymentService(mockRepo, new(MockBookingRepository), new(MockKamarRepository), new(MockPenyewaRepository), nil, nil, NewPricingService(pricingRepo, new(MockKamarRepository)))
+
+	paymentID := uint(7)
+	mockRepo.On("FindByID", paymentID).Return(&models.Pembayaran{ID: paymentID, PemesananID: 3, StatusPembayaran: "Pending"}, nil)
+	mockRepo.On("Update", mock.MatchedBy(func(p *models.Pembayaran) bool {
+		return p.ID == paymentID && p.StatusPembayaran == "Rejected"
+	})).Return(nil)
+	pricingRepo.On("FindPaymentVoucherIDs", paymentID).Return([]uint{4}, nil)
+	pricingRepo.On("ReleaseVoucher", uint(4), 1).Return(nil)
+	mockRepo.On("UpdateReminderStatus", paymentID, "Rejected").Return(nil)
+
+	assert.NoError(t, service.RejectPayment(paymentID))
+	mockRepo.AssertExpectations(t)
+	pricingRepo.AssertExpectations(t)
+
+	// Menolak ulang tidak boleh mengembalikan kuota dua kali
+	mockRepo.ExpectedCalls = nil
+	mockRepo.On("FindByID", paymentID).Return(&models.Pembayaran{ID: paymentID, PemesananID: 3, StatusPembayaran: "Rejected"}, nil)
+	mockRepo.On("Update", mock.Anything).Return(nil)
+	mockRepo.On("UpdateReminderStatus", paymentID, "Rejected").Return(nil)
+
+	assert.NoError(t, service.RejectPayment(paymentID))
+	pricingRepo.AssertNumberOfCalls(t, "ReleaseVoucher", 1)
+}
+
+func TestPaymentService_UploadPaymentProof_ReclaimsVouchersOfRejectedPayment(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+	mockPenyewaRepo := new(MockPenyewaRepository)
+	pricingRepo := new(MockPricingRepository)
+	service := NewPaymentService(mockRepo, mockBookingRepo, new(MockKamarRepository), mockPenyewaRepo, nil, nil, NewPricingService(pricingRepo, new(MockKamarRepository)))
+
+	mockRepo.On("FindByID", uint(7)).Return(&models.Pembayaran{ID: 7, PemesananID: 3, StatusPembayaran: "Rejected"}, nil)
+	mockBookingRepo.On("FindByID", uint(3)).Return(&models.Pemesanan{ID: 3, PenyewaID: 1}, nil)
+	mockPenyewaRepo.On("FindByUserID", uint(1)).Return(&models.Penyewa{ID: 1, UserID: 1}, nil)
+	pricingRepo.On("FindPaymentVoucherIDs", uint(7)).Return([]uint{4}, nil)
+	pricingRepo.On("ClaimVoucher", uint(4)).Return(repository.ErrVoucherExhausted)
+
+	err := service.UploadPaymentProof(7, "/proofs/b.jpg", 1)
+	assert.ErrorIs(t, err, ErrInvalidVoucher)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"koskosan-be/internal/templates"
+	"koskosan-be/internal/utils"
+	"strings"
+	"time"
+)
+
+var (
+	ErrPriceChangeNotFound = errors.New("perubahan harga terjadwal tidak ditemukan")
+	ErrInvalidPriceChange  = errors.New("perubahan harga tidak valid")
+	ErrPriceChangeApplied  = errors.New("perubahan harga sudah berlaku dan tidak bisa dibatalkan")
+)
+
+// DefaultRentNoticeDays dipakai jika jumlah hari pemberitahuan tidak dikonfigurasi
+const DefaultRentNoticeDays = 30
+
+// PriceChangeInput untuk menjadwalkan perubahan harga kamar
+type PriceChangeInput struct {
+	HargaPerBulan float64 `json:"harga_per_bulan" binding:"required"`
+	EffectiveFrom string  `json:"effective_from" binding:"required"` // YYYY-MM-DD
+	Note          string  `json:"note"`
+}
+
+// LeasePricer menentukan harga bulanan sewa berjalan untuk tagihan otomatis
+type LeasePricer interface {
+	// LeasePrice: harga kontrak pemesanan, kecuali ada perubahan terjadwal yang sudah
+	// diberitahukan ke penyewa dan berlaku untuk periode yang dimulai pada at
+	LeasePrice(booking *models.Pemesanan, at time.Time) (float64, error)
+}
+
+type PriceHistoryService interface {
+	LeasePricer
+	GetHistory(kamarID uint) ([]models.KamarPriceHistory, error)
+	// ScheduleChange menjadwalkan perubahan harga paling cepat NoticeDays hari dari sekarang
+	ScheduleChange(kamarID uint, input PriceChangeInput) (*models.KamarPriceHistory, error)
+	// CancelChange membatalkan perubahan terjadwal yang belum berlaku
+	CancelChange(kamarID, changeID uint) error
+	NoticeDays() int
+	// SendDueNotices memberi tahu penyewa perubahan yang berlaku dalam NoticeDays hari; mengembalikan jumlah perubahan
+	SendDueNotices() (int, error)
+	// ApplyDueChanges mengganti harga kamar untuk pemesanan baru setelah tanggal berlaku
+	ApplyDueChanges() (int, error)
+}
+
+type priceHistoryService struct {
+	repo       repository.PriceHistoryRepository
+	kamarRepo  repository.KamarRepository
+	notifier   NotificationDispatcher
+	noticeDays int
+}
+
+func NewPriceHistoryService(repo repository.PriceHistoryRepository, kamarRepo repository.KamarRepository, notifier NotificationDispatcher, noticeDays int) PriceHistoryService {
+	if notifier == nil {
+		notifier = noopDispatcher{}
+	}
+	if noticeDays < 0 {
+		noticeDays = DefaultRentNoticeDays
+	}
+	return &priceHistoryService{repo, kamarRepo, notifier, noticeDays}
+}
+
+func (s *priceHistoryService) NoticeDays() int {
+	return s.noticeDays
+}
+
+func (s *priceHistoryService) GetHistory(kamarID uint) ([]models.KamarPriceHistory, error) {
+	if _, err := s.kamarRepo.FindByID(kamarID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByKamarID(kamarID)
+}
+
+func (s *priceHistoryService) ScheduleChange(kamarID uint, input PriceChangeInput) (*models.KamarPriceHistory, error) {
+	kamar, err := s.kamarRepo.FindByID(kamarID)
+	if err != nil {
+		return nil, err
+	}
+	if input.HargaPerBulan <= 0 {
+		return nil, fmt.Errorf("%w: harga_per_bulan harus lebih dari 0", ErrInvalidPriceChange)
+	}
+	if input.HargaPerBulan == kamar.HargaPerBulan {
+		return nil, fmt.Errorf("%w: harga baru sama dengan harga sekarang", ErrInvalidPriceChange)
+	}
+	effective, err := time.Parse("2006-01-02", strings.TrimSpace(input.EffectiveFrom))
+	if err != nil {
+		return nil, fmt.Errorf("%w: effective_from harus berformat YYYY-MM-DD", ErrInvalidPriceChange)
+	}
+	earliest := dateOnly(time.Now()).AddDate(0, 0, s.noticeDays)
+	if effective.Before(earliest) {
+		return nil, fmt.Errorf("%w: penyewa harus diberi tahu %d hari sebelumnya, paling cepat %s",
+			ErrInvalidPriceChange, s.noticeDays, earliest.Format("2006-01-02"))
+	}
+
+	change := &models.KamarPriceHistory{
+		KamarID:       kamar.ID,
+		HargaSebelum:  kamar.HargaPerBulan,
+		HargaPerBulan: input.HargaPerBulan,
+		EffectiveFrom: effective,
+		Source:        models.PriceChangeScheduled,
+		Note:          strings.TrimSpace(input.Note),
+	}
+	if err := s.repo.Create(change); err != nil {
+		return nil, err
+	}
+
+	// Sudah masuk jendela pemberitahuan: kirim sekarang supaya tidak menunggu jadwal harian
+	if !effective.After(earliest) {
+		if err := s.notify(change); err != nil {
+			utils.GlobalLogger.Error("Failed to send rent change notice for change %d: %v", change.ID, err)
+		}
+	}
+	return change, nil
+}
+
+func (s *priceHistoryService) CancelChange(kamarID, changeID uint) error {
+	change, err := s.repo.FindByID(changeID)
+	if err != nil || change.KamarID != kamarID || change.Source != models.PriceChangeScheduled {
+		return ErrPriceChangeNotFound
+	}
+	if change.AppliedAt != nil {
+		return ErrPriceChangeApplied
+	}
+	return s.repo.Delete(change.ID)
+}
+
+func (s *priceHistoryService) LeasePrice(booking *models.Pemesanan, at time.Time) (float64, error) {
+	price, _ := contractPricing{}.LeasePrice(booking, at)
+	changes, err := s.repo.FindNoticedChanges(booking.KamarID, booking.ID, booking.CreatedAt)
+	if err != nil {
+		return price, err
+	}
+	var latest time.Time
+	for _, change := range changes {
+		effective := leaseEffectiveDate(change, s.noticeDays)
+		if effective.After(at) || effective.Before(latest) {
+			continue
+		}
+		latest = effective
+		price = change.HargaPerBulan
+	}
+	return price, nil
+}
+
+func (s *priceHistoryService) SendDueNotices() (int, error) {
+	changes, err := s.repo.FindPendingNotices(dateOnly(time.Now()).AddDate(0, 0, s.noticeDays))
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range changes {
+		if err := s.notify(&changes[i]); err != nil {
+			// NoticeSentAt tetap kosong supaya penyewa yang terlewat dicoba lagi di jadwal berikutnya
+			utils.GlobalLogger.Error("Failed to send rent change notice for change %d: %v", changes[i].ID, err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+func (s *priceHistoryService) ApplyDueChanges() (int, error) {
+	changes, err := s.repo.FindDueChanges(dateOnly(time.Now()))
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for i := range changes {
+		if err := s.repo.ApplyChange(&changes[i], time.Now()); err != nil {
+			utils.GlobalLogger.Error("Failed to apply price change %d: %v", changes[i].ID, err)
+			continue
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+// notify mengirim pemberitahuan ke setiap penyewa kamar yang harganya berubah dan belum diberi tahu.
+// Pemberitahuan dicatat per sewa; NoticeSentAt baru diisi setelah semua penyewa menerimanya.
+func (s *priceHistoryService) notify(change *models.KamarPriceHistory) error {
+	leases, err := s.repo.FindRunningLeases(change.KamarID)
+	if err != nil {
+		return err
+	}
+	notices, err := s.repo.FindLeaseNotices(change.ID)
+	if err != nil {
+		return err
+	}
+	notified := make(map[uint]bool, len(notices))
+	for _, notice := range notices {
+		notified[notice.PemesananID] = true
+	}
+
+	now := time.Now()
+	failed := 0
+	for i := range leases {
+		lease := &leases[i]
+		if notified[lease.ID] {
+			continue
+		}
+		if err := s.notifyLease(change, lease, now); err != nil {
+			utils.GlobalLogger.Error("Failed to send rent change notice %d to booking %d: %v", change.ID, lease.ID, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d penyewa belum menerima pemberitahuan", failed)
+	}
+
+	if err := s.repo.MarkNoticeSent(change.ID, now); err != nil {
+		return err
+	}
+	change.NoticeSentAt = &now
+	return nil
+}
+
+func (s *priceHistoryService) notifyLease(change *models.KamarPriceHistory, lease *models.Pemesanan, now time.Time) error {
+	current, err := s.LeasePrice(lease, change.EffectiveFrom)
+	if err != nil {
+		return err
+	}
+	if current == change.HargaPerBulan {
+		return nil
+	}
+
+	// Tanggal berlaku untuk penyewa tidak boleh kurang dari NoticeDays hari sejak pemberitahuan
+	effective := leaseEffectiveDate(models.KamarPriceHistory{EffectiveFrom: change.EffectiveFrom, NoticeSentAt: &now}, s.noticeDays)
+	if _, err := s.notifier.Dispatch(OutboundMessage{
+		Event: utils.NewDomainEvent(utils.EventRentChangeNotice, lease.Penyewa.UserID, map[string]interface{}{
+			"booking_id":     lease.ID,
+			"room_number":    lease.Kamar.NomorKamar,
+			"old_amount":     current,
+			"new_amount":     change.HargaPerBulan,
+			"effective_from": effective,
+		}),
+		Template: templates.RentChange,
+		Data: map[string]interface{}{
+			"RoomNumber":    lease.Kamar.NomorKamar,
+			"OldAmount":     current,
+			"NewAmount":     change.HargaPerBulan,
+			"EffectiveDate": effective,
+			"Note":          change.Note,
+		},
+	}); err != nil {
+		return err
+	}
+	return s.repo.CreateLeaseNotice(&models.RentChangeNotice{PriceHistoryID: change.ID, PemesananID: lease.ID, SentAt: now})
+}
+
+// leaseEffectiveDate: tanggal perubahan berlaku untuk sewa berjalan, diundur jika pemberitahuannya terlambat.
+// Waktu pemberitahuan diambil dari Notices (per sewa) jika ada, selain itu dari NoticeSentAt.
+func leaseEffectiveDate(change models.KamarPriceHistory, noticeDays int) time.Time {
+	noticedAt := change.NoticeSentAt
+	if len(change.Notices) > 0 {
+		noticedAt = &change.Notices[0].SentAt
+	}
+	if noticedAt == nil {
+		return change.EffectiveFrom
+	}
+	earliest := dateOnly(*noticedAt).AddDate(0, 0, noticeDays)
+	if change.EffectiveFrom.Before(earliest) {
+		return earliest
+	}
+	return change.EffectiveFrom
+}
+
+// dateOnly memotong jam; tanggal dari form (YYYY-MM-DD) di-parse sebagai tengah malam UTC
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// contractPricing dipakai jika reminder dibuat tanpa LeasePricer: selalu harga kontrak
+type contractPricing struct{}
+
+func (contractPricing) LeasePrice(booking *models.Pemesanan, at time.Time) (float64, error) {
+	// Pemesanan lama tanpa snapshot harga memakai harga kamar
+	if booking.HargaPerBulan > 0 {
+		return booking.HargaPerBulan, nil
+	}
+	return booking.Kamar.HargaPerBulan, nil
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"errors"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"koskosan-be/internal/templates"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// MockPriceHistoryRepository implements repository.PriceHistoryRepository
+type MockPriceHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockPriceHistoryRepository) FindByKamarID(kamarID uint) ([]models.KamarPriceHistory, error) {
+	args := m.Called(kamarID)
+	return args.Get(0).([]models.KamarPriceHistory), args.Error(1)
+}
+
+func (m *MockPriceHistoryRepository) FindByID(id uint) (*models.KamarPriceHistory, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.KamarPriceHistory), args.Error(1)
+}
+
+func (m *MockPriceHistoryRepository) Create(entry *models.KamarPriceHistory) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *MockPriceHistoryRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockPriceHistoryRepository) FindPendingNotices(before time.Time) ([]models.KamarPriceHistory, error) {
+	args := m.Called(before)
+	return args.Get(0).([]models.KamarPriceHistory), args.Error(1)
+}
+
+func (m *MockPriceHistoryRepository) MarkNoticeSent(id uint, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
+func (m *MockPriceHistoryRepository) FindLeaseNotices(priceHistoryID uint) ([]models.RentChangeNotice, error) {
+	args := m.Called(priceHistoryID)
+	return args.Get(0).([]models.RentChangeNotice), args.Error(1)
+}
+
+func (m *MockPriceHistoryRepository) CreateLeaseNotice(notice *models.RentChangeNotice) error {
+	args := m.Called(notice)
+	return args.Error(0)
+}
+
+func (m *MockPriceHistoryRepository) FindDueChanges(now time.Time) ([]models.KamarPriceHistory, error) {
+	args := m.Called(now)
+	return args.Get(0).([]models.KamarPriceHistory), args.Error(1)
+}
+
+func (m *MockPriceHistoryRepository) ApplyChange(entry *models.KamarPriceHistory, at time.Time) error {
+	args := m.Called(entry, at)
+	return args.Error(0)
+}
+
+func (m *MockPriceHistoryRepository) FindNoticedChanges(kamarID, pemesananID uint, contractedAt time.Time) ([]models.KamarPriceHistory, error) {
+	args := m.Called(kamarID, pemesananID, contractedAt)
+	return args.Get(0).([]models.KamarPriceHistory), args.Error(1)
+}
+
+func (m *MockPriceHistoryRepository) FindRunningLeases(kamarID uint) ([]models.Pemesanan, error) {
+	args := m.Called(kamarID)
+	return args.Get(0).([]models.Pemesanan), args.Error(1)
+}
+
+func (m *MockPriceHistoryRepository) WithTx(tx *gorm.DB) repository.PriceHistoryRepository {
+	return m
+}
+
+func TestPriceHistoryService_ScheduleChangeRequiresNoticePeriod(t *testing.T) {
+	repo := new(MockPriceHistoryRepository)
+	kamarRepo := new(MockKamarRepository)
+	kamarRepo.On("FindByID", uint(1)).Return(&models.Kamar{ID: 1, HargaPerBulan: 1500000}, nil)
+	s := NewPriceHistoryService(repo, kamarRepo, nil, 30)
+
+	tooSoon := time.Now().AddDate(0, 0, 10).Format("2006-01-02")
+	_, err := s.ScheduleChange(1, PriceChangeInput{HargaPerBulan: 1650000, EffectiveFrom: tooSoon})
+	assert.ErrorIs(t, err, ErrInvalidPriceChange)
+
+	_, err = s.ScheduleChange(1, PriceChangeInput{HargaPerBulan: 1500000, EffectiveFrom: "2099-01-01"})
+	assert.ErrorIs(t, err, ErrInvalidPriceChange)
+
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestPriceHistoryService_ScheduleChangeInsideWindowNotifiesTenants(t *testing.T) {
+	repo := new(MockPriceHistoryRepository)
+	kamarRepo := new(MockKamarRepository)
+	notifier := new(MockNotificationDispatcher)
+	kamarRepo.On("FindByID", uint(1)).Return(&models.Kamar{ID: 1, NomorKamar: "A-01", HargaPerBulan: 1500000}, nil)
+	s := NewPriceHistoryService(repo, kamarRepo, notifier, 30)
+
+	effective := dateOnly(time.Now()).AddDate(0, 0, 30)
+	lease := models.Pemesanan{
+		ID: 7, KamarID: 1, HargaPerBulan: 1400000, CreatedAt: time.Now().AddDate(0, -3, 0),
+		Penyewa: models.Penyewa{UserID: 9}, Kamar: models.Kamar{NomorKamar: "A-01"},
+	}
+	repo.On("Create", mock.MatchedBy(func(c *models.KamarPriceHistory) bool {
+		return c.Source == models.PriceChangeScheduled && c.HargaSebelum == 1500000 && c.EffectiveFrom.Equal(effective)
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.KamarPriceHistory).ID = 4
+	}).Return(nil)
+	repo.On("FindRunningLeases", uint(1)).Return([]models.Pemesanan{lease}, nil)
+	repo.On("FindLeaseNotices", uint(4)).Return([]models.RentChangeNotice{}, nil)
+	repo.On("FindNoticedChanges", uint(1), uint(7), lease.CreatedAt).Return([]models.KamarPriceHistory{}, nil)
+	notifier.On("Dispatch", mock.MatchedBy(func(msg OutboundMessage) bool {
+		return msg.Template == templates.RentChange && msg.Event.UserID == 9 &&
+			msg.Data["OldAmount"] == 1400000.0 && msg.Data["NewAmount"] == 1650000.0
+	})).Return([]string{"email"}, nil)
+	repo.On("CreateLeaseNotice", mock.MatchedBy(func(n *models.RentChangeNotice) bool {
+		return n.PriceHistoryID == 4 && n.PemesananID == 7
+	})).Return(nil)
+	repo.On("MarkNoticeSent", uint(4), mock.Anything).Return(nil)
+
+	change, err := s.ScheduleChange(1, PriceChangeInput{HargaPerBulan: 1650000, EffectiveFrom: effective.Format("2006-01-02")})
+	require.NoError(t, err)
+	assert.NotNil(t, change.NoticeSentAt)
+	notifier.AssertExpectations(t)
+	repo.AssertExpectations(t)
+}
+
+func TestPriceHistoryService_LeasePriceKeepsContractUntilNoticedChange(t *testing.T) {
+	repo := new(MockPriceHistoryRepository)
+	s := NewPriceHistoryService(repo, new(MockKamarRepository), nil, 30)
+
+	contracted := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	noticed := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	booking := &models.Pemesanan{KamarID: 1, HargaPerBulan: 1400000, CreatedAt: contracted, Kamar: models.Kamar{HargaPerBulan: 1800000}}
+	repo.On("FindNoticedChanges", uint(1), uint(0), contracted).Return([]models.KamarPriceHistory{
+		{HargaPerBulan: 1500000, EffectiveFrom: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), NoticeSentAt: &noticed},
+	}, nil)
+
+	// Harga kamar sekarang (1.8jt) tidak dipakai; harga kontrak sampai perubahan berlaku
+	price, err := s.LeasePrice(booking, time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 1400000.0, price)
+
+	price, err = s.LeasePrice(booking, time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 1500000.0, price)
+}
+
+func TestPriceHistoryService_LeasePricePostponesLateNotice(t *testing.T) {
+	repo := new(MockPriceHistoryRepository)
+	s := NewPriceHistoryService(repo, new(MockKamarRepository), nil, 30)
+
+	contracted := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	noticed := time.Date(2026, 3, 20, 8, 0, 0, 0, time.UTC) // hanya 12 hari sebelum 1 April
+	booking := &models.Pemesanan{KamarID: 1, HargaPerBulan: 1400000, CreatedAt: contracted}
+	repo.On("FindNoticedChanges", uint(1), uint(0), contracted).Return([]models.KamarPriceHistory{
+		{HargaPerBulan: 1500000, EffectiveFrom: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), NoticeSentAt: &noticed},
+	}, nil)
+
+	price, err := s.LeasePrice(booking, time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 1400000.0, price)
+
+	price, err = s.LeasePrice(booking, time.Date(2026, 4, 19, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 1500000.0, price)
+}
+
+func TestPriceHistoryService_SendDueNoticesRetriesOnlyMissedLeases(t *testing.T) {
+	repo := new(MockPriceHistoryRepository)
+	notifier := new(MockNotificationDispatcher)
+	s := NewPriceHistoryService(repo, new(MockKamarRepository), notifier, 30)
+
+	contracted := time.Now().AddDate(0, -3, 0)
+	change := models.KamarPriceHistory{ID: 4, KamarID: 1, HargaPerBulan: 1650000, EffectiveFrom: dateOnly(time.Now()).AddDate(0, 0, 30), Source: models.PriceChangeScheduled}
+	leases := []models.Pemesanan{
+		{ID: 7, KamarID: 1, HargaPerBulan: 1400000, CreatedAt: contracted, Penyewa: models.Penyewa{UserID: 9}},
+		{ID: 8, KamarID: 1, HargaPerBulan: 1400000, CreatedAt: contracted, Penyewa: models.Penyewa{UserID: 10}},
+	}
+	repo.On("FindPendingNotices", mock.Anything).Return([]models.KamarPriceHistory{change}, nil)
+	repo.On("FindRunningLeases", uint(1)).Return(leases, nil)
+	repo.On("FindNoticedChanges", uint(1), mock.Anything, contracted).Return([]models.KamarPriceHistory{}, nil)
+	repo.On("FindLeaseNotices", uint(4)).Return([]models.RentChangeNotice{}, nil).Once()
+	notifier.On("Dispatch", mock.MatchedBy(func(msg OutboundMessage) bool { return msg.Event.UserID == 9 })).
+		Return([]string{"email"}, nil).Once()
+	notifier.On("Dispatch", mock.MatchedBy(func(msg OutboundMessage) bool { return msg.Event.UserID == 10 })).
+		Return(nil, errors.New("smtp down")).Once()
+	repo.On("CreateLeaseNotice", mock.MatchedBy(func(n *models.RentChangeNotice) bool { return n.PemesananID == 7 })).Return(nil).Once()
+
+	sent, err := s.SendDueNotices()
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+	repo.AssertNotCalled(t, "MarkNoticeSent", mock.Anything, mock.Anything)
+
+	// Jadwal berikutnya: penyewa 7 sudah diberi tahu, hanya penyewa 8 yang dikirimi
+	repo.On("FindLeaseNotices", uint(4)).Return([]models.RentChangeNotice{{PriceHistoryID: 4, PemesananID: 7, SentAt: time.Now()}}, nil).Once()
+	notifier.On("Dispatch", mock.MatchedBy(func(msg OutboundMessage) bool { return msg.Event.UserID == 10 })).
+		Return([]string{"email"}, nil).Once()
+	repo.On("CreateLeaseNotice", mock.MatchedBy(func(n *models.RentChangeNotice) bool { return n.PemesananID == 8 })).Return(nil).Once()
+	repo.On("MarkNoticeSent", uint(4), mock.Anything).Return(nil)
+
+	sent, err = s.SendDueNotices()
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	notifier.AssertNumberOfCalls(t, "Dispatch", 3)
+	repo.AssertExpectations(t)
+}
+
+func TestPriceHistoryService_LeasePriceUsesLeaseNoticeDate(t *testing.T) {
+	repo := new(MockPriceHistoryRepository)
+	s := NewPriceHistoryService(repo, new(MockKamarRepository), nil, 30)
+
+	contracted := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	onTime := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	allNoticed := time.Date(2026, 3, 20, 8, 0, 0, 0, time.UTC) // penyewa lain baru berhasil diberi tahu
+	booking := &models.Pemesanan{ID: 7, KamarID: 1, HargaPerBulan: 1400000, CreatedAt: contracted}
+	repo.On("FindNoticedChanges", uint(1), uint(7), contracted).Return([]models.KamarPriceHistory{{
+		HargaPerBulan: 1500000, EffectiveFrom: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), NoticeSentAt: &allNoticed,
+		Notices: []models.RentChangeNotice{{PemesananID: 7, SentAt: onTime}},
+	}}, nil)
+
+	price, err := s.LeasePrice(booking, time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 1500000.0, price)
+}
+
+func TestPriceHistoryService_CancelChange(t *testing.T) {
+	repo := new(MockPriceHistoryRepository)
+	s := NewPriceHistoryService(repo, new(MockKamarRepository), nil, 30)
+	applied := time.Now()
+
+	repo.On("FindByID", uint(3)).Return(&models.KamarPriceHistory{ID: 3, KamarID: 1, Source: models.PriceChangeScheduled, AppliedAt: &applied}, nil)
+	repo.On("FindByID", uint(4)).Return(&models.KamarPriceHistory{ID: 4, KamarID: 1, Source: models.PriceChangeManual}, nil)
+	repo.On("FindByID", uint(5)).Return(&models.KamarPriceHistory{ID: 5, KamarID: 1, Source: models.PriceChangeScheduled}, nil)
+	repo.On("Delete", uint(5)).Return(nil)
+
+	assert.ErrorIs(t, s.CancelChange(1, 3), ErrPriceChangeApplied)
+	assert.ErrorIs(t, s.CancelChange(1, 4), ErrPriceChangeNotFound)
+	assert.ErrorIs(t, s.CancelChange(2, 5), ErrPriceChangeNotFound)
+	assert.NoError(t, s.CancelChange(1, 5))
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,434 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPricingRuleNotFound = errors.New("aturan harga tidak ditemukan")
+	ErrInvalidPricingRule  = errors.New("aturan harga tidak valid")
+	ErrInvalidVoucher      = errors.New("voucher tidak berlaku")
+)
+
+// PriceQuote adalah rincian harga sewa setelah diskon
+type PriceQuote struct {
+	HargaPerBulan float64                  `json:"harga_per_bulan"`
+	Months        int                      `json:"months"`
+	Subtotal      float64                  `json:"subtotal"`
+	Diskon        float64                  `json:"diskon"`
+	Total         float64                  `json:"total"`
+	Discounts     []models.BookingDiscount `json:"discounts"`
+}
+
+// PriceCalculator dipakai booking dan payment service untuk menghitung harga sewa
+type PriceCalculator interface {
+	// Quote menghitung harga kamar untuk sewa mulai start selama months bulan
+	Quote(kamar *models.Kamar, start time.Time, months int, voucherCode string) (*PriceQuote, error)
+	// Redeem memakai kuota voucher dan menyimpan snapshot diskon; tx boleh nil
+	Redeem(tx *gorm.DB, quote *PriceQuote, pemesananID uint, pembayaranID *uint) error
+	// ReleaseVouchers mengembalikan kuota voucher milik pemesanan yang dibatalkan,
+	// atau hanya milik pembayaran perpanjangan yang ditolak jika pembayaranID diisi
+	ReleaseVouchers(tx *gorm.DB, pemesananID uint, pembayaranID *uint) error
+	// ReclaimVouchers memakai lagi kuota voucher pembayaran yang ditolak lalu diunggah ulang
+	ReclaimVouchers(tx *gorm.DB, pembayaranID uint) error
+}
+
+type PricingRuleInput struct {
+	Name            string  `json:"name" binding:"required,max=100"`
+	Type            string  `json:"type" binding:"required"`
+	Code            string  `json:"code" binding:"max=50"`
+	DiscountPercent float64 `json:"discount_percent"`
+	DiscountAmount  float64 `json:"discount_amount"`
+	MinMonths       int     `json:"min_months"`
+	StartsAt        string  `json:"starts_at"` // YYYY-MM-DD
+	EndsAt          string  `json:"ends_at"`   // YYYY-MM-DD, termasuk hari itu
+	RoomTypeID      *uint   `json:"room_type_id"`
+	MaxUses         int     `json:"max_uses"`
+	Active          *bool   `json:"active"` // nil = aktif
+}
+
+type PricingService interface {
+	PriceCalculator
+	// QuoteRoom menghitung harga kamar untuk ditampilkan sebelum memesan
+	QuoteRoom(kamarID uint, tanggalMulai string, months int, voucherCode string) (*PriceQuote, error)
+	GetRules() ([]models.PricingRule, error)
+	CreateRule(input PricingRuleInput) (*models.PricingRule, error)
+	UpdateRule(id uint, input PricingRuleInput) (*models.PricingRule, error)
+	DeleteRule(id uint) error
+}
+
+type pricingService struct {
+	repo      repository.PricingRepository
+	kamarRepo repository.KamarRepository
+}
+
+func NewPricingService(repo repository.PricingRepository, kamarRepo repository.KamarRepository) PricingService {
+	return &pricingService{repo, kamarRepo}
+}
+
+func (s *pricingService) QuoteRoom(kamarID uint, tanggalMulai string, months int, voucherCode string) (*PriceQuote, error) {
+	start, err := time.Parse("2006-01-02", tanggalMulai)
+	if err != nil {
+		return nil, fmt.Errorf("tanggal_mulai harus berformat YYYY-MM-DD")
+	}
+	kamar, err := s.kamarRepo.FindByID(kamarID)
+	if err != nil {
+		return nil, err
+	}
+	return s.Quote(kamar, start, months, voucherCode)
+}
+
+func (s *pricingService) Quote(kamar *models.Kamar, start time.Time, months int, voucherCode string) (*PriceQuote, error) {
+	if months <= 0 {
+		return nil, fmt.Errorf("durasi sewa minimal 1 bulan")
+	}
+	rules, err := s.repo.FindActive()
+	if err != nil {
+		return nil, err
+	}
+
+	var voucher *models.PricingRule
+	if code := normalizeVoucherCode(voucherCode); code != "" {
+		voucher, err = s.repo.FindVoucher(code)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: kode %s tidak dikenal", ErrInvalidVoucher, code)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := checkVoucher(voucher, kamar.RoomTypeID, months, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	return EvaluatePricing(rules, voucher, kamar.HargaPerBulan, kamar.RoomTypeID, start, months), nil
+}
+
+func (s *pricingService) Redeem(tx *gorm.DB, quote *PriceQuote, pemesananID uint, pembayaranID *uint) error {
+	if quote == nil || len(quote.Discounts) == 0 {
+		return nil
+	}
+	repo := s.repo
+	if tx != nil {
+		repo = repo.WithTx(tx)
+	}
+
+	discounts := make([]models.BookingDiscount, len(quote.Discounts))
+	for i, d := range quote.Discounts {
+		if d.Type == models.PricingRuleVoucher {
+			if err := repo.ClaimVoucher(d.PricingRuleID); err != nil {
+				if errors.Is(err, repository.ErrVoucherExhausted) {
+					return fmt.Errorf("%w: %v", ErrInvalidVoucher, err)
+				}
+				return err
+			}
+		}
+		d.PemesananID = pemesananID
+		d.PembayaranID = pembayaranID
+		discounts[i] = d
+	}
+	return repo.CreateDiscounts(discounts)
+}
+
+func (s *pricingService) ReleaseVouchers(tx *gorm.DB, pemesananID uint, pembayaranID *uint) error {
+	repo := s.repo
+	if tx != nil {
+		repo = repo.WithTx(tx)
+	}
+
+	var ids []uint
+	var err error
+	if pembayaranID != nil {
+		ids, err = repo.FindPaymentVoucherIDs(*pembayaranID)
+	} else {
+		ids, err = repo.FindBookingVoucherIDs(pemesananID)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Voucher yang sama bisa dipakai pemesanan dan perpanjangannya, kembalikan sebanyak pemakaiannya
+	counts := map[uint]int{}
+	for _, id := range ids {
+		counts[id]++
+	}
+	for id, count := range counts {
+		if err := repo.ReleaseVoucher(id, count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *pricingService) ReclaimVouchers(tx *gorm.DB, pembayaranID uint) error {
+	repo := s.repo
+	if tx != nil {
+		repo = repo.WithTx(tx)
+	}
+
+	ids, err := repo.FindPaymentVoucherIDs(pembayaranID)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := repo.ClaimVoucher(id); err != nil {
+			if errors.Is(err, repository.ErrVoucherExhausted) {
+				return fmt.Errorf("%w: %v", ErrInvalidVoucher, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *pricingService) GetRules() ([]models.PricingRule, error) {
+	return s.repo.FindAll()
+}
+
+func (s *pricingService) CreateRule(input PricingRuleInput) (*models.PricingRule, error) {
+	rule := &models.PricingRule{Active: true}
+	if err := s.applyRuleInput(rule, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *pricingService) UpdateRule(id uint, input PricingRuleInput) (*models.PricingRule, error) {
+	rule, err := s.repo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPricingRuleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyRuleInput(rule, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteRule tidak mengubah diskon yang sudah tercatat di pemesanan
+func (s *pricingService) DeleteRule(id uint) error {
+	if _, err := s.repo.FindByID(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPricingRuleNotFound
+		}
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+func (s *pricingService) applyRuleInput(rule *models.PricingRule, input PricingRuleInput) error {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Type = strings.TrimSpace(input.Type)
+	if input.Name == "" {
+		return fmt.Errorf("%w: nama wajib diisi", ErrInvalidPricingRule)
+	}
+	if (input.DiscountPercent > 0) == (input.DiscountAmount > 0) {
+		return fmt.Errorf("%w: isi salah satu dari discount_percent atau discount_amount", ErrInvalidPricingRule)
+	}
+	if input.DiscountPercent < 0 || input.DiscountPercent > 100 || input.DiscountAmount < 0 {
+		return fmt.Errorf("%w: besar diskon tidak valid", ErrInvalidPricingRule)
+	}
+	if input.MinMonths < 0 || input.MaxUses < 0 {
+		return fmt.Errorf("%w: min_months dan max_uses tidak boleh negatif", ErrInvalidPricingRule)
+	}
+
+	startsAt, err := parseRuleDate(input.StartsAt)
+	if err != nil {
+		return err
+	}
+	endsAt, err := parseRuleDate(input.EndsAt)
+	if err != nil {
+		return err
+	}
+	if startsAt != nil && endsAt != nil && endsAt.Before(*startsAt) {
+		return fmt.Errorf("%w: ends_at harus setelah starts_at", ErrInvalidPricingRule)
+	}
+
+	code := ""
+	switch input.Type {
+	case models.PricingRuleLongStay:
+		if input.MinMonths < 1 {
+			return fmt.Errorf("%w: diskon sewa panjang membutuhkan min_months", ErrInvalidPricingRule)
+		}
+	case models.PricingRulePromotion:
+		if startsAt == nil || endsAt == nil {
+			return fmt.Errorf("%w: promosi membutuhkan starts_at dan ends_at", ErrInvalidPricingRule)
+		}
+	case models.PricingRuleVoucher:
+		code = normalizeVoucherCode(input.Code)
+		if code == "" || strings.ContainsAny(code, " \t") {
+			return fmt.Errorf("%w: kode voucher wajib diisi tanpa spasi", ErrInvalidPricingRule)
+		}
+		existing, err := s.repo.FindVoucher(code)
+		if err == nil && existing.ID != rule.ID {
+			return fmt.Errorf("%w: kode voucher %s sudah dipakai", ErrInvalidPricingRule, code)
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: type harus long_stay, promotion atau voucher", ErrInvalidPricingRule)
+	}
+
+	rule.Name = input.Name
+	rule.Type = input.Type
+	rule.Code = code
+	rule.DiscountPercent = input.DiscountPercent
+	rule.DiscountAmount = input.DiscountAmount
+	rule.MinMonths = input.MinMonths
+	rule.StartsAt = startsAt
+	rule.EndsAt = endsAt
+	rule.RoomTypeID = input.RoomTypeID
+	if rule.RoomTypeID != nil && *rule.RoomTypeID == 0 {
+		rule.RoomTypeID = nil
+	}
+	rule.MaxUses = input.MaxUses
+	if input.Active != nil {
+		rule.Active = *input.Active
+	}
+	return nil
+}
+
+// EvaluatePricing menghitung harga sewa. Dari long_stay dan promotion masing-masing dipakai
+// satu diskon terbesar, ditambah voucher (sudah divalidasi); total diskon tidak melebihi subtotal.
+func EvaluatePricing(rules []models.PricingRule, voucher *models.PricingRule, hargaPerBulan float64, roomTypeID *uint, start time.Time, months int) *PriceQuote {
+	quote := &PriceQuote{
+		HargaPerBulan: hargaPerBulan,
+		Months:        months,
+		Subtotal:      hargaPerBulan * float64(months),
+		Discounts:     []models.BookingDiscount{},
+	}
+
+	best := map[string]*models.PricingRule{}
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Active || !ruleApplies(rule, roomTypeID, months, start) {
+			continue
+		}
+		if rule.Type != models.PricingRuleLongStay && rule.Type != models.PricingRulePromotion {
+			continue
+		}
+		if current, ok := best[rule.Type]; !ok || discountFor(rule, quote.Subtotal) > discountFor(current, quote.Subtotal) {
+			best[rule.Type] = rule
+		}
+	}
+
+	applied := []*models.PricingRule{best[models.PricingRuleLongStay], best[models.PricingRulePromotion], voucher}
+	for _, rule := range applied {
+		if rule == nil {
+			continue
+		}
+		amount := math.Min(discountFor(rule, quote.Subtotal), quote.Subtotal-quote.Diskon)
+		if amount <= 0 {
+			continue
+		}
+		quote.Diskon += amount
+		quote.Discounts = append(quote.Discounts, models.BookingDiscount{
+			PricingRuleID: rule.ID,
+			Name:          rule.Name,
+			Type:          rule.Type,
+			Code:          rule.Code,
+			Amount:        amount,
+		})
+	}
+
+	quote.Total = quote.Subtotal - quote.Diskon
+	return quote
+}
+
+// ruleApplies mengecek tipe kamar, minimal bulan dan periode (untuk promosi: tanggal mulai sewa)
+func ruleApplies(rule *models.PricingRule, roomTypeID *uint, months int, at time.Time) bool {
+	if rule.RoomTypeID != nil && (roomTypeID == nil || *rule.RoomTypeID != *roomTypeID) {
+		return false
+	}
+	if rule.MinMonths > 0 && months < rule.MinMonths {
+		return false
+	}
+	if rule.StartsAt != nil && at.Before(*rule.StartsAt) {
+		return false
+	}
+	// EndsAt berlaku sampai akhir hari tersebut
+	if rule.EndsAt != nil && !at.Before(rule.EndsAt.AddDate(0, 0, 1)) {
+		return false
+	}
+	return true
+}
+
+func checkVoucher(voucher *models.PricingRule, roomTypeID *uint, months int, now time.Time) error {
+	switch {
+	case !voucher.Active:
+		return fmt.Errorf("%w: voucher %s sudah tidak aktif", ErrInvalidVoucher, voucher.Code)
+	case voucher.MaxUses > 0 && voucher.UsedCount >= voucher.MaxUses:
+		return fmt.Errorf("%w: kuota voucher %s sudah habis", ErrInvalidVoucher, voucher.Code)
+	case voucher.MinMonths > 0 && months < voucher.MinMonths:
+		return fmt.Errorf("%w: voucher %s hanya untuk sewa minimal %d bulan", ErrInvalidVoucher, voucher.Code, voucher.MinMonths)
+	case !ruleApplies(voucher, roomTypeID, months, now):
+		return fmt.Errorf("%w: voucher %s tidak berlaku untuk kamar ini atau di luar masa berlaku", ErrInvalidVoucher, voucher.Code)
+	}
+	return nil
+}
+
+// discountFor menghitung potongan aturan terhadap subtotal, dibulatkan ke rupiah
+func discountFor(rule *models.PricingRule, subtotal float64) float64 {
+	if rule.DiscountPercent > 0 {
+		return math.Round(subtotal * rule.DiscountPercent / 100)
+	}
+	return math.Min(rule.DiscountAmount, subtotal)
+}
+
+func normalizeVoucherCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func parseRuleDate(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: tanggal harus berformat YYYY-MM-DD", ErrInvalidPricingRule)
+	}
+	return &t, nil
+}
+
+// flatPricing dipakai jika service dibuat tanpa PriceCalculator: harga penuh tanpa diskon
+type flatPricing struct{}
+
+func (flatPricing) Quote(kamar *models.Kamar, start time.Time, months int, voucherCode string) (*PriceQuote, error) {
+	if normalizeVoucherCode(voucherCode) != "" {
+		return nil, fmt.Errorf("%w: voucher tidak tersedia", ErrInvalidVoucher)
+	}
+	if months <= 0 {
+		return nil, fmt.Errorf("durasi sewa minimal 1 bulan")
+	}
+	return EvaluatePricing(nil, nil, kamar.HargaPerBulan, kamar.RoomTypeID, start, months), nil
+}
+
+func (flatPricing) Redeem(tx *gorm.DB, quote *PriceQuote, pemesananID uint, pembayaranID *uint) error {
+	return nil
+}
+
+func (flatPricing) ReleaseVouchers(tx *gorm.DB, pemesananID uint, pembayaranID *uint) error {
+	return nil
+}
+
+func (flatPricing) ReclaimVouchers(tx *gorm.DB, pembayaranID uint) error {
+	return nil
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// MockPricingRepository implements repository.PricingRepository
+type MockPricingRepository struct {
+	mock.Mock
+}
+
+func (m *MockPricingRepository) FindAll() ([]models.PricingRule, error) {
+	args := m.Called()
+	return args.Get(0).([]models.PricingRule), args.Error(1)
+}
+
+func (m *MockPricingRepository) FindByID(id uint) (*models.PricingRule, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PricingRule), args.Error(1)
+}
+
+func (m *MockPricingRepository) FindActive() ([]models.PricingRule, error) {
+	args := m.Called()
+	return args.Get(0).([]models.PricingRule), args.Error(1)
+}
+
+func (m *MockPricingRepository) FindVoucher(code string) (*models.PricingRule, error) {
+	args := m.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PricingRule), args.Error(1)
+}
+
+func (m *MockPricingRepository) Create(rule *models.PricingRule) error {
+	args := m.Called(rule)
+	return args.Error(0)
+}
+
+func (m *MockPricingRepository) Save(rule *models.PricingRule) error {
+	args := m.Called(rule)
+	return args.Error(0)
+}
+
+func (m *MockPricingRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockPricingRepository) ClaimVoucher(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockPricingRepository) ReleaseVoucher(id uint, count int) error {
+	args := m.Called(id, count)
+	return args.Error(0)
+}
+
+func (m *MockPricingRepository) FindBookingVoucherIDs(pemesananID uint) ([]uint, error) {
+	args := m.Called(pemesananID)
+	return args.Get(0).([]uint), args.Error(1)
+}
+
+func (m *MockPricingRepository) FindPaymentVoucherIDs(pembayaranID uint) ([]uint, error) {
+	args := m.Called(pembayaranID)
+	return args.Get(0).([]uint), args.Error(1)
+}
+
+func (m *MockPricingRepository) CreateDiscounts(discounts []models.BookingDiscount) error {
+	args := m.Called(discounts)
+	return args.Error(0)
+}
+
+func (m *MockPricingRepository) WithTx(tx *gorm.DB) repository.PricingRepository {
+	return m
+}
+
+func pricingDate(value string) *time.Time {
+	t, _ := time.Parse("2006-01-02", value)
+	return &t
+}
+
+func TestEvaluatePricing_LongStayAndPromotion(t *testing.T) {
+	rules := []models.PricingRule{
+		{ID: 1, Name: "Sewa 6 bulan", Type: models.PricingRuleLongStay, DiscountPercent: 5, MinMonths: 6, Active: true},
+		{ID: 2, Name: "Sewa 12 bulan", Type: models.PricingRuleLongStay, DiscountPercent: 10, MinMonths: 12, Active: true},
+		{ID: 3, Name: "Promo Agustus", Type: models.PricingRulePromotion, DiscountAmount: 200000, StartsAt: pricingDate("2026-08-01"), EndsAt: pricingDate("2026-08-31"), Active: true},
+	}
+
+	quote := EvaluatePricing(rules, nil, 1000000, nil, *pricingDate("2026-08-31"), 6)
+
+	assert.Equal(t, 6000000.0, quote.Subtotal)
+	assert.Equal(t, 500000.0, quote.Diskon)
+	assert.Equal(t, 5500000.0, quote.Total)
+	require.Len(t, quote.Discounts, 2)
+	assert.Equal(t, uint(1), quote.Discounts[0].PricingRuleID)
+	assert.Equal(t, uint(3), quote.Discounts[1].PricingRuleID)
+}
+
+func TestEvaluatePricing_SkipsRulesThatDoNotApply(t *testing.T) {
+	deluxe := uint(2)
+	rules := []models.PricingRule{
+		{ID: 1, Type: models.PricingRuleLongStay, DiscountPercent: 5, MinMonths: 6, Active: true},
+		{ID: 2, Type: models.PricingRulePromotion, DiscountPercent: 10, StartsAt: pricingDate("2026-08-01"), EndsAt: pricingDate("2026-08-31"), Active: true},
+		{ID: 3, Type: models.PricingRuleLongStay, DiscountPercent: 5, MinMonths: 1, RoomTypeID: &deluxe, Active: true},
+		{ID: 4, Type: models.PricingRuleLongStay, DiscountPercent: 5, MinMonths: 1, Active: false},
+	}
+
+	quote := EvaluatePricing(rules, nil, 1000000, nil, *pricingDate("2026-09-01"), 3)
+
+	assert.Zero(t, quote.Diskon)
+	assert.Equal(t, 3000000.0, quote.Total)
+	assert.Empty(t, quote.Discounts)
+}
+
+func TestEvaluatePricing_DiscountCappedAtSubtotal(t *testing.T) {
+	voucher := &models.PricingRule{ID: 9, Type: models.PricingRuleVoucher, Code: "GRATIS", DiscountAmount: 5000000}
+	rules := []models.PricingRule{
+		{ID: 1, Type: models.PricingRuleLongStay, DiscountPercent: 50, MinMonths: 1, Active: true},
+	}
+
+	quote := EvaluatePricing(rules, voucher, 1000000, nil, time.Now(), 2)
+
+	assert.Equal(t, 2000000.0, quote.Diskon)
+	assert.Zero(t, quote.Total)
+	require.Len(t, quote.Discounts, 2)
+	assert.Equal(t, 1000000.0, quote.Discounts[1].Amount)
+}
+
+func TestPricingService_QuoteWithVoucher(t *testing.T) {
+	repo := new(MockPricingRepository)
+	s := NewPricingService(repo, new(MockKamarRepository))
+	repo.On("FindActive").Return([]models.PricingRule{}, nil)
+	repo.On("FindVoucher", "HEMAT10").Return(&models.PricingRule{ID: 5, Name: "Hemat", Type: models.PricingRuleVoucher, Code: "HEMAT10", DiscountPercent: 10, MaxUses: 10, UsedCount: 3, Active: true}, nil)
+
+	quote, err := s.Quote(&models.Kamar{HargaPerBulan: 1500000}, time.Now(), 2, " hemat10 ")
+
+	require.NoError(t, err)
+	assert.Equal(t, 300000.0, quote.Diskon)
+	assert.Equal(t, 2700000.0, quote.Total)
+	require.Len(t, quote.Discounts, 1)
+	assert.Equal(t, "HEMAT10", quote.Discounts[0].Code)
+}
+
+func TestPricingService_QuoteRejectsInvalidVoucher(t *testing.T) {
+	single, deluxe := uint(1), uint(2)
+	tests := []struct {
+		name    string
+		voucher *models.PricingRule
+	}{
+		{"inactive", &models.PricingRule{ID: 5, Type: models.PricingRuleVoucher, Code: "X", DiscountPercent: 10}},
+		{"exhausted", &models.PricingRule{ID: 5, Type: models.PricingRuleVoucher, Code: "X", DiscountPercent: 10, MaxUses: 2, UsedCount: 2, Active: true}},
+		{"min months", &models.PricingRule{ID: 5, Type: models.PricingRuleVoucher, Code: "X", DiscountPercent: 10, MinMonths: 6, Active: true}},
+		{"other room type", &models.PricingRule{ID: 5, Type: models.PricingRuleVoucher, Code: "X", DiscountPercent: 10, RoomTypeID: &deluxe, Active: true}},
+		{"expired", &models.PricingRule{ID: 5, Type: models.PricingRuleVoucher, Code: "X", DiscountPercent: 10, EndsAt: pricingDate("2020-01-31"), Active: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockPricingRepository)
+			s := NewPricingService(repo, new(MockKamarRepository))
+			repo.On("FindActive").Return([]models.PricingRule{}, nil)
+			repo.On("FindVoucher", "X").Return(tt.voucher, nil)
+
+			_, err := s.Quote(&models.Kamar{HargaPerBulan: 1000000, RoomTypeID: &single}, time.Now(), 1, "x")
+
+			assert.ErrorIs(t, err, ErrInvalidVoucher)
+		})
+	}
+}
+
+func TestPricingService_QuoteUnknownVoucher(t *testing.T) {
+	repo := new(MockPricingRepository)
+	s := NewPricingService(repo, new(MockKamarRepository))
+	repo.On("FindActive").Return([]models.PricingRule{}, nil)
+	repo.On("FindVoucher", "NOPE").Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := s.Quote(&models.Kamar{HargaPerBulan: 1000000}, time.Now(), 1, "nope")
+
+	assert.ErrorIs(t, err, ErrInvalidVoucher)
+}
+
+func TestPricingService_RedeemClaimsVoucherAndSnapshots(t *testing.T) {
+	repo := new(MockPricingRepository)
+	s := NewPricingService(repo, new(MockKamarRepository))
+	paymentID := uint(11)
+	quote := &PriceQuote{Discounts: []models.BookingDiscount{
+		{PricingRuleID: 1, Type: models.PricingRuleLongStay, Amount: 300000},
+		{PricingRuleID: 5, Type: models.PricingRuleVoucher, Code: "HEMAT10", Amount: 100000},
+	}}
+	repo.On("ClaimVoucher", uint(5)).Return(nil)
+	repo.On("CreateDiscounts", mock.MatchedBy(func(d []models.BookingDiscount) bool {
+		return len(d) == 2 && d[0].PemesananID == 7 && d[1].PembayaranID != nil && *d[1].PembayaranID == 11
+	})).Return(nil)
+
+	err := s.Redeem(nil, quote, 7, &paymentID)
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "ClaimVoucher", uint(1))
+}
+
+func TestPricingService_RedeemVoucherExhausted(t *testing.T) {
+	repo := new(MockPricingRepository)
+	s := NewPricingService(repo, new(MockKamarRepository))
+	quote := &PriceQuote{Discounts: []models.BookingDiscount{{PricingRuleID: 5, Type: models.PricingRuleVoucher, Amount: 100000}}}
+	repo.On("ClaimVoucher", uint(5)).Return(repository.ErrVoucherExhausted)
+
+	err := s.Redeem(nil, quote, 7, nil)
+
+	assert.ErrorIs(t, err, ErrInvalidVoucher)
+	repo.AssertNotCalled(t, "CreateDiscounts", mock.Anything)
+}
+
+func TestPricingService_ReleaseVouchersReturnsEveryUseOfTheBooking(t *testing.T) {
+	repo := new(MockPricingRepository)
+	s := NewPricingService(repo, new(MockKamarRepository))
+	var saved []models.BookingDiscount
+	repo.On("ClaimVoucher", uint(5)).Return(nil)
+	repo.On("CreateDiscounts", mock.Anything).Run(func(args mock.Arguments) {
+		saved = append(saved, args.Get(0).([]models.BookingDiscount)...)
+	}).Return(nil)
+
+	// Voucher yang sama dipakai saat booking lalu lagi saat perpanjangan
+	quote := func() *PriceQuote {
+		return &PriceQuote{Discounts: []models.BookingDiscount{{PricingRuleID: 5, Type: models.PricingRuleVoucher, Amount: 100000}}}
+	}
+	paymentID := uint(11)
+	require.NoError(t, s.Redeem(nil, quote(), 7, nil))
+	require.NoError(t, s.Redeem(nil, quote(), 7, &paymentID))
+	repo.AssertNumberOfCalls(t, "ClaimVoucher", 2)
+
+	var ids []uint
+	for _, d := range saved {
+		ids = append(ids, d.PricingRuleID)
+	}
+	repo.On("FindBookingVoucherIDs", uint(7)).Return(ids, nil)
+	repo.On("ReleaseVoucher", uint(5), 2).Return(nil)
+
+	require.NoError(t, s.ReleaseVouchers(nil, 7, nil))
+	repo.AssertExpectations(t)
+}
+
+func TestPricingService_CreateRuleNormalizesVoucher(t *testing.T) {
+	repo := new(MockPricingRepository)
+	s := NewPricingService(repo, new(MockKamarRepository))
+	repo.On("FindVoucher", "WELCOME").Return(nil, gorm.ErrRecordNotFound)
+	repo.On("Create", mock.MatchedBy(func(r *models.PricingRule) bool {
+		return r.Code == "WELCOME" && r.Active && r.MaxUses == 50
+	})).Return(nil)
+
+	rule, err := s.CreateRule(PricingRuleInput{Name: "Welcome", Type: models.PricingRuleVoucher, Code: " welcome ", DiscountAmount: 100000, MaxUses: 50})
+
+	require.NoError(t, err)
+	assert.Equal(t, "WELCOME", rule.Code)
+	repo.AssertExpectations(t)
+}
+
+func TestPricingService_CreateRuleRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input PricingRuleInput
+	}{
+		{"no discount", PricingRuleInput{Name: "A", Type: models.PricingRuleLongStay, MinMonths: 6}},
+		{"both discounts", PricingRuleInput{Name: "A", Type: models.PricingRuleLongStay, MinMonths: 6, DiscountPercent: 5, DiscountAmount: 1000}},
+		{"percent over 100", PricingRuleInput{Name: "A", Type: models.PricingRuleLongStay, MinMonths: 6, DiscountPercent: 120}},
+		{"long stay without min months", PricingRuleInput{Name: "A", Type: models.PricingRuleLongStay, DiscountPercent: 5}},
+		{"promotion without period", PricingRuleInput{Name: "A", Type: models.PricingRulePromotion, DiscountPercent: 5, StartsAt: "2026-08-01"}},
+		{"period reversed", PricingRuleInput{Name: "A", Type: models.PricingRulePromotion, DiscountPercent: 5, StartsAt: "2026-08-31", EndsAt: "2026-08-01"}},
+		{"voucher without code", PricingRuleInput{Name: "A", Type: models.PricingRuleVoucher, DiscountPercent: 5}},
+		{"unknown type", PricingRuleInput{Name: "A", Type: "flash_sale", DiscountPercent: 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockPricingRepository)
+			s := NewPricingService(repo, new(MockKamarRepository))
+
+			_, err := s.CreateRule(tt.input)
+
+			assert.ErrorIs(t, err, ErrInvalidPricingRule)
+			repo.AssertNotCalled(t, "Create", mock.Anything)
+		})
+	}
+}
+
+func TestPricingService_CreateRuleDuplicateVoucher(t *testing.T) {
+	repo := new(MockPricingRepository)
+	s := NewPricingService(repo, new(MockKamarRepository))
+	repo.On("FindVoucher", "HEMAT").Return(&models.PricingRule{ID: 3, Code: "HEMAT"}, nil)
+
+	_, err := s.CreateRule(PricingRuleInput{Name: "Hemat", Type: models.PricingRuleVoucher, Code: "hemat", DiscountPercent: 5})
+
+	assert.ErrorIs(t, err, ErrInvalidPricingRule)
+}
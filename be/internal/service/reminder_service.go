@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"koskosan-be/internal/models"
 	"koskosan-be/internal/repository"
+	"koskosan-be/internal/templates"
 	"koskosan-be/internal/utils"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -21,19 +23,27 @@ type ReminderService interface {
 type reminderService struct {
 	paymentRepo repository.PaymentRepository
 	db          *gorm.DB
-	emailSender utils.EmailSender
-	waSender    utils.WhatsAppSender
+	notifier    NotificationDispatcher
+	prices      LeasePricer
+	frontendURL string
 }
 
-func NewReminderService(paymentRepo repository.PaymentRepository, db *gorm.DB, emailSender utils.EmailSender, waSender utils.WhatsAppSender) ReminderService {
-	return &reminderService{paymentRepo, db, emailSender, waSender}
+// NewReminderService: frontendURL (cfg.FrontendURL) dipakai untuk link pembayaran di pesan pengingat
+func NewReminderService(paymentRepo repository.PaymentRepository, db *gorm.DB, notifier NotificationDispatcher, prices LeasePricer, frontendURL string) ReminderService {
+	if notifier == nil {
+		notifier = noopDispatcher{}
+	}
+	if prices == nil {
+		prices = contractPricing{}
+	}
+	return &reminderService{paymentRepo, db, notifier, prices, frontendURL}
 }
 
 // CreateMonthlyReminders membuat reminder untuk tagihan sewa bulanan (extend) otomatis
 func (s *reminderService) CreateMonthlyReminders() error {
 	// Ambil semua pemesanan yang berstatus Confirmed beserta Kamar dan Pembayaran-nya
 	var bookings []models.Pemesanan
-	if err := s.db.Preload("Kamar").Preload("Pembayaran").Where("status_pemesanan = ?", "Confirmed").Find(&bookings).Error; err != nil {
+	if err := s.db.Preload("Kamar").Preload("Penyewa").Preload("Pembayaran").Where("status_pemesanan = ?", "Confirmed").Find(&bookings).Error; err != nil {
 		return err
 	}
 
@@ -60,11 +70,6 @@ func (s *reminderService) CreateMonthlyReminders() error {
 			continue
 		}
 
-		// Pastikan kamar memiliki harga yang valid untuk mencegah pembagian dengan 0
-		if b.Kamar.HargaPerBulan <= 0 {
-			continue
-		}
-
 		// Hitung Paid Until Date berdasarkan jumlah bulan yang sudah Confirmed.
 		// Ini lebih akurat daripada membagi total uang dengan harga per bulan,
 		// karena pembayaran DP (30%) tidak akan menghasilkan angka bulan yang bulat.
@@ -74,16 +79,29 @@ func (s *reminderService) CreateMonthlyReminders() error {
 		billingTriggerDate := paidUntil.AddDate(0, 0, -7)
 
 		if now.After(billingTriggerDate) || now.Equal(billingTriggerDate) {
+			// Harga kontrak penyewa, bukan harga kamar saat ini (yang hanya berlaku untuk pemesanan baru)
+			harga, err := s.prices.LeasePrice(&b, paidUntil)
+			if err != nil {
+				fmt.Printf("Warning: Failed to resolve lease price for booking %d: %v\n", b.ID, err)
+				continue
+			}
+			// Pastikan harga valid supaya tidak membuat tagihan 0
+			if harga <= 0 {
+				continue
+			}
+
 			// Buat record Pembayaran baru untuk bulan berikutnya (1 bulan extend)
 			payment := models.Pembayaran{
 				PemesananID:       b.ID,
-				JumlahBayar:       b.Kamar.HargaPerBulan,
+				JumlahBayar:       harga,
 				TanggalBayar:      now,
 				StatusPembayaran:  "Pending",
 				MetodePembayaran:  "manual",
 				TipePembayaran:    "extend",
 				JumlahDP:          0,
 				TanggalJatuhTempo: paidUntil,
+				Subtotal:          harga,
+				JumlahBulan:       1,
 			}
 
 			if err := s.db.Create(&payment).Error; err != nil {
@@ -110,6 +128,15 @@ func (s *reminderService) CreateMonthlyReminders() error {
 			} else {
 				fmt.Printf("Created auto-bill and reminder for booking %d, paid until %s\n", b.ID, paidUntil.Format("2006-01-02"))
 			}
+
+			s.notifier.Publish(utils.NewDomainEvent(utils.EventBillCreated, b.Penyewa.UserID, map[string]interface{}{
+				"payment_id":   payment.ID,
+				"booking_id":   b.ID,
+				"amount":       payment.JumlahBayar,
+				"payment_type": payment.TipePembayaran,
+				"due_date":     payment.TanggalJatuhTempo,
+				"room_number":  b.Kamar.NomorKamar,
+			}))
 		}
 	}
 
@@ -126,47 +153,81 @@ func (s *reminderService) SendPendingReminders() ([]models.PaymentReminder, erro
 		return nil, err
 	}
 
+	sent := make([]models.PaymentReminder, 0, len(reminders))
 	for i := range reminders {
 		// Use Preloaded data to get tenant details
 		var reminder models.PaymentReminder
 
-		if err := s.db.Preload("Pembayaran.Pemesanan.Penyewa").Preload("Pembayaran.Pemesanan.Kamar").First(&reminder, reminders[i].ID).Error; err == nil {
-
-			tenant := reminder.Pembayaran.Pemesanan.Penyewa
-			kamar := reminder.Pembayaran.Pemesanan.Kamar
-
-			// 1. Send Email (Dinonaktifkan sesuai permintaan)
-			// if tenant.Email != "" {
-			// 	frontendURL := os.Getenv("FRONTEND_URL")
-			// 	if frontendURL == "" {
-			// 		frontendURL = "http://localhost:3000"
-			// 	}
-			// 	paymentLink := fmt.Sprintf("%s/dashboard/payments/%d", frontendURL, reminder.PembayaranID)
-			// 	go func(email, name string, amount float64, due time.Time, link string) {
-			// 		s.emailSender.SendPaymentReminderEmail(email, name, amount, due, link)
-			// 	}(tenant.Email, tenant.NamaLengkap, reminder.JumlahBayar, reminder.Pembayaran.TanggalJatuhTempo, paymentLink)
-			// }
-
-			// 2. Send WhatsApp
-			// Nomor hardcode sesuai permintaan
-			phone := "081332314854"
-			dueDateFormated := reminder.Pembayaran.TanggalJatuhTempo.Format("02 Jan 2006")
-			msg := fmt.Sprintf("Halo %s 👋\n\nIni adalah pesan dari sistem Kost.\nMengingatkan bahwa tagihan sewa Kamar %s Bapak/Ibu sebesar *Rp %.0f* akan jatuh tempo pada *%s*.\n\nMohon segera melunasi pembayaran bulan ini melalui website Kost agar sewa kamar tetap aktif.\nTerima kasih!",
-				tenant.NamaLengkap, kamar.NomorKamar, reminder.JumlahBayar, dueDateFormated)
-
-			go func(phone, message string) {
-				s.waSender.SendWhatsApp(phone, message)
-			}(phone, msg)
-
-			fmt.Printf("Sent notifications for Reminder ID %d\n", reminder.ID)
+		if err := s.db.Preload("Pembayaran.Pemesanan.Penyewa").Preload("Pembayaran.Pemesanan.Kamar").First(&reminder, reminders[i].ID).Error; err != nil {
+			fmt.Printf("Warning: Failed to load reminder %d: %v\n", reminders[i].ID, err)
+			continue
+		}
+
+		tenant := reminder.Pembayaran.Pemesanan.Penyewa
+		kamar := reminder.Pembayaran.Pemesanan.Kamar
+
+		paymentLink := fmt.Sprintf("%s/dashboard/payments/%d", s.frontendURL, reminder.PembayaranID)
+		dueDate := reminder.Pembayaran.TanggalJatuhTempo
+		amount := reminder.JumlahBayar
+
+		// Email/WhatsApp ke kontak tenant sendiri sesuai preferensi kategori "bill".
+		// Channel yang sudah diantrikan di percobaan sebelumnya dilewati supaya tidak dobel.
+		delivered := splitChannels(reminders[i].SentChannels)
+		channels, err := s.notifier.Dispatch(OutboundMessage{
+			Event: utils.NewDomainEvent(utils.EventBillReminder, tenant.UserID, map[string]interface{}{
+				"payment_id":  reminder.PembayaranID,
+				"reminder_id": reminder.ID,
+				"amount":      amount,
+				"due_date":    dueDate,
+				"room_number": kamar.NomorKamar,
+			}),
+			Template: templates.PaymentReminder,
+			Data: map[string]interface{}{
+				"Amount":      amount,
+				"DueDate":     dueDate,
+				"RoomNumber":  kamar.NomorKamar,
+				"PaymentLink": paymentLink,
+			},
+			Skip: delivered,
+		})
+		// Event in-app selalu diterbitkan oleh Dispatch, walau channel lain gagal
+		delivered = appendChannels(delivered, append([]string{"in_app"}, channels...)...)
+
+		// Pesan yang sudah tersimpan di outbox dikirim & di-retry oleh worker; yang gagal masuk outbox dicoba lagi
+		// di jadwal berikutnya (is_sent tetap false), hanya untuk channel yang belum tercatat
+		reminders[i].SentChannels = strings.Join(delivered, ",")
+		reminders[i].IsSent = err == nil
+		if saveErr := s.db.Model(&reminders[i]).Select("sent_channels", "is_sent").Updates(&reminders[i]).Error; saveErr != nil {
+			utils.GlobalLogger.Error("Failed to record sent channels for reminder %d: %v", reminder.ID, saveErr)
+			continue
 		}
+		if err != nil {
+			fmt.Printf("Warning: Failed to queue some notifications for Reminder ID %d (queued %v): %v\n", reminder.ID, channels, err)
+			continue
+		}
+
+		fmt.Printf("Queued notifications for Reminder ID %d via %v\n", reminder.ID, delivered)
+		sent = append(sent, reminders[i])
+	}
+
+	return sent, nil
+}
 
-		// Update status
-		reminders[i].IsSent = true
-		s.db.Save(&reminders[i])
+// splitChannels membaca kolom sent_channels ("in_app,email")
+func splitChannels(value string) []string {
+	if value == "" {
+		return nil
 	}
+	return strings.Split(value, ",")
+}
 
-	return reminders, nil
+func appendChannels(channels []string, more ...string) []string {
+	for _, channel := range more {
+		if !skipped(channels, channel) {
+			channels = append(channels, channel)
+		}
+	}
+	return channels
 }
 
 // MarkReminderAsSent tandai reminder sudah dikirim
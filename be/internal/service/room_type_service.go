@@ -0,0 +1,189 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRoomTypeNotFound = errors.New("tipe kamar tidak ditemukan")
+	ErrRoomTypeExists   = errors.New("tipe kamar dengan nama ini sudah ada")
+	ErrRoomTypeInUse    = errors.New("tipe kamar masih dipakai kamar")
+	ErrInvalidRoomType  = errors.New("data tipe kamar tidak valid")
+)
+
+type RoomTypeInput struct {
+	Name          string  `json:"name" binding:"required,max=100"`
+	HargaPerBulan float64 `json:"harga_per_bulan"`
+	Size          string  `json:"size" binding:"max=50"`
+	Capacity      int     `json:"capacity"`
+	Description   string  `json:"description"`
+}
+
+type RoomTypeService interface {
+	GetAll() ([]models.RoomType, error)
+	GetByID(id uint) (*models.RoomType, error)
+	Create(input RoomTypeInput) (*models.RoomType, error)
+	// Update juga memperbarui kamar bertipe ini yang tidak meng-override nilainya
+	Update(id uint, input RoomTypeInput) (*models.RoomType, error)
+	// Delete ditolak selama masih ada kamar bertipe ini; foto tipe yang terhapus dikembalikan supaya file-nya bisa dibersihkan
+	Delete(id uint) ([]models.RoomTypeImage, error)
+	AddImages(id uint, urls []string) (*models.RoomType, error)
+	// DeleteImage menghapus satu foto dan mengembalikannya supaya file-nya bisa dibersihkan
+	DeleteImage(id, imageID uint) (*models.RoomTypeImage, error)
+}
+
+type roomTypeService struct {
+	repo repository.RoomTypeRepository
+}
+
+func NewRoomTypeService(repo repository.RoomTypeRepository) RoomTypeService {
+	return &roomTypeService{repo}
+}
+
+func (s *roomTypeService) GetAll() ([]models.RoomType, error) {
+	return s.repo.FindAll()
+}
+
+func (s *roomTypeService) GetByID(id uint) (*models.RoomType, error) {
+	roomType, err := s.repo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRoomTypeNotFound
+	}
+	return roomType, err
+}
+
+func (s *roomTypeService) Create(input RoomTypeInput) (*models.RoomType, error) {
+	if err := normalizeRoomTypeInput(&input); err != nil {
+		return nil, err
+	}
+	if err := s.ensureUniqueName(input.Name, 0); err != nil {
+		return nil, err
+	}
+
+	roomType := &models.RoomType{
+		Name:          input.Name,
+		HargaPerBulan: input.HargaPerBulan,
+		Size:          input.Size,
+		Capacity:      input.Capacity,
+		Description:   input.Description,
+	}
+	if err := s.repo.Create(roomType); err != nil {
+		return nil, err
+	}
+	return roomType, nil
+}
+
+func (s *roomTypeService) Update(id uint, input RoomTypeInput) (*models.RoomType, error) {
+	if err := normalizeRoomTypeInput(&input); err != nil {
+		return nil, err
+	}
+	roomType, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureUniqueName(input.Name, id); err != nil {
+		return nil, err
+	}
+
+	roomType.Name = input.Name
+	roomType.HargaPerBulan = input.HargaPerBulan
+	roomType.Size = input.Size
+	roomType.Capacity = input.Capacity
+	roomType.Description = input.Description
+	if err := s.repo.Save(roomType); err != nil {
+		return nil, err
+	}
+	return roomType, nil
+}
+
+func (s *roomTypeService) Delete(id uint) ([]models.RoomTypeImage, error) {
+	if _, err := s.GetByID(id); err != nil {
+		return nil, err
+	}
+	count, err := s.repo.CountKamars(id)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("%w (%d kamar); pindahkan kamar ke tipe lain dahulu", ErrRoomTypeInUse, count)
+	}
+	return s.repo.Delete(id)
+}
+
+func (s *roomTypeService) AddImages(id uint, urls []string) (*models.RoomType, error) {
+	if _, err := s.GetByID(id); err != nil {
+		return nil, err
+	}
+	for _, url := range urls {
+		if err := s.repo.AddImage(&models.RoomTypeImage{RoomTypeID: id, ImageURL: url}); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetByID(id)
+}
+
+func (s *roomTypeService) DeleteImage(id, imageID uint) (*models.RoomTypeImage, error) {
+	image, err := s.repo.DeleteImage(id, imageID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRoomTypeNotFound
+	}
+	return image, err
+}
+
+func (s *roomTypeService) ensureUniqueName(name string, exceptID uint) error {
+	existing, err := s.repo.FindByName(name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != exceptID {
+		return ErrRoomTypeExists
+	}
+	return nil
+}
+
+func normalizeRoomTypeInput(input *RoomTypeInput) error {
+	input.Name = strings.Join(strings.Fields(input.Name), " ")
+	input.Size = strings.TrimSpace(input.Size)
+	input.Description = strings.TrimSpace(input.Description)
+
+	switch {
+	case input.Name == "":
+		return fmt.Errorf("%w: nama wajib diisi", ErrInvalidRoomType)
+	case input.HargaPerBulan <= 0:
+		return fmt.Errorf("%w: harga_per_bulan harus lebih dari 0", ErrInvalidRoomType)
+	case input.Capacity < 0:
+		return fmt.Errorf("%w: capacity tidak boleh negatif", ErrInvalidRoomType)
+	}
+	return nil
+}
+
+// ApplyRoomType menyalin nilai bawaan tipe ke kamar untuk field yang tidak di-override,
+// dan memakai foto tipe jika kamar belum punya foto utama
+func ApplyRoomType(kamar *models.Kamar, roomType *models.RoomType) {
+	kamar.RoomTypeID = &roomType.ID
+	kamar.TipeKamar = roomType.Name
+	if !kamar.OverridePrice {
+		kamar.HargaPerBulan = roomType.HargaPerBulan
+	}
+	if !kamar.OverrideSize {
+		kamar.Size = roomType.Size
+	}
+	if !kamar.OverrideCapacity {
+		kamar.Capacity = roomType.Capacity
+	}
+	if !kamar.OverrideDescription {
+		kamar.Description = roomType.Description
+	}
+	if kamar.ImageURL == "" && len(roomType.Images) > 0 {
+		kamar.ImageURL = roomType.Images[0].ImageURL
+	}
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// MockRoomTypeRepository implements repository.RoomTypeRepository
+type MockRoomTypeRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoomTypeRepository) FindAll() ([]models.RoomType, error) {
+	args := m.Called()
+	return args.Get(0).([]models.RoomType), args.Error(1)
+}
+
+func (m *MockRoomTypeRepository) FindByID(id uint) (*models.RoomType, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RoomType), args.Error(1)
+}
+
+func (m *MockRoomTypeRepository) FindByName(name string) (*models.RoomType, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RoomType), args.Error(1)
+}
+
+func (m *MockRoomTypeRepository) Create(roomType *models.RoomType) error {
+	args := m.Called(roomType)
+	return args.Error(0)
+}
+
+func (m *MockRoomTypeRepository) Save(roomType *models.RoomType) error {
+	args := m.Called(roomType)
+	return args.Error(0)
+}
+
+func (m *MockRoomTypeRepository) Delete(id uint) ([]models.RoomTypeImage, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.RoomTypeImage), args.Error(1)
+}
+
+func (m *MockRoomTypeRepository) CountKamars(id uint) (int64, error) {
+	args := m.Called(id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRoomTypeRepository) AddImage(image *models.RoomTypeImage) error {
+	args := m.Called(image)
+	return args.Error(0)
+}
+
+func (m *MockRoomTypeRepository) DeleteImage(roomTypeID, imageID uint) (*models.RoomTypeImage, error) {
+	args := m.Called(roomTypeID, imageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RoomTypeImage), args.Error(1)
+}
+
+func (m *MockRoomTypeRepository) WithTx(tx *gorm.DB) repository.RoomTypeRepository {
+	return m
+}
+
+func TestRoomTypeService_CreateNormalizesInput(t *testing.T) {
+	repo := new(MockRoomTypeRepository)
+	s := NewRoomTypeService(repo)
+	repo.On("FindByName", "Deluxe Plus").Return(nil, gorm.ErrRecordNotFound)
+	repo.On("Create", mock.MatchedBy(func(rt *models.RoomType) bool {
+		return rt.Name == "Deluxe Plus" && rt.HargaPerBulan == 2500000 && rt.Size == "4x5m"
+	})).Return(nil)
+
+	roomType, err := s.Create(RoomTypeInput{Name: " Deluxe   Plus", HargaPerBulan: 2500000, Size: " 4x5m ", Capacity: 2})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Deluxe Plus", roomType.Name)
+	repo.AssertExpectations(t)
+}
+
+func TestRoomTypeService_CreateRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input RoomTypeInput
+	}{
+		{"empty name", RoomTypeInput{Name: "  ", HargaPerBulan: 1000000}},
+		{"zero price", RoomTypeInput{Name: "Single"}},
+		{"negative capacity", RoomTypeInput{Name: "Single", HargaPerBulan: 1000000, Capacity: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockRoomTypeRepository)
+			s := NewRoomTypeService(repo)
+
+			_, err := s.Create(tt.input)
+
+			assert.ErrorIs(t, err, ErrInvalidRoomType)
+			repo.AssertNotCalled(t, "Create", mock.Anything)
+		})
+	}
+}
+
+func TestRoomTypeService_CreateDuplicateName(t *testing.T) {
+	repo := new(MockRoomTypeRepository)
+	s := NewRoomTypeService(repo)
+	repo.On("FindByName", "single").Return(&models.RoomType{ID: 1, Name: "Single"}, nil)
+
+	_, err := s.Create(RoomTypeInput{Name: "single", HargaPerBulan: 1000000})
+
+	assert.ErrorIs(t, err, ErrRoomTypeExists)
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestRoomTypeService_UpdateSavesDefaults(t *testing.T) {
+	repo := new(MockRoomTypeRepository)
+	s := NewRoomTypeService(repo)
+	repo.On("FindByID", uint(1)).Return(&models.RoomType{ID: 1, Name: "Single", HargaPerBulan: 1500000}, nil)
+	repo.On("FindByName", "Single").Return(&models.RoomType{ID: 1, Name: "Single"}, nil)
+	repo.On("Save", mock.MatchedBy(func(rt *models.RoomType) bool {
+		return rt.ID == 1 && rt.HargaPerBulan == 1600000 && rt.Capacity == 1
+	})).Return(nil)
+
+	_, err := s.Update(1, RoomTypeInput{Name: "Single", HargaPerBulan: 1600000, Capacity: 1})
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestRoomTypeService_DeleteInUse(t *testing.T) {
+	repo := new(MockRoomTypeRepository)
+	s := NewRoomTypeService(repo)
+	repo.On("FindByID", uint(1)).Return(&models.RoomType{ID: 1}, nil)
+	repo.On("CountKamars", uint(1)).Return(int64(3), nil)
+
+	_, err := s.Delete(1)
+
+	assert.ErrorIs(t, err, ErrRoomTypeInUse)
+	repo.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+func TestRoomTypeService_DeleteImageNotFound(t *testing.T) {
+	repo := new(MockRoomTypeRepository)
+	s := NewRoomTypeService(repo)
+	repo.On("DeleteImage", uint(1), uint(9)).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := s.DeleteImage(1, 9)
+
+	assert.ErrorIs(t, err, ErrRoomTypeNotFound)
+}
+
+func TestRoomTypeService_DeleteReturnsImagesForCleanup(t *testing.T) {
+	repo := new(MockRoomTypeRepository)
+	s := NewRoomTypeService(repo)
+	images := []models.RoomTypeImage{{ID: 4, RoomTypeID: 1, ImageURL: "/room-types/a.jpg"}}
+	repo.On("FindByID", uint(1)).Return(&models.RoomType{ID: 1, Images: images}, nil)
+	repo.On("CountKamars", uint(1)).Return(int64(0), nil)
+	repo.On("Delete", uint(1)).Return(images, nil)
+
+	deleted, err := s.Delete(1)
+
+	require.NoError(t, err)
+	assert.Equal(t, images, deleted)
+}
+
+func TestApplyRoomType_KeepsOverrides(t *testing.T) {
+	roomType := &models.RoomType{
+		ID: 2, Name: "Deluxe", HargaPerBulan: 2000000, Size: "4x5m", Capacity: 2, Description: "Luas",
+		Images: []models.RoomTypeImage{{ImageURL: "/uploads/room-types/deluxe.jpg"}},
+	}
+	kamar := &models.Kamar{TipeKamar: "Lama", HargaPerBulan: 2200000, OverridePrice: true, Size: "3x3m"}
+
+	ApplyRoomType(kamar, roomType)
+
+	require.NotNil(t, kamar.RoomTypeID)
+	assert.Equal(t, uint(2), *kamar.RoomTypeID)
+	assert.Equal(t, "Deluxe", kamar.TipeKamar)
+	assert.Equal(t, 2200000.0, kamar.HargaPerBulan)
+	assert.Equal(t, "4x5m", kamar.Size)
+	assert.Equal(t, 2, kamar.Capacity)
+	assert.Equal(t, "Luas", kamar.Description)
+	assert.Equal(t, "/uploads/room-types/deluxe.jpg", kamar.ImageURL)
+}
+
+func TestKamarService_AssignRoomTypeNotFound(t *testing.T) {
+	roomTypeRepo := new(MockRoomTypeRepository)
+	s := NewKamarService(new(MockKamarRepository), new(MockFacilityRepository), roomTypeRepo)
+	roomTypeRepo.On("FindByID", uint(5)).Return(nil, gorm.ErrRecordNotFound)
+
+	err := s.AssignRoomType(&models.Kamar{}, 5)
+
+	assert.ErrorIs(t, err, ErrRoomTypeNotFound)
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"koskosan-be/internal/utils"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Form publik yang dilindungi SpamGuard
+const (
+	SpamFormContact        = "contact"
+	SpamFormRegister       = "register"
+	SpamFormForgotPassword = "forgot_password"
+)
+
+// Alasan penolakan yang dicatat di log
+const (
+	SpamReasonHoneypot     = "honeypot"
+	SpamReasonTooFast      = "too_fast"
+	SpamReasonTooManyLinks = "too_many_links"
+	SpamReasonBlockedWord  = "blocked_word"
+	SpamReasonChallenge    = "challenge_failed"
+)
+
+// Aturan bawaan sebelum admin menyimpan pengaturan sendiri
+const (
+	DefaultSpamMinSubmitSeconds = 3
+	DefaultSpamMaxLinks         = 2
+	spamSettingsTTL             = time.Minute
+	// spamClockSkew: toleransi selisih jam antar instance server yang menerbitkan form token
+	spamClockSkew = time.Minute
+	// spamFormTokenMaxAge membatasi pemakaian ulang satu form token oleh bot
+	spamFormTokenMaxAge = 24 * time.Hour
+	spamContentExcerpt  = 500
+)
+
+var DefaultSpamBlockedWords = []string{"casino", "viagra", "judi online", "slot gacor", "crypto giveaway"}
+
+var (
+	ErrSpamRejected        = errors.New("kiriman ditolak")
+	ErrInvalidSpamSettings = errors.New("pengaturan anti-spam tidak valid")
+)
+
+var spamLinkPattern = regexp.MustCompile(`(?i)https?://|www\.|\[url=`)
+
+// SpamRejectedError membawa alasan penolakan; errors.Is(err, ErrSpamRejected) bernilai true
+type SpamRejectedError struct {
+	Reason string
+	Detail string
+}
+
+func (e *SpamRejectedError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrSpamRejected.Error(), e.Reason)
+}
+
+func (e *SpamRejectedError) Is(target error) bool {
+	return target == ErrSpamRejected
+}
+
+// FormSubmission adalah metadata dan isi teks satu kiriman form publik
+type FormSubmission struct {
+	Form           string
+	IPAddress      string
+	UserAgent      string
+	Honeypot       string // field tersembunyi; manusia tidak mengisinya
+	ChallengeToken string // token captcha dari widget hCaptcha/Turnstile
+	FormToken      string // token bertanda tangan dari IssueFormToken saat form ditampilkan; kosong = tidak dikirim
+	Email          string
+	Content        []string // field teks bebas yang diperiksa link dan kata terlarang (tanpa password)
+}
+
+type SpamSettingsInput struct {
+	MinSubmitSeconds int    `json:"min_submit_seconds" binding:"min=0,max=60"`
+	MaxLinks         int    `json:"max_links" binding:"min=0,max=20"`
+	BlockedWords     string `json:"blocked_words"` // satu per baris
+}
+
+type SpamGuard interface {
+	// IssueFormToken menerbitkan token bertanda tangan berisi waktu form ditampilkan
+	IssueFormToken() string
+	// Check mengembalikan *SpamRejectedError jika kiriman ditolak; penolakan dicatat ke log
+	Check(sub FormSubmission) error
+
+	// Admin
+	GetSettings() (*models.SpamSettings, error)
+	UpdateSettings(adminID uint, input SpamSettingsInput) (*models.SpamSettings, error)
+	GetRejections(form, reason string, pagination *utils.Pagination) ([]models.SpamRejection, int64, error)
+	GetRejectionStats(days int) ([]repository.SpamRejectionCount, error)
+}
+
+type spamGuard struct {
+	repo     repository.SpamRepository
+	verifier utils.ChallengeVerifier
+	secret   string
+	now      func() time.Time
+
+	mu        sync.Mutex
+	cached    *models.SpamSettings
+	cachedAt  time.Time
+	cachedTTL time.Duration
+}
+
+// NewSpamGuard: secret menandatangani form token (JWT_SECRET, seperti token CSRF)
+func NewSpamGuard(repo repository.SpamRepository, verifier utils.ChallengeVerifier, secret string) SpamGuard {
+	if verifier == nil {
+		verifier = utils.NoopChallengeVerifier{}
+	}
+	return &spamGuard{repo: repo, verifier: verifier, secret: secret, now: time.Now, cachedTTL: spamSettingsTTL}
+}
+
+func (g *spamGuard) IssueFormToken() string {
+	return utils.GenerateFormToken(g.now(), g.secret)
+}
+
+// Check menjalankan aturan murah lebih dulu; captcha (request ke provider) terakhir
+func (g *spamGuard) Check(sub FormSubmission) error {
+	settings := g.settings()
+
+	if err := g.evaluate(sub, settings); err != nil {
+		var rejected *SpamRejectedError
+		if errors.As(err, &rejected) {
+			g.logRejection(sub, rejected)
+		}
+		return err
+	}
+	return nil
+}
+
+func (g *spamGuard) evaluate(sub FormSubmission, settings *models.SpamSettings) error {
+	if strings.TrimSpace(sub.Honeypot) != "" {
+		return &SpamRejectedError{Reason: SpamReasonHoneypot, Detail: "honeypot field terisi"}
+	}
+
+	if settings.MinSubmitSeconds > 0 {
+		if err := g.checkFormToken(sub.FormToken, settings.MinSubmitSeconds); err != nil {
+			return err
+		}
+	}
+
+	content := strings.Join(sub.Content, "\n")
+	if links := len(spamLinkPattern.FindAllStringIndex(content, -1)); links > settings.MaxLinks {
+		return &SpamRejectedError{Reason: SpamReasonTooManyLinks, Detail: fmt.Sprintf("%d link (maks. %d)", links, settings.MaxLinks)}
+	}
+
+	lower := strings.ToLower(content)
+	for _, word := range splitBlockedWords(settings.BlockedWords) {
+		if strings.Contains(lower, word) {
+			return &SpamRejectedError{Reason: SpamReasonBlockedWord, Detail: word}
+		}
+	}
+
+	if err := g.verifier.Verify(sub.ChallengeToken, sub.IPAddress); err != nil {
+		if errors.Is(err, utils.ErrChallengeFailed) {
+			return &SpamRejectedError{Reason: SpamReasonChallenge, Detail: err.Error()}
+		}
+		// Provider captcha tidak bisa dihubungi: jangan blokir semua pengunjung, aturan lain sudah lolos
+		utils.GlobalLogger.Error("Captcha verification unavailable for %s form: %v", sub.Form, err)
+	}
+	return nil
+}
+
+// checkFormToken memastikan form token ditandatangani server dan form diisi minimal minSeconds detik.
+// Nilai mentah token hanya dipakai untuk detail log penolakan.
+func (g *spamGuard) checkFormToken(token string, minSeconds int) error {
+	if token == "" {
+		return &SpamRejectedError{Reason: SpamReasonTooFast, Detail: "form_token tidak dikirim"}
+	}
+	issuedAt, err := utils.ParseFormToken(token, g.secret)
+	if err != nil {
+		return &SpamRejectedError{Reason: SpamReasonTooFast, Detail: fmt.Sprintf("form_token tidak valid: %.100q", token)}
+	}
+
+	now := g.now()
+	elapsed := now.Sub(issuedAt)
+	switch {
+	case issuedAt.After(now.Add(spamClockSkew)):
+		return &SpamRejectedError{Reason: SpamReasonTooFast, Detail: "form_token di masa depan"}
+	case elapsed > spamFormTokenMaxAge:
+		return &SpamRejectedError{Reason: SpamReasonTooFast, Detail: fmt.Sprintf("form_token kedaluwarsa (%s)", elapsed.Round(time.Minute))}
+	case elapsed < time.Duration(minSeconds)*time.Second:
+		return &SpamRejectedError{Reason: SpamReasonTooFast, Detail: fmt.Sprintf("dikirim setelah %.1f detik", elapsed.Seconds())}
+	}
+	return nil
+}
+
+func (g *spamGuard) logRejection(sub FormSubmission, rejected *SpamRejectedError) {
+	content := []rune(strings.Join(sub.Content, "\n"))
+	if len(content) > spamContentExcerpt {
+		content = content[:spamContentExcerpt]
+	}
+	userAgent := sub.UserAgent
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	err := g.repo.CreateRejection(&models.SpamRejection{
+		Form:      sub.Form,
+		Reason:    rejected.Reason,
+		Detail:    rejected.Detail,
+		IPAddress: sub.IPAddress,
+		UserAgent: userAgent,
+		Email:     sub.Email,
+		Content:   string(content),
+	})
+	if err != nil {
+		utils.GlobalLogger.Error("Failed to log spam rejection for %s form: %v", sub.Form, err)
+	}
+	utils.GlobalLogger.Info("Rejected %s form submission from %s: %s (%s)", sub.Form, sub.IPAddress, rejected.Reason, rejected.Detail)
+}
+
+// settings memakai cache singkat supaya setiap kiriman form tidak membaca database
+func (g *spamGuard) settings() *models.SpamSettings {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.cached != nil && g.now().Sub(g.cachedAt) < g.cachedTTL {
+		return g.cached
+	}
+
+	settings, err := g.repo.FindSettings()
+	if err != nil {
+		utils.GlobalLogger.Error("Failed to load spam settings, using defaults: %v", err)
+		settings = &models.SpamSettings{}
+	}
+	if settings.ID == 0 {
+		settings = defaultSpamSettings()
+	}
+	g.cached = settings
+	g.cachedAt = g.now()
+	return settings
+}
+
+func (g *spamGuard) GetSettings() (*models.SpamSettings, error) {
+	settings, err := g.repo.FindSettings()
+	if err != nil {
+		return nil, err
+	}
+	if settings.ID == 0 {
+		return defaultSpamSettings(), nil
+	}
+	return settings, nil
+}
+
+func (g *spamGuard) UpdateSettings(adminID uint, input SpamSettingsInput) (*models.SpamSettings, error) {
+	if input.MinSubmitSeconds < 0 || input.MinSubmitSeconds > 60 || input.MaxLinks < 0 || input.MaxLinks > 20 {
+		return nil, fmt.Errorf("%w: waktu minimal 0-60 detik, link maksimal 0-20", ErrInvalidSpamSettings)
+	}
+
+	settings, err := g.repo.FindSettings()
+	if err != nil {
+		return nil, err
+	}
+	settings.MinSubmitSeconds = input.MinSubmitSeconds
+	settings.MaxLinks = input.MaxLinks
+	settings.BlockedWords = strings.Join(splitBlockedWords(input.BlockedWords), "\n")
+	settings.UpdatedBy = adminID
+
+	if err := g.repo.SaveSettings(settings); err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	g.cached = settings
+	g.cachedAt = g.now()
+	g.mu.Unlock()
+
+	return settings, nil
+}
+
+func (g *spamGuard) GetRejections(form, reason string, pagination *utils.Pagination) ([]models.SpamRejection, int64, error) {
+	return g.repo.FindRejections(form, reason, pagination)
+}
+
+func (g *spamGuard) GetRejectionStats(days int) ([]repository.SpamRejectionCount, error) {
+	if days <= 0 {
+		days = 30
+	}
+	return g.repo.CountRejectionsSince(g.now().AddDate(0, 0, -days))
+}
+
+func defaultSpamSettings() *models.SpamSettings {
+	return &models.SpamSettings{
+		MinSubmitSeconds: DefaultSpamMinSubmitSeconds,
+		MaxLinks:         DefaultSpamMaxLinks,
+		BlockedWords:     strings.Join(DefaultSpamBlockedWords, "\n"),
+	}
+}
+
+// splitBlockedWords: satu kata/frasa per baris (koma juga diterima), huruf kecil, tanpa duplikat
+func splitBlockedWords(value string) []string {
+	seen := map[string]bool{}
+	var words []string
+	for _, word := range strings.FieldsFunc(value, func(r rune) bool { return r == '\n' || r == ',' }) {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word == "" || seen[word] {
+			continue
+		}
+		seen[word] = true
+		words = append(words, word)
+	}
+	return words
+}
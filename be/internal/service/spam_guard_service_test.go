@@ -0,0 +1,206 @@
+package service
+
+import (
+	"errors"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"koskosan-be/internal/utils"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type MockSpamRepository struct {
+	mock.Mock
+}
+
+func (m *MockSpamRepository) FindSettings() (*models.SpamSettings, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SpamSettings), args.Error(1)
+}
+
+func (m *MockSpamRepository) SaveSettings(settings *models.SpamSettings) error {
+	args := m.Called(settings)
+	return args.Error(0)
+}
+
+func (m *MockSpamRepository) CreateRejection(rejection *models.SpamRejection) error {
+	args := m.Called(rejection)
+	return args.Error(0)
+}
+
+func (m *MockSpamRepository) FindRejections(form, reason string, pagination *utils.Pagination) ([]models.SpamRejection, int64, error) {
+	args := m.Called(form, reason, pagination)
+	return args.Get(0).([]models.SpamRejection), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockSpamRepository) CountRejectionsSince(since time.Time) ([]repository.SpamRejectionCount, error) {
+	args := m.Called(since)
+	return args.Get(0).([]repository.SpamRejectionCount), args.Error(1)
+}
+
+func (m *MockSpamRepository) WithTx(tx *gorm.DB) repository.SpamRepository {
+	return m
+}
+
+// unavailableVerifier mensimulasikan provider captcha yang tidak bisa dihubungi
+type unavailableVerifier struct{}
+
+func (unavailableVerifier) Verify(token, remoteIP string) error {
+	return errors.New("dial tcp: timeout")
+}
+
+var spamTestNow = time.Date(2026, 10, 19, 9, 0, 0, 0, time.Local)
+
+const spamTestSecret = "spam-test-secret"
+
+func newTestSpamGuard(settings *models.SpamSettings, verifier utils.ChallengeVerifier) (*spamGuard, *MockSpamRepository) {
+	repo := new(MockSpamRepository)
+	repo.On("FindSettings").Return(settings, nil)
+	g := NewSpamGuard(repo, verifier, spamTestSecret).(*spamGuard)
+	g.now = func() time.Time { return spamTestNow }
+	return g, repo
+}
+
+// validSubmission lolos semua aturan bawaan dengan FakeChallengeVerifier
+func validSubmission() FormSubmission {
+	return FormSubmission{
+		Form:           SpamFormContact,
+		IPAddress:      "10.0.0.1",
+		ChallengeToken: utils.FakeChallengePassToken,
+		FormToken:      utils.GenerateFormToken(spamTestNow.Add(-20*time.Second), spamTestSecret),
+		Email:          "siti@example.com",
+		Content:        []string{"Siti", "Halo, apakah masih ada kamar? Lihat https://example.com"},
+	}
+}
+
+func assertRejected(t *testing.T, err error, reason string) {
+	t.Helper()
+	require.ErrorIs(t, err, ErrSpamRejected)
+	var rejected *SpamRejectedError
+	require.True(t, errors.As(err, &rejected))
+	assert.Equal(t, reason, rejected.Reason)
+}
+
+func TestSpamGuard_AcceptsNormalSubmission(t *testing.T) {
+	g, repo := newTestSpamGuard(&models.SpamSettings{}, utils.FakeChallengeVerifier{})
+
+	err := g.Check(validSubmission())
+
+	assert.NoError(t, err)
+	repo.AssertNotCalled(t, "CreateRejection", mock.Anything)
+}
+
+func TestSpamGuard_Rules(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(sub *FormSubmission)
+		reason string
+	}{
+		{"honeypot", func(sub *FormSubmission) { sub.Honeypot = "http://spam.example" }, SpamReasonHoneypot},
+		{"missing token", func(sub *FormSubmission) { sub.FormToken = "" }, SpamReasonTooFast},
+		{"too fast", func(sub *FormSubmission) {
+			sub.FormToken = utils.GenerateFormToken(spamTestNow.Add(-time.Second), spamTestSecret)
+		}, SpamReasonTooFast},
+		{"forged start time", func(sub *FormSubmission) {
+			// Script yang mengarang waktu mulai sendiri tanpa tanda tangan server
+			sub.FormToken = strconv.FormatInt(spamTestNow.Add(-time.Minute).UnixMilli(), 10)
+		}, SpamReasonTooFast},
+		{"signed with another secret", func(sub *FormSubmission) {
+			sub.FormToken = utils.GenerateFormToken(spamTestNow.Add(-time.Minute), "other-secret")
+		}, SpamReasonTooFast},
+		{"expired token", func(sub *FormSubmission) {
+			sub.FormToken = utils.GenerateFormToken(spamTestNow.Add(-48*time.Hour), spamTestSecret)
+		}, SpamReasonTooFast},
+		{"too many links", func(sub *FormSubmission) {
+			sub.Content = []string{"http://a.example www.b.example [url=c]"}
+		}, SpamReasonTooManyLinks},
+		{"blocked word", func(sub *FormSubmission) { sub.Content = []string{"Promo SLOT GACOR hari ini"} }, SpamReasonBlockedWord},
+		{"challenge", func(sub *FormSubmission) { sub.ChallengeToken = "wrong" }, SpamReasonChallenge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, repo := newTestSpamGuard(&models.SpamSettings{}, utils.FakeChallengeVerifier{})
+			repo.On("CreateRejection", mock.MatchedBy(func(r *models.SpamRejection) bool {
+				return r.Form == SpamFormContact && r.Reason == tt.reason && r.IPAddress == "10.0.0.1"
+			})).Return(nil)
+
+			sub := validSubmission()
+			tt.modify(&sub)
+
+			assertRejected(t, g.Check(sub), tt.reason)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSpamGuard_UsesAdminSettings(t *testing.T) {
+	g, repo := newTestSpamGuard(&models.SpamSettings{ID: 1, MinSubmitSeconds: 0, MaxLinks: 0, BlockedWords: "kost murah"}, utils.NoopChallengeVerifier{})
+	repo.On("CreateRejection", mock.Anything).Return(nil)
+
+	// Waktu isi dinonaktifkan, kata bawaan tidak dipakai lagi
+	sub := validSubmission()
+	sub.FormToken = ""
+	sub.Content = []string{"casino"}
+	assert.NoError(t, g.Check(sub))
+
+	sub.Content = []string{"Info Kost Murah"}
+	assertRejected(t, g.Check(sub), SpamReasonBlockedWord)
+
+	sub.Content = []string{"lihat https://example.com"}
+	assertRejected(t, g.Check(sub), SpamReasonTooManyLinks)
+}
+
+// Provider captcha down tidak boleh memblokir pengunjung yang lolos aturan lain
+func TestSpamGuard_ChallengeProviderUnavailable(t *testing.T) {
+	g, repo := newTestSpamGuard(&models.SpamSettings{}, unavailableVerifier{})
+
+	assert.NoError(t, g.Check(validSubmission()))
+	repo.AssertNotCalled(t, "CreateRejection", mock.Anything)
+}
+
+func TestSpamGuard_CachesSettings(t *testing.T) {
+	g, repo := newTestSpamGuard(&models.SpamSettings{}, utils.FakeChallengeVerifier{})
+
+	require.NoError(t, g.Check(validSubmission()))
+	require.NoError(t, g.Check(validSubmission()))
+
+	repo.AssertNumberOfCalls(t, "FindSettings", 1)
+}
+
+func TestSpamGuard_UpdateSettingsNormalizesWords(t *testing.T) {
+	g, repo := newTestSpamGuard(&models.SpamSettings{}, utils.FakeChallengeVerifier{})
+	repo.On("SaveSettings", mock.MatchedBy(func(s *models.SpamSettings) bool {
+		return s.BlockedWords == "casino\nslot gacor" && s.UpdatedBy == 1 && s.MaxLinks == 1
+	})).Return(nil)
+
+	settings, err := g.UpdateSettings(1, SpamSettingsInput{MinSubmitSeconds: 5, MaxLinks: 1, BlockedWords: " Casino \n\nslot gacor, casino"})
+
+	require.NoError(t, err)
+	assert.Equal(t, 5, settings.MinSubmitSeconds)
+	repo.AssertExpectations(t)
+
+	// Cache langsung memakai pengaturan baru
+	sub := validSubmission()
+	sub.Content = []string{"a http://x.example b http://y.example"}
+	repo.On("CreateRejection", mock.Anything).Return(nil)
+	assertRejected(t, g.Check(sub), SpamReasonTooManyLinks)
+}
+
+func TestSpamGuard_UpdateSettingsValidates(t *testing.T) {
+	g, repo := newTestSpamGuard(&models.SpamSettings{}, utils.FakeChallengeVerifier{})
+
+	_, err := g.UpdateSettings(1, SpamSettingsInput{MinSubmitSeconds: 120})
+
+	assert.ErrorIs(t, err, ErrInvalidSpamSettings)
+	repo.AssertNotCalled(t, "SaveSettings", mock.Anything)
+}
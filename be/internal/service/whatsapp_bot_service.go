@@ -0,0 +1,242 @@
+package service
+
+import (
+	"errors"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"koskosan-be/internal/templates"
+	"koskosan-be/internal/utils"
+	"koskosan-be/internal/validators"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Perintah bot, huruf besar/kecil bebas. Alias bahasa Inggris dibalas dengan bahasa preferensi tenant.
+var botCommands = map[string]string{
+	"TAGIHAN": templates.BotBills,
+	"BILL":    templates.BotBills,
+	"STATUS":  templates.BotStatus,
+	"BAYAR":   templates.BotPay,
+	"PAY":     templates.BotPay,
+}
+
+// Hasil pemrosesan gambar bukti transfer (field Result di template bot_proof)
+const (
+	botProofReceived  = "received"
+	botProofNoPayment = "no_payment"
+	botProofFailed    = "failed"
+)
+
+// WhatsAppBotService menjawab pesan WhatsApp masuk dari tenant: cek tagihan, status sewa,
+// dan menerima foto bukti transfer untuk pembayaran yang masih menunggu bukti.
+type WhatsAppBotService interface {
+	HandleMessage(msg utils.InboundWhatsApp) error
+}
+
+type whatsAppBotService struct {
+	penyewaRepo    repository.PenyewaRepository
+	prefRepo       repository.NotificationPreferenceRepository
+	inboundRepo    repository.InboundMessageRepository
+	paymentService PaymentService
+	bookingService BookingService
+	sender         utils.WhatsAppSender
+	media          utils.WhatsAppMediaFetcher
+	messages       *templates.Catalogue
+	upload         func(data []byte, folder string) (string, error)
+}
+
+func NewWhatsAppBotService(penyewaRepo repository.PenyewaRepository, prefRepo repository.NotificationPreferenceRepository, inboundRepo repository.InboundMessageRepository, paymentService PaymentService, bookingService BookingService, sender utils.WhatsAppSender, media utils.WhatsAppMediaFetcher, messages *templates.Catalogue) WhatsAppBotService {
+	return &whatsAppBotService{penyewaRepo, prefRepo, inboundRepo, paymentService, bookingService, sender, media, messages, utils.UploadBytes}
+}
+
+// HandleMessage memproses satu pesan masuk dan mengirim balasan ke nomor pengirim.
+// Pesan dengan MessageID yang sudah pernah diproses (webhook dikirim ulang provider) diabaikan.
+func (s *whatsAppBotService) HandleMessage(msg utils.InboundWhatsApp) error {
+	from, err := validators.NormalizePhone(msg.From)
+	if err != nil {
+		utils.GlobalLogger.Warn("WhatsApp bot: ignoring message from invalid number %q", msg.From)
+		return nil
+	}
+
+	if msg.MessageID != "" {
+		first, err := s.inboundRepo.MarkProcessed(msg.MessageID, from)
+		if err != nil {
+			return err
+		}
+		if !first {
+			utils.GlobalLogger.Info("WhatsApp bot: ignoring redelivered message %s from %s", msg.MessageID, from)
+			return nil
+		}
+	}
+
+	penyewa, err := s.penyewaRepo.FindByPhone(from)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return s.reply(from, templates.BotUnregistered, templates.DefaultLanguage, map[string]interface{}{"TenantName": msg.Name})
+	}
+	if err != nil {
+		return err
+	}
+
+	language := templates.DefaultLanguage
+	if setting, err := s.prefRepo.FindSetting(penyewa.UserID); err == nil && setting.Language != "" {
+		language = setting.Language
+	}
+
+	template := templates.BotHelp
+	data := map[string]interface{}{}
+	if msg.Media != nil {
+		template, data = templates.BotProof, s.handleProof(penyewa, msg.Media)
+	} else {
+		switch template = botCommands[botCommand(msg.Text)]; template {
+		case templates.BotBills:
+			data, err = s.bills(penyewa)
+		case templates.BotStatus:
+			data, err = s.status(penyewa)
+		case templates.BotPay:
+			data, err = s.pay(penyewa)
+		default:
+			template = templates.BotHelp
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	data["TenantName"] = penyewa.NamaLengkap
+	return s.reply(from, template, language, data)
+}
+
+// botCommand mengambil kata pertama pesan, misalnya "tagihan bulan ini" -> "TAGIHAN"
+func botCommand(text string) string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(strings.Trim(fields[0], "*.,!?"))
+}
+
+func (s *whatsAppBotService) bills(penyewa *models.Penyewa) (map[string]interface{}, error) {
+	reminders, err := s.paymentService.GetPaymentReminders(penyewa.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	bills := []map[string]interface{}{}
+	for _, r := range reminders {
+		if r.StatusReminder == "Paid" || r.StatusReminder == "Expired" {
+			continue
+		}
+		bills = append(bills, map[string]interface{}{
+			"RoomNumber": r.Pembayaran.Pemesanan.Kamar.NomorKamar,
+			"Amount":     r.JumlahBayar,
+			"DueDate":    r.TanggalReminder,
+			"Status":     r.StatusReminder,
+		})
+	}
+	return map[string]interface{}{"Bills": bills}, nil
+}
+
+func (s *whatsAppBotService) status(penyewa *models.Penyewa) (map[string]interface{}, error) {
+	bookings, err := s.bookingService.GetUserBookings(penyewa.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []map[string]interface{}{}
+	for _, b := range bookings {
+		// TanggalMulai sudah diformat "2006-01-02" oleh BookingService
+		start, _ := time.Parse("2006-01-02", b.TanggalMulai)
+		items = append(items, map[string]interface{}{
+			"RoomNumber":    b.Kamar.NomorKamar,
+			"Status":        b.StatusPemesanan,
+			"StartDate":     start,
+			"Months":        b.DurasiSewa,
+			"PaymentStatus": b.StatusBayar,
+		})
+	}
+	return map[string]interface{}{"Bookings": items}, nil
+}
+
+func (s *whatsAppBotService) pay(penyewa *models.Penyewa) (map[string]interface{}, error) {
+	payment, room, err := s.awaitingProof(penyewa)
+	if err != nil {
+		return nil, err
+	}
+	if payment == nil {
+		return map[string]interface{}{"Payment": nil}, nil
+	}
+	return map[string]interface{}{
+		"Payment": map[string]interface{}{"ID": payment.ID, "RoomNumber": room, "Amount": payment.JumlahBayar},
+	}, nil
+}
+
+// handleProof mengunduh gambar dan menyimpannya sebagai BuktiTransfer lewat UploadPaymentProof.
+// Error tidak dikembalikan; tenant mendapat balasan "gagal" dan detailnya dicatat di log.
+func (s *whatsAppBotService) handleProof(penyewa *models.Penyewa, media *utils.InboundMedia) map[string]interface{} {
+	payment, room, err := s.awaitingProof(penyewa)
+	if err != nil {
+		utils.GlobalLogger.Error("WhatsApp bot: failed to load payments for user %d: %v", penyewa.UserID, err)
+		return map[string]interface{}{"Result": botProofFailed}
+	}
+	if payment == nil {
+		return map[string]interface{}{"Result": botProofNoPayment}
+	}
+
+	// Jenis gambar dideteksi ulang dari isinya oleh upload, content type dari WhatsApp tidak dipakai
+	data, _, err := s.media.FetchMedia(*media)
+	if err != nil {
+		utils.GlobalLogger.Error("WhatsApp bot: failed to download proof from user %d: %v", penyewa.UserID, err)
+		return map[string]interface{}{"Result": botProofFailed}
+	}
+
+	url, err := s.upload(data, "proofs")
+	if err != nil {
+		utils.GlobalLogger.Error("WhatsApp bot: failed to store proof from user %d: %v", penyewa.UserID, err)
+		return map[string]interface{}{"Result": botProofFailed}
+	}
+
+	if err := s.paymentService.UploadPaymentProof(payment.ID, url, penyewa.UserID); err != nil {
+		utils.GlobalLogger.Error("WhatsApp bot: failed to attach proof to payment %d: %v", payment.ID, err)
+		return map[string]interface{}{"Result": botProofFailed}
+	}
+
+	return map[string]interface{}{"Result": botProofReceived, "RoomNumber": room, "Amount": payment.JumlahBayar}
+}
+
+// awaitingProof mencari pembayaran terbaru yang masih Pending (atau Rejected, boleh kirim ulang bukti).
+// nil jika tidak ada.
+func (s *whatsAppBotService) awaitingProof(penyewa *models.Penyewa) (*models.Pembayaran, string, error) {
+	bookings, err := s.bookingService.GetUserBookings(penyewa.UserID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var latest *models.Pembayaran
+	var room string
+	for _, b := range bookings {
+		if b.StatusPemesanan == "Cancelled" {
+			continue
+		}
+		for i := range b.Payments {
+			p := &b.Payments[i]
+			if p.StatusPembayaran != "Pending" && p.StatusPembayaran != "Rejected" {
+				continue
+			}
+			if latest == nil || p.CreatedAt.After(latest.CreatedAt) {
+				latest, room = p, b.Kamar.NomorKamar
+			}
+		}
+	}
+	return latest, room, nil
+}
+
+func (s *whatsAppBotService) reply(to, template, language string, data map[string]interface{}) error {
+	msg, err := s.messages.Render(template, templates.ChannelWhatsApp, language, data)
+	if err != nil {
+		return err
+	}
+	_, err = s.sender.SendWhatsApp(to, msg.Body)
+	return err
+}
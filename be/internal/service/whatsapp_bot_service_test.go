@@ -0,0 +1,343 @@
+package service
+
+import (
+	"errors"
+	"koskosan-be/internal/models"
+	"koskosan-be/internal/repository"
+	"koskosan-be/internal/templates"
+	"koskosan-be/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockPaymentService implements PaymentService interface
+type MockPaymentService struct {
+	mock.Mock
+}
+
+func (m *MockPaymentService) GetAllPayments() ([]models.Pembayaran, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Pembayaran), args.Error(1)
+}
+
+func (m *MockPaymentService) ConfirmPayment(paymentID uint) error {
+	args := m.Called(paymentID)
+	return args.Error(0)
+}
+
+func (m *MockPaymentService) RejectPayment(paymentID uint) error {
+	args := m.Called(paymentID)
+	return args.Error(0)
+}
+
+func (m *MockPaymentService) CreatePaymentSession(pemesananID uint, paymentType string, voucherCode string) (*models.Pembayaran, error) {
+	args := m.Called(pemesananID, paymentType, voucherCode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Pembayaran), args.Error(1)
+}
+
+func (m *MockPaymentService) ConfirmCashPayment(paymentID uint, buktiTransfer string) error {
+	args := m.Called(paymentID, buktiTransfer)
+	return args.Error(0)
+}
+
+func (m *MockPaymentService) GetPaymentReminders(userID uint) ([]models.PaymentReminder, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.PaymentReminder), args.Error(1)
+}
+
+func (m *MockPaymentService) CreatePaymentReminder(pembayaranID uint, jumlahBayar float64, daysUntilDue int) error {
+	args := m.Called(pembayaranID, jumlahBayar, daysUntilDue)
+	return args.Error(0)
+}
+
+func (m *MockPaymentService) UploadPaymentProof(paymentID uint, buktiTransfer string, userID uint) error {
+	args := m.Called(paymentID, buktiTransfer, userID)
+	return args.Error(0)
+}
+
+func (m *MockPaymentService) GetPaymentProof(paymentID uint, userID uint, isAdmin bool) (string, error) {
+	args := m.Called(paymentID, userID, isAdmin)
+	return args.String(0), args.Error(1)
+}
+
+// MockBookingService implements BookingService interface
+type MockBookingService struct {
+	mock.Mock
+}
+
+func (m *MockBookingService) GetUserBookings(userID uint) ([]BookingResponse, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]BookingResponse), args.Error(1)
+}
+
+func (m *MockBookingService) CreateBooking(userID uint, kamarID uint, tanggalMulai string, durasiSewa int) (*models.Pemesanan, error) {
+	args := m.Called(userID, kamarID, tanggalMulai, durasiSewa)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Pemesanan), args.Error(1)
+}
+
+func (m *MockBookingService) CreateBookingWithProof(userID uint, kamarID uint, tanggalMulai string, durasiSewa int, proofURL string, paymentType string, paymentMethod string, voucherCode string) (*models.Pemesanan, error) {
+	args := m.Called(userID, kamarID, tanggalMulai, durasiSewa, proofURL, paymentType, paymentMethod, voucherCode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Pemesanan), args.Error(1)
+}
+
+func (m *MockBookingService) FindAvailableRoom(roomTypeID uint, tanggalMulai string, durasiSewa int) (*models.Kamar, error) {
+	args := m.Called(roomTypeID, tanggalMulai, durasiSewa)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Kamar), args.Error(1)
+}
+
+func (m *MockBookingService) CancelBooking(id uint, userID uint) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+
+func (m *MockBookingService) ExtendBooking(bookingID uint, months int, userID uint, paymentMethod string, voucherCode string) (*models.Pembayaran, error) {
+	args := m.Called(bookingID, months, userID, paymentMethod, voucherCode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Pembayaran), args.Error(1)
+}
+
+func (m *MockBookingService) AutoCancelExpiredBookings() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+// MockMediaFetcher implements utils.WhatsAppMediaFetcher interface
+type MockMediaFetcher struct {
+	mock.Mock
+}
+
+func (m *MockMediaFetcher) FetchMedia(media utils.InboundMedia) ([]byte, string, error) {
+	args := m.Called(media)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]byte), args.String(1), args.Error(2)
+}
+
+// MockInboundMessageRepository implements repository.InboundMessageRepository interface
+type MockInboundMessageRepository struct {
+	mock.Mock
+}
+
+func (m *MockInboundMessageRepository) MarkProcessed(messageID, from string) (bool, error) {
+	args := m.Called(messageID, from)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockInboundMessageRepository) WithTx(tx *gorm.DB) repository.InboundMessageRepository {
+	return m
+}
+
+type botTestDeps struct {
+	penyewaRepo    *MockPenyewaRepository
+	prefRepo       *MockNotificationPreferenceRepository
+	inboundRepo    *MockInboundMessageRepository
+	paymentService *MockPaymentService
+	bookingService *MockBookingService
+	sender         *MockWhatsAppSender
+	media          *MockMediaFetcher
+	uploaded       [][]byte
+}
+
+func newTestBot() (*whatsAppBotService, *botTestDeps) {
+	d := &botTestDeps{
+		penyewaRepo:    new(MockPenyewaRepository),
+		prefRepo:       new(MockNotificationPreferenceRepository),
+		inboundRepo:    new(MockInboundMessageRepository),
+		paymentService: new(MockPaymentService),
+		bookingService: new(MockBookingService),
+		sender:         new(MockWhatsAppSender),
+		media:          new(MockMediaFetcher),
+	}
+	s := NewWhatsAppBotService(d.penyewaRepo, d.prefRepo, d.inboundRepo, d.paymentService, d.bookingService, d.sender, d.media, templates.MustNew(nil)).(*whatsAppBotService)
+	s.upload = func(data []byte, folder string) (string, error) {
+		d.uploaded = append(d.uploaded, data)
+		return "/proofs/from-whatsapp.png", nil
+	}
+	return s, d
+}
+
+func (d *botTestDeps) expectTenant(language string) *models.Penyewa {
+	penyewa := &models.Penyewa{ID: 3, UserID: 7, NamaLengkap: "Budi Santoso", NomorHP: "081234567890"}
+	d.penyewaRepo.On("FindByPhone", "6281234567890").Return(penyewa, nil)
+	d.prefRepo.On("FindSetting", uint(7)).Return(&models.NotificationSetting{UserID: 7, Language: language}, nil)
+	return penyewa
+}
+
+// captureReply menyimpan isi balasan bot
+func (d *botTestDeps) captureReply() *string {
+	var body string
+	d.sender.On("SendWhatsApp", "6281234567890", mock.Anything).Run(func(args mock.Arguments) {
+		body = args.String(1)
+	}).Return("wamid.reply", nil)
+	return &body
+}
+
+func pendingBooking() []BookingResponse {
+	return []BookingResponse{{
+		ID:              10,
+		Kamar:           models.Kamar{NomorKamar: "A-01"},
+		TanggalMulai:    "2026-09-01",
+		DurasiSewa:      6,
+		StatusPemesanan: "Confirmed",
+		StatusBayar:     "Pending",
+		Payments: []models.Pembayaran{
+			{ID: 20, JumlahBayar: 1500000, StatusPembayaran: "Confirmed", CreatedAt: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)},
+			{ID: 21, JumlahBayar: 1500000, StatusPembayaran: "Pending", CreatedAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}}
+}
+
+func TestWhatsAppBot_Tagihan(t *testing.T) {
+	s, d := newTestBot()
+	d.expectTenant("id")
+	reply := d.captureReply()
+
+	d.paymentService.On("GetPaymentReminders", uint(7)).Return([]models.PaymentReminder{
+		{JumlahBayar: 1500000, TanggalReminder: time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC), StatusReminder: "Pending",
+			Pembayaran: models.Pembayaran{Pemesanan: models.Pemesanan{Kamar: models.Kamar{NomorKamar: "A-01"}}}},
+		{JumlahBayar: 900000, StatusReminder: "Paid"},
+	}, nil)
+
+	err := s.HandleMessage(utils.InboundWhatsApp{From: "6281234567890", Text: "tagihan bulan ini?"})
+
+	assert.NoError(t, err)
+	assert.Contains(t, *reply, "Budi Santoso")
+	assert.Contains(t, *reply, "Kamar A-01: *Rp 1.500.000*, jatuh tempo 25 Okt 2026")
+	assert.NotContains(t, *reply, "900.000")
+}
+
+func TestWhatsAppBot_StatusInEnglish(t *testing.T) {
+	s, d := newTestBot()
+	d.expectTenant("en")
+	reply := d.captureReply()
+	d.bookingService.On("GetUserBookings", uint(7)).Return(pendingBooking(), nil)
+
+	err := s.HandleMessage(utils.InboundWhatsApp{From: "+62 812-3456-7890", Text: "STATUS"})
+
+	assert.NoError(t, err)
+	assert.Contains(t, *reply, "Room A-01: Confirmed, from 01 Sep 2026 (6 months), last payment Pending")
+}
+
+func TestWhatsAppBot_Bayar(t *testing.T) {
+	s, d := newTestBot()
+	d.expectTenant("id")
+	reply := d.captureReply()
+	d.bookingService.On("GetUserBookings", uint(7)).Return(pendingBooking(), nil)
+
+	err := s.HandleMessage(utils.InboundWhatsApp{From: "6281234567890", Text: "BAYAR"})
+
+	assert.NoError(t, err)
+	assert.Contains(t, *reply, "Kamar A-01 sebesar *Rp 1.500.000* menunggu bukti transfer")
+}
+
+func TestWhatsAppBot_ImageBecomesPaymentProof(t *testing.T) {
+	s, d := newTestBot()
+	d.expectTenant("id")
+	reply := d.captureReply()
+	d.bookingService.On("GetUserBookings", uint(7)).Return(pendingBooking(), nil)
+
+	media := utils.InboundMedia{ID: "media-1", MimeType: "image/png"}
+	d.media.On("FetchMedia", media).Return([]byte("png-bytes"), "image/png", nil)
+	d.paymentService.On("UploadPaymentProof", uint(21), "/proofs/from-whatsapp.png", uint(7)).Return(nil)
+
+	err := s.HandleMessage(utils.InboundWhatsApp{From: "6281234567890", Media: &media})
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("png-bytes")}, d.uploaded)
+	assert.Contains(t, *reply, "sudah kami terima")
+	d.paymentService.AssertExpectations(t)
+}
+
+func TestWhatsAppBot_RedeliveredMessageIsIgnored(t *testing.T) {
+	s, d := newTestBot()
+	d.expectTenant("id")
+	reply := d.captureReply()
+	d.bookingService.On("GetUserBookings", uint(7)).Return(pendingBooking(), nil)
+
+	media := utils.InboundMedia{ID: "media-1", MimeType: "image/png"}
+	d.media.On("FetchMedia", media).Return([]byte("png-bytes"), "image/png", nil)
+	d.paymentService.On("UploadPaymentProof", uint(21), "/proofs/from-whatsapp.png", uint(7)).Return(nil).Once()
+	d.inboundRepo.On("MarkProcessed", "wamid.proof", "6281234567890").Return(true, nil).Once()
+	d.inboundRepo.On("MarkProcessed", "wamid.proof", "6281234567890").Return(false, nil).Once()
+
+	msg := utils.InboundWhatsApp{MessageID: "wamid.proof", From: "6281234567890", Media: &media}
+	assert.NoError(t, s.HandleMessage(msg))
+	assert.NoError(t, s.HandleMessage(msg))
+
+	assert.Len(t, d.uploaded, 1)
+	assert.Contains(t, *reply, "sudah kami terima")
+	d.paymentService.AssertNumberOfCalls(t, "UploadPaymentProof", 1)
+	d.sender.AssertNumberOfCalls(t, "SendWhatsApp", 1)
+}
+
+func TestWhatsAppBot_ImageWithoutPendingPayment(t *testing.T) {
+	s, d := newTestBot()
+	d.expectTenant("id")
+	reply := d.captureReply()
+	d.bookingService.On("GetUserBookings", uint(7)).Return([]BookingResponse{}, nil)
+
+	err := s.HandleMessage(utils.InboundWhatsApp{From: "6281234567890", Media: &utils.InboundMedia{ID: "media-1"}})
+
+	assert.NoError(t, err)
+	assert.Contains(t, *reply, "Tidak ada pembayaran yang menunggu bukti transfer")
+	d.media.AssertNotCalled(t, "FetchMedia", mock.Anything)
+	d.paymentService.AssertNotCalled(t, "UploadPaymentProof", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWhatsAppBot_ImageDownloadFails(t *testing.T) {
+	s, d := newTestBot()
+	d.expectTenant("id")
+	reply := d.captureReply()
+	d.bookingService.On("GetUserBookings", uint(7)).Return(pendingBooking(), nil)
+	d.media.On("FetchMedia", mock.Anything).Return(nil, "", errors.New("lampiran bukan gambar"))
+
+	err := s.HandleMessage(utils.InboundWhatsApp{From: "6281234567890", Media: &utils.InboundMedia{URL: "https://example.com/file.pdf"}})
+
+	assert.NoError(t, err)
+	assert.Contains(t, *reply, "gagal diproses")
+	d.paymentService.AssertNotCalled(t, "UploadPaymentProof", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWhatsAppBot_UnknownCommandAndNumber(t *testing.T) {
+	t.Run("Unknown command gets help", func(t *testing.T) {
+		s, d := newTestBot()
+		d.expectTenant("id")
+		reply := d.captureReply()
+
+		err := s.HandleMessage(utils.InboundWhatsApp{From: "6281234567890", Text: "halo min"})
+
+		assert.NoError(t, err)
+		assert.Contains(t, *reply, "*TAGIHAN*")
+	})
+
+	t.Run("Unregistered number", func(t *testing.T) {
+		s, d := newTestBot()
+		d.penyewaRepo.On("FindByPhone", "6281234567890").Return(nil, gorm.ErrRecordNotFound)
+		reply := d.captureReply()
+
+		err := s.HandleMessage(utils.InboundWhatsApp{From: "081234567890", Text: "TAGIHAN"})
+
+		assert.NoError(t, err)
+		assert.Contains(t, *reply, "belum terdaftar")
+		d.paymentService.AssertNotCalled(t, "GetPaymentReminders", mock.Anything)
+	})
+}
@@ -0,0 +1,311 @@
+// Package templates berisi katalog pesan email/WhatsApp (id/en).
+// Template bawaan ada di folder files/ (di-embed ke binary), admin bisa menimpanya lewat tabel message_templates.
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"koskosan-be/internal/models"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ChannelEmail    = "email"
+	ChannelWhatsApp = "whatsapp"
+	ChannelSMS      = "sms" // cadangan WhatsApp, teks pendek tanpa format
+
+	LanguageID      = "id"
+	LanguageEN      = "en"
+	DefaultLanguage = LanguageID
+)
+
+// Nama template yang dipakai aplikasi
+const (
+	PaymentSuccess  = "payment_success"
+	PaymentReminder = "payment_reminder"
+	PasswordReset   = "password_reset"
+	ContactMessage  = "contact_message"
+	ContactReply    = "contact_reply"
+	TicketStatus    = "ticket_status_changed"
+	RentChange      = "rent_change_notice"
+
+	// Balasan bot WhatsApp (hanya channel whatsapp)
+	BotHelp         = "bot_help"
+	BotUnregistered = "bot_unregistered"
+	BotBills        = "bot_bills"
+	BotStatus       = "bot_status"
+	BotPay          = "bot_pay"
+	BotProof        = "bot_proof"
+)
+
+var Languages = []string{LanguageID, LanguageEN}
+
+// subjectSeparator memisahkan baris subject dan body pada file template email
+const subjectSeparator = "\n---\n"
+
+//go:embed files
+var files embed.FS
+
+// Source adalah isi mentah satu template (sebelum di-parse)
+type Source struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Message adalah hasil render yang siap dikirim
+type Message struct {
+	Subject string `json:"subject,omitempty"`
+	Body    string `json:"body"`
+}
+
+// Entry dipakai untuk daftar template di halaman admin
+type Entry struct {
+	Name       string `json:"name"`
+	Channel    string `json:"channel"`
+	Language   string `json:"language"`
+	Subject    string `json:"subject"`
+	Body       string `json:"body"`
+	Customized bool   `json:"customized"` // true = sudah diedit admin (dari database)
+}
+
+// Store adalah sumber template hasil edit admin (repository.MessageTemplateRepository)
+type Store interface {
+	Find(name, channel, language string) (*models.MessageTemplate, error)
+	FindAll() ([]models.MessageTemplate, error)
+}
+
+type key struct {
+	name, channel, language string
+}
+
+type Catalogue struct {
+	defaults map[key]Source
+	layout   *htmltemplate.Template
+	store    Store
+}
+
+// New memuat template bawaan dari files/. store boleh nil (hanya template bawaan).
+func New(store Store) (*Catalogue, error) {
+	c := &Catalogue{defaults: map[key]Source{}, store: store}
+
+	layout, err := fs.ReadFile(files, "files/layout.html.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	if c.layout, err = htmltemplate.New("layout").Parse(string(layout)); err != nil {
+		return nil, fmt.Errorf("layout email: %w", err)
+	}
+
+	for _, channel := range []string{ChannelEmail, ChannelWhatsApp, ChannelSMS} {
+		paths, err := fs.Glob(files, "files/"+channel+"/*.tmpl")
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			// files/email/payment_success.id.tmpl -> payment_success, id
+			base := strings.TrimSuffix(path[strings.LastIndex(path, "/")+1:], ".tmpl")
+			name, language, ok := strings.Cut(base, ".")
+			if !ok {
+				return nil, fmt.Errorf("nama file template tidak valid: %s", path)
+			}
+			raw, err := fs.ReadFile(files, path)
+			if err != nil {
+				return nil, err
+			}
+			src := parseSource(channel, string(raw))
+			if err := Validate(channel, src); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			c.defaults[key{name, channel, language}] = src
+		}
+	}
+	return c, nil
+}
+
+// MustNew seperti New tapi panic jika template bawaan rusak (kesalahan build, bukan runtime)
+func MustNew(store Store) *Catalogue {
+	c, err := New(store)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func parseSource(channel, raw string) Source {
+	raw = strings.ReplaceAll(raw, "\r\n", "\n")
+	if channel == ChannelEmail {
+		if subject, body, ok := strings.Cut(raw, subjectSeparator); ok {
+			return Source{Subject: strings.TrimSpace(subject), Body: strings.TrimSpace(body)}
+		}
+	}
+	return Source{Body: strings.TrimSpace(raw)}
+}
+
+// Has mengecek apakah template punya varian untuk channel tersebut
+func (c *Catalogue) Has(name, channel string) bool {
+	_, ok := c.defaults[key{name, channel, DefaultLanguage}]
+	return ok
+}
+
+// Default mengembalikan template bawaan
+func (c *Catalogue) Default(name, channel, language string) (Source, bool) {
+	src, ok := c.defaults[key{name, channel, language}]
+	return src, ok
+}
+
+// Lookup mengembalikan template yang berlaku: hasil edit admin jika ada, selain itu bawaan.
+// Bahasa yang tidak dikenal jatuh ke DefaultLanguage.
+func (c *Catalogue) Lookup(name, channel, language string) (Source, error) {
+	if !IsLanguage(language) {
+		language = DefaultLanguage
+	}
+	if c.store != nil {
+		tmpl, err := c.store.Find(name, channel, language)
+		if err == nil {
+			return Source{Subject: tmpl.Subject, Body: tmpl.Body}, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return Source{}, err
+		}
+	}
+	if src, ok := c.defaults[key{name, channel, language}]; ok {
+		return src, nil
+	}
+	if src, ok := c.defaults[key{name, channel, DefaultLanguage}]; ok {
+		return src, nil
+	}
+	return Source{}, fmt.Errorf("template %s/%s tidak ditemukan", channel, name)
+}
+
+// Render mengisi template dengan data. Email dibungkus layout HTML bersama.
+func (c *Catalogue) Render(name, channel, language string, data map[string]interface{}) (*Message, error) {
+	src, err := c.Lookup(name, channel, language)
+	if err != nil {
+		return nil, err
+	}
+	if !IsLanguage(language) {
+		language = DefaultLanguage
+	}
+	return c.RenderSource(channel, language, src, data)
+}
+
+// RenderSource merender template mentah, dipakai juga untuk preview sebelum disimpan
+func (c *Catalogue) RenderSource(channel, language string, src Source, data map[string]interface{}) (*Message, error) {
+	funcs := Funcs(language)
+
+	if channel != ChannelEmail {
+		body, err := executeText(src.Body, funcs, data)
+		if err != nil {
+			return nil, err
+		}
+		return &Message{Body: body}, nil
+	}
+
+	subject, err := executeText(src.Subject, funcs, data)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := htmltemplate.New("body").Funcs(htmltemplate.FuncMap(funcs)).Parse(src.Body)
+	if err != nil {
+		return nil, err
+	}
+	var content bytes.Buffer
+	if err := t.Execute(&content, data); err != nil {
+		return nil, err
+	}
+
+	var page bytes.Buffer
+	err = c.layout.Execute(&page, map[string]interface{}{
+		"Lang":    language,
+		"Subject": subject,
+		"Content": htmltemplate.HTML(content.String()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Message{Subject: subject, Body: page.String()}, nil
+}
+
+func executeText(src string, funcs map[string]interface{}, data map[string]interface{}) (string, error) {
+	t, err := texttemplate.New("text").Funcs(texttemplate.FuncMap(funcs)).Parse(src)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// Entries mendaftar semua template (bawaan + status edit admin), urut nama/channel/bahasa
+func (c *Catalogue) Entries() ([]Entry, error) {
+	overrides := map[key]models.MessageTemplate{}
+	if c.store != nil {
+		stored, err := c.store.FindAll()
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range stored {
+			overrides[key{t.Name, t.Channel, t.Language}] = t
+		}
+	}
+
+	entries := make([]Entry, 0, len(c.defaults))
+	for k, src := range c.defaults {
+		entry := Entry{Name: k.name, Channel: k.channel, Language: k.language, Subject: src.Subject, Body: src.Body}
+		if t, ok := overrides[k]; ok {
+			entry.Subject, entry.Body, entry.Customized = t.Subject, t.Body, true
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Channel != b.Channel {
+			return a.Channel < b.Channel
+		}
+		return a.Language < b.Language
+	})
+	return entries, nil
+}
+
+// Validate memastikan template bisa di-parse sebelum disimpan
+func Validate(channel string, src Source) error {
+	funcs := Funcs(DefaultLanguage)
+	if strings.TrimSpace(src.Body) == "" {
+		return errors.New("isi template tidak boleh kosong")
+	}
+	if channel == ChannelEmail {
+		if strings.TrimSpace(src.Subject) == "" {
+			return errors.New("subject email tidak boleh kosong")
+		}
+		if _, err := texttemplate.New("subject").Funcs(texttemplate.FuncMap(funcs)).Parse(src.Subject); err != nil {
+			return err
+		}
+		_, err := htmltemplate.New("body").Funcs(htmltemplate.FuncMap(funcs)).Parse(src.Body)
+		return err
+	}
+	_, err := texttemplate.New("body").Funcs(texttemplate.FuncMap(funcs)).Parse(src.Body)
+	return err
+}
+
+func IsLanguage(language string) bool {
+	for _, l := range Languages {
+		if l == language {
+			return true
+		}
+	}
+	return false
+}
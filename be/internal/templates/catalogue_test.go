@@ -0,0 +1,118 @@
+package templates
+
+import (
+	"koskosan-be/internal/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeStore menyimulasikan tabel message_templates
+type fakeStore map[string]models.MessageTemplate
+
+func (f fakeStore) Find(name, channel, language string) (*models.MessageTemplate, error) {
+	if t, ok := f[name+"/"+channel+"/"+language]; ok {
+		return &t, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f fakeStore) FindAll() ([]models.MessageTemplate, error) {
+	var all []models.MessageTemplate
+	for _, t := range f {
+		all = append(all, t)
+	}
+	return all, nil
+}
+
+func TestCatalogue_RendersEveryDefaultTemplate(t *testing.T) {
+	c := MustNew(nil)
+
+	entries, err := c.Entries()
+	require.NoError(t, err)
+	assert.NotEmpty(t, entries)
+
+	for _, e := range entries {
+		msg, err := c.Render(e.Name, e.Channel, e.Language, SampleData(e.Name))
+		require.NoError(t, err, "%s/%s/%s", e.Channel, e.Name, e.Language)
+		assert.NotContains(t, msg.Body, "<no value>", "%s/%s/%s", e.Channel, e.Name, e.Language)
+		if e.Channel == ChannelEmail {
+			assert.NotEmpty(t, msg.Subject)
+			assert.Contains(t, msg.Body, `<html lang="`+e.Language+`">`)
+		}
+	}
+}
+
+func TestCatalogue_LanguageVariantsAndFallback(t *testing.T) {
+	c := MustNew(nil)
+	data := map[string]interface{}{
+		"TenantName": "Budi",
+		"Amount":     1500000.0,
+		"DueDate":    time.Date(2026, 8, 17, 0, 0, 0, 0, time.UTC),
+		"RoomNumber": "A-01",
+	}
+
+	id, err := c.Render(PaymentReminder, ChannelWhatsApp, LanguageID, data)
+	require.NoError(t, err)
+	assert.Contains(t, id.Body, "Halo Budi")
+	assert.Contains(t, id.Body, "*Rp 1.500.000*")
+	assert.Contains(t, id.Body, "17 Agu 2026")
+
+	en, err := c.Render(PaymentReminder, ChannelWhatsApp, LanguageEN, data)
+	require.NoError(t, err)
+	assert.Contains(t, en.Body, "Hi Budi")
+	assert.Contains(t, en.Body, "17 Aug 2026")
+
+	// Bahasa yang tidak dikenal memakai bahasa default
+	fallback, err := c.Render(PaymentReminder, ChannelWhatsApp, "fr", data)
+	require.NoError(t, err)
+	assert.Equal(t, id.Body, fallback.Body)
+}
+
+func TestCatalogue_StoreOverridesDefault(t *testing.T) {
+	c := MustNew(fakeStore{
+		"payment_success/whatsapp/id": {Name: PaymentSuccess, Channel: ChannelWhatsApp, Language: LanguageID, Body: "Lunas: {{rupiah .Amount}}"},
+	})
+
+	msg, err := c.Render(PaymentSuccess, ChannelWhatsApp, LanguageID, map[string]interface{}{"Amount": 250000.0})
+	require.NoError(t, err)
+	assert.Equal(t, "Lunas: Rp 250.000", msg.Body)
+
+	entries, err := c.Entries()
+	require.NoError(t, err)
+	for _, e := range entries {
+		customized := e.Name == PaymentSuccess && e.Channel == ChannelWhatsApp && e.Language == LanguageID
+		assert.Equal(t, customized, e.Customized, "%s/%s/%s", e.Channel, e.Name, e.Language)
+	}
+}
+
+func TestCatalogue_EmailEscapesUserInput(t *testing.T) {
+	c := MustNew(nil)
+
+	msg, err := c.Render(ContactMessage, ChannelEmail, LanguageID, map[string]interface{}{
+		"Name":    "Eve",
+		"Email":   "eve@example.com",
+		"Message": `<script>alert("x")</script>`,
+	})
+	require.NoError(t, err)
+	assert.False(t, strings.Contains(msg.Body, "<script>"))
+	assert.Contains(t, msg.Body, "&lt;script&gt;")
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(ChannelWhatsApp, Source{Body: "Halo {{.TenantName}}"}))
+	assert.Error(t, Validate(ChannelWhatsApp, Source{Body: "Halo {{.TenantName"}))
+	assert.Error(t, Validate(ChannelEmail, Source{Body: "<p>ok</p>"}), "email needs a subject")
+	assert.Error(t, Validate(ChannelEmail, Source{Subject: "Hi", Body: "{{unknownFunc}}"}))
+}
+
+func TestRupiah(t *testing.T) {
+	assert.Equal(t, "Rp 0", Rupiah(0))
+	assert.Equal(t, "Rp 950", Rupiah(950))
+	assert.Equal(t, "Rp 1.500.000", Rupiah(1500000))
+	assert.Equal(t, "-Rp 12.000", Rupiah(-12000))
+}
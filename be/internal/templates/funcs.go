@@ -0,0 +1,142 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var monthsID = []string{"Jan", "Feb", "Mar", "Apr", "Mei", "Jun", "Jul", "Agu", "Sep", "Okt", "Nov", "Des"}
+
+// Funcs adalah fungsi yang tersedia di semua template; format tanggal mengikuti bahasa
+func Funcs(language string) map[string]interface{} {
+	return map[string]interface{}{
+		"rupiah": Rupiah,
+		"date": func(t time.Time) string {
+			return formatDate(t, language, false)
+		},
+		"datetime": func(t time.Time) string {
+			return formatDate(t, language, true)
+		},
+	}
+}
+
+// Rupiah: 1500000 -> "Rp 1.500.000"
+func Rupiah(amount float64) string {
+	digits := fmt.Sprintf("%.0f", amount)
+	negative := strings.HasPrefix(digits, "-")
+	digits = strings.TrimPrefix(digits, "-")
+
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+	if negative {
+		return "-Rp " + b.String()
+	}
+	return "Rp " + b.String()
+}
+
+func formatDate(t time.Time, language string, withTime bool) string {
+	layout := "02 Jan 2006"
+	if withTime {
+		layout += " 15:04"
+	}
+	out := t.Format(layout)
+	if language == LanguageID {
+		// "Jan" di posisi bulan diganti nama bulan Indonesia
+		out = out[:3] + monthsID[t.Month()-1] + out[6:]
+	}
+	return out
+}
+
+// SampleData adalah data contoh untuk preview template di halaman admin
+func SampleData(name string) map[string]interface{} {
+	now := time.Now()
+	switch name {
+	case PaymentSuccess:
+		return map[string]interface{}{
+			"TenantName": "Budi Santoso",
+			"Amount":     1500000.0,
+			"Date":       now,
+			"RoomNumber": "A-01",
+		}
+	case PaymentReminder:
+		return map[string]interface{}{
+			"TenantName":  "Budi Santoso",
+			"Amount":      1500000.0,
+			"DueDate":     now.AddDate(0, 0, 3),
+			"RoomNumber":  "A-01",
+			"PaymentLink": "http://localhost:3000/dashboard/payments/1",
+		}
+	case PasswordReset:
+		return map[string]interface{}{
+			"ResetLink": "http://localhost:3000/reset-password?token=preview",
+		}
+	case ContactMessage:
+		return map[string]interface{}{
+			"Name":    "Siti Aminah",
+			"Email":   "siti@example.com",
+			"Message": "Halo, apakah masih ada kamar kosong untuk bulan depan?",
+		}
+	case ContactReply:
+		return map[string]interface{}{
+			"Name":    "Siti Aminah",
+			"Subject": "Ketersediaan kamar",
+			"Message": "Halo, apakah masih ada kamar kosong untuk bulan depan?",
+			"Reply":   "Halo Kak Siti, masih ada 2 kamar kosong mulai tanggal 1. Silakan booking lewat website.",
+		}
+	case TicketStatus:
+		return map[string]interface{}{
+			"TenantName": "Budi Santoso",
+			"TicketID":   12,
+			"Title":      "AC tidak dingin",
+			"RoomNumber": "A-01",
+			"Status":     "resolved",
+			"Note":       "Freon sudah diisi ulang oleh teknisi.",
+		}
+	case RentChange:
+		return map[string]interface{}{
+			"TenantName":    "Budi Santoso",
+			"RoomNumber":    "A-01",
+			"OldAmount":     1500000.0,
+			"NewAmount":     1650000.0,
+			"EffectiveDate": now.AddDate(0, 1, 0),
+			"Note":          "Penyesuaian tarif listrik dan air.",
+		}
+	case BotHelp, BotUnregistered:
+		return map[string]interface{}{
+			"TenantName": "Budi Santoso",
+		}
+	case BotBills:
+		return map[string]interface{}{
+			"TenantName": "Budi Santoso",
+			"Bills": []map[string]interface{}{
+				{"RoomNumber": "A-01", "Amount": 1500000.0, "DueDate": now.AddDate(0, 0, 3), "Status": "Pending"},
+			},
+		}
+	case BotStatus:
+		return map[string]interface{}{
+			"TenantName": "Budi Santoso",
+			"Bookings": []map[string]interface{}{
+				{"RoomNumber": "A-01", "Status": "Confirmed", "StartDate": now.AddDate(0, -2, 0), "Months": 6, "PaymentStatus": "Confirmed"},
+			},
+		}
+	case BotPay:
+		return map[string]interface{}{
+			"TenantName": "Budi Santoso",
+			"Payment":    map[string]interface{}{"ID": 1, "RoomNumber": "A-01", "Amount": 1500000.0},
+		}
+	case BotProof:
+		return map[string]interface{}{
+			"TenantName": "Budi Santoso",
+			"Result":     "received",
+			"RoomNumber": "A-01",
+			"Amount":     1500000.0,
+		}
+	}
+	return map[string]interface{}{}
+}
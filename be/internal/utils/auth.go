@@ -2,7 +2,10 @@ package utils
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -71,15 +74,15 @@ func GetAuthToken(c *gin.Context) (string, error) {
 }
 
 // GenerateTokenPair generates both access and refresh tokens
-func GenerateTokenPair(userID int, username string, role string, jwtSecret string) (accessToken, refreshToken string, err error) {
+func GenerateTokenPair(userID int, username string, role string, keys *KeySet) (accessToken, refreshToken string, err error) {
 	// Generate access token (short-lived)
-	accessToken, err = GenerateToken(userID, username, role, "access", jwtSecret, AccessTokenExpiry)
+	accessToken, err = GenerateToken(userID, username, role, "access", keys, AccessTokenExpiry)
 	if err != nil {
 		return "", "", err
 	}
 
 	// Generate refresh token (long-lived)
-	refreshToken, err = GenerateToken(userID, username, role, "refresh", jwtSecret, RefreshTokenExpiry)
+	refreshToken, err = GenerateToken(userID, username, role, "refresh", keys, RefreshTokenExpiry)
 	if err != nil {
 		return "", "", err
 	}
@@ -87,8 +90,8 @@ func GenerateTokenPair(userID int, username string, role string, jwtSecret strin
 	return accessToken, refreshToken, nil
 }
 
-// GenerateToken generate JWT token with type
-func GenerateToken(userID int, username string, role string, tokenType string, jwtSecret string, expiresIn time.Duration) (string, error) {
+// GenerateToken generate JWT token with type, signed by the active key (RS256/EdDSA)
+func GenerateToken(userID int, username string, role string, tokenType string, keys *KeySet, expiresIn time.Duration) (string, error) {
 	expiresAt := time.Now().Add(expiresIn)
 
 	claims := &TokenClaims{
@@ -103,23 +106,15 @@ func GenerateToken(userID int, username string, role string, tokenType string, j
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(jwtSecret))
-	if err != nil {
-		return "", err
-	}
-
-	return tokenString, nil
+	return keys.Sign(claims)
 }
 
-// ValidateToken validate JWT token
-func ValidateToken(token string, jwtSecret string) (*TokenClaims, error) {
+// ValidateToken validate JWT token.
+// Signing method dan kid diperiksa oleh KeySet; token HS256 atau alg "none" selalu ditolak.
+func ValidateToken(token string, keys *KeySet) (*TokenClaims, error) {
 	claims := &TokenClaims{}
 
-	parsedToken, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(jwtSecret), nil
-	})
-
+	parsedToken, err := keys.Parse(token, claims)
 	if err != nil {
 		return nil, err
 	}
@@ -128,17 +123,12 @@ func ValidateToken(token string, jwtSecret string) (*TokenClaims, error) {
 		return nil, errors.New("invalid token")
 	}
 
-	// Check if token expired
-	if time.Now().After(claims.ExpiresAt.Time) {
-		return nil, errors.New("token expired")
-	}
-
 	return claims, nil
 }
 
 // ValidateAccessToken validates access token specifically
-func ValidateAccessToken(token string, jwtSecret string) (*TokenClaims, error) {
-	claims, err := ValidateToken(token, jwtSecret)
+func ValidateAccessToken(token string, keys *KeySet) (*TokenClaims, error) {
+	claims, err := ValidateToken(token, keys)
 	if err != nil {
 		return nil, err
 	}
@@ -151,8 +141,8 @@ func ValidateAccessToken(token string, jwtSecret string) (*TokenClaims, error) {
 }
 
 // ValidateRefreshToken validates refresh token specifically
-func ValidateRefreshToken(token string, jwtSecret string) (*TokenClaims, error) {
-	claims, err := ValidateToken(token, jwtSecret)
+func ValidateRefreshToken(token string, keys *KeySet) (*TokenClaims, error) {
+	claims, err := ValidateToken(token, keys)
 	if err != nil {
 		return nil, err
 	}
@@ -164,6 +154,21 @@ func ValidateRefreshToken(token string, jwtSecret string) (*TokenClaims, error)
 	return claims, nil
 }
 
+// GenerateSecureToken menghasilkan token acak URL-safe dari n byte crypto/rand
+func GenerateSecureToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken mengembalikan SHA-256 hex dari token, untuk disimpan di database sebagai pengganti token asli
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 // SetAuthCookies sets both access and refresh token cookies with security flags
 func SetAuthCookies(c *gin.Context, accessToken, refreshToken string, isProduction bool) {
 	// Set SameSite mode for CSRF protection
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSiteVerifyChallengeVerifier_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "secret", r.PostForm.Get("secret"))
+		assert.Equal(t, "token", r.PostForm.Get("response"))
+		assert.Equal(t, "10.0.0.1", r.PostForm.Get("remoteip"))
+		_, _ = io.WriteString(w, `{"success":true}`)
+	}))
+	defer server.Close()
+
+	err := NewSiteVerifyChallengeVerifier(server.URL, "secret").Verify("token", "10.0.0.1")
+
+	assert.NoError(t, err)
+}
+
+func TestSiteVerifyChallengeVerifier_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"success":false,"error-codes":["invalid-input-response"]}`)
+	}))
+	defer server.Close()
+
+	err := NewSiteVerifyChallengeVerifier(server.URL, "secret").Verify("token", "")
+
+	assert.ErrorIs(t, err, ErrChallengeFailed)
+	assert.Contains(t, err.Error(), "invalid-input-response")
+}
+
+func TestSiteVerifyChallengeVerifier_EmptyTokenSkipsRequest(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	err := NewSiteVerifyChallengeVerifier(server.URL, "secret").Verify(" ", "")
+
+	assert.ErrorIs(t, err, ErrChallengeFailed)
+	assert.False(t, called)
+}
+
+func TestFakeChallengeVerifier(t *testing.T) {
+	assert.NoError(t, FakeChallengeVerifier{}.Verify(FakeChallengePassToken, ""))
+	assert.ErrorIs(t, FakeChallengeVerifier{}.Verify("anything", ""), ErrChallengeFailed)
+}
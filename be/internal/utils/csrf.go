@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	CSRFCookieName = "csrf_token"
+	CSRFHeaderName = "X-CSRF-Token"
+)
+
+// GenerateCSRFToken membuat token double-submit "<nonce>.<hmac>" yang terikat ke user.
+// HMAC memakai JWT_SECRET, sehingga cookie yang disuntikkan dari subdomain lain tidak bisa dipakai.
+func GenerateCSRFToken(userID int, secret string) (string, error) {
+	nonce, err := GenerateSecureToken(16)
+	if err != nil {
+		return "", err
+	}
+	return nonce + "." + csrfSignature(nonce, userID, secret), nil
+}
+
+// ValidateCSRFToken memeriksa bahwa token ditandatangani untuk user tersebut
+func ValidateCSRFToken(token string, userID int, secret string) bool {
+	nonce, signature, ok := strings.Cut(token, ".")
+	if !ok || nonce == "" || signature == "" {
+		return false
+	}
+	expected := csrfSignature(nonce, userID, secret)
+	return hmac.Equal([]byte(signature), []byte(expected))
+}
+
+// CSRFTokensMatch membandingkan header dan cookie dalam waktu konstan
+func CSRFTokensMatch(headerToken, cookieToken string) bool {
+	if headerToken == "" || cookieToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(headerToken), []byte(cookieToken)) == 1
+}
+
+func csrfSignature(nonce string, userID int, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "csrf:%d:%s", userID, nonce)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SetCSRFCookie menyimpan token CSRF di cookie yang bisa dibaca JavaScript (bukan HttpOnly).
+// Umurnya mengikuti refresh token; token baru diterbitkan setiap login dan /api/auth/refresh.
+func SetCSRFCookie(c *gin.Context, token string, isProduction bool) {
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(
+		CSRFCookieName,
+		token,
+		int(RefreshTokenExpiry.Seconds()),
+		"/",
+		"",
+		isProduction,
+		false, // double-submit: frontend must be able to read it
+	)
+	c.Header(CSRFHeaderName, token)
+}
+
+// ClearCSRFCookie menghapus cookie CSRF (logout)
+func ClearCSRFCookie(c *gin.Context) {
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(CSRFCookieName, "", -1, "/", "", false, false)
+}
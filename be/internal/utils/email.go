@@ -3,170 +3,94 @@ package utils
 import (
 	"fmt"
 	"koskosan-be/internal/config"
+	"koskosan-be/internal/templates"
 	"log"
 	"strconv"
-	"time"
 
 	"gopkg.in/gomail.v2"
 )
 
 type EmailSender interface {
+	SendEmail(toEmail, subject, htmlBody string) error
 	SendResetPasswordEmail(toEmail, token string) error
-	SendPaymentSuccessEmail(toEmail, tenantName string, amount float64, date time.Time) error
-	SendPaymentReminderEmail(toEmail, tenantName string, amount float64, dueDate time.Time, paymentLink string) error
 }
 
 type GomailSender struct {
-	dialer *gomail.Dialer
-	from   string
-	cfg    *config.Config
+	dialer   *gomail.Dialer
+	from     string
+	cfg      *config.Config
+	messages *templates.Catalogue
 }
 
-func NewGomailSender(cfg *config.Config) *GomailSender {
+func NewGomailSender(cfg *config.Config, messages *templates.Catalogue) *GomailSender {
 	port, _ := strconv.Atoi(cfg.SMTPPort)
 	dialer := gomail.NewDialer(cfg.SMTPHost, port, cfg.SMTPEmail, cfg.SMTPPassword)
 	return &GomailSender{
-		dialer: dialer,
-		from:   cfg.SMTPEmail,
-		cfg:    cfg,
+		dialer:   dialer,
+		from:     cfg.SMTPEmail,
+		cfg:      cfg,
+		messages: messages,
 	}
 }
 
-func (s *GomailSender) SendResetPasswordEmail(toEmail, token string) error {
+// SendEmail mengirim email HTML yang sudah dirender dari katalog template
+func (s *GomailSender) SendEmail(toEmail, subject, htmlBody string) error {
 	m := gomail.NewMessage()
 	m.SetHeader("From", s.from)
 	m.SetHeader("To", toEmail)
-	m.SetHeader("Subject", "Password Reset Request")
-
-	// Use frontend URL from config
-	resetLink := fmt.Sprintf("%s/reset-password?token=%s", s.cfg.FrontendURL, token)
-
-	body := fmt.Sprintf(`
-		<html>
-		<body>
-			<h2>Password Reset Request</h2>
-			<p>Click the link below to reset your password:</p>
-			<a href="%s">Reset Password</a>
-			<p>This link will expire in 1 hour.</p>
-			<p>If you didn't request this, please ignore this email.</p>
-		</body>
-		</html>
-	`, resetLink)
-
-	m.SetBody("text/html", body)
-
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/html", htmlBody)
 	return s.dialer.DialAndSend(m)
 }
 
-func (s *GomailSender) SendPaymentSuccessEmail(toEmail, tenantName string, amount float64, date time.Time) error {
-	m := gomail.NewMessage()
-	m.SetHeader("From", s.from)
-	m.SetHeader("To", toEmail)
-	m.SetHeader("Subject", "Payment Confirmation - Kost Putra Rahmat ZAW")
-
-	formattedAmount := fmt.Sprintf("Rp %.0f", amount)
-	formattedDate := date.Format("02 Jan 2006 15:04")
-
-	body := fmt.Sprintf(`
-		<div style="font-family: Arial, sans-serif; padding: 20px; color: #333;">
-			<h2 style="color: #4CAF50;">Pembayaran Berhasil!</h2>
-			<p>Halo, <strong>%s</strong>,</p>
-			<p>Terima kasih, pembayaran Anda telah kami terima.</p>
-			<table style="width: 100%%; max-width: 400px; margin: 20px 0; border-collapse: collapse;">
-				<tr>
-					<td style="padding: 8px; border-bottom: 1px solid #ddd;">Jumlah</td>
-					<td style="padding: 8px; border-bottom: 1px solid #ddd; font-weight: bold;">%s</td>
-				</tr>
-				<tr>
-					<td style="padding: 8px; border-bottom: 1px solid #ddd;">Tanggal</td>
-					<td style="padding: 8px; border-bottom: 1px solid #ddd;">%s</td>
-				</tr>
-				<tr>
-					<td style="padding: 8px; border-bottom: 1px solid #ddd;">Status</td>
-					<td style="padding: 8px; border-bottom: 1px solid #ddd; color: green;">Lunas</td>
-				</tr>
-			</table>
-			<p>Simpan email ini sebagai bukti pembayaran yang sah.</p>
-			<br/>
-			<p style="font-size: 12px; color: #888;">Kost Putra Rahmat ZAW Management</p>
-		</div>
-	`, tenantName, formattedAmount, formattedDate)
+func (s *GomailSender) SendResetPasswordEmail(toEmail, token string) error {
+	// Use frontend URL from config
+	resetLink := fmt.Sprintf("%s/reset-password?token=%s", s.cfg.FrontendURL, token)
 
-	m.SetBody("text/html", body)
-	return s.dialer.DialAndSend(m)
+	msg, err := s.messages.Render(templates.PasswordReset, templates.ChannelEmail, templates.DefaultLanguage, map[string]interface{}{
+		"ResetLink": resetLink,
+	})
+	if err != nil {
+		return err
+	}
+	return s.SendEmail(toEmail, msg.Subject, msg.Body)
 }
 
-func (s *GomailSender) SendPaymentReminderEmail(toEmail, tenantName string, amount float64, dueDate time.Time, paymentLink string) error {
-	m := gomail.NewMessage()
-	m.SetHeader("From", s.from)
-	m.SetHeader("To", toEmail)
-	m.SetHeader("Subject", "Tagihan Belum Dibayar - Kost Putra Rahmat ZAW")
-
-	formattedAmount := fmt.Sprintf("Rp %.0f", amount)
-	formattedDate := dueDate.Format("02 Jan 2006")
-
-	body := fmt.Sprintf(`
-		<div style="font-family: Arial, sans-serif; padding: 20px; color: #333;">
-			<h2 style="color: #F44336;">Pengingat Tagihan</h2>
-			<p>Halo, <strong>%s</strong>,</p>
-			<p>Ini adalah pengingat untuk tagihan sewa kos Anda yang belum dibayar.</p>
-			<table style="width: 100%%; max-width: 400px; margin: 20px 0; border-collapse: collapse;">
-				<tr>
-					<td style="padding: 8px; border-bottom: 1px solid #ddd;">Total Tagihan</td>
-					<td style="padding: 8px; border-bottom: 1px solid #ddd; font-weight: bold;">%s</td>
-				</tr>
-				<tr>
-					<td style="padding: 8px; border-bottom: 1px solid #ddd;">Jatuh Tempo</td>
-					<td style="padding: 8px; border-bottom: 1px solid #ddd; color: #F44336;">%s</td>
-				</tr>
-			</table>
-			<p>Mohon segera lakukan pembayaran sebelum tanggal jatuh tempo untuk menghindari denda.</p>
-			<div style="margin: 30px 0;">
-				<a href="%s" style="background-color: #2196F3; color: white; padding: 12px 24px; text-decoration: none; border-radius: 4px; font-weight: bold;">Bayar Sekarang</a>
-			</div>
-			<p style="font-size: 12px; color: #888;">Jika Anda sudah melakukan pembayaran, mohon abaikan email ini.</p>
-		</div>
-	`, tenantName, formattedAmount, formattedDate, paymentLink)
-
-	m.SetBody("text/html", body)
-	return s.dialer.DialAndSend(m)
+type LogSender struct {
+	frontendURL string
 }
 
-type LogSender struct{}
-
+// SendResetPasswordEmail tidak menulis token asli ke log; hanya prefix-nya untuk korelasi
 func (s *LogSender) SendResetPasswordEmail(toEmail, token string) error {
 	log.Printf("---------------------------------------------------------")
 	log.Printf("[EMAIL SIMULATION] To: %s", toEmail)
 	log.Printf("[EMAIL SIMULATION] Subject: Reset Password Request")
-	log.Printf("[EMAIL SIMULATION] Token: %s", token)
-	log.Printf("[EMAIL SIMULATION] Link: http://localhost:3000/reset-password?token=%s", token)
+	log.Printf("[EMAIL SIMULATION] Link: %s/reset-password?token=%s", s.frontendURL, redactToken(token))
 	log.Printf("---------------------------------------------------------")
 	return nil
 }
 
-func (s *LogSender) SendPaymentSuccessEmail(toEmail, tenantName string, amount float64, date time.Time) error {
-	log.Printf("---------------------------------------------------------")
-	log.Printf("[EMAIL SIMULATION] To: %s", toEmail)
-	log.Printf("[EMAIL SIMULATION] Subject: Payment Success")
-	log.Printf("[EMAIL SIMULATION] Body: Payment of Rp %.0f received on %s", amount, date.Format("02 Jan 2006"))
-	log.Printf("---------------------------------------------------------")
-	return nil
+func redactToken(token string) string {
+	if len(token) <= 6 {
+		return "[REDACTED]"
+	}
+	return token[:6] + "...[REDACTED]"
 }
 
-func (s *LogSender) SendPaymentReminderEmail(toEmail, tenantName string, amount float64, dueDate time.Time, paymentLink string) error {
+func (s *LogSender) SendEmail(toEmail, subject, htmlBody string) error {
 	log.Printf("---------------------------------------------------------")
 	log.Printf("[EMAIL SIMULATION] To: %s", toEmail)
-	log.Printf("[EMAIL SIMULATION] Subject: Payment Reminder")
-	log.Printf("[EMAIL SIMULATION] Body: Please pay Rp %.0f by %s. Link: %s", amount, dueDate.Format("02 Jan 2006"), paymentLink)
+	log.Printf("[EMAIL SIMULATION] Subject: %s", subject)
+	log.Printf("[EMAIL SIMULATION] Body: %d bytes of HTML", len(htmlBody))
 	log.Printf("---------------------------------------------------------")
 	return nil
 }
 
 // Helper to choose sender
-func NewEmailSender(cfg *config.Config) EmailSender {
+func NewEmailSender(cfg *config.Config, messages *templates.Catalogue) EmailSender {
 	if cfg.SMTPHost != "" && cfg.SMTPEmail != "" && cfg.SMTPPassword != "" {
-		return NewGomailSender(cfg)
+		return NewGomailSender(cfg, messages)
 	}
 	log.Println("SMTP credentials not found, using LogSender (Simulation Mode)")
-	return &LogSender{}
+	return &LogSender{frontendURL: cfg.FrontendURL}
 }
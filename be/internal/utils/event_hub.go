@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// DefaultEventHubSize adalah jumlah event terakhir yang disimpan untuk replay Last-Event-ID
+const DefaultEventHubSize = 500
+
+// EventSubscription adalah satu stream (SSE) milik user
+type EventSubscription struct {
+	UserID  uint
+	IsAdmin bool
+	C       chan DomainEvent
+}
+
+// EventHub menyimpan event terbaru di memori dan meneruskannya ke subscriber (SSE).
+// Menerima event dari publisher lokal dan dari instance lain lewat BroadcastAdapter.
+type EventHub struct {
+	mu          sync.RWMutex
+	buffer      []DomainEvent
+	size        int
+	seen        map[string]bool
+	subscribers map[*EventSubscription]struct{}
+}
+
+func NewEventHub(size int) *EventHub {
+	if size <= 0 {
+		size = DefaultEventHubSize
+	}
+	return &EventHub{
+		size:        size,
+		seen:        make(map[string]bool),
+		subscribers: make(map[*EventSubscription]struct{}),
+	}
+}
+
+// Publish menyimpan event dan mengirimnya ke subscriber yang berhak.
+// Event yang sudah pernah diterima (ID sama) diabaikan.
+func (h *EventHub) Publish(event DomainEvent) {
+	if event.ID == "" {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.seen[event.ID] {
+		return
+	}
+	h.seen[event.ID] = true
+	h.buffer = append(h.buffer, event)
+	if len(h.buffer) > h.size {
+		delete(h.seen, h.buffer[0].ID)
+		h.buffer = h.buffer[1:]
+	}
+
+	for sub := range h.subscribers {
+		if !sub.canSee(event) {
+			continue
+		}
+		select {
+		case sub.C <- event:
+		default:
+			// Client lambat: event di-drop, client bisa reconnect dengan Last-Event-ID
+		}
+	}
+}
+
+// HandleRemoteBroadcast menerima event dari instance lain (lihat SocketServer.OnRemoteBroadcast)
+func (h *EventHub) HandleRemoteBroadcast(msg BroadcastMessage) {
+	var event DomainEvent
+	if err := json.Unmarshal(msg.Payload, &event); err != nil || event.ID == "" || event.Type != msg.Event {
+		return
+	}
+	h.Publish(event)
+}
+
+// Subscribe mendaftarkan stream baru dan mengembalikan event setelah lastEventID untuk di-replay.
+// Jika lastEventID kosong atau sudah tidak ada di buffer, tidak ada replay.
+func (h *EventHub) Subscribe(userID uint, isAdmin bool, lastEventID string) (*EventSubscription, []DomainEvent) {
+	sub := &EventSubscription{
+		UserID:  userID,
+		IsAdmin: isAdmin,
+		C:       make(chan DomainEvent, 32),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var missed []DomainEvent
+	if lastEventID != "" && h.seen[lastEventID] {
+		found := false
+		for _, event := range h.buffer {
+			if found && sub.canSee(event) {
+				missed = append(missed, event)
+			}
+			if event.ID == lastEventID {
+				found = true
+			}
+		}
+	}
+
+	h.subscribers[sub] = struct{}{}
+	return sub, missed
+}
+
+func (h *EventHub) Unsubscribe(sub *EventSubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subscribers, sub)
+}
+
+func (s *EventSubscription) canSee(event DomainEvent) bool {
+	return s.IsAdmin || (event.UserID != 0 && event.UserID == s.UserID)
+}
+
+// MultiEventPublisher meneruskan satu event ke beberapa publisher (socket.io, SSE, dst)
+type MultiEventPublisher []EventPublisher
+
+func (m MultiEventPublisher) Publish(event DomainEvent) {
+	for _, p := range m {
+		p.Publish(event)
+	}
+}
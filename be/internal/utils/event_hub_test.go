@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventHub_ReplaysEventsAfterLastEventID(t *testing.T) {
+	hub := NewEventHub(10)
+
+	first := NewDomainEvent(EventBillCreated, 7, nil)
+	otherTenant := NewDomainEvent(EventBillCreated, 8, nil)
+	second := NewDomainEvent(EventPaymentConfirmed, 7, nil)
+	hub.Publish(first)
+	hub.Publish(otherTenant)
+	hub.Publish(second)
+
+	sub, missed := hub.Subscribe(7, false, first.ID)
+	defer hub.Unsubscribe(sub)
+	assert.Equal(t, []DomainEvent{second}, missed)
+
+	_, adminMissed := hub.Subscribe(1, true, first.ID)
+	assert.Equal(t, []DomainEvent{otherTenant, second}, adminMissed)
+
+	_, unknown := hub.Subscribe(7, false, "evicted-or-unknown")
+	assert.Empty(t, unknown)
+}
+
+func TestEventHub_DeduplicatesRemoteEvents(t *testing.T) {
+	hub := NewEventHub(10)
+	sub, _ := hub.Subscribe(7, false, "")
+	defer hub.Unsubscribe(sub)
+
+	event := NewDomainEvent(EventBookingCancelled, 7, map[string]interface{}{"booking_id": 3})
+	payload, _ := json.Marshal(event)
+
+	// Event yang sama datang lewat room user dan room admin
+	hub.HandleRemoteBroadcast(BroadcastMessage{Origin: "other", Room: "user_7", Event: event.Type, Payload: payload})
+	hub.HandleRemoteBroadcast(BroadcastMessage{Origin: "other", Room: AdminRoom, Event: event.Type, Payload: payload})
+
+	assert.Len(t, sub.C, 1)
+	received := <-sub.C
+	assert.Equal(t, event.ID, received.ID)
+}
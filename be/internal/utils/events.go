@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"time"
+)
+
+// Domain event types yang dikirim ke client secara real-time
+const (
+	EventPaymentConfirmed     = "payment.confirmed"
+	EventPaymentRejected      = "payment.rejected"
+	EventPaymentProofUploaded = "payment.proof_uploaded"
+	EventBookingCreated       = "booking.created"
+	EventBookingCancelled     = "booking.cancelled"
+	EventBillCreated          = "bill.created"
+	EventBillReminder         = "bill.reminder"
+	EventRentChangeNotice     = "bill.rent_change"
+	EventTicketCreated        = "maintenance.created"
+	EventTicketStatusChanged  = "maintenance.status_changed"
+	EventTicketComment        = "maintenance.comment"
+	EventContactReceived      = "contact.received"
+)
+
+// DomainEvent adalah perubahan state yang perlu diketahui tenant dan/atau admin.
+// UserID = 0 berarti event hanya untuk admin.
+type DomainEvent struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	UserID     uint                   `json:"user_id,omitempty"`
+	Data       map[string]interface{} `json:"data"`
+	OccurredAt time.Time              `json:"occurred_at"`
+}
+
+// NewDomainEvent membuat event dengan ID unik dan timestamp sekarang
+func NewDomainEvent(eventType string, userID uint, data map[string]interface{}) DomainEvent {
+	id, err := GenerateSecureToken(12)
+	if err != nil {
+		id = time.Now().Format("20060102150405.000000000")
+	}
+	return DomainEvent{
+		ID:         id,
+		Type:       eventType,
+		UserID:     userID,
+		Data:       data,
+		OccurredAt: time.Now(),
+	}
+}
+
+// EventPublisher is an interface for publishing domain events.
+// Publish dipanggil setelah transaksi commit dan tidak boleh memblokir request.
+type EventPublisher interface {
+	Publish(event DomainEvent)
+}
+
+// SocketEventPublisher mengirim event ke room tenant ("user_<id>") dan room admin
+type SocketEventPublisher struct {
+	socket *SocketServer
+}
+
+func NewSocketEventPublisher(socket *SocketServer) EventPublisher {
+	return &SocketEventPublisher{socket: socket}
+}
+
+func (p *SocketEventPublisher) Publish(event DomainEvent) {
+	if p.socket == nil {
+		return
+	}
+	if event.UserID > 0 {
+		p.socket.BroadcastToUser(event.UserID, event.Type, event)
+	}
+	p.socket.BroadcastToAdmins(event.Type, event)
+}
+
+// NoopEventPublisher dipakai saat real-time tidak aktif (misalnya di CLI atau test)
+type NoopEventPublisher struct{}
+
+func (NoopEventPublisher) Publish(event DomainEvent) {}
@@ -1,130 +1,225 @@
 package utils
 
 import (
-	"bytes"
-	"context"
 	"fmt"
 	"io"
 	"mime/multipart"
-	"os"
-	"path/filepath"
+	"net/url"
+	"path"
 	"strings"
 	"time"
 
-	"github.com/cloudinary/cloudinary-go/v2"
-	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	"github.com/google/uuid"
 )
 
-// UploadToCloudinary uploads a multipart file to Cloudinary and returns the secure CDN URL.
-// Falls back to local storage if CLOUDINARY_URL is not configured.
-// folder is the Cloudinary folder (e.g., "rooms", "profiles", "proofs", "gallery").
-func UploadToCloudinary(fileHeader *multipart.FileHeader, folder string) (string, error) {
-	cloudinaryURL := os.Getenv("CLOUDINARY_URL")
+// Akhiran nama file varian, contoh /rooms/x.jpg -> /rooms/x_md.jpg dan /rooms/x_th.jpg
+const (
+	mediumSuffix    = "_md"
+	thumbnailSuffix = "_th"
+)
+
+var imageContentTypes = map[string]string{".jpg": "image/jpeg", ".png": "image/png"}
+
+// UploadImage memproses gambar dari form multipart (ProcessImage) lalu menyimpannya beserta
+// variannya di GlobalStorage. folder contohnya "rooms", "profiles", "proofs", "gallery";
+// folder privat (STORAGE_PRIVATE_FOLDERS) menghasilkan referensi yang hanya bisa dibuka lewat FileURL.
+func UploadImage(fileHeader *multipart.FileHeader, folder string) (string, error) {
+	buf, err := readUploadedFile(fileHeader)
+	if err != nil {
+		return "", err
+	}
+	return UploadBytes(buf, folder)
+}
 
-	// Fallback to local if Cloudinary not configured
-	if cloudinaryURL == "" {
-		return SaveUploadedFile(fileHeader, folder)
+// UploadBytes seperti UploadImage untuk gambar yang tidak berasal dari form multipart
+// (misalnya gambar yang diunduh dari pesan WhatsApp). Format file ditentukan dari isinya.
+func UploadBytes(data []byte, folder string) (string, error) {
+	processed, err := ProcessImage(data)
+	if err != nil {
+		return "", err
 	}
+	return storeProcessedImage(GlobalStorage, processed, folder)
+}
 
+func readUploadedFile(fileHeader *multipart.FileHeader) ([]byte, error) {
+	if fileHeader.Size > MaxImageBytes {
+		return nil, fmt.Errorf("%w: maksimal %d MB", ErrImageTooLarge, MaxImageBytes>>20)
+	}
 	src, err := fileHeader.Open()
 	if err != nil {
-		return "", fmt.Errorf("failed to open uploaded file: %v", err)
+		return nil, fmt.Errorf("failed to open uploaded file: %v", err)
 	}
 	defer src.Close()
 
-	// Read file into buffer
-	buf, err := io.ReadAll(src)
+	// Batas dibaca ulang dari isi file karena Size berasal dari klien
+	buf, err := io.ReadAll(io.LimitReader(src, MaxImageBytes+1))
 	if err != nil {
-		return "", fmt.Errorf("failed to read file: %v", err)
+		return nil, fmt.Errorf("failed to read file: %v", err)
 	}
+	return buf, nil
+}
 
-	// Initialize Cloudinary
-	cld, err := cloudinary.NewFromURL(cloudinaryURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to init cloudinary: %v", err)
-	}
-	cld.Config.URL.Secure = true
-
-	// Generate unique public ID
-	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
-	publicID := fmt.Sprintf("%s/%s_%s", folder, time.Now().Format("20060102150405"), uuid.New().String()[:8])
-	_ = ext // Cloudinary auto-detects format
-
-	ctx := context.Background()
-	uploadResult, err := cld.Upload.Upload(ctx, bytes.NewReader(buf), uploader.UploadParams{
-		PublicID:     publicID,
-		Folder:       folder,
-		ResourceType: "image",
-		// Auto quality and format for optimal performance
-		Transformation: "q_auto,f_auto",
-	})
+// storeProcessedImage menyimpan gambar asli lalu varian medium/thumbnail dengan akhiran _md/_th.
+// Varian selalu ditulis supaya VariantRef tidak perlu mengecek keberadaannya (gambar asli dipakai
+// hanya jika sudah muat di ukuran varian), kecuali gambar publik di storage yang membuat varian sendiri (Cloudinary).
+func storeProcessedImage(storage Storage, processed *ProcessedImage, folder string) (string, error) {
+	private := IsPrivateFolder(folder)
+	key := fmt.Sprintf("%s/%s_%s%s", folder, time.Now().Format("20060102150405"), uuid.New().String()[:8], processed.Ext)
+	contentType := imageContentTypes[processed.Ext]
+
+	variants := []struct {
+		suffix string
+		size   int
+		data   []byte
+	}{
+		{mediumSuffix, MediumImageSize, processed.Medium},
+		{thumbnailSuffix, ThumbnailImageSize, processed.Thumbnail},
+	}
+	for i, variant := range variants {
+		if variant.data != nil {
+			continue
+		}
+		if processed.Width > variant.size || processed.Height > variant.size {
+			return "", fmt.Errorf("missing %dpx variant for %dx%d image", variant.size, processed.Width, processed.Height)
+		}
+		variants[i].data = processed.Original
+	}
+
+	ref, err := storage.Put(key, processed.Original, contentType, private)
 	if err != nil {
-		return "", fmt.Errorf("cloudinary upload failed: %v", err)
+		return "", err
+	}
+	if transformer, ok := storage.(imageTransformer); ok && transformer.transformsPublicImages() && !private {
+		return ref, nil
 	}
 
-	return uploadResult.SecureURL, nil
+	for _, variant := range variants {
+		if _, err := storage.Put(variantURL(key, variant.suffix), variant.data, contentType, private); err != nil {
+			return "", err
+		}
+	}
+	return ref, nil
 }
 
-// SaveUploadedFile saves a multipart file to the specified local folder path
-// and returns the relative URL to access it (e.g., /folder/filename.jpg).
-// Use UploadToCloudinary instead for production — this is a local-only fallback.
-func SaveUploadedFile(fileHeader *multipart.FileHeader, folder string) (string, error) {
-	src, err := fileHeader.Open()
-	if err != nil {
-		return "", fmt.Errorf("failed to open uploaded file: %v", err)
+// ImageVariantURLs mengembalikan URL asli/medium/thumbnail untuk klien dari referensi file.
+// Cloudinary publik memakai transformasi URL; storage lain memakai file _md/_th jika ada
+// (upload lama tanpa varian dan URL eksternal memakai URL aslinya). File privat mendapat signed URL.
+func ImageVariantURLs(ref string) ImageVariants {
+	variants := ImageVariants{Original: ref, Medium: ref, Thumbnail: ref}
+	if ref == "" {
+		return variants
+	}
+	if _, ok := cloudinaryPublicID(ref); ok {
+		variants.Medium = cloudinaryTransform(ref, fmt.Sprintf("c_limit,w_%d,h_%d,q_auto,f_auto", MediumImageSize, MediumImageSize))
+		variants.Thumbnail = cloudinaryTransform(ref, fmt.Sprintf("c_limit,w_%d,h_%d,q_auto,f_auto", ThumbnailImageSize, ThumbnailImageSize))
+		return variants
+	}
+	if medium, ok := GlobalStorage.VariantRef(ref, mediumSuffix); ok {
+		variants.Medium = medium
+	}
+	if thumbnail, ok := GlobalStorage.VariantRef(ref, thumbnailSuffix); ok {
+		variants.Thumbnail = thumbnail
 	}
-	defer src.Close()
 
-	uploadDir := filepath.Join("public", folder)
-	if err := os.MkdirAll(uploadDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create upload directory: %v", err)
+	if IsPrivateRef(ref) {
+		for _, target := range []*string{&variants.Original, &variants.Medium, &variants.Thumbnail} {
+			signed, err := FileURL(*target)
+			if err != nil {
+				GlobalLogger.Error("Failed to sign file URL %s: %v", *target, err)
+				continue
+			}
+			*target = signed
+		}
 	}
+	return variants
+}
 
-	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
-	if ext == "" {
-		ext = ".jpg"
+// ImageVariantsOf seperti ImageVariantURLs untuk beberapa referensi sekaligus (urutannya sama)
+func ImageVariantsOf(refs []string) []ImageVariants {
+	variants := make([]ImageVariants, 0, len(refs))
+	for _, ref := range refs {
+		variants = append(variants, ImageVariantURLs(ref))
 	}
+	return variants
+}
 
-	newFileName := fmt.Sprintf("%s_%s%s", time.Now().Format("20060102150405"), uuid.New().String()[:8], ext)
-	dstPath := filepath.Join(uploadDir, newFileName)
+func variantURL(fileURL, suffix string) string {
+	ext := path.Ext(fileURL)
+	return strings.TrimSuffix(fileURL, ext) + suffix + ext
+}
 
-	dst, err := os.Create(dstPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to create destination file: %v", err)
-	}
-	defer dst.Close()
+func cloudinaryTransform(fileURL, transformation string) string {
+	before, after, _ := strings.Cut(fileURL, "/upload/")
+	return before + "/upload/" + transformation + "/" + after
+}
 
-	if _, err := io.Copy(dst, src); err != nil {
-		return "", fmt.Errorf("failed to save file: %v", err)
+// DeleteUploadedFile menghapus file hasil UploadImage/UploadBytes beserta variannya dari GlobalStorage.
+// URL Cloudinary lama tetap dihapus lewat CLOUDINARY_URL dari config (lihat InitStorage) walaupun
+// storage aktif bukan Cloudinary; URL eksternal lain diabaikan.
+func DeleteUploadedFile(ref string) error {
+	storage := GlobalStorage
+	if _, ok := cloudinaryPublicID(ref); ok {
+		if _, isCloudinary := storage.(*CloudinaryStorage); !isCloudinary {
+			if legacyCloudinary == nil {
+				return fmt.Errorf("cannot delete %s: CLOUDINARY_URL is not configured", ref)
+			}
+			storage = legacyCloudinary
+		}
 	}
 
-	return fmt.Sprintf("/%s/%s", folder, newFileName), nil
+	refs := []string{ref}
+	for _, suffix := range []string{mediumSuffix, thumbnailSuffix} {
+		if variant, ok := storage.VariantRef(ref, suffix); ok {
+			refs = append(refs, variant)
+		}
+	}
+	for _, target := range refs {
+		if err := storage.Delete(target); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// DeleteLocalFile removes a file from local disk given its relative URL.
-// For Cloudinary URLs, this is a no-op (deletion handled separately if needed).
-func DeleteLocalFile(fileURL string) error {
-	if fileURL == "" {
-		return nil
+// cloudinaryPublicID mengambil public ID dari URL Cloudinary
+// (https://res.cloudinary.com/<cloud>/image/upload/[transformasi/]v1712345678/<public_id>.<ext>)
+func cloudinaryPublicID(fileURL string) (string, bool) {
+	u, err := url.Parse(fileURL)
+	if err != nil || !strings.HasSuffix(u.Hostname(), "cloudinary.com") {
+		return "", false
 	}
-	// Skip Cloudinary URLs
-	if strings.HasPrefix(fileURL, "http://") || strings.HasPrefix(fileURL, "https://") {
-		return nil
+	_, rest, ok := strings.Cut(u.Path, "/upload/")
+	if !ok {
+		return "", false
 	}
 
-	relativePath := strings.TrimPrefix(fileURL, "/")
-	fullPath := filepath.Join("public", relativePath)
-
-	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
-		return nil
+	// Public ID dimulai setelah segmen versi; tanpa versi dianggap tanpa transformasi
+	segments := strings.Split(rest, "/")
+	for i, segment := range segments {
+		if len(segment) > 1 && segment[0] == 'v' && strings.Trim(segment[1:], "0123456789") == "" {
+			segments = segments[i+1:]
+			break
+		}
 	}
-
-	return os.Remove(fullPath)
+	publicID := strings.Join(segments, "/")
+	publicID = strings.TrimSuffix(publicID, path.Ext(publicID))
+	return publicID, publicID != ""
 }
 
-// IsImageFile validates if an uploaded file is an image by checking its MIME type
+// IsImageFile validates if an uploaded file is an image by sniffing its magic bytes;
+// the client-supplied Content-Type header is not trusted.
 func IsImageFile(file *multipart.FileHeader) bool {
-	contentType := file.Header.Get("Content-Type")
-	return strings.HasPrefix(contentType, "image/")
+	src, err := file.Open()
+	if err != nil {
+		return false
+	}
+	defer src.Close()
+
+	header := make([]byte, 512)
+	n, err := io.ReadFull(src, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return false
+	}
+	_, ok := SniffImageType(header[:n])
+	return ok
 }
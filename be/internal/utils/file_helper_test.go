@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudinaryPublicID(t *testing.T) {
+	tests := []struct {
+		url      string
+		publicID string
+		ok       bool
+	}{
+		{"https://res.cloudinary.com/demo/image/upload/v1712345678/rooms/20260101_ab12cd34.jpg", "rooms/20260101_ab12cd34", true},
+		{"https://res.cloudinary.com/demo/image/upload/q_auto,f_auto/v1712345678/rooms/rooms/a.webp", "rooms/rooms/a", true},
+		{"https://res.cloudinary.com/demo/image/upload/gallery/b.png", "gallery/b", true},
+		{"/rooms/20260101_ab12cd34.jpg", "", false},
+		{"https://example.com/image/upload/v1/a.jpg", "", false},
+	}
+	for _, tt := range tests {
+		publicID, ok := cloudinaryPublicID(tt.url)
+		assert.Equal(t, tt.ok, ok, tt.url)
+		assert.Equal(t, tt.publicID, publicID, tt.url)
+	}
+}
+
+func TestDeleteUploadedFile_RemovesLocalFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.MkdirAll(filepath.Join("public", "rooms"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join("public", "rooms", "a.jpg"), []byte("x"), 0644))
+
+	require.NoError(t, DeleteUploadedFile("/rooms/a.jpg"))
+	_, err := os.Stat(filepath.Join("public", "rooms", "a.jpg"))
+	assert.True(t, os.IsNotExist(err))
+
+	// File yang sudah tidak ada dan URL eksternal tidak dianggap error
+	assert.NoError(t, DeleteUploadedFile("/rooms/a.jpg"))
+	assert.NoError(t, DeleteUploadedFile("https://images.example.com/a.jpg"))
+}
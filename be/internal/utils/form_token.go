@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidFormToken = errors.New("form token tidak valid")
+
+// GenerateFormToken membuat token "<unix milidetik>.<hmac>" saat form publik ditampilkan.
+// Waktu terbitnya ditandatangani server, jadi klien tidak bisa memajukan waktu mulai mengisi form.
+func GenerateFormToken(issuedAt time.Time, secret string) string {
+	millis := strconv.FormatInt(issuedAt.UnixMilli(), 10)
+	return millis + "." + formTokenSignature(millis, secret)
+}
+
+// ParseFormToken memeriksa tanda tangan token lalu mengembalikan waktu terbitnya
+func ParseFormToken(token, secret string) (time.Time, error) {
+	millis, signature, ok := strings.Cut(token, ".")
+	if !ok || millis == "" || signature == "" {
+		return time.Time{}, ErrInvalidFormToken
+	}
+	if !hmac.Equal([]byte(signature), []byte(formTokenSignature(millis, secret))) {
+		return time.Time{}, ErrInvalidFormToken
+	}
+	issued, err := strconv.ParseInt(millis, 10, 64)
+	if err != nil || issued <= 0 {
+		return time.Time{}, ErrInvalidFormToken
+	}
+	return time.UnixMilli(issued), nil
+}
+
+func formTokenSignature(millis, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "form:%s", millis)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormToken_RoundTrip(t *testing.T) {
+	issuedAt := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	token := GenerateFormToken(issuedAt, "secret")
+
+	parsed, err := ParseFormToken(token, "secret")
+	require.NoError(t, err)
+	assert.True(t, issuedAt.Equal(parsed))
+}
+
+func TestFormToken_RejectsTamperedTokens(t *testing.T) {
+	issuedAt := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	token := GenerateFormToken(issuedAt, "secret")
+	_, signature, _ := strings.Cut(token, ".")
+	earlier := GenerateFormToken(issuedAt.Add(-time.Hour), "secret")
+	earlierMillis, _, _ := strings.Cut(earlier, ".")
+
+	for _, tampered := range []string{
+		"",
+		"1760864400000",
+		earlierMillis + "." + signature, // waktu dimundurkan, tanda tangan lama
+		GenerateFormToken(issuedAt, "other-secret"),
+		"abc." + signature,
+	} {
+		_, err := ParseFormToken(tampered, "secret")
+		assert.ErrorIs(t, err, ErrInvalidFormToken, tampered)
+	}
+}
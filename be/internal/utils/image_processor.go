@@ -0,0 +1,272 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // registrasi decoder GIF untuk image.Decode
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	_ "golang.org/x/image/webp" // registrasi decoder WebP untuk image.Decode
+)
+
+var (
+	ErrUnsupportedImage = errors.New("file harus berupa gambar JPEG, PNG, GIF, atau WebP")
+	ErrImageTooLarge    = errors.New("ukuran gambar melebihi batas")
+)
+
+const (
+	MaxImageBytes     = 10 << 20 // 10 MB per file
+	MaxImageDimension = 8000     // sisi terpanjang, piksel
+	maxImagePixels    = 40_000_000
+
+	MediumImageSize    = 1024
+	ThumbnailImageSize = 320
+	jpegQuality        = 85
+)
+
+// ImageVariants berisi URL foto asli beserta versi kecilnya; jika versi kecil tidak ada, URL-nya sama dengan Original
+type ImageVariants struct {
+	Original  string `json:"original"`
+	Medium    string `json:"medium"`
+	Thumbnail string `json:"thumbnail"`
+}
+
+// ProcessedImage adalah hasil ProcessImage. Semua byte sudah di-encode ulang tanpa metadata (EXIF/GPS);
+// Medium dan Thumbnail nil jika gambar aslinya sudah lebih kecil dari ukuran varian.
+type ProcessedImage struct {
+	Ext           string // ".jpg" atau ".png"
+	Width, Height int
+	Original      []byte
+	Medium        []byte
+	Thumbnail     []byte
+}
+
+// SniffImageType mendeteksi jenis gambar dari magic bytes, bukan dari Content-Type kiriman klien
+func SniffImageType(data []byte) (string, bool) {
+	switch contentType := http.DetectContentType(data); contentType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return contentType, true
+	default:
+		return contentType, false
+	}
+}
+
+// ProcessImage memvalidasi dan membersihkan gambar upload: JPEG tetap JPEG, PNG/GIF menjadi PNG,
+// WebP menjadi JPEG (PNG jika ada transparansi), orientasi EXIF JPEG diterapkan ke piksel lalu
+// metadata dibuang, dan varian medium/thumbnail dibuat.
+func ProcessImage(data []byte) (*ProcessedImage, error) {
+	if len(data) > MaxImageBytes {
+		return nil, fmt.Errorf("%w: maksimal %d MB", ErrImageTooLarge, MaxImageBytes>>20)
+	}
+	contentType, ok := SniffImageType(data)
+	if !ok {
+		return nil, ErrUnsupportedImage
+	}
+	// Cek dimensi dari header sebelum decode supaya gambar raksasa tidak sempat memenuhi memori
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if err := checkDimensions(config.Width, config.Height); err != nil {
+		return nil, err
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+
+	img := toRGBA(decoded)
+	ext := ".png"
+	switch {
+	case contentType == "image/jpeg":
+		ext = ".jpg"
+		img = applyOrientation(img, jpegOrientation(data))
+	case contentType == "image/webp" && img.Opaque():
+		ext = ".jpg"
+	}
+
+	result := &ProcessedImage{Ext: ext, Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}
+	if result.Original, err = encodeImage(img, ext); err != nil {
+		return nil, err
+	}
+	if medium := resizeToFit(img, MediumImageSize); medium != nil {
+		if result.Medium, err = encodeImage(medium, ext); err != nil {
+			return nil, err
+		}
+	}
+	if thumbnail := resizeToFit(img, ThumbnailImageSize); thumbnail != nil {
+		if result.Thumbnail, err = encodeImage(thumbnail, ext); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func checkDimensions(width, height int) error {
+	if width <= 0 || height <= 0 {
+		return ErrUnsupportedImage
+	}
+	if width > MaxImageDimension || height > MaxImageDimension || width*height > maxImagePixels {
+		return fmt.Errorf("%w: maksimal %dx%d piksel", ErrImageTooLarge, MaxImageDimension, MaxImageDimension)
+	}
+	return nil
+}
+
+func encodeImage(img image.Image, ext string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if ext == ".jpg" {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		err = (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode image: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func toRGBA(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
+
+// resizeToFit mengecilkan gambar (rata-rata area) agar sisi terpanjangnya maxSize; nil jika sudah cukup kecil
+func resizeToFit(src *image.RGBA, maxSize int) *image.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	if w <= maxSize && h <= maxSize {
+		return nil
+	}
+	dw, dh := maxSize, max(1, h*maxSize/w)
+	if h > w {
+		dw, dh = max(1, w*maxSize/h), maxSize
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*h/dh, max((y+1)*h/dh, y*h/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*w/dw, max((x+1)*w/dw, x*w/dw+1)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[src.PixOffset(x0, sy) : src.PixOffset(x1-1, sy)+4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			offset := dst.PixOffset(x, y)
+			for c := range sum {
+				dst.Pix[offset+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return dst
+}
+
+// applyOrientation memutar/membalik piksel sesuai tag Orientation EXIF (1-8) supaya foto HP tetap tegak setelah EXIF dibuang
+func applyOrientation(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // cermin horizontal
+				dx, dy = w-1-x, y
+			case 3: // putar 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // cermin vertikal
+				dx, dy = x, h-1-y
+			case 5: // transpose
+				dx, dy = y, x
+			case 6: // putar 90 searah jarum jam
+				dx, dy = h-1-y, x
+			case 7: // transverse
+				dx, dy = h-1-y, w-1-x
+			case 8: // putar 90 berlawanan jarum jam
+				dx, dy = y, w-1-x
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):dst.PixOffset(dx, dy)+4], src.Pix[src.PixOffset(x, y):src.PixOffset(x, y)+4])
+		}
+	}
+	return dst
+}
+
+// jpegOrientation membaca tag Orientation (0x0112) dari segmen APP1 Exif; 1 jika tidak ada
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 { // start of scan / end of image: metadata sudah lewat
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return 1
+		}
+		if marker == 0xE1 {
+			if orientation := exifOrientation(data[i+4 : i+2+size]); orientation > 0 {
+				return orientation
+			}
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+func exifOrientation(segment []byte) int {
+	if len(segment) < 14 || string(segment[:6]) != "Exif\x00\x00" {
+		return 0
+	}
+	tiff := segment[6:]
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for k := 0; k < entries; k++ {
+		entry := ifd + 2 + k*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 0
+}
@@ -0,0 +1,279 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"mime/multipart"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testJPEG membuat JPEG w x h dengan piksel kiri atas merah, ditambah segmen EXIF berisi orientasi dan "GPS"
+func testJPEG(t *testing.T, w, h int, orientation uint16) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{0, 0, 255, 255})
+		}
+	}
+	for y := 0; y < h/4; y++ {
+		for x := 0; x < w/4; x++ {
+			img.Set(x, y, color.RGBA{255, 0, 0, 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}))
+
+	tiff := []byte("II*\x00\x08\x00\x00\x00\x01\x00")
+	entry := make([]byte, 12)
+	binary.LittleEndian.PutUint16(entry[0:], 0x0112)
+	binary.LittleEndian.PutUint16(entry[2:], 3)
+	binary.LittleEndian.PutUint32(entry[4:], 1)
+	binary.LittleEndian.PutUint16(entry[8:], orientation)
+	tiff = append(tiff, entry...)
+	tiff = append(tiff, []byte("\x00\x00\x00\x00GPS-LAT-6.2088-LNG-106.8456")...)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), app1...), data[2:]...)
+}
+
+func TestSniffImageType(t *testing.T) {
+	_, ok := SniffImageType(testJPEG(t, 8, 8, 1))
+	assert.True(t, ok)
+	_, ok = SniffImageType([]byte("<?php echo 'not an image'; ?>"))
+	assert.False(t, ok)
+}
+
+func TestProcessImage_StripsExifAndAppliesOrientation(t *testing.T) {
+	data := testJPEG(t, 1600, 800, 6)
+	require.Equal(t, 6, jpegOrientation(data))
+
+	processed, err := ProcessImage(data)
+	require.NoError(t, err)
+	assert.Equal(t, ".jpg", processed.Ext)
+	assert.NotContains(t, string(processed.Original), "GPS-LAT")
+	assert.Equal(t, 1, jpegOrientation(processed.Original))
+
+	// Diputar 90 derajat: tegak 800x1600, area merah pindah ke kanan atas
+	img, err := jpeg.Decode(bytes.NewReader(processed.Original))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 800, 1600), img.Bounds())
+	r, _, b, _ := img.At(790, 10).RGBA()
+	assert.Greater(t, r, b)
+
+	medium, err := jpeg.Decode(bytes.NewReader(processed.Medium))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 512, MediumImageSize), medium.Bounds())
+	thumbnail, err := jpeg.Decode(bytes.NewReader(processed.Thumbnail))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 160, ThumbnailImageSize), thumbnail.Bounds())
+}
+
+func TestProcessImage_SmallPNGHasNoVariants(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 200, 100))))
+
+	processed, err := ProcessImage(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, ".png", processed.Ext)
+	assert.Nil(t, processed.Medium)
+	assert.Nil(t, processed.Thumbnail)
+}
+
+func TestProcessImage_RejectsInvalidInput(t *testing.T) {
+	_, err := ProcessImage([]byte("\xFF\xD8\xFF<?php system($_GET['c']); ?>"))
+	assert.ErrorIs(t, err, ErrUnsupportedImage)
+
+	_, err = ProcessImage(make([]byte, MaxImageBytes+1))
+	assert.ErrorIs(t, err, ErrImageTooLarge)
+
+	// Header PNG 9000x9000 ditolak sebelum di-decode
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))))
+	data := buf.Bytes()
+	binary.BigEndian.PutUint32(data[16:], 9000)
+	binary.BigEndian.PutUint32(data[20:], 9000)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	_, err = ProcessImage(data)
+	assert.ErrorIs(t, err, ErrImageTooLarge)
+}
+
+// testWebP membuat WebP lossless (VP8L) w x h satu warna, dibungkus VP8X dengan chunk EXIF berisi "GPS".
+// Tiap kode prefix memakai simple code satu simbol sehingga data pikselnya tidak butuh bit sama sekali.
+func testWebP(t *testing.T, w, h int, c color.NRGBA) []byte {
+	var bits []byte
+	var acc uint64
+	var n uint
+	write := func(value uint64, width uint) {
+		acc |= value << n
+		for n += width; n >= 8; n -= 8 {
+			bits = append(bits, byte(acc))
+			acc >>= 8
+		}
+	}
+	alphaUsed := uint64(0)
+	if c.A != 0xFF {
+		alphaUsed = 1
+	}
+	write(0x2f, 8)
+	write(uint64(w-1), 14)
+	write(uint64(h-1), 14)
+	write(alphaUsed, 1)
+	write(0, 3) // versi
+	write(0, 1) // tanpa transform
+	write(0, 1) // tanpa color cache
+	write(0, 1) // tanpa meta prefix code
+	for _, symbol := range []uint8{c.G, c.R, c.B, c.A, 0} {
+		write(1, 1) // simple code
+		write(0, 1) // satu simbol
+		write(1, 1) // simbol 8 bit
+		write(uint64(symbol), 8)
+	}
+	if n > 0 {
+		bits = append(bits, byte(acc))
+	}
+
+	chunk := func(fourCC string, payload []byte) []byte {
+		header := make([]byte, 8)
+		copy(header, fourCC)
+		binary.LittleEndian.PutUint32(header[4:], uint32(len(payload)))
+		out := append(header, payload...)
+		if len(payload)%2 == 1 {
+			out = append(out, 0)
+		}
+		return out
+	}
+	vp8x := make([]byte, 10)
+	vp8x[0] = 0x08 // flag EXIF
+	vp8x[4], vp8x[5], vp8x[6] = byte(w-1), byte((w-1)>>8), byte((w-1)>>16)
+	vp8x[7], vp8x[8], vp8x[9] = byte(h-1), byte((h-1)>>8), byte((h-1)>>16)
+
+	body := []byte("WEBP")
+	body = append(body, chunk("VP8X", vp8x)...)
+	body = append(body, chunk("VP8L", bits)...)
+	body = append(body, chunk("EXIF", []byte("GPS-LAT-6.2088"))...)
+	data := append([]byte("RIFF\x00\x00\x00\x00"), body...)
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(body)))
+	return data
+}
+
+func TestProcessImage_WebPReencodedAsJPEGWithVariants(t *testing.T) {
+	processed, err := ProcessImage(testWebP(t, 1600, 800, color.NRGBA{R: 200, G: 40, B: 10, A: 0xFF}))
+	require.NoError(t, err)
+	assert.Equal(t, ".jpg", processed.Ext)
+	assert.Equal(t, 1600, processed.Width)
+	assert.Equal(t, 800, processed.Height)
+	assert.NotContains(t, string(processed.Original), "GPS-LAT")
+
+	medium, err := jpeg.Decode(bytes.NewReader(processed.Medium))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, MediumImageSize, MediumImageSize/2), medium.Bounds())
+	r, g, _, _ := medium.At(10, 10).RGBA()
+	assert.InDelta(t, 200, r>>8, 8)
+	assert.InDelta(t, 40, g>>8, 8)
+
+	thumbnail, err := jpeg.Decode(bytes.NewReader(processed.Thumbnail))
+	require.NoError(t, err)
+	assert.Equal(t, ThumbnailImageSize, thumbnail.Bounds().Dx())
+}
+
+func TestProcessImage_TransparentWebPBecomesPNG(t *testing.T) {
+	processed, err := ProcessImage(testWebP(t, 64, 32, color.NRGBA{R: 255, A: 0x80}))
+	require.NoError(t, err)
+	assert.Equal(t, ".png", processed.Ext)
+	assert.Nil(t, processed.Medium)
+
+	img, err := png.Decode(bytes.NewReader(processed.Original))
+	require.NoError(t, err)
+	_, _, _, a := img.At(0, 0).RGBA()
+	assert.InDelta(t, 0x80, a>>8, 1)
+}
+
+func TestProcessImage_RejectsCorruptWebP(t *testing.T) {
+	data := testWebP(t, 64, 32, color.NRGBA{A: 0xFF})
+	_, err := ProcessImage(data[:len(data)-30])
+	assert.ErrorIs(t, err, ErrUnsupportedImage)
+}
+
+// multipartFile membuat FileHeader seperti hasil c.FormFile dengan Content-Type kiriman klien
+func multipartFile(t *testing.T, name, contentType string, data []byte) *multipart.FileHeader {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	header := make(map[string][]string)
+	header["Content-Disposition"] = []string{`form-data; name="image"; filename="` + name + `"`}
+	header["Content-Type"] = []string{contentType}
+	part, err := writer.CreatePart(header)
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest("POST", "/", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	require.NoError(t, req.ParseMultipartForm(MaxImageBytes))
+	return req.MultipartForm.File["image"][0]
+}
+
+func TestIsImageFile_IgnoresClientContentType(t *testing.T) {
+	assert.False(t, IsImageFile(multipartFile(t, "shell.jpg", "image/jpeg", []byte("<?php system($_GET['c']); ?>"))))
+	assert.True(t, IsImageFile(multipartFile(t, "photo.bin", "application/octet-stream", testJPEG(t, 8, 8, 1))))
+}
+
+func TestUploadImage_WritesVariantsAndDeleteRemovesThem(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	url, err := UploadImage(multipartFile(t, "room.jpeg", "image/jpeg", testJPEG(t, 1200, 600, 1)), "rooms")
+	require.NoError(t, err)
+
+	variants := ImageVariantURLs(url)
+	assert.Equal(t, url, variants.Original)
+	assert.Equal(t, variantURL(url, mediumSuffix), variants.Medium)
+	assert.Equal(t, variantURL(url, thumbnailSuffix), variants.Thumbnail)
+
+	require.NoError(t, DeleteUploadedFile(url))
+	for _, fileURL := range []string{variants.Original, variants.Medium, variants.Thumbnail} {
+		_, err := os.Stat(filepath.Join("public", fileURL))
+		assert.True(t, os.IsNotExist(err), fileURL)
+	}
+	// Upload lama tanpa file varian memakai URL aslinya
+	assert.Equal(t, ImageVariants{"/rooms/old.jpg", "/rooms/old.jpg", "/rooms/old.jpg"}, ImageVariantURLs("/rooms/old.jpg"))
+}
+
+func TestStoreProcessedImage_CopiesOriginalOnlyWhenItFitsTheVariant(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewLocalStorage(filepath.Join(dir, "public"), filepath.Join(dir, "private"), []byte("secret"))
+
+	small := &ProcessedImage{Ext: ".png", Width: 600, Height: 300, Original: []byte("original"), Thumbnail: []byte("thumbnail")}
+	ref, err := storeProcessedImage(storage, small, "rooms")
+	require.NoError(t, err)
+	medium, err := os.ReadFile(filepath.Join(dir, "public", filepath.FromSlash(variantURL(ref, mediumSuffix))))
+	require.NoError(t, err)
+	assert.Equal(t, "original", string(medium))
+
+	large := &ProcessedImage{Ext: ".png", Width: 2000, Height: 300, Original: []byte("original"), Thumbnail: []byte("thumbnail")}
+	_, err = storeProcessedImage(storage, large, "gallery")
+	assert.Error(t, err)
+	_, err = os.Stat(filepath.Join(dir, "public", "gallery"))
+	assert.True(t, os.IsNotExist(err), "gambar asli tidak boleh tersimpan jika variannya gagal")
+}
+
+func TestImageVariantURLs_Cloudinary(t *testing.T) {
+	variants := ImageVariantURLs("https://res.cloudinary.com/demo/image/upload/v1712345678/rooms/a.jpg")
+	assert.Equal(t, "https://res.cloudinary.com/demo/image/upload/c_limit,w_1024,h_1024,q_auto,f_auto/v1712345678/rooms/a.jpg", variants.Medium)
+	assert.Equal(t, "https://res.cloudinary.com/demo/image/upload/c_limit,w_320,h_320,q_auto,f_auto/v1712345678/rooms/a.jpg", variants.Thumbnail)
+}
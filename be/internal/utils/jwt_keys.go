@@ -0,0 +1,414 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Algoritma JWT yang didukung. HS256 sengaja tidak termasuk.
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// keyManifestFile menyimpan daftar kid, algoritma, dan kid yang aktif untuk signing.
+// Private key masing-masing disimpan terpisah sebagai <kid>.pem.
+const keyManifestFile = "keys.json"
+
+// SigningKey adalah satu pasangan kunci JWT beserta metadatanya
+type SigningKey struct {
+	KID       string
+	Algorithm string
+	CreatedAt time.Time
+	RetiredAt *time.Time
+	private   crypto.Signer
+}
+
+// Public mengembalikan public key untuk verifikasi
+func (k *SigningKey) Public() crypto.PublicKey {
+	return k.private.Public()
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+	if k.Algorithm == AlgRS256 {
+		return jwt.SigningMethodRS256
+	}
+	return jwt.SigningMethodEdDSA
+}
+
+type keyManifest struct {
+	ActiveKID string             `json:"active_kid"`
+	Keys      []keyManifestEntry `json:"keys"`
+}
+
+type keyManifestEntry struct {
+	KID       string     `json:"kid"`
+	Algorithm string     `json:"alg"`
+	CreatedAt time.Time  `json:"created_at"`
+	RetiredAt *time.Time `json:"retired_at,omitempty"`
+}
+
+// KeySet menyimpan semua kunci verifikasi yang masih berlaku dan satu kunci aktif untuk signing.
+// Selama rotasi, token yang ditandatangani kunci lama tetap valid sampai kunci itu di-prune.
+type KeySet struct {
+	mu        sync.RWMutex
+	dir       string
+	activeKID string
+	keys      map[string]*SigningKey
+}
+
+// LoadKeySet membaca manifest dan private key dari direktori
+func LoadKeySet(dir string) (*KeySet, error) {
+	ks := &KeySet{dir: dir}
+	if err := ks.Reload(); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// EnsureKeySet memuat key set dari direktori, atau membuat kunci pertama jika belum ada
+func EnsureKeySet(dir, algorithm string) (*KeySet, error) {
+	if _, err := os.Stat(filepath.Join(dir, keyManifestFile)); errors.Is(err, os.ErrNotExist) {
+		GlobalLogger.Warn("JWT key manifest not found in %s, generating initial %s key", dir, algorithm)
+		if _, err := RotateKeys(dir, algorithm); err != nil {
+			return nil, err
+		}
+	}
+	return LoadKeySet(dir)
+}
+
+// NewEphemeralKeySet membuat key set in-memory (tidak disimpan ke disk), untuk testing
+func NewEphemeralKeySet(algorithm string) (*KeySet, error) {
+	key, err := generateSigningKey(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return &KeySet{
+		activeKID: key.KID,
+		keys:      map[string]*SigningKey{key.KID: key},
+	}, nil
+}
+
+// Reload membaca ulang direktori kunci, sehingga hasil rotasi dari CLI terambil tanpa restart
+func (ks *KeySet) Reload() error {
+	if ks.dir == "" {
+		return nil
+	}
+
+	manifest, err := readKeyManifest(ks.dir)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*SigningKey, len(manifest.Keys))
+	for _, entry := range manifest.Keys {
+		signer, err := readPrivateKey(filepath.Join(ks.dir, entry.KID+".pem"))
+		if err != nil {
+			return fmt.Errorf("failed to load key %s: %v", entry.KID, err)
+		}
+		if err := checkKeyAlgorithm(signer, entry.Algorithm); err != nil {
+			return fmt.Errorf("key %s: %v", entry.KID, err)
+		}
+		keys[entry.KID] = &SigningKey{
+			KID:       entry.KID,
+			Algorithm: entry.Algorithm,
+			CreatedAt: entry.CreatedAt,
+			RetiredAt: entry.RetiredAt,
+			private:   signer,
+		}
+	}
+
+	if _, ok := keys[manifest.ActiveKID]; !ok {
+		return fmt.Errorf("active key %q not found in %s", manifest.ActiveKID, ks.dir)
+	}
+
+	ks.mu.Lock()
+	ks.activeKID = manifest.ActiveKID
+	ks.keys = keys
+	ks.mu.Unlock()
+	return nil
+}
+
+// ActiveKey mengembalikan kunci yang dipakai untuk menandatangani token baru
+func (ks *KeySet) ActiveKey() *SigningKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.keys[ks.activeKID]
+}
+
+// Sign menandatangani claims dengan kunci aktif dan menambahkan header kid
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	key := ks.ActiveKey()
+	if key == nil {
+		return "", errors.New("no active signing key")
+	}
+
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.KID
+	return token.SignedString(key.private)
+}
+
+// Parse memverifikasi token berdasarkan kid, dan menolak algoritma di luar RS256/EdDSA
+func (ks *KeySet) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, ks.keyfunc,
+		jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA}),
+		jwt.WithExpirationRequired(),
+	)
+}
+
+func (ks *KeySet) keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token has no kid header")
+	}
+
+	ks.mu.RLock()
+	key, ok := ks.keys[kid]
+	ks.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	// Algoritma di header harus sama dengan algoritma kunci, bukan sekadar salah satu yang diizinkan
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method %s for key %s", token.Method.Alg(), kid)
+	}
+
+	return key.Public(), nil
+}
+
+// JWK adalah representasi public key sesuai RFC 7517
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KID       string `json:"kid"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 (OKP)
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKSet adalah isi dari /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS mengembalikan semua public key yang masih dipakai untuk verifikasi
+func (ks *KeySet) JWKS() JWKSet {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range ks.keys {
+		jwk := JWK{Use: "sig", Algorithm: key.Algorithm, KID: key.KID}
+		switch pub := key.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// RotateKeys membuat kunci baru, menjadikannya kunci aktif, dan menandai kunci lama sebagai retired.
+// Kunci lama tetap dipakai untuk verifikasi sampai di-prune dengan PruneKeys.
+func RotateKeys(dir, algorithm string) (*SigningKey, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create key directory: %v", err)
+	}
+
+	manifest, err := readKeyManifest(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if manifest == nil {
+		manifest = &keyManifest{}
+	}
+
+	key, err := generateSigningKey(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if err := writePrivateKey(filepath.Join(dir, key.KID+".pem"), key.private); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range manifest.Keys {
+		if manifest.Keys[i].RetiredAt == nil {
+			manifest.Keys[i].RetiredAt = &now
+		}
+	}
+	manifest.Keys = append(manifest.Keys, keyManifestEntry{
+		KID:       key.KID,
+		Algorithm: key.Algorithm,
+		CreatedAt: key.CreatedAt,
+	})
+	manifest.ActiveKID = key.KID
+
+	if err := writeKeyManifest(dir, manifest); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// PruneKeys menghapus kunci yang sudah retired lebih lama dari retention.
+// retention minimal harus sama dengan umur refresh token agar sesi yang ada tidak ikut terputus.
+func PruneKeys(dir string, retention time.Duration) ([]string, error) {
+	manifest, err := readKeyManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []keyManifestEntry
+	var removed []string
+	cutoff := time.Now().Add(-retention)
+	for _, entry := range manifest.Keys {
+		if entry.KID != manifest.ActiveKID && entry.RetiredAt != nil && entry.RetiredAt.Before(cutoff) {
+			removed = append(removed, entry.KID)
+			continue
+		}
+		kept = append(kept, entry)
+	}
+
+	if len(removed) == 0 {
+		return nil, nil
+	}
+
+	manifest.Keys = kept
+	if err := writeKeyManifest(dir, manifest); err != nil {
+		return nil, err
+	}
+	for _, kid := range removed {
+		if err := os.Remove(filepath.Join(dir, kid+".pem")); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, fmt.Errorf("failed to remove key file %s: %v", kid, err)
+		}
+	}
+	return removed, nil
+}
+
+func generateSigningKey(algorithm string) (*SigningKey, error) {
+	var signer crypto.Signer
+	switch algorithm {
+	case AlgRS256:
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		signer = rsaKey
+	case AlgEdDSA:
+		_, edKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		signer = edKey
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q (use %s or %s)", algorithm, AlgRS256, AlgEdDSA)
+	}
+
+	now := time.Now()
+	kidSuffix := make([]byte, 4)
+	if _, err := rand.Read(kidSuffix); err != nil {
+		return nil, err
+	}
+
+	return &SigningKey{
+		KID:       fmt.Sprintf("%s-%x", now.Format("20060102150405"), kidSuffix),
+		Algorithm: algorithm,
+		CreatedAt: now,
+		private:   signer,
+	}, nil
+}
+
+func checkKeyAlgorithm(signer crypto.Signer, algorithm string) error {
+	switch signer.(type) {
+	case *rsa.PrivateKey:
+		if algorithm == AlgRS256 {
+			return nil
+		}
+	case ed25519.PrivateKey:
+		if algorithm == AlgEdDSA {
+			return nil
+		}
+	}
+	return fmt.Errorf("key type %T does not match algorithm %s", signer, algorithm)
+}
+
+func readKeyManifest(dir string) (*keyManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, keyManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var manifest keyManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid key manifest: %v", err)
+	}
+	return &manifest, nil
+}
+
+// writeKeyManifest menulis ke file sementara lalu rename, agar instance lain tidak membaca manifest setengah jadi
+func writeKeyManifest(dir string, manifest *keyManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, keyManifestFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, keyManifestFile))
+}
+
+func readPrivateKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+	return signer, nil
+}
+
+func writePrivateKey(path string, signer crypto.Signer) error {
+	der, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"koskosan-be/internal/config"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SMSSender mengirim SMS teks biasa dan mengembalikan ID pesan dari provider (boleh kosong).
+// Dipakai sebagai cadangan jika nomor tenant tidak bisa menerima WhatsApp.
+type SMSSender interface {
+	SendSMS(to, message string) (string, error)
+}
+
+// --- Zenziva ---
+
+// ZenzivaSender memakai API SMS reguler Zenziva (userkey + passkey dari console Zenziva)
+type ZenzivaSender struct {
+	userKey string
+	passKey string
+	baseURL string
+	client  *http.Client
+}
+
+func NewZenzivaSender(baseURL, userKey, passKey string) *ZenzivaSender {
+	return &ZenzivaSender{
+		userKey: userKey,
+		passKey: passKey,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *ZenzivaSender) SendSMS(to, message string) (string, error) {
+	form := url.Values{
+		"userkey": {s.userKey},
+		"passkey": {s.passKey},
+		"to":      {to},
+		"message": {message},
+	}
+
+	resp, err := s.client.PostForm(s.baseURL+"/sendsms/", form)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("zenziva api returned status: %s, body: %s", resp.Status, body)
+	}
+
+	// Zenziva membalas 200 juga untuk error; status "1" = terkirim ke antrian
+	var result struct {
+		MessageID string `json:"messageId"`
+		Status    string `json:"status"`
+		Text      string `json:"text"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("zenziva api returned invalid body: %s", body)
+	}
+	if result.Status != "1" {
+		return "", fmt.Errorf("zenziva api rejected message: %s", result.Text)
+	}
+	return result.MessageID, nil
+}
+
+// --- Simulation ---
+
+type LogSMSSender struct{}
+
+func (s *LogSMSSender) SendSMS(to, message string) (string, error) {
+	log.Printf("---------------------------------------------------------")
+	log.Printf("[SMS SIMULATION] To: %s", to)
+	log.Printf("[SMS SIMULATION] Message: %s", message)
+	log.Printf("---------------------------------------------------------")
+
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return "log-" + hex.EncodeToString(id), nil
+}
+
+// NewSMSSender memilih provider sesuai SMS_PROVIDER (zenziva, log). Kosong = simulasi log.
+func NewSMSSender(cfg *config.Config) SMSSender {
+	if cfg.SMSProvider == "zenziva" {
+		log.Println("[INFO] Initializing Zenziva SMS Sender")
+		return NewZenzivaSender(cfg.ZenzivaAPIURL, cfg.ZenzivaUserKey, cfg.ZenzivaPassKey)
+	}
+
+	log.Println("[WARNING] SMS_PROVIDER is not set. SMS fallback messages will only be logged locally (Simulation Mode).")
+	return &LogSMSSender{}
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZenzivaSender_SendsSMS(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/sendsms/", r.URL.Path)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "user", r.PostForm.Get("userkey"))
+		assert.Equal(t, "pass", r.PostForm.Get("passkey"))
+		assert.Equal(t, "6281234567890", r.PostForm.Get("to"))
+		assert.Equal(t, "halo", r.PostForm.Get("message"))
+		_, _ = io.WriteString(w, `{"messageId":"118","to":"6281234567890","status":"1","text":"Success"}`)
+	}))
+	defer server.Close()
+
+	id, err := NewZenzivaSender(server.URL+"/", "user", "pass").SendSMS("6281234567890", "halo")
+
+	require.NoError(t, err)
+	assert.Equal(t, "118", id)
+}
+
+func TestZenzivaSender_RejectedMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"status":"0","text":"Saldo tidak cukup"}`)
+	}))
+	defer server.Close()
+
+	_, err := NewZenzivaSender(server.URL, "user", "pass").SendSMS("6281234567890", "halo")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Saldo tidak cukup")
+}
+
+func TestZenzivaSender_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := NewZenzivaSender(server.URL, "user", "pass").SendSMS("6281234567890", "halo")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "503")
+}
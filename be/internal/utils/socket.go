@@ -1,10 +1,14 @@
 package utils
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"sync"
+	"time"
 
 	socketio "github.com/googollee/go-socket.io"
 	"github.com/googollee/go-socket.io/engineio"
@@ -13,66 +17,88 @@ import (
 	"github.com/googollee/go-socket.io/engineio/transport/websocket"
 )
 
+// AdminRoom berisi semua koneksi dengan role admin
+const AdminRoom = "admins"
+
 type SocketServer struct {
 	Server *socketio.Server
 	// Map UserID -> Set of SocketIDs (a user might have multiple tabs open)
+	// Hanya koneksi di instance ini; broadcast ke instance lain lewat adapter
 	UserSockets map[uint]map[string]bool
 	mu          sync.RWMutex
+
+	instanceID     string
+	adapter        BroadcastAdapter
+	remoteHandlers []func(BroadcastMessage)
+}
+
+// socketSession disimpan di context koneksi, diambil dari claims access token
+type socketSession struct {
+	UserID uint
+	Role   string
 }
 
 var GlobalSocket *SocketServer
 
-func InitSocketServer() (*SocketServer, error) {
+func InitSocketServer(allowedOrigins []string, keys *KeySet) (*SocketServer, error) {
+	checkOrigin := func(r *http.Request) bool {
+		return IsAllowedOrigin(r.Header.Get("Origin"), allowedOrigins)
+	}
+
 	server := socketio.NewServer(&engineio.Options{
 		Transports: []transport.Transport{
 			&polling.Transport{
-				CheckOrigin: func(r *http.Request) bool {
-					return true
-				},
+				CheckOrigin: checkOrigin,
 			},
 			&websocket.Transport{
-				CheckOrigin: func(r *http.Request) bool {
-					return true
-				},
+				CheckOrigin: checkOrigin,
 			},
 		},
 	})
-	
+
 	ss := &SocketServer{
 		Server:      server,
 		UserSockets: make(map[uint]map[string]bool),
+		instanceID:  newInstanceID(),
+		adapter:     LocalAdapter{},
 	}
 
+	// User ditentukan dari access token saat handshake, bukan dari data yang dikirim client
 	server.OnConnect("/", func(s socketio.Conn) error {
-		s.SetContext("")
-		log.Printf("Socket connected: %s", s.ID())
-		return nil
-	})
+		claims, err := ValidateAccessToken(socketToken(s), keys)
+		if err != nil || claims.UserID <= 0 {
+			log.Printf("Socket %s rejected: unauthenticated", s.ID())
+			return errors.New("unauthorized")
+		}
 
-	server.OnEvent("/", "authenticate", func(s socketio.Conn, userID uint) {
-		ss.mu.Lock()
-		defer ss.mu.Unlock()
-		
-		// Join room for targeting
-		roomName := fmt.Sprintf("user_%d", userID)
+		session := socketSession{UserID: uint(claims.UserID), Role: claims.Role}
+		s.SetContext(session)
+
+		roomName := fmt.Sprintf("user_%d", session.UserID)
 		s.Join(roomName)
-		
-		if ss.UserSockets[userID] == nil {
-			ss.UserSockets[userID] = make(map[string]bool)
+		if session.Role == "admin" {
+			s.Join(AdminRoom)
 		}
-		ss.UserSockets[userID][s.ID()] = true
-		s.SetContext(userID)
-		log.Printf("Socket %s authenticated for user %d and joined room %s", s.ID(), userID, roomName)
+
+		ss.mu.Lock()
+		if ss.UserSockets[session.UserID] == nil {
+			ss.UserSockets[session.UserID] = make(map[string]bool)
+		}
+		ss.UserSockets[session.UserID][s.ID()] = true
+		ss.mu.Unlock()
+
+		log.Printf("Socket %s connected for user %d (role %s)", s.ID(), session.UserID, session.Role)
+		return nil
 	})
 
 	server.OnDisconnect("/", func(s socketio.Conn, reason string) {
-		userID, ok := s.Context().(uint)
+		session, ok := s.Context().(socketSession)
 		if ok {
 			ss.mu.Lock()
-			if ss.UserSockets[userID] != nil {
-				delete(ss.UserSockets[userID], s.ID())
-				if len(ss.UserSockets[userID]) == 0 {
-					delete(ss.UserSockets, userID)
+			if ss.UserSockets[session.UserID] != nil {
+				delete(ss.UserSockets[session.UserID], s.ID())
+				if len(ss.UserSockets[session.UserID]) == 0 {
+					delete(ss.UserSockets, session.UserID)
 				}
 			}
 			ss.mu.Unlock()
@@ -89,12 +115,98 @@ func InitSocketServer() (*SocketServer, error) {
 	return ss, nil
 }
 
+// socketToken mengambil access token dari cookie, header Authorization, atau query ?token=
+func socketToken(s socketio.Conn) string {
+	header := s.RemoteHeader()
+	if cookieHeader := header.Get("Cookie"); cookieHeader != "" {
+		req := http.Request{Header: http.Header{"Cookie": {cookieHeader}}}
+		if cookie, err := req.Cookie("access_token"); err == nil && cookie.Value != "" {
+			return cookie.Value
+		}
+	}
+	if token, ok := strings.CutPrefix(header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	u := s.URL()
+	return u.Query().Get("token")
+}
+
+// IsAllowedOrigin mencocokkan header Origin dengan ALLOWED_ORIGINS (sama seperti CORS).
+// Request tanpa Origin (non-browser atau same-origin polling) tetap diizinkan.
+func IsAllowedOrigin(origin string, allowedOrigins []string) bool {
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range allowedOrigins {
+		allowed = strings.TrimSpace(allowed)
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	log.Printf("Socket connection rejected for origin %s", origin)
+	return false
+}
+
+// UseAdapter memasang adapter broadcast antar instance dan mulai menerima pesan dari instance lain
+func (ss *SocketServer) UseAdapter(adapter BroadcastAdapter) error {
+	if err := adapter.Subscribe(ss.handleRemoteBroadcast); err != nil {
+		return err
+	}
+	ss.adapter = adapter
+	return nil
+}
+
+// handleRemoteBroadcast meng-emit pesan dari instance lain ke socket lokal
+func (ss *SocketServer) handleRemoteBroadcast(msg BroadcastMessage) {
+	if msg.Origin == ss.instanceID {
+		return
+	}
+	ss.Server.BroadcastToRoom("/", msg.Room, msg.Event, msg.Payload)
+	for _, handler := range ss.remoteHandlers {
+		handler(msg)
+	}
+}
+
+// OnRemoteBroadcast mendaftarkan handler tambahan untuk broadcast dari instance lain (misalnya EventHub untuk SSE).
+// Harus dipanggil sebelum UseAdapter.
+func (ss *SocketServer) OnRemoteBroadcast(handler func(BroadcastMessage)) {
+	ss.remoteHandlers = append(ss.remoteHandlers, handler)
+}
+
+// broadcast emit ke socket lokal lalu meneruskan ke instance lain lewat adapter
+func (ss *SocketServer) broadcast(room, event string, data interface{}) {
+	ss.Server.BroadcastToRoom("/", room, event, data)
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Failed to encode broadcast '%s': %v", event, err)
+		return
+	}
+	msg := BroadcastMessage{Origin: ss.instanceID, Room: room, Event: event, Payload: payload}
+	if err := ss.adapter.Publish(msg); err != nil {
+		log.Printf("Failed to forward broadcast '%s' to other instances: %v", event, err)
+	}
+}
+
 func (ss *SocketServer) BroadcastToUser(userID uint, event string, data interface{}) {
 	roomName := fmt.Sprintf("user_%d", userID)
-	ss.Server.BroadcastToRoom("/", roomName, event, data)
+	ss.broadcast(roomName, event, data)
 	log.Printf("Broadcasted event '%s' to user %d (room %s)", event, userID, roomName)
 }
 
+// BroadcastToAdmins mengirim event ke semua admin yang sedang online
+func (ss *SocketServer) BroadcastToAdmins(event string, data interface{}) {
+	ss.broadcast(AdminRoom, event, data)
+}
+
 func (ss *SocketServer) BroadcastToAll(event string, data interface{}) {
-	ss.Server.BroadcastToRoom("/", "", event, data)
+	ss.broadcast("", event, data)
+}
+
+func newInstanceID() string {
+	id, err := GenerateSecureToken(8)
+	if err != nil {
+		return fmt.Sprintf("instance-%d", time.Now().UnixNano())
+	}
+	return id
 }
@@ -0,0 +1,218 @@
+package utils
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/lib/pq"
+)
+
+// SocketBroadcastChannel adalah nama channel Redis / Postgres NOTIFY untuk broadcast antar instance
+const SocketBroadcastChannel = "koskosan_socket_broadcast"
+
+// pg_notify menolak payload >= 8000 byte
+const maxNotifyPayload = 7900
+
+// BroadcastMessage adalah satu emit ke room yang diteruskan ke instance lain
+type BroadcastMessage struct {
+	Origin  string          `json:"origin"` // instance pengirim, supaya tidak di-emit dua kali
+	Room    string          `json:"room"`
+	Event   string          `json:"event"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// BroadcastAdapter is an interface for fanning out socket broadcasts across API replicas.
+// Subscribe dipanggil sekali saat startup; handler menerima pesan dari semua instance (termasuk diri sendiri).
+type BroadcastAdapter interface {
+	Publish(msg BroadcastMessage) error
+	Subscribe(handler func(BroadcastMessage)) error
+	Close() error
+}
+
+// LocalAdapter dipakai untuk single instance: tidak ada yang perlu diteruskan
+type LocalAdapter struct{}
+
+func (LocalAdapter) Publish(msg BroadcastMessage) error             { return nil }
+func (LocalAdapter) Subscribe(handler func(BroadcastMessage)) error { return nil }
+func (LocalAdapter) Close() error                                   { return nil }
+
+// ==================== Redis Pub/Sub ====================
+
+// RedisAdapter meneruskan broadcast lewat Redis PUBLISH/SUBSCRIBE
+type RedisAdapter struct {
+	pool    *redis.Pool
+	channel string
+
+	mu     sync.Mutex
+	psc    *redis.PubSubConn
+	closed bool
+}
+
+func NewRedisAdapter(redisURL string) (*RedisAdapter, error) {
+	pool := &redis.Pool{
+		MaxIdle:     5,
+		IdleTimeout: 4 * time.Minute,
+		Dial: func() (redis.Conn, error) {
+			return redis.DialURL(redisURL)
+		},
+	}
+
+	conn := pool.Get()
+	defer conn.Close()
+	if _, err := conn.Do("PING"); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("redis adapter: %w", err)
+	}
+
+	return &RedisAdapter{pool: pool, channel: SocketBroadcastChannel}, nil
+}
+
+func (a *RedisAdapter) Publish(msg BroadcastMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	conn := a.pool.Get()
+	defer conn.Close()
+	_, err = conn.Do("PUBLISH", a.channel, body)
+	return err
+}
+
+// Subscribe membuka koneksi SUBSCRIBE di background dan reconnect otomatis jika terputus
+func (a *RedisAdapter) Subscribe(handler func(BroadcastMessage)) error {
+	ready := make(chan error, 1)
+	go func() {
+		first := true
+		for {
+			err := a.listen(handler, func() {
+				if first {
+					first = false
+					ready <- nil
+				}
+			})
+
+			if first {
+				ready <- err
+				return
+			}
+			a.mu.Lock()
+			closed := a.closed
+			a.mu.Unlock()
+			if closed {
+				return
+			}
+			log.Printf("Redis adapter subscription lost: %v, reconnecting...", err)
+			time.Sleep(time.Second)
+		}
+	}()
+	return <-ready
+}
+
+func (a *RedisAdapter) listen(handler func(BroadcastMessage), onSubscribed func()) error {
+	conn := a.pool.Get()
+	psc := &redis.PubSubConn{Conn: conn}
+	defer psc.Close()
+
+	if err := psc.Subscribe(a.channel); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.psc = psc
+	a.mu.Unlock()
+
+	for {
+		switch v := psc.Receive().(type) {
+		case redis.Message:
+			var msg BroadcastMessage
+			if err := json.Unmarshal(v.Data, &msg); err != nil {
+				log.Printf("Redis adapter: invalid message: %v", err)
+				continue
+			}
+			handler(msg)
+		case redis.Subscription:
+			if v.Kind == "subscribe" {
+				onSubscribed()
+			}
+			if v.Count == 0 {
+				return nil
+			}
+		case error:
+			return v
+		}
+	}
+}
+
+func (a *RedisAdapter) Close() error {
+	a.mu.Lock()
+	a.closed = true
+	psc := a.psc
+	a.mu.Unlock()
+
+	if psc != nil {
+		psc.Unsubscribe()
+	}
+	return a.pool.Close()
+}
+
+// ==================== Postgres LISTEN/NOTIFY ====================
+
+// PostgresAdapter meneruskan broadcast lewat LISTEN/NOTIFY, tanpa infrastruktur tambahan.
+// Payload NOTIFY dibatasi ~8KB, jadi event besar sebaiknya hanya membawa ID.
+type PostgresAdapter struct {
+	db       *sql.DB
+	listener *pq.Listener
+	channel  string
+}
+
+func NewPostgresAdapter(db *sql.DB, dsn string) *PostgresAdapter {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Postgres adapter listener event %d: %v", ev, err)
+		}
+	})
+	return &PostgresAdapter{db: db, listener: listener, channel: SocketBroadcastChannel}
+}
+
+func (a *PostgresAdapter) Publish(msg BroadcastMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if len(body) > maxNotifyPayload {
+		return fmt.Errorf("postgres adapter: payload too large (%d bytes)", len(body))
+	}
+	_, err = a.db.Exec("SELECT pg_notify($1, $2)", a.channel, string(body))
+	return err
+}
+
+func (a *PostgresAdapter) Subscribe(handler func(BroadcastMessage)) error {
+	if err := a.listener.Listen(a.channel); err != nil {
+		return fmt.Errorf("postgres adapter: %w", err)
+	}
+
+	go func() {
+		for n := range a.listener.NotificationChannel() {
+			// nil dikirim setelah reconnect; notifikasi selama putus memang hilang
+			if n == nil {
+				continue
+			}
+			var msg BroadcastMessage
+			if err := json.Unmarshal([]byte(n.Extra), &msg); err != nil {
+				log.Printf("Postgres adapter: invalid message: %v", err)
+				continue
+			}
+			handler(msg)
+		}
+	}()
+	return nil
+}
+
+func (a *PostgresAdapter) Close() error {
+	return a.listener.Close()
+}
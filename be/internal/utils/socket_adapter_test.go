@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis adalah stand-in Redis minimal (PING, PUBLISH, SUBSCRIBE, UNSUBSCRIBE)
+// supaya test adapter tidak butuh server Redis sungguhan.
+type fakeRedis struct {
+	ln          net.Listener
+	mu          sync.Mutex
+	subscribers map[string]map[*fakeRedisConn]bool
+}
+
+type fakeRedisConn struct {
+	conn net.Conn
+	mu   sync.Mutex
+}
+
+func (c *fakeRedisConn) write(s string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	io.WriteString(c.conn, s)
+}
+
+func startFakeRedis(t *testing.T) *fakeRedis {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	fr := &fakeRedis{ln: ln, subscribers: make(map[string]map[*fakeRedisConn]bool)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go fr.serve(&fakeRedisConn{conn: conn})
+		}
+	}()
+	t.Cleanup(func() { ln.Close() })
+	return fr
+}
+
+func (fr *fakeRedis) URL() string {
+	return "redis://" + fr.ln.Addr().String()
+}
+
+func (fr *fakeRedis) serve(c *fakeRedisConn) {
+	defer func() {
+		fr.mu.Lock()
+		for _, subs := range fr.subscribers {
+			delete(subs, c)
+		}
+		fr.mu.Unlock()
+		c.conn.Close()
+	}()
+
+	r := bufio.NewReader(c.conn)
+	for {
+		args, err := readRESPArray(r)
+		if err != nil {
+			return
+		}
+		switch strings.ToUpper(args[0]) {
+		case "PING":
+			c.write("+PONG\r\n")
+		case "PUBLISH":
+			fr.mu.Lock()
+			subs := fr.subscribers[args[1]]
+			for sub := range subs {
+				sub.write(fmt.Sprintf("*3\r\n$7\r\nmessage\r\n%s%s", bulk(args[1]), bulk(args[2])))
+			}
+			n := len(subs)
+			fr.mu.Unlock()
+			c.write(fmt.Sprintf(":%d\r\n", n))
+		case "SUBSCRIBE":
+			fr.mu.Lock()
+			if fr.subscribers[args[1]] == nil {
+				fr.subscribers[args[1]] = make(map[*fakeRedisConn]bool)
+			}
+			fr.subscribers[args[1]][c] = true
+			fr.mu.Unlock()
+			c.write(fmt.Sprintf("*3\r\n$9\r\nsubscribe\r\n%s:1\r\n", bulk(args[1])))
+		case "UNSUBSCRIBE":
+			fr.mu.Lock()
+			for channel, subs := range fr.subscribers {
+				delete(subs, c)
+				c.write(fmt.Sprintf("*3\r\n$11\r\nunsubscribe\r\n%s:0\r\n", bulk(channel)))
+			}
+			fr.mu.Unlock()
+		default:
+			c.write("-ERR unknown command\r\n")
+		}
+	}
+}
+
+func bulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func readRESPArray(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(header[1:]))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func TestRedisAdapter_DeliversAcrossInstances(t *testing.T) {
+	fr := startFakeRedis(t)
+
+	instanceA, err := NewRedisAdapter(fr.URL())
+	require.NoError(t, err)
+	defer instanceA.Close()
+
+	instanceB, err := NewRedisAdapter(fr.URL())
+	require.NoError(t, err)
+	defer instanceB.Close()
+
+	received := make(chan BroadcastMessage, 1)
+	require.NoError(t, instanceB.Subscribe(func(msg BroadcastMessage) {
+		received <- msg
+	}))
+
+	payload, _ := json.Marshal(map[string]interface{}{"payment_id": 12})
+	require.NoError(t, instanceA.Publish(BroadcastMessage{
+		Origin:  "instance-a",
+		Room:    "user_7",
+		Event:   EventPaymentConfirmed,
+		Payload: payload,
+	}))
+
+	select {
+	case msg := <-received:
+		assert.Equal(t, "instance-a", msg.Origin)
+		assert.Equal(t, "user_7", msg.Room)
+		assert.Equal(t, EventPaymentConfirmed, msg.Event)
+		assert.JSONEq(t, `{"payment_id":12}`, string(msg.Payload))
+	case <-time.After(2 * time.Second):
+		t.Fatal("broadcast was not delivered to the other instance")
+	}
+}
+
+func TestRedisAdapter_UnreachableServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	_, err = NewRedisAdapter("redis://" + addr)
+	assert.Error(t, err)
+}
@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"crypto/rand"
+	"fmt"
+	"koskosan-be/internal/config"
+	"log"
+	"strings"
+	"time"
+)
+
+// Storage menyimpan file upload. Key berbentuk "<folder>/<nama file>".
+// File publik direferensikan dengan URL yang bisa dibuka langsung; file privat dengan "/<key>"
+// yang hanya bisa dibuka lewat SignedURL.
+type Storage interface {
+	// Put menyimpan data dan mengembalikan referensi yang disimpan di database
+	Put(key string, data []byte, contentType string, private bool) (string, error)
+	// Delete menghapus file dari referensi hasil Put; referensi milik storage lain diabaikan
+	Delete(ref string) error
+	// SignedURL membuat URL sementara untuk referensi file privat
+	SignedURL(ref string, ttl time.Duration) (string, error)
+	// VariantRef mengembalikan referensi file varian (akhiran _md/_th) jika tersimpan di storage ini
+	VariantRef(ref, suffix string) (string, bool)
+}
+
+// imageTransformer diimplementasikan storage yang membuat varian gambar publik sendiri (Cloudinary),
+// sehingga file varian tidak perlu diunggah
+type imageTransformer interface {
+	transformsPublicImages() bool
+}
+
+// GlobalStorage dipakai UploadImage/UploadBytes/DeleteUploadedFile; diganti InitStorage saat startup
+var GlobalStorage Storage = NewLocalStorage("public", "private", randomSecret())
+
+var (
+	privateFolders = map[string]bool{"proofs": true}
+	signedURLTTL   = 15 * time.Minute
+	// legacyCloudinary menghapus upload Cloudinary lama saat storage aktif bukan Cloudinary; nil jika CLOUDINARY_URL kosong
+	legacyCloudinary *CloudinaryStorage
+)
+
+// InitStorage memasang GlobalStorage sesuai STORAGE_PROVIDER beserta folder privat dan masa berlaku signed URL
+func InitStorage(cfg *config.Config) error {
+	storage, err := NewStorage(cfg)
+	if err != nil {
+		return err
+	}
+	GlobalStorage = storage
+	legacyCloudinary = nil
+	if cld, ok := storage.(*CloudinaryStorage); ok {
+		legacyCloudinary = cld
+	} else if cfg.CloudinaryURL != "" {
+		if legacyCloudinary, err = NewCloudinaryStorage(cfg.CloudinaryURL); err != nil {
+			log.Printf("[WARN] CLOUDINARY_URL is invalid, old Cloudinary uploads cannot be deleted: %v", err)
+		}
+	}
+	privateFolders = map[string]bool{}
+	for _, folder := range strings.Split(cfg.StoragePrivateFolders, ",") {
+		if folder = strings.Trim(strings.TrimSpace(folder), "/"); folder != "" {
+			privateFolders[folder] = true
+		}
+	}
+	signedURLTTL = time.Duration(cfg.SignedURLTTLMinutes) * time.Minute
+	return nil
+}
+
+// NewStorage memilih storage sesuai STORAGE_PROVIDER (local, s3, cloudinary).
+// Jika kosong: Cloudinary bila CLOUDINARY_URL diisi, selain itu disk lokal.
+func NewStorage(cfg *config.Config) (Storage, error) {
+	provider := cfg.StorageProvider
+	if provider == "" && cfg.CloudinaryURL != "" {
+		provider = "cloudinary"
+	}
+
+	switch provider {
+	case "s3":
+		log.Println("[INFO] Initializing S3-compatible storage")
+		return NewS3Storage(S3Config{
+			Endpoint:      cfg.S3Endpoint,
+			Region:        cfg.S3Region,
+			AccessKey:     cfg.S3AccessKey,
+			SecretKey:     cfg.S3SecretKey,
+			PublicBucket:  cfg.S3PublicBucket,
+			PrivateBucket: cfg.S3PrivateBucket,
+			PublicURL:     cfg.S3PublicURL,
+		})
+	case "cloudinary":
+		log.Println("[INFO] Initializing Cloudinary storage")
+		return NewCloudinaryStorage(cfg.CloudinaryURL)
+	}
+
+	secret := cfg.StorageSigningSecret
+	if secret == "" {
+		secret = cfg.JWTSecret
+	}
+	log.Println("[INFO] Initializing local disk storage (public/, private/)")
+	return NewLocalStorage("public", "private", []byte(secret)), nil
+}
+
+// IsPrivateFolder: folder yang isinya hanya boleh dibuka lewat signed URL
+func IsPrivateFolder(folder string) bool {
+	return privateFolders[folder]
+}
+
+// IsPrivateRef: referensi "/<folder privat>/..." hasil Put untuk file privat
+// (termasuk bukti lama di public/proofs yang sekarang tidak lagi disajikan terbuka)
+func IsPrivateRef(ref string) bool {
+	if !strings.HasPrefix(ref, "/") {
+		return false
+	}
+	folder, _, ok := strings.Cut(strings.TrimPrefix(ref, "/"), "/")
+	return ok && IsPrivateFolder(folder)
+}
+
+// FileURL mengubah referensi file menjadi URL untuk klien: file privat mendapat signed URL
+// yang berlaku SIGNED_URL_TTL_MINUTES, file publik dikembalikan apa adanya
+func FileURL(ref string) (string, error) {
+	if !IsPrivateRef(ref) {
+		return ref, nil
+	}
+	return GlobalStorage.SignedURL(ref, signedURLTTL)
+}
+
+// SignedURLTTL adalah masa berlaku URL dari FileURL
+func SignedURLTTL() time.Duration {
+	return signedURLTTL
+}
+
+func randomSecret() []byte {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(fmt.Sprintf("failed to generate storage secret: %v", err))
+	}
+	return secret
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+)
+
+// CloudinaryStorage mengunggah file publik sebagai aset "upload" (varian lewat transformasi URL)
+// dan file privat sebagai aset "private" yang hanya bisa diunduh lewat private download URL.
+type CloudinaryStorage struct {
+	cld *cloudinary.Cloudinary
+}
+
+func NewCloudinaryStorage(cloudinaryURL string) (*CloudinaryStorage, error) {
+	cld, err := cloudinary.NewFromURL(cloudinaryURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init cloudinary: %v", err)
+	}
+	cld.Config.URL.Secure = true
+	return &CloudinaryStorage{cld: cld}, nil
+}
+
+func (s *CloudinaryStorage) Put(key string, data []byte, contentType string, private bool) (string, error) {
+	params := uploader.UploadParams{
+		PublicID:     strings.TrimSuffix(key, path.Ext(key)),
+		ResourceType: "image",
+	}
+	if private {
+		params.Type = api.Private
+	} else {
+		// Auto quality and format for optimal performance
+		params.Transformation = "q_auto,f_auto"
+	}
+
+	result, err := s.cld.Upload.Upload(context.Background(), bytes.NewReader(data), params)
+	if err != nil {
+		return "", fmt.Errorf("cloudinary upload failed: %v", err)
+	}
+	if result.Error.Message != "" {
+		return "", fmt.Errorf("cloudinary upload failed: %s", result.Error.Message)
+	}
+	if private {
+		return "/" + key, nil
+	}
+	return result.SecureURL, nil
+}
+
+func (s *CloudinaryStorage) Delete(ref string) error {
+	params := uploader.DestroyParams{ResourceType: "image"}
+	if publicID, ok := cloudinaryPublicID(ref); ok {
+		params.PublicID = publicID
+	} else if key, ok := localKey(ref); ok && IsPrivateRef(ref) {
+		params.PublicID = strings.TrimSuffix(key, path.Ext(key))
+		params.Type = api.Private
+	} else {
+		return nil
+	}
+
+	result, err := s.cld.Upload.Destroy(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("cloudinary delete failed: %v", err)
+	}
+	// "not found" berarti sudah terhapus sebelumnya
+	if result.Result != "ok" && result.Result != "not found" {
+		return fmt.Errorf("cloudinary delete failed: %s", result.Result)
+	}
+	return nil
+}
+
+func (s *CloudinaryStorage) SignedURL(ref string, ttl time.Duration) (string, error) {
+	key, ok := localKey(ref)
+	if !ok || !IsPrivateRef(ref) {
+		return "", fmt.Errorf("not a private cloudinary file reference: %s", ref)
+	}
+	expiresAt := time.Now().Add(ttl)
+	return s.cld.Upload.PrivateDownloadURL(uploader.PrivateDownloadURLParams{
+		PublicID:     strings.TrimSuffix(key, path.Ext(key)),
+		Format:       strings.TrimPrefix(path.Ext(key), "."),
+		DeliveryType: string(api.Private),
+		ExpiresAt:    &expiresAt,
+		ResourceType: api.Image,
+	})
+}
+
+// VariantRef: file privat diunggah bersama variannya; file publik memakai transformasi (ImageVariantURLs)
+func (s *CloudinaryStorage) VariantRef(ref, suffix string) (string, bool) {
+	if !IsPrivateRef(ref) {
+		return "", false
+	}
+	return variantURL(ref, suffix), true
+}
+
+func (s *CloudinaryStorage) transformsPublicImages() bool {
+	return true
+}
@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidSignature = errors.New("signed URL tidak valid")
+	ErrSignedURLExpired = errors.New("signed URL sudah kedaluwarsa")
+)
+
+// LocalSignedURLPrefix adalah route yang menyajikan file privat storage lokal (lihat FileHandler)
+const LocalSignedURLPrefix = "/api/files/"
+
+// LocalStorage menyimpan file publik di publicDir (disajikan r.Static per folder) dan file privat di
+// privateDir yang tidak pernah disajikan langsung, hanya lewat signed URL bertanda tangan HMAC.
+type LocalStorage struct {
+	publicDir  string
+	privateDir string
+	secret     []byte
+	now        func() time.Time
+}
+
+func NewLocalStorage(publicDir, privateDir string, secret []byte) *LocalStorage {
+	return &LocalStorage{publicDir: publicDir, privateDir: privateDir, secret: secret, now: time.Now}
+}
+
+func (s *LocalStorage) Put(key string, data []byte, contentType string, private bool) (string, error) {
+	dir := s.publicDir
+	if private {
+		dir = s.privateDir
+	}
+	fullPath := filepath.Join(dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create upload directory: %v", err)
+	}
+	perm := os.FileMode(0644)
+	if private {
+		perm = 0600
+	}
+	if err := os.WriteFile(fullPath, data, perm); err != nil {
+		return "", fmt.Errorf("failed to save file: %v", err)
+	}
+	return "/" + key, nil
+}
+
+func (s *LocalStorage) Delete(ref string) error {
+	key, ok := localKey(ref)
+	if !ok {
+		return nil
+	}
+	for _, dir := range []string{s.privateDir, s.publicDir} {
+		if err := os.Remove(filepath.Join(dir, filepath.FromSlash(key))); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *LocalStorage) SignedURL(ref string, ttl time.Duration) (string, error) {
+	key, ok := localKey(ref)
+	if !ok {
+		return "", fmt.Errorf("not a local file reference: %s", ref)
+	}
+	expires := strconv.FormatInt(s.now().Add(ttl).Unix(), 10)
+	query := url.Values{"expires": {expires}, "signature": {s.sign(key, expires)}}
+	return LocalSignedURLPrefix + key + "?" + query.Encode(), nil
+}
+
+func (s *LocalStorage) VariantRef(ref, suffix string) (string, bool) {
+	key, ok := localKey(ref)
+	if !ok {
+		return "", false
+	}
+	variant := variantURL(key, suffix)
+	if _, err := s.Open(variant); err != nil {
+		return "", false
+	}
+	return "/" + variant, true
+}
+
+// Verify memeriksa tanda tangan dan masa berlaku signed URL untuk key
+func (s *LocalStorage) Verify(key, expires, signature string) error {
+	if !hmac.Equal([]byte(signature), []byte(s.sign(key, expires))) {
+		return ErrInvalidSignature
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if s.now().Unix() > expiresAt {
+		return ErrSignedURLExpired
+	}
+	return nil
+}
+
+// Open mengembalikan path file untuk key: folder privat dulu, lalu public/ untuk file yang
+// diunggah sebelum folder itu dijadikan privat
+func (s *LocalStorage) Open(key string) (string, error) {
+	for _, dir := range []string{s.privateDir, s.publicDir} {
+		fullPath := filepath.Join(dir, filepath.FromSlash(key))
+		if info, err := os.Stat(fullPath); err == nil && !info.IsDir() {
+			return fullPath, nil
+		}
+	}
+	return "", os.ErrNotExist
+}
+
+func (s *LocalStorage) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// localKey mengubah "/folder/file.jpg" menjadi "folder/file.jpg"; URL absolut dan path yang keluar dari folder ditolak
+func localKey(ref string) (string, bool) {
+	if !strings.HasPrefix(ref, "/") || strings.HasPrefix(ref, "//") {
+		return "", false
+	}
+	key := path.Clean(strings.TrimPrefix(ref, "/"))
+	if key == "." || strings.HasPrefix(key, "../") || key == ".." || !strings.Contains(key, "/") {
+		return "", false
+	}
+	return key, true
+}
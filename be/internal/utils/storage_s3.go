@@ -0,0 +1,256 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3TimeFormat      = "20060102T150405Z"
+)
+
+// S3Config untuk storage S3-compatible. Bucket publik harus punya bucket policy public-read;
+// bucket privat hanya dibuka lewat presigned URL.
+type S3Config struct {
+	Endpoint      string // https://s3.ap-southeast-1.amazonaws.com, http://localhost:9000 (MinIO)
+	Region        string
+	AccessKey     string
+	SecretKey     string
+	PublicBucket  string
+	PrivateBucket string
+	PublicURL     string // base URL bucket publik (CDN); kosong = <endpoint>/<bucket publik>
+}
+
+// S3Storage memakai REST API S3 dengan Signature V4 (path-style, cocok untuk AWS maupun MinIO)
+type S3Storage struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+func NewS3Storage(cfg S3Config) (*S3Storage, error) {
+	endpoint, err := url.Parse(strings.TrimSuffix(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.PublicURL == "" {
+		cfg.PublicURL = endpoint.String() + "/" + cfg.PublicBucket
+	}
+	cfg.PublicURL = strings.TrimSuffix(cfg.PublicURL, "/")
+	return &S3Storage{cfg: cfg, endpoint: endpoint, client: &http.Client{Timeout: 30 * time.Second}, now: time.Now}, nil
+}
+
+func (s *S3Storage) Put(key string, data []byte, contentType string, private bool) (string, error) {
+	bucket := s.cfg.PublicBucket
+	if private {
+		bucket = s.cfg.PrivateBucket
+	}
+	req, err := http.NewRequest(http.MethodPut, s.objectURL(bucket, key), bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if err := s.do(req, data); err != nil {
+		return "", fmt.Errorf("s3 upload failed: %v", err)
+	}
+	if private {
+		return "/" + key, nil
+	}
+	return s.cfg.PublicURL + "/" + key, nil
+}
+
+func (s *S3Storage) Delete(ref string) error {
+	bucket, key, ok := s.locate(ref)
+	if !ok {
+		return nil
+	}
+	req, err := http.NewRequest(http.MethodDelete, s.objectURL(bucket, key), nil)
+	if err != nil {
+		return err
+	}
+	if err := s.do(req, nil); err != nil {
+		return fmt.Errorf("s3 delete failed: %v", err)
+	}
+	return nil
+}
+
+// SignedURL membuat presigned GET URL ke bucket privat
+func (s *S3Storage) SignedURL(ref string, ttl time.Duration) (string, error) {
+	bucket, key, ok := s.locate(ref)
+	if !ok || bucket != s.cfg.PrivateBucket {
+		return "", fmt.Errorf("not a private S3 file reference: %s", ref)
+	}
+	return s.presign(http.MethodGet, s.objectURL(bucket, key), ttl, s.now())
+}
+
+// VariantRef: Put selalu menyimpan varian bersama file aslinya, jadi cukup cek referensinya milik storage ini
+func (s *S3Storage) VariantRef(ref, suffix string) (string, bool) {
+	if _, _, ok := s.locate(ref); !ok {
+		return "", false
+	}
+	return variantURL(ref, suffix), true
+}
+
+// locate memetakan referensi ke bucket dan key: URL publik -> bucket publik, "/<key>" -> bucket privat
+func (s *S3Storage) locate(ref string) (bucket, key string, ok bool) {
+	if strings.HasPrefix(ref, s.cfg.PublicURL+"/") {
+		return s.cfg.PublicBucket, strings.TrimPrefix(ref, s.cfg.PublicURL+"/"), true
+	}
+	if key, ok := localKey(ref); ok && IsPrivateRef(ref) {
+		return s.cfg.PrivateBucket, key, true
+	}
+	return "", "", false
+}
+
+func (s *S3Storage) objectURL(bucket, key string) string {
+	return s.endpoint.String() + "/" + bucket + "/" + key
+}
+
+// do menandatangani request dengan header Authorization lalu mengirimnya
+func (s *S3Storage) do(req *http.Request, payload []byte) error {
+	s.sign(req, sha256Hex(payload), s.now())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// sign mengisi x-amz-date, x-amz-content-sha256 dan Authorization (SigV4) pada req
+func (s *S3Storage) sign(req *http.Request, payloadHash string, at time.Time) {
+	amzDate := at.UTC().Format(s3TimeFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method, s3EscapePath(req.URL.Path), s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(), signedHeaders, payloadHash,
+	}, "\n")
+	scope := s.scope(at)
+	signature := s.signature(at, canonicalRequest)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.cfg.AccessKey, scope, signedHeaders, signature))
+}
+
+// presign membuat URL bertanda tangan di query string (hanya header host yang ditandatangani)
+func (s *S3Storage) presign(method, rawURL string, ttl time.Duration, at time.Time) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.cfg.AccessKey+"/"+s.scope(at))
+	query.Set("X-Amz-Date", at.UTC().Format(s3TimeFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(ttl.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalRequest := strings.Join([]string{
+		method, s3EscapePath(u.Path), s3CanonicalQuery(query),
+		"host:" + u.Host + "\n", "host", s3UnsignedPayload,
+	}, "\n")
+	query.Set("X-Amz-Signature", s.signature(at, canonicalRequest))
+	u.RawQuery = s3CanonicalQuery(query)
+	return u.String(), nil
+}
+
+func (s *S3Storage) scope(at time.Time) string {
+	return at.UTC().Format("20060102") + "/" + s.cfg.Region + "/s3/aws4_request"
+}
+
+func (s *S3Storage) signature(at time.Time, canonicalRequest string) string {
+	stringToSign := strings.Join([]string{
+		s3Algorithm, at.UTC().Format(s3TimeFormat), s.scope(at), sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), at.UTC().Format("20060102"))
+	for _, part := range []string{s.cfg.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// s3Escape meng-encode sesuai RFC 3986 (yang diminta SigV4): hanya A-Z a-z 0-9 - _ . ~ yang tidak di-encode
+func s3Escape(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func s3EscapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = s3Escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, key := range keys {
+		values := append([]string{}, query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, s3Escape(key)+"="+s3Escape(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
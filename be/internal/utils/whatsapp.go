@@ -2,29 +2,61 @@ package utils
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"koskosan-be/internal/config"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// WhatsAppSender mengirim pesan teks dan mengembalikan ID pesan dari provider
+// (dipakai untuk mencocokkan callback status delivered/read). ID boleh kosong jika provider tidak memberikannya.
 type WhatsAppSender interface {
-	SendWhatsApp(to, message string) error
+	SendWhatsApp(to, message string) (string, error)
 }
 
+// Status pengiriman WhatsApp dari callback provider, urut dari yang paling awal
+const (
+	WhatsAppStatusSent      = "sent"
+	WhatsAppStatusDelivered = "delivered"
+	WhatsAppStatusRead      = "read"
+	WhatsAppStatusFailed    = "failed"
+)
+
+// WhatsAppStatus adalah satu update status dari webhook provider
+type WhatsAppStatus struct {
+	MessageID string    `json:"message_id"`
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+	Error     string    `json:"error,omitempty"`
+}
+
+const whatsAppHTTPTimeout = 10 * time.Second
+
+// --- Fonnte ---
+
 type FonnteSender struct {
-	token string
+	token   string
+	baseURL string
+	client  *http.Client
 }
 
 func NewFonnteSender(token string) *FonnteSender {
-	return &FonnteSender{token: token}
+	return &FonnteSender{token: token, baseURL: "https://api.fonnte.com", client: &http.Client{Timeout: whatsAppHTTPTimeout}}
 }
 
-func (s *FonnteSender) SendWhatsApp(to, message string) error {
+func (s *FonnteSender) SendWhatsApp(to, message string) (string, error) {
 	// Fonnte API endpoint
-	url := "https://api.fonnte.com/send"
+	url := s.baseURL + "/send"
 
 	// Prepare payload
 	payload := map[string]string{
@@ -35,43 +67,287 @@ func (s *FonnteSender) SendWhatsApp(to, message string) error {
 
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonPayload))
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	req.Header.Set("Authorization", s.token)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := s.client.Do(req)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer resp.Body.Close()
 
 	// Log the response for debugging Fonnte issues
-	var buf bytes.Buffer
-	buf.ReadFrom(resp.Body)
-	respBody := buf.String()
+	respBody, _ := io.ReadAll(resp.Body)
 	log.Printf("[Fonnte API Response] Status: %s, Body: %s", resp.Status, respBody)
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("fonnte api returned status: %s, body: %s", resp.Status, respBody)
+		return "", fmt.Errorf("fonnte api returned status: %s, body: %s", resp.Status, respBody)
+	}
+
+	// Fonnte membalas 200 juga untuk error, statusnya ada di body
+	var result struct {
+		Status bool            `json:"status"`
+		Reason string          `json:"reason"`
+		ID     []json.Number   `json:"id"`
+		Detail json.RawMessage `json:"detail"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("fonnte api returned invalid body: %s", respBody)
+	}
+	if !result.Status {
+		return "", fmt.Errorf("fonnte api rejected message: %s", result.Reason)
+	}
+	if len(result.ID) > 0 {
+		return result.ID[0].String(), nil
 	}
+	return "", nil
+}
+
+// --- Meta WhatsApp Cloud API ---
+
+// MetaCloudSender mengirim pesan teks lewat WhatsApp Cloud API.
+// Catatan: pesan teks bebas hanya diterima dalam 24 jam sejak pesan terakhir dari user.
+type MetaCloudSender struct {
+	token         string
+	phoneNumberID string
+	baseURL       string
+	client        *http.Client
+}
 
-	return nil
+func NewMetaCloudSender(baseURL, token, phoneNumberID string) *MetaCloudSender {
+	return &MetaCloudSender{
+		token:         token,
+		phoneNumberID: phoneNumberID,
+		baseURL:       strings.TrimRight(baseURL, "/"),
+		client:        &http.Client{Timeout: whatsAppHTTPTimeout},
+	}
 }
 
+func (s *MetaCloudSender) SendWhatsApp(to, message string) (string, error) {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"recipient_type":    "individual",
+		"to":                to,
+		"type":              "text",
+		"text":              map[string]interface{}{"preview_url": false, "body": message},
+	})
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/%s/messages", s.baseURL, s.phoneNumberID), bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Messages []struct {
+			ID string `json:"id"`
+		} `json:"messages"`
+		Error *struct {
+			Message string `json:"message"`
+			Code    int    `json:"code"`
+		} `json:"error"`
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if err := json.Unmarshal(body, &result); err != nil && resp.StatusCode < 300 {
+		return "", fmt.Errorf("meta api returned invalid body: %s", body)
+	}
+
+	if resp.StatusCode >= 300 || result.Error != nil {
+		if result.Error != nil {
+			return "", fmt.Errorf("meta api error %d: %s", result.Error.Code, result.Error.Message)
+		}
+		return "", fmt.Errorf("meta api returned status: %s", resp.Status)
+	}
+	if len(result.Messages) == 0 {
+		return "", errors.New("meta api returned no message id")
+	}
+	return result.Messages[0].ID, nil
+}
+
+// VerifyMetaSignature memvalidasi header X-Hub-Signature-256 ("sha256=<hex>") dari webhook Meta
+func VerifyMetaSignature(appSecret string, body []byte, header string) bool {
+	if appSecret == "" {
+		return false
+	}
+	signature, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// ParseMetaStatuses mengambil semua update status dari payload webhook Meta (entry[].changes[].value.statuses[])
+func ParseMetaStatuses(body []byte) ([]WhatsAppStatus, error) {
+	var payload struct {
+		Entry []struct {
+			Changes []struct {
+				Value struct {
+					Statuses []struct {
+						ID        string `json:"id"`
+						Status    string `json:"status"`
+						Timestamp string `json:"timestamp"`
+						Errors    []struct {
+							Code    int    `json:"code"`
+							Title   string `json:"title"`
+							Message string `json:"message"`
+						} `json:"errors"`
+					} `json:"statuses"`
+				} `json:"value"`
+			} `json:"changes"`
+		} `json:"entry"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	var statuses []WhatsAppStatus
+	for _, entry := range payload.Entry {
+		for _, change := range entry.Changes {
+			for _, st := range change.Value.Statuses {
+				status := WhatsAppStatus{MessageID: st.ID, Status: st.Status, Timestamp: time.Now()}
+				if sec, err := strconv.ParseInt(st.Timestamp, 10, 64); err == nil {
+					status.Timestamp = time.Unix(sec, 0)
+				}
+				if len(st.Errors) > 0 {
+					e := st.Errors[0]
+					status.Error = fmt.Sprintf("%d %s", e.Code, e.Title)
+					if e.Message != "" {
+						status.Error += ": " + e.Message
+					}
+				}
+				statuses = append(statuses, status)
+			}
+		}
+	}
+	return statuses, nil
+}
+
+// --- Generic HTTP webhook ---
+
+// Header untuk request/callback webhook generik. Signature = hex(HMAC-SHA256(secret, timestamp + "." + body)).
+const (
+	WebhookSignatureHeader = "X-Signature"
+	WebhookTimestampHeader = "X-Timestamp"
+	// Callback dengan timestamp lebih lama dari ini ditolak (mencegah replay)
+	WebhookSignatureTolerance = 5 * time.Minute
+)
+
+// WebhookSender meneruskan pesan ke gateway sendiri (misalnya bot WhatsApp self-hosted) lewat HTTP POST
+// bertanda tangan HMAC. Gateway boleh membalas {"id": "..."} untuk dicocokkan dengan callback status.
+type WebhookSender struct {
+	url    string
+	secret string
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewWebhookSender(url, secret string) *WebhookSender {
+	return &WebhookSender{url: url, secret: secret, client: &http.Client{Timeout: whatsAppHTTPTimeout}, now: time.Now}
+}
+
+func (s *WebhookSender) SendWhatsApp(to, message string) (string, error) {
+	body, _ := json.Marshal(map[string]string{"to": to, "message": message})
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhookPayload(s.secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("whatsapp webhook returned status: %s, body: %s", resp.Status, respBody)
+	}
+
+	var result struct {
+		ID string `json:"id"`
+	}
+	_ = json.Unmarshal(respBody, &result)
+	return result.ID, nil
+}
+
+// SignWebhookPayload menghitung signature hex untuk request/callback webhook generik
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature memvalidasi callback dari gateway webhook generik
+func VerifyWebhookSignature(secret, timestamp string, body []byte, header string, now time.Time) bool {
+	if secret == "" {
+		return false
+	}
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := now.Sub(time.Unix(sec, 0)); age > WebhookSignatureTolerance || age < -WebhookSignatureTolerance {
+		return false
+	}
+	signature, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(SignWebhookPayload(secret, timestamp, body)))
+}
+
+// --- Simulation ---
+
 type LogWASender struct{}
 
-func (s *LogWASender) SendWhatsApp(to, message string) error {
+func (s *LogWASender) SendWhatsApp(to, message string) (string, error) {
 	log.Printf("---------------------------------------------------------")
 	log.Printf("[WA SIMULATION] To: %s", to)
 	log.Printf("[WA SIMULATION] Message: %s", message)
 	log.Printf("---------------------------------------------------------")
-	return nil
+
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return "log-" + hex.EncodeToString(id), nil
 }
 
+// NewWhatsAppSender memilih provider sesuai WHATSAPP_PROVIDER (fonnte, meta, webhook, log).
+// Jika kosong: Fonnte bila FONNTE_TOKEN diisi, selain itu simulasi log.
 func NewWhatsAppSender(cfg *config.Config) WhatsAppSender {
+	switch cfg.WhatsAppProvider {
+	case "meta":
+		log.Println("[INFO] Initializing Meta WhatsApp Cloud API Sender")
+		return NewMetaCloudSender(cfg.MetaWAAPIURL, cfg.MetaWAToken, cfg.MetaWAPhoneNumberID)
+	case "webhook":
+		log.Println("[INFO] Initializing HTTP Webhook WhatsApp Sender")
+		return NewWebhookSender(cfg.WAWebhookURL, cfg.WAWebhookSecret)
+	case "log":
+		return &LogWASender{}
+	}
+
 	if cfg.FonnteToken != "" {
 		log.Println("[INFO] Initializing Real Fonnte WhatsApp Sender")
 		return NewFonnteSender(cfg.FonnteToken)
@@ -80,3 +356,29 @@ func NewWhatsAppSender(cfg *config.Config) WhatsAppSender {
 	log.Println("[WARNING] FONNTE_TOKEN is not set. WhatsApp messages will only be logged locally (Simulation Mode).")
 	return &LogWASender{}
 }
+
+// ParseWebhookStatus membaca callback status dari gateway webhook generik
+func ParseWebhookStatus(body []byte) (*WhatsAppStatus, error) {
+	var payload struct {
+		MessageID string `json:"message_id"`
+		Status    string `json:"status"`
+		Timestamp string `json:"timestamp"`
+		Error     string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.New("invalid payload")
+	}
+	if payload.MessageID == "" || payload.Status == "" {
+		return nil, errors.New("message_id dan status wajib diisi")
+	}
+
+	status := &WhatsAppStatus{MessageID: payload.MessageID, Status: payload.Status, Error: payload.Error}
+	if payload.Timestamp != "" {
+		ts, err := time.Parse(time.RFC3339, payload.Timestamp)
+		if err != nil {
+			return nil, errors.New("timestamp harus RFC3339")
+		}
+		status.Timestamp = ts
+	}
+	return status, nil
+}
@@ -0,0 +1,284 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"koskosan-be/internal/config"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Batas ukuran gambar yang diunduh dari pesan masuk (bukti transfer)
+const maxInboundMediaSize = 10 << 20
+
+// InboundWhatsApp adalah pesan masuk dari tenant, sudah dinormalisasi dari format masing-masing provider
+type InboundWhatsApp struct {
+	MessageID string
+	From      string // nomor pengirim format 62xxx
+	Name      string // nama profil WhatsApp, jika dikirim provider
+	Text      string // isi pesan atau caption gambar
+	Media     *InboundMedia
+}
+
+// InboundMedia adalah lampiran gambar. Meta hanya memberi ID (perlu diunduh lewat Graph API), provider lain memberi URL.
+type InboundMedia struct {
+	ID       string
+	URL      string
+	MimeType string
+}
+
+// WhatsAppMediaFetcher mengunduh lampiran pesan masuk, mengembalikan isi file dan content type
+type WhatsAppMediaFetcher interface {
+	FetchMedia(media InboundMedia) ([]byte, string, error)
+}
+
+// ParseMetaMessages mengambil pesan teks/gambar dari payload webhook Meta (entry[].changes[].value.messages[])
+func ParseMetaMessages(body []byte) ([]InboundWhatsApp, error) {
+	var payload struct {
+		Entry []struct {
+			Changes []struct {
+				Value struct {
+					Contacts []struct {
+						WaID    string `json:"wa_id"`
+						Profile struct {
+							Name string `json:"name"`
+						} `json:"profile"`
+					} `json:"contacts"`
+					Messages []struct {
+						ID   string `json:"id"`
+						From string `json:"from"`
+						Type string `json:"type"`
+						Text struct {
+							Body string `json:"body"`
+						} `json:"text"`
+						Image struct {
+							ID       string `json:"id"`
+							MimeType string `json:"mime_type"`
+							Caption  string `json:"caption"`
+						} `json:"image"`
+					} `json:"messages"`
+				} `json:"value"`
+			} `json:"changes"`
+		} `json:"entry"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	var messages []InboundWhatsApp
+	for _, entry := range payload.Entry {
+		for _, change := range entry.Changes {
+			names := map[string]string{}
+			for _, contact := range change.Value.Contacts {
+				names[contact.WaID] = contact.Profile.Name
+			}
+			for _, m := range change.Value.Messages {
+				msg := InboundWhatsApp{MessageID: m.ID, From: m.From, Name: names[m.From]}
+				switch m.Type {
+				case "text":
+					msg.Text = m.Text.Body
+				case "image":
+					msg.Text = m.Image.Caption
+					msg.Media = &InboundMedia{ID: m.Image.ID, MimeType: m.Image.MimeType}
+				default:
+					// Stiker, audio, lokasi, dll. diperlakukan sebagai pesan kosong (dibalas bantuan)
+				}
+				messages = append(messages, msg)
+			}
+		}
+	}
+	return messages, nil
+}
+
+// ParseWebhookMessage membaca pesan masuk dari gateway webhook generik.
+// Body: {"message_id": "...", "from": "62xxx", "name": "", "text": "", "media_url": "", "mime_type": ""}
+func ParseWebhookMessage(body []byte) (*InboundWhatsApp, error) {
+	var payload struct {
+		MessageID string `json:"message_id"`
+		From      string `json:"from"`
+		Name      string `json:"name"`
+		Text      string `json:"text"`
+		MediaURL  string `json:"media_url"`
+		MimeType  string `json:"mime_type"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.New("invalid payload")
+	}
+	if payload.From == "" {
+		return nil, errors.New("from wajib diisi")
+	}
+
+	msg := &InboundWhatsApp{MessageID: payload.MessageID, From: payload.From, Name: payload.Name, Text: payload.Text}
+	if payload.MediaURL != "" {
+		msg.Media = &InboundMedia{URL: payload.MediaURL, MimeType: payload.MimeType}
+	}
+	return msg, nil
+}
+
+// ParseFonnteMessage membaca webhook pesan masuk Fonnte (sender, message, name, url untuk lampiran)
+func ParseFonnteMessage(body []byte) (*InboundWhatsApp, error) {
+	var payload struct {
+		Sender    string `json:"sender"`
+		Message   string `json:"message"`
+		Name      string `json:"name"`
+		URL       string `json:"url"`
+		Extension string `json:"extension"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.New("invalid payload")
+	}
+	if payload.Sender == "" {
+		return nil, errors.New("sender wajib diisi")
+	}
+
+	msg := &InboundWhatsApp{From: payload.Sender, Name: payload.Name, Text: payload.Message}
+	if payload.URL != "" {
+		msg.Media = &InboundMedia{URL: payload.URL}
+		// Payload Fonnte tidak membawa ID pesan; URL lampiran unik per file sehingga dipakai untuk
+		// mengenali webhook yang dikirim ulang (pesan teks cukup dijawab ulang)
+		msg.MessageID = "fonnte:" + payload.URL
+	}
+	return msg, nil
+}
+
+// FetchMedia mengunduh lampiran Meta: GET /{media-id} untuk URL sementara, lalu unduh file dengan token yang sama
+func (s *MetaCloudSender) FetchMedia(media InboundMedia) ([]byte, string, error) {
+	if media.ID == "" {
+		return nil, "", errors.New("media id kosong")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/%s", s.baseURL, media.ID), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.token)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	var info struct {
+		URL      string `json:"url"`
+		MimeType string `json:"mime_type"`
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("meta media lookup returned status: %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil || info.URL == "" {
+		return nil, "", errors.New("meta media lookup returned no url")
+	}
+
+	return downloadMedia(s.client, info.URL, "Bearer "+s.token)
+}
+
+// httpMediaFetcher mengunduh lampiran dari URL yang diberikan provider (Fonnte, gateway generik).
+// Hanya host provider (allowedHosts, termasuk subdomainnya) yang boleh diunduh supaya payload webhook
+// tidak bisa membuat server mengakses alamat internal.
+type httpMediaFetcher struct {
+	client       *http.Client
+	allowedHosts []string
+}
+
+func newHTTPMediaFetcher(allowedHosts []string) *httpMediaFetcher {
+	f := &httpMediaFetcher{allowedHosts: allowedHosts}
+	f.client = &http.Client{
+		Timeout: whatsAppHTTPTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("terlalu banyak redirect")
+			}
+			return f.checkURL(req.URL)
+		},
+	}
+	return f
+}
+
+func (f *httpMediaFetcher) FetchMedia(media InboundMedia) ([]byte, string, error) {
+	if media.URL == "" {
+		return nil, "", errors.New("media url kosong")
+	}
+	u, err := url.Parse(media.URL)
+	if err != nil {
+		return nil, "", fmt.Errorf("media url tidak valid: %v", err)
+	}
+	if err := f.checkURL(u); err != nil {
+		return nil, "", err
+	}
+	return downloadMedia(f.client, media.URL, "")
+}
+
+func (f *httpMediaFetcher) checkURL(u *url.URL) error {
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return fmt.Errorf("skema media url tidak diizinkan: %q", u.Scheme)
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range f.allowedHosts {
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return nil
+		}
+	}
+	return fmt.Errorf("host media %q bukan host provider WhatsApp", host)
+}
+
+func downloadMedia(client *http.Client, mediaURL, authorization string) ([]byte, string, error) {
+	req, err := http.NewRequest(http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("media download returned status: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxInboundMediaSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxInboundMediaSize {
+		return nil, "", errors.New("ukuran gambar melebihi 10MB")
+	}
+
+	// Content type dari isi file, bukan dari header, supaya file non-gambar tidak lolos
+	contentType := http.DetectContentType(data)
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, "", fmt.Errorf("lampiran bukan gambar (%s)", contentType)
+	}
+	return data, contentType, nil
+}
+
+// NewWhatsAppMediaFetcher memakai provider yang sama dengan NewWhatsAppSender.
+// Host lampiran diambil dari WHATSAPP_MEDIA_HOSTS; kosong = fonnte.com untuk Fonnte, tidak ada untuk gateway generik.
+func NewWhatsAppMediaFetcher(cfg *config.Config) WhatsAppMediaFetcher {
+	if cfg.WhatsAppProvider == "meta" {
+		return NewMetaCloudSender(cfg.MetaWAAPIURL, cfg.MetaWAToken, cfg.MetaWAPhoneNumberID)
+	}
+
+	var hosts []string
+	for _, host := range strings.Split(cfg.WhatsAppMediaHosts, ",") {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 && cfg.WhatsAppProvider != "webhook" {
+		hosts = []string{"fonnte.com"}
+	}
+	if len(hosts) == 0 {
+		log.Println("[WARN] WHATSAPP_MEDIA_HOSTS is empty: images sent to the WhatsApp bot will be rejected")
+	}
+	return newHTTPMediaFetcher(hosts)
+}
@@ -0,0 +1,308 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaCloudSender_SendsTextMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/123456/messages", r.URL.Path)
+		assert.Equal(t, "Bearer meta-token", r.Header.Get("Authorization"))
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "whatsapp", body["messaging_product"])
+		assert.Equal(t, "6281234567890", body["to"])
+		assert.Equal(t, "text", body["type"])
+		assert.Equal(t, "halo", body["text"].(map[string]interface{})["body"])
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"messaging_product":"whatsapp","contacts":[{"wa_id":"6281234567890"}],"messages":[{"id":"wamid.HBgM"}]}`)
+	}))
+	defer server.Close()
+
+	id, err := NewMetaCloudSender(server.URL+"/", "meta-token", "123456").SendWhatsApp("6281234567890", "halo")
+
+	require.NoError(t, err)
+	assert.Equal(t, "wamid.HBgM", id)
+}
+
+func TestMetaCloudSender_ReturnsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = io.WriteString(w, `{"error":{"message":"Invalid OAuth access token.","type":"OAuthException","code":190}}`)
+	}))
+	defer server.Close()
+
+	id, err := NewMetaCloudSender(server.URL, "expired", "123456").SendWhatsApp("6281234567890", "halo")
+
+	require.Error(t, err)
+	assert.Empty(t, id)
+	assert.Contains(t, err.Error(), "190")
+	assert.Contains(t, err.Error(), "Invalid OAuth access token.")
+}
+
+func TestMetaCloudSender_NonJSONErrorResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	_, err := NewMetaCloudSender(server.URL, "token", "123456").SendWhatsApp("6281234567890", "halo")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "502")
+}
+
+func TestWebhookSender_SignsRequest(t *testing.T) {
+	now := time.Unix(1760860800, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp := r.Header.Get(WebhookTimestampHeader)
+		assert.Equal(t, strconv.FormatInt(now.Unix(), 10), timestamp)
+
+		// Signature dihitung ulang secara manual, bukan dengan helper yang sedang dites
+		mac := hmac.New(sha256.New, []byte("shared-secret"))
+		mac.Write([]byte(timestamp + "." + string(body)))
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), r.Header.Get(WebhookSignatureHeader))
+		assert.JSONEq(t, `{"to":"6281234567890","message":"halo"}`, string(body))
+
+		_, _ = io.WriteString(w, `{"id":"gw-42"}`)
+	}))
+	defer server.Close()
+
+	sender := NewWebhookSender(server.URL, "shared-secret")
+	sender.now = func() time.Time { return now }
+	id, err := sender.SendWhatsApp("6281234567890", "halo")
+
+	require.NoError(t, err)
+	assert.Equal(t, "gw-42", id)
+}
+
+func TestWebhookSender_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "device offline", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := NewWebhookSender(server.URL, "shared-secret").SendWhatsApp("6281234567890", "halo")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "device offline")
+}
+
+func TestFonnteSender_RejectedMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/send", r.URL.Path)
+		assert.Equal(t, "fonnte-token", r.Header.Get("Authorization"))
+		_, _ = io.WriteString(w, `{"status":false,"reason":"invalid token"}`)
+	}))
+	defer server.Close()
+
+	sender := NewFonnteSender("fonnte-token")
+	sender.baseURL = server.URL
+	_, err := sender.SendWhatsApp("6281234567890", "halo")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid token")
+}
+
+func TestFonnteSender_ReturnsMessageID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"status":true,"detail":"success! message in queue","id":[80367170],"process":"pending","target":["6281234567890"]}`)
+	}))
+	defer server.Close()
+
+	sender := NewFonnteSender("fonnte-token")
+	sender.baseURL = server.URL
+	id, err := sender.SendWhatsApp("6281234567890", "halo")
+
+	require.NoError(t, err)
+	assert.Equal(t, "80367170", id)
+}
+
+func TestVerifyMetaSignature(t *testing.T) {
+	body := []byte(`{"object":"whatsapp_business_account"}`)
+	mac := hmac.New(sha256.New, []byte("app-secret"))
+	mac.Write(body)
+	valid := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	assert.True(t, VerifyMetaSignature("app-secret", body, valid))
+	assert.False(t, VerifyMetaSignature("other-secret", body, valid))
+	assert.False(t, VerifyMetaSignature("app-secret", []byte(`{"object":"tampered"}`), valid))
+	assert.False(t, VerifyMetaSignature("app-secret", body, "sha1=abc"))
+	assert.False(t, VerifyMetaSignature("", body, valid))
+}
+
+func TestParseMetaStatuses(t *testing.T) {
+	body := []byte(`{
+		"object": "whatsapp_business_account",
+		"entry": [{
+			"id": "1",
+			"changes": [{
+				"field": "messages",
+				"value": {
+					"messaging_product": "whatsapp",
+					"statuses": [
+						{"id": "wamid.1", "status": "delivered", "timestamp": "1760860800", "recipient_id": "6281234567890"},
+						{"id": "wamid.2", "status": "failed", "timestamp": "1760860900", "errors": [{"code": 131026, "title": "Message undeliverable"}]}
+					]
+				}
+			}]
+		}]
+	}`)
+
+	statuses, err := ParseMetaStatuses(body)
+
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.Equal(t, WhatsAppStatus{MessageID: "wamid.1", Status: WhatsAppStatusDelivered, Timestamp: time.Unix(1760860800, 0)}, statuses[0])
+	assert.Equal(t, WhatsAppStatusFailed, statuses[1].Status)
+	assert.Equal(t, "131026 Message undeliverable", statuses[1].Error)
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	now := time.Unix(1760860800, 0)
+	body := []byte(`{"message_id":"gw-42","status":"read"}`)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := "sha256=" + SignWebhookPayload("shared-secret", timestamp, body)
+
+	assert.True(t, VerifyWebhookSignature("shared-secret", timestamp, body, signature, now))
+	assert.False(t, VerifyWebhookSignature("wrong-secret", timestamp, body, signature, now))
+	assert.False(t, VerifyWebhookSignature("shared-secret", timestamp, []byte(`{"message_id":"gw-42","status":"failed"}`), signature, now))
+	// Callback lama (replay) ditolak walaupun signature benar
+	assert.False(t, VerifyWebhookSignature("shared-secret", timestamp, body, signature, now.Add(10*time.Minute)))
+}
+
+func TestParseWebhookStatus(t *testing.T) {
+	status, err := ParseWebhookStatus([]byte(`{"message_id":"gw-42","status":"read","timestamp":"2026-10-19T08:00:00Z"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "gw-42", status.MessageID)
+	assert.Equal(t, WhatsAppStatusRead, status.Status)
+	assert.Equal(t, time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC), status.Timestamp)
+
+	_, err = ParseWebhookStatus([]byte(`{"status":"read"}`))
+	assert.Error(t, err)
+}
+
+func TestParseMetaMessages(t *testing.T) {
+	body := []byte(`{
+		"entry": [{
+			"changes": [{
+				"value": {
+					"contacts": [{"wa_id": "6281234567890", "profile": {"name": "Budi"}}],
+					"messages": [
+						{"id": "wamid.in1", "from": "6281234567890", "type": "text", "text": {"body": "TAGIHAN"}},
+						{"id": "wamid.in2", "from": "6281234567890", "type": "image", "image": {"id": "media-1", "mime_type": "image/jpeg", "caption": "bukti"}}
+					]
+				}
+			}]
+		}]
+	}`)
+
+	messages, err := ParseMetaMessages(body)
+
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, InboundWhatsApp{MessageID: "wamid.in1", From: "6281234567890", Name: "Budi", Text: "TAGIHAN"}, messages[0])
+	assert.Equal(t, "bukti", messages[1].Text)
+	assert.Equal(t, &InboundMedia{ID: "media-1", MimeType: "image/jpeg"}, messages[1].Media)
+}
+
+func TestParseFonnteMessage(t *testing.T) {
+	msg, err := ParseFonnteMessage([]byte(`{"device":"628111","sender":"6281234567890","message":"status","name":"Budi","url":"https://files.example.com/a.jpg","extension":"jpg"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "6281234567890", msg.From)
+	assert.Equal(t, "status", msg.Text)
+	assert.Equal(t, "https://files.example.com/a.jpg", msg.Media.URL)
+	assert.Equal(t, "fonnte:https://files.example.com/a.jpg", msg.MessageID)
+
+	_, err = ParseFonnteMessage([]byte(`{"message":"status"}`))
+	assert.Error(t, err)
+}
+
+// PNG 1x1 minimal, cukup untuk http.DetectContentType
+var testPNG = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00\x1f\x15\xc4\x89")
+
+func TestMetaCloudSender_FetchMedia(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer meta-token", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/media-1":
+			_, _ = io.WriteString(w, `{"url":"`+server.URL+`/download/media-1","mime_type":"image/png"}`)
+		case "/download/media-1":
+			_, _ = w.Write(testPNG)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	data, contentType, err := NewMetaCloudSender(server.URL, "meta-token", "123456").FetchMedia(InboundMedia{ID: "media-1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, testPNG, data)
+	assert.Equal(t, "image/png", contentType)
+}
+
+func TestHTTPMediaFetcher_RejectsNonImage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "%PDF-1.4 not an image")
+	}))
+	defer server.Close()
+
+	fetcher := newHTTPMediaFetcher([]string{"127.0.0.1"})
+	_, _, err := fetcher.FetchMedia(InboundMedia{URL: server.URL + "/file.pdf"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bukan gambar")
+}
+
+func TestHTTPMediaFetcher_OnlyDownloadsFromProviderHosts(t *testing.T) {
+	var requests int
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write(testPNG)
+	}))
+	defer internal.Close()
+
+	fetcher := newHTTPMediaFetcher([]string{"fonnte.com"})
+	for _, mediaURL := range []string{
+		internal.URL + "/a.png",
+		"http://169.254.169.254/latest/meta-data",
+		"https://fonnte.com.evil.example/a.png",
+		"file:///etc/passwd",
+	} {
+		_, _, err := fetcher.FetchMedia(InboundMedia{URL: mediaURL})
+		assert.Error(t, err, mediaURL)
+	}
+	assert.Zero(t, requests)
+
+	assert.NoError(t, fetcher.checkURL(&url.URL{Scheme: "https", Host: "api.fonnte.com"}))
+
+	// Redirect dari host provider ke host lain juga ditolak
+	internalURL, _ := url.Parse(internal.URL)
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://localhost:"+internalURL.Port()+"/a.png", http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	_, _, err := newHTTPMediaFetcher([]string{"127.0.0.1"}).FetchMedia(InboundMedia{URL: redirect.URL + "/a.png"})
+	assert.Error(t, err)
+	assert.Zero(t, requests)
+}
@@ -45,6 +45,23 @@ func ValidatePhone(phone string) error {
 	return nil
 }
 
+// NormalizePhone mengubah nomor HP Indonesia ke format 62xxxxxxxxxx (dipakai gateway WhatsApp/SMS).
+// Spasi, tanda hubung dan titik diabaikan.
+func NormalizePhone(phone string) (string, error) {
+	cleaned := strings.NewReplacer(" ", "", "-", "", ".", "").Replace(strings.TrimSpace(phone))
+	if err := ValidatePhone(cleaned); err != nil {
+		return "", err
+	}
+	switch {
+	case strings.HasPrefix(cleaned, "+62"):
+		return cleaned[1:], nil
+	case strings.HasPrefix(cleaned, "0"):
+		return "62" + cleaned[1:], nil
+	default:
+		return cleaned, nil
+	}
+}
+
 // ValidateDateRange validates that start date is before end date
 // and both are not in the past
 func ValidateDateRange(start, end time.Time) error {
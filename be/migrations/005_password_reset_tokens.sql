@@ -0,0 +1,8 @@
+-- Migration: Move password reset tokens out of users
+-- Purpose: Reset tokens are now stored hashed (SHA-256) in password_reset_tokens
+--          with creation IP, expiry and single-use used_at (created by AutoMigrate).
+-- Date: 2026-10-19
+
+-- Outstanding plaintext tokens can no longer be redeemed; users simply request a new link.
+ALTER TABLE users DROP COLUMN IF EXISTS reset_token;
+ALTER TABLE users DROP COLUMN IF EXISTS reset_token_expiry;
@@ -0,0 +1,10 @@
+-- Migration: In-app notification inbox
+-- Purpose: Every event sent to a user (socket, email, WhatsApp) is also stored in
+--          notifications so tenants can see their history and unread count.
+--          The table itself is created by AutoMigrate; this adds the partial index
+--          used by the unread counter.
+-- Date: 2026-10-19
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_unread
+    ON notifications (user_id)
+    WHERE read_at IS NULL;
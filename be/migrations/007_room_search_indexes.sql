@@ -0,0 +1,27 @@
+-- Migration: Room search indexes
+-- Purpose: GET /api/kamar now filters by type, price, floor, capacity, facilities and
+--          availability, and sorts by price/rating/newest. The plain column indexes are
+--          also declared on models.Kamar (AutoMigrate uses the same names); this file adds
+--          them to existing databases together with the indexes AutoMigrate cannot express.
+-- Date: 2026-10-19
+
+CREATE INDEX IF NOT EXISTS idx_kamars_tipe_kamar ON kamars (tipe_kamar);
+CREATE INDEX IF NOT EXISTS idx_kamars_harga_per_bulan ON kamars (harga_per_bulan);
+CREATE INDEX IF NOT EXISTS idx_kamars_floor ON kamars (floor);
+CREATE INDEX IF NOT EXISTS idx_kamars_capacity ON kamars (capacity);
+CREATE INDEX IF NOT EXISTS idx_kamars_created_at ON kamars (created_at DESC);
+
+-- fasilitas ILIKE '%wi-fi%' (filter fasilitas)
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_kamars_fasilitas_trgm
+    ON kamars USING gin (fasilitas gin_trgm_ops);
+
+-- Cek ketersediaan: pemesanan aktif per kamar yang bertumpuk dengan rentang tanggal
+CREATE INDEX IF NOT EXISTS idx_pemesanans_kamar_active
+    ON pemesanans (kamar_id, tanggal_mulai)
+    WHERE status_pemesanan IN ('Pending', 'Confirmed') AND deleted_at IS NULL;
+
+-- Urutan rating: rata-rata ulasan per kamar
+CREATE INDEX IF NOT EXISTS idx_reviews_kamar_rating
+    ON reviews (kamar_id, rating)
+    WHERE deleted_at IS NULL;
@@ -0,0 +1,49 @@
+-- Migration: Structured room facilities
+-- Purpose: Kamar.fasilitas was a free-text list ("AC, Wi-Fi, Kamar Mandi Dalam").
+--          Rooms now link to a facilities catalogue through kamar_facilities (both
+--          created by AutoMigrate). This parses the existing strings into the
+--          catalogue, links every room, and rewrites kamars.fasilitas in the
+--          normalised form the API keeps in sync from now on. Safe to re-run.
+-- Date: 2026-10-19
+
+BEGIN;
+
+-- 1. Satu entri katalog per nama (tidak peka huruf besar, spasi dirapikan)
+INSERT INTO facilities (name, icon_key, category, created_at, updated_at)
+SELECT DISTINCT ON (LOWER(parsed.name)) parsed.name, '', 'other', NOW(), NOW()
+FROM (
+    SELECT regexp_replace(trim(part), '\s+', ' ', 'g') AS name
+    FROM kamars k, regexp_split_to_table(k.fasilitas, ',') AS part
+    WHERE k.deleted_at IS NULL
+) parsed
+WHERE parsed.name <> ''
+  AND NOT EXISTS (SELECT 1 FROM facilities f WHERE LOWER(f.name) = LOWER(parsed.name))
+ORDER BY LOWER(parsed.name), parsed.name;
+
+-- 2. Ikon dan kategori untuk nama yang umum dipakai
+UPDATE facilities SET icon_key = 'ac', category = 'room' WHERE icon_key = '' AND LOWER(name) IN ('ac', 'air conditioner');
+UPDATE facilities SET icon_key = 'wifi', category = 'building' WHERE icon_key = '' AND LOWER(name) IN ('wi-fi', 'wifi', 'internet');
+UPDATE facilities SET icon_key = 'bath', category = 'bathroom' WHERE icon_key = '' AND LOWER(name) LIKE 'kamar mandi%';
+UPDATE facilities SET icon_key = 'wardrobe', category = 'room' WHERE icon_key = '' AND LOWER(name) = 'lemari';
+UPDATE facilities SET icon_key = 'desk', category = 'room' WHERE icon_key = '' AND LOWER(name) IN ('meja', 'meja belajar');
+UPDATE facilities SET icon_key = 'bed', category = 'room' WHERE icon_key = '' AND LOWER(name) IN ('kasur', 'tempat tidur', 'spring bed');
+UPDATE facilities SET icon_key = 'parking', category = 'building' WHERE icon_key = '' AND LOWER(name) LIKE 'parkir%';
+
+-- 3. Tautkan kamar ke katalog
+INSERT INTO kamar_facilities (kamar_id, facility_id)
+SELECT DISTINCT k.id, f.id
+FROM kamars k
+CROSS JOIN LATERAL regexp_split_to_table(k.fasilitas, ',') AS part
+JOIN facilities f ON LOWER(f.name) = LOWER(regexp_replace(trim(part), '\s+', ' ', 'g'))
+WHERE k.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+-- 4. Tulis ulang teks fasilitas dari katalog (urut nama, sama dengan API)
+UPDATE kamars SET fasilitas = COALESCE((
+    SELECT string_agg(f.name, ', ' ORDER BY f.name)
+    FROM kamar_facilities kf JOIN facilities f ON f.id = kf.facility_id
+    WHERE kf.kamar_id = kamars.id
+), '')
+WHERE deleted_at IS NULL;
+
+COMMIT;
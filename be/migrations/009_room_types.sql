@@ -0,0 +1,44 @@
+-- Migration: Room types as entities
+-- Purpose: Kamar.tipe_kamar was a string copied onto every room. Rooms now point
+--          to room_types (created by AutoMigrate together with room_type_images
+--          and the kamars.room_type_id / override_* columns). This creates one
+--          type per distinct tipe_kamar, takes its defaults from the oldest room
+--          of that type, links the rooms, and marks values that differ from the
+--          type as overrides so no room changes price. Safe to re-run.
+-- Date: 2026-10-19
+
+BEGIN;
+
+-- 1. Satu tipe per nama tipe_kamar (tidak peka huruf besar); nilai bawaan dari kamar tertua
+INSERT INTO room_types (name, harga_per_bulan, size, capacity, description, created_at, updated_at)
+SELECT DISTINCT ON (LOWER(trim(k.tipe_kamar)))
+    trim(k.tipe_kamar), k.harga_per_bulan, COALESCE(k.size, ''), COALESCE(k.capacity, 0),
+    COALESCE(k.description, ''), NOW(), NOW()
+FROM kamars k
+WHERE k.deleted_at IS NULL
+  AND trim(COALESCE(k.tipe_kamar, '')) <> ''
+  AND NOT EXISTS (SELECT 1 FROM room_types rt WHERE LOWER(rt.name) = LOWER(trim(k.tipe_kamar)))
+ORDER BY LOWER(trim(k.tipe_kamar)), k.id;
+
+-- 2. Tautkan kamar yang belum punya tipe
+UPDATE kamars k
+SET room_type_id = rt.id, tipe_kamar = rt.name
+FROM room_types rt
+WHERE k.room_type_id IS NULL
+  AND k.deleted_at IS NULL
+  AND LOWER(rt.name) = LOWER(trim(k.tipe_kamar));
+
+-- 3. Nilai kamar yang berbeda dari tipenya dipertahankan sebagai override
+UPDATE kamars k
+SET override_price       = k.harga_per_bulan IS DISTINCT FROM rt.harga_per_bulan,
+    override_size        = COALESCE(k.size, '') <> rt.size,
+    override_capacity    = COALESCE(k.capacity, 0) <> rt.capacity,
+    override_description = COALESCE(k.description, '') <> rt.description
+FROM room_types rt
+WHERE rt.id = k.room_type_id
+  AND k.deleted_at IS NULL;
+
+-- 4. Laporan pendapatan per tipe
+CREATE INDEX IF NOT EXISTS idx_kamars_room_type_status ON kamars (room_type_id, status) WHERE deleted_at IS NULL;
+
+COMMIT;
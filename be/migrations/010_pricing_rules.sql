@@ -0,0 +1,45 @@
+-- Migration: Seasonal and promotional pricing rules
+-- Purpose: pricing_rules and booking_discounts, and the price snapshot columns on
+--          pemesanans (harga_per_bulan, subtotal, diskon, total_harga) and
+--          pembayarans (subtotal, diskon, jumlah_bulan), are created by AutoMigrate.
+--          This locks existing bookings at the price they were billed at (full
+--          room price, no discount) so payment sessions do not re-price them, and
+--          records how many months each existing extend bill covers. Safe to re-run.
+-- Date: 2026-10-19
+
+BEGIN;
+
+-- 1. Snapshot harga untuk pemesanan lama (harga kamar saat ini, tanpa diskon)
+UPDATE pemesanans p
+SET harga_per_bulan = k.harga_per_bulan,
+    subtotal        = k.harga_per_bulan * p.durasi_sewa,
+    diskon          = 0,
+    total_harga     = k.harga_per_bulan * p.durasi_sewa
+FROM kamars k
+WHERE k.id = p.kamar_id
+  AND COALESCE(p.total_harga, 0) = 0;
+
+-- 2. Jumlah bulan tagihan perpanjangan lama (sebelumnya dihitung dari nominal / harga kamar)
+UPDATE pembayarans b
+SET jumlah_bulan = GREATEST(FLOOR(b.jumlah_bayar / k.harga_per_bulan), 1),
+    subtotal     = b.jumlah_bayar
+FROM pemesanans p
+JOIN kamars k ON k.id = p.kamar_id
+WHERE p.id = b.pemesanan_id
+  AND b.tipe_pembayaran = 'extend'
+  AND COALESCE(b.jumlah_bulan, 0) = 0
+  AND k.harga_per_bulan > 0;
+
+-- 3. Rincian tagihan lainnya mengikuti snapshot pemesanan
+UPDATE pembayarans b
+SET subtotal = p.subtotal
+FROM pemesanans p
+WHERE p.id = b.pemesanan_id
+  AND b.tipe_pembayaran <> 'extend'
+  AND COALESCE(b.subtotal, 0) = 0;
+
+-- 4. Pencarian voucher (kode unik di antara voucher) dan aturan aktif
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pricing_rules_voucher_code ON pricing_rules (code) WHERE type = 'voucher';
+CREATE INDEX IF NOT EXISTS idx_pricing_rules_active_type ON pricing_rules (type) WHERE active;
+
+COMMIT;
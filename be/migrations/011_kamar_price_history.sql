@@ -0,0 +1,23 @@
+-- Migration: Room price history and scheduled rent changes
+-- Purpose: kamar_price_histories is created by AutoMigrate. This seeds one history
+--          row per existing room with its current price (effective from when the
+--          room was created) so the history starts complete, and indexes the
+--          lookups used by the daily notice/apply job. Safe to re-run.
+-- Date: 2026-10-19
+
+BEGIN;
+
+-- 1. Harga awal kamar yang sudah ada
+INSERT INTO kamar_price_histories (kamar_id, harga_sebelum, harga_per_bulan, effective_from, source, applied_at, created_at)
+SELECT k.id, 0, k.harga_per_bulan, k.created_at, 'manual', k.created_at, NOW()
+FROM kamars k
+WHERE k.deleted_at IS NULL
+  AND NOT EXISTS (SELECT 1 FROM kamar_price_histories h WHERE h.kamar_id = k.id);
+
+-- 2. Perubahan terjadwal yang menunggu pemberitahuan / diterapkan
+CREATE INDEX IF NOT EXISTS idx_kamar_price_histories_pending_notice
+    ON kamar_price_histories (effective_from) WHERE source = 'scheduled' AND notice_sent_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_kamar_price_histories_pending_apply
+    ON kamar_price_histories (effective_from) WHERE source = 'scheduled' AND applied_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,26 @@
+-- Migration: Room image ordering and cover
+-- Purpose: kamar_images.sort_order is created by AutoMigrate. This numbers the
+--          existing images per room, putting the image that matches the room's
+--          current cover (kamars.image_url) first and keeping upload order for the
+--          rest, so the first image and image_url agree. Safe to re-run.
+-- Date: 2026-10-19
+
+BEGIN;
+
+UPDATE kamar_images ki
+SET sort_order = ordered.position
+FROM (
+    SELECT i.id,
+           ROW_NUMBER() OVER (
+               PARTITION BY i.kamar_id
+               ORDER BY (i.image_url = k.image_url) DESC, i.id
+           ) - 1 AS position
+    FROM kamar_images i
+    JOIN kamars k ON k.id = i.kamar_id
+    WHERE i.deleted_at IS NULL
+) ordered
+WHERE ki.id = ordered.id;
+
+CREATE INDEX IF NOT EXISTS idx_kamar_images_kamar_order ON kamar_images (kamar_id, sort_order) WHERE deleted_at IS NULL;
+
+COMMIT;
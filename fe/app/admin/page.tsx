@@ -7,6 +7,13 @@ import { LuxuryRoomManagement } from "@/app/components/admin/LuxuryRoomManagemen
 import { TenantData } from "@/app/components/admin/TenantData";
 import { LuxuryPaymentConfirmation } from "@/app/components/admin/LuxuryPaymentConfirmation";
 import { GalleryData } from "@/app/components/admin/GalleryData";
+import { NotificationDeliveries } from "@/app/components/admin/NotificationDeliveries";
+import { MaintenanceTickets } from "@/app/components/admin/MaintenanceTickets";
+import { ContactInbox } from "@/app/components/admin/ContactInbox";
+import { SpamProtectionSettings } from "@/app/components/admin/SpamProtectionSettings";
+import { FacilityCatalog } from "@/app/components/admin/FacilityCatalog";
+import { RoomTypeCatalog } from "@/app/components/admin/RoomTypeCatalog";
+import { PricingRules } from "@/app/components/admin/PricingRules";
 import { AdminLogin } from "@/app/components/shared/AdminLogin";
 import { api } from "@/app/services/api";
 import { Button } from "@/app/components/ui/button";
@@ -71,14 +78,28 @@ export default function AdminPage() {
         return <LuxuryDashboard key="dashboard" />;
       case "rooms":
         return <LuxuryRoomManagement key="rooms" />;
+      case "room-types":
+        return <RoomTypeCatalog key="room-types" />;
+      case "pricing":
+        return <PricingRules key="pricing" />;
+      case "facilities":
+        return <FacilityCatalog key="facilities" />;
       case "tenants":
         return <TenantData key="tenants" />;
       case "payments":
         return <LuxuryPaymentConfirmation key="payments" />;
+      case "tickets":
+        return <MaintenanceTickets key="tickets" />;
+      case "messages":
+        return <ContactInbox key="messages" />;
+      case "spam":
+        return <SpamProtectionSettings key="spam" />;
       case "reports":
         return <LuxuryReports key="reports" />;
       case "gallery":
         return <GalleryData key="gallery" />;
+      case "deliveries":
+        return <NotificationDeliveries key="deliveries" />;
       default:
         return <LuxuryDashboard key="default" />;
     }
@@ -1,6 +1,6 @@
 'use client';
 
-import { LayoutDashboard, Image as LucideImageIcon, Home, Users, CreditCard, TrendingUp } from 'lucide-react';
+import { LayoutDashboard, Image as LucideImageIcon, Home, Users, CreditCard, TrendingUp, Send, Wrench, Mail, ShieldAlert, Sofa, BedDouble, BadgePercent } from 'lucide-react';
 import { useState, useEffect } from 'react';
 import NextImage from 'next/image';
 import { ThemeToggleButton } from '@/app/components/ui/ThemeToggleButton';
@@ -24,10 +24,17 @@ export function AdminSidebar({ currentPage, onNavigate }: AdminSidebarProps) {
   const menuItems = [
     { id: 'dashboard', label: t('dashboard'), icon: LayoutDashboard },
     { id: 'rooms', label: t('rooms'), icon: Home },
+    { id: 'room-types', label: t('roomTypes'), icon: BedDouble },
+    { id: 'pricing', label: t('pricingRules'), icon: BadgePercent },
+    { id: 'facilities', label: t('facilityCatalog'), icon: Sofa },
     { id: 'tenants', label: t('tenants'), icon: Users },
     { id: 'payments', label: t('payments'), icon: CreditCard },
+    { id: 'tickets', label: t('tickets'), icon: Wrench },
+    { id: 'messages', label: t('messages'), icon: Mail },
+    { id: 'spam', label: t('spam'), icon: ShieldAlert },
     { id: 'reports', label: t('reports'), icon: TrendingUp },
-    { id: 'gallery', label: t('gallery'), icon: LucideImageIcon }
+    { id: 'gallery', label: t('gallery'), icon: LucideImageIcon },
+    { id: 'deliveries', label: t('deliveries'), icon: Send }
   ];
 
 
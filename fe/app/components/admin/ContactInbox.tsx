@@ -0,0 +1,235 @@
+"use client";
+
+import { useState, useEffect, useCallback } from 'react';
+import { Mail, Loader2, Inbox, Send, UserCheck, ShieldAlert, ShieldCheck } from 'lucide-react';
+import { toast } from 'sonner';
+import { Button } from '@/app/components/ui/button';
+import { Input } from '@/app/components/ui/input';
+import { Textarea } from '@/app/components/ui/textarea';
+import { Dialog, DialogContent, DialogTitle } from '@/app/components/ui/dialog';
+import { api, ContactMessage, ContactStatus } from '@/app/services/api';
+import { useTranslations } from 'next-intl';
+import { motion } from "framer-motion";
+
+type StatusFilter = ContactStatus | '';
+
+const contactStatusStyles: Record<ContactStatus, string> = {
+  new: 'bg-amber-500/10 text-amber-600 dark:text-amber-500 border-amber-500/20',
+  read: 'bg-slate-500/10 text-slate-500 border-slate-500/20',
+  replied: 'bg-emerald-500/10 text-emerald-600 dark:text-emerald-500 border-emerald-500/20',
+  spam: 'bg-red-500/10 text-red-500 border-red-500/20',
+};
+
+export function ContactInbox() {
+  const t = useTranslations('contactInbox');
+  const [status, setStatus] = useState<StatusFilter>('');
+  const [messages, setMessages] = useState<ContactMessage[]>([]);
+  const [isLoading, setIsLoading] = useState(false);
+  const [selected, setSelected] = useState<ContactMessage | null>(null);
+  const [subject, setSubject] = useState('');
+  const [reply, setReply] = useState('');
+  const [isSaving, setIsSaving] = useState(false);
+
+  const fetchMessages = useCallback(async () => {
+    setIsLoading(true);
+    try {
+      const res = await api.getContactMessages(status);
+      setMessages(res.data || []);
+    } catch (error) {
+      console.error("Failed to fetch contact messages:", error);
+    } finally {
+      setIsLoading(false);
+    }
+  }, [status]);
+
+  useEffect(() => {
+    void fetchMessages();
+  }, [fetchMessages]);
+
+  const openMessage = async (id: number) => {
+    try {
+      const res = await api.getContactMessage(id);
+      setSelected(res.data);
+      setSubject('');
+      setReply('');
+      // Pesan baru otomatis menjadi "read" saat dibuka
+      setMessages((prev) => prev.map((m) => (m.id === id ? { ...m, status: res.data.status } : m)));
+    } catch (error) {
+      console.error("Failed to fetch contact message:", error);
+    }
+  };
+
+  // Jalankan aksi lalu muat ulang detail dan daftar
+  const runAction = async (action: () => Promise<unknown>, successMessage?: string) => {
+    if (!selected) return;
+    setIsSaving(true);
+    try {
+      await action();
+      if (successMessage) toast.success(successMessage);
+      await openMessage(selected.id);
+      void fetchMessages();
+    } catch (error) {
+      toast.error(error instanceof Error ? error.message : t('actionFailed'));
+    } finally {
+      setIsSaving(false);
+    }
+  };
+
+  const statusFilters: StatusFilter[] = ['', 'new', 'read', 'replied', 'spam'];
+
+  return (
+    <div className="p-4 md:p-8 space-y-6 md:space-y-8 bg-gray-50 dark:bg-slate-950 min-h-screen">
+      <motion.div
+        initial={{ opacity: 0, y: -20 }}
+        animate={{ opacity: 1, y: 0 }}
+        transition={{ duration: 0.4 }}
+        className="flex flex-col md:flex-row md:items-center justify-between gap-4"
+      >
+        <div>
+          <h2 className="text-2xl md:text-3xl font-bold text-amber-600 dark:text-amber-500">{t('title')}</h2>
+          <p className="text-slate-500 dark:text-slate-400 text-xs md:text-sm">{t('subtitle')}</p>
+        </div>
+        <div className="flex flex-wrap gap-2">
+          {statusFilters.map((s) => (
+            <Button
+              key={s || 'all'}
+              variant="ghost"
+              size="sm"
+              onClick={() => setStatus(s)}
+              className={`rounded-xl text-xs font-bold ${status === s
+                ? 'bg-amber-500/15 text-amber-600 dark:text-amber-400'
+                : 'text-slate-500 dark:text-slate-400 hover:bg-slate-100 dark:hover:bg-slate-800'
+              }`}
+            >
+              {s ? t(`status.${s}`) : t('all')}
+            </Button>
+          ))}
+        </div>
+      </motion.div>
+
+      <motion.div
+        initial={{ opacity: 0, y: 20 }}
+        animate={{ opacity: 1, y: 0 }}
+        transition={{ delay: 0.1, duration: 0.4 }}
+        className="bg-white dark:bg-slate-900/40 rounded-2xl border border-slate-200 dark:border-slate-800 overflow-x-auto pb-20 md:pb-0"
+      >
+        {isLoading ? (
+          <div className="py-20 flex justify-center">
+            <Loader2 className="size-8 animate-spin text-amber-500" />
+          </div>
+        ) : messages.length === 0 ? (
+          <div className="py-20 text-center">
+            <Inbox className="size-12 text-slate-400 dark:text-slate-700 mx-auto mb-4" />
+            <p className="text-slate-500">{t('empty')}</p>
+          </div>
+        ) : (
+          <table className="w-full text-sm">
+            <thead>
+              <tr className="text-left text-[10px] uppercase tracking-wider text-slate-500 border-b border-slate-200 dark:border-slate-800">
+                <th className="p-4">{t('sender')}</th>
+                <th className="p-4">{t('message')}</th>
+                <th className="p-4">{t('assignee')}</th>
+                <th className="p-4">{t('received')}</th>
+              </tr>
+            </thead>
+            <tbody>
+              {messages.map((message) => (
+                <tr
+                  key={message.id}
+                  onClick={() => openMessage(message.id)}
+                  className="border-b border-slate-100 dark:border-slate-800/50 text-slate-700 dark:text-slate-300 cursor-pointer hover:bg-slate-50 dark:hover:bg-slate-800/40"
+                >
+                  <td className="p-4">
+                    <div className="flex items-center gap-2">
+                      <Mail className={`size-4 ${message.status === 'new' ? 'text-amber-500' : 'text-slate-400'}`} />
+                      <span className={`text-slate-900 dark:text-white ${message.status === 'new' ? 'font-bold' : 'font-medium'}`}>{message.name}</span>
+                    </div>
+                    <span className="text-xs text-slate-500">{message.email}</span>
+                  </td>
+                  <td className="p-4 max-w-md">
+                    <p className="truncate">{message.message}</p>
+                    <span className={`inline-block mt-1 px-2 py-0.5 text-[10px] font-bold rounded-lg border uppercase ${contactStatusStyles[message.status]}`}>
+                      {t(`status.${message.status}`)}
+                    </span>
+                  </td>
+                  <td className="p-4 text-xs">{message.assignee?.username || '-'}</td>
+                  <td className="p-4 text-xs text-slate-500">
+                    {new Date(message.created_at).toLocaleString('id-ID', { day: 'numeric', month: 'short', hour: '2-digit', minute: '2-digit' })}
+                  </td>
+                </tr>
+              ))}
+            </tbody>
+          </table>
+        )}
+      </motion.div>
+
+      <Dialog open={!!selected} onOpenChange={(open) => !open && setSelected(null)}>
+        <DialogContent className="w-[95vw] max-w-2xl bg-white dark:bg-slate-900 border-slate-200 dark:border-slate-800 text-slate-900 dark:text-white rounded-2xl max-h-[90vh] overflow-y-auto">
+          <DialogTitle>{selected ? `${selected.name} <${selected.email}>` : ''}</DialogTitle>
+          {selected && (
+            <div className="space-y-5">
+              <div className="flex flex-wrap items-center gap-2 text-xs">
+                <span className={`px-2 py-0.5 font-bold rounded-lg border uppercase ${contactStatusStyles[selected.status]}`}>{t(`status.${selected.status}`)}</span>
+                <span className="text-slate-500">
+                  {new Date(selected.created_at).toLocaleString('id-ID', { day: 'numeric', month: 'short', year: 'numeric', hour: '2-digit', minute: '2-digit' })}
+                </span>
+                <span className="text-slate-500">· {t('assignee')}: {selected.assignee?.username || '-'}</span>
+              </div>
+
+              <p className="text-sm text-slate-700 dark:text-slate-300 whitespace-pre-line bg-slate-50 dark:bg-slate-800/50 p-4 rounded-xl">{selected.message}</p>
+
+              <div className="flex flex-wrap gap-2">
+                <Button size="sm" variant="ghost" disabled={isSaving} onClick={() => runAction(() => api.assignContactMessage(selected.id))} className="rounded-xl text-xs">
+                  <UserCheck className="size-4 mr-1" />
+                  {t('assignToMe')}
+                </Button>
+                <Button
+                  size="sm"
+                  variant="ghost"
+                  disabled={isSaving}
+                  onClick={() => runAction(() => api.markContactSpam(selected.id, selected.status !== 'spam'))}
+                  className="rounded-xl text-xs"
+                >
+                  {selected.status === 'spam' ? <ShieldCheck className="size-4 mr-1" /> : <ShieldAlert className="size-4 mr-1" />}
+                  {selected.status === 'spam' ? t('notSpam') : t('markSpam')}
+                </Button>
+              </div>
+
+              <div className="space-y-3">
+                <h4 className="text-sm font-semibold">{t('replies')}</h4>
+                {(selected.replies || []).length === 0 && <p className="text-xs text-slate-500">{t('noReplies')}</p>}
+                {(selected.replies || []).map((r) => (
+                  <div key={r.id} className="p-3 rounded-xl text-sm bg-amber-500/10 ml-8">
+                    <p className="text-[10px] text-slate-500 mb-1">
+                      {r.author_name} · {new Date(r.created_at).toLocaleString('id-ID', { day: 'numeric', month: 'short', hour: '2-digit', minute: '2-digit' })}
+                    </p>
+                    <p className="text-xs font-semibold mb-1">{r.subject}</p>
+                    <p className="whitespace-pre-line">{r.message}</p>
+                  </div>
+                ))}
+              </div>
+
+              {selected.status !== 'spam' && (
+                <div className="space-y-3">
+                  <Input value={subject} onChange={(e) => setSubject(e.target.value)} placeholder={t('subjectPlaceholder')} maxLength={150} className="rounded-xl" />
+                  <Textarea value={reply} onChange={(e) => setReply(e.target.value)} placeholder={t('replyPlaceholder')} className="rounded-xl min-h-28" />
+                  <div className="flex justify-end">
+                    <Button
+                      size="sm"
+                      disabled={isSaving || !reply.trim()}
+                      onClick={() => runAction(() => api.replyContactMessage(selected.id, subject, reply), t('replySent'))}
+                      className="bg-amber-500 hover:bg-amber-600 text-white rounded-xl"
+                    >
+                      {isSaving ? <Loader2 className="size-4 animate-spin mr-1" /> : <Send className="size-4 mr-1" />}
+                      {t('sendReply')}
+                    </Button>
+                  </div>
+                </div>
+              )}
+            </div>
+          )}
+        </DialogContent>
+      </Dialog>
+    </div>
+  );
+}
@@ -0,0 +1,182 @@
+"use client";
+
+import { useState, useEffect, useCallback } from 'react';
+import { Plus, Loader2, Pencil, Trash2, Save, X, Sofa } from 'lucide-react';
+import { toast } from 'sonner';
+import { Button } from '@/app/components/ui/button';
+import { Input } from '@/app/components/ui/input';
+import { api, Facility, FacilityCategory } from '@/app/services/api';
+import { FacilityIcon, facilityIcons } from '@/app/components/shared/FacilityIcon';
+import { useTranslations } from 'next-intl';
+import { motion } from "framer-motion";
+
+type FacilityDraft = Pick<Facility, 'name' | 'icon_key' | 'category'>;
+
+const emptyDraft: FacilityDraft = { name: '', icon_key: '', category: 'other' };
+const fallbackCategories: FacilityCategory[] = ['room', 'bathroom', 'building', 'service', 'other'];
+
+export function FacilityCatalog() {
+  const t = useTranslations('facilityCatalog');
+  const [facilities, setFacilities] = useState<Facility[]>([]);
+  const [categories, setCategories] = useState<FacilityCategory[]>(fallbackCategories);
+  const [isLoading, setIsLoading] = useState(false);
+  const [isSaving, setIsSaving] = useState(false);
+  const [draft, setDraft] = useState<FacilityDraft>(emptyDraft);
+  // null = form tambah, angka = sedang mengedit fasilitas tersebut
+  const [editingId, setEditingId] = useState<number | null>(null);
+
+  const fetchFacilities = useCallback(async () => {
+    setIsLoading(true);
+    try {
+      const res = await api.getFacilities();
+      setFacilities(res.data || []);
+      if (res.categories?.length) setCategories(res.categories);
+    } catch (error) {
+      console.error("Failed to fetch facilities:", error);
+    } finally {
+      setIsLoading(false);
+    }
+  }, []);
+
+  useEffect(() => {
+    void fetchFacilities();
+  }, [fetchFacilities]);
+
+  const resetDraft = () => {
+    setDraft(emptyDraft);
+    setEditingId(null);
+  };
+
+  const handleSave = async () => {
+    if (!draft.name.trim()) {
+      toast.error(t('nameRequired'));
+      return;
+    }
+    setIsSaving(true);
+    try {
+      if (editingId) {
+        await api.updateFacility(editingId, draft);
+        toast.success(t('updated'));
+      } else {
+        await api.createFacility(draft);
+        toast.success(t('created'));
+      }
+      resetDraft();
+      await fetchFacilities();
+    } catch (error) {
+      toast.error(error instanceof Error ? error.message : t('saveFailed'));
+    } finally {
+      setIsSaving(false);
+    }
+  };
+
+  const handleDelete = async (facility: Facility) => {
+    if (!window.confirm(t('deleteConfirm', { name: facility.name }))) return;
+    try {
+      await api.deleteFacility(facility.id);
+      toast.success(t('deleted'));
+      if (editingId === facility.id) resetDraft();
+      await fetchFacilities();
+    } catch (error) {
+      toast.error(error instanceof Error ? error.message : t('deleteFailed'));
+    }
+  };
+
+  return (
+    <div className="p-4 md:p-8 space-y-6 md:space-y-8 bg-gray-50 dark:bg-slate-950 min-h-screen">
+      <motion.div
+        initial={{ opacity: 0, y: -20 }}
+        animate={{ opacity: 1, y: 0 }}
+        transition={{ duration: 0.4 }}
+      >
+        <h2 className="text-2xl md:text-3xl font-bold text-amber-600 dark:text-amber-500">{t('title')}</h2>
+        <p className="text-slate-500 dark:text-slate-400 text-xs md:text-sm">{t('subtitle')}</p>
+      </motion.div>
+
+      <motion.div
+        initial={{ opacity: 0, y: 20 }}
+        animate={{ opacity: 1, y: 0 }}
+        transition={{ delay: 0.1, duration: 0.4 }}
+        className="bg-white dark:bg-slate-900/40 rounded-2xl border border-slate-200 dark:border-slate-800 p-4 md:p-6"
+      >
+        <h3 className="font-semibold text-slate-900 dark:text-white mb-4">{editingId ? t('editFacility') : t('addFacility')}</h3>
+        <div className="grid md:grid-cols-[2fr_1fr_1fr_auto] gap-3 items-center">
+          <Input value={draft.name} onChange={(e) => setDraft({ ...draft, name: e.target.value })} placeholder={t('namePlaceholder')} maxLength={100} className="rounded-xl" />
+          <select
+            value={draft.category}
+            onChange={(e) => setDraft({ ...draft, category: e.target.value as FacilityCategory })}
+            className="h-9 rounded-xl border border-slate-200 dark:border-slate-700 bg-transparent px-3 text-sm text-slate-700 dark:text-slate-300"
+          >
+            {categories.map((c) => <option key={c} value={c}>{t(`category.${c}`)}</option>)}
+          </select>
+          <select
+            value={draft.icon_key}
+            onChange={(e) => setDraft({ ...draft, icon_key: e.target.value })}
+            className="h-9 rounded-xl border border-slate-200 dark:border-slate-700 bg-transparent px-3 text-sm text-slate-700 dark:text-slate-300"
+          >
+            <option value="">{t('noIcon')}</option>
+            {Object.keys(facilityIcons).map((key) => <option key={key} value={key}>{key}</option>)}
+          </select>
+          <div className="flex gap-2">
+            <Button size="sm" disabled={isSaving} onClick={handleSave} className="bg-amber-500 hover:bg-amber-600 text-white rounded-xl">
+              {isSaving ? <Loader2 className="size-4 animate-spin mr-1" /> : editingId ? <Save className="size-4 mr-1" /> : <Plus className="size-4 mr-1" />}
+              {editingId ? t('save') : t('add')}
+            </Button>
+            {editingId && (
+              <Button size="sm" variant="ghost" onClick={resetDraft} className="rounded-xl">
+                <X className="size-4" />
+              </Button>
+            )}
+          </div>
+        </div>
+      </motion.div>
+
+      <motion.div
+        initial={{ opacity: 0, y: 20 }}
+        animate={{ opacity: 1, y: 0 }}
+        transition={{ delay: 0.2, duration: 0.4 }}
+        className="space-y-6 pb-20 md:pb-0"
+      >
+        {isLoading ? (
+          <div className="py-20 flex justify-center">
+            <Loader2 className="size-8 animate-spin text-amber-500" />
+          </div>
+        ) : facilities.length === 0 ? (
+          <div className="py-20 text-center">
+            <Sofa className="size-12 text-slate-400 dark:text-slate-700 mx-auto mb-4" />
+            <p className="text-slate-500">{t('empty')}</p>
+          </div>
+        ) : categories.map((category) => {
+          const items = facilities.filter((f) => f.category === category);
+          if (items.length === 0) return null;
+          return (
+            <div key={category}>
+              <p className="text-[10px] uppercase tracking-wider font-bold text-slate-500 mb-2">{t(`category.${category}`)}</p>
+              <div className="grid sm:grid-cols-2 lg:grid-cols-3 gap-3">
+                {items.map((facility) => (
+                  <div key={facility.id} className="flex items-center gap-3 bg-white dark:bg-slate-900/40 rounded-xl border border-slate-200 dark:border-slate-800 p-3">
+                    <div className="size-9 rounded-lg bg-amber-500/10 flex items-center justify-center">
+                      <FacilityIcon iconKey={facility.icon_key} className="size-4 text-amber-600 dark:text-amber-500" />
+                    </div>
+                    <span className="flex-1 text-sm font-medium text-slate-900 dark:text-white truncate">{facility.name}</span>
+                    <Button
+                      size="sm"
+                      variant="ghost"
+                      onClick={() => { setEditingId(facility.id); setDraft({ name: facility.name, icon_key: facility.icon_key, category: facility.category }); }}
+                      className="rounded-lg"
+                    >
+                      <Pencil className="size-4" />
+                    </Button>
+                    <Button size="sm" variant="ghost" onClick={() => handleDelete(facility)} className="rounded-lg text-red-500 hover:text-red-600">
+                      <Trash2 className="size-4" />
+                    </Button>
+                  </div>
+                ))}
+              </div>
+            </div>
+          );
+        })}
+      </motion.div>
+    </div>
+  );
+}
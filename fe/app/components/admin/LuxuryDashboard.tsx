@@ -7,6 +7,7 @@ import {
   CreditCard,
   ArrowUpRight,
   LogOut,
+  Wrench,
 } from "lucide-react";
 import {
   LineChart,
@@ -87,6 +88,7 @@ export function LuxuryDashboard() {
     type_breakdown: [],
     demographics: [],
     recent_checkouts: [],
+    tickets: { open: 0, in_progress: 0, resolved: 0, closed: 0, urgent: 0, avg_resolution_hours: 0 },
   });
   const [tenants, setTenants] = useState<Tenant[]>([]);
   const [payments, setPayments] = useState<Payment[]>([]);
@@ -127,6 +129,7 @@ export function LuxuryDashboard() {
   const activeTenants = stats.active_tenants;
   const pendingPayments = stats.pending_payments;
   const totalRevenue = stats.total_revenue;
+  const tickets = stats.tickets;
 
   // Historical revenue data from backend
   const revenueData =
@@ -318,6 +321,46 @@ export function LuxuryDashboard() {
         </motion.div>
       </motion.div>
 
+      {/* Maintenance Tickets */}
+      {tickets && (
+        <motion.div
+          initial={{ opacity: 0, y: 20 }}
+          animate={{ opacity: 1, y: 0 }}
+          transition={{ delay: 0.15, duration: 0.4 }}
+          className="bg-white dark:bg-slate-900 border border-slate-200 dark:border-slate-800 rounded-2xl p-4 md:p-6 shadow-sm dark:shadow-none flex flex-col md:flex-row md:items-center gap-4 md:gap-8"
+        >
+          <div className="flex items-center gap-3">
+            <div className="p-2 md:p-3 bg-orange-500/10 dark:bg-orange-500/20 rounded-xl">
+              <Wrench className="size-4 md:size-6 text-orange-500 dark:text-orange-400" />
+            </div>
+            <div>
+              <h3 className="text-base md:text-lg font-semibold text-slate-900 dark:text-white">{t('maintenanceTickets')}</h3>
+              <p className="text-[10px] md:text-xs text-slate-500 dark:text-slate-400">{t('maintenanceTicketsSubtitle')}</p>
+            </div>
+          </div>
+          <div className="grid grid-cols-2 md:grid-cols-4 gap-4 flex-1">
+            <div>
+              <p className="text-[10px] md:text-xs text-slate-500 dark:text-slate-400 uppercase">{t('ticketsOpen')}</p>
+              <p className="text-xl md:text-2xl font-bold text-slate-900 dark:text-white">{tickets.open}</p>
+            </div>
+            <div>
+              <p className="text-[10px] md:text-xs text-slate-500 dark:text-slate-400 uppercase">{t('ticketsInProgress')}</p>
+              <p className="text-xl md:text-2xl font-bold text-slate-900 dark:text-white">{tickets.in_progress}</p>
+            </div>
+            <div>
+              <p className="text-[10px] md:text-xs text-slate-500 dark:text-slate-400 uppercase">{t('ticketsUrgent')}</p>
+              <p className={`text-xl md:text-2xl font-bold ${tickets.urgent > 0 ? 'text-red-500' : 'text-slate-900 dark:text-white'}`}>{tickets.urgent}</p>
+            </div>
+            <div>
+              <p className="text-[10px] md:text-xs text-slate-500 dark:text-slate-400 uppercase">{t('avgResolution')}</p>
+              <p className="text-xl md:text-2xl font-bold text-slate-900 dark:text-white">
+                {tickets.avg_resolution_hours > 0 ? t('hoursShort', { hours: tickets.avg_resolution_hours }) : '-'}
+              </p>
+            </div>
+          </div>
+        </motion.div>
+      )}
+
       {/* Charts Section */}
       {/* Charts Section */}
       <motion.div 
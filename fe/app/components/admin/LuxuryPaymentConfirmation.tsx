@@ -1,7 +1,7 @@
 "use client";
 
 import { useState, useEffect, useCallback } from 'react';
-import { getImageUrl } from '@/app/utils/api-url';
+import { usePaymentProofUrl } from '@/app/components/shared/usePaymentProofUrl';
 import { Check, X, Eye, Clock, CheckCircle2, XCircle, AlertCircle, Loader2, RefreshCw, Home, CreditCard } from 'lucide-react';
 import { api, Payment as ApiPayment } from '@/app/services/api';
 import { Button } from '@/app/components/ui/button';
@@ -19,7 +19,7 @@ interface Payment {
   date: string;
   method: string;
   status: 'Pending' | 'Confirmed' | 'Rejected';
-  receiptUrl: string;
+  receiptRef: string;
   paymentType: string; // 'full' | 'dp' | 'extend'
 }
 
@@ -28,6 +28,7 @@ export function LuxuryPaymentConfirmation() {
   const [payments, setPayments] = useState<Payment[]>([]);
   const [isLoading, setIsLoading] = useState(true);
   const [viewingPayment, setViewingPayment] = useState<Payment | null>(null);
+  const receiptUrl = usePaymentProofUrl(viewingPayment?.id, viewingPayment?.receiptRef);
   const [processingId, setProcessingId] = useState<number | null>(null);
   const [activeTab, setActiveTab] = useState<'pending' | 'history'>('pending');
 
@@ -46,7 +47,7 @@ export function LuxuryPaymentConfirmation() {
         date: new Date(p.tanggal_bayar).toLocaleDateString('id-ID'),
         method: (p.metode_pembayaran || '').toLowerCase() === 'cash' ? t('cash') : t('transferBank'),
         status: p.status_pembayaran as Payment['status'],
-        receiptUrl: getImageUrl(p.bukti_transfer) || '',
+        receiptRef: p.bukti_transfer || '',
         paymentType: p.tipe_pembayaran || 'full',
       }));
       
@@ -414,15 +415,19 @@ export function LuxuryPaymentConfirmation() {
               <div>
                 <p className="text-xs md:text-sm text-slate-500 dark:text-slate-400 mb-3">{t('receiptPreview')}</p>
                 <div className="bg-slate-50 dark:bg-slate-800/30 rounded-xl p-4 md:p-6 text-center border-2 border-dashed border-slate-300 dark:border-slate-700">
-                  {viewingPayment.receiptUrl ? (
+                  {viewingPayment.receiptRef ? (
                     <div className="relative w-full h-64 md:h-96">
-                       <Image 
-                          src={viewingPayment.receiptUrl} 
-                          alt="Receipt" 
-                          fill
-                          className="object-contain rounded-lg"
-                          unoptimized
-                       />
+                       {receiptUrl ? (
+                         <Image 
+                            src={receiptUrl} 
+                            alt="Receipt" 
+                            fill
+                            className="object-contain rounded-lg"
+                            unoptimized
+                         />
+                       ) : (
+                         <Loader2 className="absolute inset-0 m-auto size-8 text-amber-500 animate-spin" />
+                       )}
                     </div>
                   ) : (
                     <div className="py-8">
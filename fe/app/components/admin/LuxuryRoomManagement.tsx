@@ -9,7 +9,10 @@ import { Input } from '@/app/components/ui/input';
 import { Dialog, DialogContent, DialogHeader, DialogTitle, DialogTrigger } from '@/app/components/ui/dialog';
 import { Label } from '@/app/components/ui/label';
 import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from '@/app/components/ui/select';
-import { api } from '@/app/services/api';
+import { api, Facility, RoomType } from '@/app/services/api';
+import { FacilityIcon } from '@/app/components/shared/FacilityIcon';
+import { RoomPriceHistory } from '@/app/components/admin/RoomPriceHistory';
+import { RoomImageManager } from '@/app/components/admin/RoomImageManager';
 import { toast } from 'sonner';
 import { useTranslations } from 'next-intl';
 import { motion } from "framer-motion";
@@ -28,6 +31,8 @@ interface Room {
   description: string;
   image: string;
   facilities: string[];
+  facilityIds: number[];
+  roomTypeId: number | null;
 }
 
 interface BackendRoom {
@@ -44,6 +49,8 @@ interface BackendRoom {
   description: string;
   image_url: string;
   fasilitas: string;
+  facilities?: Facility[];
+  room_type_id?: number | null;
 }
 
 export function LuxuryRoomManagement() {
@@ -65,6 +72,8 @@ export function LuxuryRoomManagement() {
   const [occupancyMap, setOccupancyMap] = useState<Record<string, { tenant_name: string; payment_status: string; last_pay_amount: number; payment_month: string }>>({});
   const [roomPaymentDetail, setRoomPaymentDetail] = useState<{ tenant_name: string; penyewa_id: number; email: string; nomor_hp: string; check_in: string; check_out: string; durasi_sewa: number; payments: { id: number; jumlah_bayar: number; status_pembayaran: string; metode_pembayaran: string; tanggal_bayar: string; payment_month: string }[] } | null>(null);
   const [loadingPayments, setLoadingPayments] = useState(false);
+  const [facilityCatalog, setFacilityCatalog] = useState<Facility[]>([]);
+  const [roomTypes, setRoomTypes] = useState<RoomType[]>([]);
 
   const [formData, setFormData] = useState<Partial<Room>>({
     name: '',
@@ -73,6 +82,8 @@ export function LuxuryRoomManagement() {
     status: 'Tersedia',
     capacity: 1,
     facilities: [],
+    facilityIds: [],
+    roomTypeId: null,
     floor: 1,
     size: '',
     bedrooms: 1,
@@ -97,7 +108,9 @@ export function LuxuryRoomManagement() {
         bathrooms: r.bathrooms || 1,
         description: r.description || '',
         image: getImageUrl(r.image_url) || 'https://via.placeholder.com/300',
-        facilities: r.fasilitas ? r.fasilitas.split(',').map(f => f.trim()) : []
+        facilities: r.facilities?.length ? r.facilities.map(f => f.name) : (r.fasilitas ? r.fasilitas.split(',').map(f => f.trim()) : []),
+        facilityIds: r.facilities?.map(f => f.id) || [],
+        roomTypeId: r.room_type_id ?? null
       }));
       setRooms(mapped);
     } catch (e) {
@@ -109,6 +122,8 @@ export function LuxuryRoomManagement() {
 
   useEffect(() => {
     fetchRooms();
+    api.getFacilities().then(res => setFacilityCatalog(res.data || [])).catch(() => {});
+    api.getRoomTypes().then(res => setRoomTypes(res.data || [])).catch(() => {});
     // Fetch total revenue from dashboard stats
     api.getDashboardStats().then(stats => {
       setTotalRevenue(stats.total_revenue || 0);
@@ -159,6 +174,24 @@ export function LuxuryRoomManagement() {
       return (aVal as number) > (bVal as number) ? modifier : -modifier;
     });
 
+  // Memilih tipe mengisi harga, ukuran, kapasitas dan deskripsi dengan nilai bawaan tipe
+  const handleTypeChange = (value: string) => {
+    const roomType = roomTypes.find(rt => String(rt.id) === value);
+    if (!roomType) {
+      setFormData({ ...formData, roomTypeId: null, type: value });
+      return;
+    }
+    setFormData({
+      ...formData,
+      roomTypeId: roomType.id,
+      type: roomType.name,
+      price: roomType.harga_per_bulan,
+      size: roomType.size,
+      capacity: roomType.capacity || formData.capacity,
+      description: roomType.description
+    });
+  };
+
   const handleSubmit = async () => {
     // Validation
     if (!formData.name?.trim()) {
@@ -173,7 +206,7 @@ export function LuxuryRoomManagement() {
       toast.error('Ukuran kamar wajib diisi (contoh: 3x4m)');
       return;
     }
-    if (!formData.facilities || formData.facilities.length === 0) {
+    if (!formData.facilityIds || formData.facilityIds.length === 0) {
       toast.error('Fasilitas wajib diisi minimal satu');
       return;
     }
@@ -182,26 +215,46 @@ export function LuxuryRoomManagement() {
       return;
     }
 
+    const roomType = roomTypes.find(rt => rt.id === formData.roomTypeId);
     const data = new FormData();
+    // Nilai yang sama dengan tipe dikirim sebagai inherit supaya ikut berubah saat tipe diubah
+    const inherit: string[] = [];
+    const appendTyped = (field: string, value: string, typeValue?: string) => {
+      if (roomType && value === typeValue) {
+        inherit.push(field);
+      } else {
+        data.append(field, value);
+      }
+    };
     data.append('nomor_kamar', formData.name.trim());
     data.append('tipe_kamar', formData.type || 'Standard');
-    data.append('harga_per_bulan', String(formData.price));
+    if (roomType) {
+      data.append('room_type_id', String(roomType.id));
+    } else if (editingRoom?.roomTypeId) {
+      data.append('room_type_id', '0');
+    }
+    appendTyped('harga_per_bulan', String(formData.price), roomType && String(roomType.harga_per_bulan));
     data.append('status', formData.status || 'Tersedia');
-    data.append('capacity', String(formData.capacity));
+    appendTyped('capacity', String(formData.capacity), roomType && String(roomType.capacity));
     data.append('floor', String(formData.floor));
-    data.append('size', formData.size.trim());
+    appendTyped('size', formData.size.trim(), roomType?.size);
     data.append('bedrooms', String(formData.bedrooms));
     data.append('bathrooms', String(formData.bathrooms));
-    data.append('description', formData.description.trim());
-    // Handle facilities array to string
-    data.append('fasilitas', Array.isArray(formData.facilities) ? formData.facilities.join(', ') : formData.facilities || '');
+    appendTyped('description', formData.description.trim(), roomType?.description);
+    if (inherit.length > 0) {
+      data.append('inherit', inherit.join(','));
+    }
+    // ID dari katalog fasilitas; backend menyusun ulang teks fasilitas
+    data.append('facility_ids', formData.facilityIds.join(','));
 
     const newImages = imageFiles.filter(f => f !== null);
     if (newImages.length > 0 && newImages.length < 3) {
       toast.error('Minimal 3 foto kamar diperlukan');
       return;
     }
-    if (!editingRoom && newImages.length < 3) {
+    // Kamar bertipe boleh memakai foto tipe jika tidak mengunggah foto sendiri
+    const useTypeImages = newImages.length === 0 && !!roomType?.images?.length;
+    if (!editingRoom && newImages.length < 3 && !useTypeImages) {
       toast.error('Minimal 3 foto kamar diperlukan untuk kamar baru');
       return;
     }
@@ -258,6 +311,8 @@ export function LuxuryRoomManagement() {
       status: 'Tersedia',
       capacity: 1,
       facilities: [],
+      facilityIds: [],
+      roomTypeId: null,
       floor: 1,
       size: '',
       bedrooms: 1,
@@ -316,7 +371,7 @@ export function LuxuryRoomManagement() {
           <Dialog open={isDialogOpen} onOpenChange={setIsDialogOpen}>
             <DialogTrigger asChild>
               <Button
-                onClick={() => { setEditingRoom(null); setFormData({ name: '', type: 'Standard', price: 0, status: 'Tersedia', capacity: 1, facilities: [], floor: 1, size: '', bedrooms: 1, bathrooms: 1, description: '' }); }}
+                onClick={() => { setEditingRoom(null); setFormData({ name: '', type: 'Standard', price: 0, status: 'Tersedia', capacity: 1, facilities: [], facilityIds: [], roomTypeId: null, floor: 1, size: '', bedrooms: 1, bathrooms: 1, description: '' }); }}
                 className="flex-1 sm:flex-none bg-gradient-to-r from-amber-500 to-amber-600 hover:from-amber-600 hover:to-amber-700 text-white shadow-lg shadow-amber-500/20 px-4 md:px-6 py-2 h-auto"
               >
                 <Plus className="size-4 mr-2" />
@@ -343,15 +398,22 @@ export function LuxuryRoomManagement() {
                   </div>
                   <div className="space-y-2">
                     <Label htmlFor="type" className="text-slate-600 dark:text-slate-300">{t('type')}</Label>
-                    <Select value={formData.type} onValueChange={(value) => setFormData({ ...formData, type: value })}>
+                    <Select value={formData.roomTypeId ? String(formData.roomTypeId) : formData.type} onValueChange={handleTypeChange}>
                       <SelectTrigger className="bg-slate-50 dark:bg-slate-800 border-slate-200 dark:border-slate-700 text-slate-900 dark:text-white">
                         <SelectValue />
                       </SelectTrigger>
                       <SelectContent className="bg-white dark:bg-slate-800 border-slate-200 dark:border-slate-700 text-slate-900 dark:text-white">
-                        <SelectItem value="Standard">{t('type_standard')}</SelectItem>
-                        <SelectItem value="Premium">{t('type_premium')}</SelectItem>
+                        {roomTypes.length > 0 ? roomTypes.map(rt => (
+                          <SelectItem key={rt.id} value={String(rt.id)}>{rt.name}</SelectItem>
+                        )) : (
+                          <>
+                            <SelectItem value="Standard">{t('type_standard')}</SelectItem>
+                            <SelectItem value="Premium">{t('type_premium')}</SelectItem>
+                          </>
+                        )}
                       </SelectContent>
                     </Select>
+                    {formData.roomTypeId && <p className="text-[10px] text-slate-500">{t('roomTypeInheritHint')}</p>}
                   </div>
                 </div>
 
@@ -444,13 +506,31 @@ export function LuxuryRoomManagement() {
 
                 <div className="space-y-2">
                   <Label htmlFor="facilities" className="text-slate-600 dark:text-slate-300">{t('facilities')}</Label>
-                  <Input
-                    id="facilities"
-                    value={Array.isArray(formData.facilities) ? formData.facilities.join(', ') : formData.facilities}
-                    onChange={(e) => setFormData({ ...formData, facilities: e.target.value.split(',').map(s => s.trim()) })}
-                    placeholder="TV, AC, WiFi, Bathroom"
-                    className="bg-slate-50 dark:bg-slate-800 border-slate-200 dark:border-slate-700 text-slate-900 dark:text-white focus:ring-amber-500"
-                  />
+                  <div id="facilities" className="flex flex-wrap gap-2">
+                    {facilityCatalog.length === 0 && <span className="text-slate-500 text-xs italic">{t('noFacilityCatalog')}</span>}
+                    {facilityCatalog.map((facility) => {
+                      const selected = formData.facilityIds?.includes(facility.id);
+                      return (
+                        <button
+                          key={facility.id}
+                          type="button"
+                          onClick={() => setFormData({
+                            ...formData,
+                            facilityIds: selected
+                              ? (formData.facilityIds || []).filter(id => id !== facility.id)
+                              : [...(formData.facilityIds || []), facility.id],
+                          })}
+                          className={`flex items-center gap-1.5 px-3 py-1.5 rounded-lg border text-xs font-medium transition-colors ${selected
+                            ? 'bg-amber-500/15 border-amber-500/40 text-amber-600 dark:text-amber-400'
+                            : 'bg-slate-50 dark:bg-slate-800 border-slate-200 dark:border-slate-700 text-slate-600 dark:text-slate-300'
+                          }`}
+                        >
+                          <FacilityIcon iconKey={facility.icon_key} className="size-3.5" />
+                          {facility.name}
+                        </button>
+                      );
+                    })}
+                  </div>
                 </div>
 
                 <div className="space-y-2">
@@ -567,7 +647,7 @@ export function LuxuryRoomManagement() {
                     </div>
                   )}
 
-                  {editingRoom && <p className="text-xs text-amber-600 italic mt-1">* Jika ingin mengubah gambar, Anda harus mengunggah 3 gambar baru sekaligus untuk menggantikan gambar sebelumnya.</p>}
+                  {editingRoom && <p className="text-xs text-amber-600 italic mt-1">* Jika ingin mengubah gambar, Anda harus mengunggah 3 gambar baru sekaligus untuk menggantikan gambar sebelumnya. Untuk menambah, menghapus, atau mengurutkan satu per satu, buka detail kamar.</p>}
                   {imageFiles.some(f => f !== null) && (
                     <div className="flex justify-start mt-2">
                        <Button 
@@ -689,8 +769,14 @@ export function LuxuryRoomManagement() {
             </SelectTrigger>
             <SelectContent className="bg-white dark:bg-slate-900 border-slate-200 dark:border-slate-800 text-slate-900 dark:text-white">
               <SelectItem value="All">{t('allTypes')}</SelectItem>
-              <SelectItem value="Standard">Standard</SelectItem>
-              <SelectItem value="Premium">Premium</SelectItem>
+              {roomTypes.length > 0 ? roomTypes.map(rt => (
+                <SelectItem key={rt.id} value={rt.name}>{rt.name}</SelectItem>
+              )) : (
+                <>
+                  <SelectItem value="Standard">Standard</SelectItem>
+                  <SelectItem value="Premium">Premium</SelectItem>
+                </>
+              )}
             </SelectContent>
           </Select>
         </div>
@@ -871,6 +957,10 @@ export function LuxuryRoomManagement() {
                   </p>
                 </div>
 
+                <RoomImageManager roomId={Number(viewingRoom.id)} onChange={fetchRooms} />
+
+                <RoomPriceHistory roomId={Number(viewingRoom.id)} currentPrice={viewingRoom.price} />
+
                 {/* Tenant & Payment History */}
                 {occupancyMap[viewingRoom.id] ? (
                   <div className="space-y-4">
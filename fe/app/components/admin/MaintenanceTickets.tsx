@@ -0,0 +1,269 @@
+"use client";
+
+import { useState, useEffect, useCallback } from 'react';
+import { Wrench, Loader2, Inbox, Send, UserCheck, ImageIcon } from 'lucide-react';
+import { Button } from '@/app/components/ui/button';
+import { Textarea } from '@/app/components/ui/textarea';
+import { Dialog, DialogContent, DialogTitle } from '@/app/components/ui/dialog';
+import { api, MaintenanceTicket, TicketStatus, TicketPriority } from '@/app/services/api';
+import { getImageUrl } from '@/app/utils/api-url';
+import { useTranslations } from 'next-intl';
+import { motion } from "framer-motion";
+
+type StatusFilter = TicketStatus | '';
+
+export const ticketStatusStyles: Record<TicketStatus, string> = {
+  open: 'bg-red-500/10 text-red-500 border-red-500/20',
+  in_progress: 'bg-amber-500/10 text-amber-600 dark:text-amber-500 border-amber-500/20',
+  resolved: 'bg-emerald-500/10 text-emerald-600 dark:text-emerald-500 border-emerald-500/20',
+  closed: 'bg-slate-500/10 text-slate-500 border-slate-500/20',
+};
+
+export const ticketPriorityStyles: Record<TicketPriority, string> = {
+  low: 'text-slate-500',
+  medium: 'text-blue-500',
+  high: 'text-amber-600 dark:text-amber-500',
+  urgent: 'text-red-500 font-bold',
+};
+
+// Status yang boleh dipilih dari status saat ini (sama dengan aturan di backend)
+const nextStatuses: Record<TicketStatus, TicketStatus[]> = {
+  open: ['in_progress', 'resolved', 'closed'],
+  in_progress: ['open', 'resolved', 'closed'],
+  resolved: ['in_progress', 'closed'],
+  closed: [],
+};
+
+export function MaintenanceTickets() {
+  const t = useTranslations('tickets');
+  const [status, setStatus] = useState<StatusFilter>('open');
+  const [tickets, setTickets] = useState<MaintenanceTicket[]>([]);
+  const [isLoading, setIsLoading] = useState(false);
+  const [selected, setSelected] = useState<MaintenanceTicket | null>(null);
+  const [message, setMessage] = useState('');
+  const [isSaving, setIsSaving] = useState(false);
+
+  const fetchTickets = useCallback(async () => {
+    setIsLoading(true);
+    try {
+      const res = await api.getMaintenanceTickets({ status });
+      setTickets(res.data || []);
+    } catch (error) {
+      console.error("Failed to fetch maintenance tickets:", error);
+    } finally {
+      setIsLoading(false);
+    }
+  }, [status]);
+
+  useEffect(() => {
+    void fetchTickets();
+  }, [fetchTickets]);
+
+  const openTicket = async (id: number) => {
+    try {
+      const res = await api.getMaintenanceTicket(id);
+      setSelected(res.data);
+      setMessage('');
+    } catch (error) {
+      console.error("Failed to fetch ticket:", error);
+    }
+  };
+
+  // Jalankan aksi lalu muat ulang detail dan daftar
+  const runAction = async (action: () => Promise<unknown>) => {
+    if (!selected) return;
+    setIsSaving(true);
+    try {
+      await action();
+      setMessage('');
+      await openTicket(selected.id);
+      void fetchTickets();
+    } catch (error) {
+      console.error("Ticket action failed:", error);
+    } finally {
+      setIsSaving(false);
+    }
+  };
+
+  const statusFilters: StatusFilter[] = ['open', 'in_progress', 'resolved', 'closed', ''];
+
+  return (
+    <div className="p-4 md:p-8 space-y-6 md:space-y-8 bg-gray-50 dark:bg-slate-950 min-h-screen">
+      <motion.div
+        initial={{ opacity: 0, y: -20 }}
+        animate={{ opacity: 1, y: 0 }}
+        transition={{ duration: 0.4 }}
+        className="flex flex-col md:flex-row md:items-center justify-between gap-4"
+      >
+        <div>
+          <h2 className="text-2xl md:text-3xl font-bold text-amber-600 dark:text-amber-500">{t('adminTitle')}</h2>
+          <p className="text-slate-500 dark:text-slate-400 text-xs md:text-sm">{t('adminSubtitle')}</p>
+        </div>
+        <div className="flex flex-wrap gap-2">
+          {statusFilters.map((s) => (
+            <Button
+              key={s || 'all'}
+              variant="ghost"
+              size="sm"
+              onClick={() => setStatus(s)}
+              className={`rounded-xl text-xs font-bold ${status === s
+                ? 'bg-amber-500/15 text-amber-600 dark:text-amber-400'
+                : 'text-slate-500 dark:text-slate-400 hover:bg-slate-100 dark:hover:bg-slate-800'
+              }`}
+            >
+              {s ? t(`status.${s}`) : t('all')}
+            </Button>
+          ))}
+        </div>
+      </motion.div>
+
+      <motion.div
+        initial={{ opacity: 0, y: 20 }}
+        animate={{ opacity: 1, y: 0 }}
+        transition={{ delay: 0.1, duration: 0.4 }}
+        className="bg-white dark:bg-slate-900/40 rounded-2xl border border-slate-200 dark:border-slate-800 overflow-x-auto pb-20 md:pb-0"
+      >
+        {isLoading ? (
+          <div className="py-20 flex justify-center">
+            <Loader2 className="size-8 animate-spin text-amber-500" />
+          </div>
+        ) : tickets.length === 0 ? (
+          <div className="py-20 text-center">
+            <Inbox className="size-12 text-slate-400 dark:text-slate-700 mx-auto mb-4" />
+            <p className="text-slate-500">{t('empty')}</p>
+          </div>
+        ) : (
+          <table className="w-full text-sm">
+            <thead>
+              <tr className="text-left text-[10px] uppercase tracking-wider text-slate-500 border-b border-slate-200 dark:border-slate-800">
+                <th className="p-4">{t('ticket')}</th>
+                <th className="p-4">{t('room')}</th>
+                <th className="p-4">{t('priorityLabel')}</th>
+                <th className="p-4">{t('assignee')}</th>
+                <th className="p-4">{t('created')}</th>
+              </tr>
+            </thead>
+            <tbody>
+              {tickets.map((ticket) => (
+                <tr
+                  key={ticket.id}
+                  onClick={() => openTicket(ticket.id)}
+                  className="border-b border-slate-100 dark:border-slate-800/50 text-slate-700 dark:text-slate-300 cursor-pointer hover:bg-slate-50 dark:hover:bg-slate-800/40"
+                >
+                  <td className="p-4">
+                    <div className="flex items-center gap-2">
+                      <Wrench className="size-4 text-amber-500" />
+                      <span className="font-semibold text-slate-900 dark:text-white">#{ticket.id} {ticket.title}</span>
+                      {ticket.photos?.length > 0 && <ImageIcon className="size-3 text-slate-400" />}
+                    </div>
+                    <span className="text-xs text-slate-500">{t(`category.${ticket.category}`)}</span>
+                    <span className={`inline-block ml-2 px-2 py-0.5 text-[10px] font-bold rounded-lg border uppercase ${ticketStatusStyles[ticket.status]}`}>
+                      {t(`status.${ticket.status}`)}
+                    </span>
+                  </td>
+                  <td className="p-4">
+                    <p className="font-semibold text-slate-900 dark:text-white">{ticket.kamar?.nomor_kamar || '-'}</p>
+                    <p className="text-xs text-slate-500">{ticket.penyewa?.nama_lengkap}</p>
+                  </td>
+                  <td className={`p-4 text-xs uppercase ${ticketPriorityStyles[ticket.priority]}`}>{t(`priority.${ticket.priority}`)}</td>
+                  <td className="p-4 text-xs">{ticket.assignee?.username || '-'}</td>
+                  <td className="p-4 text-xs text-slate-500">
+                    {new Date(ticket.created_at).toLocaleString('id-ID', { day: 'numeric', month: 'short', hour: '2-digit', minute: '2-digit' })}
+                  </td>
+                </tr>
+              ))}
+            </tbody>
+          </table>
+        )}
+      </motion.div>
+
+      <Dialog open={!!selected} onOpenChange={(open) => !open && setSelected(null)}>
+        <DialogContent className="w-[95vw] max-w-2xl bg-white dark:bg-slate-900 border-slate-200 dark:border-slate-800 text-slate-900 dark:text-white rounded-2xl max-h-[90vh] overflow-y-auto">
+          <DialogTitle>{selected ? `#${selected.id} ${selected.title}` : ''}</DialogTitle>
+          {selected && (
+            <div className="space-y-5">
+              <div className="flex flex-wrap items-center gap-2 text-xs">
+                <span className={`px-2 py-0.5 font-bold rounded-lg border uppercase ${ticketStatusStyles[selected.status]}`}>{t(`status.${selected.status}`)}</span>
+                <span className={`uppercase ${ticketPriorityStyles[selected.priority]}`}>{t(`priority.${selected.priority}`)}</span>
+                <span className="text-slate-500">{t(`category.${selected.category}`)}</span>
+                <span className="text-slate-500">· {selected.kamar?.nomor_kamar} · {selected.penyewa?.nama_lengkap}</span>
+              </div>
+
+              <p className="text-sm text-slate-700 dark:text-slate-300 whitespace-pre-line">{selected.description}</p>
+
+              {selected.photos?.length > 0 && (
+                <div className="grid grid-cols-3 gap-2">
+                  {selected.photos.map((photo) => (
+                    <a key={photo.id} href={getImageUrl(photo.url)} target="_blank" rel="noreferrer">
+                      {/* eslint-disable-next-line @next/next/no-img-element */}
+                      <img src={getImageUrl(photo.url)} alt={selected.title} className="w-full h-24 object-cover rounded-xl border border-slate-200 dark:border-slate-800" />
+                    </a>
+                  ))}
+                </div>
+              )}
+
+              <div className="flex flex-wrap items-center gap-2">
+                <span className="text-xs text-slate-500">{t('assignee')}: {selected.assignee?.username || '-'}</span>
+                {selected.status !== 'closed' && (
+                  <Button size="sm" variant="ghost" disabled={isSaving} onClick={() => runAction(() => api.assignMaintenanceTicket(selected.id))} className="rounded-xl text-xs">
+                    <UserCheck className="size-4 mr-1" />
+                    {t('assignToMe')}
+                  </Button>
+                )}
+              </div>
+
+              <div className="space-y-3">
+                <h4 className="text-sm font-semibold">{t('comments')}</h4>
+                {(selected.comments || []).length === 0 && <p className="text-xs text-slate-500">{t('noComments')}</p>}
+                {(selected.comments || []).map((c) => (
+                  <div key={c.id} className={`p-3 rounded-xl text-sm ${c.is_admin ? 'bg-amber-500/10 ml-8' : 'bg-slate-100 dark:bg-slate-800 mr-8'}`}>
+                    <p className="text-[10px] text-slate-500 mb-1">
+                      {c.author_name} · {new Date(c.created_at).toLocaleString('id-ID', { day: 'numeric', month: 'short', hour: '2-digit', minute: '2-digit' })}
+                    </p>
+                    <p className="whitespace-pre-line">{c.message}</p>
+                  </div>
+                ))}
+              </div>
+
+              {selected.status !== 'closed' && (
+                <div className="space-y-3">
+                  <Textarea
+                    value={message}
+                    onChange={(e) => setMessage(e.target.value)}
+                    placeholder={t('commentPlaceholder')}
+                    className="rounded-xl"
+                  />
+                  <div className="flex flex-wrap gap-2 justify-end">
+                    <Button
+                      size="sm"
+                      variant="ghost"
+                      disabled={isSaving || !message.trim()}
+                      onClick={() => runAction(() => api.addMaintenanceTicketComment(selected.id, message))}
+                      className="rounded-xl"
+                    >
+                      <Send className="size-4 mr-1" />
+                      {t('sendComment')}
+                    </Button>
+                    {nextStatuses[selected.status].map((next) => (
+                      <Button
+                        key={next}
+                        size="sm"
+                        disabled={isSaving}
+                        onClick={() => runAction(() => api.updateMaintenanceTicketStatus(selected.id, next, message))}
+                        className="bg-amber-500 hover:bg-amber-600 text-white rounded-xl"
+                      >
+                        {isSaving && <Loader2 className="size-4 animate-spin mr-1" />}
+                        {t(`markAs.${next}`)}
+                      </Button>
+                    ))}
+                  </div>
+                  <p className="text-[10px] text-slate-500">{t('statusNoteHint')}</p>
+                </div>
+              )}
+            </div>
+          )}
+        </DialogContent>
+      </Dialog>
+    </div>
+  );
+}
@@ -0,0 +1,169 @@
+"use client";
+
+import { useState, useEffect, useCallback } from 'react';
+import { Mail, MessageCircle, MessageSquare, RotateCw, Loader2, Inbox } from 'lucide-react';
+import { Button } from '@/app/components/ui/button';
+import { api, NotificationDelivery } from '@/app/services/api';
+import { useTranslations } from 'next-intl';
+import { motion } from "framer-motion";
+
+type DeliveryStatus = 'failed' | 'pending' | 'sent' | 'all';
+
+const statusStyles: Record<NotificationDelivery['status'], string> = {
+  failed: 'bg-red-500/10 text-red-500 border-red-500/20',
+  pending: 'bg-amber-500/10 text-amber-600 dark:text-amber-500 border-amber-500/20',
+  sent: 'bg-emerald-500/10 text-emerald-600 dark:text-emerald-500 border-emerald-500/20',
+};
+
+export function NotificationDeliveries() {
+  const t = useTranslations('admin');
+  const [status, setStatus] = useState<DeliveryStatus>('failed');
+  const [deliveries, setDeliveries] = useState<NotificationDelivery[]>([]);
+  const [isLoading, setIsLoading] = useState(false);
+  const [resendingId, setResendingId] = useState<number | null>(null);
+
+  const statusLabels: Record<DeliveryStatus, string> = {
+    failed: t('deliveryStatusFailed'),
+    pending: t('deliveryStatusPending'),
+    sent: t('deliveryStatusSent'),
+    all: t('deliveryStatusAll'),
+  };
+
+  const fetchDeliveries = useCallback(async () => {
+    setIsLoading(true);
+    try {
+      const res = await api.getNotificationDeliveries(status);
+      setDeliveries(res.data || []);
+    } catch (error) {
+      console.error("Failed to fetch notification deliveries:", error);
+    } finally {
+      setIsLoading(false);
+    }
+  }, [status]);
+
+  useEffect(() => {
+    void fetchDeliveries();
+  }, [fetchDeliveries]);
+
+  const handleResend = async (id: number) => {
+    setResendingId(id);
+    try {
+      await api.resendNotificationDelivery(id);
+      void fetchDeliveries();
+    } catch (error) {
+      console.error("Failed to resend delivery:", error);
+    } finally {
+      setResendingId(null);
+    }
+  };
+
+  return (
+    <div className="p-4 md:p-8 space-y-6 md:space-y-8 bg-gray-50 dark:bg-slate-950 min-h-screen">
+      <motion.div
+        initial={{ opacity: 0, y: -20 }}
+        animate={{ opacity: 1, y: 0 }}
+        transition={{ duration: 0.4 }}
+        className="flex flex-col md:flex-row md:items-center justify-between gap-4"
+      >
+        <div>
+          <h2 className="text-2xl md:text-3xl font-bold text-amber-600 dark:text-amber-500">{t('deliveriesTitle')}</h2>
+          <p className="text-slate-500 dark:text-slate-400 text-xs md:text-sm">{t('deliveriesSubtitle')}</p>
+        </div>
+        <div className="flex gap-2">
+          {(Object.keys(statusLabels) as DeliveryStatus[]).map((s) => (
+            <Button
+              key={s}
+              variant="ghost"
+              size="sm"
+              onClick={() => setStatus(s)}
+              className={`rounded-xl text-xs font-bold ${status === s
+                ? 'bg-amber-500/15 text-amber-600 dark:text-amber-400'
+                : 'text-slate-500 dark:text-slate-400 hover:bg-slate-100 dark:hover:bg-slate-800'
+              }`}
+            >
+              {statusLabels[s]}
+            </Button>
+          ))}
+        </div>
+      </motion.div>
+
+      <motion.div
+        initial={{ opacity: 0, y: 20 }}
+        animate={{ opacity: 1, y: 0 }}
+        transition={{ delay: 0.1, duration: 0.4 }}
+        className="bg-white dark:bg-slate-900/40 rounded-2xl border border-slate-200 dark:border-slate-800 overflow-x-auto pb-20 md:pb-0"
+      >
+        {isLoading ? (
+          <div className="py-20 flex justify-center">
+            <Loader2 className="size-8 animate-spin text-amber-500" />
+          </div>
+        ) : deliveries.length === 0 ? (
+          <div className="py-20 text-center">
+            <Inbox className="size-12 text-slate-400 dark:text-slate-700 mx-auto mb-4" />
+            <p className="text-slate-500">{t('noDeliveries')}</p>
+          </div>
+        ) : (
+          <table className="w-full text-sm">
+            <thead>
+              <tr className="text-left text-[10px] uppercase tracking-wider text-slate-500 border-b border-slate-200 dark:border-slate-800">
+                <th className="p-4">{t('deliveryChannel')}</th>
+                <th className="p-4">{t('deliveryRecipient')}</th>
+                <th className="p-4">{t('deliveryAttempts')}</th>
+                <th className="p-4">{t('deliveryLastError')}</th>
+                <th className="p-4">{t('deliveryUpdated')}</th>
+                <th className="p-4" />
+              </tr>
+            </thead>
+            <tbody>
+              {deliveries.map((d) => (
+                <tr key={d.id} className="border-b border-slate-100 dark:border-slate-800/50 text-slate-700 dark:text-slate-300">
+                  <td className="p-4">
+                    <div className="flex items-center gap-2">
+                      {d.channel === 'email' ? <Mail className="size-4 text-blue-500" /> : d.channel === 'sms' ? <MessageSquare className="size-4 text-violet-500" /> : <MessageCircle className="size-4 text-emerald-500" />}
+                      <span className="font-medium">{d.event_type}</span>
+                    </div>
+                    <span className={`inline-block mt-1 px-2 py-0.5 text-[10px] font-bold rounded-lg border uppercase ${statusStyles[d.status]}`}>
+                      {statusLabels[d.status]}
+                    </span>
+                    {d.channel === 'whatsapp' && d.sent_via === 'sms' && (
+                      <span className="inline-block mt-1 ml-1 px-2 py-0.5 text-[10px] font-bold rounded-lg border uppercase bg-violet-500/10 text-violet-500 border-violet-500/20">
+                        {t('deliveryViaSms')}
+                      </span>
+                    )}
+                    {(d.delivery_status === 'delivered' || d.delivery_status === 'read') && (
+                      <span className="inline-block mt-1 ml-1 px-2 py-0.5 text-[10px] font-bold rounded-lg border uppercase bg-blue-500/10 text-blue-500 border-blue-500/20">
+                        {d.delivery_status === 'read' ? t('deliveryRead') : t('deliveryDelivered')}
+                      </span>
+                    )}
+                  </td>
+                  <td className="p-4">
+                    <p className="font-semibold text-slate-900 dark:text-white">{d.recipient_name || '-'}</p>
+                    <p className="text-xs text-slate-500">{d.recipient}</p>
+                  </td>
+                  <td className="p-4">{d.attempts}/{d.max_attempts}</td>
+                  <td className="p-4 max-w-xs truncate text-xs text-red-500" title={d.last_error}>{d.last_error || '-'}</td>
+                  <td className="p-4 text-xs text-slate-500">
+                    {new Date(d.updated_at).toLocaleString('id-ID', { day: 'numeric', month: 'short', hour: '2-digit', minute: '2-digit' })}
+                  </td>
+                  <td className="p-4 text-right">
+                    {d.status !== 'sent' && (
+                      <Button
+                        size="sm"
+                        onClick={() => handleResend(d.id)}
+                        disabled={resendingId === d.id}
+                        className="bg-amber-500 hover:bg-amber-600 text-white rounded-xl"
+                      >
+                        {resendingId === d.id ? <Loader2 className="size-4 animate-spin" /> : <RotateCw className="size-4 mr-1" />}
+                        {t('resend')}
+                      </Button>
+                    )}
+                  </td>
+                </tr>
+              ))}
+            </tbody>
+          </table>
+        )}
+      </motion.div>
+    </div>
+  );
+}
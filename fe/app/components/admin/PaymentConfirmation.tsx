@@ -1,7 +1,7 @@
 "use client";
 
 import React, { useState, useEffect } from 'react';
-import { getImageUrl } from '@/app/utils/api-url';
+import { usePaymentProofUrl } from '@/app/components/shared/usePaymentProofUrl';
 import { Check, X, Eye, Loader2 } from 'lucide-react';
 import { toast } from 'sonner';
 import { api } from '@/app/services/api';
@@ -25,7 +25,7 @@ interface Payment {
   date: string;
   method: string;
   status: 'Pending' | 'Confirmed' | 'Rejected';
-  receiptUrl: string;
+  receiptRef: string;
 }
 
 interface BackendPayment {
@@ -44,6 +44,7 @@ interface BackendPayment {
 export function PaymentConfirmation() {
   const [payments, setPayments] = useState<Payment[]>([]);
   const [viewingPayment, setViewingPayment] = useState<Payment | null>(null);
+  const receiptUrl = usePaymentProofUrl(viewingPayment?.id, viewingPayment?.receiptRef);
   const [isLoading, setIsLoading] = useState(true);
 
   const fetchPayments = async () => {
@@ -58,7 +59,7 @@ export function PaymentConfirmation() {
         date: new Date(p.tanggal_bayar).toLocaleDateString('id-ID'),
         method: (p.metode_pembayaran || '').toLowerCase() === 'cash' ? 'Tunai' : 'Transfer Bank',
         status: p.status_pembayaran as Payment['status'],
-        receiptUrl: getImageUrl(p.bukti_transfer) || '',
+        receiptRef: p.bukti_transfer || '',
       }));
       setPayments(mapped);
     } catch (e) {
@@ -243,15 +244,19 @@ export function PaymentConfirmation() {
               <div>
                 <p className="text-sm text-slate-600 mb-2">Receipt</p>
                 <div className="overflow-hidden rounded-lg border bg-slate-50">
-                  {viewingPayment.receiptUrl ? (
-                    <NextImage 
-                      src={viewingPayment.receiptUrl} 
-                      alt="Receipt" 
-                      width={500}
-                      height={500}
-                      className="w-full h-auto max-h-96 object-contain"
-                      unoptimized 
-                    />
+                  {viewingPayment.receiptRef ? (
+                    receiptUrl ? (
+                      <NextImage 
+                        src={receiptUrl} 
+                        alt="Receipt" 
+                        width={500}
+                        height={500}
+                        className="w-full h-auto max-h-96 object-contain"
+                        unoptimized 
+                      />
+                    ) : (
+                      <div className="p-8 flex justify-center"><Loader2 className="size-6 animate-spin text-slate-400" /></div>
+                    )
                   ) : (
                     <div className="p-8 text-center text-slate-500">No receipt image provided</div>
                   )}
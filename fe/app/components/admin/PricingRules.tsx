@@ -0,0 +1,288 @@
+"use client";
+
+import { useState, useEffect, useCallback } from 'react';
+import { Plus, Loader2, Pencil, Trash2, Save, X, BadgePercent } from 'lucide-react';
+import { toast } from 'sonner';
+import { Button } from '@/app/components/ui/button';
+import { Input } from '@/app/components/ui/input';
+import { api, PricingRule, PricingRuleInput, PricingRuleType, RoomType } from '@/app/services/api';
+import { useTranslations } from 'next-intl';
+import { motion } from "framer-motion";
+
+const ruleTypes: PricingRuleType[] = ['long_stay', 'promotion', 'voucher'];
+
+const emptyDraft: PricingRuleInput = {
+  name: '', type: 'long_stay', code: '', discount_percent: 0, discount_amount: 0,
+  min_months: 6, starts_at: '', ends_at: '', room_type_id: null, max_uses: 0, active: true,
+};
+
+// starts_at/ends_at dari backend berupa timestamp; form memakai YYYY-MM-DD
+const toDateInput = (value: string | null) => (value ? value.slice(0, 10) : '');
+
+export function PricingRules() {
+  const t = useTranslations('pricingRules');
+  const [rules, setRules] = useState<PricingRule[]>([]);
+  const [roomTypes, setRoomTypes] = useState<RoomType[]>([]);
+  const [isLoading, setIsLoading] = useState(false);
+  const [isSaving, setIsSaving] = useState(false);
+  const [draft, setDraft] = useState<PricingRuleInput>(emptyDraft);
+  // Diskon persen atau potongan tetap (rupiah)
+  const [discountMode, setDiscountMode] = useState<'percent' | 'amount'>('percent');
+  // null = form tambah, angka = sedang mengedit aturan tersebut
+  const [editingId, setEditingId] = useState<number | null>(null);
+
+  const fetchRules = useCallback(async () => {
+    setIsLoading(true);
+    try {
+      const [rulesRes, typesRes] = await Promise.all([api.getPricingRules(), api.getRoomTypes()]);
+      setRules(rulesRes.data || []);
+      setRoomTypes(typesRes.data || []);
+    } catch (error) {
+      console.error("Failed to fetch pricing rules:", error);
+    } finally {
+      setIsLoading(false);
+    }
+  }, []);
+
+  useEffect(() => {
+    void fetchRules();
+  }, [fetchRules]);
+
+  const resetDraft = () => {
+    setDraft(emptyDraft);
+    setDiscountMode('percent');
+    setEditingId(null);
+  };
+
+  const formatPrice = (price: number) =>
+    new Intl.NumberFormat('id-ID', { style: 'currency', currency: 'IDR', minimumFractionDigits: 0 }).format(price);
+
+  const formatDiscount = (rule: PricingRule) =>
+    rule.discount_percent > 0 ? `${rule.discount_percent}%` : formatPrice(rule.discount_amount);
+
+  const describeRule = (rule: PricingRule) => {
+    const parts: string[] = [];
+    if (rule.min_months > 0) parts.push(t('minMonthsValue', { count: rule.min_months }));
+    if (rule.starts_at && rule.ends_at) parts.push(`${toDateInput(rule.starts_at)} – ${toDateInput(rule.ends_at)}`);
+    if (rule.type === 'voucher') {
+      parts.push(rule.max_uses > 0 ? t('usesValue', { used: rule.used_count, max: rule.max_uses }) : t('usesUnlimited', { used: rule.used_count }));
+    }
+    const roomType = roomTypes.find((rt) => rt.id === rule.room_type_id);
+    parts.push(roomType ? roomType.name : t('allRoomTypes'));
+    return parts.join(' · ');
+  };
+
+  const handleSave = async () => {
+    if (!draft.name.trim()) {
+      toast.error(t('nameRequired'));
+      return;
+    }
+    const payload: PricingRuleInput = {
+      ...draft,
+      discount_percent: discountMode === 'percent' ? draft.discount_percent : 0,
+      discount_amount: discountMode === 'amount' ? draft.discount_amount : 0,
+      code: draft.type === 'voucher' ? draft.code : '',
+    };
+    setIsSaving(true);
+    try {
+      if (editingId) {
+        await api.updatePricingRule(editingId, payload);
+        toast.success(t('updated'));
+      } else {
+        await api.createPricingRule(payload);
+        toast.success(t('created'));
+      }
+      resetDraft();
+      await fetchRules();
+    } catch (error) {
+      toast.error(error instanceof Error ? error.message : t('saveFailed'));
+    } finally {
+      setIsSaving(false);
+    }
+  };
+
+  const handleEdit = (rule: PricingRule) => {
+    setEditingId(rule.id);
+    setDiscountMode(rule.discount_percent > 0 ? 'percent' : 'amount');
+    setDraft({
+      name: rule.name,
+      type: rule.type,
+      code: rule.code || '',
+      discount_percent: rule.discount_percent,
+      discount_amount: rule.discount_amount,
+      min_months: rule.min_months,
+      starts_at: toDateInput(rule.starts_at),
+      ends_at: toDateInput(rule.ends_at),
+      room_type_id: rule.room_type_id,
+      max_uses: rule.max_uses,
+      active: rule.active,
+    });
+  };
+
+  const handleToggleActive = async (rule: PricingRule) => {
+    try {
+      await api.updatePricingRule(rule.id, {
+        name: rule.name,
+        type: rule.type,
+        code: rule.code,
+        discount_percent: rule.discount_percent,
+        discount_amount: rule.discount_amount,
+        min_months: rule.min_months,
+        starts_at: toDateInput(rule.starts_at),
+        ends_at: toDateInput(rule.ends_at),
+        room_type_id: rule.room_type_id,
+        max_uses: rule.max_uses,
+        active: !rule.active,
+      });
+      await fetchRules();
+    } catch (error) {
+      toast.error(error instanceof Error ? error.message : t('saveFailed'));
+    }
+  };
+
+  const handleDelete = async (rule: PricingRule) => {
+    if (!window.confirm(t('deleteConfirm', { name: rule.name }))) return;
+    try {
+      await api.deletePricingRule(rule.id);
+      toast.success(t('deleted'));
+      if (editingId === rule.id) resetDraft();
+      await fetchRules();
+    } catch (error) {
+      toast.error(error instanceof Error ? error.message : t('deleteFailed'));
+    }
+  };
+
+  const selectClass = "h-9 rounded-xl border border-slate-200 dark:border-slate-700 bg-transparent px-3 text-sm text-slate-700 dark:text-slate-300";
+
+  return (
+    <div className="p-4 md:p-8 space-y-6 md:space-y-8 bg-gray-50 dark:bg-slate-950 min-h-screen">
+      <motion.div
+        initial={{ opacity: 0, y: -20 }}
+        animate={{ opacity: 1, y: 0 }}
+        transition={{ duration: 0.4 }}
+      >
+        <h2 className="text-2xl md:text-3xl font-bold text-amber-600 dark:text-amber-500">{t('title')}</h2>
+        <p className="text-slate-500 dark:text-slate-400 text-xs md:text-sm">{t('subtitle')}</p>
+      </motion.div>
+
+      <motion.div
+        initial={{ opacity: 0, y: 20 }}
+        animate={{ opacity: 1, y: 0 }}
+        transition={{ delay: 0.1, duration: 0.4 }}
+        className="bg-white dark:bg-slate-900/40 rounded-2xl border border-slate-200 dark:border-slate-800 p-4 md:p-6 space-y-3"
+      >
+        <h3 className="font-semibold text-slate-900 dark:text-white">{editingId ? t('editRule') : t('addRule')}</h3>
+        <div className="grid md:grid-cols-4 gap-3">
+          <Input value={draft.name} onChange={(e) => setDraft({ ...draft, name: e.target.value })} placeholder={t('namePlaceholder')} maxLength={100} className="rounded-xl" />
+          <select value={draft.type} onChange={(e) => setDraft({ ...draft, type: e.target.value as PricingRuleType })} className={selectClass}>
+            {ruleTypes.map((type) => (
+              <option key={type} value={type}>{t(`types.${type}`)}</option>
+            ))}
+          </select>
+          <div className="flex gap-2">
+            <select value={discountMode} onChange={(e) => setDiscountMode(e.target.value as 'percent' | 'amount')} className={selectClass}>
+              <option value="percent">%</option>
+              <option value="amount">Rp</option>
+            </select>
+            <Input
+              type="number"
+              min="0"
+              value={(discountMode === 'percent' ? draft.discount_percent : draft.discount_amount) || ''}
+              onChange={(e) => setDraft(discountMode === 'percent'
+                ? { ...draft, discount_percent: Number(e.target.value) }
+                : { ...draft, discount_amount: Number(e.target.value) })}
+              placeholder={t('discountPlaceholder')}
+              className="rounded-xl"
+            />
+          </div>
+          <select
+            value={draft.room_type_id ?? ''}
+            onChange={(e) => setDraft({ ...draft, room_type_id: e.target.value ? Number(e.target.value) : null })}
+            className={selectClass}
+          >
+            <option value="">{t('allRoomTypes')}</option>
+            {roomTypes.map((rt) => (
+              <option key={rt.id} value={rt.id}>{rt.name}</option>
+            ))}
+          </select>
+        </div>
+        <div className="grid md:grid-cols-4 gap-3">
+          <label className="text-xs text-slate-500 space-y-1">
+            <span>{t('minMonths')}</span>
+            <Input type="number" min="0" value={draft.min_months} onChange={(e) => setDraft({ ...draft, min_months: Number(e.target.value) })} className="rounded-xl" />
+          </label>
+          <label className="text-xs text-slate-500 space-y-1">
+            <span>{draft.type === 'promotion' ? t('leaseStartsFrom') : t('validFrom')}</span>
+            <Input type="date" value={draft.starts_at} onChange={(e) => setDraft({ ...draft, starts_at: e.target.value })} className="rounded-xl" />
+          </label>
+          <label className="text-xs text-slate-500 space-y-1">
+            <span>{draft.type === 'promotion' ? t('leaseStartsUntil') : t('validUntil')}</span>
+            <Input type="date" value={draft.ends_at} onChange={(e) => setDraft({ ...draft, ends_at: e.target.value })} className="rounded-xl" />
+          </label>
+          {draft.type === 'voucher' && (
+            <div className="grid grid-cols-2 gap-2">
+              <label className="text-xs text-slate-500 space-y-1">
+                <span>{t('code')}</span>
+                <Input value={draft.code} onChange={(e) => setDraft({ ...draft, code: e.target.value.toUpperCase() })} maxLength={50} className="rounded-xl uppercase" />
+              </label>
+              <label className="text-xs text-slate-500 space-y-1">
+                <span>{t('maxUses')}</span>
+                <Input type="number" min="0" value={draft.max_uses} onChange={(e) => setDraft({ ...draft, max_uses: Number(e.target.value) })} className="rounded-xl" />
+              </label>
+            </div>
+          )}
+        </div>
+        <p className="text-xs text-slate-500">{t(`hints.${draft.type}`)}</p>
+        <div className="flex gap-2">
+          <Button size="sm" disabled={isSaving} onClick={handleSave} className="bg-amber-500 hover:bg-amber-600 text-white rounded-xl">
+            {isSaving ? <Loader2 className="size-4 animate-spin mr-1" /> : editingId ? <Save className="size-4 mr-1" /> : <Plus className="size-4 mr-1" />}
+            {editingId ? t('save') : t('add')}
+          </Button>
+          {editingId && (
+            <Button size="sm" variant="ghost" onClick={resetDraft} className="rounded-xl">
+              <X className="size-4" />
+            </Button>
+          )}
+        </div>
+      </motion.div>
+
+      <motion.div
+        initial={{ opacity: 0, y: 20 }}
+        animate={{ opacity: 1, y: 0 }}
+        transition={{ delay: 0.2, duration: 0.4 }}
+        className="bg-white dark:bg-slate-900/40 rounded-2xl border border-slate-200 dark:border-slate-800 divide-y divide-slate-100 dark:divide-slate-800 pb-20 md:pb-0"
+      >
+        {isLoading ? (
+          <div className="py-20 flex justify-center">
+            <Loader2 className="size-8 animate-spin text-amber-500" />
+          </div>
+        ) : rules.length === 0 ? (
+          <div className="py-20 text-center">
+            <BadgePercent className="size-12 text-slate-400 dark:text-slate-700 mx-auto mb-4" />
+            <p className="text-slate-500">{t('empty')}</p>
+          </div>
+        ) : rules.map((rule) => (
+          <div key={rule.id} className={`flex items-center gap-3 p-4 ${rule.active ? '' : 'opacity-50'}`}>
+            <div className="flex-1 min-w-0">
+              <p className="font-semibold text-slate-900 dark:text-white truncate">
+                {rule.name}
+                {rule.code && <span className="ml-2 font-mono text-xs text-amber-600">{rule.code}</span>}
+              </p>
+              <p className="text-xs text-slate-500">{t(`types.${rule.type}`)} · {describeRule(rule)}</p>
+            </div>
+            <span className="text-sm font-bold text-emerald-600">-{formatDiscount(rule)}</span>
+            <Button size="sm" variant="outline" onClick={() => handleToggleActive(rule)} className="rounded-lg text-xs">
+              {rule.active ? t('deactivate') : t('activate')}
+            </Button>
+            <Button size="sm" variant="ghost" onClick={() => handleEdit(rule)} className="rounded-lg">
+              <Pencil className="size-4" />
+            </Button>
+            <Button size="sm" variant="ghost" onClick={() => handleDelete(rule)} className="rounded-lg text-red-500 hover:text-red-600">
+              <Trash2 className="size-4" />
+            </Button>
+          </div>
+        ))}
+      </motion.div>
+    </div>
+  );
+}
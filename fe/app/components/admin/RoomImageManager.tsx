@@ -0,0 +1,140 @@
+"use client";
+
+import { useState, useEffect, useCallback, useRef } from 'react';
+import { Loader2, Trash2, Star, ArrowLeft, ArrowRight, ImagePlus, Images } from 'lucide-react';
+import { toast } from 'sonner';
+import { Button } from '@/app/components/ui/button';
+import { ImageWithFallback } from '@/app/components/shared/ImageWithFallback';
+import { api, KamarImage } from '@/app/services/api';
+import { getImageUrl } from '@/app/utils/api-url';
+import { useTranslations } from 'next-intl';
+
+interface RoomImageManagerProps {
+  roomId: number;
+  // dipanggil setelah foto berubah supaya daftar kamar ikut memperbarui cover
+  onChange?: () => void;
+}
+
+export function RoomImageManager({ roomId, onChange }: RoomImageManagerProps) {
+  const t = useTranslations('roomImageManager');
+  const [images, setImages] = useState<KamarImage[]>([]);
+  const [isLoading, setIsLoading] = useState(false);
+  const [isSaving, setIsSaving] = useState(false);
+  const fileInputRef = useRef<HTMLInputElement>(null);
+
+  const fetchImages = useCallback(async () => {
+    setIsLoading(true);
+    try {
+      const room = await api.getRoomById(String(roomId));
+      setImages(room.Images || []);
+    } catch (error) {
+      console.error("Failed to fetch room images:", error);
+    } finally {
+      setIsLoading(false);
+    }
+  }, [roomId]);
+
+  useEffect(() => {
+    void fetchImages();
+  }, [fetchImages]);
+
+  // Jalankan aksi, tampilkan hasilnya, lalu muat ulang foto
+  const run = async (action: () => Promise<unknown>, success: string, failed: string) => {
+    setIsSaving(true);
+    try {
+      await action();
+      toast.success(success);
+      await fetchImages();
+      onChange?.();
+    } catch (error) {
+      toast.error(error instanceof Error ? error.message : failed);
+    } finally {
+      setIsSaving(false);
+    }
+  };
+
+  const handleUpload = (files: FileList | null) => {
+    if (!files || files.length === 0) return;
+    const selected = Array.from(files);
+    if (fileInputRef.current) fileInputRef.current.value = '';
+    void run(() => api.uploadRoomImages(roomId, selected), t('uploaded'), t('uploadFailed'));
+  };
+
+  const handleMove = (index: number, offset: number) => {
+    const ids = images.map((img) => img.id);
+    const [moved] = ids.splice(index, 1);
+    ids.splice(index + offset, 0, moved);
+    void run(() => api.reorderRoomImages(roomId, ids), t('reordered'), t('reorderFailed'));
+  };
+
+  const handleDelete = (image: KamarImage) => {
+    if (!window.confirm(t('deleteConfirm'))) return;
+    void run(() => api.deleteRoomImage(roomId, image.id), t('deleted'), t('deleteFailed'));
+  };
+
+  return (
+    <div className="space-y-3">
+      <div className="flex items-center justify-between gap-2">
+        <p className="text-slate-500 dark:text-slate-400 text-[10px] font-bold uppercase tracking-widest flex items-center gap-2">
+          <Images className="size-3.5" /> {t('title')}
+        </p>
+        <input
+          ref={fileInputRef}
+          type="file"
+          accept="image/*"
+          multiple
+          className="hidden"
+          onChange={(e) => handleUpload(e.target.files)}
+        />
+        <Button size="sm" variant="outline" disabled={isSaving} onClick={() => fileInputRef.current?.click()} className="rounded-xl text-xs">
+          {isSaving ? <Loader2 className="size-4 mr-2 animate-spin" /> : <ImagePlus className="size-4 mr-2" />}
+          {t('add')}
+        </Button>
+      </div>
+
+      {isLoading ? (
+        <div className="flex justify-center py-4"><Loader2 className="size-5 animate-spin text-amber-500" /></div>
+      ) : images.length === 0 ? (
+        <p className="text-slate-400 text-xs italic text-center py-3">{t('empty')}</p>
+      ) : (
+        <div className="grid grid-cols-2 md:grid-cols-3 gap-3">
+          {images.map((image, idx) => (
+            <div key={image.id} className="relative h-28 rounded-xl overflow-hidden border border-slate-200 dark:border-slate-700 bg-slate-50 dark:bg-slate-900 group">
+              <ImageWithFallback src={getImageUrl(image.image_url)} alt={`${t('title')} ${idx + 1}`} className="w-full h-full object-cover" />
+              {idx === 0 && (
+                <span className="absolute top-2 left-2 px-2 py-0.5 rounded-md bg-amber-500 text-white text-[10px] font-bold">{t('cover')}</span>
+              )}
+              <div className="absolute inset-x-0 bottom-0 flex items-center justify-between gap-1 p-1.5 bg-black/50 opacity-100 md:opacity-0 md:group-hover:opacity-100 transition-opacity">
+                <div className="flex gap-1">
+                  <Button variant="ghost" size="icon" title={t('moveLeft')} disabled={isSaving || idx === 0} onClick={() => handleMove(idx, -1)} className="size-7 text-white hover:bg-white/20">
+                    <ArrowLeft className="size-3.5" />
+                  </Button>
+                  <Button variant="ghost" size="icon" title={t('moveRight')} disabled={isSaving || idx === images.length - 1} onClick={() => handleMove(idx, 1)} className="size-7 text-white hover:bg-white/20">
+                    <ArrowRight className="size-3.5" />
+                  </Button>
+                </div>
+                <div className="flex gap-1">
+                  {idx > 0 && (
+                    <Button
+                      variant="ghost"
+                      size="icon"
+                      title={t('setCover')}
+                      disabled={isSaving}
+                      onClick={() => void run(() => api.setRoomCoverImage(roomId, image.id), t('coverSet'), t('coverFailed'))}
+                      className="size-7 text-white hover:bg-white/20"
+                    >
+                      <Star className="size-3.5" />
+                    </Button>
+                  )}
+                  <Button variant="ghost" size="icon" title={t('delete')} disabled={isSaving} onClick={() => handleDelete(image)} className="size-7 text-white hover:bg-red-500/60">
+                    <Trash2 className="size-3.5" />
+                  </Button>
+                </div>
+              </div>
+            </div>
+          ))}
+        </div>
+      )}
+    </div>
+  );
+}
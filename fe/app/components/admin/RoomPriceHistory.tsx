@@ -0,0 +1,157 @@
+"use client";
+
+import { useState, useEffect, useCallback } from 'react';
+import { Loader2, Trash2, CalendarClock, History } from 'lucide-react';
+import { toast } from 'sonner';
+import { Button } from '@/app/components/ui/button';
+import { Input } from '@/app/components/ui/input';
+import { api, KamarPriceHistory } from '@/app/services/api';
+import { useTranslations } from 'next-intl';
+
+interface RoomPriceHistoryProps {
+  roomId: number;
+  currentPrice: number;
+}
+
+// effective_from dari backend berupa timestamp; tampilkan dan kirim sebagai YYYY-MM-DD
+const toDateInput = (value: string | null) => (value ? value.slice(0, 10) : '');
+
+// Tanggal paling cepat yang boleh dijadwalkan (hari ini + masa pemberitahuan)
+const earliestDate = (noticeDays: number) => {
+  const d = new Date();
+  d.setDate(d.getDate() + noticeDays);
+  return `${d.getFullYear()}-${String(d.getMonth() + 1).padStart(2, '0')}-${String(d.getDate()).padStart(2, '0')}`;
+};
+
+export function RoomPriceHistory({ roomId, currentPrice }: RoomPriceHistoryProps) {
+  const t = useTranslations('roomPriceHistory');
+  const [history, setHistory] = useState<KamarPriceHistory[]>([]);
+  const [noticeDays, setNoticeDays] = useState(30);
+  const [isLoading, setIsLoading] = useState(false);
+  const [isSaving, setIsSaving] = useState(false);
+  const [price, setPrice] = useState(0);
+  const [effectiveFrom, setEffectiveFrom] = useState('');
+  const [note, setNote] = useState('');
+
+  const fetchHistory = useCallback(async () => {
+    setIsLoading(true);
+    try {
+      const res = await api.getPriceHistory(roomId);
+      setHistory(res.data || []);
+      setNoticeDays(res.notice_days);
+    } catch (error) {
+      console.error("Failed to fetch price history:", error);
+    } finally {
+      setIsLoading(false);
+    }
+  }, [roomId]);
+
+  useEffect(() => {
+    void fetchHistory();
+  }, [fetchHistory]);
+
+  const formatPrice = (value: number) =>
+    new Intl.NumberFormat('id-ID', { style: 'currency', currency: 'IDR', minimumFractionDigits: 0 }).format(value);
+
+  const handleSchedule = async () => {
+    if (price <= 0 || !effectiveFrom) {
+      toast.error(t('fieldsRequired'));
+      return;
+    }
+    setIsSaving(true);
+    try {
+      await api.schedulePriceChange(roomId, { harga_per_bulan: price, effective_from: effectiveFrom, note });
+      toast.success(t('scheduled'));
+      setPrice(0);
+      setEffectiveFrom('');
+      setNote('');
+      await fetchHistory();
+    } catch (error) {
+      toast.error(error instanceof Error ? error.message : t('scheduleFailed'));
+    } finally {
+      setIsSaving(false);
+    }
+  };
+
+  const handleCancel = async (change: KamarPriceHistory) => {
+    if (!window.confirm(t('cancelConfirm', { date: toDateInput(change.effective_from) }))) return;
+    try {
+      await api.cancelPriceChange(roomId, change.id);
+      toast.success(t('cancelled'));
+      await fetchHistory();
+    } catch (error) {
+      toast.error(error instanceof Error ? error.message : t('cancelFailed'));
+    }
+  };
+
+  const statusLabel = (change: KamarPriceHistory) => {
+    if (change.source === 'manual') return t('statusManual');
+    if (change.applied_at) return t('statusApplied');
+    if (change.notice_sent_at) return t('statusNoticed', { date: toDateInput(change.notice_sent_at) });
+    return t('statusScheduled');
+  };
+
+  return (
+    <div className="space-y-4">
+      <p className="text-slate-500 dark:text-slate-400 text-[10px] font-bold uppercase tracking-widest flex items-center gap-2">
+        <History className="size-3.5" /> {t('title')}
+      </p>
+
+      <div className="p-3 md:p-4 bg-slate-50 dark:bg-slate-800/40 rounded-xl border border-slate-200 dark:border-slate-700/50 space-y-3">
+        <p className="text-xs font-semibold text-slate-700 dark:text-slate-200 flex items-center gap-2">
+          <CalendarClock className="size-4 text-amber-500" /> {t('scheduleTitle')}
+        </p>
+        <p className="text-[10px] text-slate-500">{t('scheduleHint', { days: noticeDays, price: formatPrice(currentPrice) })}</p>
+        <div className="grid md:grid-cols-3 gap-2">
+          <Input
+            type="number"
+            min="0"
+            value={price || ''}
+            onChange={(e) => setPrice(Number(e.target.value))}
+            placeholder={t('pricePlaceholder')}
+            className="rounded-xl"
+          />
+          <Input
+            type="date"
+            min={earliestDate(noticeDays)}
+            value={effectiveFrom}
+            onChange={(e) => setEffectiveFrom(e.target.value)}
+            className="rounded-xl"
+          />
+          <Input value={note} onChange={(e) => setNote(e.target.value)} placeholder={t('notePlaceholder')} className="rounded-xl" />
+        </div>
+        <Button size="sm" onClick={handleSchedule} disabled={isSaving} className="bg-amber-500 hover:bg-amber-600 text-white rounded-xl">
+          {isSaving && <Loader2 className="size-4 mr-2 animate-spin" />}
+          {t('schedule')}
+        </Button>
+      </div>
+
+      {isLoading ? (
+        <div className="flex justify-center py-4"><Loader2 className="size-5 animate-spin text-amber-500" /></div>
+      ) : history.length === 0 ? (
+        <p className="text-slate-400 text-xs italic text-center py-3">{t('empty')}</p>
+      ) : (
+        <div className="space-y-2">
+          {history.map((change) => (
+            <div key={change.id} className="flex items-center justify-between gap-3 p-3 bg-slate-50 dark:bg-slate-800/40 rounded-xl border border-slate-200 dark:border-slate-700/50">
+              <div className="min-w-0">
+                <p className="text-xs font-semibold text-slate-700 dark:text-slate-200">
+                  {change.harga_sebelum > 0 && <span className="text-slate-400 line-through mr-2">{formatPrice(change.harga_sebelum)}</span>}
+                  {formatPrice(change.harga_per_bulan)}
+                </p>
+                <p className="text-[10px] text-slate-400">
+                  {toDateInput(change.effective_from)} · {statusLabel(change)}{change.note ? ` · ${change.note}` : ''}
+                </p>
+              </div>
+              {change.source === 'scheduled' && !change.applied_at && (
+                <Button variant="ghost" size="icon" onClick={() => handleCancel(change)} className="size-8 text-slate-400 hover:text-red-500 flex-shrink-0">
+                  <Trash2 className="size-4" />
+                </Button>
+              )}
+            </div>
+          ))}
+        </div>
+      )}
+    </div>
+  );
+}
@@ -0,0 +1,229 @@
+"use client";
+
+import { useState, useEffect, useCallback } from 'react';
+import { Plus, Loader2, Pencil, Trash2, Save, X, BedDouble, ImagePlus } from 'lucide-react';
+import { toast } from 'sonner';
+import { Button } from '@/app/components/ui/button';
+import { Input } from '@/app/components/ui/input';
+import { api, RoomType, RoomTypeInput } from '@/app/services/api';
+import { getImageUrl } from '@/app/utils/api-url';
+import { ImageWithFallback } from '@/app/components/shared/ImageWithFallback';
+import { useTranslations } from 'next-intl';
+import { motion } from "framer-motion";
+
+const emptyDraft: RoomTypeInput = { name: '', harga_per_bulan: 0, size: '', capacity: 1, description: '' };
+
+export function RoomTypeCatalog() {
+  const t = useTranslations('roomTypeCatalog');
+  const [roomTypes, setRoomTypes] = useState<RoomType[]>([]);
+  const [isLoading, setIsLoading] = useState(false);
+  const [isSaving, setIsSaving] = useState(false);
+  const [uploadingId, setUploadingId] = useState<number | null>(null);
+  const [draft, setDraft] = useState<RoomTypeInput>(emptyDraft);
+  // null = form tambah, angka = sedang mengedit tipe tersebut
+  const [editingId, setEditingId] = useState<number | null>(null);
+
+  const fetchRoomTypes = useCallback(async () => {
+    setIsLoading(true);
+    try {
+      const res = await api.getRoomTypes();
+      setRoomTypes(res.data || []);
+    } catch (error) {
+      console.error("Failed to fetch room types:", error);
+    } finally {
+      setIsLoading(false);
+    }
+  }, []);
+
+  useEffect(() => {
+    void fetchRoomTypes();
+  }, [fetchRoomTypes]);
+
+  const resetDraft = () => {
+    setDraft(emptyDraft);
+    setEditingId(null);
+  };
+
+  const formatPrice = (price: number) =>
+    new Intl.NumberFormat('id-ID', { style: 'currency', currency: 'IDR', minimumFractionDigits: 0 }).format(price);
+
+  const handleSave = async () => {
+    if (!draft.name.trim()) {
+      toast.error(t('nameRequired'));
+      return;
+    }
+    if (!draft.harga_per_bulan || draft.harga_per_bulan <= 0) {
+      toast.error(t('priceRequired'));
+      return;
+    }
+    setIsSaving(true);
+    try {
+      if (editingId) {
+        await api.updateRoomType(editingId, draft);
+        toast.success(t('updated'));
+      } else {
+        await api.createRoomType(draft);
+        toast.success(t('created'));
+      }
+      resetDraft();
+      await fetchRoomTypes();
+    } catch (error) {
+      toast.error(error instanceof Error ? error.message : t('saveFailed'));
+    } finally {
+      setIsSaving(false);
+    }
+  };
+
+  const handleDelete = async (roomType: RoomType) => {
+    if (!window.confirm(t('deleteConfirm', { name: roomType.name }))) return;
+    try {
+      await api.deleteRoomType(roomType.id);
+      toast.success(t('deleted'));
+      if (editingId === roomType.id) resetDraft();
+      await fetchRoomTypes();
+    } catch (error) {
+      toast.error(error instanceof Error ? error.message : t('deleteFailed'));
+    }
+  };
+
+  const handleUpload = async (roomType: RoomType, files: FileList | null) => {
+    if (!files || files.length === 0) return;
+    setUploadingId(roomType.id);
+    try {
+      await api.uploadRoomTypeImages(roomType.id, Array.from(files));
+      toast.success(t('photosUploaded'));
+      await fetchRoomTypes();
+    } catch (error) {
+      toast.error(error instanceof Error ? error.message : t('uploadFailed'));
+    } finally {
+      setUploadingId(null);
+    }
+  };
+
+  const handleDeleteImage = async (roomType: RoomType, imageId: number) => {
+    try {
+      await api.deleteRoomTypeImage(roomType.id, imageId);
+      await fetchRoomTypes();
+    } catch (error) {
+      toast.error(error instanceof Error ? error.message : t('deleteFailed'));
+    }
+  };
+
+  return (
+    <div className="p-4 md:p-8 space-y-6 md:space-y-8 bg-gray-50 dark:bg-slate-950 min-h-screen">
+      <motion.div
+        initial={{ opacity: 0, y: -20 }}
+        animate={{ opacity: 1, y: 0 }}
+        transition={{ duration: 0.4 }}
+      >
+        <h2 className="text-2xl md:text-3xl font-bold text-amber-600 dark:text-amber-500">{t('title')}</h2>
+        <p className="text-slate-500 dark:text-slate-400 text-xs md:text-sm">{t('subtitle')}</p>
+      </motion.div>
+
+      <motion.div
+        initial={{ opacity: 0, y: 20 }}
+        animate={{ opacity: 1, y: 0 }}
+        transition={{ delay: 0.1, duration: 0.4 }}
+        className="bg-white dark:bg-slate-900/40 rounded-2xl border border-slate-200 dark:border-slate-800 p-4 md:p-6 space-y-3"
+      >
+        <h3 className="font-semibold text-slate-900 dark:text-white">{editingId ? t('editType') : t('addType')}</h3>
+        <div className="grid md:grid-cols-4 gap-3">
+          <Input value={draft.name} onChange={(e) => setDraft({ ...draft, name: e.target.value })} placeholder={t('namePlaceholder')} maxLength={100} className="rounded-xl" />
+          <Input type="number" value={draft.harga_per_bulan || ''} onChange={(e) => setDraft({ ...draft, harga_per_bulan: Number(e.target.value) })} placeholder={t('pricePlaceholder')} className="rounded-xl" />
+          <Input value={draft.size} onChange={(e) => setDraft({ ...draft, size: e.target.value })} placeholder={t('sizePlaceholder')} maxLength={50} className="rounded-xl" />
+          <Input type="number" min="1" value={draft.capacity} onChange={(e) => setDraft({ ...draft, capacity: Number(e.target.value) })} placeholder={t('capacity')} className="rounded-xl" />
+        </div>
+        <textarea
+          value={draft.description}
+          onChange={(e) => setDraft({ ...draft, description: e.target.value })}
+          placeholder={t('descriptionPlaceholder')}
+          rows={2}
+          className="w-full rounded-xl border border-slate-200 dark:border-slate-700 bg-transparent px-3 py-2 text-sm text-slate-700 dark:text-slate-300"
+        />
+        <div className="flex gap-2">
+          <Button size="sm" disabled={isSaving} onClick={handleSave} className="bg-amber-500 hover:bg-amber-600 text-white rounded-xl">
+            {isSaving ? <Loader2 className="size-4 animate-spin mr-1" /> : editingId ? <Save className="size-4 mr-1" /> : <Plus className="size-4 mr-1" />}
+            {editingId ? t('save') : t('add')}
+          </Button>
+          {editingId && (
+            <Button size="sm" variant="ghost" onClick={resetDraft} className="rounded-xl">
+              <X className="size-4" />
+            </Button>
+          )}
+        </div>
+        {editingId && <p className="text-xs text-slate-500">{t('propagationHint')}</p>}
+      </motion.div>
+
+      <motion.div
+        initial={{ opacity: 0, y: 20 }}
+        animate={{ opacity: 1, y: 0 }}
+        transition={{ delay: 0.2, duration: 0.4 }}
+        className="grid md:grid-cols-2 gap-4 pb-20 md:pb-0"
+      >
+        {isLoading ? (
+          <div className="md:col-span-2 py-20 flex justify-center">
+            <Loader2 className="size-8 animate-spin text-amber-500" />
+          </div>
+        ) : roomTypes.length === 0 ? (
+          <div className="md:col-span-2 py-20 text-center">
+            <BedDouble className="size-12 text-slate-400 dark:text-slate-700 mx-auto mb-4" />
+            <p className="text-slate-500">{t('empty')}</p>
+          </div>
+        ) : roomTypes.map((roomType) => (
+          <div key={roomType.id} className="bg-white dark:bg-slate-900/40 rounded-2xl border border-slate-200 dark:border-slate-800 p-4 space-y-3">
+            <div className="flex items-start gap-3">
+              <div className="flex-1 min-w-0">
+                <p className="font-semibold text-slate-900 dark:text-white truncate">{roomType.name}</p>
+                <p className="text-sm text-amber-600 dark:text-amber-500 font-bold">{formatPrice(roomType.harga_per_bulan)}</p>
+                <p className="text-xs text-slate-500">
+                  {[roomType.size, t('capacityValue', { count: roomType.capacity })].filter(Boolean).join(' · ')}
+                </p>
+              </div>
+              <Button
+                size="sm"
+                variant="ghost"
+                onClick={() => {
+                  setEditingId(roomType.id);
+                  setDraft({ name: roomType.name, harga_per_bulan: roomType.harga_per_bulan, size: roomType.size, capacity: roomType.capacity, description: roomType.description });
+                }}
+                className="rounded-lg"
+              >
+                <Pencil className="size-4" />
+              </Button>
+              <Button size="sm" variant="ghost" onClick={() => handleDelete(roomType)} className="rounded-lg text-red-500 hover:text-red-600">
+                <Trash2 className="size-4" />
+              </Button>
+            </div>
+            {roomType.description && <p className="text-xs text-slate-600 dark:text-slate-400 line-clamp-2">{roomType.description}</p>}
+            <div className="flex flex-wrap gap-2">
+              {roomType.images?.map((image) => (
+                <div key={image.id} className="relative size-16 rounded-lg overflow-hidden group">
+                  <ImageWithFallback src={getImageUrl(image.image_url)} alt={roomType.name} className="size-full object-cover" />
+                  <button
+                    type="button"
+                    onClick={() => handleDeleteImage(roomType, image.id)}
+                    className="absolute inset-0 hidden group-hover:flex items-center justify-center bg-black/50 text-white"
+                    aria-label={t('deletePhoto')}
+                  >
+                    <Trash2 className="size-4" />
+                  </button>
+                </div>
+              ))}
+              <label className="size-16 rounded-lg border border-dashed border-slate-300 dark:border-slate-700 flex items-center justify-center cursor-pointer text-slate-400 hover:text-amber-500">
+                {uploadingId === roomType.id ? <Loader2 className="size-4 animate-spin" /> : <ImagePlus className="size-4" />}
+                <input
+                  type="file"
+                  accept="image/*"
+                  multiple
+                  className="hidden"
+                  disabled={uploadingId !== null}
+                  onChange={(e) => { void handleUpload(roomType, e.target.files); e.target.value = ''; }}
+                />
+              </label>
+            </div>
+          </div>
+        ))}
+      </motion.div>
+    </div>
+  );
+}
@@ -0,0 +1,209 @@
+"use client";
+
+import { useState, useEffect, useCallback } from 'react';
+import { ShieldAlert, Loader2, Save, ShieldCheck } from 'lucide-react';
+import { toast } from 'sonner';
+import { Button } from '@/app/components/ui/button';
+import { Input } from '@/app/components/ui/input';
+import { Textarea } from '@/app/components/ui/textarea';
+import { api, SpamForm, SpamReason, SpamRejection, SpamRejectionCount } from '@/app/services/api';
+import { useTranslations } from 'next-intl';
+import { motion } from "framer-motion";
+
+const spamForms: SpamForm[] = ['contact', 'register', 'forgot_password'];
+const spamReasons: SpamReason[] = ['honeypot', 'too_fast', 'too_many_links', 'blocked_word', 'challenge_failed'];
+
+export function SpamProtectionSettings() {
+  const t = useTranslations('spamProtection');
+  const [minSubmitSeconds, setMinSubmitSeconds] = useState('3');
+  const [maxLinks, setMaxLinks] = useState('2');
+  const [blockedWords, setBlockedWords] = useState('');
+  const [isSaving, setIsSaving] = useState(false);
+  const [stats, setStats] = useState<SpamRejectionCount[]>([]);
+  const [rejections, setRejections] = useState<SpamRejection[]>([]);
+  const [form, setForm] = useState<SpamForm | ''>('');
+  const [reason, setReason] = useState<SpamReason | ''>('');
+  const [isLoading, setIsLoading] = useState(false);
+
+  useEffect(() => {
+    const fetchSettings = async () => {
+      try {
+        const [settingsRes, statsRes] = await Promise.all([api.getSpamSettings(), api.getSpamStats(30)]);
+        setMinSubmitSeconds(String(settingsRes.data.min_submit_seconds));
+        setMaxLinks(String(settingsRes.data.max_links));
+        setBlockedWords(settingsRes.data.blocked_words);
+        setStats(statsRes.data || []);
+      } catch (error) {
+        console.error("Failed to fetch spam settings:", error);
+      }
+    };
+    void fetchSettings();
+  }, []);
+
+  const fetchRejections = useCallback(async () => {
+    setIsLoading(true);
+    try {
+      const res = await api.getSpamRejections(form, reason);
+      setRejections(res.data || []);
+    } catch (error) {
+      console.error("Failed to fetch spam rejections:", error);
+    } finally {
+      setIsLoading(false);
+    }
+  }, [form, reason]);
+
+  useEffect(() => {
+    void fetchRejections();
+  }, [fetchRejections]);
+
+  const handleSave = async () => {
+    setIsSaving(true);
+    try {
+      const res = await api.updateSpamSettings({
+        min_submit_seconds: Number(minSubmitSeconds) || 0,
+        max_links: Number(maxLinks) || 0,
+        blocked_words: blockedWords,
+      });
+      setBlockedWords(res.data.blocked_words);
+      toast.success(t('saved'));
+    } catch (error) {
+      toast.error(error instanceof Error ? error.message : t('saveFailed'));
+    } finally {
+      setIsSaving(false);
+    }
+  };
+
+  // Total penolakan 30 hari terakhir per alasan
+  const totalsByReason = spamReasons.map((r) => ({
+    reason: r,
+    count: stats.filter((s) => s.reason === r).reduce((sum, s) => sum + s.count, 0),
+  }));
+
+  return (
+    <div className="p-4 md:p-8 space-y-6 md:space-y-8 bg-gray-50 dark:bg-slate-950 min-h-screen">
+      <motion.div
+        initial={{ opacity: 0, y: -20 }}
+        animate={{ opacity: 1, y: 0 }}
+        transition={{ duration: 0.4 }}
+      >
+        <h2 className="text-2xl md:text-3xl font-bold text-amber-600 dark:text-amber-500">{t('title')}</h2>
+        <p className="text-slate-500 dark:text-slate-400 text-xs md:text-sm">{t('subtitle')}</p>
+      </motion.div>
+
+      <div className="grid grid-cols-2 md:grid-cols-5 gap-4">
+        {totalsByReason.map(({ reason: r, count }) => (
+          <div key={r} className="bg-white dark:bg-slate-900/40 rounded-2xl border border-slate-200 dark:border-slate-800 p-4">
+            <p className="text-[10px] uppercase tracking-wider text-slate-500">{t(`reason.${r}`)}</p>
+            <p className="text-2xl font-bold text-slate-900 dark:text-white">{count}</p>
+            <p className="text-[10px] text-slate-400">{t('last30Days')}</p>
+          </div>
+        ))}
+      </div>
+
+      <motion.div
+        initial={{ opacity: 0, y: 20 }}
+        animate={{ opacity: 1, y: 0 }}
+        transition={{ delay: 0.1, duration: 0.4 }}
+        className="bg-white dark:bg-slate-900/40 rounded-2xl border border-slate-200 dark:border-slate-800 p-4 md:p-6 space-y-4"
+      >
+        <h3 className="font-semibold text-slate-900 dark:text-white">{t('rules')}</h3>
+        <div className="grid md:grid-cols-2 gap-4">
+          <label className="text-sm text-slate-600 dark:text-slate-300 space-y-1">
+            <span>{t('minSubmitSeconds')}</span>
+            <Input type="number" min={0} max={60} value={minSubmitSeconds} onChange={(e) => setMinSubmitSeconds(e.target.value)} className="rounded-xl" />
+          </label>
+          <label className="text-sm text-slate-600 dark:text-slate-300 space-y-1">
+            <span>{t('maxLinks')}</span>
+            <Input type="number" min={0} max={20} value={maxLinks} onChange={(e) => setMaxLinks(e.target.value)} className="rounded-xl" />
+          </label>
+        </div>
+        <label className="block text-sm text-slate-600 dark:text-slate-300 space-y-1">
+          <span>{t('blockedWords')}</span>
+          <Textarea value={blockedWords} onChange={(e) => setBlockedWords(e.target.value)} placeholder={t('blockedWordsHint')} className="rounded-xl min-h-32 font-mono text-xs" />
+        </label>
+        <div className="flex justify-end">
+          <Button size="sm" disabled={isSaving} onClick={handleSave} className="bg-amber-500 hover:bg-amber-600 text-white rounded-xl">
+            {isSaving ? <Loader2 className="size-4 animate-spin mr-1" /> : <Save className="size-4 mr-1" />}
+            {t('save')}
+          </Button>
+        </div>
+      </motion.div>
+
+      <motion.div
+        initial={{ opacity: 0, y: 20 }}
+        animate={{ opacity: 1, y: 0 }}
+        transition={{ delay: 0.2, duration: 0.4 }}
+        className="bg-white dark:bg-slate-900/40 rounded-2xl border border-slate-200 dark:border-slate-800 overflow-x-auto pb-20 md:pb-0"
+      >
+        <div className="flex flex-col md:flex-row md:items-center justify-between gap-3 p-4">
+          <h3 className="font-semibold text-slate-900 dark:text-white">{t('rejectionLog')}</h3>
+          <div className="flex gap-2">
+            <select
+              value={form}
+              onChange={(e) => setForm(e.target.value as SpamForm | '')}
+              className="h-9 rounded-xl border border-slate-200 dark:border-slate-700 bg-transparent px-3 text-xs text-slate-700 dark:text-slate-300"
+            >
+              <option value="">{t('allForms')}</option>
+              {spamForms.map((f) => <option key={f} value={f}>{t(`form.${f}`)}</option>)}
+            </select>
+            <select
+              value={reason}
+              onChange={(e) => setReason(e.target.value as SpamReason | '')}
+              className="h-9 rounded-xl border border-slate-200 dark:border-slate-700 bg-transparent px-3 text-xs text-slate-700 dark:text-slate-300"
+            >
+              <option value="">{t('allReasons')}</option>
+              {spamReasons.map((r) => <option key={r} value={r}>{t(`reason.${r}`)}</option>)}
+            </select>
+          </div>
+        </div>
+
+        {isLoading ? (
+          <div className="py-20 flex justify-center">
+            <Loader2 className="size-8 animate-spin text-amber-500" />
+          </div>
+        ) : rejections.length === 0 ? (
+          <div className="py-20 text-center">
+            <ShieldCheck className="size-12 text-slate-400 dark:text-slate-700 mx-auto mb-4" />
+            <p className="text-slate-500">{t('empty')}</p>
+          </div>
+        ) : (
+          <table className="w-full text-sm">
+            <thead>
+              <tr className="text-left text-[10px] uppercase tracking-wider text-slate-500 border-y border-slate-200 dark:border-slate-800">
+                <th className="p-4">{t('time')}</th>
+                <th className="p-4">{t('formColumn')}</th>
+                <th className="p-4">{t('reasonColumn')}</th>
+                <th className="p-4">{t('sender')}</th>
+                <th className="p-4">{t('content')}</th>
+              </tr>
+            </thead>
+            <tbody>
+              {rejections.map((r) => (
+                <tr key={r.id} className="border-b border-slate-100 dark:border-slate-800/50 text-slate-700 dark:text-slate-300 align-top">
+                  <td className="p-4 text-xs text-slate-500 whitespace-nowrap">
+                    {new Date(r.created_at).toLocaleString('id-ID', { day: 'numeric', month: 'short', hour: '2-digit', minute: '2-digit' })}
+                  </td>
+                  <td className="p-4 text-xs">{t(`form.${r.form}`)}</td>
+                  <td className="p-4">
+                    <span className="inline-flex items-center gap-1 px-2 py-0.5 text-[10px] font-bold rounded-lg border uppercase bg-red-500/10 text-red-500 border-red-500/20">
+                      <ShieldAlert className="size-3" />
+                      {t(`reason.${r.reason}`)}
+                    </span>
+                    <p className="text-[10px] text-slate-500 mt-1">{r.detail}</p>
+                  </td>
+                  <td className="p-4 text-xs">
+                    <p className="text-slate-900 dark:text-white">{r.email || '-'}</p>
+                    <p className="text-slate-500">{r.ip_address}</p>
+                  </td>
+                  <td className="p-4 max-w-md">
+                    <p className="text-xs truncate" title={r.content}>{r.content || '-'}</p>
+                  </td>
+                </tr>
+              ))}
+            </tbody>
+          </table>
+        )}
+      </motion.div>
+    </div>
+  );
+}
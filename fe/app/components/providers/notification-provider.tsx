@@ -31,19 +31,8 @@ export const NotificationProvider = ({ children }: { children: React.ReactNode }
     newSocket.on("connect", () => {
       console.log("Connected to notification server");
       setIsConnected(true);
-      
-      // Authenticate with user ID if available
-      const userStr = localStorage.getItem("user");
-      if (userStr) {
-        try {
-          const user = JSON.parse(userStr);
-          if (user.id) {
-            newSocket.emit("authenticate", user.id);
-          }
-        } catch (e) {
-          console.error("Failed to parse user for socket auth", e);
-        }
-      }
+      // No explicit "authenticate" emit: the server derives the user from the
+      // HttpOnly access_token cookie sent with the handshake.
     });
 
     newSocket.on("disconnect", () => {
@@ -58,11 +47,50 @@ export const NotificationProvider = ({ children }: { children: React.ReactNode }
       });
     });
 
+    // Domain events pushed by the backend (payment/booking/bill lifecycle)
+    const domainEventMessages: Record<string, { title: string; type: "success" | "error" | "info" }> = {
+      "payment.confirmed": { title: "Pembayaran dikonfirmasi", type: "success" },
+      "payment.rejected": { title: "Pembayaran ditolak", type: "error" },
+      "payment.proof_uploaded": { title: "Bukti pembayaran diunggah", type: "info" },
+      "booking.created": { title: "Pesanan baru dibuat", type: "info" },
+      "booking.cancelled": { title: "Pesanan dibatalkan", type: "info" },
+      "bill.created": { title: "Tagihan baru", type: "info" },
+      "bill.reminder": { title: "Pengingat tagihan", type: "info" },
+      "bill.rent_change": { title: "Perubahan harga sewa", type: "info" },
+      "contact.received": { title: "Pesan kontak baru", type: "info" },
+    };
+    const handleDomainEvent = (eventType: string) => {
+      const entry = domainEventMessages[eventType];
+      if (!entry) return;
+      toast[entry.type](entry.title);
+      window.dispatchEvent(new CustomEvent("domain-event", { detail: eventType }));
+    };
+    Object.keys(domainEventMessages).forEach((eventType) => {
+      newSocket.on(eventType, () => handleDomainEvent(eventType));
+    });
+
+    // SSE fallback when the socket.io handshake keeps failing (old phones, corporate proxies).
+    // EventSource reconnects on its own and sends Last-Event-ID so missed events are replayed.
+    let eventSource: EventSource | null = null;
+    let failedAttempts = 0;
+    newSocket.on("connect_error", () => {
+      failedAttempts++;
+      if (eventSource || failedAttempts < 3 || typeof EventSource === "undefined") return;
+      const apiUrl = process.env.NEXT_PUBLIC_API_URL || "http://localhost:8081/api";
+      eventSource = new EventSource(`${apiUrl}/notifications/stream`, { withCredentials: true });
+      Object.keys(domainEventMessages).forEach((eventType) => {
+        eventSource?.addEventListener(eventType, () => handleDomainEvent(eventType));
+      });
+      eventSource.onopen = () => setIsConnected(true);
+      newSocket.close();
+    });
+
     // eslint-disable-next-line react-hooks/set-state-in-effect
     setSocket(() => newSocket);
 
     return () => {
       newSocket.close();
+      eventSource?.close();
     };
   }, []);
 
@@ -0,0 +1,26 @@
+import { AirVent, Bath, Bed, Car, CircleCheck, Lamp, Refrigerator, Shirt, ShieldCheck, Tv, Utensils, WashingMachine, Wifi, Archive, Bike, Flame, LucideIcon } from 'lucide-react';
+
+// icon_key dari katalog fasilitas (backend) -> ikon lucide
+export const facilityIcons: Record<string, LucideIcon> = {
+  ac: AirVent,
+  wifi: Wifi,
+  bath: Bath,
+  bed: Bed,
+  wardrobe: Archive,
+  desk: Lamp,
+  tv: Tv,
+  fridge: Refrigerator,
+  kitchen: Utensils,
+  laundry: WashingMachine,
+  parking: Car,
+  motorbike: Bike,
+  water_heater: Flame,
+  security: ShieldCheck,
+  clothes: Shirt,
+};
+
+/** Ikon fasilitas; icon_key yang tidak dikenal memakai ikon centang */
+export function FacilityIcon({ iconKey, className }: { iconKey?: string; className?: string }) {
+  const Icon = (iconKey && facilityIcons[iconKey]) || CircleCheck;
+  return <Icon className={className} />;
+}
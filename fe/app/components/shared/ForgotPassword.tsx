@@ -8,6 +8,7 @@ import { Label } from '@/app/components/ui/label';
 import { ArrowLeft, CheckCircle2 } from 'lucide-react';
 import { api } from '@/app/services/api';
 import { ImageWithFallback } from './ImageWithFallback';
+import { useSpamProtection, HoneypotField, CaptchaWidget } from './SpamProtection';
 import { useTranslations } from 'next-intl';
 
 interface ForgotPasswordProps {
@@ -19,6 +20,7 @@ export function ForgotPassword({ onBack }: ForgotPasswordProps) {
   const [isLoading, setIsLoading] = useState(false);
   const [error, setError] = useState('');
   const [success, setSuccess] = useState(false);
+  const spam = useSpamProtection();
   const t = useTranslations('auth');
   const tc = useTranslations('common');
 
@@ -27,9 +29,10 @@ export function ForgotPassword({ onBack }: ForgotPasswordProps) {
     setIsLoading(true);
     setError('');
     try {
-      await api.forgotPassword(email);
+      await api.forgotPassword(email, spam.fields());
       setSuccess(true);
     } catch (err: unknown) {
+      spam.reset();
       if (err instanceof Error) {
         setError(err.message);
       } else {
@@ -107,7 +110,8 @@ export function ForgotPassword({ onBack }: ForgotPasswordProps) {
                     </div>
                 )}
 
-                <form onSubmit={handleSubmit} className="space-y-4">
+                <form onSubmit={handleSubmit} className="relative space-y-4">
+                    <HoneypotField {...spam.honeypotProps} />
                     <div>
                     <Label htmlFor="email">{t('emailLabel')}</Label>
                     <Input
@@ -121,9 +125,11 @@ export function ForgotPassword({ onBack }: ForgotPasswordProps) {
                     />
                     </div>
 
+                    <CaptchaWidget {...spam.captchaProps} />
+
                     <Button
                     type="submit"
-                    disabled={isLoading}
+                    disabled={isLoading || !spam.captchaReady}
                     className="w-full bg-stone-900 hover:bg-stone-800 text-white h-11 font-semibold mt-6"
                     >
                     {isLoading ? t('sending') : t('sendResetLink')}
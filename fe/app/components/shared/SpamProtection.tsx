@@ -0,0 +1,149 @@
+'use client';
+
+import { useCallback, useEffect, useRef, useState } from 'react';
+import { api, SpamFields } from '@/app/services/api';
+
+// Harus sama dengan CAPTCHA_PROVIDER di backend: hcaptcha | turnstile | fake (kosong = tanpa captcha)
+const captchaProvider = process.env.NEXT_PUBLIC_CAPTCHA_PROVIDER || '';
+const captchaSiteKey = process.env.NEXT_PUBLIC_CAPTCHA_SITE_KEY || '';
+
+// Token yang diterima FakeChallengeVerifier di backend
+const FAKE_CAPTCHA_TOKEN = 'fake-pass';
+
+const captchaScripts: Record<string, { src: string; global: 'hcaptcha' | 'turnstile' }> = {
+  hcaptcha: { src: 'https://js.hcaptcha.com/1/api.js?render=explicit', global: 'hcaptcha' },
+  turnstile: { src: 'https://challenges.cloudflare.com/turnstile/v0/api.js?render=explicit', global: 'turnstile' },
+};
+
+interface CaptchaApi {
+  render: (container: HTMLElement, options: Record<string, unknown>) => string;
+  reset: (widgetId?: string) => void;
+  remove?: (widgetId: string) => void;
+}
+
+let scriptPromise: Promise<CaptchaApi> | null = null;
+
+function loadCaptchaScript(): Promise<CaptchaApi> {
+  const script = captchaScripts[captchaProvider];
+  if (!scriptPromise) {
+    scriptPromise = new Promise((resolve, reject) => {
+      const el = document.createElement('script');
+      el.src = script.src;
+      el.async = true;
+      el.defer = true;
+      el.onload = () => resolve((window as unknown as Record<string, CaptchaApi>)[script.global]);
+      el.onerror = () => {
+        scriptPromise = null;
+        reject(new Error('Failed to load captcha script'));
+      };
+      document.head.appendChild(el);
+    });
+  }
+  return scriptPromise;
+}
+
+/**
+ * State anti-spam untuk satu form: form token dari server (waktu mulai diisi), honeypot dan token captcha.
+ * Panggil reset() setelah kiriman berhasil supaya captcha dan form token diterbitkan ulang.
+ */
+export function useSpamProtection() {
+  const [formToken, setFormToken] = useState('');
+  const [honeypot, setHoneypot] = useState('');
+  const [captchaToken, setCaptchaToken] = useState('');
+  const [resetKey, setResetKey] = useState(0);
+
+  useEffect(() => {
+    let cancelled = false;
+    api.getFormToken()
+      .then((res) => {
+        if (!cancelled) setFormToken(res.form_token);
+      })
+      .catch((error) => console.error('Form token unavailable:', error));
+    return () => {
+      cancelled = true;
+    };
+  }, [resetKey]);
+
+  const fields = useCallback((): SpamFields => ({
+    captcha_token: captchaToken,
+    website: honeypot,
+    form_token: formToken,
+  }), [captchaToken, honeypot, formToken]);
+
+  const reset = useCallback(() => {
+    setFormToken('');
+    setCaptchaToken('');
+    setResetKey((key) => key + 1);
+  }, []);
+
+  return {
+    fields,
+    reset,
+    captchaReady: !captchaProvider || captchaToken !== '',
+    honeypotProps: { value: honeypot, onChange: setHoneypot },
+    captchaProps: { onVerify: setCaptchaToken, resetKey },
+  };
+}
+
+/** Input tersembunyi untuk menjebak bot; manusia tidak melihat dan tidak mengisinya */
+export function HoneypotField({ value, onChange }: { value: string; onChange: (value: string) => void }) {
+  return (
+    <div aria-hidden="true" className="absolute -left-[10000px] top-auto w-px h-px overflow-hidden">
+      <label>
+        Website
+        <input
+          type="text"
+          name="website"
+          tabIndex={-1}
+          autoComplete="off"
+          value={value}
+          onChange={(e) => onChange(e.target.value)}
+        />
+      </label>
+    </div>
+  );
+}
+
+/** Widget hCaptcha/Turnstile. Tidak menampilkan apa pun jika captcha tidak dikonfigurasi. */
+export function CaptchaWidget({ onVerify, resetKey }: { onVerify: (token: string) => void; resetKey: number }) {
+  const containerRef = useRef<HTMLDivElement>(null);
+  const widgetRef = useRef<{ api: CaptchaApi; id: string } | null>(null);
+
+  useEffect(() => {
+    if (captchaProvider === 'fake') {
+      onVerify(FAKE_CAPTCHA_TOKEN);
+      return;
+    }
+    if (!captchaScripts[captchaProvider] || !containerRef.current) return;
+
+    let cancelled = false;
+    loadCaptchaScript()
+      .then((captcha) => {
+        if (cancelled || !containerRef.current) return;
+        if (widgetRef.current) {
+          widgetRef.current.api.reset(widgetRef.current.id);
+          return;
+        }
+        const id = captcha.render(containerRef.current, {
+          sitekey: captchaSiteKey,
+          callback: (token: string) => onVerify(token),
+          'expired-callback': () => onVerify(''),
+          'error-callback': () => onVerify(''),
+        });
+        widgetRef.current = { api: captcha, id };
+      })
+      .catch((error) => console.error('Captcha unavailable:', error));
+
+    return () => {
+      cancelled = true;
+    };
+  }, [onVerify, resetKey]);
+
+  useEffect(() => () => {
+    if (widgetRef.current?.api.remove) widgetRef.current.api.remove(widgetRef.current.id);
+    widgetRef.current = null;
+  }, []);
+
+  if (!captchaScripts[captchaProvider]) return null;
+  return <div ref={containerRef} className="flex justify-center" />;
+}
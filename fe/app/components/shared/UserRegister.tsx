@@ -8,6 +8,7 @@ import { Label } from "@/app/components/ui/label";
 import { UserPlus, ArrowLeft, CheckCircle2 } from "lucide-react";
 import { api } from "@/app/services/api";
 import { ImageWithFallback } from "./ImageWithFallback";
+import { useSpamProtection, HoneypotField, CaptchaWidget } from "./SpamProtection";
 import { toast } from "sonner";
 import { useTranslations } from "next-intl";
 
@@ -31,6 +32,7 @@ export function UserRegister({
   const [isLoading, setIsLoading] = useState(false);
   const [error, setError] = useState("");
   const [isSuccess, setIsSuccess] = useState(false);
+  const spam = useSpamProtection();
   const t = useTranslations('auth');
   const tc = useTranslations('common');
 
@@ -45,7 +47,7 @@ export function UserRegister({
 
     setIsLoading(true);
     try {
-      await api.register({ username, password, email, nomor_hp: phone, alamat_asal: address, tanggal_lahir: birthdate, nik, role: "tenant" });
+      await api.register({ username, password, email, nomor_hp: phone, alamat_asal: address, tanggal_lahir: birthdate, nik, role: "tenant", ...spam.fields() });
       toast.success(t('accountCreatedToast'), {
         description: t('accountCreatedToastDesc'),
         duration: 5000,
@@ -55,6 +57,8 @@ export function UserRegister({
         onRegisterSuccess();
       }, 2000);
     } catch (err: unknown) {
+      // Token captcha hanya berlaku sekali
+      spam.reset();
       if (err instanceof Error) {
         setError(err.message);
       } else {
@@ -180,7 +184,8 @@ export function UserRegister({
             </div>
           )}
 
-          <form onSubmit={handleRegister} className="space-y-4">
+          <form onSubmit={handleRegister} className="relative space-y-4">
+            <HoneypotField {...spam.honeypotProps} />
             <div>
               <Label htmlFor="username">{t('chooseUsername')}</Label>
               <Input id="username" value={username} onChange={(e) => setUsername(e.target.value)} placeholder="Ex: arkan_tenant" className="mt-1.5 h-11 !bg-white !border-stone-900 border text-stone-900 placeholder:text-stone-400 focus-visible:!border-stone-900 focus-visible:ring-stone-900/20 rounded-xl [color-scheme:light]" required />
@@ -214,7 +219,9 @@ export function UserRegister({
               <Input id="confirm-password" type="password" value={confirmPassword} onChange={(e) => setConfirmPassword(e.target.value)} placeholder="••••••••" className="mt-1.5 h-11 !bg-white !border-stone-900 border text-stone-900 placeholder:text-stone-400 focus-visible:!border-stone-900 focus-visible:ring-stone-900/20 rounded-xl [color-scheme:light]" required />
             </div>
 
-            <Button type="submit" disabled={isLoading} className="w-full bg-stone-900 hover:bg-stone-800 text-white h-11 font-semibold mt-6">
+            <CaptchaWidget {...spam.captchaProps} />
+
+            <Button type="submit" disabled={isLoading || !spam.captchaReady} className="w-full bg-stone-900 hover:bg-stone-800 text-white h-11 font-semibold mt-6">
               {isLoading ? t('creatingAccount') : t('registerButton')}
             </Button>
           </form>
@@ -0,0 +1,31 @@
+'use client';
+
+import { useEffect, useState } from 'react';
+import { api } from '@/app/services/api';
+import { getImageUrl, isPrivateFileRef } from '@/app/utils/api-url';
+
+/**
+ * URL gambar bukti transfer. Bukti di folder privat diminta sebagai signed URL
+ * (GET /payments/:id/proof) setiap kali dibuka; bukti lama berupa URL publik dipakai langsung.
+ */
+export function usePaymentProofUrl(paymentId: number | string | null | undefined, ref: string | null | undefined) {
+  const [signedUrl, setSignedUrl] = useState<{ ref: string; url: string } | null>(null);
+  const isPrivate = isPrivateFileRef(ref);
+
+  useEffect(() => {
+    if (!isPrivate || !ref || paymentId == null) return;
+    let cancelled = false;
+    api.getPaymentProof(paymentId)
+      .then((res) => {
+        if (!cancelled) setSignedUrl({ ref, url: getImageUrl(res.url) });
+      })
+      .catch((err) => console.error('Failed to load payment proof:', err));
+    return () => {
+      cancelled = true;
+    };
+  }, [paymentId, ref, isPrivate]);
+
+  if (!ref) return '';
+  if (!isPrivate) return getImageUrl(ref);
+  return signedUrl?.ref === ref ? signedUrl.url : '';
+}
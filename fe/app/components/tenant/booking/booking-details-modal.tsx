@@ -5,7 +5,7 @@ import { Copy, CheckCircle2, Clock, XCircle, CreditCard, Calendar } from "lucide
 import { Button } from "@/app/components/ui/button";
 import { toast } from "sonner";
 import { ImageWithFallback } from "@/app/components/shared/ImageWithFallback";
-import { Payment } from "@/app/services/api";
+import { Payment, BookingDiscount } from "@/app/services/api";
 
 // import { Booking } from "@/app/services/api"; // Remove strict Booking import
 
@@ -23,6 +23,11 @@ interface BookingDetails {
     moveOutDate?: string;
     tanggal_keluar?: string;
     payments?: Payment[];
+    // Snapshot harga saat dipesan; kosong untuk pemesanan lama
+    subtotal?: number;
+    diskon?: number;
+    totalHarga?: number;
+    discounts?: BookingDiscount[];
 }
 
 interface BookingDetailsModalProps {
@@ -48,6 +53,10 @@ export function BookingDetailsModal({ isOpen, onClose, booking }: BookingDetails
     }
   };
 
+  // Diskon pemesanan awal; diskon perpanjangan ditampilkan di tagihannya masing-masing
+  const bookingDiscounts = (booking.discounts || []).filter((d) => !d.pembayaran_id);
+  const discountsForPayment = (paymentId: number) => (booking.discounts || []).filter((d) => d.pembayaran_id === paymentId);
+
   return (
     <Dialog open={isOpen} onOpenChange={onClose}>
       <DialogContent className="max-w-2xl max-h-[90vh] flex flex-col p-0 overflow-hidden bg-white dark:bg-slate-950 border-slate-200 dark:border-slate-800">
@@ -134,6 +143,27 @@ export function BookingDetailsModal({ isOpen, onClose, booking }: BookingDetails
                 </div>
               </div>
 
+              {/* Price Breakdown */}
+              {!!booking.totalHarga && (
+                <div className="p-4 border border-slate-200 dark:border-slate-800 rounded-xl mb-6 space-y-2 text-sm">
+                  <h5 className="font-semibold text-slate-500 mb-1">Price Breakdown</h5>
+                  <div className="flex justify-between">
+                    <span className="text-slate-500">Subtotal</span>
+                    <span>Rp {(booking.subtotal || 0).toLocaleString()}</span>
+                  </div>
+                  {bookingDiscounts.map((discount) => (
+                    <div key={discount.id ?? `${discount.type}-${discount.pricing_rule_id}`} className="flex justify-between text-emerald-600">
+                      <span>{discount.code ? `${discount.name} (${discount.code})` : discount.name}</span>
+                      <span>- Rp {discount.amount.toLocaleString()}</span>
+                    </div>
+                  ))}
+                  <div className="flex justify-between pt-2 border-t border-slate-100 dark:border-slate-800 font-bold">
+                    <span>Total</span>
+                    <span>Rp {booking.totalHarga.toLocaleString()}</span>
+                  </div>
+                </div>
+              )}
+
               {/* Transaction History */}
               <div>
                 <h5 className="font-semibold text-sm text-slate-500 mb-3">Transaction History</h5>
@@ -160,6 +190,11 @@ export function BookingDetailsModal({ isOpen, onClose, booking }: BookingDetails
                         </div>
                         <div className="text-right">
                           <p className="font-bold text-sm">Rp {payment.jumlah_bayar.toLocaleString()}</p>
+                          {payment.tipe_pembayaran === 'extend' && discountsForPayment(payment.id).map((discount) => (
+                            <p key={discount.id ?? discount.pricing_rule_id} className="text-[10px] text-emerald-600">
+                              {discount.name}: - Rp {discount.amount.toLocaleString()}
+                            </p>
+                          ))}
                           <Badge variant="secondary" className="text-[10px] h-5 px-1.5">
                             {payment.status_pembayaran}
                           </Badge>
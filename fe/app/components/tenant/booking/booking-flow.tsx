@@ -20,7 +20,7 @@ import {
   SelectTrigger,
   SelectValue,
 } from "@/app/components/ui/select";
-import { api, Room } from "@/app/services/api";
+import { api, Room, PriceQuote } from "@/app/services/api";
 import { toast } from "sonner";
 
 
@@ -69,6 +69,11 @@ export function BookingFlow({ roomId, onBack, initialData }: BookingFlowProps) {
   });
   const [proofFile, setProofFile] = useState<File | null>(null);
   const [loading, setLoading] = useState(false);
+  // Harga dari backend (diskon sewa panjang, promosi, voucher); null = hitung lokal
+  const [quote, setQuote] = useState<PriceQuote | null>(null);
+  const [voucherInput, setVoucherInput] = useState("");
+  const [appliedVoucher, setAppliedVoucher] = useState("");
+  const [applyingVoucher, setApplyingVoucher] = useState(false);
 
   const steps = [
     { number: 1, title: "Personal Info", description: "Your details" },
@@ -144,6 +149,47 @@ export function BookingFlow({ roomId, onBack, initialData }: BookingFlowProps) {
     }
   }, [room, pricePerMonth]);
 
+  const isMockRoom = roomId.toString().startsWith("mock-");
+
+  // Hitung ulang harga setiap tanggal/durasi/voucher berubah
+  useEffect(() => {
+    if (isMockRoom || !room || !formData.moveInDate || !formData.duration) {
+      setQuote(null);
+      return;
+    }
+    let cancelled = false;
+    api.getPriceQuote(Number(roomId), formData.moveInDate, parseInt(formData.duration), appliedVoucher || undefined)
+      .then((res) => { if (!cancelled) setQuote(res); })
+      .catch(() => {
+        if (cancelled) return;
+        setQuote(null);
+        // Voucher tidak lagi berlaku untuk tanggal/durasi ini
+        if (appliedVoucher) {
+          setAppliedVoucher("");
+          toast.error(`Voucher ${appliedVoucher} tidak berlaku untuk pesanan ini`);
+        }
+      });
+    return () => { cancelled = true; };
+  }, [isMockRoom, room, roomId, formData.moveInDate, formData.duration, appliedVoucher]);
+
+  const totalRent = quote ? quote.total : pricePerMonth * (parseInt(formData.duration) || 0);
+
+  const applyVoucher = async () => {
+    const code = voucherInput.trim().toUpperCase();
+    if (!code || isMockRoom || !formData.moveInDate) return;
+    setApplyingVoucher(true);
+    try {
+      const res = await api.getPriceQuote(Number(roomId), formData.moveInDate, parseInt(formData.duration), code);
+      setQuote(res);
+      setAppliedVoucher(code);
+      toast.success(`Voucher ${code} dipakai`);
+    } catch (err: unknown) {
+      toast.error(err instanceof Error ? err.message : "Voucher tidak berlaku");
+    } finally {
+      setApplyingVoucher(false);
+    }
+  };
+
   const nextStep = async () => {
     if (step < 3) {
       setStep(step + 1);
@@ -162,6 +208,9 @@ export function BookingFlow({ roomId, onBack, initialData }: BookingFlowProps) {
         fd.append('durasi_sewa', formData.duration);
         fd.append('payment_type', formData.paymentType);
         fd.append('payment_method', formData.paymentMethod);
+        if (appliedVoucher) {
+          fd.append('voucher_code', appliedVoucher);
+        }
         
         if (formData.paymentMethod === 'transfer' && proofFile) {
           fd.append('proof', proofFile);
@@ -443,9 +492,15 @@ export function BookingFlow({ roomId, onBack, initialData }: BookingFlowProps) {
                           <span>Monthly rent</span>
                           <span>Rp {pricePerMonth.toLocaleString("id-ID")}</span>
                         </div>
+                        {quote?.discounts.map((discount) => (
+                          <div key={`${discount.type}-${discount.pricing_rule_id}`} className="flex justify-between text-emerald-700 dark:text-emerald-400">
+                            <span>{discount.name}</span>
+                            <span>- Rp {discount.amount.toLocaleString("id-ID")}</span>
+                          </div>
+                        ))}
                         <div className="font-bold pt-2 border-t border-blue-200 dark:border-blue-800 flex justify-between text-base">
                           <span>Total due</span>
-                          <span>Rp {(pricePerMonth * parseInt(formData.duration)).toLocaleString("id-ID")}</span>
+                          <span>Rp {totalRent.toLocaleString("id-ID")}</span>
                         </div>
                       </div>
                     </div>
@@ -638,14 +693,49 @@ export function BookingFlow({ roomId, onBack, initialData }: BookingFlowProps) {
                             {formData.duration} months
                           </span>
                         </div>
+                        <div className="flex justify-between">
+                          <span className="text-muted-foreground">
+                            Subtotal
+                          </span>
+                          <span className="font-medium">
+                            Rp {(quote ? quote.subtotal : totalRent).toLocaleString("id-ID")}
+                          </span>
+                        </div>
+                        {quote?.discounts.map((discount) => (
+                          <div key={`${discount.type}-${discount.pricing_rule_id}`} className="flex justify-between text-emerald-600">
+                            <span>{discount.code ? `${discount.name} (${discount.code})` : discount.name}</span>
+                            <span className="font-medium">- Rp {discount.amount.toLocaleString("id-ID")}</span>
+                          </div>
+                        ))}
                         <div className="flex justify-between">
                           <span className="text-muted-foreground">
                             Total Rent
                           </span>
                           <span className="font-medium">
-                            Rp {(pricePerMonth * parseInt(formData.duration)).toLocaleString("id-ID")}
+                            Rp {totalRent.toLocaleString("id-ID")}
                           </span>
                         </div>
+                        {!isMockRoom && (
+                          <div className="flex gap-2 pt-1">
+                            <Input
+                              value={voucherInput}
+                              onChange={(e) => setVoucherInput(e.target.value)}
+                              placeholder="Voucher code"
+                              maxLength={50}
+                              className="h-9 bg-background uppercase"
+                            />
+                            <Button
+                              type="button"
+                              variant="outline"
+                              size="sm"
+                              disabled={applyingVoucher || !voucherInput.trim()}
+                              onClick={applyVoucher}
+                              className="h-9"
+                            >
+                              {applyingVoucher ? <Loader2 className="w-4 h-4 animate-spin" /> : "Apply"}
+                            </Button>
+                          </div>
+                        )}
 
                          {formData.paymentType === "dp" && (
                           <>
@@ -655,7 +745,7 @@ export function BookingFlow({ roomId, onBack, initialData }: BookingFlowProps) {
                                   Down Payment (30%)
                                 </span>
                                 <span className="font-semibold text-orange-600">
-                                  Rp {(pricePerMonth * parseInt(formData.duration) * 0.3).toLocaleString("id-ID")}
+                                  Rp {(totalRent * 0.3).toLocaleString("id-ID")}
                                 </span>
                               </div>
                               <p className="text-xs text-muted-foreground mt-1">
@@ -667,7 +757,7 @@ export function BookingFlow({ roomId, onBack, initialData }: BookingFlowProps) {
                                 Remaining (70%)
                               </span>
                               <span className="font-semibold text-blue-600">
-                                Rp {(pricePerMonth * parseInt(formData.duration) * 0.7).toLocaleString("id-ID")}
+                                Rp {(totalRent * 0.7).toLocaleString("id-ID")}
                               </span>
                             </div>
                             <p className="text-xs text-muted-foreground">
@@ -686,8 +776,8 @@ export function BookingFlow({ roomId, onBack, initialData }: BookingFlowProps) {
                           <span className="font-bold">Amount to Pay Now</span>
                           <span className="font-bold text-lg">
                              {formData.paymentType === "dp"
-                              ? `Rp ${(pricePerMonth * parseInt(formData.duration) * 0.3).toLocaleString("id-ID")}`
-                              : `Rp ${(pricePerMonth * parseInt(formData.duration)).toLocaleString("id-ID")}`
+                              ? `Rp ${(totalRent * 0.3).toLocaleString("id-ID")}`
+                              : `Rp ${totalRent.toLocaleString("id-ID")}`
                              }
                           </span>
                         </div>
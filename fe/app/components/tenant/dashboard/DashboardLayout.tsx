@@ -4,7 +4,7 @@ import { RoomDetail } from '../room-detail/RoomDetail';
 import { ContactUs } from './contact-us';
 
 import { motion, AnimatePresence } from 'framer-motion';
-import { Home, History, User, ImageIcon } from 'lucide-react';
+import { Home, History, User, ImageIcon, Wrench } from 'lucide-react';
 
 // Modular Components & Hooks
 import { Header } from './Header';
@@ -14,6 +14,7 @@ import { GalleryView } from './views/GalleryView';
 import { HistoryView } from './views/HistoryView';
 import { BookingView } from './views/BookingView';
 import { ProfileView } from './views/ProfileView';
+import { TicketsView } from './views/TicketsView';
 import { Footer } from './Footer';
 import { useProfile } from './hooks/useProfile';
 import { MenuItem } from './types';
@@ -113,6 +114,7 @@ export function UserPlatform({ onLogout, onBackToAdmin, isLoggedIn: initialIsLog
     { id: 'home', label: t('home'), icon: Home },
     { id: 'gallery', label: t('gallery'), icon: ImageIcon },
     { id: 'history', label: t('ordersAndBills'), icon: History, hidden: !isLoggedIn },
+    { id: 'tickets', label: t('tickets'), icon: Wrench, hidden: !isLoggedIn },
     { id: 'profile', label: t('profile'), icon: User, hidden: !isLoggedIn },
   ];
 
@@ -205,6 +207,8 @@ export function UserPlatform({ onLogout, onBackToAdmin, isLoggedIn: initialIsLog
               <HistoryView isLoggedIn={isLoggedIn} onLogout={onLogout} />
             )}
 
+            {activeView === 'tickets' && isLoggedIn && <TicketsView />}
+
             {activeView === 'profile' && isLoggedIn && (
               <ProfileView
                 isLoadingProfile={profileSystem.isLoadingProfile}
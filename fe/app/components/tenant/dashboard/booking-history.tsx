@@ -780,6 +780,7 @@ export function BookingHistory() {
             currentEndDate: selectedBooking.moveOutDate,
             pricePerMonth: selectedBooking.monthlyRent,
             image: selectedBooking.roomImage,
+            roomId: selectedBooking.roomId,
           }}
           onSuccess={refreshData}
         />
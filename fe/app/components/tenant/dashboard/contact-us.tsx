@@ -8,6 +8,7 @@ import { Input } from '@/app/components/ui/input';
 import { toast } from 'sonner';
 import { api } from '@/app/services/api';
 import { useTranslations } from 'next-intl';
+import { useSpamProtection, HoneypotField, CaptchaWidget } from '@/app/components/shared/SpamProtection';
 
 
 export function ContactUs() {
@@ -19,6 +20,7 @@ export function ContactUs() {
   });
   const [loading, setLoading] = useState(false);
   const [emailError, setEmailError] = useState('');
+  const spam = useSpamProtection();
 
   const validateEmail = (email: string) => {
     const emailRegex = /^[^\s@]+@[^\s@]+\.[^\s@]+$/;
@@ -57,9 +59,10 @@ export function ContactUs() {
 
     setLoading(true);
     try {
-      await api.sendContactForm(formData);
+      await api.sendContactForm({ ...formData, ...spam.fields() });
       setLoading(false);
       setFormData({ name: '', email: '', message: '' });
+      spam.reset();
       toast.success(t('messageSentSuccess'), {
         description: t('messageSentDesc'),
         duration: 4000,
@@ -67,6 +70,7 @@ export function ContactUs() {
       });
     } catch (err: unknown) {
       setLoading(false);
+      spam.reset();
       let errorMessage = t('messageSentError');
       if (err instanceof Error) {
         errorMessage = err.message;
@@ -195,7 +199,8 @@ export function ContactUs() {
               whileInView={{ opacity: 1, x: 0 }}
               className="lg:col-span-2 bg-white dark:bg-slate-900 rounded-[2rem] p-10 shadow-xl shadow-slate-200 dark:shadow-none border border-slate-100 dark:border-slate-800"
             >
-              <form onSubmit={handleSubmit} className="space-y-6">
+              <form onSubmit={handleSubmit} className="relative space-y-6">
+                <HoneypotField {...spam.honeypotProps} />
                 <div className="grid grid-cols-1 md:grid-cols-2 gap-6">
                   <div className="space-y-2">
                     <label className="text-sm font-bold text-slate-700 dark:text-slate-300">{t('fullName')}</label>
@@ -236,9 +241,11 @@ export function ContactUs() {
                   ></textarea>
                 </div>
 
+                <CaptchaWidget {...spam.captchaProps} />
+
                 <Button 
                   type="submit"
-                  disabled={loading}
+                  disabled={loading || !spam.captchaReady}
                   className="w-full md:w-auto px-10 h-12 bg-stone-900 dark:bg-amber-600 hover:bg-stone-800 dark:hover:bg-amber-700 disabled:bg-stone-600 text-white rounded-xl flex items-center gap-2 group transition-all"
                 >
                   {loading ? (
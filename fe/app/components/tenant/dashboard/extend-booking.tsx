@@ -1,10 +1,10 @@
-import React, { useState, useRef } from 'react';
+import React, { useState, useRef, useEffect } from 'react';
 import { motion, AnimatePresence } from 'framer-motion';
-import { Calendar, Clock, X, ChevronRight, AlertCircle, Upload, CheckCircle2, Building, Banknote } from 'lucide-react';
+import { Calendar, Clock, X, ChevronRight, AlertCircle, Upload, CheckCircle2, Building, Banknote, Ticket } from 'lucide-react';
 import { Button } from '@/app/components/ui/button';
 import { Badge } from '@/app/components/ui/badge';
 import { toast } from 'sonner';
-import { api } from '@/app/services/api';
+import { api, PriceQuote } from '@/app/services/api';
 
 import NextImage from 'next/image';
 
@@ -17,6 +17,7 @@ interface ExtendBookingProps {
     currentEndDate: string;
     pricePerMonth: number;
     image: string;
+    roomId?: number; // untuk simulasi diskon/voucher
   };
   onSuccess?: () => void;
 }
@@ -27,8 +28,28 @@ export function ExtendBooking({ isOpen, onClose, bookingData, onSuccess }: Exten
   const [paymentMethod, setPaymentMethod] = useState<'transfer' | 'cash'>('transfer');
   const [proofFile, setProofFile] = useState<File | null>(null);
   const fileInputRef = useRef<HTMLInputElement>(null);
+  const [quote, setQuote] = useState<PriceQuote | null>(null);
+  const [voucherInput, setVoucherInput] = useState('');
+  const [appliedVoucher, setAppliedVoucher] = useState('');
 
-  const totalCost = duration * bookingData.pricePerMonth;
+  // Harga perpanjangan dihitung untuk periode mulai dari tanggal selesai sewa saat ini
+  useEffect(() => {
+    if (!isOpen || !bookingData.roomId) return;
+    let cancelled = false;
+    api.getPriceQuote(bookingData.roomId, bookingData.currentEndDate, duration, appliedVoucher || undefined)
+      .then((res) => { if (!cancelled) setQuote(res); })
+      .catch((error) => {
+        if (cancelled) return;
+        setQuote(null);
+        if (appliedVoucher) {
+          setAppliedVoucher('');
+          toast.error(error instanceof Error ? error.message : 'Voucher tidak berlaku');
+        }
+      });
+    return () => { cancelled = true; };
+  }, [isOpen, bookingData.roomId, bookingData.currentEndDate, duration, appliedVoucher]);
+
+  const totalCost = quote ? quote.total : duration * bookingData.pricePerMonth;
 
   // Logika hitung tanggal baru (sederhana)
   const calculateNewDate = (currentDate: string, monthsToAdd: number) => {
@@ -53,14 +74,14 @@ export function ExtendBooking({ isOpen, onClose, bookingData, onSuccess }: Exten
 
     setLoading(true);
     try {
-      const paymentResponse = await api.extendBooking(bookingData.id, duration, paymentMethod); // Call API Directly
+      const paymentResponse = await api.extendBooking(bookingData.id, duration, paymentMethod, appliedVoucher || undefined); // Call API Directly
 
       if (paymentMethod === 'transfer' && proofFile && paymentResponse.id) {
         await api.uploadPaymentProof(paymentResponse.id, proofFile);
       }
 
       toast.success('Permintaan perpanjangan sewa berhasil dibuat!', {
-        description: `Tagihan baru sebesar Rp ${totalCost.toLocaleString()} telah dibuat. Silakan tunggu konfirmasi dari admin.`,
+        description: `Tagihan baru sebesar Rp ${(paymentResponse.jumlah_bayar ?? totalCost).toLocaleString()} telah dibuat. Silakan tunggu konfirmasi dari admin.`,
         duration: 5000,
       });
       
@@ -69,7 +90,7 @@ export function ExtendBooking({ isOpen, onClose, bookingData, onSuccess }: Exten
     } catch (error) {
       console.error("Failed to extend booking", error);
       toast.error("Gagal memperpanjang sewa", {
-        description: "Terjadi kesalahan saat memproses permintaan anda."
+        description: error instanceof Error ? error.message : "Terjadi kesalahan saat memproses permintaan anda."
       });
     } finally {
       setLoading(false);
@@ -253,6 +274,33 @@ export function ExtendBooking({ isOpen, onClose, bookingData, onSuccess }: Exten
                 </div>
               )}
 
+              {/* Voucher */}
+              {bookingData.roomId && (
+                <div className="space-y-2">
+                  <label className="text-sm font-bold text-slate-700 flex items-center gap-2">
+                    <Ticket className="w-4 h-4" /> Kode Voucher
+                  </label>
+                  <div className="flex gap-2">
+                    <input
+                      value={voucherInput}
+                      onChange={(e) => setVoucherInput(e.target.value)}
+                      placeholder={appliedVoucher || 'Opsional'}
+                      maxLength={50}
+                      className="flex-1 h-10 px-3 rounded-xl border border-slate-200 text-sm uppercase"
+                    />
+                    <Button
+                      type="button"
+                      variant="outline"
+                      disabled={!voucherInput.trim()}
+                      onClick={() => { setAppliedVoucher(voucherInput.trim().toUpperCase()); setVoucherInput(''); }}
+                      className="h-10 rounded-xl"
+                    >
+                      Pakai
+                    </Button>
+                  </div>
+                </div>
+              )}
+
               {/* Summary Info */}
               <div className="space-y-3 pt-4">
                 <div className="flex justify-between text-sm">
@@ -263,6 +311,12 @@ export function ExtendBooking({ isOpen, onClose, bookingData, onSuccess }: Exten
                   <span className="text-slate-500">Biaya Per Bulan</span>
                   <span className="font-medium text-slate-900">${bookingData.pricePerMonth}</span>
                 </div>
+                {quote?.discounts.map((discount) => (
+                  <div key={`${discount.type}-${discount.pricing_rule_id}`} className="flex justify-between text-sm text-emerald-600">
+                    <span>{discount.code ? `${discount.name} (${discount.code})` : discount.name}</span>
+                    <span className="font-medium">- Rp {discount.amount.toLocaleString('id-ID')}</span>
+                  </div>
+                ))}
                 <div className="pt-3 border-t border-dashed border-slate-200 flex justify-between items-center">
                   <span className="font-bold text-slate-900">Total Pembayaran</span>
                   <span className="text-2xl font-black text-stone-900">${totalCost}</span>
@@ -1,6 +1,6 @@
 import { useState, useMemo, useCallback } from 'react';
 import useSWR from 'swr';
-import { api, PaymentReminder, Payment, Booking } from '@/app/services/api';
+import { api, PaymentReminder, Payment, Booking, BookingDiscount } from '@/app/services/api';
 import { getImageUrl } from '@/app/utils/api-url';
 
 export interface UIBooking {
@@ -22,6 +22,12 @@ export interface UIBooking {
   startDate: Date;
   endDate: Date;
   dueDate: Date;
+  roomId?: number;
+  // Snapshot harga untuk invoice (0 untuk pemesanan lama)
+  subtotal?: number;
+  diskon?: number;
+  totalHarga?: number;
+  discounts?: BookingDiscount[];
 }
 
 export function useHistory() {
@@ -88,10 +94,15 @@ export function useHistory() {
         rawStatus: b.status_bayar,
         pendingPaymentId: actionablePayment?.id,
         paymentStatus: actionablePayment?.status_pembayaran,
-        payments: b.pembayaran || [],
+        payments: b.pembayaran || b.payments || [],
         startDate: start,
         endDate: end,
-        dueDate: due
+        dueDate: due,
+        roomId: b.kamar?.id,
+        subtotal: b.subtotal,
+        diskon: b.diskon,
+        totalHarga: b.total_harga,
+        discounts: b.discounts || [],
       };
     });
   }, [bookingsData]);
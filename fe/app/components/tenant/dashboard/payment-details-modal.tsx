@@ -8,7 +8,7 @@ import {
 import { Badge } from '@/app/components/ui/badge';
 import { PaymentReminder } from '@/app/services/api';
 import { CheckCircle2, CreditCard, BanknoteIcon as FileSpreadsheet, ListOrdered, Calendar } from 'lucide-react';
-import { getImageUrl } from '@/app/utils/api-url';
+import { usePaymentProofUrl } from '@/app/components/shared/usePaymentProofUrl';
 import Image from 'next/image';
 import { useTranslations } from 'next-intl';
 
@@ -20,6 +20,7 @@ interface PaymentDetailsModalProps {
 
 export function PaymentDetailsModal({ isOpen, onClose, reminder }: PaymentDetailsModalProps) {
   const t = useTranslations('history');
+  const proofUrl = usePaymentProofUrl(reminder?.pembayaran?.id, reminder?.pembayaran?.bukti_transfer);
   if (!reminder || !reminder.pembayaran) return null;
 
   const { pembayaran } = reminder;
@@ -109,12 +110,15 @@ export function PaymentDetailsModal({ isOpen, onClose, reminder }: PaymentDetail
                     {t('paymentProofTitle')}
                  </p>
                  <div className="rounded-xl overflow-hidden border border-slate-200 dark:border-slate-700 bg-slate-50 relative aspect-[4/3] w-full">
-                   <Image 
-                     src={getImageUrl(pembayaran.bukti_transfer)} 
-                     alt="Proof of Payment"
-                     fill
-                     className="object-contain"
-                   />
+                   {proofUrl && (
+                     <Image 
+                       src={proofUrl} 
+                       alt="Proof of Payment"
+                       fill
+                       className="object-contain"
+                       unoptimized
+                     />
+                   )}
                  </div>
                </div>
             )}
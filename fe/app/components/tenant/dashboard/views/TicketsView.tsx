@@ -0,0 +1,253 @@
+import { useState, useEffect, useCallback } from 'react';
+import { motion } from 'framer-motion';
+import { Wrench, Plus, Loader2, Send, ArrowLeft, Inbox } from 'lucide-react';
+import { toast } from 'sonner';
+import { Button } from '@/app/components/ui/button';
+import { Input } from '@/app/components/ui/input';
+import { Textarea } from '@/app/components/ui/textarea';
+import { api, Booking, MaintenanceTicket, TicketCategory, TicketPriority } from '@/app/services/api';
+import { ticketStatusStyles, ticketPriorityStyles } from '@/app/components/admin/MaintenanceTickets';
+import { getImageUrl } from '@/app/utils/api-url';
+import { useTranslations } from 'next-intl';
+
+const categories: TicketCategory[] = ['listrik', 'air', 'ac', 'furnitur', 'kebersihan', 'internet', 'lainnya'];
+const priorities: TicketPriority[] = ['low', 'medium', 'high', 'urgent'];
+const MAX_PHOTOS = 5;
+
+const selectClass = 'w-full h-10 px-3 rounded-xl border border-slate-200 dark:border-slate-700 bg-white dark:bg-slate-900 text-sm text-slate-900 dark:text-white';
+
+export function TicketsView() {
+  const t = useTranslations('tickets');
+  const [tickets, setTickets] = useState<MaintenanceTicket[]>([]);
+  const [rooms, setRooms] = useState<Booking[]>([]);
+  const [isLoading, setIsLoading] = useState(true);
+  const [isCreating, setIsCreating] = useState(false);
+  const [isSaving, setIsSaving] = useState(false);
+  const [selected, setSelected] = useState<MaintenanceTicket | null>(null);
+  const [comment, setComment] = useState('');
+  const [form, setForm] = useState({ kamar_id: '', title: '', description: '', category: 'lainnya' as TicketCategory, priority: 'medium' as TicketPriority });
+  const [photos, setPhotos] = useState<File[]>([]);
+
+  const fetchTickets = useCallback(async () => {
+    try {
+      const res = await api.getMyTickets();
+      setTickets(res.data || []);
+    } catch (error) {
+      console.error('Failed to fetch tickets:', error);
+    } finally {
+      setIsLoading(false);
+    }
+  }, []);
+
+  useEffect(() => {
+    void fetchTickets();
+    // Hanya kamar yang sedang disewa yang bisa dilaporkan
+    api.getMyBookings()
+      .then((bookings) => {
+        const active = (bookings || []).filter((b) => b.status_pemesanan === 'Confirmed');
+        setRooms(active);
+        if (active.length > 0) {
+          setForm((prev) => ({ ...prev, kamar_id: prev.kamar_id || String(active[0].kamar?.id ?? '') }));
+        }
+      })
+      .catch((error) => console.error('Failed to fetch bookings:', error));
+  }, [fetchTickets]);
+
+  const openTicket = async (id: number) => {
+    try {
+      const res = await api.getMyTicket(id);
+      setSelected(res.data);
+      setComment('');
+    } catch (error) {
+      console.error('Failed to fetch ticket:', error);
+    }
+  };
+
+  const handleCreate = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setIsSaving(true);
+    try {
+      const data = new FormData();
+      Object.entries(form).forEach(([key, value]) => data.append(key, value));
+      photos.forEach((photo) => data.append('photos', photo));
+      await api.createTicket(data);
+      toast.success(t('submitted'));
+      setIsCreating(false);
+      setForm((prev) => ({ ...prev, title: '', description: '', category: 'lainnya', priority: 'medium' }));
+      setPhotos([]);
+      void fetchTickets();
+    } catch (error) {
+      toast.error(error instanceof Error ? error.message : t('createFailed'));
+    } finally {
+      setIsSaving(false);
+    }
+  };
+
+  const handleComment = async () => {
+    if (!selected || !comment.trim()) return;
+    setIsSaving(true);
+    try {
+      await api.addTicketComment(selected.id, comment);
+      await openTicket(selected.id);
+    } catch (error) {
+      toast.error(error instanceof Error ? error.message : t('commentFailed'));
+    } finally {
+      setIsSaving(false);
+    }
+  };
+
+  if (selected) {
+    return (
+      <div className="max-w-3xl mx-auto px-4 py-10 space-y-6">
+        <Button variant="ghost" onClick={() => setSelected(null)} className="rounded-xl">
+          <ArrowLeft className="w-4 h-4 mr-2" />
+          {t('back')}
+        </Button>
+        <div className="bg-white dark:bg-slate-900 p-6 rounded-3xl shadow-xl border border-slate-100 dark:border-slate-800 space-y-5">
+          <div>
+            <h2 className="text-2xl font-bold text-slate-900 dark:text-white">#{selected.id} {selected.title}</h2>
+            <div className="flex flex-wrap items-center gap-2 mt-2 text-xs">
+              <span className={`px-2 py-0.5 font-bold rounded-lg border uppercase ${ticketStatusStyles[selected.status]}`}>{t(`status.${selected.status}`)}</span>
+              <span className={`uppercase ${ticketPriorityStyles[selected.priority]}`}>{t(`priority.${selected.priority}`)}</span>
+              <span className="text-slate-500">{t(`category.${selected.category}`)} · {selected.kamar?.nomor_kamar}</span>
+            </div>
+          </div>
+          <p className="text-slate-700 dark:text-slate-300 whitespace-pre-line">{selected.description}</p>
+          {selected.photos?.length > 0 && (
+            <div className="grid grid-cols-3 gap-2">
+              {selected.photos.map((photo) => (
+                // eslint-disable-next-line @next/next/no-img-element
+                <img key={photo.id} src={getImageUrl(photo.url)} alt={selected.title} className="w-full h-28 object-cover rounded-xl" />
+              ))}
+            </div>
+          )}
+
+          <div className="space-y-3">
+            <h3 className="font-semibold text-slate-900 dark:text-white">{t('comments')}</h3>
+            {(selected.comments || []).length === 0 && <p className="text-sm text-slate-500">{t('noComments')}</p>}
+            {(selected.comments || []).map((c) => (
+              <div key={c.id} className={`p-3 rounded-xl text-sm ${c.is_admin ? 'bg-amber-500/10 mr-8' : 'bg-slate-100 dark:bg-slate-800 ml-8'}`}>
+                <p className="text-[10px] text-slate-500 mb-1">
+                  {c.is_admin ? t('admin') : c.author_name} · {new Date(c.created_at).toLocaleString('id-ID', { day: 'numeric', month: 'short', hour: '2-digit', minute: '2-digit' })}
+                </p>
+                <p className="whitespace-pre-line text-slate-800 dark:text-slate-200">{c.message}</p>
+              </div>
+            ))}
+          </div>
+
+          {selected.status !== 'closed' && (
+            <div className="space-y-2">
+              <Textarea value={comment} onChange={(e) => setComment(e.target.value)} placeholder={t('commentPlaceholder')} className="rounded-xl" />
+              <div className="flex justify-end">
+                <Button onClick={handleComment} disabled={isSaving || !comment.trim()} className="bg-stone-900 hover:bg-stone-800 text-white rounded-xl">
+                  {isSaving ? <Loader2 className="w-4 h-4 animate-spin mr-2" /> : <Send className="w-4 h-4 mr-2" />}
+                  {t('sendComment')}
+                </Button>
+              </div>
+            </div>
+          )}
+        </div>
+      </div>
+    );
+  }
+
+  return (
+    <div className="max-w-4xl mx-auto px-4 py-10 space-y-6">
+      <div className="flex flex-col md:flex-row md:items-center justify-between gap-4">
+        <div>
+          <h2 className="text-3xl font-bold text-slate-900 dark:text-white">{t('title')}</h2>
+          <p className="text-slate-600 dark:text-slate-400">{t('subtitle')}</p>
+        </div>
+        {rooms.length > 0 && !isCreating && (
+          <Button onClick={() => setIsCreating(true)} className="bg-stone-900 hover:bg-stone-800 text-white rounded-xl">
+            <Plus className="w-4 h-4 mr-2" />
+            {t('newTicket')}
+          </Button>
+        )}
+      </div>
+
+      {isCreating && (
+        <motion.form
+          initial={{ opacity: 0, y: -10 }}
+          animate={{ opacity: 1, y: 0 }}
+          onSubmit={handleCreate}
+          className="bg-white dark:bg-slate-900 p-6 rounded-3xl shadow-xl border border-slate-100 dark:border-slate-800 space-y-4"
+        >
+          <div className="grid grid-cols-1 md:grid-cols-3 gap-4">
+            <label className="space-y-1 text-sm text-slate-600 dark:text-slate-400">
+              <span>{t('room')}</span>
+              <select value={form.kamar_id} onChange={(e) => setForm({ ...form, kamar_id: e.target.value })} className={selectClass}>
+                {rooms.map((b) => (
+                  <option key={b.id} value={b.kamar?.id}>{b.kamar?.nomor_kamar}</option>
+                ))}
+              </select>
+            </label>
+            <label className="space-y-1 text-sm text-slate-600 dark:text-slate-400">
+              <span>{t('categoryLabel')}</span>
+              <select value={form.category} onChange={(e) => setForm({ ...form, category: e.target.value as TicketCategory })} className={selectClass}>
+                {categories.map((c) => <option key={c} value={c}>{t(`category.${c}`)}</option>)}
+              </select>
+            </label>
+            <label className="space-y-1 text-sm text-slate-600 dark:text-slate-400">
+              <span>{t('priorityLabel')}</span>
+              <select value={form.priority} onChange={(e) => setForm({ ...form, priority: e.target.value as TicketPriority })} className={selectClass}>
+                {priorities.map((p) => <option key={p} value={p}>{t(`priority.${p}`)}</option>)}
+              </select>
+            </label>
+          </div>
+          <Input required maxLength={150} value={form.title} onChange={(e) => setForm({ ...form, title: e.target.value })} placeholder={t('titlePlaceholder')} className="rounded-xl" />
+          <Textarea required maxLength={2000} value={form.description} onChange={(e) => setForm({ ...form, description: e.target.value })} placeholder={t('descriptionPlaceholder')} className="rounded-xl min-h-28" />
+          <label className="block space-y-1 text-sm text-slate-600 dark:text-slate-400">
+            <span>{t('photos', { max: MAX_PHOTOS })}</span>
+            <input
+              type="file"
+              accept="image/*"
+              multiple
+              onChange={(e) => setPhotos(Array.from(e.target.files || []).slice(0, MAX_PHOTOS))}
+              className="block w-full text-sm"
+            />
+          </label>
+          <div className="flex justify-end gap-2">
+            <Button type="button" variant="ghost" onClick={() => setIsCreating(false)} className="rounded-xl">{t('cancel')}</Button>
+            <Button type="submit" disabled={isSaving} className="bg-stone-900 hover:bg-stone-800 text-white rounded-xl">
+              {isSaving && <Loader2 className="w-4 h-4 animate-spin mr-2" />}
+              {t('submit')}
+            </Button>
+          </div>
+        </motion.form>
+      )}
+
+      {isLoading ? (
+        <div className="py-20 flex justify-center">
+          <Loader2 className="w-8 h-8 animate-spin text-stone-500" />
+        </div>
+      ) : tickets.length === 0 ? (
+        <div className="py-20 text-center bg-white dark:bg-slate-900 rounded-3xl border border-slate-100 dark:border-slate-800">
+          <Inbox className="w-12 h-12 text-slate-400 mx-auto mb-4" />
+          <p className="text-slate-500">{rooms.length > 0 ? t('emptyTenant') : t('noActiveRoom')}</p>
+        </div>
+      ) : (
+        <div className="space-y-3">
+          {tickets.map((ticket) => (
+            <button
+              key={ticket.id}
+              onClick={() => openTicket(ticket.id)}
+              className="w-full text-left bg-white dark:bg-slate-900 p-5 rounded-2xl border border-slate-100 dark:border-slate-800 hover:shadow-lg transition-shadow flex items-center gap-4"
+            >
+              <div className="p-3 bg-amber-500/10 rounded-xl">
+                <Wrench className="w-5 h-5 text-amber-500" />
+              </div>
+              <div className="flex-1 min-w-0">
+                <p className="font-semibold text-slate-900 dark:text-white truncate">#{ticket.id} {ticket.title}</p>
+                <p className="text-xs text-slate-500">
+                  {t(`category.${ticket.category}`)} · {ticket.kamar?.nomor_kamar} · {new Date(ticket.created_at).toLocaleDateString('id-ID', { day: 'numeric', month: 'short', year: 'numeric' })}
+                </p>
+              </div>
+              <span className={`px-2 py-0.5 text-[10px] font-bold rounded-lg border uppercase ${ticketStatusStyles[ticket.status]}`}>{t(`status.${ticket.status}`)}</span>
+            </button>
+          ))}
+        </div>
+      )}
+    </div>
+  );
+}
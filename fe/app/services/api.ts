@@ -24,11 +24,64 @@ export interface PaginatedResponse<T> {
   meta: Pagination;
 }
 
+export type FacilityCategory = 'room' | 'bathroom' | 'building' | 'service' | 'other';
+
+export interface Facility {
+  id: number;
+  name: string;
+  icon_key: string;
+  category: FacilityCategory;
+  created_at?: string;
+  updated_at?: string;
+}
+
+export interface RoomTypeImage {
+  id: number;
+  room_type_id: number;
+  image_url: string;
+}
+
+export interface RoomType {
+  id: number;
+  name: string;
+  harga_per_bulan: number;
+  size: string;
+  capacity: number;
+  description: string;
+  images?: RoomTypeImage[];
+  created_at?: string;
+  updated_at?: string;
+}
+
+export type RoomTypeInput = Pick<RoomType, 'name' | 'harga_per_bulan' | 'size' | 'capacity' | 'description'>;
+
+// URL hasil pipeline gambar backend; medium/thumbnail sama dengan original jika varian tidak ada
+export interface ImageVariants {
+  original: string;
+  medium: string;
+  thumbnail: string;
+}
+
+export interface KamarImage {
+  id: number;
+  kamar_id: number;
+  image_url: string;
+  sort_order: number;
+}
+
 export interface Room {
   id: number;
   nomor_kamar: string;
-  tipe_kamar: string;
+  tipe_kamar: string; // nama room_type jika room_type_id diisi
+  room_type_id?: number | null;
+  room_type?: RoomType;
+  // true = nilai milik kamar sendiri, false = mengikuti room_type
+  override_price?: boolean;
+  override_size?: boolean;
+  override_capacity?: boolean;
+  override_description?: boolean;
   fasilitas: string | string[]; // Supports both raw string or parsed array
+  facilities?: Facility[]; // katalog; fasilitas di atas berisi nama-namanya
   harga_per_bulan: number;
   status: 'Tersedia' | 'Terisi' | 'Perbaikan' | 'Booked';
   capacity: number;
@@ -45,7 +98,7 @@ export interface Room {
     nama_kategori: string;
   };
   Gallery?: { image_url: string }[];
-  Images?: { id: number; kamar_id: number; image_url: string }[];
+  Images?: KamarImage[]; // urut menurut sort_order; yang pertama = image_url (cover)
   // derived fields for UI
   rating?: number;
   reviews?: number;
@@ -108,6 +161,82 @@ export interface Payment {
   jumlah_dp: number;
   tanggal_jatuh_tempo?: string;
   created_at?: string;
+  // Rincian invoice: subtotal - diskon = total tagihan (jumlah_bayar bisa berupa DP-nya)
+  subtotal?: number;
+  diskon?: number;
+  jumlah_bulan?: number;
+}
+
+export type PricingRuleType = 'long_stay' | 'promotion' | 'voucher';
+
+export interface PricingRule {
+  id: number;
+  name: string;
+  type: PricingRuleType;
+  code?: string;
+  discount_percent: number;
+  discount_amount: number;
+  min_months: number;
+  starts_at: string | null;
+  ends_at: string | null;
+  room_type_id: number | null;
+  max_uses: number;
+  used_count: number;
+  active: boolean;
+}
+
+export interface PricingRuleInput {
+  name: string;
+  type: PricingRuleType;
+  code?: string;
+  discount_percent: number;
+  discount_amount: number;
+  min_months: number;
+  starts_at?: string; // YYYY-MM-DD
+  ends_at?: string;
+  room_type_id: number | null;
+  max_uses: number;
+  active: boolean;
+}
+
+export interface BookingDiscount {
+  id?: number;
+  pembayaran_id?: number | null;
+  pricing_rule_id: number;
+  name: string;
+  type: PricingRuleType;
+  code?: string;
+  amount: number;
+}
+
+export type PriceChangeSource = 'manual' | 'scheduled';
+
+export interface KamarPriceHistory {
+  id: number;
+  kamar_id: number;
+  harga_sebelum: number;
+  harga_per_bulan: number;
+  effective_from: string;
+  source: PriceChangeSource;
+  note: string;
+  notice_sent_at: string | null;
+  applied_at: string | null;
+  created_at: string;
+}
+
+export interface PriceChangeInput {
+  harga_per_bulan: number;
+  effective_from: string; // YYYY-MM-DD
+  note?: string;
+}
+
+export interface PriceQuote {
+  harga_per_bulan: number;
+  months: number;
+  subtotal: number;
+  diskon: number;
+  total: number;
+  discounts: BookingDiscount[];
 }
 
 export interface PaymentReminder {
@@ -142,6 +271,11 @@ export interface Booking {
   monthlyRent?: number;
   status_bayar?: string; 
   total_bayar?: number; 
+  // Snapshot harga saat dipesan (0 untuk pemesanan lama)
+  subtotal?: number;
+  diskon?: number;
+  total_harga?: number;
+  discounts?: BookingDiscount[];
 }
 
 export interface DashboardStats {
@@ -154,7 +288,7 @@ export interface DashboardStats {
   rejected_payments: number;
   potential_revenue: number;
   monthly_trend: { month: string; revenue: number }[];
-  type_breakdown: { type: string; revenue: number; count: number; occupied: number }[];
+  type_breakdown: { type_id: number | null; type: string; revenue: number; count: number; occupied: number }[];
   demographics: { name: string; value: number; color: string }[];
   recent_checkouts: {
     room_name: string;
@@ -162,6 +296,136 @@ export interface DashboardStats {
     checkout_date: string;
     reason: string;
   }[];
+  tickets: TicketStats;
+}
+
+export interface TicketStats {
+  open: number;
+  in_progress: number;
+  resolved: number;
+  closed: number;
+  urgent: number;
+  avg_resolution_hours: number;
+}
+
+export type TicketStatus = 'open' | 'in_progress' | 'resolved' | 'closed';
+export type TicketCategory = 'listrik' | 'air' | 'ac' | 'furnitur' | 'kebersihan' | 'internet' | 'lainnya';
+export type TicketPriority = 'low' | 'medium' | 'high' | 'urgent';
+
+export interface MaintenanceTicketComment {
+  id: number;
+  ticket_id: number;
+  user_id: number;
+  author_name: string;
+  is_admin: boolean;
+  message: string;
+  created_at: string;
+}
+
+export interface MaintenanceTicket {
+  id: number;
+  penyewa_id: number;
+  penyewa?: Tenant;
+  kamar_id: number;
+  kamar?: Room;
+  title: string;
+  description: string;
+  category: TicketCategory;
+  priority: TicketPriority;
+  status: TicketStatus;
+  assigned_to: number | null;
+  assignee?: User;
+  photos: { id: number; ticket_id: number; url: string; created_at: string }[];
+  comments?: MaintenanceTicketComment[];
+  resolved_at: string | null;
+  closed_at: string | null;
+  created_at: string;
+  updated_at: string;
+}
+
+/** Field anti-spam yang dikirim bersama body form publik (contact, register, forgot-password) */
+export interface SpamFields {
+  captcha_token: string;
+  website: string; // honeypot, harus kosong
+  form_token: string; // dari GET /forms/token saat form ditampilkan, waktu mulainya ditandatangani server
+}
+
+export type RoomSort = 'price_asc' | 'price_desc' | 'rating' | 'newest';
+
+/** Query GET /kamar; field kosong diabaikan */
+export interface RoomSearchParams {
+  tipe_kamar?: string;
+  room_type_id?: number;
+  status?: string;
+  min_price?: number;
+  max_price?: number;
+  floor?: number;
+  capacity?: number; // kapasitas minimal
+  fasilitas?: string[];
+  facility_ids?: number[]; // kamar harus punya semuanya
+  available_from?: string; // YYYY-MM-DD, diisi bersama available_to
+  available_to?: string;
+  sort?: RoomSort;
+  page?: number;
+  limit?: number;
+}
+
+export type SpamForm = 'contact' | 'register' | 'forgot_password';
+export type SpamReason = 'honeypot' | 'too_fast' | 'too_many_links' | 'blocked_word' | 'challenge_failed';
+
+export interface SpamSettings {
+  id: number;
+  min_submit_seconds: number;
+  max_links: number;
+  blocked_words: string; // satu kata/frasa per baris
+  updated_by: number;
+  updated_at: string;
+}
+
+export interface SpamRejection {
+  id: number;
+  form: SpamForm;
+  reason: SpamReason;
+  detail: string;
+  ip_address: string;
+  user_agent: string;
+  email: string;
+  content: string;
+  created_at: string;
+}
+
+export interface SpamRejectionCount {
+  form: SpamForm;
+  reason: SpamReason;
+  count: number;
+}
+
+export type ContactStatus = 'new' | 'read' | 'replied' | 'spam';
+
+export interface ContactReply {
+  id: number;
+  contact_message_id: number;
+  user_id: number;
+  author_name: string;
+  subject: string;
+  message: string;
+  created_at: string;
+}
+
+export interface ContactMessage {
+  id: number;
+  name: string;
+  email: string;
+  message: string;
+  status: ContactStatus;
+  assigned_to: number | null;
+  assignee?: User;
+  replies?: ContactReply[];
+  ip_address: string;
+  read_at: string | null;
+  replied_at: string | null;
+  created_at: string;
+  updated_at: string;
 }
 
 export interface LoginResponse {
@@ -169,12 +433,67 @@ export interface LoginResponse {
     user: User;
     penyewa?: Tenant;
     is_google_user?: boolean;
+    csrf_token?: string;
 }
 
 export interface MessageResponse {
     message: string;
 }
 
+export interface AppNotification {
+    id: number;
+    user_id: number;
+    event_id: string;
+    type: string;
+    title: string;
+    message: string;
+    channels: string;
+    data: string; // JSON payload of the event
+    read_at: string | null;
+    created_at: string;
+}
+
+export interface NotificationDelivery {
+    id: number;
+    user_id: number;
+    event_id: string;
+    event_type: string;
+    channel: 'email' | 'whatsapp' | 'sms';
+    recipient: string;
+    recipient_name: string;
+    template: string;
+    body: string;
+    sms_body: string;
+    sent_via: '' | 'email' | 'whatsapp' | 'sms';
+    status: 'pending' | 'sent' | 'failed';
+    attempts: number;
+    max_attempts: number;
+    next_retry_at: string;
+    last_error: string;
+    sent_at: string | null;
+    provider_message_id: string;
+    delivery_status: '' | 'sent' | 'delivered' | 'read' | 'failed';
+    delivered_at: string | null;
+    read_at: string | null;
+    created_at: string;
+    updated_at: string;
+}
+
+export interface NotificationPreferences {
+    categories: { category: 'payment' | 'bill' | 'booking' | 'maintenance'; email: boolean; whatsapp: boolean; in_app: boolean }[];
+    quiet_hours: { enabled: boolean; start: string; end: string };
+    language: 'id' | 'en';
+}
+
+export interface MessageTemplate {
+    name: string;
+    channel: 'email' | 'whatsapp' | 'sms';
+    language: 'id' | 'en';
+    subject: string;
+    body: string;
+    customized: boolean;
+}
+
 interface ApiError extends Error {
   status: number;
   errors?: string[];
@@ -221,6 +540,17 @@ const safeJson = async (res: Response) => {
   }
 };
 
+// CSRF token (double-submit). Issued by the backend on login and /auth/refresh,
+// sent back as X-CSRF-Token on every POST/PUT/DELETE.
+const CSRF_STORAGE_KEY = 'csrf_token';
+let csrfToken: string | null = typeof window !== 'undefined' ? sessionStorage.getItem(CSRF_STORAGE_KEY) : null;
+
+const rememberCsrfToken = (token?: string) => {
+  if (!token) return;
+  csrfToken = token;
+  if (typeof window !== 'undefined') sessionStorage.setItem(CSRF_STORAGE_KEY, token);
+};
+
 // Auto-refresh token helper
 const refreshAccessToken = async (): Promise<boolean> => {
   try {
@@ -228,6 +558,10 @@ const refreshAccessToken = async (): Promise<boolean> => {
       method: 'POST',
       credentials: 'include',
     });
+    if (res.ok) {
+      const data = await res.json().catch(() => ({}));
+      rememberCsrfToken(data.csrf_token);
+    }
     return res.ok;
   } catch {
     return false;
@@ -235,10 +569,15 @@ const refreshAccessToken = async (): Promise<boolean> => {
 };
 
 // === Internal Helper ===
-const apiCall = async <T>(method: string, endpoint: string, body?: unknown): Promise<T> => {
+const apiCall = async <T>(method: string, endpoint: string, body?: unknown, retried = false): Promise<T> => {
+  const headers: Record<string, string> = { 'Content-Type': 'application/json' };
+  if (method !== 'GET' && csrfToken) {
+    headers['X-CSRF-Token'] = csrfToken;
+  }
+
   const config: RequestInit = {
     method,
-    headers: { 'Content-Type': 'application/json' },
+    headers,
     credentials: 'include', // IMPORTANT: Send HttpOnly cookies with requests
   };
 
@@ -260,12 +599,20 @@ const apiCall = async <T>(method: string, endpoint: string, body?: unknown): Pro
     throw new ApiErrorClass('Koneksi ke server gagal. Harap periksa jaringan Anda atau hubungi admin.', 0);
   }
 
+  // Missing/stale CSRF token (e.g. new tab): refresh once to get a fresh one, then retry
+  if (res.status === 403 && method !== 'GET' && !retried) {
+    const refreshed = await refreshAccessToken();
+    if (refreshed) {
+      return apiCall<T>(method, endpoint, body, true);
+    }
+  }
+
   if (res.status === 401 && endpoint !== '/auth/refresh' && endpoint !== '/auth/login') {
     // Try to refresh token
     const refreshed = await refreshAccessToken();
     if (refreshed) {
       // Retry original request
-      return apiCall<T>(method, endpoint, body);
+      return apiCall<T>(method, endpoint, body, retried);
     }
     // Refresh failed, redirect to login
     if (typeof window !== 'undefined') {
@@ -282,6 +629,7 @@ export const api = {
   // --- AUTH ---
   login: async (credentials: { username: string; password: string }) => {
     const data = await apiCall<LoginResponse>('POST', '/auth/login', credentials);
+    rememberCsrfToken(data.csrf_token);
     // Store user data in localStorage and non-HttpOnly cookie for middleware
     if (data.user) {
       localStorage.setItem('user', JSON.stringify(data.user));
@@ -295,6 +643,7 @@ export const api = {
     const data = await apiCall<LoginResponse>('POST', '/auth/google-login', {
       id_token: idToken,
     });
+    rememberCsrfToken(data.csrf_token);
     // Store user data in localStorage and non-HttpOnly cookie for middleware
     if (data.user) {
       localStorage.setItem('user', JSON.stringify(data.user));
@@ -303,14 +652,14 @@ export const api = {
     return data;
   },
 
-  register: async (userData: Partial<Tenant> & { password?: string; username?: string }) => {
+  register: async (userData: Partial<Tenant> & { password?: string; username?: string } & Partial<SpamFields>) => {
     // userData already includes birthdate from UserRegister.tsx
     // Returns created User object or message? Typically user object.
     return apiCall<User>('POST', '/auth/register', userData);
   },
   
-  forgotPassword: async (email: string) => {
-    return apiCall<MessageResponse>('POST', '/auth/forgot-password', { email });
+  forgotPassword: async (email: string, spam?: SpamFields) => {
+    return apiCall<MessageResponse>('POST', '/auth/forgot-password', { email, ...spam });
   },
 
   resetPassword: async (token: string, newPassword: string) => {
@@ -350,9 +699,23 @@ export const api = {
   },
 
   // --- KAMAR / ROOMS ---
-  getRooms: async () => {
-    // Backend returns raw array []Kamar
-    return apiCall<Room[]>('GET', '/kamar');
+  searchRooms: async (params: RoomSearchParams = {}) => {
+    const query = new URLSearchParams();
+    Object.entries(params).forEach(([key, value]) => {
+      if (value === undefined || value === '' || (Array.isArray(value) && value.length === 0)) return;
+      query.set(key, Array.isArray(value) ? value.join(',') : String(value));
+    });
+    return apiCall<PaginatedResponse<Room[]>>('GET', `/kamar?${query.toString()}`);
+  },
+
+  getRooms: async (): Promise<Room[]> => {
+    // Semua kamar: /kamar dipaginasi (maks. 100 per halaman), ambil sampai halaman terakhir
+    const rooms: Room[] = [];
+    for (let page = 1; ; page++) {
+      const res = await apiCall<PaginatedResponse<Room[]>>('GET', `/kamar?page=${page}&limit=100`);
+      rooms.push(...(res.data || []));
+      if (page >= res.meta.total_pages) return rooms;
+    }
   },
 
   getRoomById: async (id: string) => {
@@ -372,6 +735,103 @@ export const api = {
     return apiCall<MessageResponse>('DELETE', `/kamar/${id}`);
   },
 
+  uploadRoomImages: async (id: number, files: File[]) => {
+    const formData = new FormData();
+    files.forEach((file) => formData.append('images', file));
+    return apiCall<MessageResponse & { data: Room; variants: ImageVariants[] }>('POST', `/kamar/${id}/images`, formData);
+  },
+
+  deleteRoomImage: async (id: number, imageId: number) => {
+    return apiCall<MessageResponse>('DELETE', `/kamar/${id}/images/${imageId}`);
+  },
+
+  reorderRoomImages: async (id: number, imageIds: number[]) => {
+    return apiCall<MessageResponse & { data: Room }>('PUT', `/kamar/${id}/images/order`, { image_ids: imageIds });
+  },
+
+  setRoomCoverImage: async (id: number, imageId: number) => {
+    return apiCall<MessageResponse & { data: Room }>('PUT', `/kamar/${id}/images/${imageId}/cover`);
+  },
+
+  // --- FACILITIES ---
+  getFacilities: async () => {
+    return apiCall<{ data: Facility[]; categories: FacilityCategory[] }>('GET', '/facilities');
+  },
+
+  createFacility: async (facility: Pick<Facility, 'name' | 'icon_key' | 'category'>) => {
+    return apiCall<MessageResponse & { data: Facility }>('POST', '/facilities', facility);
+  },
+
+  updateFacility: async (id: number, facility: Pick<Facility, 'name' | 'icon_key' | 'category'>) => {
+    return apiCall<MessageResponse & { data: Facility }>('PUT', `/facilities/${id}`, facility);
+  },
+
+  deleteFacility: async (id: number) => {
+    return apiCall<MessageResponse>('DELETE', `/facilities/${id}`);
+  },
+
+  // --- ROOM TYPES ---
+  getRoomTypes: async () => {
+    return apiCall<{ data: RoomType[] }>('GET', '/room-types');
+  },
+
+  createRoomType: async (roomType: RoomTypeInput) => {
+    return apiCall<MessageResponse & { data: RoomType }>('POST', '/room-types', roomType);
+  },
+
+  updateRoomType: async (id: number, roomType: RoomTypeInput) => {
+    return apiCall<MessageResponse & { data: RoomType }>('PUT', `/room-types/${id}`, roomType);
+  },
+
+  deleteRoomType: async (id: number) => {
+    return apiCall<MessageResponse>('DELETE', `/room-types/${id}`);
+  },
+
+  uploadRoomTypeImages: async (id: number, files: File[]) => {
+    const formData = new FormData();
+    files.forEach((file) => formData.append('images', file));
+    return apiCall<MessageResponse & { data: RoomType; variants: ImageVariants[] }>('POST', `/room-types/${id}/images`, formData);
+  },
+
+  deleteRoomTypeImage: async (id: number, imageId: number) => {
+    return apiCall<MessageResponse>('DELETE', `/room-types/${id}/images/${imageId}`);
+  },
+
+  // --- PRICING ---
+  getPriceQuote: async (kamarId: number, tanggalMulai: string, durasiSewa: number, voucherCode?: string) => {
+    const params = new URLSearchParams({ kamar_id: String(kamarId), tanggal_mulai: tanggalMulai, durasi_sewa: String(durasiSewa) });
+    if (voucherCode) params.set('voucher_code', voucherCode);
+    return apiCall<PriceQuote>('GET', `/pricing/quote?${params.toString()}`);
+  },
+
+  getPricingRules: async () => {
+    return apiCall<{ data: PricingRule[] }>('GET', '/pricing-rules');
+  },
+
+  createPricingRule: async (rule: PricingRuleInput) => {
+    return apiCall<MessageResponse & { data: PricingRule }>('POST', '/pricing-rules', rule);
+  },
+
+  updatePricingRule: async (id: number, rule: PricingRuleInput) => {
+    return apiCall<MessageResponse & { data: PricingRule }>('PUT', `/pricing-rules/${id}`, rule);
+  },
+
+  deletePricingRule: async (id: number) => {
+    return apiCall<MessageResponse>('DELETE', `/pricing-rules/${id}`);
+  },
+
+  getPriceHistory: async (kamarId: number) => {
+    return apiCall<{ data: KamarPriceHistory[]; notice_days: number }>('GET', `/kamar/${kamarId}/price-history`);
+  },
+
+  schedulePriceChange: async (kamarId: number, change: PriceChangeInput) => {
+    return apiCall<MessageResponse & { data: KamarPriceHistory }>('POST', `/kamar/${kamarId}/price-changes`, change);
+  },
+
+  cancelPriceChange: async (kamarId: number, changeId: number) => {
+    return apiCall<MessageResponse>('DELETE', `/kamar/${kamarId}/price-changes/${changeId}`);
+  },
+
   // --- BOOKINGS & REVIEWS ---
   getMyBookings: async () => {
     return apiCall<Booking[]>('GET', '/bookings');
@@ -381,6 +841,11 @@ export const api = {
     return apiCall<Booking>('POST', '/bookings', bookingData);
   },
 
+  // Pesan per tipe: backend memilihkan kamar kosong (409 jika tipe penuh di tanggal tersebut)
+  createBookingByType: async (roomTypeId: number, tanggalMulai: string, durasiSewa: number) => {
+    return apiCall<Booking>('POST', '/bookings', { room_type_id: roomTypeId, tanggal_mulai: tanggalMulai, durasi_sewa: durasiSewa });
+  },
+
   createBookingWithProof: async (formData: FormData) => {
     return apiCall<Booking>('POST', '/bookings/with-proof', formData);
   },
@@ -389,19 +854,24 @@ export const api = {
     return apiCall<MessageResponse>('POST', `/bookings/${id}/cancel`);
   },
 
-  extendBooking: async (id: string, months: number, paymentMethod: string) => {
-    return apiCall<Payment>('POST', `/bookings/${id}/extend`, { months, payment_method: paymentMethod });
+  extendBooking: async (id: string, months: number, paymentMethod: string, voucherCode?: string) => {
+    return apiCall<Payment>('POST', `/bookings/${id}/extend`, { months, payment_method: paymentMethod, voucher_code: voucherCode || undefined });
   },
 
   // --- PAYMENTS (Manual Transfer) ---
-  createPayment: async (data: { pemesanan_id: number; payment_type: 'full' | 'dp' }) => {
+  createPayment: async (data: { pemesanan_id: number; payment_type: 'full' | 'dp'; voucher_code?: string }) => {
     return apiCall<{ message: string; payment: Payment }>('POST', '/payments', data);
   },
 
   uploadPaymentProof: async (paymentId: number, file: File) => {
     const formData = new FormData();
     formData.append('proof', file);
-    return apiCall<{ message: string; url: string }>('POST', `/payments/${paymentId}/proof`, formData);
+    return apiCall<{ message: string; url: string; variants: ImageVariants }>('POST', `/payments/${paymentId}/proof`, formData);
+  },
+
+  // Bukti transfer disimpan privat; URL-nya hanya berlaku sebentar (expires_at)
+  getPaymentProof: async (paymentId: number | string) => {
+    return apiCall<{ url: string; variants: ImageVariants; expires_at?: string }>('GET', `/payments/${paymentId}/proof`);
   },
 
   getPaymentReminders: async () => {
@@ -473,8 +943,11 @@ export const api = {
   },
 
   // --- OTHERS ---
-  sendContactForm: async (data: { name: string; email: string; message: string }) => {
-    // Check contact_handler.go if needed, assume message
+  getFormToken: async () => {
+    return apiCall<{ form_token: string }>('GET', '/forms/token');
+  },
+
+  sendContactForm: async (data: { name: string; email: string; message: string } & Partial<SpamFields>) => {
     return apiCall<MessageResponse>('POST', '/contact', data);
   },
 
@@ -502,6 +975,148 @@ export const api = {
     return apiCall<{ active_tenants: number; average_rating: number; total_reviews: number }>('GET', '/public-stats');
   },
 
+  // --- NOTIFICATIONS ---
+  getNotifications: async (page = 1, limit = 10, unreadOnly = false) => {
+    const query = `?page=${page}&limit=${limit}${unreadOnly ? '&unread=true' : ''}`;
+    return apiCall<PaginatedResponse<AppNotification[]> & { unread_count: number }>('GET', `/notifications${query}`);
+  },
+
+  getUnreadNotificationCount: async () => {
+    return apiCall<{ unread_count: number }>('GET', '/notifications/unread-count');
+  },
+
+  markNotificationRead: async (id: number) => {
+    return apiCall<MessageResponse>('PUT', `/notifications/${id}/read`);
+  },
+
+  markAllNotificationsRead: async () => {
+    return apiCall<MessageResponse & { updated: number }>('PUT', '/notifications/read-all');
+  },
+
+  getNotificationPreferences: async () => {
+    return apiCall<NotificationPreferences>('GET', '/profile/notification-preferences');
+  },
+
+  updateNotificationPreferences: async (prefs: NotificationPreferences) => {
+    return apiCall<NotificationPreferences>('PUT', '/profile/notification-preferences', prefs);
+  },
+
+  // --- NOTIFICATION OUTBOX (ADMIN) ---
+  getNotificationDeliveries: async (status: 'failed' | 'pending' | 'sent' | 'all' = 'failed', page = 1, limit = 20) => {
+    return apiCall<PaginatedResponse<NotificationDelivery[]>>('GET', `/notification-deliveries?status=${status}&page=${page}&limit=${limit}`);
+  },
+
+  resendNotificationDelivery: async (id: number) => {
+    return apiCall<MessageResponse & { data: NotificationDelivery }>('POST', `/notification-deliveries/${id}/resend`);
+  },
+
+  // --- MAINTENANCE TICKETS (TENANT) ---
+  getMyTickets: async () => {
+    return apiCall<{ data: MaintenanceTicket[] }>('GET', '/tickets');
+  },
+
+  getMyTicket: async (id: number) => {
+    return apiCall<{ data: MaintenanceTicket }>('GET', `/tickets/${id}`);
+  },
+
+  // formData: kamar_id, title, description, category, priority, photos (multiple)
+  createTicket: async (formData: FormData) => {
+    return apiCall<MessageResponse & { data: MaintenanceTicket }>('POST', '/tickets', formData);
+  },
+
+  addTicketComment: async (id: number, message: string) => {
+    return apiCall<{ data: MaintenanceTicketComment }>('POST', `/tickets/${id}/comments`, { message });
+  },
+
+  // --- MAINTENANCE TICKETS (ADMIN) ---
+  getMaintenanceTickets: async (filter: { status?: TicketStatus | ''; category?: TicketCategory | ''; priority?: TicketPriority | ''; assigned_to?: 'me' | number } = {}, page = 1, limit = 20) => {
+    const params = new URLSearchParams({ page: String(page), limit: String(limit) });
+    Object.entries(filter).forEach(([key, value]) => {
+      if (value) params.set(key, String(value));
+    });
+    return apiCall<PaginatedResponse<MaintenanceTicket[]>>('GET', `/maintenance-tickets?${params.toString()}`);
+  },
+
+  getMaintenanceTicket: async (id: number) => {
+    return apiCall<{ data: MaintenanceTicket }>('GET', `/maintenance-tickets/${id}`);
+  },
+
+  assignMaintenanceTicket: async (id: number, assigneeId?: number) => {
+    return apiCall<MessageResponse & { data: MaintenanceTicket }>('PUT', `/maintenance-tickets/${id}/assign`, assigneeId ? { assignee_id: assigneeId } : {});
+  },
+
+  updateMaintenanceTicketStatus: async (id: number, status: TicketStatus, note = '') => {
+    return apiCall<MessageResponse & { data: MaintenanceTicket }>('PUT', `/maintenance-tickets/${id}/status`, { status, note });
+  },
+
+  addMaintenanceTicketComment: async (id: number, message: string) => {
+    return apiCall<{ data: MaintenanceTicketComment }>('POST', `/maintenance-tickets/${id}/comments`, { message });
+  },
+
+  // --- CONTACT INBOX (ADMIN) ---
+  getContactMessages: async (status: ContactStatus | '' = '', page = 1, limit = 20) => {
+    const params = new URLSearchParams({ page: String(page), limit: String(limit) });
+    if (status) params.set('status', status);
+    return apiCall<PaginatedResponse<ContactMessage[]>>('GET', `/contact-messages?${params.toString()}`);
+  },
+
+  getContactUnreadCount: async () => {
+    return apiCall<{ count: number }>('GET', '/contact-messages/unread-count');
+  },
+
+  getContactMessage: async (id: number) => {
+    return apiCall<{ data: ContactMessage }>('GET', `/contact-messages/${id}`);
+  },
+
+  assignContactMessage: async (id: number, assigneeId?: number) => {
+    return apiCall<MessageResponse & { data: ContactMessage }>('PUT', `/contact-messages/${id}/assign`, assigneeId ? { assignee_id: assigneeId } : {});
+  },
+
+  replyContactMessage: async (id: number, subject: string, message: string) => {
+    return apiCall<MessageResponse & { data: ContactReply }>('POST', `/contact-messages/${id}/reply`, { subject, message });
+  },
+
+  markContactSpam: async (id: number, spam: boolean) => {
+    return apiCall<{ data: ContactMessage }>('PUT', `/contact-messages/${id}/spam`, { spam });
+  },
+
+  // --- SPAM PROTECTION (ADMIN) ---
+  getSpamSettings: async () => {
+    return apiCall<{ data: SpamSettings }>('GET', '/spam/settings');
+  },
+
+  updateSpamSettings: async (settings: Pick<SpamSettings, 'min_submit_seconds' | 'max_links' | 'blocked_words'>) => {
+    return apiCall<MessageResponse & { data: SpamSettings }>('PUT', '/spam/settings', settings);
+  },
+
+  getSpamRejections: async (form: SpamForm | '' = '', reason: SpamReason | '' = '', page = 1, limit = 20) => {
+    const params = new URLSearchParams({ page: String(page), limit: String(limit) });
+    if (form) params.set('form', form);
+    if (reason) params.set('reason', reason);
+    return apiCall<PaginatedResponse<SpamRejection[]>>('GET', `/spam/rejections?${params.toString()}`);
+  },
+
+  getSpamStats: async (days = 30) => {
+    return apiCall<{ data: SpamRejectionCount[] }>('GET', `/spam/stats?days=${days}`);
+  },
+
+  // --- MESSAGE TEMPLATES (ADMIN) ---
+  getMessageTemplates: async () => {
+    return apiCall<{ data: MessageTemplate[]; languages: string[] }>('GET', '/message-templates');
+  },
+
+  updateMessageTemplate: async (tmpl: Pick<MessageTemplate, 'name' | 'channel' | 'language' | 'subject' | 'body'>) => {
+    return apiCall<MessageResponse & { data: MessageTemplate }>('PUT', `/message-templates/${tmpl.name}/${tmpl.channel}/${tmpl.language}`, { subject: tmpl.subject, body: tmpl.body });
+  },
+
+  resetMessageTemplate: async (name: string, channel: string, language: string) => {
+    return apiCall<MessageResponse & { data: MessageTemplate }>('DELETE', `/message-templates/${name}/${channel}/${language}`);
+  },
+
+  previewMessageTemplate: async (name: string, channel: string, language: string, draft?: { subject: string; body: string }) => {
+    return apiCall<{ subject?: string; body: string }>('POST', `/message-templates/${name}/${channel}/${language}/preview`, draft);
+  },
+
   healthCheck: async () => {
     return apiCall<{ status: string }>('GET', '/health');
   },
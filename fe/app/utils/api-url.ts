@@ -47,6 +47,14 @@ export const getImageUrl = (imageUrl: string | null | undefined, preset?: ImageP
   return `${apiUrl}${imageUrl}`;
 };
 
+// Folder upload privat di backend (STORAGE_PRIVATE_FOLDERS); file-nya hanya bisa dibuka lewat signed URL
+const PRIVATE_FILE_FOLDERS = ['proofs'];
+
+export const isPrivateFileRef = (ref: string | null | undefined): boolean => {
+  if (!ref || !ref.startsWith('/')) return false;
+  return PRIVATE_FILE_FOLDERS.includes(ref.split('/')[1]);
+};
+
 // Get default placeholder image
 export const getPlaceholderImage = (): string => {
   return 'https://images.unsplash.com/photo-1522771739844-6a9f6d5f14af?q=80&w=1080';